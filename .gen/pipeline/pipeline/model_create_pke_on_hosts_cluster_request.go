@@ -0,0 +1,35 @@
+/*
+ * Pipeline API
+ *
+ * Pipeline is a feature rich application platform, built for containers on top of Kubernetes to automate the DevOps experience, continuous application development and the lifecycle of deployments. 
+ *
+ * API version: latest
+ * Contact: info@banzaicloud.com
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package pipeline
+
+type CreatePkeOnHostsClusterRequest struct {
+
+	Name string `json:"name"`
+
+	SecretId string `json:"secretId,omitempty"`
+
+	SecretName string `json:"secretName,omitempty"`
+
+	SshSecretId string `json:"sshSecretId,omitempty"`
+
+	ScaleOptions ScaleOptions `json:"scaleOptions,omitempty"`
+
+	Type string `json:"type"`
+
+	Kubernetes CreatePkeClusterKubernetes `json:"kubernetes"`
+
+	Proxy PkeClusterHttpProxy `json:"proxy,omitempty"`
+
+	// Address (eg. of a load balancer or a virtual IP) the API server is reachable on. Defaults to the address of the first master host.
+	ApiServerAddress string `json:"apiServerAddress,omitempty"`
+
+	Nodepools []PkeOnHostsNodePool `json:"nodepools"`
+}
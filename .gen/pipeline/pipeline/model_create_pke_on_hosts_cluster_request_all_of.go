@@ -0,0 +1,19 @@
+/*
+ * Pipeline API
+ *
+ * Pipeline is a feature rich application platform, built for containers on top of Kubernetes to automate the DevOps experience, continuous application development and the lifecycle of deployments. 
+ *
+ * API version: latest
+ * Contact: info@banzaicloud.com
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package pipeline
+
+type CreatePkeOnHostsClusterRequestAllOf struct {
+
+	// Address (eg. of a load balancer or a virtual IP) the API server is reachable on. Defaults to the address of the first master host.
+	ApiServerAddress string `json:"apiServerAddress,omitempty"`
+
+	Nodepools []PkeOnHostsNodePool `json:"nodepools"`
+}
@@ -0,0 +1,29 @@
+/*
+ * Pipeline API
+ *
+ * Pipeline is a feature rich application platform, built for containers on top of Kubernetes to automate the DevOps experience, continuous application development and the lifecycle of deployments. 
+ *
+ * API version: latest
+ * Contact: info@banzaicloud.com
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package pipeline
+
+type PkeOnHostsHost struct {
+
+	// Address of the host Pipeline connects to over SSH.
+	Address string `json:"address"`
+
+	// SSH port of the host. Defaults to 22.
+	Port int32 `json:"port,omitempty"`
+
+	// ID of the SSH secret used to connect to the host. Overrides the main cluster secret.
+	SshSecretId string `json:"sshSecretId,omitempty"`
+
+	// SHA256 fingerprint of the SSH host key of the host (as printed by ssh-keygen -l). Connections to hosts presenting other keys are refused.
+	SshHostKeyFingerprint string `json:"sshHostKeyFingerprint"`
+
+	// Labels applied to the Kubernetes node of the host.
+	Labels map[string]string `json:"labels,omitempty"`
+}
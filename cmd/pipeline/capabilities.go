@@ -47,6 +47,9 @@ func mapCapabilities(config configuration) cap.Capabilities {
 				"enabled":     config.Cluster.Ingress.Enabled,
 				"controllers": config.Cluster.Ingress.Controllers,
 			},
+			"policy": cap.Cap{
+				"enabled": config.Cluster.Policy.Enabled,
+			},
 		},
 		"helm": cap.Cap{
 			"version": "3.1.3", // TODO: determine based on go.mod build time
@@ -26,6 +26,7 @@ import (
 
 	"github.com/banzaicloud/pipeline/internal/app/frontend"
 	"github.com/banzaicloud/pipeline/internal/cmd"
+	"github.com/banzaicloud/pipeline/internal/quota"
 	"github.com/banzaicloud/pipeline/src/auth"
 )
 
@@ -54,6 +55,9 @@ type configuration struct {
 
 	Pipeline PipelineConfig
 
+	// Organization quota configuration
+	Quota quota.Config
+
 	SpotMetrics struct {
 		Enabled            bool
 		CollectionInterval time.Duration
@@ -62,7 +66,7 @@ type configuration struct {
 
 // Validate validates the configuration.
 func (c configuration) Validate() error {
-	return errors.Combine(c.Auth.Validate(), c.Config.Validate(), c.Frontend.Validate())
+	return errors.Combine(c.Auth.Validate(), c.Config.Validate(), c.Frontend.Validate(), c.Quota.Validate())
 }
 
 // Process post-processes the configuration after loading (before validation).
@@ -173,6 +177,14 @@ func configure(v *viper.Viper, p *pflag.FlagSet) {
 	v.SetDefault("spotmetrics::enabled", false)
 	v.SetDefault("spotmetrics::collectionInterval", 30*time.Second)
 
+	v.SetDefault("quota::enabled", false)
+	v.SetDefault("quota::default::clusters", 0)
+	v.SetDefault("quota::default::nodes", 0)
+	v.SetDefault("quota::default::nodePools", 0)
+	v.SetDefault("quota::default::releases", 0)
+	v.SetDefault("quota::default::secrets", 0)
+	v.SetDefault("quota::organizations", map[string]interface{}{})
+
 	v.SetDefault("audit::enabled", true)
 	v.SetDefault("audit::headers", []string{"secretId"})
 	v.SetDefault("audit::skipPaths", []string{"/auth/dex/callback", "/pipeline/api"})
@@ -84,14 +84,25 @@ import (
 	arkSync "github.com/banzaicloud/pipeline/internal/ark/sync"
 	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
 	intClusterAuth "github.com/banzaicloud/pipeline/internal/cluster/auth"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess/clusteraccessadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess/clusteraccessdriver"
 	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertdriver"
 	"github.com/banzaicloud/pipeline/internal/cluster/clusterdriver"
 	"github.com/banzaicloud/pipeline/internal/cluster/clustersecret"
 	"github.com/banzaicloud/pipeline/internal/cluster/clustersecret/clustersecretadapter"
 	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
 	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksadapter"
 	eksDriver "github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksprovider/driver"
+	pkeDistribution "github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke/pkeadapter"
 	"github.com/banzaicloud/pipeline/internal/cluster/endpoints"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupdriver"
 	prometheusMetrics "github.com/banzaicloud/pipeline/internal/cluster/metrics/adapters/prometheus"
 	"github.com/banzaicloud/pipeline/internal/clustergroup"
 	cgroupAdapter "github.com/banzaicloud/pipeline/internal/clustergroup/adapter"
@@ -102,6 +113,8 @@ import (
 	"github.com/banzaicloud/pipeline/internal/federation"
 	"github.com/banzaicloud/pipeline/internal/global"
 	"github.com/banzaicloud/pipeline/internal/global/globalcluster"
+	"github.com/banzaicloud/pipeline/internal/global/globalquota"
+	"github.com/banzaicloud/pipeline/internal/global/globalsecretusage"
 	"github.com/banzaicloud/pipeline/internal/global/nplabels"
 	"github.com/banzaicloud/pipeline/internal/helm"
 	"github.com/banzaicloud/pipeline/internal/helm/helmdriver"
@@ -115,11 +128,14 @@ import (
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/ingress"
 	integratedServiceLogging "github.com/banzaicloud/pipeline/internal/integratedservices/services/logging"
 	featureMonitoring "github.com/banzaicloud/pipeline/internal/integratedservices/services/monitoring"
+	integratedServicePolicy "github.com/banzaicloud/pipeline/internal/integratedservices/services/policy"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan/securityscanadapter"
 	integratedServiceVault "github.com/banzaicloud/pipeline/internal/integratedservices/services/vault"
 	cgFeatureIstio "github.com/banzaicloud/pipeline/internal/istio/istiofeature"
 	"github.com/banzaicloud/pipeline/internal/kubernetes"
+	"github.com/banzaicloud/pipeline/internal/kubernetes/kubernetesadapter"
+	"github.com/banzaicloud/pipeline/internal/metricsfederation"
 	"github.com/banzaicloud/pipeline/internal/monitor"
 	intPKE "github.com/banzaicloud/pipeline/internal/pke"
 	"github.com/banzaicloud/pipeline/internal/platform/appkit"
@@ -135,16 +151,37 @@ import (
 	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
 	"github.com/banzaicloud/pipeline/internal/platform/log"
 	"github.com/banzaicloud/pipeline/internal/platform/watermill"
+	"github.com/banzaicloud/pipeline/internal/policy"
+	"github.com/banzaicloud/pipeline/internal/policy/policyadapter"
+	"github.com/banzaicloud/pipeline/internal/policy/policydriver"
+	"github.com/banzaicloud/pipeline/internal/policy/policyfeature"
 	azurePKEAdapter "github.com/banzaicloud/pipeline/internal/providers/azure/pke/adapter"
 	azurePKEDriver "github.com/banzaicloud/pipeline/internal/providers/azure/pke/driver"
 	"github.com/banzaicloud/pipeline/internal/providers/google"
 	"github.com/banzaicloud/pipeline/internal/providers/google/googleadapter"
+	hostsPKEAdapter "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/adapter"
+	hostsPKEDriver "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver"
 	vspherePKEAdapter "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/adapter"
 	vspherePKEDriver "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/driver"
+	"github.com/banzaicloud/pipeline/internal/quota"
+	"github.com/banzaicloud/pipeline/internal/quota/quotaadapter"
+	"github.com/banzaicloud/pipeline/internal/quota/quotadriver"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingdriver"
 	"github.com/banzaicloud/pipeline/internal/secret/pkesecret"
 	"github.com/banzaicloud/pipeline/internal/secret/restricted"
-	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationdriver"
 	"github.com/banzaicloud/pipeline/internal/secret/types"
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+	"github.com/banzaicloud/pipeline/internal/secret/usage/usagedriver"
+	"github.com/banzaicloud/pipeline/internal/security/securityadapter"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport/vulnreportadapter"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport/vulnreportdriver"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry/whitelistexpiryadapter"
 	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/banzaicloud/pipeline/pkg/cloudinfo"
 	"github.com/banzaicloud/pipeline/pkg/ctxutil"
@@ -188,6 +225,7 @@ func main() {
 
 	p.String("config", "", "Configuration file")
 	p.Bool("version", false, "Show version information")
+	p.String("migrate-secrets-from", "", "Copy organization secrets from the given secret store backend (vault, sql or kubernetes) to the configured one, then exit")
 
 	_ = p.Parse(os.Args[1:])
 
@@ -264,12 +302,28 @@ func main() {
 		appkiterrors.IsServiceError, // filter out client errors
 	)
 
-	vaultClient, err := vault.NewClient("pipeline")
-	emperror.Panic(err)
-	global.SetVault(vaultClient)
+	// Vault is only used when it stores the organization secrets
+	var vaultClient *vault.Client
+	if config.Secret.Store.Backend == cmd.SecretStoreBackendVault {
+		client, err := vault.NewClient("pipeline")
+		emperror.Panic(err)
+
+		vaultClient = client
+		global.SetVault(vaultClient)
+	}
 
-	secretStore := secretadapter.NewVaultStore(vaultClient, "secret")
-	pkeSecreter := pkesecret.NewPkeSecreter(vaultClient, commonLogger)
+	// Connect to database
+	db, err := database.Connect(config.Database.Config)
+	emperror.Panic(errors.WithMessage(err, "failed to initialize db"))
+	global.SetDB(db)
+
+	secretStore, err := cmd.CreateSecretStore(config.Secret.Store.Backend, config.Secret.Store, vaultClient, db)
+	emperror.Panic(errors.WithMessage(err, "failed to create secret store"))
+
+	var pkeSecreter types.PkeSecreter = pkesecret.NewLocalPkeSecreter()
+	if vaultClient != nil {
+		pkeSecreter = pkesecret.NewPkeSecreter(vaultClient, commonLogger)
+	}
 	secretTypes := types.NewDefaultTypeList(types.DefaultTypeListConfig{
 		TLSDefaultValidity: config.Secret.TLS.DefaultValidity,
 		PkeSecreter:        pkeSecreter,
@@ -277,11 +331,6 @@ func main() {
 	secret.InitSecretStore(secretStore, secretTypes)
 	restricted.InitSecretStore(secret.Store)
 
-	// Connect to database
-	db, err := database.Connect(config.Database.Config)
-	emperror.Panic(errors.WithMessage(err, "failed to initialize db"))
-	global.SetDB(db)
-
 	publisher, subscriber := watermill.NewPubSub(logger)
 	defer publisher.Close()
 	defer subscriber.Close()
@@ -329,7 +378,10 @@ func main() {
 	}
 
 	// Initialize auth
-	tokenStore := bauth.NewVaultTokenStore("pipeline")
+	var tokenStore bauth.TokenStore = authadapter.NewSecretTokenStore(secretStore)
+	if vaultClient != nil {
+		tokenStore = bauth.NewVaultTokenStore("pipeline")
+	}
 	tokenGenerator := pkgAuth.NewJWTTokenGenerator(
 		config.Auth.Token.Issuer,
 		config.Auth.Token.Audience,
@@ -348,6 +400,17 @@ func main() {
 		}
 	}
 
+	if backend, _ := p.GetString("migrate-secrets-from"); backend != "" {
+		err := migrateSecrets(context.Background(), backend, config.Secret.Store, vaultClient, db, secretStore, commonLogger)
+		if err != nil {
+			logger.Error(err.Error())
+
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
 	// External DNS service
 	dnsSvc, err := dns.GetExternalDnsServiceClient()
 	if err != nil {
@@ -408,6 +471,48 @@ func main() {
 		errorHandler.Handle(errors.WrapIf(err, "Failed to configure Cadence client"))
 	}
 
+	secretRotationStarter := rotationadapter.NewCadenceStarter(workflowClient)
+	if config.Secret.Rotation.Enabled {
+		err := secretRotationStarter.StartScheduler(context.Background(), config.Secret.Rotation.Schedule)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIf(err, "failed to start secret rotation scheduler"))
+		}
+	}
+
+	secretBindingStarter := bindingadapter.NewCadenceStarter(workflowClient)
+	if config.Secret.Binding.Enabled {
+		err := secretBindingStarter.StartScheduler(context.Background(), config.Secret.Binding.Schedule)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIf(err, "failed to start secret binding scheduler"))
+		}
+	}
+
+	etcdBackupStarter := etcdbackupadapter.NewCadenceStarter(workflowClient)
+	if config.Cluster.EtcdBackup.Enabled {
+		err := etcdBackupStarter.StartScheduler(context.Background(), config.Cluster.EtcdBackup.Schedule)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIf(err, "failed to start etcd backup scheduler"))
+		}
+	}
+
+	clusterCertificateStarter := clustercertadapter.NewCadenceStarter(workflowClient)
+	if config.Cluster.Certificates.Enabled {
+		err := clusterCertificateStarter.StartScheduler(context.Background(), config.Cluster.Certificates.Schedule)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIf(err, "failed to start cluster certificate scheduler"))
+		}
+	}
+
+	clusterCertificateStore := clustercertadapter.NewGormStore(db)
+	certificateExpiries := clustercert.NewExpiryGetter(config.Cluster.Certificates, clusterCertificateStore)
+
+	if config.Cluster.SecurityScan.Enabled && config.Cluster.SecurityScan.WhitelistExpiry.Enabled {
+		err := whitelistexpiryadapter.NewCadenceStarter(workflowClient).StartScheduler(context.Background(), config.Cluster.SecurityScan.WhitelistExpiry.Schedule)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIf(err, "failed to start security whitelist expiry scheduler"))
+		}
+	}
+
 	releaseDeleter := cmd.CreateReleaseDeleter(config.Helm, db, commonSecretStore, commonLogger)
 
 	clusterManager := cluster.NewManager(clusters, secretValidator, clusterEvents, statusChangeDurationMetric, clusterTotalMetric, workflowClient, logrusLogger, errorHandler, clusteradapter.NewStore(db, clusters), releaseDeleter)
@@ -443,6 +548,7 @@ func main() {
 
 	azurePKEClusterStore := azurePKEAdapter.NewClusterStore(db, commonLogger)
 	gormVspherePKEClusterStore := vspherePKEAdapter.NewClusterStore(db)
+	gormHostsPKEClusterStore := hostsPKEAdapter.NewClusterStore(db)
 	k8sPreparer := intPKE.MakeKubernetesPreparer(logrusLogger, "Kubernetes")
 	clusterCreators := api.ClusterCreators{
 		PKEOnAzure: azurePKEDriver.MakeClusterCreator(
@@ -479,6 +585,19 @@ func main() {
 			gormVspherePKEClusterStore,
 			workflowClient,
 		),
+		PKEOnHosts: hostsPKEDriver.MakePKEOnHostsClusterCreator(
+			commonLogger,
+			hostsPKEDriver.ClusterConfig{
+				OIDCIssuerURL:               config.Auth.OIDC.Issuer,
+				PipelineExternalURL:         externalBaseURL,
+				PipelineExternalURLInsecure: externalURLInsecure,
+			},
+			k8sPreparer,
+			authdriver.NewOrganizationGetter(db),
+			secret.Store,
+			gormHostsPKEClusterStore,
+			workflowClient,
+		),
 	}
 
 	orgService := helmadapter.NewOrgService(commonLogger)
@@ -492,15 +611,29 @@ func main() {
 		commonLogger,
 	)
 
+	var quotaReleaseLister quotaadapter.ReleaseLister = helmFacade
+	if !config.Helm.V3 {
+		quotaReleaseLister = api.NewHelm2ReleaseLister(helm.ClusterKubeConfigFunc(clusterManager.KubeConfigFunc()))
+	}
+
+	quotaUsageCounter := quotaadapter.NewUsageCounter(clusterManager, secretStore, quotaReleaseLister, commonLogger)
+	quotaEnforcer := quota.NewEnforcer(config.Quota, quotaUsageCounter)
+	globalquota.SetEnforcer(quotaEnforcer)
+
+	secretUsageIndex := cmd.CreateSecretUsageIndex(db, commonLogger)
+	globalsecretusage.SetIndex(secretUsageIndex)
+
 	cgroupAdapter := cgroupAdapter.NewClusterGetter(clusterManager)
 	clusterGroupManager := clustergroup.NewManager(cgroupAdapter, clustergroup.NewClusterGroupRepository(db, logrusLogger), logrusLogger, errorHandler)
 	federationHandler := federation.NewFederationHandler(cgroupAdapter, config.Cluster.Namespace, logrusLogger, errorHandler, config.Cluster.Federation, config.Cluster.DNS.Config, unifiedHelmReleaser)
 	deploymentManager := deployment.NewCGDeploymentManager(db, cgroupAdapter, logrusLogger, errorHandler)
 
 	serviceMeshFeatureHandler := cgFeatureIstio.NewServiceMeshFeatureHandler(cgroupAdapter, logrusLogger, errorHandler, config.Cluster.Backyards, unifiedHelmReleaser)
+	metricsFederationHandler := metricsfederation.NewHandler(config.Cluster.Monitoring.Namespace, logrusLogger, errorHandler, config.Cluster.MetricsFederation, unifiedHelmReleaser)
 	clusterGroupManager.RegisterFeatureHandler(federation.FeatureName, federationHandler)
 	clusterGroupManager.RegisterFeatureHandler(deployment.FeatureName, deploymentManager)
 	clusterGroupManager.RegisterFeatureHandler(cgFeatureIstio.FeatureName, serviceMeshFeatureHandler)
+	clusterGroupManager.RegisterFeatureHandler(metricsfederation.FeatureName, metricsFederationHandler)
 	clusterUpdaters := api.ClusterUpdaters{
 		PKEOnAzure: azurePKEDriver.MakeClusterUpdater(
 			logrusLogger,
@@ -526,6 +659,18 @@ func main() {
 			gormVspherePKEClusterStore,
 			workflowClient,
 		),
+		PKEOnHosts: hostsPKEDriver.MakeClusterUpdater(
+			commonLogger,
+			hostsPKEDriver.ClusterConfig{
+				OIDCIssuerURL:               config.Auth.OIDC.Issuer,
+				PipelineExternalURL:         externalBaseURL,
+				PipelineExternalURLInsecure: externalURLInsecure,
+			},
+			authdriver.NewOrganizationGetter(db),
+			secret.Store,
+			gormHostsPKEClusterStore,
+			workflowClient,
+		),
 	}
 
 	configFactory := kubernetes.NewConfigFactory(commonSecretStore)
@@ -629,10 +774,11 @@ func main() {
 	clusterAuthService, err := intClusterAuth.NewDexClusterAuthService(clusterSecretStore)
 	emperror.Panic(errors.WrapIf(err, "failed to create DexClusterAuthService"))
 
-	dashboardAPI := dashboard.NewDashboardAPI(clusterManager, clusterGroupManager, logrusLogger, errorHandler, config.Auth, clusterAuthService)
+	dashboardAPI := dashboard.NewDashboardAPI(clusterManager, clusterGroupManager, logrusLogger, errorHandler, config.Auth, clusterAuthService, certificateExpiries)
 	dgroup := base.Group(path.Join("dashboard", "orgs"))
 	dgroup.Use(auth.InternalHandler)
 	dgroup.Use(auth.Handler)
+	dgroup.Use(auth.NewClusterScopeHandler(basePath))
 	dgroup.Use(api.OrganizationMiddleware)
 	dgroup.Use(authorizationMiddleware)
 	dgroup.GET("/:orgid/clusters", dashboardAPI.GetDashboard)
@@ -671,6 +817,8 @@ func main() {
 		unifiedHelmReleaser,
 		config.Auth,
 		clusterAuthService,
+		quotaEnforcer,
+		certificateExpiries,
 	)
 
 	v1 := base.Group("api/v1")
@@ -681,6 +829,7 @@ func main() {
 
 		v1.Use(auth.InternalHandler)
 		v1.Use(auth.Handler)
+		v1.Use(auth.NewClusterScopeHandler(basePath))
 		capdriver.RegisterHTTPHandler(mapCapabilities(config), commonErrorHandler, v1)
 		v1.GET("/me", userAPI.GetCurrentUser)
 
@@ -726,18 +875,116 @@ func main() {
 				cRouter.POST("/secrets", api.InstallSecretsToCluster)
 				cRouter.POST("/secrets/:secretName", api.InstallSecretToCluster)
 				cRouter.PATCH("/secrets/:secretName", api.MergeSecretInCluster)
-				cRouter.Any("/proxy/*path", clusterAPI.ProxyToCluster)
+				{
+					memberAccess := clusteraccess.NewMemberAccess(kubernetes.NewService(
+						kubernetesadapter.NewConfigSecretGetter(clusters),
+						configFactory,
+						logger,
+					))
+
+					cRouter.Any("/proxy/*path", api.NewProxyImpersonationMiddleware(organizationStore, memberAccess, errorHandler), clusterAPI.ProxyToCluster)
+				}
 				cRouter.HEAD("", clusterAPI.ClusterCheck)
 				cRouter.GET("/config", api.GetClusterConfig)
 				cRouter.GET("/nodes", api.GetClusterNodes)
 
 				cRouter.GET("/secrets", api.ListClusterSecrets)
+
+				{
+					service := clusteraccess.NewService(
+						config.Cluster.Kubeconfig,
+						externalBaseURL,
+						externalURLInsecure,
+						clusteradapter.NewStore(db, clusters),
+						auth.UserExtractor{},
+						auth.NewKubeconfigTokenGenerator(tokenManager),
+						tokenStore,
+						kubernetes.NewService(
+							kubernetesadapter.NewConfigSecretGetter(clusters),
+							configFactory,
+							logger,
+						),
+						clusteraccessadapter.NewGormStore(db),
+					)
+					endpoints := clusteraccessdriver.MakeEndpoints(
+						service,
+						kitxendpoint.Combine(endpointMiddleware...),
+					)
+
+					clusteraccessdriver.RegisterHTTPHandlers(
+						endpoints,
+						clusterRouter.PathPrefix("/kubeconfigs").Subrouter(),
+						kitxhttp.ServerOptions(httpServerOptions),
+					)
+
+					cRouter.POST("/kubeconfigs", gin.WrapH(router))
+					cRouter.GET("/kubeconfigs", gin.WrapH(router))
+					cRouter.DELETE("/kubeconfigs", gin.WrapH(router))
+					cRouter.DELETE("/kubeconfigs/:kubeconfigId", gin.WrapH(router))
+				}
+
+				{
+					service := etcdbackup.NewService(
+						etcdbackupadapter.NewGormStore(db),
+						clusteradapter.NewStore(db, clusters),
+						secretStore,
+						etcdbackupadapter.NewObjectStoreFactory(secretStore),
+						etcdBackupStarter,
+						commonLogger,
+					)
+					endpoints := etcdbackupdriver.MakeEndpoints(
+						service,
+						kitxendpoint.Combine(endpointMiddleware...),
+					)
+
+					etcdbackupdriver.RegisterHTTPHandlers(
+						endpoints,
+						clusterRouter.PathPrefix("/etcdbackup").Subrouter(),
+						kitxhttp.ServerOptions(httpServerOptions),
+					)
+
+					cRouter.GET("/etcdbackup/settings", gin.WrapH(router))
+					cRouter.PUT("/etcdbackup/settings", gin.WrapH(router))
+					cRouter.DELETE("/etcdbackup/settings", gin.WrapH(router))
+					cRouter.GET("/etcdbackup/snapshots", gin.WrapH(router))
+					cRouter.POST("/etcdbackup/snapshots", gin.WrapH(router))
+					cRouter.GET("/etcdbackup/snapshots/:snapshotId", gin.WrapH(router))
+					cRouter.POST("/etcdbackup/snapshots/:snapshotId/restore", gin.WrapH(router))
+					cRouter.GET("/etcdbackup/restores", gin.WrapH(router))
+				}
+
+				{
+					service := clustercert.NewService(
+						config.Cluster.Certificates,
+						clusterCertificateStore,
+						clusteradapter.NewStore(db, clusters),
+						secretStore,
+						clusterCertificateStarter,
+						commonLogger,
+					)
+					endpoints := clustercertdriver.MakeEndpoints(
+						service,
+						kitxendpoint.Combine(endpointMiddleware...),
+					)
+
+					clustercertdriver.RegisterHTTPHandlers(
+						endpoints,
+						clusterRouter.PathPrefix("/certificates").Subrouter(),
+						kitxhttp.ServerOptions(httpServerOptions),
+					)
+
+					cRouter.GET("/certificates", gin.WrapH(router))
+					cRouter.GET("/certificates/settings", gin.WrapH(router))
+					cRouter.PUT("/certificates/settings", gin.WrapH(router))
+					cRouter.GET("/certificates/rotations", gin.WrapH(router))
+					cRouter.POST("/certificates/rotations", gin.WrapH(router))
+				}
 				cs := helm.ClusterKubeConfigFunc(clusterManager.KubeConfigFunc())
 
 				{
 					if config.Helm.V3 {
 						endpoints := helmdriver.MakeEndpoints(
-							helmFacade,
+							quotadriver.HelmServiceMiddleware(quotaEnforcer)(helmFacade),
 							kitxendpoint.Combine(endpointMiddleware...),
 						)
 
@@ -821,6 +1068,13 @@ func main() {
 								clusterStore,
 								eksadapter.NewNodePoolStore(db),
 								eksadapter.NewNodePoolManager(workflowClient, config.Pipeline.Enterprise),
+								eksadapter.NewClusterStore(db),
+								eksadapter.NewClusterManager(workflowClient, config.Pipeline.Enterprise),
+							)),
+							"pke": clusteradapter.NewPKEService(pkeDistribution.NewService(
+								clusterStore,
+								pkeadapter.NewClusterStore(db, azurePKEClusterStore, gormVspherePKEClusterStore),
+								pkeadapter.NewClusterManager(workflowClient, config.Pipeline.Enterprise),
 							)),
 						},
 						clusteradapter.NewNodePoolStore(db, clusterStore),
@@ -848,6 +1102,8 @@ func main() {
 						),
 					)
 
+					service = quotadriver.ClusterServiceMiddleware(quotaEnforcer, clusterStore, quotaadapter.NewNodePoolStore(clusterManager))(service)
+
 					endpoints := clusterdriver.MakeEndpoints(
 						service,
 						kitxendpoint.Combine(endpointMiddleware...),
@@ -863,6 +1119,7 @@ func main() {
 					cRouter.Any("/nodepools", gin.WrapH(router))
 					cRouter.Any("/nodepools/:nodePoolName", gin.WrapH(router))
 					cRouter.Any("/nodepools/:nodePoolName/update", gin.WrapH(router))
+					cRouter.POST("/upgrade", gin.WrapH(router))
 				}
 			}
 
@@ -873,6 +1130,11 @@ func main() {
 				clusterGetter := integratedserviceadapter.MakeClusterGetter(clusterManager)
 				clusterPropertyGetter := dnsadapter.NewClusterPropertyGetter(clusterManager)
 				endpointManager := endpoints.NewEndpointManager(commonLogger)
+				kubernetesService := kubernetes.NewService(
+					kubernetesadapter.NewConfigSecretGetter(clusteradapter.NewClusters(db)),
+					configFactory,
+					commonLogger,
+				)
 				integratedServiceManagers := []integratedservices.IntegratedServiceManager{
 					securityscan.MakeIntegratedServiceManager(commonLogger, config.Cluster.SecurityScan.Config),
 				}
@@ -891,6 +1153,7 @@ func main() {
 						commonSecretStore,
 						endpointManager,
 						unifiedHelmReleaser,
+						kubernetesService,
 						config.Cluster.Monitoring.Config,
 						commonLogger,
 					))
@@ -924,7 +1187,7 @@ func main() {
 						))
 					}
 
-					securityApiHandler := api.NewSecurityApiHandlers(commonClusterGetter, commonErrorHandler, commonLogger)
+					securityApiHandler := api.NewSecurityApiHandlers(commonClusterGetter, securityadapter.NewGormWhitelistAuditLog(db), commonErrorHandler, commonLogger)
 
 					anchoreProxy := api.NewAnchoreProxy(basePath, configProvider, commonErrorHandler, commonLogger)
 					proxyHandler := anchoreProxy.Proxy()
@@ -939,6 +1202,8 @@ func main() {
 					cRouter.GET("/whitelists", securityApiHandler.GetWhiteLists)
 					cRouter.POST("/whitelists", securityApiHandler.CreateWhiteList)
 					cRouter.DELETE("/whitelists/:name", securityApiHandler.DeleteWhiteList)
+					cRouter.POST("/whitelists/:name/approve", securityApiHandler.ApproveWhiteList)
+					cRouter.GET("/whitelists/audit", securityApiHandler.GetWhiteListAudit)
 				}
 
 				if config.Cluster.Expiry.Enabled {
@@ -946,10 +1211,16 @@ func main() {
 						expiry.NewExpiryServiceManager(services.BindIntegratedServiceSpec))
 				}
 
+				policyManager := integratedServicePolicy.MakeIntegratedServiceManager(kubernetesService, config.Cluster.Policy.Config, commonLogger)
+				if config.Cluster.Policy.Enabled {
+					integratedServiceManagers = append(integratedServiceManagers, policyManager)
+				}
+
 				if config.Cluster.Ingress.Enabled {
 					integratedServiceManagers = append(integratedServiceManagers, ingress.NewManager(
 						config.Cluster.Ingress.Config,
 						unifiedHelmReleaser,
+						kubernetesService,
 						commonLogger,
 					))
 				}
@@ -957,6 +1228,17 @@ func main() {
 				integratedServiceManagerRegistry := integratedservices.MakeIntegratedServiceManagerRegistry(integratedServiceManagers)
 				integratedServiceOperationDispatcher := integratedserviceadapter.MakeCadenceIntegratedServiceOperationDispatcher(workflowClient, commonLogger)
 				integratedServicesService = integratedservices.MakeIntegratedServiceService(integratedServiceOperationDispatcher, integratedServiceManagerRegistry, featureRepository, commonLogger)
+
+				if config.Cluster.Policy.Enabled {
+					clusterGroupManager.RegisterFeatureHandler(policyfeature.FeatureName, policyfeature.NewHandler(
+						integratedServicesService,
+						featureRepository,
+						policyManager,
+						logrusLogger,
+						errorHandler,
+					))
+				}
+
 				endpoints := integratedservicesdriver.MakeEndpoints(
 					integratedServicesService,
 					kitxendpoint.Combine(endpointMiddleware...),
@@ -1000,8 +1282,10 @@ func main() {
 
 			pkeGroup := cRouter.Group("/pke")
 
-			leaderRepository, err := pke.NewVaultLeaderRepository()
-			emperror.Panic(errors.WrapIf(err, "failed to create Vault leader repository"))
+			var leaderRepository pke.LeaderRepository = pke.NewSecretStoreLeaderRepository(secretStore)
+			if vaultClient != nil {
+				leaderRepository = pke.NewVaultLeaderRepositoryFromClient(vaultClient)
+			}
 
 			pkeAPI := pke.NewAPI(
 				commonClusterGetter,
@@ -1057,6 +1341,9 @@ func main() {
 			orgs.PUT("/:orgid/secrets/:id", api.UpdateSecrets)
 			orgs.DELETE("/:orgid/secrets/:id", api.DeleteSecrets)
 			orgs.GET("/:orgid/secrets/:id/validate", api.ValidateSecret)
+			orgs.GET("/:orgid/secrets/:id/versions", api.ListSecretVersions)
+			orgs.GET("/:orgid/secrets/:id/versions/:version", api.GetSecretVersion)
+			orgs.POST("/:orgid/secrets/:id/versions/:version/rollback", api.RollbackSecret)
 			orgs.GET("/:orgid/secrets/:id/tags", api.GetSecretTags)
 			orgs.PUT("/:orgid/secrets/:id/tags/*tag", api.AddSecretTag)
 			orgs.DELETE("/:orgid/secrets/:id/tags/*tag", api.DeleteSecretTag)
@@ -1095,6 +1382,162 @@ func main() {
 				orgs.Any("/:orgid/cloud/google/projects", gin.WrapH(router))
 			}
 
+			{
+				service := quota.NewService(config.Quota, quotaUsageCounter)
+				endpoints := quotadriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				quotadriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/quotas").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.GET("/:orgid/quotas", gin.WrapH(router))
+			}
+
+			{
+				service := rotation.NewService(
+					rotationadapter.NewGormStore(db),
+					secretStore,
+					secretTypes,
+					secretRotationStarter,
+				)
+				endpoints := rotationdriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				rotationdriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/secrets/{secretId}").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.GET("/:orgid/secrets/:id/rotation", gin.WrapH(router))
+				orgs.PUT("/:orgid/secrets/:id/rotation", gin.WrapH(router))
+				orgs.DELETE("/:orgid/secrets/:id/rotation", gin.WrapH(router))
+				orgs.POST("/:orgid/secrets/:id/rotate", gin.WrapH(router))
+			}
+
+			{
+				bindingStore := bindingadapter.NewGormStore(db)
+				clusterStore := clusteradapter.NewStore(db, clusters)
+				syncer := binding.NewSyncer(
+					bindingStore,
+					secretStore,
+					clusterStore,
+					bindingadapter.NewClusterGroupStore(clustergroup.NewClusterGroupRepository(db, logrusLogger)),
+					bindingadapter.NewKubernetesSecretManager(kubernetes.NewService(
+						kubernetesadapter.NewConfigSecretGetter(clusters),
+						configFactory,
+						commonLogger,
+					)),
+					commonLogger,
+				)
+				service := binding.NewService(
+					bindingStore,
+					secretStore,
+					clusterStore,
+					syncer,
+					secretBindingStarter,
+					commonLogger,
+				)
+				endpoints := bindingdriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				bindingdriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/secretbindings").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.POST("/:orgid/secretbindings", gin.WrapH(router))
+				orgs.GET("/:orgid/secretbindings", gin.WrapH(router))
+				orgs.GET("/:orgid/secretbindings/:bindingId", gin.WrapH(router))
+				orgs.PUT("/:orgid/secretbindings/:bindingId", gin.WrapH(router))
+				orgs.DELETE("/:orgid/secretbindings/:bindingId", gin.WrapH(router))
+				orgs.GET("/:orgid/secretbindings/:bindingId/status", gin.WrapH(router))
+			}
+
+			{
+				service := policy.NewService(policyadapter.NewGormStore(db))
+				endpoints := policydriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				policydriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/policies").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.GET("/:orgid/policies/templates", gin.WrapH(router))
+				orgs.GET("/:orgid/policies/templates/:name", gin.WrapH(router))
+				orgs.PUT("/:orgid/policies/templates/:name", gin.WrapH(router))
+				orgs.DELETE("/:orgid/policies/templates/:name", gin.WrapH(router))
+			}
+
+			if config.Cluster.SecurityScan.Enabled {
+				configProvider := anchore2.ConfigProviderChain{securityscan.NewCustomAnchoreConfigProvider(
+					integratedserviceadapter.NewGormIntegratedServiceRepository(db, commonLogger),
+					commonSecretStore,
+					commonLogger,
+				)}
+
+				if config.Cluster.SecurityScan.Anchore.Enabled {
+					configProvider = append(configProvider, securityscan.NewClusterAnchoreConfigProvider(
+						config.Cluster.SecurityScan.Anchore.Endpoint,
+						securityscanadapter.NewUserNameGenerator(securityscanadapter.NewClusterService(clusterManager)),
+						securityscanadapter.NewUserSecretStore(commonSecretStore),
+						config.Cluster.SecurityScan.Anchore.Insecure,
+					))
+				}
+
+				service := vulnreport.NewService(
+					config.Cluster.SecurityScan.Report,
+					vulnreportadapter.NewClusterLister(clusterManager, integratedserviceadapter.NewGormIntegratedServiceRepository(db, commonLogger)),
+					vulnreportadapter.NewImageLister(helm.ClusterKubeConfigFunc(clusterManager.KubeConfigFunc())),
+					vulnreport.Scanners{
+						securityscan.ScannerTypeAnchore: vulnreportadapter.NewAnchoreScanner(configProvider),
+					},
+					vulnreportadapter.NewGormTrendStore(db),
+				)
+				endpoints := vulnreportdriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				vulnreportdriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/vulnerabilities").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.GET("/:orgid/vulnerabilities", gin.WrapH(router))
+			}
+
+			{
+				service := usage.NewService(secretUsageIndex, secretStore)
+				endpoints := usagedriver.MakeEndpoints(
+					service,
+					kitxendpoint.Combine(endpointMiddleware...),
+				)
+
+				usagedriver.RegisterHTTPHandlers(
+					endpoints,
+					orgRouter.PathPrefix("/secrets/{secretId}").Subrouter(),
+					kitxhttp.ServerOptions(httpServerOptions),
+				)
+
+				orgs.GET("/:orgid/secrets/:id/usage", gin.WrapH(router))
+			}
+
 			orgs.GET("/:orgid", organizationAPI.GetOrganizations)
 			orgs.DELETE("/:orgid", organizationAPI.DeleteOrganization)
 		}
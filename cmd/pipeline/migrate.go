@@ -33,8 +33,17 @@ import (
 	"github.com/banzaicloud/pipeline/internal/app/pipeline/api/middleware/audit"
 	"github.com/banzaicloud/pipeline/internal/app/pipeline/process/processadapter"
 	"github.com/banzaicloud/pipeline/internal/ark"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess/clusteraccessadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupadapter"
 	"github.com/banzaicloud/pipeline/internal/clustergroup"
+	"github.com/banzaicloud/pipeline/internal/policy/policyadapter"
 	"github.com/banzaicloud/pipeline/internal/providers"
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+	"github.com/banzaicloud/pipeline/internal/security/securityadapter"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport/vulnreportadapter"
 	"github.com/banzaicloud/pipeline/src/auth"
 	route53model "github.com/banzaicloud/pipeline/src/dns/route53/model"
 )
@@ -109,5 +118,41 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger, commonLogger common.Logger)
 		return err
 	}
 
+	if err := policyadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := clusteraccessadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := etcdbackupadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := clustercertadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := rotationadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := bindingadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := secretadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := securityadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
+	if err := vulnreportadapter.Migrate(db, commonLogger); err != nil {
+		return err
+	}
+
 	return nil
 }
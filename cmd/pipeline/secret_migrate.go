@@ -0,0 +1,80 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/bank-vaults/pkg/sdk/vault"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cmd"
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+// migrateSecrets copies the secrets of every organization from the given backend to the configured secret store.
+func migrateSecrets(
+	ctx context.Context,
+	backend string,
+	config cmd.SecretStoreConfig,
+	vaultClient *vault.Client,
+	db *gorm.DB,
+	destination secret.Store,
+	logger common.Logger,
+) error {
+	if backend == config.Backend {
+		return errors.NewWithDetails("cannot migrate secrets to the same backend", "backend", backend)
+	}
+
+	// Pipeline only connects to Vault on its own when it is the configured backend
+	if backend == cmd.SecretStoreBackendVault && vaultClient == nil {
+		var err error
+
+		vaultClient, err = vault.NewClient("pipeline")
+		if err != nil {
+			return errors.WrapIf(err, "failed to create Vault client")
+		}
+	}
+
+	source, err := cmd.CreateSecretStore(backend, config, vaultClient, db)
+	if err != nil {
+		return errors.WrapIf(err, "failed to create source secret store")
+	}
+
+	var organizationIDs []uint
+
+	if err := db.Model(&auth.Organization{}).Pluck("id", &organizationIDs).Error; err != nil {
+		return errors.Wrap(err, "failed to list organizations")
+	}
+
+	logger.Info("migrating secrets", map[string]interface{}{
+		"from":          backend,
+		"to":            config.Backend,
+		"organizations": len(organizationIDs),
+	})
+
+	count, err := secretadapter.MigrateSecrets(ctx, source, destination, organizationIDs, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("secrets migrated successfully", map[string]interface{}{"secrets": count})
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertworkflow"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+func registerClusterCertificateWorkflows(
+	config clustercert.Config,
+	db *gorm.DB,
+	secretStore secret.Store,
+	kubeConfigs intClusterK8s.KubeConfigGetter,
+	logger common.Logger,
+) {
+	clusters := clusteradapter.NewClusters(db)
+
+	manager := clustercert.NewManager(
+		config,
+		clustercertadapter.NewGormStore(db),
+		clusteradapter.NewStore(db, clusters),
+		clustercertadapter.NewClusterLister(clusters),
+		clustercertadapter.NewKubernetesControlPlane(kubeConfigs, config.Image),
+		secretStore,
+		clustercertadapter.NewSlackNotifier(secretStore, http.DefaultClient, logger),
+		logger,
+	)
+
+	clustercertworkflow.NewSchedulerWorkflow().Register()
+	clustercertworkflow.NewRotateCertificatesWorkflow().Register()
+
+	clustercertworkflow.NewListClustersActivity(manager).Register()
+	clustercertworkflow.NewCheckCertificatesActivity(manager).Register()
+	clustercertworkflow.NewBeginRotationActivity(manager).Register()
+	clustercertworkflow.NewRenewCertificatesActivity(manager).Register()
+	clustercertworkflow.NewRefreshKubeconfigActivity(manager).Register()
+	clustercertworkflow.NewFinishRotationActivity(manager).Register()
+}
@@ -19,6 +19,7 @@ import (
 	"go.uber.org/cadence/activity"
 	"go.uber.org/cadence/workflow"
 
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
 	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksprovider/adapter"
 	eksworkflow "github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksprovider/workflow"
 	eksworkflow2 "github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksworkflow"
@@ -26,7 +27,12 @@ import (
 	"github.com/banzaicloud/pipeline/src/cluster"
 )
 
-func registerEKSWorkflows(secretStore eksworkflow.SecretStore, clusterManager *adapter.ClusterManagerAdapter) error {
+func registerEKSWorkflows(
+	secretStore eksworkflow.SecretStore,
+	clusterManager *adapter.ClusterManagerAdapter,
+	clientFactory eksworkflow2.ClientFactory,
+	clusterStore eks.ClusterStore,
+) error {
 	vpcTemplate, err := eksworkflow.GetVPCTemplate()
 	if err != nil {
 		return errors.WrapIf(err, "failed to get CloudFormation template for VPC")
@@ -140,6 +146,17 @@ func registerEKSWorkflows(secretStore eksworkflow.SecretStore, clusterManager *a
 	eksworkflow2.NewCalculateNodePoolVersionActivity().Register()
 	eksworkflow2.NewUpdateNodeGroupActivity(awsSessionFactory, nodePoolTemplate).Register()
 	eksworkflow2.NewWaitCloudFormationStackUpdateActivity(awsSessionFactory).Register()
+	eksworkflow2.NewListTerminatingInstancesActivity(awsSessionFactory).Register()
+	eksworkflow2.NewCompleteLifecycleActionActivity(awsSessionFactory).Register()
+
+	// Cluster upgrade
+	eksworkflow2.NewUpgradeClusterWorkflow(processlog.New()).Register()
+
+	eksworkflow2.NewUpgradeClusterPreflightActivity(awsSessionFactory).Register()
+	eksworkflow2.NewUpdateClusterVersionActivity(awsSessionFactory).Register()
+	eksworkflow2.NewWaitClusterVersionUpdateActivity(awsSessionFactory).Register()
+	eksworkflow2.NewUpdateClusterAddonsActivity(clientFactory).Register()
+	eksworkflow2.NewSaveClusterVersionActivity(clusterStore).Register()
 
 	return nil
 }
@@ -0,0 +1,53 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupworkflow"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+func registerEtcdBackupWorkflows(
+	config etcdbackup.Config,
+	db *gorm.DB,
+	secretStore secret.Store,
+	kubeConfigs intClusterK8s.KubeConfigGetter,
+	logger common.Logger,
+) {
+	manager := etcdbackup.NewManager(
+		etcdbackupadapter.NewGormStore(db),
+		clusteradapter.NewStore(db, clusteradapter.NewClusters(db)),
+		etcdbackupadapter.NewObjectStoreFactory(secretStore),
+		etcdbackupadapter.NewKubernetesEtcd(kubeConfigs, config.RestoreImage),
+		logger,
+	)
+
+	etcdbackupworkflow.NewSchedulerWorkflow().Register()
+	etcdbackupworkflow.NewCreateSnapshotWorkflow().Register()
+	etcdbackupworkflow.NewRestoreSnapshotWorkflow().Register()
+
+	etcdbackupworkflow.NewScheduleSnapshotsActivity(manager).Register()
+	etcdbackupworkflow.NewTakeSnapshotActivity(manager).Register()
+	etcdbackupworkflow.NewApplyRetentionActivity(manager).Register()
+	etcdbackupworkflow.NewRestoreSnapshotActivity(manager).Register()
+	etcdbackupworkflow.NewRecordFailureActivity(manager).Register()
+}
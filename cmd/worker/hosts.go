@@ -0,0 +1,60 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	hostsworkflow "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/workflow"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow/pkeworkflowadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/kubesecret"
+)
+
+func registerHostsWorkflows(secretStore pkeworkflow.SecretStore, tokenGenerator pkeworkflowadapter.TokenGenerator, store pke.ClusterStore, kubeSecretStore kubesecret.KubeSecretStore) {
+	sshConnector := hostsworkflow.NewSSHConnector(secretStore)
+
+	workflow.RegisterWithOptions(hostsworkflow.CreateClusterWorkflow, workflow.RegisterOptions{Name: hostsworkflow.CreateClusterWorkflowName})
+
+	installNodeActivity := hostsworkflow.MakeInstallNodeActivity(sshConnector, tokenGenerator, store)
+	activity.RegisterWithOptions(installNodeActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.InstallNodeActivityName})
+
+	labelK8sNodeActivity := hostsworkflow.MakeLabelK8sNodeActivity(kubeSecretStore)
+	activity.RegisterWithOptions(labelK8sNodeActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.LabelK8sNodeActivityName})
+
+	setClusterStatusActivity := hostsworkflow.MakeSetClusterStatusActivity(store)
+	activity.RegisterWithOptions(setClusterStatusActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.SetClusterStatusActivityName})
+
+	workflow.RegisterWithOptions(hostsworkflow.UpdateClusterWorkflow, workflow.RegisterOptions{Name: hostsworkflow.UpdateClusterWorkflowName})
+
+	deleteK8sNodeActivity := hostsworkflow.MakeDeleteK8sNodeActivity(kubeSecretStore)
+	activity.RegisterWithOptions(deleteK8sNodeActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.DeleteK8sNodeActivityName})
+
+	resetNodeActivity := hostsworkflow.MakeResetNodeActivity(sshConnector)
+	activity.RegisterWithOptions(resetNodeActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.ResetNodeActivityName})
+
+	deleteHostFromStoreActivity := hostsworkflow.MakeDeleteHostFromStoreActivity(store)
+	activity.RegisterWithOptions(deleteHostFromStoreActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.DeleteHostFromStoreActivityName})
+
+	deleteNodePoolFromStoreActivity := hostsworkflow.MakeDeleteNodePoolFromStoreActivity(store)
+	activity.RegisterWithOptions(deleteNodePoolFromStoreActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.DeleteNodePoolFromStoreActivityName})
+
+	workflow.RegisterWithOptions(hostsworkflow.DeleteClusterWorkflow, workflow.RegisterOptions{Name: hostsworkflow.DeleteClusterWorkflowName})
+
+	deleteClusterFromStoreActivity := hostsworkflow.MakeDeleteClusterFromStoreActivity(store)
+	activity.RegisterWithOptions(deleteClusterFromStoreActivity.Execute, activity.RegisterOptions{Name: hostsworkflow.DeleteClusterFromStoreActivityName})
+}
@@ -65,6 +65,7 @@ import (
 	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
 	"github.com/banzaicloud/pipeline/internal/federation"
 	"github.com/banzaicloud/pipeline/internal/global"
+	"github.com/banzaicloud/pipeline/internal/global/globalsecretusage"
 	"github.com/banzaicloud/pipeline/internal/helm"
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
@@ -78,12 +79,14 @@ import (
 	intsvcingressadapter "github.com/banzaicloud/pipeline/internal/integratedservices/services/ingress/ingressadapter"
 	integratedServiceLogging "github.com/banzaicloud/pipeline/internal/integratedservices/services/logging"
 	integratedServiceMonitoring "github.com/banzaicloud/pipeline/internal/integratedservices/services/monitoring"
+	integratedServicePolicy "github.com/banzaicloud/pipeline/internal/integratedservices/services/policy"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan/securityscanadapter"
 	integratedServiceVault "github.com/banzaicloud/pipeline/internal/integratedservices/services/vault"
 	cgFeatureIstio "github.com/banzaicloud/pipeline/internal/istio/istiofeature"
 	"github.com/banzaicloud/pipeline/internal/kubernetes"
 	"github.com/banzaicloud/pipeline/internal/kubernetes/kubernetesadapter"
+	"github.com/banzaicloud/pipeline/internal/metricsfederation"
 	intpkeworkflowadapter "github.com/banzaicloud/pipeline/internal/pke/workflow/adapter"
 	"github.com/banzaicloud/pipeline/internal/platform/appkit"
 	"github.com/banzaicloud/pipeline/internal/platform/buildinfo"
@@ -91,8 +94,12 @@ import (
 	"github.com/banzaicloud/pipeline/internal/platform/database"
 	"github.com/banzaicloud/pipeline/internal/platform/errorhandler"
 	"github.com/banzaicloud/pipeline/internal/platform/log"
+	"github.com/banzaicloud/pipeline/internal/policy"
+	"github.com/banzaicloud/pipeline/internal/policy/policyadapter"
 	azurePKEAdapter "github.com/banzaicloud/pipeline/internal/providers/azure/pke/adapter"
 	azurepkedriver "github.com/banzaicloud/pipeline/internal/providers/azure/pke/driver"
+	hostsadapter "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/adapter"
+	hostsdriver "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver"
 	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
 	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow/pkeworkflowadapter"
 	vsphereadapter "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/adapter"
@@ -100,14 +107,15 @@ import (
 	"github.com/banzaicloud/pipeline/internal/secret/kubesecret"
 	"github.com/banzaicloud/pipeline/internal/secret/pkesecret"
 	"github.com/banzaicloud/pipeline/internal/secret/restricted"
-	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
 	"github.com/banzaicloud/pipeline/internal/secret/types"
 	anchore "github.com/banzaicloud/pipeline/internal/security"
+	"github.com/banzaicloud/pipeline/internal/security/securityadapter"
 	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/banzaicloud/pipeline/pkg/cloudinfo"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	"github.com/banzaicloud/pipeline/pkg/hook"
 	"github.com/banzaicloud/pipeline/src/auth"
+	"github.com/banzaicloud/pipeline/src/auth/authadapter"
 	"github.com/banzaicloud/pipeline/src/auth/authdriver"
 	"github.com/banzaicloud/pipeline/src/cluster"
 	legacyclusteradapter "github.com/banzaicloud/pipeline/src/cluster/clusteradapter"
@@ -206,12 +214,28 @@ func main() {
 
 	commonLogger := commonadapter.NewContextAwareLogger(logger, appkit.ContextExtractor)
 
-	vaultClient, err := vault.NewClient("pipeline")
-	emperror.Panic(err)
-	global.SetVault(vaultClient)
+	// Vault is only used when it stores the organization secrets
+	var vaultClient *vault.Client
+	if config.Secret.Store.Backend == cmd.SecretStoreBackendVault {
+		client, err := vault.NewClient("pipeline")
+		emperror.Panic(err)
+
+		vaultClient = client
+		global.SetVault(vaultClient)
+	}
+
+	db, err := database.Connect(config.Database.Config)
+	if err != nil {
+		emperror.Panic(err)
+	}
+	global.SetDB(db)
 
-	secretStore := secretadapter.NewVaultStore(vaultClient, "secret")
-	pkeSecreter := pkesecret.NewPkeSecreter(vaultClient, commonLogger)
+	secretStore, err := cmd.CreateSecretStore(config.Secret.Store.Backend, config.Secret.Store, vaultClient, db)
+	emperror.Panic(errors.WithMessage(err, "failed to create secret store"))
+	var pkeSecreter types.PkeSecreter = pkesecret.NewLocalPkeSecreter()
+	if vaultClient != nil {
+		pkeSecreter = pkesecret.NewPkeSecreter(vaultClient, commonLogger)
+	}
 	secretTypes := types.NewDefaultTypeList(types.DefaultTypeListConfig{
 		TLSDefaultValidity: config.Secret.TLS.DefaultValidity,
 		PkeSecreter:        pkeSecreter,
@@ -227,11 +251,7 @@ func main() {
 		worker, err := cadence.NewWorker(config.Cadence, taskList, zaplog.New(logur.WithFields(logger, map[string]interface{}{"component": "cadence-worker"})))
 		emperror.Panic(err)
 
-		db, err := database.Connect(config.Database.Config)
-		if err != nil {
-			emperror.Panic(err)
-		}
-		global.SetDB(db)
+		globalsecretusage.SetIndex(cmd.CreateSecretUsageIndex(db, commonLogger))
 
 		workflowClient, err := cadence.NewClient(config.Cadence, zaplog.New(logur.WithFields(logger, map[string]interface{}{"component": "cadence-client"})))
 		if err != nil {
@@ -254,7 +274,10 @@ func main() {
 			clusteradapter.NewStore(db, clusterRepo),
 			releaseDeleter,
 		)
-		tokenStore := bauth.NewVaultTokenStore("pipeline")
+		var tokenStore bauth.TokenStore = authadapter.NewSecretTokenStore(secretStore)
+		if vaultClient != nil {
+			tokenStore = bauth.NewVaultTokenStore("pipeline")
+		}
 		tokenManager := pkgAuth.NewTokenManager(
 			pkgAuth.NewJWTTokenGenerator(
 				config.Auth.Token.Issuer,
@@ -275,6 +298,42 @@ func main() {
 			commonLogger,
 		)
 
+		registerSecretRotationWorkflows(db, secretStore, secretTypes, clusterManager, commonLogger)
+		registerSecurityWhitelistExpiryWorkflows(config.Cluster.SecurityScan.WhitelistExpiry, db, secretStore, clusterManager, commonLogger)
+		registerSecretBindingWorkflows(
+			db,
+			secretStore,
+			kubernetes.NewService(
+				kubernetesadapter.NewConfigSecretGetter(clusterRepo),
+				kubernetes.NewConfigFactory(commonSecretStore),
+				commonLogger,
+			),
+			commonLogger,
+			logrusLogger,
+		)
+		registerEtcdBackupWorkflows(
+			config.Cluster.EtcdBackup,
+			db,
+			secretStore,
+			kubernetes.NewService(
+				kubernetesadapter.NewConfigSecretGetter(clusterRepo),
+				kubernetes.NewConfigFactory(commonSecretStore),
+				commonLogger,
+			),
+			commonLogger,
+		)
+		registerClusterCertificateWorkflows(
+			config.Cluster.Certificates,
+			db,
+			secretStore,
+			kubernetes.NewService(
+				kubernetesadapter.NewConfigSecretGetter(clusterRepo),
+				kubernetes.NewConfigFactory(commonSecretStore),
+				commonLogger,
+			),
+			commonLogger,
+		)
+
 		clusters := pkeworkflowadapter.NewClusterManagerAdapter(clusterManager)
 		secretStore := pkeworkflowadapter.NewSecretStore(secret.Store)
 
@@ -392,13 +451,19 @@ func main() {
 		registerAzureWorkflows(secretStore, tokenGenerator, azurePKEClusterStore)
 
 		// Register EKS specific workflows
-		err = registerEKSWorkflows(secret.Store, eksClusters)
+		err = registerEKSWorkflows(
+			secret.Store,
+			eksClusters,
+			kubernetes.NewClientFactory(configFactory),
+			eksadapter.NewClusterStore(db),
+		)
 		if err != nil {
 			emperror.Panic(errors.WrapIf(err, "failed to register EKS workflows"))
 		}
 
 		clusterStore := clusteradapter.NewStore(db, clusteradapter.NewClusters(db))
 		vsphereClusterStore := vsphereadapter.NewClusterStore(db)
+		hostsClusterStore := hostsadapter.NewClusterStore(db)
 
 		cgroupAdapter := cgroupAdapter.NewClusterGetter(clusterManager)
 		clusterGroupManager := clustergroup.NewManager(cgroupAdapter, clustergroup.NewClusterGroupRepository(db, logrusLogger), logrusLogger, errorHandler)
@@ -408,9 +473,11 @@ func main() {
 			federationHandler := federation.NewFederationHandler(cgroupAdapter, config.Cluster.Namespace, logrusLogger, errorHandler, config.Cluster.Federation, config.Cluster.DNS.Config, unifiedHelmReleaser)
 			deploymentManager := deployment.NewCGDeploymentManager(db, cgroupAdapter, logrusLogger, errorHandler)
 			serviceMeshFeatureHandler := cgFeatureIstio.NewServiceMeshFeatureHandler(cgroupAdapter, logrusLogger, errorHandler, config.Cluster.Backyards, unifiedHelmReleaser)
+			metricsFederationHandler := metricsfederation.NewHandler(config.Cluster.Monitoring.Namespace, logrusLogger, errorHandler, config.Cluster.MetricsFederation, unifiedHelmReleaser)
 			clusterGroupManager.RegisterFeatureHandler(federation.FeatureName, federationHandler)
 			clusterGroupManager.RegisterFeatureHandler(deployment.FeatureName, deploymentManager)
 			clusterGroupManager.RegisterFeatureHandler(cgFeatureIstio.FeatureName, serviceMeshFeatureHandler)
+			clusterGroupManager.RegisterFeatureHandler(metricsfederation.FeatureName, metricsFederationHandler)
 
 			removeClusterFromGroupActivity := clusterworkflow.MakeRemoveClusterFromGroupActivity(clusterGroupManager)
 			activity.RegisterWithOptions(removeClusterFromGroupActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.RemoveClusterFromGroupActivityName})
@@ -482,6 +549,17 @@ func main() {
 							workflowClient,
 						),
 					},
+					clusteradapter.ClusterDeleterEntry{
+						Key: clusteradapter.MakeClusterDeleterKey(pkgCluster.Hosts, pkgCluster.PKE),
+						Deleter: hostsdriver.MakeClusterDeleter(
+							nil,
+							clusterManager.GetKubeProxyCache(),
+							commonLogger,
+							nil,
+							hostsClusterStore,
+							workflowClient,
+						),
+					},
 				),
 			)
 			activity.RegisterWithOptions(deleteClusterActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.DeleteClusterActivityName})
@@ -522,6 +600,28 @@ func main() {
 
 			setClusterStatusActivity := clusterworkflow.NewSetClusterStatusActivity(clusterStore)
 			activity.RegisterWithOptions(setClusterStatusActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.SetClusterStatusActivityName})
+
+			clientFactory := cluster2.NewClientFactory(clusterStore, kubernetes.NewClientFactory(configFactory))
+
+			listNodePoolNodesActivity := clusterworkflow.NewListNodePoolNodesActivity(clientFactory)
+			activity.RegisterWithOptions(listNodePoolNodesActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.ListNodePoolNodesActivityName})
+
+			getNodeByProviderIDActivity := clusterworkflow.NewGetNodeByProviderIDActivity(clientFactory)
+			activity.RegisterWithOptions(getNodeByProviderIDActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.GetNodeByProviderIDActivityName})
+
+			cordonNodesActivity := clusterworkflow.NewCordonNodesActivity(clientFactory)
+			activity.RegisterWithOptions(cordonNodesActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.CordonNodesActivityName})
+
+			drainNodeActivity := clusterworkflow.NewDrainNodeActivity(
+				clientFactory,
+				intClusterK8s.NodeDrainOptions{
+					GracePeriod: config.Cluster.Drain.GracePeriod,
+					Timeout:     config.Cluster.Drain.Timeout,
+				},
+			)
+			activity.RegisterWithOptions(drainNodeActivity.Execute, activity.RegisterOptions{Name: clusterworkflow.DrainNodeActivityName})
+
+			registerPKEUpgradeWorkflows(config.Distribution.PKE.Upgrade, db, clientFactory, azurePKEClusterStore, vsphereClusterStore)
 		}
 
 		k8sConfigGetter := kubesecret.MakeKubeSecretStore(secret.Store)
@@ -530,6 +630,10 @@ func main() {
 
 		registerVsphereWorkflows(secretStore, tokenGenerator, vsphereClusterStore, k8sConfigGetter)
 
+		// Register workflows of PKE on existing hosts
+
+		registerHostsWorkflows(secretStore, tokenGenerator, hostsClusterStore, k8sConfigGetter)
+
 		generateCertificatesActivity := pkeworkflow.NewGenerateCertificatesActivity(clusterSecretStore)
 		activity.RegisterWithOptions(generateCertificatesActivity.Execute, activity.RegisterOptions{Name: pkeworkflow.GenerateCertificatesActivityName})
 
@@ -620,7 +724,7 @@ func main() {
 				logger,
 			)
 			featureAnchoreService := securityscan.NewIntegratedServiceAnchoreService(anchoreUserService, logger)
-			featureWhitelistService := securityscan.NewIntegratedServiceWhitelistService(clusterGetter, anchore.NewSecurityResourceService(logger), logger)
+			featureWhitelistService := securityscan.NewIntegratedServiceWhitelistService(clusterGetter, anchore.NewSecurityResourceService(anchore.NewKubeConfigClientFactory(), securityadapter.NewGormWhitelistAuditLog(db), logger), logger)
 
 			// expiry integrated service
 			workflow.RegisterWithOptions(expiryWorkflow.ExpiryJobWorkflow, workflow.RegisterOptions{Name: expiryWorkflow.ExpiryJobWorkflowName})
@@ -679,6 +783,15 @@ func main() {
 					logger,
 					commonSecretStore,
 				),
+				integratedServicePolicy.MakeIntegratedServiceOperator(
+					clusterGetter,
+					clusterService,
+					unifiedHelmReleaser,
+					kubernetesService,
+					policy.NewService(policyadapter.NewGormStore(db)),
+					config.Cluster.Policy.Config,
+					logger,
+				),
 				expiry.NewExpiryServiceOperator(expirerService, services.BindIntegratedServiceSpec, logger),
 				intsvcingress.NewOperator(
 					intsvcingressadapter.NewOperatorClusterStore(clusterStore),
@@ -686,6 +799,9 @@ func main() {
 					config.Cluster.Ingress.Config,
 					unifiedHelmReleaser,
 					intsvcingressadapter.NewOrgDomainService(config.Cluster.DNS.BaseDomain, orgGetter),
+					kubernetesService,
+					commonSecretStore,
+					intsvcingressadapter.NewDNSProviderStore(featureRepository),
 				),
 			})
 
@@ -0,0 +1,42 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke/pkeadapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke/pkeworkflow"
+	azurePke "github.com/banzaicloud/pipeline/internal/providers/azure/pke"
+	vspherePKE "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+func registerPKEUpgradeWorkflows(
+	config pke.UpgradeConfig,
+	db *gorm.DB,
+	clientFactory clusterworkflow.ClientFactory,
+	azureClusters azurePke.ClusterStore,
+	vsphereClusters vspherePKE.ClusterStore,
+) {
+	pkeworkflow.NewUpgradeClusterWorkflow(processlog.New()).Register()
+
+	pkeworkflow.NewUpgradeClusterPreflightActivity(clientFactory).Register()
+	pkeworkflow.NewUpgradeNodeActivity(clientFactory, config.Image, config.NodeTimeout).Register()
+	pkeworkflow.NewCheckNodeHealthActivity(clientFactory, config.HealthCheckTimeout).Register()
+	pkeworkflow.NewSaveClusterVersionActivity(pkeadapter.NewClusterStore(db, azureClusters, vsphereClusters)).Register()
+}
@@ -0,0 +1,51 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/clustergroup"
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingworkflow"
+)
+
+func registerSecretBindingWorkflows(
+	db *gorm.DB,
+	secretStore secret.Store,
+	kubernetesService bindingadapter.KubernetesService,
+	logger common.Logger,
+	logrusLogger logrus.FieldLogger,
+) {
+	syncer := binding.NewSyncer(
+		bindingadapter.NewGormStore(db),
+		secretStore,
+		clusteradapter.NewStore(db, clusteradapter.NewClusters(db)),
+		bindingadapter.NewClusterGroupStore(clustergroup.NewClusterGroupRepository(db, logrusLogger)),
+		bindingadapter.NewKubernetesSecretManager(kubernetesService),
+		logger,
+	)
+
+	bindingworkflow.NewSyncSchedulerWorkflow().Register()
+	bindingworkflow.NewSyncBindingWorkflow().Register()
+
+	bindingworkflow.NewListBindingsActivity(syncer).Register()
+	bindingworkflow.NewSyncBindingActivity(syncer).Register()
+}
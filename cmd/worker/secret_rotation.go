@@ -0,0 +1,47 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationworkflow"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+func registerSecretRotationWorkflows(
+	db *gorm.DB,
+	secretStore secret.Store,
+	secretTypes secret.TypeList,
+	clusters rotationadapter.ClusterLister,
+	logger common.Logger,
+) {
+	rotator := rotation.NewRotator(rotationadapter.NewGormStore(db), secretStore, secretTypes, logger)
+
+	rotationworkflow.NewRotationSchedulerWorkflow().Register()
+	rotationworkflow.NewRotateSecretWorkflow(processlog.New()).Register()
+
+	rotationworkflow.NewListDueSecretsActivity(rotator).Register()
+	rotationworkflow.NewGetSecretVersionActivity(rotator).Register()
+	rotationworkflow.NewRotateSecretActivity(rotator).Register()
+	rotationworkflow.NewSyncSecretActivity(rotationadapter.NewClusterSecretSyncer(clusters, logger)).Register()
+	rotationworkflow.NewNotifyActivity(rotationadapter.NewSlackNotifier(secretStore, http.DefaultClient, logger)).Register()
+}
@@ -0,0 +1,52 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	anchore "github.com/banzaicloud/pipeline/internal/security"
+	"github.com/banzaicloud/pipeline/internal/security/securityadapter"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry/whitelistexpiryadapter"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry/whitelistexpiryworkflow"
+)
+
+func registerSecurityWhitelistExpiryWorkflows(
+	config whitelistexpiry.Config,
+	db *gorm.DB,
+	secretStore secret.Store,
+	clusters whitelistexpiryadapter.ClusterGetter,
+	logger common.Logger,
+) {
+	auditLog := securityadapter.NewGormWhitelistAuditLog(db)
+
+	expirer := whitelistexpiry.NewExpirer(
+		config,
+		whitelistexpiryadapter.NewClusterLister(clusters, integratedserviceadapter.NewGormIntegratedServiceRepository(db, logger)),
+		anchore.NewSecurityResourceService(anchore.NewKubeConfigClientFactory(), auditLog, logger),
+		auditLog,
+		whitelistexpiryadapter.NewSlackNotifier(secretStore, http.DefaultClient, logger),
+		logger,
+	)
+
+	whitelistexpiryworkflow.NewExpirySchedulerWorkflow().Register()
+	whitelistexpiryworkflow.NewExpireWhitelistsActivity(expirer).Register()
+}
@@ -0,0 +1,12 @@
+CREATE TABLE `encrypted_secrets` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `secret_id` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `version` int(11) DEFAULT NULL,
+  `encrypted_key` longblob,
+  `ciphertext` longblob,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_encrypted_secrets_org_secret` (`organization_id`,`secret_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+CREATE TABLE `vulnerability_report_trends` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `date` timestamp NULL DEFAULT NULL,
+  `images` int(11) DEFAULT NULL,
+  `unknown_count` int(11) DEFAULT NULL,
+  `negligible_count` int(11) DEFAULT NULL,
+  `low_count` int(11) DEFAULT NULL,
+  `medium_count` int(11) DEFAULT NULL,
+  `high_count` int(11) DEFAULT NULL,
+  `critical_count` int(11) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_vulnerability_report_trends_org_date` (`organization_id`,`date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
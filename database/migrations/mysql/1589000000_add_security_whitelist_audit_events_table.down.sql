@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `security_whitelist_audit_events`;
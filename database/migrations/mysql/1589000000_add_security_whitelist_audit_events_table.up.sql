@@ -0,0 +1,14 @@
+CREATE TABLE `security_whitelist_audit_events` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `item_name` varchar(255) DEFAULT NULL,
+  `action` varchar(255) DEFAULT NULL,
+  `actor` varchar(255) DEFAULT NULL,
+  `owner` varchar(255) DEFAULT NULL,
+  `approver` varchar(255) DEFAULT NULL,
+  `reason` text,
+  `expires_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_security_whitelist_audit_events_cluster` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE `policy_templates` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  `kind` varchar(255) DEFAULT NULL,
+  `description` text,
+  `rego` text,
+  `parameters` text,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_policy_templates_org_name` (`organization_id`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+CREATE TABLE `cluster_kubeconfigs` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `token_id` varchar(255) DEFAULT NULL,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `user_id` int(10) unsigned DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `expires_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_cluster_kubeconfigs_token_id` (`token_id`),
+  KEY `idx_cluster_kubeconfigs_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,26 @@
+CREATE TABLE `secret_bindings` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `namespace` varchar(255) DEFAULT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  `sources` text,
+  `target_cluster_ids` text,
+  `target_cluster_group_id` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_secret_bindings_org_namespace_name` (`organization_id`,`namespace`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `secret_binding_statuses` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `binding_id` int(10) unsigned DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `status` varchar(255) DEFAULT NULL,
+  `message` text,
+  `checksum` varchar(255) DEFAULT NULL,
+  `synced_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_secret_binding_statuses_binding_cluster` (`binding_id`,`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,33 @@
+CREATE TABLE `hosts_pke_clusters` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `spec` json DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_hosts_pke_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `hosts_pke_node_pools` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `created_by` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `roles` json DEFAULT NULL,
+  `labels` json DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_hosts_pke_np_cluster_id_name` (`cluster_id`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `hosts_pke_hosts` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `node_pool_id` int(10) unsigned DEFAULT NULL,
+  `address` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `port` int(11) DEFAULT NULL,
+  `ssh_secret_id` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `labels` json DEFAULT NULL,
+  `node_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `ssh_host_key_fingerprint` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_hosts_pke_host_cluster_id_address` (`cluster_id`,`address`),
+  KEY `idx_hosts_pke_host_node_pool_id` (`node_pool_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
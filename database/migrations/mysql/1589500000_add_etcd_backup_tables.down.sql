@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `etcd_backup_restores`;
+DROP TABLE IF EXISTS `etcd_backup_snapshots`;
+DROP TABLE IF EXISTS `etcd_backup_settings`;
@@ -0,0 +1,50 @@
+CREATE TABLE `etcd_backup_settings` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `enabled` tinyint(1) DEFAULT NULL,
+  `bucket_cloud` varchar(255) DEFAULT NULL,
+  `bucket_name` varchar(255) DEFAULT NULL,
+  `bucket_secret_id` varchar(255) DEFAULT NULL,
+  `bucket_location` varchar(255) DEFAULT NULL,
+  `bucket_resource_group` varchar(255) DEFAULT NULL,
+  `bucket_storage_account` varchar(255) DEFAULT NULL,
+  `snapshot_interval` bigint(20) DEFAULT NULL,
+  `retention_count` int(11) DEFAULT NULL,
+  `retention_period` bigint(20) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_etcd_backup_settings_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `etcd_backup_snapshots` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `status` varchar(255) DEFAULT NULL,
+  `status_message` text,
+  `trigger` varchar(255) DEFAULT NULL,
+  `bucket_cloud` varchar(255) DEFAULT NULL,
+  `bucket_name` varchar(255) DEFAULT NULL,
+  `bucket_secret_id` varchar(255) DEFAULT NULL,
+  `bucket_location` varchar(255) DEFAULT NULL,
+  `bucket_resource_group` varchar(255) DEFAULT NULL,
+  `bucket_storage_account` varchar(255) DEFAULT NULL,
+  `object_key` varchar(255) DEFAULT NULL,
+  `size` bigint(20) DEFAULT NULL,
+  `completed_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_etcd_backup_snapshots_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `etcd_backup_restores` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `snapshot_id` int(10) unsigned DEFAULT NULL,
+  `status` varchar(255) DEFAULT NULL,
+  `status_message` text,
+  `started_at` timestamp NULL DEFAULT NULL,
+  `finished_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_etcd_backup_restores_cluster_id` (`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `cluster_certificate_rotations`;
+DROP TABLE IF EXISTS `cluster_certificate_settings`;
+DROP TABLE IF EXISTS `cluster_certificates`;
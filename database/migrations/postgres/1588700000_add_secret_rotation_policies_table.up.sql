@@ -0,0 +1,15 @@
+CREATE TABLE "secret_rotation_policies" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "organization_id" integer,
+  "secret_id" text,
+  "interval_days" integer,
+  "days_before_expiry" integer,
+  "notification_secret_id" text,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_secret_rotation_policies_org_secret ON "secret_rotation_policies"(
+  "organization_id", "secret_id"
+);
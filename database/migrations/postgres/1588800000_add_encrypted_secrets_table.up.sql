@@ -0,0 +1,15 @@
+CREATE TABLE "encrypted_secrets" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "organization_id" integer,
+  "secret_id" text,
+  "version" integer,
+  "encrypted_key" bytea,
+  "ciphertext" bytea,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_encrypted_secrets_org_secret ON "encrypted_secrets"(
+  "organization_id", "secret_id"
+);
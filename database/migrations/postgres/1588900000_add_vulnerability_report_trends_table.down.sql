@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "vulnerability_report_trends";
@@ -0,0 +1,17 @@
+CREATE TABLE "vulnerability_report_trends" (
+  "id" serial,
+  "organization_id" integer,
+  "date" timestamp with time zone,
+  "images" integer,
+  "unknown_count" integer,
+  "negligible_count" integer,
+  "low_count" integer,
+  "medium_count" integer,
+  "high_count" integer,
+  "critical_count" integer,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_vulnerability_report_trends_org_date ON "vulnerability_report_trends"(
+  "organization_id", "date"
+);
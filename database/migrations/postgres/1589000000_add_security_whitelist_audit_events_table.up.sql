@@ -0,0 +1,17 @@
+CREATE TABLE "security_whitelist_audit_events" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "cluster_id" integer,
+  "item_name" text,
+  "action" text,
+  "actor" text,
+  "owner" text,
+  "approver" text,
+  "reason" text,
+  "expires_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_security_whitelist_audit_events_cluster ON "security_whitelist_audit_events"(
+  "cluster_id"
+);
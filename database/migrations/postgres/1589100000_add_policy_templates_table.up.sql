@@ -0,0 +1,16 @@
+CREATE TABLE "policy_templates" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "organization_id" integer,
+  "name" text,
+  "kind" text,
+  "description" text,
+  "rego" text,
+  "parameters" text,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_policy_templates_org_name ON "policy_templates"(
+  "organization_id", "name"
+);
@@ -0,0 +1,13 @@
+CREATE TABLE "cluster_kubeconfigs" (
+  "id" serial,
+  "token_id" text,
+  "organization_id" integer,
+  "cluster_id" integer,
+  "user_id" integer,
+  "created_at" timestamp with time zone,
+  "expires_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_cluster_kubeconfigs_token_id ON "cluster_kubeconfigs"("token_id");
+CREATE INDEX idx_cluster_kubeconfigs_cluster_id ON "cluster_kubeconfigs"("cluster_id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "secret_binding_statuses";
+DROP TABLE IF EXISTS "secret_bindings";
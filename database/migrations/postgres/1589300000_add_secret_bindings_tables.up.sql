@@ -0,0 +1,32 @@
+CREATE TABLE "secret_bindings" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "organization_id" integer,
+  "namespace" text,
+  "name" text,
+  "sources" text,
+  "target_cluster_ids" text,
+  "target_cluster_group_id" integer,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_secret_bindings_org_namespace_name ON "secret_bindings"(
+  "organization_id", "namespace", "name"
+);
+
+CREATE TABLE "secret_binding_statuses" (
+  "id" serial,
+  "updated_at" timestamp with time zone,
+  "binding_id" integer,
+  "cluster_id" integer,
+  "status" text,
+  "message" text,
+  "checksum" text,
+  "synced_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_secret_binding_statuses_binding_cluster ON "secret_binding_statuses"(
+  "binding_id", "cluster_id"
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "hosts_pke_hosts";
+DROP TABLE IF EXISTS "hosts_pke_node_pools";
+DROP TABLE IF EXISTS "hosts_pke_clusters";
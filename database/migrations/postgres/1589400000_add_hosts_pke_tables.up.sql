@@ -0,0 +1,36 @@
+CREATE TABLE "hosts_pke_clusters" (
+  "id" serial,
+  "cluster_id" integer,
+  "spec" json,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_hosts_pke_cluster_id ON "hosts_pke_clusters"(cluster_id);
+
+CREATE TABLE "hosts_pke_node_pools" (
+  "id" serial,
+  "cluster_id" integer,
+  "created_by" integer,
+  "name" text,
+  "roles" json,
+  "labels" json,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_hosts_pke_np_cluster_id_name ON "hosts_pke_node_pools"(cluster_id, "name");
+
+CREATE TABLE "hosts_pke_hosts" (
+  "id" serial,
+  "cluster_id" integer,
+  "node_pool_id" integer,
+  "address" text,
+  "port" integer,
+  "ssh_secret_id" text,
+  "labels" json,
+  "node_name" text,
+  "ssh_host_key_fingerprint" text,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_hosts_pke_host_cluster_id_address ON "hosts_pke_hosts"(cluster_id, "address");
+CREATE INDEX idx_hosts_pke_host_node_pool_id ON "hosts_pke_hosts"(node_pool_id);
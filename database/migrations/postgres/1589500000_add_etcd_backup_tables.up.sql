@@ -0,0 +1,53 @@
+CREATE TABLE "etcd_backup_settings" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "cluster_id" integer,
+  "enabled" boolean,
+  "bucket_cloud" text,
+  "bucket_name" text,
+  "bucket_secret_id" text,
+  "bucket_location" text,
+  "bucket_resource_group" text,
+  "bucket_storage_account" text,
+  "snapshot_interval" bigint,
+  "retention_count" integer,
+  "retention_period" bigint,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_etcd_backup_settings_cluster_id ON "etcd_backup_settings"("cluster_id");
+
+CREATE TABLE "etcd_backup_snapshots" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "cluster_id" integer,
+  "status" text,
+  "status_message" text,
+  "trigger" text,
+  "bucket_cloud" text,
+  "bucket_name" text,
+  "bucket_secret_id" text,
+  "bucket_location" text,
+  "bucket_resource_group" text,
+  "bucket_storage_account" text,
+  "object_key" text,
+  "size" bigint,
+  "completed_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_etcd_backup_snapshots_cluster_id ON "etcd_backup_snapshots"("cluster_id");
+
+CREATE TABLE "etcd_backup_restores" (
+  "id" serial,
+  "cluster_id" integer,
+  "snapshot_id" integer,
+  "status" text,
+  "status_message" text,
+  "started_at" timestamp with time zone,
+  "finished_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_etcd_backup_restores_cluster_id ON "etcd_backup_restores"("cluster_id");
@@ -0,0 +1,35 @@
+CREATE TABLE "cluster_certificates" (
+  "id" serial,
+  "cluster_id" integer,
+  "node" text,
+  "name" text,
+  "subject" text,
+  "expires_at" timestamp with time zone,
+  "checked_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_cluster_certificates_cluster_id ON "cluster_certificates"("cluster_id");
+
+CREATE TABLE "cluster_certificate_settings" (
+  "id" serial,
+  "created_at" timestamp with time zone,
+  "updated_at" timestamp with time zone,
+  "cluster_id" integer,
+  "notification_secret_id" text,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX idx_cluster_certificate_settings_cluster_id ON "cluster_certificate_settings"("cluster_id");
+
+CREATE TABLE "cluster_certificate_rotations" (
+  "id" serial,
+  "cluster_id" integer,
+  "status" text,
+  "status_message" text,
+  "started_at" timestamp with time zone,
+  "finished_at" timestamp with time zone,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX idx_cluster_certificate_rotations_cluster_id ON "cluster_certificate_rotations"("cluster_id");
@@ -0,0 +1,360 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+// Config contains configuration for kubeconfigs issued by Pipeline.
+type Config struct {
+	// DefaultTTL is the lifetime of a kubeconfig when none is requested.
+	DefaultTTL time.Duration
+
+	// MaxTTL is the longest lifetime a kubeconfig can be requested with.
+	MaxTTL time.Duration
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	var err error
+
+	if c.DefaultTTL <= 0 {
+		err = errors.Append(err, errors.New("kubeconfig default TTL must be positive"))
+	}
+
+	if c.MaxTTL < c.DefaultTTL {
+		err = errors.Append(err, errors.New("kubeconfig max TTL cannot be less than the default TTL"))
+	}
+
+	return err
+}
+
+// Kubeconfig describes a kubeconfig issued by Pipeline.
+type Kubeconfig struct {
+	ID             string    `json:"id"`
+	OrganizationID uint      `json:"-"`
+	ClusterID      uint      `json:"clusterId"`
+	UserID         uint      `json:"userId"`
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// IssueKubeconfigRequest contains the parameters of a new kubeconfig.
+type IssueKubeconfigRequest struct {
+	// TTL is the requested lifetime of the kubeconfig (the default is used when zero).
+	TTL time.Duration
+}
+
+// IssuedKubeconfig is a newly issued kubeconfig.
+type IssuedKubeconfig struct {
+	ID         string    `json:"id"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Kubeconfig string    `json:"kubeconfig"`
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service issues and revokes short-lived kubeconfigs that access clusters through the Kubernetes API proxy of Pipeline.
+type Service interface {
+	// IssueKubeconfig issues a kubeconfig for the current user.
+	IssueKubeconfig(ctx context.Context, clusterID uint, request IssueKubeconfigRequest) (kubeconfig IssuedKubeconfig, err error)
+
+	// ListKubeconfigs lists the kubeconfigs issued for a cluster that have not expired yet.
+	ListKubeconfigs(ctx context.Context, clusterID uint) (kubeconfigs []Kubeconfig, err error)
+
+	// RevokeKubeconfig revokes a kubeconfig issued for a cluster.
+	RevokeKubeconfig(ctx context.Context, clusterID uint, id string) error
+
+	// RevokeKubeconfigs revokes every kubeconfig issued for a cluster.
+	RevokeKubeconfigs(ctx context.Context, clusterID uint) error
+}
+
+// ClusterStore provides cluster details.
+type ClusterStore interface {
+	// GetCluster returns a generic representation of a cluster.
+	GetCluster(ctx context.Context, id uint) (cluster.Cluster, error)
+}
+
+// UserExtractor extracts user information from the context.
+type UserExtractor interface {
+	// GetUserID returns the ID of the currently authenticated user.
+	// If a user cannot be found in the context, it returns false as the second return value.
+	GetUserID(ctx context.Context) (uint, bool)
+}
+
+// TokenGenerator generates tokens that can only be used for accessing a single cluster.
+type TokenGenerator interface {
+	// GenerateKubeconfigToken generates and stores a token of a user that expires at the given time.
+	GenerateKubeconfigToken(userID uint, orgID uint, clusterID uint, expiresAt time.Time, name string) (string, string, error)
+}
+
+// TokenRevoker revokes tokens.
+type TokenRevoker interface {
+	// Revoke revokes a token of a user.
+	Revoke(userID string, tokenID string) error
+}
+
+// KubernetesService ensures objects in clusters.
+type KubernetesService interface {
+	// EnsureObject makes sure that a given Object is on the cluster.
+	EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error
+}
+
+// +testify:mock:testOnly=true
+
+// Store keeps track of issued kubeconfigs.
+type Store interface {
+	// Create records an issued kubeconfig.
+	Create(ctx context.Context, kubeconfig Kubeconfig) error
+
+	// Get returns a kubeconfig issued for a cluster.
+	Get(ctx context.Context, clusterID uint, id string) (Kubeconfig, error)
+
+	// List lists the kubeconfigs issued for a cluster.
+	List(ctx context.Context, clusterID uint) ([]Kubeconfig, error)
+
+	// Delete deletes the record of an issued kubeconfig.
+	Delete(ctx context.Context, clusterID uint, id string) error
+}
+
+// NotFoundError is returned if a kubeconfig cannot be found.
+type NotFoundError struct {
+	ClusterID uint
+	ID        string
+}
+
+// Error implements the error interface.
+func (NotFoundError) Error() string {
+	return "kubeconfig not found"
+}
+
+// Details returns error details.
+func (e NotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID, "kubeconfigId", e.ID}
+}
+
+// NotFound tells a client that this error is related to a resource being not found.
+// Can be used to translate the error to eg. status code.
+func (NotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the transport layer whether this error should be translated into the transport format
+// or an internal error should be returned instead.
+func (NotFoundError) ServiceError() bool {
+	return true
+}
+
+// NewService returns a new Service.
+func NewService(
+	config Config,
+	externalURL string,
+	externalURLInsecure bool,
+	clusters ClusterStore,
+	users UserExtractor,
+	tokens TokenGenerator,
+	revoker TokenRevoker,
+	kubernetes KubernetesService,
+	store Store,
+) Service {
+	return service{
+		config:              config,
+		externalURL:         strings.TrimSuffix(externalURL, "/"),
+		externalURLInsecure: externalURLInsecure,
+		clusters:            clusters,
+		users:               users,
+		tokens:              tokens,
+		revoker:             revoker,
+		kubernetes:          kubernetes,
+		store:               store,
+	}
+}
+
+type service struct {
+	config              Config
+	externalURL         string
+	externalURLInsecure bool
+	clusters            ClusterStore
+	users               UserExtractor
+	tokens              TokenGenerator
+	revoker             TokenRevoker
+	kubernetes          KubernetesService
+	store               Store
+}
+
+func (s service) IssueKubeconfig(ctx context.Context, clusterID uint, request IssueKubeconfigRequest) (IssuedKubeconfig, error) {
+	userID, ok := s.users.GetUserID(ctx)
+	if !ok {
+		return IssuedKubeconfig{}, errors.New("user not found in the context")
+	}
+
+	ttl := request.TTL
+	if ttl == 0 {
+		ttl = s.config.DefaultTTL
+	}
+
+	if ttl < 0 || ttl > s.config.MaxTTL {
+		return IssuedKubeconfig{}, NewValidationError(
+			"invalid kubeconfig TTL",
+			[]string{fmt.Sprintf("TTL must be positive and cannot exceed %s", s.config.MaxTTL)},
+		)
+	}
+
+	c, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return IssuedKubeconfig{}, err
+	}
+
+	// Members are mapped to a group that needs a binding in the cluster, admins are not
+	err = s.kubernetes.EnsureObject(ctx, clusterID, memberClusterRoleBinding())
+	if err != nil {
+		return IssuedKubeconfig{}, errors.WrapIfWithDetails(err, "failed to ensure cluster role binding for members", "clusterId", clusterID)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	tokenID, token, err := s.tokens.GenerateKubeconfigToken(userID, c.OrganizationID, c.ID, expiresAt, fmt.Sprintf("kubeconfig-%s", c.Name))
+	if err != nil {
+		return IssuedKubeconfig{}, errors.WrapIf(err, "failed to generate kubeconfig token")
+	}
+
+	err = s.store.Create(ctx, Kubeconfig{
+		ID:             tokenID,
+		OrganizationID: c.OrganizationID,
+		ClusterID:      c.ID,
+		UserID:         userID,
+		CreatedAt:      now,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return IssuedKubeconfig{}, err
+	}
+
+	kubeconfig, err := s.kubeconfig(c, token)
+	if err != nil {
+		return IssuedKubeconfig{}, err
+	}
+
+	return IssuedKubeconfig{
+		ID:         tokenID,
+		ExpiresAt:  expiresAt,
+		Kubeconfig: kubeconfig,
+	}, nil
+}
+
+func (s service) kubeconfig(c cluster.Cluster, token string) (string, error) {
+	contextName := fmt.Sprintf("pipeline@%s", c.Name)
+
+	config := clientcmdapi.Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters: []clientcmdapi.NamedCluster{
+			{
+				Name: c.Name,
+				Cluster: clientcmdapi.Cluster{
+					Server:                fmt.Sprintf("%s/api/v1/orgs/%d/clusters/%d/proxy", s.externalURL, c.OrganizationID, c.ID),
+					InsecureSkipTLSVerify: s.externalURLInsecure,
+				},
+			},
+		},
+		Contexts: []clientcmdapi.NamedContext{
+			{
+				Name: contextName,
+				Context: clientcmdapi.Context{
+					AuthInfo: contextName,
+					Cluster:  c.Name,
+				},
+			},
+		},
+		AuthInfos: []clientcmdapi.NamedAuthInfo{
+			{
+				Name: contextName,
+				AuthInfo: clientcmdapi.AuthInfo{
+					Token: token,
+				},
+			},
+		},
+		CurrentContext: contextName,
+	}
+
+	kubeconfig, err := yaml.Marshal(config)
+	if err != nil {
+		return "", errors.WrapIf(err, "failed to encode kubeconfig")
+	}
+
+	return string(kubeconfig), nil
+}
+
+func (s service) ListKubeconfigs(ctx context.Context, clusterID uint) ([]Kubeconfig, error) {
+	kubeconfigs, err := s.store.List(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]Kubeconfig, 0, len(kubeconfigs))
+
+	for _, kubeconfig := range kubeconfigs {
+		if kubeconfig.ExpiresAt.After(now) {
+			active = append(active, kubeconfig)
+		}
+	}
+
+	return active, nil
+}
+
+func (s service) RevokeKubeconfig(ctx context.Context, clusterID uint, id string) error {
+	kubeconfig, err := s.store.Get(ctx, clusterID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.revoke(ctx, kubeconfig)
+}
+
+func (s service) RevokeKubeconfigs(ctx context.Context, clusterID uint) error {
+	kubeconfigs, err := s.store.List(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	for _, kubeconfig := range kubeconfigs {
+		err = errors.Append(err, s.revoke(ctx, kubeconfig))
+	}
+
+	return err
+}
+
+func (s service) revoke(ctx context.Context, kubeconfig Kubeconfig) error {
+	err := s.revoker.Revoke(fmt.Sprint(kubeconfig.UserID), kubeconfig.ID)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to revoke kubeconfig token", "kubeconfigId", kubeconfig.ID)
+	}
+
+	return s.store.Delete(ctx, kubeconfig.ClusterID, kubeconfig.ID)
+}
@@ -0,0 +1,190 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+type clusterStoreStub struct{}
+
+func (clusterStoreStub) GetCluster(ctx context.Context, id uint) (cluster.Cluster, error) {
+	return cluster.Cluster{ID: id, OrganizationID: 1, Name: "my-cluster"}, nil
+}
+
+type userExtractorStub struct{}
+
+func (userExtractorStub) GetUserID(ctx context.Context) (uint, bool) {
+	return 3, true
+}
+
+type tokenGeneratorStub struct {
+	expiresAt time.Time
+}
+
+func (g *tokenGeneratorStub) GenerateKubeconfigToken(userID uint, orgID uint, clusterID uint, expiresAt time.Time, name string) (string, string, error) {
+	g.expiresAt = expiresAt
+
+	return "tokenId", "token", nil
+}
+
+type tokenRevokerStub struct {
+	revoked []string
+}
+
+func (r *tokenRevokerStub) Revoke(userID string, tokenID string) error {
+	r.revoked = append(r.revoked, userID+"/"+tokenID)
+
+	return nil
+}
+
+type kubernetesServiceStub struct {
+	objects []runtime.Object
+}
+
+func (s *kubernetesServiceStub) EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	s.objects = append(s.objects, o)
+
+	return nil
+}
+
+func newTestService(store Store, tokens *tokenGeneratorStub, revoker *tokenRevokerStub, kubernetes *kubernetesServiceStub) Service {
+	return NewService(
+		Config{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour},
+		"https://pipeline.example.com/pipeline/",
+		false,
+		clusterStoreStub{},
+		userExtractorStub{},
+		tokens,
+		revoker,
+		kubernetes,
+		store,
+	)
+}
+
+func TestService_IssueKubeconfig(t *testing.T) {
+	store := new(MockStore)
+	store.On("Create", mock.Anything, mock.MatchedBy(func(kubeconfig Kubeconfig) bool {
+		return kubeconfig.ID == "tokenId" && kubeconfig.OrganizationID == 1 && kubeconfig.ClusterID == 2 && kubeconfig.UserID == 3
+	})).Return(nil)
+
+	tokens := &tokenGeneratorStub{}
+	kubernetes := &kubernetesServiceStub{}
+
+	service := newTestService(store, tokens, &tokenRevokerStub{}, kubernetes)
+
+	issued, err := service.IssueKubeconfig(context.Background(), 2, IssueKubeconfigRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "tokenId", issued.ID)
+	assert.Equal(t, tokens.expiresAt, issued.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), issued.ExpiresAt, time.Minute)
+	assert.Equal(t, []runtime.Object{memberClusterRoleBinding()}, kubernetes.objects)
+
+	var config clientcmdapi.Config
+	err = yaml.Unmarshal([]byte(issued.Kubeconfig), &config)
+	require.NoError(t, err)
+
+	require.Len(t, config.Clusters, 1)
+	assert.Equal(t, "https://pipeline.example.com/pipeline/api/v1/orgs/1/clusters/2/proxy", config.Clusters[0].Cluster.Server)
+	require.Len(t, config.AuthInfos, 1)
+	assert.Equal(t, "token", config.AuthInfos[0].AuthInfo.Token)
+	assert.Equal(t, "pipeline@my-cluster", config.CurrentContext)
+
+	store.AssertExpectations(t)
+}
+
+func TestService_IssueKubeconfig_InvalidTTL(t *testing.T) {
+	service := newTestService(new(MockStore), &tokenGeneratorStub{}, &tokenRevokerStub{}, &kubernetesServiceStub{})
+
+	_, err := service.IssueKubeconfig(context.Background(), 2, IssueKubeconfigRequest{TTL: 48 * time.Hour})
+	require.Error(t, err)
+
+	assert.True(t, errors.As(err, &ValidationError{}))
+}
+
+func TestService_ListKubeconfigs(t *testing.T) {
+	active := Kubeconfig{ID: "active", ClusterID: 2, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	expired := Kubeconfig{ID: "expired", ClusterID: 2, UserID: 3, ExpiresAt: time.Now().Add(-time.Hour)}
+
+	store := new(MockStore)
+	store.On("List", mock.Anything, uint(2)).Return([]Kubeconfig{active, expired}, nil)
+
+	service := newTestService(store, &tokenGeneratorStub{}, &tokenRevokerStub{}, &kubernetesServiceStub{})
+
+	kubeconfigs, err := service.ListKubeconfigs(context.Background(), 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Kubeconfig{active}, kubeconfigs)
+}
+
+func TestService_RevokeKubeconfig(t *testing.T) {
+	store := new(MockStore)
+	store.On("Get", mock.Anything, uint(2), "tokenId").Return(Kubeconfig{ID: "tokenId", ClusterID: 2, UserID: 3}, nil)
+	store.On("Delete", mock.Anything, uint(2), "tokenId").Return(nil)
+
+	revoker := &tokenRevokerStub{}
+
+	service := newTestService(store, &tokenGeneratorStub{}, revoker, &kubernetesServiceStub{})
+
+	err := service.RevokeKubeconfig(context.Background(), 2, "tokenId")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"3/tokenId"}, revoker.revoked)
+
+	store.AssertExpectations(t)
+}
+
+func TestService_RevokeKubeconfig_NotFound(t *testing.T) {
+	store := new(MockStore)
+	store.On("Get", mock.Anything, uint(2), "tokenId").Return(Kubeconfig{}, NotFoundError{ClusterID: 2, ID: "tokenId"})
+
+	service := newTestService(store, &tokenGeneratorStub{}, &tokenRevokerStub{}, &kubernetesServiceStub{})
+
+	err := service.RevokeKubeconfig(context.Background(), 2, "tokenId")
+	require.Error(t, err)
+
+	assert.True(t, errors.As(err, &NotFoundError{}))
+}
+
+func TestService_RevokeKubeconfigs(t *testing.T) {
+	store := new(MockStore)
+	store.On("List", mock.Anything, uint(2)).Return([]Kubeconfig{{ID: "a", ClusterID: 2, UserID: 3}, {ID: "b", ClusterID: 2, UserID: 4}}, nil)
+	store.On("Delete", mock.Anything, uint(2), "a").Return(nil)
+	store.On("Delete", mock.Anything, uint(2), "b").Return(nil)
+
+	revoker := &tokenRevokerStub{}
+
+	service := newTestService(store, &tokenGeneratorStub{}, revoker, &kubernetesServiceStub{})
+
+	err := service.RevokeKubeconfigs(context.Background(), 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"3/a", "4/b"}, revoker.revoked)
+
+	store.AssertExpectations(t)
+}
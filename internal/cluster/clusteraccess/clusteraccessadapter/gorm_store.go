@@ -0,0 +1,134 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccessadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+)
+
+// kubeconfigModel describes the issued kubeconfig model.
+type kubeconfigModel struct {
+	ID             uint   `gorm:"primary_key"`
+	TokenID        string `gorm:"unique_index:idx_cluster_kubeconfigs_token_id"`
+	OrganizationID uint
+	ClusterID      uint `gorm:"index:idx_cluster_kubeconfigs_cluster_id"`
+	UserID         uint
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+// TableName changes the default table name.
+func (kubeconfigModel) TableName() string {
+	return "cluster_kubeconfigs"
+}
+
+// GormStore is an issued kubeconfig store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// Create implements the clusteraccess.Store interface.
+func (s GormStore) Create(ctx context.Context, kubeconfig clusteraccess.Kubeconfig) error {
+	model := kubeconfigModel{
+		TokenID:        kubeconfig.ID,
+		OrganizationID: kubeconfig.OrganizationID,
+		ClusterID:      kubeconfig.ClusterID,
+		UserID:         kubeconfig.UserID,
+		CreatedAt:      kubeconfig.CreatedAt,
+		ExpiresAt:      kubeconfig.ExpiresAt,
+	}
+
+	if err := s.db.Create(&model).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to save issued kubeconfig", "clusterId", kubeconfig.ClusterID)
+	}
+
+	return nil
+}
+
+// Get implements the clusteraccess.Store interface.
+func (s GormStore) Get(ctx context.Context, clusterID uint, id string) (clusteraccess.Kubeconfig, error) {
+	var model kubeconfigModel
+
+	err := s.db.Where(kubeconfigModel{ClusterID: clusterID, TokenID: id}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return clusteraccess.Kubeconfig{}, errors.WithStack(clusteraccess.NotFoundError{
+			ClusterID: clusterID,
+			ID:        id,
+		})
+	} else if err != nil {
+		return clusteraccess.Kubeconfig{}, errors.WrapIfWithDetails(
+			err, "failed to get issued kubeconfig",
+			"clusterId", clusterID,
+			"kubeconfigId", id,
+		)
+	}
+
+	return toKubeconfig(model), nil
+}
+
+// List implements the clusteraccess.Store interface.
+func (s GormStore) List(ctx context.Context, clusterID uint) ([]clusteraccess.Kubeconfig, error) {
+	var models []kubeconfigModel
+
+	if err := s.db.Where(kubeconfigModel{ClusterID: clusterID}).Order("created_at").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list issued kubeconfigs", "clusterId", clusterID)
+	}
+
+	kubeconfigs := make([]clusteraccess.Kubeconfig, 0, len(models))
+
+	for _, model := range models {
+		kubeconfigs = append(kubeconfigs, toKubeconfig(model))
+	}
+
+	return kubeconfigs, nil
+}
+
+// Delete implements the clusteraccess.Store interface.
+func (s GormStore) Delete(ctx context.Context, clusterID uint, id string) error {
+	err := s.db.Where(kubeconfigModel{ClusterID: clusterID, TokenID: id}).Delete(kubeconfigModel{}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to delete issued kubeconfig",
+			"clusterId", clusterID,
+			"kubeconfigId", id,
+		)
+	}
+
+	return nil
+}
+
+func toKubeconfig(model kubeconfigModel) clusteraccess.Kubeconfig {
+	return clusteraccess.Kubeconfig{
+		ID:             model.TokenID,
+		OrganizationID: model.OrganizationID,
+		ClusterID:      model.ClusterID,
+		UserID:         model.UserID,
+		CreatedAt:      model.CreatedAt,
+		ExpiresAt:      model.ExpiresAt,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccessadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+	"github.com/banzaicloud/pipeline/internal/common"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.Get(ctx, 2, "tokenId")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &clusteraccess.NotFoundError{}))
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	kubeconfig := clusteraccess.Kubeconfig{
+		ID:             "tokenId",
+		OrganizationID: 1,
+		ClusterID:      2,
+		UserID:         3,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(time.Hour),
+	}
+
+	err = store.Create(ctx, kubeconfig)
+	require.NoError(t, err)
+
+	err = store.Create(ctx, clusteraccess.Kubeconfig{ID: "otherTokenId", OrganizationID: 1, ClusterID: 4, UserID: 3})
+	require.NoError(t, err)
+
+	stored, err := store.Get(ctx, 2, "tokenId")
+	require.NoError(t, err)
+	assert.Equal(t, kubeconfig.ID, stored.ID)
+	assert.Equal(t, kubeconfig.UserID, stored.UserID)
+	assert.True(t, kubeconfig.ExpiresAt.Equal(stored.ExpiresAt))
+
+	kubeconfigs, err := store.List(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, kubeconfigs, 1)
+	assert.Equal(t, "tokenId", kubeconfigs[0].ID)
+
+	err = store.Delete(ctx, 2, "tokenId")
+	require.NoError(t, err)
+
+	kubeconfigs, err = store.List(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, kubeconfigs)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccessdriver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodPost).Path("").Handler(kithttp.NewServer(
+		endpoints.IssueKubeconfig,
+		decodeIssueKubeconfigHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeIssueKubeconfigHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("").Handler(kithttp.NewServer(
+		endpoints.ListKubeconfigs,
+		decodeListKubeconfigsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListKubeconfigsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("").Handler(kithttp.NewServer(
+		endpoints.RevokeKubeconfigs,
+		decodeRevokeKubeconfigsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("/{kubeconfigId}").Handler(kithttp.NewServer(
+		endpoints.RevokeKubeconfig,
+		decodeRevokeKubeconfigHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+}
+
+func decodeClusterID(r *http.Request) (uint, error) {
+	clusterIDStr, ok := mux.Vars(r)["clusterId"]
+	if !ok || clusterIDStr == "" {
+		return 0, errors.NewWithDetails("missing parameter from the URL", "param", "clusterId")
+	}
+
+	clusterID, err := strconv.ParseUint(clusterIDStr, 0, 0)
+	if err != nil {
+		return 0, errors.WrapIf(err, "invalid cluster ID format")
+	}
+
+	return uint(clusterID), nil
+}
+
+type issueKubeconfigRequestBody struct {
+	TTL string `json:"ttl"`
+}
+
+func decodeIssueKubeconfigHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var body issueKubeconfigRequestBody
+
+	// The request body is optional
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	var request clusteraccess.IssueKubeconfigRequest
+
+	if body.TTL != "" {
+		request.TTL, err = time.ParseDuration(body.TTL)
+		if err != nil {
+			return nil, clusteraccess.NewValidationError("invalid kubeconfig TTL", []string{err.Error()})
+		}
+	}
+
+	return IssueKubeconfigRequest{ClusterID: clusterID, Request: request}, nil
+}
+
+func encodeIssueKubeconfigHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(IssueKubeconfigResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Kubeconfig)
+}
+
+func decodeListKubeconfigsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListKubeconfigsRequest{ClusterID: clusterID}, nil
+}
+
+func encodeListKubeconfigsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListKubeconfigsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Kubeconfigs)
+}
+
+func decodeRevokeKubeconfigsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return RevokeKubeconfigsRequest{ClusterID: clusterID}, nil
+}
+
+func decodeRevokeKubeconfigHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := mux.Vars(r)["kubeconfigId"]
+	if !ok || id == "" {
+		return nil, errors.NewWithDetails("missing parameter from the URL", "param", "kubeconfigId")
+	}
+
+	return RevokeKubeconfigRequest{ClusterID: clusterID, Id: id}, nil
+}
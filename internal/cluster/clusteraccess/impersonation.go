@@ -0,0 +1,136 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"emperror.dev/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+// Kubernetes groups the Pipeline organization roles are mapped to in the Kubernetes API proxy.
+const (
+	// AdminGroup gives cluster admin access without a binding in the cluster.
+	AdminGroup = "system:masters"
+
+	// MemberGroup is bound to MemberClusterRole by Pipeline.
+	MemberGroup = "pipeline:members"
+)
+
+// MemberClusterRole is the cluster role granting read-only access to members.
+const MemberClusterRole = "view"
+
+const memberClusterRoleBindingName = "pipeline-members-view"
+
+// GroupsForRole returns the Kubernetes groups of a Pipeline organization role.
+func GroupsForRole(role string) []string {
+	switch role {
+	case auth.RoleAdmin:
+		return []string{AdminGroup}
+	case auth.RoleMember:
+		return []string{MemberGroup}
+	default:
+		return nil
+	}
+}
+
+// UserName returns the Kubernetes user name of a Pipeline user.
+func UserName(login string) string {
+	return "pipeline:" + login
+}
+
+// Impersonate replaces the impersonation headers of a request proxied to a cluster
+// so that it is authorized as the given user and groups instead of the cluster credentials of Pipeline.
+func Impersonate(header http.Header, userName string, groups []string) {
+	RemoveImpersonation(header)
+
+	header.Set("Impersonate-User", userName)
+
+	for _, group := range groups {
+		header.Add("Impersonate-Group", group)
+	}
+}
+
+// RemoveImpersonation removes every impersonation header of a request, including the ones set by the client.
+func RemoveImpersonation(header http.Header) {
+	for key := range header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(key), "Impersonate-") {
+			header.Del(key)
+		}
+	}
+}
+
+// MemberAccess makes sure that MemberGroup is bound in the clusters members are impersonated in.
+type MemberAccess struct {
+	kubernetes KubernetesService
+
+	// ensured holds the IDs of the clusters where the binding is already ensured
+	ensured *sync.Map
+}
+
+// NewMemberAccess returns a new MemberAccess.
+func NewMemberAccess(kubernetes KubernetesService) MemberAccess {
+	return MemberAccess{
+		kubernetes: kubernetes,
+		ensured:    &sync.Map{},
+	}
+}
+
+// Ensure creates the cluster role binding of MemberGroup in a cluster.
+// The binding is only ensured once per cluster by the same MemberAccess.
+func (a MemberAccess) Ensure(ctx context.Context, clusterID uint) error {
+	if _, ok := a.ensured.Load(clusterID); ok {
+		return nil
+	}
+
+	err := a.kubernetes.EnsureObject(ctx, clusterID, memberClusterRoleBinding())
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to ensure cluster role binding for members", "clusterId", clusterID)
+	}
+
+	a.ensured.Store(clusterID, true)
+
+	return nil
+}
+
+func memberClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: memberClusterRoleBindingName,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "pipeline",
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     MemberClusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     MemberGroup,
+			},
+		},
+	}
+}
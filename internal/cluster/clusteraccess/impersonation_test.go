@@ -0,0 +1,65 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteraccess
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+func TestImpersonate(t *testing.T) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Impersonate-User", "system:admin")
+	header.Add("Impersonate-Group", "system:masters")
+	header.Set("Impersonate-Extra-Scopes", "everything")
+
+	Impersonate(header, UserName("john.doe"), GroupsForRole(auth.RoleMember))
+
+	assert.Equal(t, http.Header{
+		"Accept":            {"application/json"},
+		"Impersonate-User":  {"pipeline:john.doe"},
+		"Impersonate-Group": {MemberGroup},
+	}, header)
+}
+
+func TestGroupsForRole(t *testing.T) {
+	assert.Equal(t, []string{AdminGroup}, GroupsForRole(auth.RoleAdmin))
+	assert.Equal(t, []string{MemberGroup}, GroupsForRole(auth.RoleMember))
+	assert.Empty(t, GroupsForRole("unknown"))
+}
+
+func TestMemberAccess_Ensure(t *testing.T) {
+	kubernetes := &kubernetesServiceStub{}
+	access := NewMemberAccess(kubernetes)
+
+	require.NoError(t, access.Ensure(context.Background(), 1))
+	require.NoError(t, access.Ensure(context.Background(), 1))
+	require.NoError(t, access.Ensure(context.Background(), 2))
+
+	require.Len(t, kubernetes.objects, 2)
+
+	binding, ok := kubernetes.objects[0].(*rbacv1.ClusterRoleBinding)
+	require.True(t, ok)
+	assert.Equal(t, MemberClusterRole, binding.RoleRef.Name)
+	assert.Equal(t, MemberGroup, binding.Subjects[0].Name)
+}
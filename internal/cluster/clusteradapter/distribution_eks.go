@@ -58,3 +58,7 @@ func (s eksService) UpdateNodePool(ctx context.Context, clusterID uint, nodePool
 func (s eksService) DeleteNodePool(ctx context.Context, clusterID uint, name string) (deleted bool, err error) {
 	panic("implement me")
 }
+
+func (s eksService) UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error) {
+	return s.service.UpgradeCluster(ctx, clusterID, version)
+}
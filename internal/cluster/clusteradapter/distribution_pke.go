@@ -0,0 +1,67 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteradapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+)
+
+// NewPKEService returns a new PKE distribution service.
+func NewPKEService(service pke.Service) cluster.Service {
+	return pkeService{
+		service: service,
+	}
+}
+
+type pkeService struct {
+	service pke.Service
+}
+
+// PKE clusters only support upgrades through the distribution API,
+// every other operation is served by the legacy cluster API.
+
+func (s pkeService) DeleteCluster(ctx context.Context, clusterIdentifier cluster.Identifier, options cluster.DeleteClusterOptions) (deleted bool, err error) {
+	return false, notSupportedPKEOperation(clusterIdentifier.ClusterID)
+}
+
+func (s pkeService) CreateNodePool(ctx context.Context, clusterID uint, rawNodePool cluster.NewRawNodePool) error {
+	return notSupportedPKEOperation(clusterID)
+}
+
+func (s pkeService) UpdateNodePool(ctx context.Context, clusterID uint, nodePoolName string, rawNodePoolUpdate cluster.RawNodePoolUpdate) (string, error) {
+	return "", notSupportedPKEOperation(clusterID)
+}
+
+func (s pkeService) DeleteNodePool(ctx context.Context, clusterID uint, name string) (deleted bool, err error) {
+	return false, notSupportedPKEOperation(clusterID)
+}
+
+func (s pkeService) UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error) {
+	return s.service.UpgradeCluster(ctx, clusterID, version)
+}
+
+func notSupportedPKEOperation(clusterID uint) error {
+	return errors.WithStack(cluster.NotSupportedDistributionError{
+		ID:           clusterID,
+		Distribution: "pke",
+
+		Message: "not supported distribution",
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusteradapter
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+func TestPKEService_NotSupportedOperations(t *testing.T) {
+	ctx := context.Background()
+	service := NewPKEService(nil)
+
+	_, err := service.UpdateNodePool(ctx, 1, "pool", cluster.RawNodePoolUpdate{})
+	assert.True(t, errors.As(err, &cluster.NotSupportedDistributionError{}))
+
+	err = service.CreateNodePool(ctx, 1, cluster.NewRawNodePool{})
+	assert.True(t, errors.As(err, &cluster.NotSupportedDistributionError{}))
+
+	_, err = service.DeleteNodePool(ctx, 1, "pool")
+	assert.True(t, errors.As(err, &cluster.NotSupportedDistributionError{}))
+
+	_, err = service.DeleteCluster(ctx, cluster.Identifier{ClusterID: 1}, cluster.DeleteClusterOptions{})
+	assert.True(t, errors.As(err, &cluster.NotSupportedDistributionError{}))
+}
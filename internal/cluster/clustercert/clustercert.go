@@ -0,0 +1,401 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+// Config contains the configuration of control plane certificate management.
+type Config struct {
+	// Enabled turns the scheduled certificate expiry checks on.
+	Enabled bool
+
+	// Schedule is the cron schedule of the certificate expiry checks.
+	Schedule string
+
+	// WarningThreshold is how long before the expiry of a certificate warnings are sent.
+	WarningThreshold time.Duration
+
+	// Image is the image of the pods reading and renewing certificates on master nodes.
+	// It must contain sh and chroot.
+	Image string
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	var err error
+
+	if c.Enabled && c.Schedule == "" {
+		err = errors.Append(err, errors.New("cluster certificate check schedule is required"))
+	}
+
+	if c.WarningThreshold <= 0 {
+		err = errors.Append(err, errors.New("cluster certificate warning threshold must be positive"))
+	}
+
+	if c.Image == "" {
+		err = errors.Append(err, errors.New("cluster certificate image is required"))
+	}
+
+	return err
+}
+
+// Certificate is a control plane certificate on a master node.
+type Certificate struct {
+	Node      string    `json:"node"`
+	Name      string    `json:"name"`
+	Subject   string    `json:"subject"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Expiry summarizes the certificate expiries of a cluster.
+type Expiry struct {
+	// ExpiresAt is the expiry of the certificate expiring first.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Expiring tells whether a certificate expires within the warning threshold.
+	Expiring bool `json:"expiring"`
+
+	// CheckedAt is the time of the last certificate expiry check.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Certificates contains the control plane certificates of a cluster.
+type Certificates struct {
+	Expiry
+
+	Certificates []Certificate `json:"certificates"`
+}
+
+// Settings contain the certificate settings of a cluster.
+type Settings struct {
+	ClusterID uint `json:"-"`
+
+	// NotificationSecretID is the ID of a Slack secret used for sending certificate expiry warnings.
+	NotificationSecretID string `json:"notificationSecretId,omitempty"`
+}
+
+// Rotation statuses.
+const (
+	RotationRunning   = "RUNNING"
+	RotationSucceeded = "SUCCEEDED"
+	RotationFailed    = "FAILED"
+)
+
+// Rotation is the renewal of the control plane certificates of a cluster.
+type Rotation struct {
+	ID            uint       `json:"id"`
+	ClusterID     uint       `json:"clusterId"`
+	Status        string     `json:"status"`
+	StatusMessage string     `json:"statusMessage,omitempty"`
+	StartedAt     time.Time  `json:"startedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service manages the control plane certificates of PKE clusters.
+type Service interface {
+	// GetCertificates returns the control plane certificates of a cluster found by the last check.
+	GetCertificates(ctx context.Context, clusterID uint) (certificates Certificates, err error)
+
+	// GetSettings returns the certificate settings of a cluster.
+	GetSettings(ctx context.Context, clusterID uint) (settings Settings, err error)
+
+	// UpdateSettings replaces the certificate settings of a cluster.
+	UpdateSettings(ctx context.Context, clusterID uint, settings Settings) (updated Settings, err error)
+
+	// RotateCertificates starts renewing the control plane certificates of a cluster.
+	RotateCertificates(ctx context.Context, clusterID uint) (rotation Rotation, err error)
+
+	// ListRotations lists the certificate rotation history of a cluster (newest first).
+	ListRotations(ctx context.Context, clusterID uint) (rotations []Rotation, err error)
+}
+
+// +testify:mock:testOnly=true
+
+// Store persists control plane certificates, settings and rotation history.
+type Store interface {
+	// ListCertificates lists the certificates of a cluster found by the last check (ordered by expiry).
+	ListCertificates(ctx context.Context, clusterID uint) ([]Certificate, time.Time, error)
+
+	// PutCertificates replaces the certificates of a cluster.
+	PutCertificates(ctx context.Context, clusterID uint, certificates []Certificate, checkedAt time.Time) error
+
+	// GetSettings returns the certificate settings of a cluster.
+	// Returns empty settings when nothing is configured for the cluster.
+	GetSettings(ctx context.Context, clusterID uint) (Settings, error)
+
+	// PutSettings replaces the certificate settings of a cluster.
+	PutSettings(ctx context.Context, settings Settings) error
+
+	// CreateRotation records a new rotation and returns its ID.
+	CreateRotation(ctx context.Context, rotation Rotation) (uint, error)
+
+	// GetRotation returns a rotation of a cluster.
+	// Returns a RotationNotFoundError when the rotation cannot be found.
+	GetRotation(ctx context.Context, clusterID uint, id uint) (Rotation, error)
+
+	// ListRotations lists the rotations of a cluster (newest first).
+	ListRotations(ctx context.Context, clusterID uint) ([]Rotation, error)
+
+	// UpdateRotation updates the status of a rotation.
+	UpdateRotation(ctx context.Context, rotation Rotation) error
+}
+
+// ClusterStore provides access to clusters.
+type ClusterStore interface {
+	// GetCluster returns a generic representation of a cluster.
+	GetCluster(ctx context.Context, id uint) (cluster.Cluster, error)
+
+	// SetStatus sets the cluster status.
+	SetStatus(ctx context.Context, id uint, status string, statusMessage string) error
+}
+
+// +testify:mock:testOnly=true
+
+// Starter starts certificate rotations in the background.
+type Starter interface {
+	// StartRotation starts a rotation that is already recorded.
+	StartRotation(ctx context.Context, clusterID uint, rotationID uint) error
+}
+
+// ExpiryGetter returns the certificate expiry summary of clusters.
+type ExpiryGetter interface {
+	// GetExpiry returns the certificate expiry summary of a cluster.
+	// Returns nil if the certificates of the cluster have not been checked yet.
+	GetExpiry(ctx context.Context, clusterID uint) (*Expiry, error)
+}
+
+// NewExpiryGetter returns a new ExpiryGetter.
+func NewExpiryGetter(config Config, store Store) ExpiryGetter {
+	return expiryGetter{
+		config: config,
+		store:  store,
+	}
+}
+
+type expiryGetter struct {
+	config Config
+	store  Store
+}
+
+func (g expiryGetter) GetExpiry(ctx context.Context, clusterID uint) (*Expiry, error) {
+	certificates, err := getCertificates(ctx, g.store, clusterID, g.config.WarningThreshold, time.Now())
+	if errors.As(err, &CertificatesNotFoundError{}) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &certificates.Expiry, nil
+}
+
+// getCertificates returns the certificates of a cluster along with their expiry summary.
+func getCertificates(ctx context.Context, store Store, clusterID uint, threshold time.Duration, now time.Time) (Certificates, error) {
+	certificates, checkedAt, err := store.ListCertificates(ctx, clusterID)
+	if err != nil {
+		return Certificates{}, err
+	}
+
+	if len(certificates) == 0 {
+		return Certificates{}, errors.WithStack(CertificatesNotFoundError{ClusterID: clusterID})
+	}
+
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].ExpiresAt.Before(certificates[j].ExpiresAt)
+	})
+
+	return Certificates{
+		Expiry: Expiry{
+			ExpiresAt: certificates[0].ExpiresAt,
+			Expiring:  certificates[0].ExpiresAt.Sub(now) < threshold,
+			CheckedAt: checkedAt,
+		},
+		Certificates: certificates,
+	}, nil
+}
+
+// NewService returns a new Service.
+func NewService(
+	config Config,
+	store Store,
+	clusters ClusterStore,
+	secrets secret.Store,
+	starter Starter,
+	logger Logger,
+) Service {
+	return service{
+		config:   config,
+		store:    store,
+		clusters: clusters,
+		secrets:  secrets,
+		starter:  starter,
+
+		logger: logger,
+	}
+}
+
+type service struct {
+	config   Config
+	store    Store
+	clusters ClusterStore
+	secrets  secret.Store
+	starter  Starter
+
+	logger Logger
+}
+
+func (s service) GetCertificates(ctx context.Context, clusterID uint) (Certificates, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return Certificates{}, err
+	}
+
+	return getCertificates(ctx, s.store, clusterID, s.config.WarningThreshold, time.Now())
+}
+
+func (s service) GetSettings(ctx context.Context, clusterID uint) (Settings, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return Settings{}, err
+	}
+
+	return s.store.GetSettings(ctx, clusterID)
+}
+
+func (s service) UpdateSettings(ctx context.Context, clusterID uint, settings Settings) (Settings, error) {
+	c, err := s.getCluster(ctx, clusterID)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	settings.ClusterID = clusterID
+
+	if settings.NotificationSecretID != "" {
+		model, err := s.secrets.Get(ctx, c.OrganizationID, settings.NotificationSecretID)
+		if errors.As(err, &secret.NotFoundError{}) {
+			return Settings{}, NewValidationError(
+				"invalid certificate settings",
+				[]string{fmt.Sprintf("notification secret %s cannot be found", settings.NotificationSecretID)},
+			)
+		} else if err != nil {
+			return Settings{}, err
+		}
+
+		if model.Type != types.Slack {
+			return Settings{}, NewValidationError(
+				"invalid certificate settings",
+				[]string{fmt.Sprintf("notification secret must be of type %s", types.Slack)},
+			)
+		}
+	}
+
+	if err := s.store.PutSettings(ctx, settings); err != nil {
+		return Settings{}, err
+	}
+
+	return s.store.GetSettings(ctx, clusterID)
+}
+
+func (s service) RotateCertificates(ctx context.Context, clusterID uint) (Rotation, error) {
+	c, err := s.getCluster(ctx, clusterID)
+	if err != nil {
+		return Rotation{}, err
+	}
+
+	if c.Status != cluster.Running && c.Status != cluster.Warning {
+		return Rotation{}, NewValidationError(
+			"cannot rotate certificates",
+			[]string{fmt.Sprintf("cluster must be running, current status is %s", c.Status)},
+		)
+	}
+
+	rotations, err := s.store.ListRotations(ctx, clusterID)
+	if err != nil {
+		return Rotation{}, err
+	}
+
+	if len(rotations) > 0 && rotations[0].Status == RotationRunning {
+		return Rotation{}, errors.WithStack(RotationInProgressError{ClusterID: clusterID})
+	}
+
+	id, err := s.store.CreateRotation(ctx, Rotation{
+		ClusterID: clusterID,
+		Status:    RotationRunning,
+	})
+	if err != nil {
+		return Rotation{}, err
+	}
+
+	rotation, err := s.store.GetRotation(ctx, clusterID, id)
+	if err != nil {
+		return Rotation{}, err
+	}
+
+	if err := s.starter.StartRotation(ctx, clusterID, id); err != nil {
+		now := time.Now()
+		rotation.Status = RotationFailed
+		rotation.StatusMessage = "failed to start the rotation"
+		rotation.FinishedAt = &now
+
+		if err := s.store.UpdateRotation(ctx, rotation); err != nil {
+			s.logger.Warn("failed to update certificate rotation status", map[string]interface{}{
+				"clusterId":  clusterID,
+				"rotationId": id,
+				"error":      err.Error(),
+			})
+		}
+
+		return Rotation{}, err
+	}
+
+	return rotation, nil
+}
+
+func (s service) ListRotations(ctx context.Context, clusterID uint) ([]Rotation, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListRotations(ctx, clusterID)
+}
+
+// getCluster returns a cluster with certificates managed by Pipeline.
+func (s service) getCluster(ctx context.Context, clusterID uint) (cluster.Cluster, error) {
+	c, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return cluster.Cluster{}, err
+	}
+
+	if c.Distribution != pkgCluster.PKE {
+		return cluster.Cluster{}, NewValidationError(
+			"certificate management is not supported",
+			[]string{"certificate management is only supported for PKE clusters"},
+		)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,220 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	internaltesting "github.com/banzaicloud/pipeline/internal/testing"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type inMemoryClusterStore struct {
+	clusters map[uint]cluster.Cluster
+}
+
+func newInMemoryClusterStore(clusters ...cluster.Cluster) *inMemoryClusterStore {
+	store := &inMemoryClusterStore{clusters: make(map[uint]cluster.Cluster)}
+
+	for _, c := range clusters {
+		store.clusters[c.ID] = c
+	}
+
+	return store
+}
+
+func (s *inMemoryClusterStore) GetCluster(_ context.Context, id uint) (cluster.Cluster, error) {
+	c, ok := s.clusters[id]
+	if !ok {
+		return cluster.Cluster{}, cluster.NotFoundError{ClusterID: id}
+	}
+
+	return c, nil
+}
+
+func (s *inMemoryClusterStore) SetStatus(_ context.Context, id uint, status string, statusMessage string) error {
+	c := s.clusters[id]
+	c.Status = status
+	c.StatusMessage = statusMessage
+	s.clusters[id] = c
+
+	return nil
+}
+
+func pkeCluster(id uint, status string) cluster.Cluster {
+	return cluster.Cluster{
+		ID:             id,
+		OrganizationID: 1,
+		Name:           "cluster",
+		Distribution:   pkgCluster.PKE,
+		Status:         status,
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := Config{
+		Enabled:          true,
+		Schedule:         "0 6 * * *",
+		WarningThreshold: 720 * time.Hour,
+		Image:            "busybox",
+	}
+
+	assert.NoError(t, config.Validate())
+
+	assert.Error(t, Config{WarningThreshold: time.Hour}.Validate())
+	assert.Error(t, Config{Enabled: true, WarningThreshold: time.Hour, Image: "busybox"}.Validate())
+	assert.NoError(t, Config{WarningThreshold: time.Hour, Image: "busybox"}.Validate())
+}
+
+func TestService_GetCertificates(t *testing.T) {
+	now := time.Now()
+	checkedAt := now.Add(-time.Hour)
+
+	store := new(MockStore)
+	store.On("ListCertificates", mock.Anything, uint(1)).Return([]Certificate{
+		{Node: "master-0", Name: "pki/ca.crt", ExpiresAt: now.Add(10 * 365 * 24 * time.Hour)},
+		{Node: "master-0", Name: "pki/apiserver.crt", ExpiresAt: now.Add(10 * 24 * time.Hour)},
+	}, checkedAt, nil)
+	store.On("ListCertificates", mock.Anything, uint(2)).Return([]Certificate(nil), time.Time{}, nil)
+
+	service := NewService(
+		Config{WarningThreshold: 30 * 24 * time.Hour},
+		store,
+		newInMemoryClusterStore(pkeCluster(1, cluster.Running), pkeCluster(2, cluster.Running)),
+		internaltesting.NewSecretStore(t, 1),
+		new(MockStarter),
+		NoopLogger{},
+	)
+
+	certificates, err := service.GetCertificates(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "pki/apiserver.crt", certificates.Certificates[0].Name)
+	assert.Equal(t, now.Add(10*24*time.Hour), certificates.ExpiresAt)
+	assert.True(t, certificates.Expiring)
+	assert.Equal(t, checkedAt, certificates.CheckedAt)
+
+	_, err = service.GetCertificates(context.Background(), 2)
+	assert.True(t, errors.As(err, &CertificatesNotFoundError{}))
+
+	store.AssertExpectations(t)
+}
+
+func TestService_UpdateSettings(t *testing.T) {
+	store := new(MockStore)
+	store.On("PutSettings", mock.Anything, Settings{ClusterID: 1, NotificationSecretID: "slack"}).Return(nil)
+	store.On("GetSettings", mock.Anything, uint(1)).Return(Settings{ClusterID: 1, NotificationSecretID: "slack"}, nil)
+
+	secrets := internaltesting.NewSecretStore(
+		t, 1,
+		secret.Model{ID: "slack", Type: types.Slack},
+		secret.Model{ID: "other", Type: "password"},
+	)
+
+	service := NewService(Config{}, store, newInMemoryClusterStore(pkeCluster(1, cluster.Running)), secrets, new(MockStarter), NoopLogger{})
+
+	settings, err := service.UpdateSettings(context.Background(), 1, Settings{NotificationSecretID: "slack"})
+	require.NoError(t, err)
+	assert.Equal(t, "slack", settings.NotificationSecretID)
+
+	_, err = service.UpdateSettings(context.Background(), 1, Settings{NotificationSecretID: "other"})
+	assert.True(t, errors.As(err, &ValidationError{}))
+
+	_, err = service.UpdateSettings(context.Background(), 1, Settings{NotificationSecretID: "missing"})
+	assert.True(t, errors.As(err, &ValidationError{}))
+
+	store.AssertExpectations(t)
+}
+
+func TestService_NotPKE(t *testing.T) {
+	c := pkeCluster(1, cluster.Running)
+	c.Distribution = pkgCluster.EKS
+
+	service := NewService(Config{}, new(MockStore), newInMemoryClusterStore(c), nil, new(MockStarter), NoopLogger{})
+
+	_, err := service.RotateCertificates(context.Background(), 1)
+	assert.True(t, errors.As(err, &ValidationError{}))
+}
+
+func TestService_RotateCertificates(t *testing.T) {
+	rotation := Rotation{ID: 2, ClusterID: 1, Status: RotationRunning}
+
+	store := new(MockStore)
+	store.On("ListRotations", mock.Anything, uint(1)).Return([]Rotation{{ID: 1, ClusterID: 1, Status: RotationFailed}}, nil)
+	store.On("CreateRotation", mock.Anything, Rotation{ClusterID: 1, Status: RotationRunning}).Return(uint(2), nil)
+	store.On("GetRotation", mock.Anything, uint(1), uint(2)).Return(rotation, nil)
+
+	starter := new(MockStarter)
+	starter.On("StartRotation", mock.Anything, uint(1), uint(2)).Return(nil)
+
+	service := NewService(Config{}, store, newInMemoryClusterStore(pkeCluster(1, cluster.Warning)), nil, starter, NoopLogger{})
+
+	started, err := service.RotateCertificates(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, rotation, started)
+
+	store.AssertExpectations(t)
+	starter.AssertExpectations(t)
+}
+
+func TestService_RotateCertificates_InProgress(t *testing.T) {
+	store := new(MockStore)
+	store.On("ListRotations", mock.Anything, uint(1)).Return([]Rotation{{ID: 1, ClusterID: 1, Status: RotationRunning}}, nil)
+
+	service := NewService(Config{}, store, newInMemoryClusterStore(pkeCluster(1, cluster.Running)), nil, new(MockStarter), NoopLogger{})
+
+	_, err := service.RotateCertificates(context.Background(), 1)
+	assert.True(t, errors.As(err, &RotationInProgressError{}))
+
+	store.AssertExpectations(t)
+}
+
+func TestService_RotateCertificates_NotRunning(t *testing.T) {
+	service := NewService(Config{}, new(MockStore), newInMemoryClusterStore(pkeCluster(1, cluster.Updating)), nil, new(MockStarter), NoopLogger{})
+
+	_, err := service.RotateCertificates(context.Background(), 1)
+	assert.True(t, errors.As(err, &ValidationError{}))
+}
+
+func TestService_RotateCertificates_StartFailure(t *testing.T) {
+	store := new(MockStore)
+	store.On("ListRotations", mock.Anything, uint(1)).Return([]Rotation(nil), nil)
+	store.On("CreateRotation", mock.Anything, mock.Anything).Return(uint(1), nil)
+	store.On("GetRotation", mock.Anything, uint(1), uint(1)).Return(Rotation{ID: 1, ClusterID: 1, Status: RotationRunning}, nil)
+	store.On("UpdateRotation", mock.Anything, mock.MatchedBy(func(rotation Rotation) bool {
+		return rotation.ID == 1 && rotation.Status == RotationFailed && rotation.FinishedAt != nil
+	})).Return(nil)
+
+	starter := new(MockStarter)
+	starter.On("StartRotation", mock.Anything, uint(1), uint(1)).Return(errors.New("cadence is down"))
+
+	service := NewService(Config{}, store, newInMemoryClusterStore(pkeCluster(1, cluster.Running)), nil, starter, NoopLogger{})
+
+	_, err := service.RotateCertificates(context.Background(), 1)
+	assert.Error(t, err)
+
+	store.AssertExpectations(t)
+	starter.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert/clustercertworkflow"
+)
+
+// CadenceStarter starts cluster certificate workflows.
+type CadenceStarter struct {
+	workflowClient client.Client
+}
+
+// NewCadenceStarter returns a new CadenceStarter.
+func NewCadenceStarter(workflowClient client.Client) CadenceStarter {
+	return CadenceStarter{
+		workflowClient: workflowClient,
+	}
+}
+
+// StartRotation implements the clustercert.Starter interface.
+func (s CadenceStarter) StartRotation(ctx context.Context, clusterID uint, rotationID uint) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           clustercertworkflow.RotateCertificatesWorkflowID(clusterID),
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 3 * time.Hour,
+		WorkflowIDReusePolicy:        client.WorkflowIDReusePolicyAllowDuplicate,
+	}
+
+	input := clustercertworkflow.RotateCertificatesWorkflowInput{
+		ClusterID:  clusterID,
+		RotationID: rotationID,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, clustercertworkflow.RotateCertificatesWorkflowName, input)
+	if isAlreadyStartedError(err) {
+		return errors.WithStack(clustercert.RotationInProgressError{ClusterID: clusterID})
+	} else if err != nil {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", clustercertworkflow.RotateCertificatesWorkflowName)
+	}
+
+	return nil
+}
+
+// StartScheduler starts the cron workflow that checks the certificates of clusters.
+// It does nothing if the scheduler is already running.
+func (s CadenceStarter) StartScheduler(ctx context.Context, schedule string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           clustercertworkflow.SchedulerWorkflowName,
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 12 * time.Hour,
+		CronSchedule:                 schedule,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, clustercertworkflow.SchedulerWorkflowName)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", clustercertworkflow.SchedulerWorkflowName)
+	}
+
+	return nil
+}
+
+func isAlreadyStartedError(err error) bool {
+	var alreadyStartedErr *shared.WorkflowExecutionAlreadyStartedError
+
+	return errors.As(err, &alreadyStartedErr)
+}
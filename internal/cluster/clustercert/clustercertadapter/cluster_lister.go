@@ -0,0 +1,65 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/src/model"
+)
+
+// ClusterModels lists every cluster.
+type ClusterModels interface {
+	All() ([]*model.ClusterModel, error)
+}
+
+// ClusterLister lists running PKE clusters.
+type ClusterLister struct {
+	clusters ClusterModels
+}
+
+// NewClusterLister returns a new ClusterLister.
+func NewClusterLister(clusters ClusterModels) ClusterLister {
+	return ClusterLister{
+		clusters: clusters,
+	}
+}
+
+// ListClusters implements the clustercert.ClusterLister interface.
+func (l ClusterLister) ListClusters(ctx context.Context) ([]uint, error) {
+	clusters, err := l.clusters.All()
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var ids []uint
+
+	for _, c := range clusters {
+		if c.Distribution != pkgCluster.PKE {
+			continue
+		}
+
+		if c.Status != pkgCluster.Running && c.Status != pkgCluster.Warning {
+			continue
+		}
+
+		ids = append(ids, c.ID)
+	}
+
+	return ids, nil
+}
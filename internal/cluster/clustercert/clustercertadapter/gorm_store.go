@@ -0,0 +1,248 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+// certificateModel describes a control plane certificate of a cluster found by the last check.
+type certificateModel struct {
+	ID        uint `gorm:"primary_key"`
+	ClusterID uint `gorm:"index:idx_cluster_certificates_cluster_id"`
+	Node      string
+	Name      string
+	Subject   string `gorm:"type:text"`
+	ExpiresAt time.Time
+	CheckedAt time.Time
+}
+
+// TableName changes the default table name.
+func (certificateModel) TableName() string {
+	return "cluster_certificates"
+}
+
+// settingsModel describes the certificate settings of a cluster.
+type settingsModel struct {
+	ID                   uint `gorm:"primary_key"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	ClusterID            uint `gorm:"unique_index:idx_cluster_certificate_settings_cluster_id"`
+	NotificationSecretID string
+}
+
+// TableName changes the default table name.
+func (settingsModel) TableName() string {
+	return "cluster_certificate_settings"
+}
+
+// rotationModel describes a control plane certificate rotation of a cluster.
+type rotationModel struct {
+	ID            uint `gorm:"primary_key"`
+	ClusterID     uint `gorm:"index:idx_cluster_certificate_rotations_cluster_id"`
+	Status        string
+	StatusMessage string `gorm:"type:text"`
+	StartedAt     time.Time
+	FinishedAt    *time.Time
+}
+
+// TableName changes the default table name.
+func (rotationModel) TableName() string {
+	return "cluster_certificate_rotations"
+}
+
+// GormStore is a cluster certificate store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// ListCertificates implements the clustercert.Store interface.
+func (s GormStore) ListCertificates(ctx context.Context, clusterID uint) ([]clustercert.Certificate, time.Time, error) {
+	var models []certificateModel
+
+	if err := s.db.Where(certificateModel{ClusterID: clusterID}).Order("expires_at, id").Find(&models).Error; err != nil {
+		return nil, time.Time{}, errors.WrapIfWithDetails(err, "failed to list cluster certificates", "clusterId", clusterID)
+	}
+
+	var checkedAt time.Time
+
+	certificates := make([]clustercert.Certificate, 0, len(models))
+	for _, model := range models {
+		certificates = append(certificates, clustercert.Certificate{
+			Node:      model.Node,
+			Name:      model.Name,
+			Subject:   model.Subject,
+			ExpiresAt: model.ExpiresAt,
+		})
+
+		checkedAt = model.CheckedAt
+	}
+
+	return certificates, checkedAt, nil
+}
+
+// PutCertificates implements the clustercert.Store interface.
+func (s GormStore) PutCertificates(ctx context.Context, clusterID uint, certificates []clustercert.Certificate, checkedAt time.Time) error {
+	tx := s.db.Begin()
+
+	if err := tx.Where(certificateModel{ClusterID: clusterID}).Delete(certificateModel{}).Error; err != nil {
+		tx.Rollback()
+
+		return errors.WrapIfWithDetails(err, "failed to delete cluster certificates", "clusterId", clusterID)
+	}
+
+	for _, certificate := range certificates {
+		model := certificateModel{
+			ClusterID: clusterID,
+			Node:      certificate.Node,
+			Name:      certificate.Name,
+			Subject:   certificate.Subject,
+			ExpiresAt: certificate.ExpiresAt,
+			CheckedAt: checkedAt,
+		}
+
+		if err := tx.Create(&model).Error; err != nil {
+			tx.Rollback()
+
+			return errors.WrapIfWithDetails(err, "failed to save cluster certificates", "clusterId", clusterID)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to save cluster certificates", "clusterId", clusterID)
+	}
+
+	return nil
+}
+
+// GetSettings implements the clustercert.Store interface.
+func (s GormStore) GetSettings(ctx context.Context, clusterID uint) (clustercert.Settings, error) {
+	var model settingsModel
+
+	err := s.db.Where(settingsModel{ClusterID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return clustercert.Settings{ClusterID: clusterID}, nil
+	} else if err != nil {
+		return clustercert.Settings{}, errors.WrapIfWithDetails(err, "failed to get cluster certificate settings", "clusterId", clusterID)
+	}
+
+	return clustercert.Settings{
+		ClusterID:            model.ClusterID,
+		NotificationSecretID: model.NotificationSecretID,
+	}, nil
+}
+
+// PutSettings implements the clustercert.Store interface.
+func (s GormStore) PutSettings(ctx context.Context, settings clustercert.Settings) error {
+	var model settingsModel
+
+	err := s.db.Where(settingsModel{ClusterID: settings.ClusterID}).First(&model).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return errors.WrapIfWithDetails(err, "failed to get cluster certificate settings", "clusterId", settings.ClusterID)
+	}
+
+	model.ClusterID = settings.ClusterID
+	model.NotificationSecretID = settings.NotificationSecretID
+
+	if err := s.db.Save(&model).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to save cluster certificate settings", "clusterId", settings.ClusterID)
+	}
+
+	return nil
+}
+
+// CreateRotation implements the clustercert.Store interface.
+func (s GormStore) CreateRotation(ctx context.Context, rotation clustercert.Rotation) (uint, error) {
+	model := rotationModel{
+		ClusterID:     rotation.ClusterID,
+		Status:        rotation.Status,
+		StatusMessage: rotation.StatusMessage,
+		StartedAt:     time.Now(),
+	}
+
+	if err := s.db.Create(&model).Error; err != nil {
+		return 0, errors.WrapIfWithDetails(err, "failed to create certificate rotation", "clusterId", rotation.ClusterID)
+	}
+
+	return model.ID, nil
+}
+
+// GetRotation implements the clustercert.Store interface.
+func (s GormStore) GetRotation(ctx context.Context, clusterID uint, id uint) (clustercert.Rotation, error) {
+	var model rotationModel
+
+	err := s.db.Where(rotationModel{ID: id, ClusterID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return clustercert.Rotation{}, errors.WithStack(clustercert.RotationNotFoundError{ClusterID: clusterID, RotationID: id})
+	} else if err != nil {
+		return clustercert.Rotation{}, errors.WrapIfWithDetails(err, "failed to get certificate rotation", "clusterId", clusterID, "rotationId", id)
+	}
+
+	return toRotation(model), nil
+}
+
+// ListRotations implements the clustercert.Store interface.
+func (s GormStore) ListRotations(ctx context.Context, clusterID uint) ([]clustercert.Rotation, error) {
+	var models []rotationModel
+
+	if err := s.db.Where(rotationModel{ClusterID: clusterID}).Order("id desc").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list certificate rotations", "clusterId", clusterID)
+	}
+
+	rotations := make([]clustercert.Rotation, 0, len(models))
+	for _, model := range models {
+		rotations = append(rotations, toRotation(model))
+	}
+
+	return rotations, nil
+}
+
+// UpdateRotation implements the clustercert.Store interface.
+func (s GormStore) UpdateRotation(ctx context.Context, rotation clustercert.Rotation) error {
+	err := s.db.Model(rotationModel{ID: rotation.ID}).Where("cluster_id = ?", rotation.ClusterID).Updates(map[string]interface{}{
+		"status":         rotation.Status,
+		"status_message": rotation.StatusMessage,
+		"finished_at":    rotation.FinishedAt,
+	}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update certificate rotation", "clusterId", rotation.ClusterID, "rotationId", rotation.ID)
+	}
+
+	return nil
+}
+
+func toRotation(model rotationModel) clustercert.Rotation {
+	return clustercert.Rotation{
+		ID:            model.ID,
+		ClusterID:     model.ClusterID,
+		Status:        model.Status,
+		StatusMessage: model.StatusMessage,
+		StartedAt:     model.StartedAt,
+		FinishedAt:    model.FinishedAt,
+	}
+}
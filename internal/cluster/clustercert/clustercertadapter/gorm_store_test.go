@@ -0,0 +1,135 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	"github.com/banzaicloud/pipeline/internal/common"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore_Certificates(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	certificates, _, err := store.ListCertificates(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+
+	checkedAt := time.Date(2020, 5, 1, 6, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2021, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	old := []clustercert.Certificate{
+		{Node: "master-0", Name: "apiserver.crt", Subject: "CN=kube-apiserver", ExpiresAt: expiresAt.Add(-time.Hour)},
+	}
+	require.NoError(t, store.PutCertificates(ctx, 1, old, checkedAt.Add(-24*time.Hour)))
+
+	current := []clustercert.Certificate{
+		{Node: "master-0", Name: "apiserver.crt", Subject: "CN=kube-apiserver", ExpiresAt: expiresAt.Add(time.Hour)},
+		{Node: "master-0", Name: "ca.crt", Subject: "CN=kubernetes", ExpiresAt: expiresAt},
+	}
+	require.NoError(t, store.PutCertificates(ctx, 1, current, checkedAt))
+	require.NoError(t, store.PutCertificates(ctx, 2, old, checkedAt))
+
+	certificates, storedCheckedAt, err := store.ListCertificates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, certificates, 2)
+
+	for i := range certificates {
+		certificates[i].ExpiresAt = certificates[i].ExpiresAt.UTC()
+	}
+
+	assert.Equal(t, []clustercert.Certificate{current[1], current[0]}, certificates)
+	assert.True(t, checkedAt.Equal(storedCheckedAt))
+}
+
+func TestGormStore_Settings(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	settings, err := store.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, clustercert.Settings{ClusterID: 1}, settings)
+
+	require.NoError(t, store.PutSettings(ctx, clustercert.Settings{ClusterID: 1, NotificationSecretID: "old"}))
+	require.NoError(t, store.PutSettings(ctx, clustercert.Settings{ClusterID: 1, NotificationSecretID: "slack"}))
+
+	settings, err = store.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, clustercert.Settings{ClusterID: 1, NotificationSecretID: "slack"}, settings)
+}
+
+func TestGormStore_Rotations(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.GetRotation(ctx, 1, 1)
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &clustercert.RotationNotFoundError{}))
+
+	first, err := store.CreateRotation(ctx, clustercert.Rotation{ClusterID: 1, Status: clustercert.RotationRunning})
+	require.NoError(t, err)
+
+	finishedAt := time.Now()
+	require.NoError(t, store.UpdateRotation(ctx, clustercert.Rotation{
+		ID:            first,
+		ClusterID:     1,
+		Status:        clustercert.RotationFailed,
+		StatusMessage: "failed to renew certificates",
+		FinishedAt:    &finishedAt,
+	}))
+
+	second, err := store.CreateRotation(ctx, clustercert.Rotation{ClusterID: 1, Status: clustercert.RotationRunning})
+	require.NoError(t, err)
+
+	_, err = store.CreateRotation(ctx, clustercert.Rotation{ClusterID: 2, Status: clustercert.RotationRunning})
+	require.NoError(t, err)
+
+	_, err = store.GetRotation(ctx, 2, first)
+	assert.True(t, errors.As(err, &clustercert.RotationNotFoundError{}))
+
+	rotation, err := store.GetRotation(ctx, 1, first)
+	require.NoError(t, err)
+	assert.Equal(t, clustercert.RotationFailed, rotation.Status)
+	assert.Equal(t, "failed to renew certificates", rotation.StatusMessage)
+	assert.NotNil(t, rotation.FinishedAt)
+
+	rotations, err := store.ListRotations(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rotations, 2)
+	assert.Equal(t, second, rotations[0].ID)
+	assert.Equal(t, clustercert.RotationRunning, rotations[0].Status)
+	assert.Nil(t, rotations[0].FinishedAt)
+	assert.Equal(t, first, rotations[1].ID)
+}
@@ -0,0 +1,349 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+)
+
+const (
+	controlPlaneNamespace = "kube-system"
+	podLabelName          = "app.kubernetes.io/name"
+	podLabelValue         = "pipeline-certificates"
+	readerContainer       = "reader"
+	renewContainer        = "renew"
+	hostKubernetesDir     = "/etc/kubernetes"
+	certificateFilePrefix = "### "
+)
+
+// KubernetesControlPlane reads and renews the kubeadm managed certificates of PKE master nodes
+// through pods scheduled on the nodes.
+//
+// Certificates are read by a pod mounting the Kubernetes configuration directory of the node read-only.
+// Renewals are executed by a privileged pod running kubeadm on the node, which restarts
+// the control plane static pods one by one by moving their manifests away and back.
+type KubernetesControlPlane struct {
+	kubeConfigs intClusterK8s.KubeConfigGetter
+	image       string
+
+	pollInterval time.Duration
+}
+
+// NewKubernetesControlPlane returns a new KubernetesControlPlane.
+func NewKubernetesControlPlane(kubeConfigs intClusterK8s.KubeConfigGetter, image string) KubernetesControlPlane {
+	return KubernetesControlPlane{
+		kubeConfigs: kubeConfigs,
+		image:       image,
+
+		pollInterval: 10 * time.Second,
+	}
+}
+
+// MasterNodes implements the clustercert.ControlPlane interface.
+func (c KubernetesControlPlane) MasterNodes(ctx context.Context, clusterID uint) ([]string, error) {
+	_, client, err := intClusterK8s.NewClusterClient(ctx, c.kubeConfigs, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getMasterNodes(client)
+}
+
+// ReadCertificates implements the clustercert.ControlPlane interface.
+func (c KubernetesControlPlane) ReadCertificates(ctx context.Context, clusterID uint, node string) ([]clustercert.Certificate, error) {
+	var output bytes.Buffer
+
+	err := c.execInReader(ctx, clusterID, node, []string{"sh", "-c", readCertificatesScript}, &output)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to read certificates")
+	}
+
+	return parseCertificates(node, output.String())
+}
+
+// ReadAdminKubeconfig implements the clustercert.ControlPlane interface.
+func (c KubernetesControlPlane) ReadAdminKubeconfig(ctx context.Context, clusterID uint, node string) ([]byte, error) {
+	var output bytes.Buffer
+
+	err := c.execInReader(ctx, clusterID, node, []string{"cat", "/host" + hostKubernetesDir + "/admin.conf"}, &output)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to read admin kubeconfig")
+	}
+
+	return output.Bytes(), nil
+}
+
+// RenewCertificates implements the clustercert.ControlPlane interface.
+func (c KubernetesControlPlane) RenewCertificates(ctx context.Context, clusterID uint, node string) error {
+	_, client, err := intClusterK8s.NewClusterClient(ctx, c.kubeConfigs, clusterID)
+	if err != nil {
+		return err
+	}
+
+	pod, err := client.CoreV1().Pods(controlPlaneNamespace).Create(renewPod(node, c.image))
+	if err != nil {
+		return errors.WrapIf(err, "failed to create certificate renewal pod")
+	}
+
+	err = c.waitForRenewal(ctx, client, pod.Name)
+
+	_ = client.CoreV1().Pods(controlPlaneNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+
+	return err
+}
+
+// execInReader executes a command in a short-lived reader pod on a master node.
+func (c KubernetesControlPlane) execInReader(ctx context.Context, clusterID uint, node string, command []string, stdout io.Writer) error {
+	config, client, err := intClusterK8s.NewClusterClient(ctx, c.kubeConfigs, clusterID)
+	if err != nil {
+		return err
+	}
+
+	pod, err := client.CoreV1().Pods(controlPlaneNamespace).Create(readerPod(node, c.image))
+	if err != nil {
+		return errors.WrapIf(err, "failed to create certificate reader pod")
+	}
+	defer func() {
+		_ = client.CoreV1().Pods(controlPlaneNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+	}()
+
+	err = intClusterK8s.WaitForPodRunning(ctx, client, controlPlaneNamespace, pod.Name, c.pollInterval)
+	if err != nil {
+		return errors.WrapIf(err, "certificate reader pod did not start")
+	}
+
+	return intClusterK8s.ExecInPod(config, client, *pod, readerContainer, command, nil, stdout)
+}
+
+// waitForRenewal waits until the renewal pod completes.
+// The API server is restarted during the renewal, so errors of getting the pod are tolerated until the timeout.
+func (c KubernetesControlPlane) waitForRenewal(ctx context.Context, client kubernetes.Interface, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Minute)
+	defer cancel()
+
+	err := intClusterK8s.Poll(ctx, c.pollInterval, func() (bool, error) {
+		return intClusterK8s.IsPodCompleted(client, controlPlaneNamespace, name)
+	})
+
+	if ctx.Err() != nil {
+		return errors.WrapIf(err, "certificates were not renewed in time")
+	}
+
+	return errors.WrapIf(err, "certificate renewal failed")
+}
+
+// getMasterNodes returns the names of the master nodes of a cluster.
+func getMasterNodes(client kubernetes.Interface) ([]string, error) {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: intClusterK8s.MasterNodeLabel})
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list master nodes")
+	}
+
+	names := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		names = append(names, node.Name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// readCertificatesScript prints the control plane certificates of a node,
+// each preceded by a line with the name of the file it is read from.
+// Only the (public) client certificates are printed from the kubeconfig files.
+const readCertificatesScript = `
+cd /host/etc/kubernetes || exit 1
+
+for f in pki/*.crt pki/etcd/*.crt; do
+  [ -f "$f" ] || continue
+  echo "### $f"
+  cat "$f"
+done
+
+for f in admin.conf controller-manager.conf scheduler.conf; do
+  [ -f "$f" ] || continue
+  echo "### $f"
+  sed -n 's/^ *client-certificate-data: *//p' "$f"
+done
+`
+
+// parseCertificates parses the output of readCertificatesScript.
+func parseCertificates(node string, output string) ([]clustercert.Certificate, error) {
+	var certificates []clustercert.Certificate
+
+	var name string
+	var content strings.Builder
+
+	flush := func() error {
+		// Kubeconfig files may reference certificate files instead of embedding them
+		if name == "" || strings.TrimSpace(content.String()) == "" {
+			return nil
+		}
+
+		data := []byte(content.String())
+
+		// Kubeconfig files contain base64 encoded PEM data
+		if strings.HasSuffix(name, ".conf") {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content.String()))
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to decode certificate", "file", name)
+			}
+
+			data = decoded
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "CERTIFICATE" {
+			return errors.NewWithDetails("failed to decode certificate", "file", name)
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to parse certificate", "file", name)
+		}
+
+		certificates = append(certificates, clustercert.Certificate{
+			Node:      node,
+			Name:      name,
+			Subject:   certificate.Subject.String(),
+			ExpiresAt: certificate.NotAfter,
+		})
+
+		return nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, certificateFilePrefix) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			name = strings.TrimPrefix(line, certificateFilePrefix)
+			content.Reset()
+
+			continue
+		}
+
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// readerPod returns a pod giving read-only access to the Kubernetes configuration directory of a node.
+func readerPod(node string, image string) *corev1.Pod {
+	return intClusterK8s.NewNodePod(intClusterK8s.NodePodSpec{
+		Namespace:    controlPlaneNamespace,
+		GenerateName: "pipeline-certificates-read-",
+		Labels:       map[string]string{podLabelName: podLabelValue},
+		NodeName:     node,
+		Container:    readerContainer,
+		Image:        image,
+		Command:      []string{"sleep", "3600"},
+		Volumes: []intClusterK8s.NodePodVolume{
+			{Name: "kubernetes", MountPath: "/host" + hostKubernetesDir, ReadOnly: true, HostPath: hostKubernetesDir},
+		},
+	})
+}
+
+// renewScript renews the certificates of a master node and restarts the control plane components.
+// It runs in the root file system of the node to use the kubeadm and kubectl binaries installed by PKE.
+// Static pods are restarted one at a time by moving their manifests away until the kubelet stops them.
+const renewScript = `
+set -eu
+
+if kubeadm certs --help >/dev/null 2>&1; then
+  kubeadm certs renew all
+else
+  kubeadm alpha certs renew all
+fi
+
+manifests=/etc/kubernetes/manifests
+stash=/etc/kubernetes/manifests-pipeline-certificates
+
+mkdir -p "$stash"
+
+restore() {
+  for f in "$stash"/*.yaml; do
+    [ -f "$f" ] && mv "$f" "$manifests/"
+  done
+  rmdir "$stash" 2>/dev/null || true
+}
+
+trap restore EXIT
+
+for component in etcd kube-apiserver kube-controller-manager kube-scheduler; do
+  [ -f "$manifests/$component.yaml" ] || continue
+
+  echo "restarting $component"
+  mv "$manifests/$component.yaml" "$stash/"
+  sleep 30
+  mv "$stash/$component.yaml" "$manifests/"
+  sleep 10
+done
+
+echo "waiting for the API server"
+
+for i in $(seq 1 60); do
+  if kubectl --kubeconfig /etc/kubernetes/admin.conf get --raw=/healthz >/dev/null 2>&1; then
+    echo "certificates renewed"
+    exit 0
+  fi
+
+  sleep 5
+done
+
+echo "API server did not become healthy after renewing certificates" >&2
+exit 1
+`
+
+// renewPod returns a privileged pod renewing the certificates of a master node.
+func renewPod(node string, image string) *corev1.Pod {
+	return intClusterK8s.NewNodePod(intClusterK8s.NodePodSpec{
+		Namespace:    controlPlaneNamespace,
+		GenerateName: "pipeline-certificates-renew-",
+		Labels:       map[string]string{podLabelName: podLabelValue},
+		NodeName:     node,
+		Container:    renewContainer,
+		Image:        image,
+		Command:      intClusterK8s.HostRootCommand(renewScript),
+		Privileged:   true,
+		HostNetwork:  true,
+		HostPID:      true,
+		Volumes:      []intClusterK8s.NodePodVolume{intClusterK8s.HostRootVolume()},
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertadapter
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+)
+
+func newCertificate(t *testing.T, commonName string, notAfter time.Time) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseCertificates(t *testing.T) {
+	apiserverExpiry := time.Date(2021, 5, 1, 6, 0, 0, 0, time.UTC)
+	adminExpiry := time.Date(2021, 4, 1, 6, 0, 0, 0, time.UTC)
+
+	output := "### pki/apiserver.crt\n" +
+		string(newCertificate(t, "kube-apiserver", apiserverExpiry)) +
+		"### admin.conf\n" +
+		base64.StdEncoding.EncodeToString(newCertificate(t, "kubernetes-admin", adminExpiry)) + "\n" +
+		"### scheduler.conf\n"
+
+	certificates, err := parseCertificates("master-0", output)
+	require.NoError(t, err)
+
+	assert.Equal(t, []clustercert.Certificate{
+		{Node: "master-0", Name: "pki/apiserver.crt", Subject: "CN=kube-apiserver", ExpiresAt: apiserverExpiry},
+		{Node: "master-0", Name: "admin.conf", Subject: "CN=kubernetes-admin", ExpiresAt: adminExpiry},
+	}, certificates)
+
+	_, err = parseCertificates("master-0", "### pki/ca.crt\nnot a certificate\n")
+	assert.Error(t, err)
+}
+
+func TestGetMasterNodes(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-1", Labels: map[string]string{intClusterK8s.MasterNodeLabel: ""}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Labels: map[string]string{intClusterK8s.MasterNodeLabel: ""}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}},
+	)
+
+	nodes, err := getMasterNodes(client)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"master-0", "master-1"}, nodes)
+}
+
+func TestRenewPod(t *testing.T) {
+	pod := renewPod("master-0", "busybox")
+
+	assert.Equal(t, "master-0", pod.Spec.NodeName)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	require.Len(t, pod.Spec.Containers, 1)
+
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, "busybox", container.Image)
+	assert.Equal(t, []string{"chroot", "/host", "sh", "-c", renewScript}, container.Command)
+	assert.True(t, *container.SecurityContext.Privileged)
+	assert.Equal(t, "/", pod.Spec.Volumes[0].HostPath.Path)
+}
@@ -0,0 +1,165 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertdriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("").Handler(kithttp.NewServer(
+		endpoints.GetCertificates,
+		decodeGetCertificatesHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetCertificatesHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/settings").Handler(kithttp.NewServer(
+		endpoints.GetSettings,
+		decodeGetSettingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetSettingsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPut).Path("/settings").Handler(kithttp.NewServer(
+		endpoints.UpdateSettings,
+		decodeUpdateSettingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeUpdateSettingsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/rotations").Handler(kithttp.NewServer(
+		endpoints.ListRotations,
+		decodeListRotationsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListRotationsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPost).Path("/rotations").Handler(kithttp.NewServer(
+		endpoints.RotateCertificates,
+		decodeRotateCertificatesHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeRotateCertificatesHTTPResponse, errorEncoder),
+		options...,
+	))
+}
+
+func decodeClusterID(r *http.Request) (uint, error) {
+	clusterIDStr, ok := mux.Vars(r)["clusterId"]
+	if !ok || clusterIDStr == "" {
+		return 0, errors.NewWithDetails("missing parameter from the URL", "param", "clusterId")
+	}
+
+	clusterID, err := strconv.ParseUint(clusterIDStr, 0, 0)
+	if err != nil {
+		return 0, errors.WrapIf(err, "invalid cluster ID format")
+	}
+
+	return uint(clusterID), nil
+}
+
+func decodeGetCertificatesHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetCertificatesRequest{ClusterID: clusterID}, nil
+}
+
+func encodeGetCertificatesHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetCertificatesResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Certificates)
+}
+
+func decodeGetSettingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSettingsRequest{ClusterID: clusterID}, nil
+}
+
+func encodeGetSettingsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetSettingsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Settings)
+}
+
+func decodeUpdateSettingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings clustercert.Settings
+
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	return UpdateSettingsRequest{ClusterID: clusterID, Settings: settings}, nil
+}
+
+func encodeUpdateSettingsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(UpdateSettingsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Updated)
+}
+
+func decodeListRotationsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListRotationsRequest{ClusterID: clusterID}, nil
+}
+
+func encodeListRotationsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListRotationsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Rotations)
+}
+
+func decodeRotateCertificatesHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return RotateCertificatesRequest{ClusterID: clusterID}, nil
+}
+
+func encodeRotateCertificatesHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(RotateCertificatesResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(resp.Rotation, http.StatusAccepted))
+}
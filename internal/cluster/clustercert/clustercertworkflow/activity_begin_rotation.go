@@ -0,0 +1,62 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const BeginRotationActivityName = "cluster-certificates-begin-rotation"
+
+// BeginRotationActivity marks a cluster as being updated and lists the master nodes to renew the certificates of.
+type BeginRotationActivity struct {
+	manager clustercert.Manager
+}
+
+type BeginRotationActivityInput struct {
+	ClusterID uint
+}
+
+type BeginRotationActivityOutput struct {
+	Nodes []string
+}
+
+// NewBeginRotationActivity returns a new BeginRotationActivity.
+func NewBeginRotationActivity(manager clustercert.Manager) BeginRotationActivity {
+	return BeginRotationActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a BeginRotationActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: BeginRotationActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a BeginRotationActivity) Execute(ctx context.Context, input BeginRotationActivityInput) (BeginRotationActivityOutput, error) {
+	nodes, err := a.manager.BeginRotation(ctx, input.ClusterID)
+	if err != nil {
+		return BeginRotationActivityOutput{}, err
+	}
+
+	return BeginRotationActivityOutput{
+		Nodes: nodes,
+	}, nil
+}
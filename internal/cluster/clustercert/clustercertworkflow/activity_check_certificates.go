@@ -0,0 +1,52 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const CheckCertificatesActivityName = "cluster-certificates-check-certificates"
+
+// CheckCertificatesActivity records the control plane certificates of a cluster and warns about expiring ones.
+type CheckCertificatesActivity struct {
+	manager clustercert.Manager
+}
+
+type CheckCertificatesActivityInput struct {
+	ClusterID uint
+}
+
+// NewCheckCertificatesActivity returns a new CheckCertificatesActivity.
+func NewCheckCertificatesActivity(manager clustercert.Manager) CheckCertificatesActivity {
+	return CheckCertificatesActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a CheckCertificatesActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: CheckCertificatesActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a CheckCertificatesActivity) Execute(ctx context.Context, input CheckCertificatesActivityInput) error {
+	return a.manager.CheckCertificates(ctx, input.ClusterID, time.Now())
+}
@@ -0,0 +1,55 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const FinishRotationActivityName = "cluster-certificates-finish-rotation"
+
+// FinishRotationActivity records the outcome of a rotation and restores the status of the cluster.
+type FinishRotationActivity struct {
+	manager clustercert.Manager
+}
+
+type FinishRotationActivityInput struct {
+	ClusterID  uint
+	RotationID uint
+
+	// Message is the reason of the failure (empty if the rotation succeeded)
+	Message string
+}
+
+// NewFinishRotationActivity returns a new FinishRotationActivity.
+func NewFinishRotationActivity(manager clustercert.Manager) FinishRotationActivity {
+	return FinishRotationActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a FinishRotationActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: FinishRotationActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a FinishRotationActivity) Execute(ctx context.Context, input FinishRotationActivityInput) error {
+	return a.manager.FinishRotation(ctx, input.ClusterID, input.RotationID, input.Message)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const ListClustersActivityName = "cluster-certificates-list-clusters"
+
+// ListClustersActivity lists the clusters whose certificates should be checked.
+type ListClustersActivity struct {
+	manager clustercert.Manager
+}
+
+type ListClustersActivityInput struct{}
+
+type ListClustersActivityOutput struct {
+	ClusterIDs []uint
+}
+
+// NewListClustersActivity returns a new ListClustersActivity.
+func NewListClustersActivity(manager clustercert.Manager) ListClustersActivity {
+	return ListClustersActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ListClustersActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ListClustersActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ListClustersActivity) Execute(ctx context.Context, _ ListClustersActivityInput) (ListClustersActivityOutput, error) {
+	clusterIDs, err := a.manager.ListClusters(ctx)
+	if err != nil {
+		return ListClustersActivityOutput{}, err
+	}
+
+	return ListClustersActivityOutput{
+		ClusterIDs: clusterIDs,
+	}, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const RefreshKubeconfigActivityName = "cluster-certificates-refresh-kubeconfig"
+
+// RefreshKubeconfigActivity updates the cluster config stored by Pipeline with the renewed admin credentials.
+type RefreshKubeconfigActivity struct {
+	manager clustercert.Manager
+}
+
+type RefreshKubeconfigActivityInput struct {
+	ClusterID uint
+}
+
+// NewRefreshKubeconfigActivity returns a new RefreshKubeconfigActivity.
+func NewRefreshKubeconfigActivity(manager clustercert.Manager) RefreshKubeconfigActivity {
+	return RefreshKubeconfigActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a RefreshKubeconfigActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: RefreshKubeconfigActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a RefreshKubeconfigActivity) Execute(ctx context.Context, input RefreshKubeconfigActivityInput) error {
+	return a.manager.RefreshKubeconfig(ctx, input.ClusterID)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+const RenewCertificatesActivityName = "cluster-certificates-renew-certificates"
+
+// RenewCertificatesActivity renews the control plane certificates of a master node and restarts its components.
+type RenewCertificatesActivity struct {
+	manager clustercert.Manager
+}
+
+type RenewCertificatesActivityInput struct {
+	ClusterID uint
+	Node      string
+}
+
+// NewRenewCertificatesActivity returns a new RenewCertificatesActivity.
+func NewRenewCertificatesActivity(manager clustercert.Manager) RenewCertificatesActivity {
+	return RenewCertificatesActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a RenewCertificatesActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: RenewCertificatesActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a RenewCertificatesActivity) Execute(ctx context.Context, input RenewCertificatesActivityInput) error {
+	heartbeat := startHeartbeat(ctx, 10*time.Second)
+	defer heartbeat.Stop()
+
+	return a.manager.RenewCertificates(ctx, input.ClusterID, input.Node)
+}
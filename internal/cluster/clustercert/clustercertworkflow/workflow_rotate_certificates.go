@@ -0,0 +1,133 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const RotateCertificatesWorkflowName = "cluster-certificates-rotate"
+
+// RotateCertificatesWorkflowID returns the ID of the workflow rotating the certificates of a cluster.
+// There can be only one rotation running for a cluster at a time.
+func RotateCertificatesWorkflowID(clusterID uint) string {
+	return fmt.Sprintf("%s-%d", RotateCertificatesWorkflowName, clusterID)
+}
+
+// RotateCertificatesWorkflow renews the control plane certificates of a cluster node by node
+// and updates the cluster config stored by Pipeline.
+type RotateCertificatesWorkflow struct{}
+
+type RotateCertificatesWorkflowInput struct {
+	ClusterID  uint
+	RotationID uint
+}
+
+// NewRotateCertificatesWorkflow returns a new RotateCertificatesWorkflow.
+func NewRotateCertificatesWorkflow() RotateCertificatesWorkflow {
+	return RotateCertificatesWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w RotateCertificatesWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: RotateCertificatesWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+// Master nodes are renewed one at a time to keep the control plane of HA clusters available.
+func (w RotateCertificatesWorkflow) Execute(ctx workflow.Context, input RotateCertificatesWorkflowInput) error {
+	err := w.rotate(ctx, input)
+
+	finishInput := FinishRotationActivityInput{
+		ClusterID:  input.ClusterID,
+		RotationID: input.RotationID,
+	}
+
+	if err != nil {
+		finishInput.Message = err.Error()
+	}
+
+	finishCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    time.Minute,
+	})
+
+	if finishErr := workflow.ExecuteActivity(finishCtx, FinishRotationActivityName, finishInput).Get(ctx, nil); finishErr != nil {
+		workflow.GetLogger(ctx).Sugar().Warnw(
+			"failed to finish certificate rotation",
+			"clusterId", input.ClusterID,
+			"rotationId", input.RotationID,
+			"error", finishErr.Error(),
+		)
+
+		if err == nil {
+			err = finishErr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Record the renewed certificates right away instead of waiting for the next scheduled check
+	checkCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	})
+
+	checkInput := CheckCertificatesActivityInput{ClusterID: input.ClusterID}
+
+	if err := workflow.ExecuteActivity(checkCtx, CheckCertificatesActivityName, checkInput).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Sugar().Warnw("failed to check cluster certificates", "clusterId", input.ClusterID, "error", err.Error())
+	}
+
+	return nil
+}
+
+func (w RotateCertificatesWorkflow) rotate(ctx workflow.Context, input RotateCertificatesWorkflowInput) error {
+	activityCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	})
+
+	var output BeginRotationActivityOutput
+
+	err := workflow.ExecuteActivity(activityCtx, BeginRotationActivityName, BeginRotationActivityInput{ClusterID: input.ClusterID}).Get(ctx, &output)
+	if err != nil {
+		return err
+	}
+
+	renewCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    30 * time.Minute,
+		HeartbeatTimeout:       time.Minute,
+	})
+
+	for _, node := range output.Nodes {
+		renewInput := RenewCertificatesActivityInput{
+			ClusterID: input.ClusterID,
+			Node:      node,
+		}
+
+		if err := workflow.ExecuteActivity(renewCtx, RenewCertificatesActivityName, renewInput).Get(ctx, nil); err != nil {
+			return err
+		}
+	}
+
+	return workflow.ExecuteActivity(activityCtx, RefreshKubeconfigActivityName, RefreshKubeconfigActivityInput{ClusterID: input.ClusterID}).Get(ctx, nil)
+}
@@ -0,0 +1,90 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
+)
+
+// nolint: gochecknoinits
+func init() {
+	NewBeginRotationActivity(clustercert.Manager{}).Register()
+	NewRenewCertificatesActivity(clustercert.Manager{}).Register()
+	NewRefreshKubeconfigActivity(clustercert.Manager{}).Register()
+	NewFinishRotationActivity(clustercert.Manager{}).Register()
+	NewCheckCertificatesActivity(clustercert.Manager{}).Register()
+}
+
+type RotateCertificatesWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestRotateCertificatesWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(RotateCertificatesWorkflowTestSuite))
+}
+
+func (s *RotateCertificatesWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewRotateCertificatesWorkflow().Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+}
+
+func (s *RotateCertificatesWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *RotateCertificatesWorkflowTestSuite) Test_Success() {
+	s.env.OnActivity(BeginRotationActivityName, mock.Anything, BeginRotationActivityInput{ClusterID: 1}).
+		Return(BeginRotationActivityOutput{Nodes: []string{"master-0", "master-1"}}, nil)
+	s.env.OnActivity(RenewCertificatesActivityName, mock.Anything, RenewCertificatesActivityInput{ClusterID: 1, Node: "master-0"}).Return(nil).Once()
+	s.env.OnActivity(RenewCertificatesActivityName, mock.Anything, RenewCertificatesActivityInput{ClusterID: 1, Node: "master-1"}).Return(nil).Once()
+	s.env.OnActivity(RefreshKubeconfigActivityName, mock.Anything, RefreshKubeconfigActivityInput{ClusterID: 1}).Return(nil)
+	s.env.OnActivity(FinishRotationActivityName, mock.Anything, FinishRotationActivityInput{ClusterID: 1, RotationID: 2}).Return(nil)
+	s.env.OnActivity(CheckCertificatesActivityName, mock.Anything, CheckCertificatesActivityInput{ClusterID: 1}).Return(nil)
+
+	s.env.ExecuteWorkflow(s.T().Name(), RotateCertificatesWorkflowInput{ClusterID: 1, RotationID: 2})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *RotateCertificatesWorkflowTestSuite) Test_RenewFailure() {
+	s.env.OnActivity(BeginRotationActivityName, mock.Anything, BeginRotationActivityInput{ClusterID: 1}).
+		Return(BeginRotationActivityOutput{Nodes: []string{"master-0", "master-1"}}, nil)
+	s.env.OnActivity(RenewCertificatesActivityName, mock.Anything, RenewCertificatesActivityInput{ClusterID: 1, Node: "master-0"}).
+		Return(errors.New("kubeadm failed")).Once()
+	s.env.OnActivity(FinishRotationActivityName, mock.Anything, mock.MatchedBy(func(input FinishRotationActivityInput) bool {
+		return input.ClusterID == 1 && input.RotationID == 2 && input.Message != ""
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(s.T().Name(), RotateCertificatesWorkflowInput{ClusterID: 1, RotationID: 2})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
@@ -0,0 +1,68 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercertworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const SchedulerWorkflowName = "cluster-certificates-scheduler"
+
+// SchedulerWorkflow checks the control plane certificates of every PKE cluster.
+type SchedulerWorkflow struct{}
+
+// NewSchedulerWorkflow returns a new SchedulerWorkflow.
+func NewSchedulerWorkflow() SchedulerWorkflow {
+	return SchedulerWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w SchedulerWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: SchedulerWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w SchedulerWorkflow) Execute(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	})
+
+	var output ListClustersActivityOutput
+
+	err := workflow.ExecuteActivity(ctx, ListClustersActivityName, ListClustersActivityInput{}).Get(ctx, &output)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	futures := make([]workflow.Future, 0, len(output.ClusterIDs))
+
+	for _, clusterID := range output.ClusterIDs {
+		futures = append(futures, workflow.ExecuteActivity(ctx, CheckCertificatesActivityName, CheckCertificatesActivityInput{ClusterID: clusterID}))
+	}
+
+	// A cluster that cannot be checked should not stop checking the others
+	for i, future := range futures {
+		if err := future.Get(ctx, nil); err != nil {
+			logger.Warnw("failed to check cluster certificates", "clusterId", output.ClusterIDs[i], "error", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercert
+
+// ValidationError is returned when a request is semantically invalid.
+type ValidationError struct {
+	message    string
+	violations []string
+}
+
+// NewValidationError returns a new ValidationError.
+func NewValidationError(message string, violations []string) ValidationError {
+	return ValidationError{
+		message:    message,
+		violations: violations,
+	}
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return "invalid request"
+}
+
+// Violations returns details of the failed validation.
+func (e ValidationError) Violations() []string {
+	return e.violations[:]
+}
+
+// Validation tells a client that this error is related to a semantic validation of the request.
+// Can be used to translate the error to status codes for example.
+func (ValidationError) Validation() bool {
+	return true
+}
+
+// ServiceError tells the consumer whether this error is caused by invalid input supplied by the client.
+// Client errors are usually returned to the consumer without retrying the operation.
+func (ValidationError) ServiceError() bool {
+	return true
+}
+
+// CertificatesNotFoundError is returned when the certificates of a cluster have not been discovered yet.
+type CertificatesNotFoundError struct {
+	ClusterID uint
+}
+
+// Error implements the error interface.
+func (CertificatesNotFoundError) Error() string {
+	return "cluster certificates not found"
+}
+
+// Details returns error details.
+func (e CertificatesNotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (CertificatesNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (CertificatesNotFoundError) ServiceError() bool {
+	return true
+}
+
+// RotationNotFoundError is returned when a certificate rotation cannot be found.
+type RotationNotFoundError struct {
+	ClusterID  uint
+	RotationID uint
+}
+
+// Error implements the error interface.
+func (RotationNotFoundError) Error() string {
+	return "certificate rotation not found"
+}
+
+// Details returns error details.
+func (e RotationNotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID, "rotationId", e.RotationID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (RotationNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (RotationNotFoundError) ServiceError() bool {
+	return true
+}
+
+// RotationInProgressError is returned when the certificates of a cluster are already being rotated.
+type RotationInProgressError struct {
+	ClusterID uint
+}
+
+// Error implements the error interface.
+func (RotationInProgressError) Error() string {
+	return "certificate rotation is already in progress"
+}
+
+// Details returns error details.
+func (e RotationInProgressError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID}
+}
+
+// Conflict tells the consumer that this error is related to a conflicting request.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (RotationInProgressError) Conflict() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (RotationInProgressError) ServiceError() bool {
+	return true
+}
@@ -0,0 +1,340 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercert
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+)
+
+// RotatingMessage is the status message of clusters while their certificates are being rotated.
+const RotatingMessage = "Rotating control plane certificates"
+
+// ControlPlane reads and renews the certificates of the master nodes of clusters.
+type ControlPlane interface {
+	// MasterNodes lists the master nodes of a cluster.
+	MasterNodes(ctx context.Context, clusterID uint) ([]string, error)
+
+	// ReadCertificates reads the control plane certificates of a master node.
+	ReadCertificates(ctx context.Context, clusterID uint, node string) ([]Certificate, error)
+
+	// RenewCertificates renews the control plane certificates of a master node
+	// and restarts the control plane components on the node.
+	RenewCertificates(ctx context.Context, clusterID uint, node string) error
+
+	// ReadAdminKubeconfig reads the admin kubeconfig of a master node.
+	ReadAdminKubeconfig(ctx context.Context, clusterID uint, node string) ([]byte, error)
+}
+
+// ClusterLister lists clusters with certificates managed by Pipeline.
+type ClusterLister interface {
+	// ListClusters lists the IDs of running PKE clusters.
+	ListClusters(ctx context.Context) ([]uint, error)
+}
+
+// Notification is a warning about the expiring certificates of a cluster.
+type Notification struct {
+	OrganizationID uint
+	ClusterID      uint
+	ClusterName    string
+
+	// Certificate is the name of the certificate expiring first.
+	Certificate string
+	Node        string
+	ExpiresAt   time.Time
+	Expired     bool
+
+	// NotificationSecretID is the ID of a Slack secret the notification is sent to (optional).
+	NotificationSecretID string
+}
+
+// Message returns a human readable notification message.
+func (n Notification) Message() string {
+	verb := "expires"
+	if n.Expired {
+		verb = "expired"
+	}
+
+	return fmt.Sprintf(
+		"Control plane certificate %q on node %s of cluster %q %s at %s",
+		n.Certificate, n.Node, n.ClusterName, verb, n.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+}
+
+// +testify:mock:testOnly=true
+
+// Notifier sends warnings about expiring certificates.
+type Notifier interface {
+	// Notify sends a notification.
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Manager checks and rotates the control plane certificates of clusters.
+type Manager struct {
+	config       Config
+	store        Store
+	clusters     ClusterStore
+	lister       ClusterLister
+	controlPlane ControlPlane
+	secrets      secret.Store
+	notifier     Notifier
+
+	logger Logger
+}
+
+// NewManager returns a new Manager.
+func NewManager(
+	config Config,
+	store Store,
+	clusters ClusterStore,
+	lister ClusterLister,
+	controlPlane ControlPlane,
+	secrets secret.Store,
+	notifier Notifier,
+	logger Logger,
+) Manager {
+	return Manager{
+		config:       config,
+		store:        store,
+		clusters:     clusters,
+		lister:       lister,
+		controlPlane: controlPlane,
+		secrets:      secrets,
+		notifier:     notifier,
+
+		logger: logger,
+	}
+}
+
+// ListClusters lists the clusters whose certificates should be checked.
+func (m Manager) ListClusters(ctx context.Context) ([]uint, error) {
+	return m.lister.ListClusters(ctx)
+}
+
+// CheckCertificates reads and records the control plane certificates of a cluster
+// and sends a warning if a certificate expires within the warning threshold.
+func (m Manager) CheckCertificates(ctx context.Context, clusterID uint, now time.Time) error {
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := m.controlPlane.MasterNodes(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) == 0 {
+		return errors.NewWithDetails("no master nodes found", "clusterId", clusterID)
+	}
+
+	var certificates []Certificate
+
+	for _, node := range nodes {
+		nodeCertificates, err := m.controlPlane.ReadCertificates(ctx, clusterID, node)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to read certificates", "node", node)
+		}
+
+		certificates = append(certificates, nodeCertificates...)
+	}
+
+	if err := m.store.PutCertificates(ctx, clusterID, certificates, now); err != nil {
+		return err
+	}
+
+	summary, err := getCertificates(ctx, m.store, clusterID, m.config.WarningThreshold, now)
+	if errors.As(err, &CertificatesNotFoundError{}) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !summary.Expiring {
+		return nil
+	}
+
+	settings, err := m.store.GetSettings(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	first := summary.Certificates[0]
+
+	return m.notifier.Notify(ctx, Notification{
+		OrganizationID:       c.OrganizationID,
+		ClusterID:            c.ID,
+		ClusterName:          c.Name,
+		Certificate:          first.Name,
+		Node:                 first.Node,
+		ExpiresAt:            first.ExpiresAt,
+		Expired:              !first.ExpiresAt.After(now),
+		NotificationSecretID: settings.NotificationSecretID,
+	})
+}
+
+// BeginRotation marks a cluster as being updated and returns the master nodes to renew the certificates of.
+func (m Manager) BeginRotation(ctx context.Context, clusterID uint) ([]string, error) {
+	nodes, err := m.controlPlane.MasterNodes(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.NewWithDetails("no master nodes found", "clusterId", clusterID)
+	}
+
+	if err := m.clusters.SetStatus(ctx, clusterID, cluster.Updating, RotatingMessage); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// RenewCertificates renews the control plane certificates of a master node.
+func (m Manager) RenewCertificates(ctx context.Context, clusterID uint, node string) error {
+	return errors.WrapIfWithDetails(
+		m.controlPlane.RenewCertificates(ctx, clusterID, node),
+		"failed to renew certificates",
+		"node", node,
+	)
+}
+
+// RefreshKubeconfig replaces the credentials in the kubeconfig Pipeline uses for accessing a cluster
+// with the renewed credentials of the admin kubeconfig on a master node.
+func (m Manager) RefreshKubeconfig(ctx context.Context, clusterID uint) error {
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := m.controlPlane.MasterNodes(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) == 0 {
+		return errors.NewWithDetails("no master nodes found", "clusterId", clusterID)
+	}
+
+	adminKubeconfig, err := m.controlPlane.ReadAdminKubeconfig(ctx, clusterID, nodes[0])
+	if err != nil {
+		return err
+	}
+
+	configSecret, err := m.secrets.Get(ctx, c.OrganizationID, c.ConfigSecretID.ResourceID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get cluster config secret")
+	}
+
+	kubeconfig, err := base64.StdEncoding.DecodeString(configSecret.Values[secrettype.K8SConfig])
+	if err != nil {
+		return errors.WrapIf(err, "failed to decode cluster config")
+	}
+
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return errors.WrapIf(err, "failed to load cluster config")
+	}
+
+	sourceConfig, err := clientcmd.Load(adminKubeconfig)
+	if err != nil {
+		return errors.WrapIf(err, "failed to load admin kubeconfig")
+	}
+
+	if err := replaceCredentials(config, sourceConfig); err != nil {
+		return err
+	}
+
+	kubeconfig, err = clientcmd.Write(*config)
+	if err != nil {
+		return errors.WrapIf(err, "failed to serialize cluster config")
+	}
+
+	configSecret.Values[secrettype.K8SConfig] = base64.StdEncoding.EncodeToString(kubeconfig)
+
+	return errors.WrapIf(m.secrets.Put(ctx, c.OrganizationID, configSecret), "failed to update cluster config secret")
+}
+
+// replaceCredentials replaces the user credentials of the current context of a kubeconfig with the ones of another.
+// The cluster endpoint is kept, since the one stored by Pipeline may differ from the one on the nodes (eg. public access points).
+func replaceCredentials(config *clientcmdapi.Config, sourceConfig *clientcmdapi.Config) error {
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return errors.New("current context not found in cluster config")
+	}
+
+	sourceContext, ok := sourceConfig.Contexts[sourceConfig.CurrentContext]
+	if !ok {
+		return errors.New("current context not found in admin kubeconfig")
+	}
+
+	authInfo, ok := sourceConfig.AuthInfos[sourceContext.AuthInfo]
+	if !ok || len(authInfo.ClientCertificateData) == 0 || len(authInfo.ClientKeyData) == 0 {
+		return errors.New("client certificate not found in admin kubeconfig")
+	}
+
+	config.AuthInfos[context.AuthInfo] = authInfo
+
+	return nil
+}
+
+// FinishRotation records the outcome of a rotation and restores the status of the cluster.
+// An empty failure message means the rotation succeeded.
+func (m Manager) FinishRotation(ctx context.Context, clusterID uint, rotationID uint, failure string) error {
+	rotation, err := m.store.GetRotation(ctx, clusterID, rotationID)
+	if err != nil {
+		return err
+	}
+
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	// The status of the cluster is only restored if it was set by the rotation
+	if c.Status == cluster.Updating && c.StatusMessage == RotatingMessage {
+		status, message := cluster.Running, cluster.RunningMessage
+		if failure != "" {
+			status, message = cluster.Warning, "Failed to rotate control plane certificates: "+failure
+		}
+
+		if err := m.clusters.SetStatus(ctx, clusterID, status, message); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	rotation.Status = RotationSucceeded
+	rotation.StatusMessage = ""
+	rotation.FinishedAt = &now
+
+	if failure != "" {
+		rotation.Status = RotationFailed
+		rotation.StatusMessage = failure
+	}
+
+	return m.store.UpdateRotation(ctx, rotation)
+}
@@ -0,0 +1,252 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustercert
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	internaltesting "github.com/banzaicloud/pipeline/internal/testing"
+	"github.com/banzaicloud/pipeline/pkg/brn"
+)
+
+type fakeControlPlane struct {
+	nodes        []string
+	certificates map[string][]Certificate
+	renewed      []string
+	kubeconfig   []byte
+}
+
+func (c *fakeControlPlane) MasterNodes(_ context.Context, _ uint) ([]string, error) {
+	return c.nodes, nil
+}
+
+func (c *fakeControlPlane) ReadCertificates(_ context.Context, _ uint, node string) ([]Certificate, error) {
+	return c.certificates[node], nil
+}
+
+func (c *fakeControlPlane) RenewCertificates(_ context.Context, _ uint, node string) error {
+	c.renewed = append(c.renewed, node)
+
+	return nil
+}
+
+func (c *fakeControlPlane) ReadAdminKubeconfig(_ context.Context, _ uint, _ string) ([]byte, error) {
+	return c.kubeconfig, nil
+}
+
+func newKubeconfig(server string, certificate string, key string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: kubernetes
+  cluster:
+    server: %s
+users:
+- name: kubernetes-admin
+  user:
+    client-certificate-data: %s
+    client-key-data: %s
+contexts:
+- name: kubernetes-admin@kubernetes
+  context:
+    cluster: kubernetes
+    user: kubernetes-admin
+current-context: kubernetes-admin@kubernetes
+`,
+		server,
+		base64.StdEncoding.EncodeToString([]byte(certificate)),
+		base64.StdEncoding.EncodeToString([]byte(key)),
+	))
+}
+
+func TestManager_CheckCertificates(t *testing.T) {
+	now := time.Date(2020, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	controlPlane := &fakeControlPlane{
+		nodes: []string{"master-0", "master-1"},
+		certificates: map[string][]Certificate{
+			"master-0": {{Node: "master-0", Name: "pki/apiserver.crt", ExpiresAt: now.Add(400 * 24 * time.Hour)}},
+			"master-1": {{Node: "master-1", Name: "admin.conf", ExpiresAt: now.Add(10 * 24 * time.Hour)}},
+		},
+	}
+
+	certificates := []Certificate{
+		controlPlane.certificates["master-0"][0],
+		controlPlane.certificates["master-1"][0],
+	}
+
+	store := new(MockStore)
+	store.On("PutCertificates", mock.Anything, uint(1), certificates, now).Return(nil)
+	store.On("ListCertificates", mock.Anything, uint(1)).Return(certificates, now, nil)
+	store.On("GetSettings", mock.Anything, uint(1)).Return(Settings{ClusterID: 1, NotificationSecretID: "slack"}, nil)
+
+	notifier := new(MockNotifier)
+	notifier.On("Notify", mock.Anything, Notification{
+		OrganizationID:       1,
+		ClusterID:            1,
+		ClusterName:          "cluster",
+		Certificate:          "admin.conf",
+		Node:                 "master-1",
+		ExpiresAt:            now.Add(10 * 24 * time.Hour),
+		NotificationSecretID: "slack",
+	}).Return(nil)
+
+	manager := NewManager(
+		Config{WarningThreshold: 30 * 24 * time.Hour},
+		store,
+		newInMemoryClusterStore(pkeCluster(1, cluster.Running)),
+		nil,
+		controlPlane,
+		nil,
+		notifier,
+		NoopLogger{},
+	)
+
+	require.NoError(t, manager.CheckCertificates(context.Background(), 1, now))
+
+	store.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestManager_CheckCertificates_NotExpiring(t *testing.T) {
+	now := time.Date(2020, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	certificates := []Certificate{{Node: "master-0", Name: "pki/apiserver.crt", ExpiresAt: now.Add(400 * 24 * time.Hour)}}
+
+	store := new(MockStore)
+	store.On("PutCertificates", mock.Anything, uint(1), certificates, now).Return(nil)
+	store.On("ListCertificates", mock.Anything, uint(1)).Return(certificates, now, nil)
+
+	manager := NewManager(
+		Config{WarningThreshold: 30 * 24 * time.Hour},
+		store,
+		newInMemoryClusterStore(pkeCluster(1, cluster.Running)),
+		nil,
+		&fakeControlPlane{nodes: []string{"master-0"}, certificates: map[string][]Certificate{"master-0": certificates}},
+		nil,
+		new(MockNotifier),
+		NoopLogger{},
+	)
+
+	require.NoError(t, manager.CheckCertificates(context.Background(), 1, now))
+
+	store.AssertExpectations(t)
+}
+
+func TestManager_RefreshKubeconfig(t *testing.T) {
+	c := pkeCluster(1, cluster.Updating)
+	c.ConfigSecretID = brn.New(1, brn.SecretResourceType, "config")
+
+	secrets := internaltesting.NewSecretStore(t, 1, secret.Model{
+		ID:   "config",
+		Type: secrettype.Kubernetes,
+		Values: map[string]string{
+			secrettype.K8SConfig: base64.StdEncoding.EncodeToString(newKubeconfig("https://public:6443", "old-cert", "old-key")),
+		},
+	})
+
+	controlPlane := &fakeControlPlane{
+		nodes:      []string{"master-0"},
+		kubeconfig: newKubeconfig("https://10.0.0.1:6443", "new-cert", "new-key"),
+	}
+
+	manager := NewManager(Config{}, new(MockStore), newInMemoryClusterStore(c), nil, controlPlane, secrets, new(MockNotifier), NoopLogger{})
+
+	require.NoError(t, manager.RefreshKubeconfig(context.Background(), 1))
+
+	stored, err := secrets.Get(context.Background(), 1, "config")
+	require.NoError(t, err)
+
+	kubeconfig, err := base64.StdEncoding.DecodeString(stored.Values[secrettype.K8SConfig])
+	require.NoError(t, err)
+
+	config, err := clientcmd.Load(kubeconfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://public:6443", config.Clusters["kubernetes"].Server)
+	assert.Equal(t, []byte("new-cert"), config.AuthInfos["kubernetes-admin"].ClientCertificateData)
+	assert.Equal(t, []byte("new-key"), config.AuthInfos["kubernetes-admin"].ClientKeyData)
+}
+
+func TestManager_Rotation(t *testing.T) {
+	clusters := newInMemoryClusterStore(pkeCluster(1, cluster.Running))
+	controlPlane := &fakeControlPlane{nodes: []string{"master-0", "master-1"}}
+
+	store := new(MockStore)
+	store.On("GetRotation", mock.Anything, uint(1), uint(2)).Return(Rotation{ID: 2, ClusterID: 1, Status: RotationRunning}, nil)
+	store.On("UpdateRotation", mock.Anything, mock.MatchedBy(func(rotation Rotation) bool {
+		return rotation.ID == 2 && rotation.Status == RotationSucceeded && rotation.FinishedAt != nil
+	})).Return(nil)
+
+	manager := NewManager(Config{}, store, clusters, nil, controlPlane, nil, new(MockNotifier), NoopLogger{})
+
+	nodes, err := manager.BeginRotation(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"master-0", "master-1"}, nodes)
+	assert.Equal(t, cluster.Updating, clusters.clusters[1].Status)
+	assert.Equal(t, RotatingMessage, clusters.clusters[1].StatusMessage)
+
+	for _, node := range nodes {
+		require.NoError(t, manager.RenewCertificates(context.Background(), 1, node))
+	}
+
+	assert.Equal(t, nodes, controlPlane.renewed)
+
+	require.NoError(t, manager.FinishRotation(context.Background(), 1, 2, ""))
+	assert.Equal(t, cluster.Running, clusters.clusters[1].Status)
+
+	store.AssertExpectations(t)
+}
+
+func TestManager_FinishRotation_Failure(t *testing.T) {
+	c := pkeCluster(1, cluster.Updating)
+	c.StatusMessage = RotatingMessage
+
+	other := pkeCluster(2, cluster.Updating)
+	other.StatusMessage = "Upgrading cluster"
+
+	clusters := newInMemoryClusterStore(c, other)
+
+	store := new(MockStore)
+	store.On("GetRotation", mock.Anything, mock.Anything, uint(3)).Return(Rotation{ID: 3, Status: RotationRunning}, nil)
+	store.On("UpdateRotation", mock.Anything, mock.MatchedBy(func(rotation Rotation) bool {
+		return rotation.Status == RotationFailed && rotation.StatusMessage == "kubeadm failed"
+	})).Return(nil)
+
+	manager := NewManager(Config{}, store, clusters, nil, &fakeControlPlane{}, nil, new(MockNotifier), NoopLogger{})
+
+	require.NoError(t, manager.FinishRotation(context.Background(), 1, 3, "kubeadm failed"))
+	assert.Equal(t, cluster.Warning, clusters.clusters[1].Status)
+	assert.Contains(t, clusters.clusters[1].StatusMessage, "kubeadm failed")
+
+	// The status set by other operations is kept
+	require.NoError(t, manager.FinishRotation(context.Background(), 2, 3, "kubeadm failed"))
+	assert.Equal(t, cluster.Updating, clusters.clusters[2].Status)
+	assert.Equal(t, "Upgrading cluster", clusters.clusters[2].StatusMessage)
+
+	store.AssertExpectations(t)
+}
@@ -62,6 +62,13 @@ func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...ki
 		kitxhttp.ErrorResponseEncoder(encodeDeleteNodePoolHTTPResponse, errorEncoder),
 		options...,
 	))
+
+	router.Methods(http.MethodPost).Path("/upgrade").Handler(kithttp.NewServer(
+		endpoints.UpgradeCluster,
+		decodeUpgradeClusterHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeUpgradeClusterHTTPResponse, errorEncoder),
+		options...,
+	))
 }
 
 func decodeDeleteClusterHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
@@ -273,3 +280,44 @@ func encodeDeleteNodePoolHTTPResponse(_ context.Context, w http.ResponseWriter,
 
 	return nil
 }
+
+// upgradeClusterRequest is the body of a cluster upgrade request.
+type upgradeClusterRequest struct {
+	// Kubernetes version the cluster is upgraded to.
+	Version string `json:"version"`
+}
+
+// upgradeClusterResponse is the body of a cluster upgrade response.
+type upgradeClusterResponse struct {
+	// Cluster upgrade process ID.
+	ProcessId string `json:"processId,omitempty"`
+}
+
+func decodeUpgradeClusterHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := getClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var request upgradeClusterRequest
+
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode request")
+	}
+
+	return UpgradeClusterRequest{
+		ClusterID: clusterID,
+		Version:   request.Version,
+	}, nil
+}
+
+func encodeUpgradeClusterHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(UpgradeClusterResponse)
+
+	apiResp := upgradeClusterResponse{
+		ProcessId: resp.ProcessID,
+	}
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(apiResp, http.StatusAccepted))
+}
@@ -30,6 +30,7 @@ type Endpoints struct {
 	DeleteCluster  endpoint.Endpoint
 	DeleteNodePool endpoint.Endpoint
 	UpdateNodePool endpoint.Endpoint
+	UpgradeCluster endpoint.Endpoint
 }
 
 // MakeEndpoints returns a(n) Endpoints struct where each endpoint invokes
@@ -42,6 +43,7 @@ func MakeEndpoints(service cluster.Service, middleware ...endpoint.Middleware) E
 		DeleteCluster:  kitxendpoint.OperationNameMiddleware("cluster.DeleteCluster")(mw(MakeDeleteClusterEndpoint(service))),
 		DeleteNodePool: kitxendpoint.OperationNameMiddleware("cluster.DeleteNodePool")(mw(MakeDeleteNodePoolEndpoint(service))),
 		UpdateNodePool: kitxendpoint.OperationNameMiddleware("cluster.UpdateNodePool")(mw(MakeUpdateNodePoolEndpoint(service))),
+		UpgradeCluster: kitxendpoint.OperationNameMiddleware("cluster.UpgradeCluster")(mw(MakeUpgradeClusterEndpoint(service))),
 	}
 }
 
@@ -202,3 +204,44 @@ func MakeUpdateNodePoolEndpoint(service cluster.Service) endpoint.Endpoint {
 		return UpdateNodePoolResponse{ProcessID: processID}, nil
 	}
 }
+
+// UpgradeClusterRequest is a request struct for UpgradeCluster endpoint.
+type UpgradeClusterRequest struct {
+	ClusterID uint
+	Version   string
+}
+
+// UpgradeClusterResponse is a response struct for UpgradeCluster endpoint.
+type UpgradeClusterResponse struct {
+	ProcessID string
+	Err       error
+}
+
+func (r UpgradeClusterResponse) Failed() error {
+	return r.Err
+}
+
+// MakeUpgradeClusterEndpoint returns an endpoint for the matching method of the underlying service.
+func MakeUpgradeClusterEndpoint(service cluster.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpgradeClusterRequest)
+
+		processID, err := service.UpgradeCluster(ctx, req.ClusterID, req.Version)
+
+		if err != nil {
+			if serviceErr := serviceError(nil); errors.As(err, &serviceErr) && serviceErr.ServiceError() {
+				return UpgradeClusterResponse{
+					Err:       err,
+					ProcessID: processID,
+				}, nil
+			}
+
+			return UpgradeClusterResponse{
+				Err:       err,
+				ProcessID: processID,
+			}, err
+		}
+
+		return UpgradeClusterResponse{ProcessID: processID}, nil
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterworkflow
+
+import (
+	"context"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const CordonNodesActivityName = "cordon-nodes"
+
+type CordonNodesActivity struct {
+	clientFactory ClientFactory
+}
+
+// NewCordonNodesActivity returns a new CordonNodesActivity.
+func NewCordonNodesActivity(clientFactory ClientFactory) CordonNodesActivity {
+	return CordonNodesActivity{
+		clientFactory: clientFactory,
+	}
+}
+
+type CordonNodesActivityInput struct {
+	ClusterID uint
+	NodeNames []string
+
+	// Uncordon makes the nodes schedulable again instead.
+	Uncordon bool
+}
+
+func (a CordonNodesActivity) Execute(ctx context.Context, input CordonNodesActivityInput) error {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return cadence.WrapClientError(err)
+	}
+
+	drainer := kubernetes.NewNodeDrainer(client, kubernetes.NodeDrainOptions{})
+
+	for _, nodeName := range input.NodeNames {
+		if input.Uncordon {
+			err = drainer.Uncordon(nodeName)
+		} else {
+			err = drainer.Cordon(nodeName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
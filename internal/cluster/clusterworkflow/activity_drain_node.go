@@ -0,0 +1,71 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterworkflow
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const DrainNodeActivityName = "drain-node"
+
+// ErrReasonNodeDrainTimeout is returned when a node could not be drained in time.
+const ErrReasonNodeDrainTimeout = "NODE_DRAIN_TIMEOUT"
+
+type DrainNodeActivity struct {
+	clientFactory ClientFactory
+	options       kubernetes.NodeDrainOptions
+}
+
+// NewDrainNodeActivity returns a new DrainNodeActivity.
+func NewDrainNodeActivity(clientFactory ClientFactory, options kubernetes.NodeDrainOptions) DrainNodeActivity {
+	return DrainNodeActivity{
+		clientFactory: clientFactory,
+		options:       options,
+	}
+}
+
+type DrainNodeActivityInput struct {
+	ClusterID uint
+	NodeName  string
+}
+
+// Execute cordons a node and evicts its pods.
+// The number of remaining pods is recorded in the activity heartbeat.
+func (a DrainNodeActivity) Execute(ctx context.Context, input DrainNodeActivityInput) error {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return _cadence.WrapClientError(err)
+	}
+
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.NodeName)
+
+	err = kubernetes.NewNodeDrainer(client, a.options).Drain(ctx, input.NodeName, func(progress kubernetes.NodeDrainProgress) {
+		logger.Infow("draining node", "remainingPods", progress.RemainingPods)
+
+		activity.RecordHeartbeat(ctx, progress)
+	})
+	if errors.Is(err, kubernetes.ErrNodeDrainTimeout) {
+		return cadence.NewCustomError(ErrReasonNodeDrainTimeout, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterworkflow
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const GetNodeByProviderIDActivityName = "get-node-by-provider-id"
+
+// GetNodeByProviderIDActivity finds the node running on a specific cloud provider instance.
+type GetNodeByProviderIDActivity struct {
+	clientFactory ClientFactory
+}
+
+// NewGetNodeByProviderIDActivity returns a new GetNodeByProviderIDActivity.
+func NewGetNodeByProviderIDActivity(clientFactory ClientFactory) GetNodeByProviderIDActivity {
+	return GetNodeByProviderIDActivity{
+		clientFactory: clientFactory,
+	}
+}
+
+type GetNodeByProviderIDActivityInput struct {
+	ClusterID  uint
+	ProviderID string
+}
+
+type GetNodeByProviderIDActivityOutput struct {
+	// NodeName is empty if there is no node with the given provider ID (eg. it has already left the cluster).
+	NodeName string
+}
+
+func (a GetNodeByProviderIDActivity) Execute(ctx context.Context, input GetNodeByProviderIDActivityInput) (GetNodeByProviderIDActivityOutput, error) {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return GetNodeByProviderIDActivityOutput{}, cadence.WrapClientError(err)
+	}
+
+	// nodes cannot be filtered by provider ID on the server side
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return GetNodeByProviderIDActivityOutput{}, errors.WrapIf(err, "failed to list nodes")
+	}
+
+	for _, node := range nodes.Items {
+		if node.Spec.ProviderID == input.ProviderID {
+			return GetNodeByProviderIDActivityOutput{NodeName: node.Name}, nil
+		}
+	}
+
+	return GetNodeByProviderIDActivityOutput{}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterworkflow
+
+import (
+	"context"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const ListNodePoolNodesActivityName = "list-node-pool-nodes"
+
+type ListNodePoolNodesActivity struct {
+	clientFactory ClientFactory
+}
+
+// NewListNodePoolNodesActivity returns a new ListNodePoolNodesActivity.
+func NewListNodePoolNodesActivity(clientFactory ClientFactory) ListNodePoolNodesActivity {
+	return ListNodePoolNodesActivity{
+		clientFactory: clientFactory,
+	}
+}
+
+type ListNodePoolNodesActivityInput struct {
+	ClusterID    uint
+	NodePoolName string
+
+	// SkipVersion excludes nodes already running this node pool version (optional).
+	SkipVersion string
+}
+
+type ListNodePoolNodesActivityOutput struct {
+	NodeNames []string
+}
+
+func (a ListNodePoolNodesActivity) Execute(ctx context.Context, input ListNodePoolNodesActivityInput) (ListNodePoolNodesActivityOutput, error) {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return ListNodePoolNodesActivityOutput{}, cadence.WrapClientError(err)
+	}
+
+	nodeNames, err := kubernetes.NewNodeDrainer(client, kubernetes.NodeDrainOptions{}).ListNodePoolNodes(input.NodePoolName, input.SkipVersion)
+	if err != nil {
+		return ListNodePoolNodesActivityOutput{}, err
+	}
+
+	return ListNodePoolNodesActivityOutput{NodeNames: nodeNames}, nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 // DynamicClientFactory returns a dynamic Kubernetes client.
@@ -25,3 +26,9 @@ type DynamicClientFactory interface {
 	// FromClusterID creates a dynamic Kubernetes client for a cluster from a cluster ID.
 	FromClusterID(ctx context.Context, clusterID uint) (dynamic.Interface, error)
 }
+
+// ClientFactory returns a Kubernetes client.
+type ClientFactory interface {
+	// FromClusterID creates a Kubernetes client for a cluster from a cluster ID.
+	FromClusterID(ctx context.Context, clusterID uint) (kubernetes.Interface, error)
+}
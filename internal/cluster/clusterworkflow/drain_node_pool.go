@@ -0,0 +1,179 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+// DrainNodePoolInput holds the parameters for draining a node pool.
+type DrainNodePoolInput struct {
+	ClusterID    uint
+	NodePoolName string
+
+	// SkipVersion excludes nodes already running this node pool version (optional).
+	SkipVersion string
+}
+
+// DrainNodePool cordons every node of a node pool, then drains them one by one and returns the drained nodes.
+// Each node is reported as a separate process activity when a process is given.
+// If any of the nodes cannot be drained, all of them are made schedulable again.
+func DrainNodePool(ctx workflow.Context, process processlog.Process, input DrainNodePoolInput) ([]string, error) {
+	nodeNames, err := listNodePoolNodes(ctx, input.ClusterID, input.NodePoolName, input.SkipVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodeNames) == 0 {
+		return nil, nil
+	}
+
+	// Cordon every node first, so that evicted pods are not rescheduled to other nodes of the same node pool
+	{
+		activityInput := CordonNodesActivityInput{
+			ClusterID: input.ClusterID,
+			NodeNames: nodeNames,
+		}
+
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, nodeActivityOptions()),
+			CordonNodesActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, nodeName := range nodeNames {
+		err := DrainNode(ctx, process, input.ClusterID, nodeName)
+		if err != nil {
+			UncordonNodes(ctx, input.ClusterID, nodeNames)
+
+			return nil, err
+		}
+	}
+
+	return nodeNames, nil
+}
+
+func listNodePoolNodes(ctx workflow.Context, clusterID uint, nodePoolName string, skipVersion string) ([]string, error) {
+	activityInput := ListNodePoolNodesActivityInput{
+		ClusterID:    clusterID,
+		NodePoolName: nodePoolName,
+		SkipVersion:  skipVersion,
+	}
+
+	var output ListNodePoolNodesActivityOutput
+
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, nodeActivityOptions()),
+		ListNodePoolNodesActivityName,
+		activityInput,
+	).Get(ctx, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NodeNames, nil
+}
+
+// DrainNode drains a single node, reporting it as a separate process activity when a process is given.
+func DrainNode(ctx workflow.Context, process processlog.Process, clusterID uint, nodeName string) error {
+	activityInput := DrainNodeActivityInput{
+		ClusterID: clusterID,
+		NodeName:  nodeName,
+	}
+
+	activityOptions := nodeActivityOptions()
+	activityOptions.StartToCloseTimeout = 2 * time.Hour // the drain timeout is enforced by the activity itself
+	activityOptions.HeartbeatTimeout = time.Minute
+	activityOptions.RetryPolicy = &cadence.RetryPolicy{
+		InitialInterval:    15 * time.Second,
+		BackoffCoefficient: 1.0,
+		MaximumAttempts:    3,
+		NonRetriableErrorReasons: []string{
+			"cadenceInternal:Panic",
+			_cadence.ClientErrorReason,
+			ErrReasonNodeDrainTimeout,
+		},
+	}
+
+	var processActivity processlog.Activity
+	if process != nil {
+		processActivity = process.StartActivity(ctx, DrainNodeActivityName)
+	}
+
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, activityOptions),
+		DrainNodeActivityName,
+		activityInput,
+	).Get(ctx, nil)
+
+	if processActivity != nil {
+		processActivity.Finish(ctx, err)
+	}
+
+	return err
+}
+
+// UncordonNodes makes previously cordoned nodes schedulable again.
+// Failures are only logged, since this is a best effort cleanup step.
+func UncordonNodes(ctx workflow.Context, clusterID uint, nodeNames []string) {
+	if len(nodeNames) == 0 {
+		return
+	}
+
+	// the nodes should be uncordoned even if the workflow is canceled
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+
+	activityInput := CordonNodesActivityInput{
+		ClusterID: clusterID,
+		NodeNames: nodeNames,
+		Uncordon:  true,
+	}
+
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, nodeActivityOptions()),
+		CordonNodesActivityName,
+		activityInput,
+	).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Sugar().Warnf("failed to uncordon nodes: %s", err)
+	}
+}
+
+func nodeActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    2 * time.Minute,
+		WaitForCancellation:    true,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    10,
+			NonRetriableErrorReasons: []string{
+				"cadenceInternal:Panic",
+				_cadence.ClientErrorReason,
+			},
+		},
+	}
+}
@@ -46,6 +46,19 @@ func DeleteNodePoolWorkflow(ctx workflow.Context, input DeleteNodePoolWorkflowIn
 	_ctx := ctx
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
+	{
+		input := DrainNodePoolInput{
+			ClusterID:    input.ClusterID,
+			NodePoolName: input.NodePoolName,
+		}
+
+		// the node pool is deleted anyway, so a failed drain should not prevent the deletion
+		_, err := DrainNodePool(_ctx, nil, input)
+		if err != nil {
+			workflow.GetLogger(ctx).Sugar().Warnf("failed to drain node pool before deletion: %s", err)
+		}
+	}
+
 	{
 		input := DeleteNodePoolActivityInput{
 			ClusterID:    input.ClusterID,
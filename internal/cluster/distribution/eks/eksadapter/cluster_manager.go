@@ -0,0 +1,83 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksworkflow"
+)
+
+type clusterManager struct {
+	workflowClient client.Client
+	enterprise     bool
+}
+
+// NewClusterManager returns a new eks.ClusterManager
+// that manages clusters asynchronously via Cadence workflows.
+func NewClusterManager(workflowClient client.Client, enterprise bool) eks.ClusterManager {
+	return clusterManager{
+		workflowClient: workflowClient,
+		enterprise:     enterprise,
+	}
+}
+
+func (m clusterManager) UpgradeCluster(ctx context.Context, c cluster.Cluster, upgrade eks.ClusterUpgrade) (string, error) {
+	taskList := "pipeline"
+	if m.enterprise {
+		taskList = "pipeline-enterprise"
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     taskList,
+		ExecutionStartToCloseTimeout: 30 * 24 * 60 * time.Minute,
+	}
+
+	nodePools := make([]eksworkflow.UpgradeClusterNodePool, 0, len(upgrade.NodePools))
+	for _, nodePoolName := range upgrade.NodePools {
+		nodePools = append(nodePools, eksworkflow.UpgradeClusterNodePool{
+			Name:      nodePoolName,
+			StackName: generateNodePoolStackName(c.Name, nodePoolName),
+		})
+	}
+
+	input := eksworkflow.UpgradeClusterWorkflowInput{
+		ProviderSecretID: c.SecretID.String(),
+		Region:           c.Location,
+
+		OrganizationID:  c.OrganizationID,
+		ClusterID:       c.ID,
+		ClusterSecretID: c.ConfigSecretID.String(),
+		ClusterName:     c.Name,
+
+		CurrentVersion: upgrade.CurrentVersion,
+		TargetVersion:  upgrade.TargetVersion,
+
+		NodePools: nodePools,
+	}
+
+	e, err := m.workflowClient.StartWorkflow(ctx, workflowOptions, eksworkflow.UpgradeClusterWorkflowName, input)
+	if err != nil {
+		return "", errors.WrapWithDetails(err, "failed to start workflow", "workflow", eksworkflow.UpgradeClusterWorkflowName)
+	}
+
+	return e.ID, nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksmodel"
+)
+
+type clusterStore struct {
+	db *gorm.DB
+}
+
+// NewClusterStore returns a new eks.ClusterStore
+// that provides an interface to EKS cluster persistence.
+func NewClusterStore(db *gorm.DB) eks.ClusterStore {
+	return clusterStore{
+		db: db,
+	}
+}
+
+func (s clusterStore) GetClusterVersion(_ context.Context, clusterID uint) (string, error) {
+	var clusterModel eksmodel.EKSClusterModel
+
+	err := s.db.Where(eksmodel.EKSClusterModel{ClusterID: clusterID}).First(&clusterModel).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return "", errors.WithStack(cluster.NotFoundError{ClusterID: clusterID})
+	} else if err != nil {
+		return "", errors.WrapWithDetails(err, "failed to get cluster", "clusterId", clusterID)
+	}
+
+	return clusterModel.Version, nil
+}
+
+func (s clusterStore) SetClusterVersion(_ context.Context, clusterID uint, version string) error {
+	err := s.db.Model(eksmodel.EKSClusterModel{}).
+		Where(eksmodel.EKSClusterModel{ClusterID: clusterID}).
+		Update("version", version).Error
+	if err != nil {
+		return errors.WrapWithDetails(err, "failed to save cluster version", "clusterId", clusterID)
+	}
+
+	return nil
+}
+
+func (s clusterStore) ListNodePoolNames(_ context.Context, clusterID uint) ([]string, error) {
+	var names []string
+
+	err := s.db.Model(eksmodel.AmazonNodePoolsModel{}).
+		Where(eksmodel.AmazonNodePoolsModel{ClusterID: clusterID}).
+		Order("name").
+		Pluck("name", &names).Error
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed to list node pools", "clusterId", clusterID)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"go.uber.org/cadence/activity"
+)
+
+const CompleteLifecycleActionActivityName = "eks-complete-lifecycle-action"
+
+// CompleteLifecycleActionActivity lets a terminating instance go once its node is drained.
+type CompleteLifecycleActionActivity struct {
+	sessionFactory AWSSessionFactory
+}
+
+// CompleteLifecycleActionActivityInput holds the parameters for completing the node drain lifecycle action of an instance.
+type CompleteLifecycleActionActivityInput struct {
+	SecretID string
+	Region   string
+
+	AutoScalingGroupName string
+	InstanceID           string
+}
+
+// NewCompleteLifecycleActionActivity creates a new CompleteLifecycleActionActivity instance.
+func NewCompleteLifecycleActionActivity(sessionFactory AWSSessionFactory) CompleteLifecycleActionActivity {
+	return CompleteLifecycleActionActivity{
+		sessionFactory: sessionFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a CompleteLifecycleActionActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: CompleteLifecycleActionActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a CompleteLifecycleActionActivity) Execute(ctx context.Context, input CompleteLifecycleActionActivityInput) error {
+	sess, err := a.sessionFactory.NewSession(input.SecretID, input.Region)
+	if err = errors.WrapIf(err, "failed to create AWS session"); err != nil {
+		return err
+	}
+
+	_, err = autoscaling.New(sess).CompleteLifecycleActionWithContext(ctx, &autoscaling.CompleteLifecycleActionInput{
+		AutoScalingGroupName:  aws.String(input.AutoScalingGroupName),
+		InstanceId:            aws.String(input.InstanceID),
+		LifecycleHookName:     aws.String(NodeDrainLifecycleHookName),
+		LifecycleActionResult: aws.String("CONTINUE"),
+	})
+
+	// the lifecycle action is already over (eg. it timed out)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == "ValidationError" && strings.Contains(awsErr.Message(), "No active Lifecycle Action found") {
+		return nil
+	}
+
+	return errors.WrapIfWithDetails(
+		err, "failed to complete lifecycle action",
+		"autoScalingGroupName", input.AutoScalingGroupName,
+		"instanceId", input.InstanceID,
+	)
+}
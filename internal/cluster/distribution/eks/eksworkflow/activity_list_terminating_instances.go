@@ -0,0 +1,117 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"go.uber.org/cadence/activity"
+)
+
+// NodeDrainLifecycleHookName is the name of the lifecycle hook holding back terminating node group instances until their nodes are drained.
+// The hook is defined in the node pool CloudFormation template.
+const NodeDrainLifecycleHookName = "pipeline-node-drain"
+
+const ListTerminatingInstancesActivityName = "eks-list-terminating-instances"
+
+// ListTerminatingInstancesActivity lists the instances of a node group waiting for the node drain lifecycle hook.
+type ListTerminatingInstancesActivity struct {
+	sessionFactory AWSSessionFactory
+}
+
+// ListTerminatingInstancesActivityInput holds the parameters for listing terminating instances.
+type ListTerminatingInstancesActivityInput struct {
+	SecretID  string
+	Region    string
+	StackName string
+}
+
+// ListTerminatingInstancesActivityOutput holds the terminating instances of a node group.
+type ListTerminatingInstancesActivityOutput struct {
+	AutoScalingGroupName string
+	Instances            []TerminatingInstance
+}
+
+// TerminatingInstance is an instance waiting for its node to be drained.
+type TerminatingInstance struct {
+	InstanceID string
+
+	// ProviderID is the provider ID of the Kubernetes node running on the instance.
+	ProviderID string
+}
+
+// NewListTerminatingInstancesActivity creates a new ListTerminatingInstancesActivity instance.
+func NewListTerminatingInstancesActivity(sessionFactory AWSSessionFactory) ListTerminatingInstancesActivity {
+	return ListTerminatingInstancesActivity{
+		sessionFactory: sessionFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ListTerminatingInstancesActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ListTerminatingInstancesActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ListTerminatingInstancesActivity) Execute(ctx context.Context, input ListTerminatingInstancesActivityInput) (ListTerminatingInstancesActivityOutput, error) {
+	sess, err := a.sessionFactory.NewSession(input.SecretID, input.Region)
+	if err = errors.WrapIf(err, "failed to create AWS session"); err != nil {
+		return ListTerminatingInstancesActivityOutput{}, err
+	}
+
+	stackResource, err := cloudformation.New(sess).DescribeStackResourceWithContext(ctx, &cloudformation.DescribeStackResourceInput{
+		StackName:         aws.String(input.StackName),
+		LogicalResourceId: aws.String("NodeGroup"),
+	})
+	if err != nil {
+		return ListTerminatingInstancesActivityOutput{}, errors.WrapIfWithDetails(err, "failed to get node group of stack", "stackName", input.StackName)
+	}
+
+	autoScalingGroupName := aws.StringValue(stackResource.StackResourceDetail.PhysicalResourceId)
+
+	groups, err := autoscaling.New(sess).DescribeAutoScalingGroupsWithContext(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(autoScalingGroupName)},
+	})
+	if err != nil {
+		return ListTerminatingInstancesActivityOutput{}, errors.WrapIfWithDetails(err, "failed to describe auto scaling group", "autoScalingGroupName", autoScalingGroupName)
+	}
+
+	output := ListTerminatingInstancesActivityOutput{
+		AutoScalingGroupName: autoScalingGroupName,
+	}
+
+	for _, group := range groups.AutoScalingGroups {
+		for _, instance := range group.Instances {
+			if aws.StringValue(instance.LifecycleState) != autoscaling.LifecycleStateTerminatingWait {
+				continue
+			}
+
+			output.Instances = append(output.Instances, TerminatingInstance{
+				InstanceID: aws.StringValue(instance.InstanceId),
+				ProviderID: fmt.Sprintf("aws:///%s/%s", aws.StringValue(instance.AvailabilityZone), aws.StringValue(instance.InstanceId)),
+			})
+		}
+	}
+
+	sort.Slice(output.Instances, func(i, j int) bool { return output.Instances[i].InstanceID < output.Instances[j].InstanceID })
+
+	return output, nil
+}
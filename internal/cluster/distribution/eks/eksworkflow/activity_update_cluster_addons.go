@@ -0,0 +1,191 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
+	"go.uber.org/cadence/activity"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
+)
+
+const UpdateClusterAddonsActivityName = "eks-update-cluster-addons"
+
+// ClientFactory returns a Kubernetes client.
+type ClientFactory interface {
+	// FromSecret creates a Kubernetes client for a cluster from a secret.
+	FromSecret(ctx context.Context, secretID string) (kubernetes.Interface, error)
+}
+
+// UpdateClusterAddonsActivity updates the default EKS addons (CoreDNS, kube-proxy, aws-node) to match a Kubernetes version.
+type UpdateClusterAddonsActivity struct {
+	clientFactory ClientFactory
+}
+
+// UpdateClusterAddonsActivityInput holds the parameters for the addon update.
+type UpdateClusterAddonsActivityInput struct {
+	Region          string
+	ClusterSecretID string
+	Version         string
+}
+
+// NewUpdateClusterAddonsActivity creates a new UpdateClusterAddonsActivity instance.
+func NewUpdateClusterAddonsActivity(clientFactory ClientFactory) UpdateClusterAddonsActivity {
+	return UpdateClusterAddonsActivity{
+		clientFactory: clientFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a UpdateClusterAddonsActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: UpdateClusterAddonsActivityName})
+}
+
+const addonNamespace = "kube-system"
+
+// Execute is the main body of the activity.
+func (a UpdateClusterAddonsActivity) Execute(ctx context.Context, input UpdateClusterAddonsActivityInput) error {
+	versions, err := eks.GetAddonVersions(input.Version)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.clientFactory.FromSecret(ctx, input.ClusterSecretID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to create Kubernetes client")
+	}
+
+	daemonSets := client.AppsV1().DaemonSets(addonNamespace)
+
+	kubeProxy, err := daemonSets.Get("kube-proxy", metav1.GetOptions{})
+	if err != nil {
+		return errors.WrapIf(err, "failed to get kube-proxy daemonset")
+	}
+
+	if updateContainerImage(&kubeProxy.Spec.Template.Spec, "kube-proxy", eks.GetAddonImage(input.Region, "eks/kube-proxy", versions.KubeProxy)) {
+		if _, err := daemonSets.Update(kubeProxy); err != nil {
+			return errors.WrapIf(err, "failed to update kube-proxy daemonset")
+		}
+	}
+
+	awsNode, err := daemonSets.Get("aws-node", metav1.GetOptions{})
+	if err != nil {
+		return errors.WrapIf(err, "failed to get aws-node daemonset")
+	}
+
+	if updateContainerImage(&awsNode.Spec.Template.Spec, "aws-node", eks.GetAddonImage(input.Region, "amazon-k8s-cni", versions.AWSNode)) {
+		if _, err := daemonSets.Update(awsNode); err != nil {
+			return errors.WrapIf(err, "failed to update aws-node daemonset")
+		}
+	}
+
+	if err := updateCoreDNSConfig(client); err != nil {
+		return err
+	}
+
+	deployments := client.AppsV1().Deployments(addonNamespace)
+
+	coreDNS, err := deployments.Get("coredns", metav1.GetOptions{})
+	if err != nil {
+		return errors.WrapIf(err, "failed to get coredns deployment")
+	}
+
+	if updateContainerImage(&coreDNS.Spec.Template.Spec, "coredns", eks.GetAddonImage(input.Region, "eks/coredns", versions.CoreDNS)) {
+		if _, err := deployments.Update(coreDNS); err != nil {
+			return errors.WrapIf(err, "failed to update coredns deployment")
+		}
+	}
+
+	return nil
+}
+
+// updateCoreDNSConfig replaces the proxy plugin (removed in CoreDNS 1.5) with the forward plugin.
+func updateCoreDNSConfig(client kubernetes.Interface) error {
+	configMaps := client.CoreV1().ConfigMaps(addonNamespace)
+
+	configMap, err := configMaps.Get("coredns", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.WrapIf(err, "failed to get coredns config")
+	}
+
+	corefile := configMap.Data["Corefile"]
+	if !strings.Contains(corefile, "proxy . ") {
+		return nil
+	}
+
+	configMap.Data["Corefile"] = strings.Replace(corefile, "proxy . ", "forward . ", 1)
+
+	if _, err := configMaps.Update(configMap); err != nil {
+		return errors.WrapIf(err, "failed to update coredns config")
+	}
+
+	return nil
+}
+
+// updateContainerImage sets the image of a container if the new image is newer than the current one.
+// Images with unknown tags (eg. customized addons) are left untouched.
+func updateContainerImage(podSpec *corev1.PodSpec, containerName string, image string) bool {
+	for i, container := range podSpec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+
+		if !isNewerImage(container.Image, image) {
+			return false
+		}
+
+		podSpec.Containers[i].Image = image
+
+		return true
+	}
+
+	return false
+}
+
+func isNewerImage(currentImage string, newImage string) bool {
+	currentVersion, err := imageVersion(currentImage)
+	if err != nil {
+		return false
+	}
+
+	newVersion, err := imageVersion(newImage)
+	if err != nil {
+		return false
+	}
+
+	return newVersion.GreaterThan(currentVersion)
+}
+
+func imageVersion(image string) (*semver.Version, error) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 {
+		return nil, errors.NewWithDetails("image has no tag", "image", image)
+	}
+
+	// Tags like v1.14.9-eksbuild.1 are treated as v1.14.9
+	tag := strings.SplitN(image[i+1:], "-", 2)[0]
+
+	return semver.NewVersion(tag)
+}
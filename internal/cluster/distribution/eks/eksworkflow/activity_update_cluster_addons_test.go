@@ -0,0 +1,130 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeClientFactory struct {
+	client kubernetes.Interface
+}
+
+func (f fakeClientFactory) FromSecret(_ context.Context, _ string) (kubernetes.Interface, error) {
+	return f.client, nil
+}
+
+func addonPodSpec(name string, image string) corev1.PodSpec {
+	return corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  name,
+				Image: image,
+			},
+		},
+	}
+}
+
+func TestUpdateClusterAddonsActivity(t *testing.T) {
+	const registry = "602401143452.dkr.ecr.us-east-1.amazonaws.com/"
+
+	objects := []runtime.Object{
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: addonNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{Spec: addonPodSpec("kube-proxy", registry+"eks/kube-proxy:v1.14.9")},
+			},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-node", Namespace: addonNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{Spec: addonPodSpec("aws-node", registry+"amazon-k8s-cni:v1.6.3")},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: addonNamespace},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{Spec: addonPodSpec("coredns", registry+"eks/coredns:v1.2.6")},
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: addonNamespace},
+			Data: map[string]string{
+				"Corefile": ".:53 {\n    proxy . /etc/resolv.conf\n}\n",
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+
+	activity := NewUpdateClusterAddonsActivity(fakeClientFactory{client: client})
+
+	err := activity.Execute(context.Background(), UpdateClusterAddonsActivityInput{
+		Region:          "us-east-1",
+		ClusterSecretID: "secret",
+		Version:         "1.15",
+	})
+	require.NoError(t, err)
+
+	kubeProxy, err := client.AppsV1().DaemonSets(addonNamespace).Get("kube-proxy", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, registry+"eks/kube-proxy:v1.15.11", kubeProxy.Spec.Template.Spec.Containers[0].Image)
+
+	// Newer versions are left untouched
+	awsNode, err := client.AppsV1().DaemonSets(addonNamespace).Get("aws-node", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, registry+"amazon-k8s-cni:v1.6.3", awsNode.Spec.Template.Spec.Containers[0].Image)
+
+	coreDNS, err := client.AppsV1().Deployments(addonNamespace).Get("coredns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, registry+"eks/coredns:v1.6.6", coreDNS.Spec.Template.Spec.Containers[0].Image)
+
+	configMap, err := client.CoreV1().ConfigMaps(addonNamespace).Get("coredns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ".:53 {\n    forward . /etc/resolv.conf\n}\n", configMap.Data["Corefile"])
+}
+
+func TestIsNewerImage(t *testing.T) {
+	tests := []struct {
+		current  string
+		new      string
+		expected bool
+	}{
+		{"eks/kube-proxy:v1.14.9", "eks/kube-proxy:v1.15.11", true},
+		{"eks/kube-proxy:v1.15.11", "eks/kube-proxy:v1.15.11", false},
+		{"eks/kube-proxy:v1.14.9-eksbuild.1", "eks/kube-proxy:v1.14.9", false},
+		{"eks/kube-proxy:v1.16.8", "eks/kube-proxy:v1.15.11", false},
+		{"eks/kube-proxy:latest", "eks/kube-proxy:v1.15.11", false},
+		{"eks/kube-proxy", "eks/kube-proxy:v1.15.11", false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.current, func(t *testing.T) {
+			assert.Equal(t, test.expected, isNewerImage(test.current, test.new))
+		})
+	}
+}
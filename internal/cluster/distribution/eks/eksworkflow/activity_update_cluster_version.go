@@ -0,0 +1,95 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/cadence/activity"
+)
+
+const UpdateClusterVersionActivityName = "eks-update-cluster-version"
+
+// UpdateClusterVersionActivity starts the Kubernetes version update of an EKS control plane.
+type UpdateClusterVersionActivity struct {
+	sessionFactory AWSSessionFactory
+}
+
+// UpdateClusterVersionActivityInput holds the parameters for the control plane version update.
+type UpdateClusterVersionActivityInput struct {
+	SecretID string
+	Region   string
+
+	ClusterName string
+	Version     string
+}
+
+type UpdateClusterVersionActivityOutput struct {
+	// UpdateID is the ID of the started EKS update (empty if the control plane already runs the requested version).
+	UpdateID string
+}
+
+// NewUpdateClusterVersionActivity creates a new UpdateClusterVersionActivity instance.
+func NewUpdateClusterVersionActivity(sessionFactory AWSSessionFactory) UpdateClusterVersionActivity {
+	return UpdateClusterVersionActivity{
+		sessionFactory: sessionFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a UpdateClusterVersionActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: UpdateClusterVersionActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a UpdateClusterVersionActivity) Execute(
+	ctx context.Context,
+	input UpdateClusterVersionActivityInput,
+) (UpdateClusterVersionActivityOutput, error) {
+	sess, err := a.sessionFactory.NewSession(input.SecretID, input.Region)
+	if err = errors.WrapIf(err, "failed to create AWS session"); err != nil { // internal error?
+		return UpdateClusterVersionActivityOutput{}, err
+	}
+
+	eksClient := eks.New(sess)
+
+	clusterOutput, err := eksClient.DescribeClusterWithContext(ctx, &eks.DescribeClusterInput{
+		Name: aws.String(input.ClusterName),
+	})
+	if err != nil {
+		return UpdateClusterVersionActivityOutput{}, errors.WrapIf(err, "failed to describe EKS cluster")
+	}
+
+	if aws.StringValue(clusterOutput.Cluster.Version) == input.Version {
+		return UpdateClusterVersionActivityOutput{}, nil
+	}
+
+	// The request token makes retries of the same update idempotent
+	updateOutput, err := eksClient.UpdateClusterVersionWithContext(ctx, &eks.UpdateClusterVersionInput{
+		ClientRequestToken: aws.String(activity.GetInfo(ctx).WorkflowExecution.ID + "-" + input.Version),
+		Name:               aws.String(input.ClusterName),
+		Version:            aws.String(input.Version),
+	})
+	if err != nil {
+		return UpdateClusterVersionActivityOutput{}, errors.WrapIfWithDetails(err, "failed to update EKS cluster version", "version", input.Version)
+	}
+
+	return UpdateClusterVersionActivityOutput{
+		UpdateID: aws.StringValue(updateOutput.Update.Id),
+	}, nil
+}
@@ -0,0 +1,152 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+)
+
+const UpgradeClusterPreflightActivityName = "eks-upgrade-cluster-preflight"
+
+// ErrReasonUpgradePreflightFailed cadence custom error reason that denotes a failed pre-flight check
+const ErrReasonUpgradePreflightFailed = "EKS_UPGRADE_PREFLIGHT_FAILED"
+
+// UpgradeClusterPreflightActivity checks whether a cluster is ready to be upgraded.
+type UpgradeClusterPreflightActivity struct {
+	sessionFactory AWSSessionFactory
+}
+
+// UpgradeClusterPreflightActivityInput holds the parameters for the pre-flight check.
+type UpgradeClusterPreflightActivityInput struct {
+	SecretID string
+	Region   string
+
+	ClusterName string
+
+	NodePoolStackNames []string
+}
+
+type UpgradeClusterPreflightActivityOutput struct {
+	// ControlPlaneVersion is the Kubernetes version the control plane is actually running.
+	ControlPlaneVersion string
+}
+
+// NewUpgradeClusterPreflightActivity creates a new UpgradeClusterPreflightActivity instance.
+func NewUpgradeClusterPreflightActivity(sessionFactory AWSSessionFactory) UpgradeClusterPreflightActivity {
+	return UpgradeClusterPreflightActivity{
+		sessionFactory: sessionFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a UpgradeClusterPreflightActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: UpgradeClusterPreflightActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a UpgradeClusterPreflightActivity) Execute(
+	ctx context.Context,
+	input UpgradeClusterPreflightActivityInput,
+) (UpgradeClusterPreflightActivityOutput, error) {
+	sess, err := a.sessionFactory.NewSession(input.SecretID, input.Region)
+	if err = errors.WrapIf(err, "failed to create AWS session"); err != nil { // internal error?
+		return UpgradeClusterPreflightActivityOutput{}, err
+	}
+
+	eksClient := eks.New(sess)
+
+	clusterOutput, err := eksClient.DescribeClusterWithContext(ctx, &eks.DescribeClusterInput{
+		Name: aws.String(input.ClusterName),
+	})
+	if err != nil {
+		return UpgradeClusterPreflightActivityOutput{}, errors.WrapIf(err, "failed to describe EKS cluster")
+	}
+
+	var violations []string
+
+	if status := aws.StringValue(clusterOutput.Cluster.Status); status != eks.ClusterStatusActive {
+		violations = append(violations, fmt.Sprintf("control plane is not active (status: %s)", status))
+	}
+
+	updatesOutput, err := eksClient.ListUpdatesWithContext(ctx, &eks.ListUpdatesInput{
+		Name: aws.String(input.ClusterName),
+	})
+	if err != nil {
+		return UpgradeClusterPreflightActivityOutput{}, errors.WrapIf(err, "failed to list EKS cluster updates")
+	}
+
+	for _, updateID := range updatesOutput.UpdateIds {
+		updateOutput, err := eksClient.DescribeUpdateWithContext(ctx, &eks.DescribeUpdateInput{
+			Name:     aws.String(input.ClusterName),
+			UpdateId: updateID,
+		})
+		if err != nil {
+			return UpgradeClusterPreflightActivityOutput{}, errors.WrapIf(err, "failed to describe EKS cluster update")
+		}
+
+		if aws.StringValue(updateOutput.Update.Status) == eks.UpdateStatusInProgress {
+			violations = append(violations, fmt.Sprintf("another update (%s) is in progress", aws.StringValue(updateID)))
+		}
+	}
+
+	cloudformationClient := cloudformation.New(sess)
+
+	for _, stackName := range input.NodePoolStackNames {
+		stacksOutput, err := cloudformationClient.DescribeStacksWithContext(ctx, &cloudformation.DescribeStacksInput{
+			StackName: aws.String(stackName),
+		})
+		if err != nil {
+			return UpgradeClusterPreflightActivityOutput{}, errors.WrapIfWithDetails(err, "failed to describe node pool stack", "stackName", stackName)
+		}
+
+		for _, stack := range stacksOutput.Stacks {
+			if status := aws.StringValue(stack.StackStatus); !isStackUpdatable(status) {
+				violations = append(violations, fmt.Sprintf("node pool stack %s is not ready for update (status: %s)", stackName, status))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return UpgradeClusterPreflightActivityOutput{}, cadence.NewCustomError(
+			ErrReasonUpgradePreflightFailed,
+			"cluster is not ready for upgrade: "+strings.Join(violations, "; "),
+		)
+	}
+
+	return UpgradeClusterPreflightActivityOutput{
+		ControlPlaneVersion: aws.StringValue(clusterOutput.Cluster.Version),
+	}, nil
+}
+
+// isStackUpdatable checks whether a CloudFormation stack is in a stable state that allows updates.
+func isStackUpdatable(status string) bool {
+	switch status {
+	case cloudformation.StackStatusCreateComplete,
+		cloudformation.StackStatusUpdateComplete,
+		cloudformation.StackStatusUpdateRollbackComplete:
+		return true
+	}
+
+	return false
+}
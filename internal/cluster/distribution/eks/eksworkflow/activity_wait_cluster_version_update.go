@@ -0,0 +1,112 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+)
+
+const WaitClusterVersionUpdateActivityName = "eks-wait-cluster-version-update"
+
+// ErrReasonClusterUpdateFailed cadence custom error reason that denotes a failed EKS cluster update
+const ErrReasonClusterUpdateFailed = "EKS_CLUSTER_UPDATE_FAILED"
+
+// WaitClusterVersionUpdateActivity waits for an EKS control plane version update to complete.
+type WaitClusterVersionUpdateActivity struct {
+	sessionFactory AWSSessionFactory
+
+	pollInterval time.Duration
+}
+
+// WaitClusterVersionUpdateActivityInput holds the parameters of the update to wait for.
+type WaitClusterVersionUpdateActivityInput struct {
+	SecretID string
+	Region   string
+
+	ClusterName string
+	UpdateID    string
+}
+
+// NewWaitClusterVersionUpdateActivity creates a new WaitClusterVersionUpdateActivity instance.
+func NewWaitClusterVersionUpdateActivity(sessionFactory AWSSessionFactory) WaitClusterVersionUpdateActivity {
+	return WaitClusterVersionUpdateActivity{
+		sessionFactory: sessionFactory,
+
+		pollInterval: 30 * time.Second,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a WaitClusterVersionUpdateActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: WaitClusterVersionUpdateActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a WaitClusterVersionUpdateActivity) Execute(ctx context.Context, input WaitClusterVersionUpdateActivityInput) error {
+	sess, err := a.sessionFactory.NewSession(input.SecretID, input.Region)
+	if err = errors.WrapIf(err, "failed to create AWS session"); err != nil { // internal error?
+		return err
+	}
+
+	eksClient := eks.New(sess)
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		updateOutput, err := eksClient.DescribeUpdateWithContext(ctx, &eks.DescribeUpdateInput{
+			Name:     aws.String(input.ClusterName),
+			UpdateId: aws.String(input.UpdateID),
+		})
+		if err != nil {
+			return errors.WrapIf(err, "failed to describe EKS cluster update")
+		}
+
+		update := updateOutput.Update
+
+		switch aws.StringValue(update.Status) {
+		case eks.UpdateStatusSuccessful:
+			return nil
+
+		case eks.UpdateStatusFailed, eks.UpdateStatusCancelled:
+			var messages []string
+			for _, updateErr := range update.Errors {
+				messages = append(messages, fmt.Sprintf("%s: %s", aws.StringValue(updateErr.ErrorCode), aws.StringValue(updateErr.ErrorMessage)))
+			}
+
+			return cadence.NewCustomError(
+				ErrReasonClusterUpdateFailed,
+				fmt.Sprintf("EKS cluster update %s: %s", strings.ToLower(aws.StringValue(update.Status)), strings.Join(messages, "; ")),
+			)
+		}
+
+		activity.RecordHeartbeat(ctx, aws.StringValue(update.Status))
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"go.uber.org/cadence/workflow"
 
 	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
 	"github.com/banzaicloud/pipeline/pkg/sdk/brn"
 	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
 )
@@ -87,6 +88,16 @@ func (w UpdateNodePoolWorkflow) Execute(ctx workflow.Context, input UpdateNodePo
 		_ = setClusterStatus(ctx, input.ClusterID, status, statusMessage)
 	}()
 
+	return updateNodePool(ctx, activityOptions, process, input)
+}
+
+// updateNodePool rolls a node pool to a new node image.
+func updateNodePool(
+	ctx workflow.Context,
+	activityOptions workflow.ActivityOptions,
+	process processlog.Process,
+	input UpdateNodePoolWorkflowInput,
+) error {
 	var nodePoolVersion string
 	{
 		activityInput := CalculateNodePoolVersionActivityInput{
@@ -99,17 +110,18 @@ func (w UpdateNodePoolWorkflow) Execute(ctx workflow.Context, input UpdateNodePo
 			InitialInterval:    10 * time.Second,
 			BackoffCoefficient: 1.01,
 			MaximumInterval:    10 * time.Minute,
+			MaximumAttempts:    5,
 		}
 
 		var output CalculateNodePoolVersionActivityOutput
 
-		err = workflow.ExecuteActivity(
+		err := workflow.ExecuteActivity(
 			workflow.WithActivityOptions(ctx, activityOptions),
 			CalculateNodePoolVersionActivityName,
 			activityInput,
 		).Get(ctx, &output)
 		if err != nil {
-			return
+			return err
 		}
 
 		nodePoolVersion = output.Version
@@ -138,14 +150,15 @@ func (w UpdateNodePoolWorkflow) Execute(ctx workflow.Context, input UpdateNodePo
 		var output UpdateNodeGroupActivityOutput
 
 		processActivity := process.StartActivity(ctx, UpdateNodeGroupActivityName)
-		err = workflow.ExecuteActivity(
+		err := workflow.ExecuteActivity(
 			workflow.WithActivityOptions(ctx, activityOptions),
 			UpdateNodeGroupActivityName,
 			activityInput,
 		).Get(ctx, &output)
 		processActivity.Finish(ctx, err)
 		if err != nil || !output.NodePoolChanged {
-			return
+			// nothing is replaced, so the nodes are left untouched
+			return err
 		}
 	}
 
@@ -167,11 +180,16 @@ func (w UpdateNodePoolWorkflow) Execute(ctx workflow.Context, input UpdateNodePo
 		}
 
 		processActivity := process.StartActivity(ctx, WaitCloudFormationStackUpdateActivityName)
-		err = workflow.ExecuteActivity(
+		stackUpdate := workflow.ExecuteActivity(
 			workflow.WithActivityOptions(ctx, activityOptions),
 			WaitCloudFormationStackUpdateActivityName,
 			activityInput,
-		).Get(ctx, nil)
+		)
+
+		// the outdated nodes are drained as the auto scaling group terminates their instances
+		drainTerminatingNodes(ctx, process, input, stackUpdate)
+
+		err := stackUpdate.Get(ctx, nil)
 		processActivity.Finish(ctx, err)
 		if err != nil {
 			return err
@@ -180,3 +198,95 @@ func (w UpdateNodePoolWorkflow) Execute(ctx workflow.Context, input UpdateNodePo
 
 	return nil
 }
+
+const terminatingInstancesPollInterval = 30 * time.Second
+
+// drainTerminatingNodes drains the nodes of instances held back by the node drain lifecycle hook,
+// until the replacement of the node group (a rolling update of the auto scaling group) is finished.
+// Drain failures are not fatal: the instance is terminated without draining its node.
+// Instances that are not released by Pipeline are terminated once the lifecycle hook times out.
+func drainTerminatingNodes(ctx workflow.Context, process processlog.Process, input UpdateNodePoolWorkflowInput, replacement workflow.Future) {
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    2 * time.Minute,
+		WaitForCancellation:    true,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    5,
+		},
+	}
+	actx := workflow.WithActivityOptions(ctx, activityOptions)
+
+	drainedInstances := make(map[string]bool)
+
+	for !replacement.IsReady() {
+		activityInput := ListTerminatingInstancesActivityInput{
+			SecretID:  input.ProviderSecretID,
+			Region:    input.Region,
+			StackName: input.StackName,
+		}
+
+		var output ListTerminatingInstancesActivityOutput
+
+		err := workflow.ExecuteActivity(actx, ListTerminatingInstancesActivityName, activityInput).Get(ctx, &output)
+		if err != nil {
+			logger.Warnf("failed to list terminating instances: %s", err)
+		}
+
+		for _, instance := range output.Instances {
+			if drainedInstances[instance.InstanceID] {
+				continue
+			}
+			drainedInstances[instance.InstanceID] = true
+
+			drainTerminatingNode(actx, process, input, instance)
+
+			activityInput := CompleteLifecycleActionActivityInput{
+				SecretID:             input.ProviderSecretID,
+				Region:               input.Region,
+				AutoScalingGroupName: output.AutoScalingGroupName,
+				InstanceID:           instance.InstanceID,
+			}
+
+			err := workflow.ExecuteActivity(actx, CompleteLifecycleActionActivityName, activityInput).Get(ctx, nil)
+			if err != nil {
+				logger.Warnf("failed to release instance %s: %s", instance.InstanceID, err)
+			}
+		}
+
+		workflow.NewSelector(ctx).
+			AddFuture(replacement, func(workflow.Future) {}).
+			AddFuture(workflow.NewTimer(ctx, terminatingInstancesPollInterval), func(workflow.Future) {}).
+			Select(ctx)
+	}
+}
+
+// drainTerminatingNode drains the node running on a terminating instance (if it is still part of the cluster).
+func drainTerminatingNode(ctx workflow.Context, process processlog.Process, input UpdateNodePoolWorkflowInput, instance TerminatingInstance) {
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	activityInput := clusterworkflow.GetNodeByProviderIDActivityInput{
+		ClusterID:  input.ClusterID,
+		ProviderID: instance.ProviderID,
+	}
+
+	var output clusterworkflow.GetNodeByProviderIDActivityOutput
+
+	err := workflow.ExecuteActivity(ctx, clusterworkflow.GetNodeByProviderIDActivityName, activityInput).Get(ctx, &output)
+	if err != nil {
+		logger.Warnf("failed to find node of instance %s: %s", instance.InstanceID, err)
+
+		return
+	}
+
+	if output.NodeName == "" {
+		return
+	}
+
+	if err := clusterworkflow.DrainNode(ctx, process, input.ClusterID, output.NodeName); err != nil {
+		logger.Warnf("failed to drain node %s: %s", output.NodeName, err)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+// nolint: gochecknoinits
+func init() {
+	for name, fn := range map[string]interface{}{
+		UpdateNodeGroupActivityName:                     NewUpdateNodeGroupActivity(nil, "").Execute,
+		WaitCloudFormationStackUpdateActivityName:       NewWaitCloudFormationStackUpdateActivity(nil).Execute,
+		ListTerminatingInstancesActivityName:            NewListTerminatingInstancesActivity(nil).Execute,
+		CompleteLifecycleActionActivityName:             NewCompleteLifecycleActionActivity(nil).Execute,
+		clusterworkflow.SetClusterStatusActivityName:    clusterworkflow.NewSetClusterStatusActivity(nil).Execute,
+		clusterworkflow.GetNodeByProviderIDActivityName: clusterworkflow.NewGetNodeByProviderIDActivity(nil).Execute,
+		clusterworkflow.DrainNodeActivityName:           clusterworkflow.NewDrainNodeActivity(nil, kubernetes.NodeDrainOptions{}).Execute,
+	} {
+		activity.RegisterWithOptions(fn, activity.RegisterOptions{Name: name})
+	}
+}
+
+type noopProcessLogger struct{}
+
+func (noopProcessLogger) StartProcess(_ workflow.Context, _ string) processlog.Process {
+	return noopProcess{}
+}
+
+type noopProcess struct{}
+
+func (noopProcess) Finish(_ workflow.Context, _ error) {}
+
+func (noopProcess) StartActivity(_ workflow.Context, _ string) processlog.Activity {
+	return noopProcess{}
+}
+
+type UpdateNodePoolWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestUpdateNodePoolWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(UpdateNodePoolWorkflowTestSuite))
+}
+
+func (s *UpdateNodePoolWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewUpdateNodePoolWorkflow(noopProcessLogger{}).Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+
+	s.env.OnActivity(CalculateNodePoolVersionActivityName, mock.Anything, mock.Anything).
+		Return(CalculateNodePoolVersionActivityOutput{Version: "v2"}, nil)
+	s.env.OnActivity(clusterworkflow.SetClusterStatusActivityName, mock.Anything, mock.Anything).Return(nil)
+}
+
+func (s *UpdateNodePoolWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+var updateNodePoolWorkflowInput = UpdateNodePoolWorkflowInput{ // nolint: gochecknoglobals
+	OrganizationID: 1,
+	ClusterID:      2,
+	ClusterName:    "cluster",
+	StackName:      "stack",
+	NodePoolName:   "pool",
+	NodeImage:      "ami-xxxxxxxxxxxxx",
+}
+
+func (s *UpdateNodePoolWorkflowTestSuite) Test_Unchanged() {
+	s.env.OnActivity(UpdateNodeGroupActivityName, mock.Anything, mock.Anything).
+		Return(UpdateNodeGroupActivityOutput{NodePoolChanged: false}, nil)
+
+	s.env.ExecuteWorkflow(s.T().Name(), updateNodePoolWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.env.AssertNotCalled(s.T(), ListTerminatingInstancesActivityName, mock.Anything, mock.Anything)
+	s.env.AssertNotCalled(s.T(), clusterworkflow.DrainNodeActivityName, mock.Anything, mock.Anything)
+}
+
+func (s *UpdateNodePoolWorkflowTestSuite) Test_DrainTerminatingNodes() {
+	s.env.OnActivity(UpdateNodeGroupActivityName, mock.Anything, mock.Anything).
+		Return(UpdateNodeGroupActivityOutput{NodePoolChanged: true}, nil)
+
+	s.env.OnActivity(WaitCloudFormationStackUpdateActivityName, mock.Anything, mock.Anything).
+		After(5 * time.Minute).
+		Return(nil)
+
+	instance0 := TerminatingInstance{InstanceID: "i-0", ProviderID: "aws:///eu-west-1a/i-0"}
+	instance1 := TerminatingInstance{InstanceID: "i-1", ProviderID: "aws:///eu-west-1b/i-1"}
+
+	listInput := ListTerminatingInstancesActivityInput{StackName: "stack"}
+	s.env.OnActivity(ListTerminatingInstancesActivityName, mock.Anything, listInput).
+		Return(ListTerminatingInstancesActivityOutput{AutoScalingGroupName: "asg"}, nil).Once()
+	s.env.OnActivity(ListTerminatingInstancesActivityName, mock.Anything, listInput).
+		Return(ListTerminatingInstancesActivityOutput{AutoScalingGroupName: "asg", Instances: []TerminatingInstance{instance0}}, nil).Twice()
+	s.env.OnActivity(ListTerminatingInstancesActivityName, mock.Anything, listInput).
+		Return(ListTerminatingInstancesActivityOutput{AutoScalingGroupName: "asg", Instances: []TerminatingInstance{instance1}}, nil)
+
+	s.env.OnActivity(
+		clusterworkflow.GetNodeByProviderIDActivityName,
+		mock.Anything,
+		clusterworkflow.GetNodeByProviderIDActivityInput{ClusterID: 2, ProviderID: "aws:///eu-west-1a/i-0"},
+	).Return(clusterworkflow.GetNodeByProviderIDActivityOutput{NodeName: "node-0"}, nil).Once()
+	s.env.OnActivity(
+		clusterworkflow.GetNodeByProviderIDActivityName,
+		mock.Anything,
+		clusterworkflow.GetNodeByProviderIDActivityInput{ClusterID: 2, ProviderID: "aws:///eu-west-1b/i-1"},
+	).Return(clusterworkflow.GetNodeByProviderIDActivityOutput{NodeName: "node-1"}, nil).Once()
+
+	s.env.OnActivity(
+		clusterworkflow.DrainNodeActivityName,
+		mock.Anything,
+		clusterworkflow.DrainNodeActivityInput{ClusterID: 2, NodeName: "node-0"},
+	).Return(nil).Once()
+
+	// a node that cannot be drained does not hold back the termination of its instance
+	s.env.OnActivity(
+		clusterworkflow.DrainNodeActivityName,
+		mock.Anything,
+		clusterworkflow.DrainNodeActivityInput{ClusterID: 2, NodeName: "node-1"},
+	).Return(cadence.NewCustomError(clusterworkflow.ErrReasonNodeDrainTimeout)).Once()
+
+	s.env.OnActivity(
+		CompleteLifecycleActionActivityName,
+		mock.Anything,
+		CompleteLifecycleActionActivityInput{AutoScalingGroupName: "asg", InstanceID: "i-0"},
+	).Return(nil).Once()
+	s.env.OnActivity(
+		CompleteLifecycleActionActivityName,
+		mock.Anything,
+		CompleteLifecycleActionActivityInput{AutoScalingGroupName: "asg", InstanceID: "i-1"},
+	).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(s.T().Name(), updateNodePoolWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
@@ -0,0 +1,307 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eksworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/eks"
+	"github.com/banzaicloud/pipeline/pkg/sdk/brn"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+const UpgradeClusterWorkflowName = "eks-upgrade-cluster"
+
+// UpgradeClusterWorkflow upgrades the Kubernetes version of an EKS cluster:
+// the control plane is upgraded one minor version at a time (along with the default addons),
+// then every node pool is rolled to the EKS optimized AMI matching the new version.
+type UpgradeClusterWorkflow struct {
+	processLogger processlog.ProcessLogger
+}
+
+// NewUpgradeClusterWorkflow returns a new UpgradeClusterWorkflow.
+func NewUpgradeClusterWorkflow(processLogger processlog.ProcessLogger) UpgradeClusterWorkflow {
+	return UpgradeClusterWorkflow{
+		processLogger: processLogger,
+	}
+}
+
+// UpgradeClusterNodePool describes a node pool rolled to the new version.
+type UpgradeClusterNodePool struct {
+	Name      string
+	StackName string
+}
+
+type UpgradeClusterWorkflowInput struct {
+	ProviderSecretID string
+	Region           string
+
+	OrganizationID  uint
+	ClusterID       uint
+	ClusterSecretID string
+	ClusterName     string
+
+	CurrentVersion string
+	TargetVersion  string
+
+	NodePools []UpgradeClusterNodePool
+}
+
+func (w UpgradeClusterWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: UpgradeClusterWorkflowName})
+}
+
+func (w UpgradeClusterWorkflow) Execute(ctx workflow.Context, input UpgradeClusterWorkflowInput) (err error) {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Duration(workflow.GetInfo(ctx).ExecutionStartToCloseTimeoutSeconds) * time.Second,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	clusterID := brn.New(input.OrganizationID, brn.ClusterResourceType, fmt.Sprint(input.ClusterID))
+
+	process := w.processLogger.StartProcess(ctx, clusterID.String())
+	defer func() {
+		process.Finish(ctx, err)
+	}()
+	defer func() {
+		status := cluster.Running
+		statusMessage := cluster.RunningMessage
+
+		if err != nil {
+			if cadence.IsCanceledError(err) {
+				ctx, _ = workflow.NewDisconnectedContext(ctx)
+			}
+
+			status = cluster.Warning
+			statusMessage = fmt.Sprintf("failed to upgrade cluster: %s", err.Error())
+		}
+
+		_ = setClusterStatus(ctx, input.ClusterID, status, statusMessage)
+	}()
+
+	var controlPlaneVersion string
+	{
+		activityInput := UpgradeClusterPreflightActivityInput{
+			SecretID:    input.ProviderSecretID,
+			Region:      input.Region,
+			ClusterName: input.ClusterName,
+		}
+
+		for _, nodePool := range input.NodePools {
+			activityInput.NodePoolStackNames = append(activityInput.NodePoolStackNames, nodePool.StackName)
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 2 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:          20 * time.Second,
+			BackoffCoefficient:       1.1,
+			MaximumAttempts:          5,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic", ErrReasonUpgradePreflightFailed},
+		}
+
+		var output UpgradeClusterPreflightActivityOutput
+
+		processActivity := process.StartActivity(ctx, UpgradeClusterPreflightActivityName)
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			UpgradeClusterPreflightActivityName,
+			activityInput,
+		).Get(ctx, &output)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+
+		controlPlaneVersion = output.ControlPlaneVersion
+	}
+
+	// The control plane might already be partially upgraded (eg. by a previous, failed upgrade)
+	versions, err := eks.UpgradePath(controlPlaneVersion, input.TargetVersion)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		versions = []string{input.TargetVersion}
+	}
+
+	for _, version := range versions {
+		err = w.upgradeControlPlane(ctx, activityOptions, process, input, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	nodeImage, err := eks.GetDefaultImageID(input.Region, input.TargetVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, nodePool := range input.NodePools {
+		err = updateNodePool(ctx, activityOptions, process, UpdateNodePoolWorkflowInput{
+			ProviderSecretID: input.ProviderSecretID,
+			Region:           input.Region,
+
+			StackName: nodePool.StackName,
+
+			OrganizationID:  input.OrganizationID,
+			ClusterID:       input.ClusterID,
+			ClusterSecretID: input.ClusterSecretID,
+			ClusterName:     input.ClusterName,
+			NodePoolName:    nodePool.Name,
+
+			NodeImage: nodeImage,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeControlPlane upgrades the control plane and the default addons to a Kubernetes version.
+func (w UpgradeClusterWorkflow) upgradeControlPlane(
+	ctx workflow.Context,
+	activityOptions workflow.ActivityOptions,
+	process processlog.Process,
+	input UpgradeClusterWorkflowInput,
+	version string,
+) error {
+	var updateID string
+	{
+		activityInput := UpdateClusterVersionActivityInput{
+			SecretID:    input.ProviderSecretID,
+			Region:      input.Region,
+			ClusterName: input.ClusterName,
+			Version:     version,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 2 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:          20 * time.Second,
+			BackoffCoefficient:       1.1,
+			MaximumAttempts:          10,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic"},
+		}
+
+		var output UpdateClusterVersionActivityOutput
+
+		processActivity := process.StartActivity(ctx, UpdateClusterVersionActivityName)
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			UpdateClusterVersionActivityName,
+			activityInput,
+		).Get(ctx, &output)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+
+		updateID = output.UpdateID
+	}
+
+	if updateID != "" {
+		activityInput := WaitClusterVersionUpdateActivityInput{
+			SecretID:    input.ProviderSecretID,
+			Region:      input.Region,
+			ClusterName: input.ClusterName,
+			UpdateID:    updateID,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 2 * time.Hour
+		activityOptions.HeartbeatTimeout = 2 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:          20 * time.Second,
+			BackoffCoefficient:       1.1,
+			MaximumAttempts:          10,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic", ErrReasonClusterUpdateFailed},
+		}
+
+		processActivity := process.StartActivity(ctx, WaitClusterVersionUpdateActivityName)
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			WaitClusterVersionUpdateActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	{
+		activityInput := UpdateClusterAddonsActivityInput{
+			Region:          input.Region,
+			ClusterSecretID: input.ClusterSecretID,
+			Version:         version,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 5 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:          20 * time.Second,
+			BackoffCoefficient:       1.1,
+			MaximumAttempts:          10,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic"},
+		}
+
+		processActivity := process.StartActivity(ctx, UpdateClusterAddonsActivityName)
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			UpdateClusterAddonsActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	{
+		activityInput := SaveClusterVersionActivityInput{
+			ClusterID: input.ClusterID,
+			Version:   version,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 30 * time.Second
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 1.01,
+			MaximumInterval:    10 * time.Minute,
+		}
+
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			SaveClusterVersionActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
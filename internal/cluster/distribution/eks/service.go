@@ -26,6 +26,9 @@ type Service interface {
 	//
 	// This method accepts a partial body representation.
 	UpdateNodePool(ctx context.Context, clusterID uint, nodePoolName string, nodePoolUpdate NodePoolUpdate) (string, error)
+
+	// UpgradeCluster upgrades the Kubernetes version of a cluster.
+	UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error)
 }
 
 // NodePoolUpdate describes a node pool update request.
@@ -41,11 +44,15 @@ func NewService(
 	genericClusters cluster.Store,
 	nodePools NodePoolStore,
 	nodePoolManager NodePoolManager,
+	clusters ClusterStore,
+	clusterManager ClusterManager,
 ) Service {
 	return service{
 		genericClusters: genericClusters,
 		nodePools:       nodePools,
 		nodePoolManager: nodePoolManager,
+		clusters:        clusters,
+		clusterManager:  clusterManager,
 	}
 }
 
@@ -53,6 +60,26 @@ type service struct {
 	genericClusters cluster.Store
 	nodePools       NodePoolStore
 	nodePoolManager NodePoolManager
+	clusters        ClusterStore
+	clusterManager  ClusterManager
+}
+
+// ClusterStore provides an interface for EKS cluster persistence.
+type ClusterStore interface {
+	// GetClusterVersion returns the Kubernetes version of a cluster.
+	GetClusterVersion(ctx context.Context, clusterID uint) (string, error)
+
+	// SetClusterVersion saves the Kubernetes version of a cluster.
+	SetClusterVersion(ctx context.Context, clusterID uint, version string) error
+
+	// ListNodePoolNames returns the names of the node pools of a cluster.
+	ListNodePoolNames(ctx context.Context, clusterID uint) ([]string, error)
+}
+
+// ClusterManager is responsible for managing clusters.
+type ClusterManager interface {
+	// UpgradeCluster upgrades the Kubernetes version of a cluster.
+	UpgradeCluster(ctx context.Context, c cluster.Cluster, upgrade ClusterUpgrade) (string, error)
 }
 
 // NodePoolManager is responsible for managing node pools.
@@ -81,3 +108,35 @@ func (s service) UpdateNodePool(
 
 	return s.nodePoolManager.UpdateNodePool(ctx, c, nodePoolName, nodePoolUpdate)
 }
+
+func (s service) UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error) {
+	c, err := s.genericClusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	currentVersion, err := s.clusters.GetClusterVersion(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ValidateVersionUpgrade(currentVersion, version); err != nil {
+		return "", err
+	}
+
+	nodePools, err := s.clusters.ListNodePoolNames(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.genericClusters.SetStatus(ctx, clusterID, cluster.Updating, "upgrading cluster")
+	if err != nil {
+		return "", err
+	}
+
+	return s.clusterManager.UpgradeCluster(ctx, c, ClusterUpgrade{
+		CurrentVersion: currentVersion,
+		TargetVersion:  version,
+		NodePools:      nodePools,
+	})
+}
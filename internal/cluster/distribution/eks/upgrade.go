@@ -0,0 +1,153 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eks
+
+import (
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+// AddonVersions describes the versions of the default EKS addons matching a Kubernetes version.
+type AddonVersions struct {
+	CoreDNS   string
+	KubeProxy string
+	AWSNode   string
+}
+
+// Addon versions taken from https://docs.aws.amazon.com/eks/latest/userguide/update-cluster.html
+// nolint: gochecknoglobals
+var addonVersionMap = map[string]AddonVersions{
+	"1.13": {CoreDNS: "v1.2.6", KubeProxy: "v1.13.12", AWSNode: "v1.6.1"},
+	"1.14": {CoreDNS: "v1.6.6", KubeProxy: "v1.14.9", AWSNode: "v1.6.1"},
+	"1.15": {CoreDNS: "v1.6.6", KubeProxy: "v1.15.11", AWSNode: "v1.6.1"},
+}
+
+// GetAddonVersions returns the addon versions matching a Kubernetes version.
+func GetAddonVersions(kubernetesVersion string) (AddonVersions, error) {
+	minorVersion, err := minorVersion(kubernetesVersion)
+	if err != nil {
+		return AddonVersions{}, err
+	}
+
+	versions, ok := addonVersionMap[minorVersion]
+	if !ok {
+		return AddonVersions{}, errors.Errorf("unsupported Kubernetes version %q", kubernetesVersion)
+	}
+
+	return versions, nil
+}
+
+// addonRegistryAccounts contains the accounts of the regional EKS image registries that differ from the default.
+// nolint: gochecknoglobals
+var addonRegistryAccounts = map[string]string{
+	"ap-east-1":  "800184023465",
+	"me-south-1": "558608220178",
+}
+
+const defaultAddonRegistryAccount = "602401143452"
+
+// GetAddonImage returns the image of an EKS addon in a region.
+func GetAddonImage(region string, image string, version string) string {
+	account, ok := addonRegistryAccounts[region]
+	if !ok {
+		account = defaultAddonRegistryAccount
+	}
+
+	return fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s:%s", account, region, image, version)
+}
+
+// ClusterUpgrade describes a Kubernetes version upgrade of an EKS cluster.
+type ClusterUpgrade struct {
+	// CurrentVersion is the Kubernetes version the control plane is running.
+	CurrentVersion string
+
+	// TargetVersion is the Kubernetes version the cluster is upgraded to.
+	TargetVersion string
+
+	// NodePools are the names of the node pools rolled to the new version after the control plane upgrade.
+	NodePools []string
+}
+
+// ValidateVersionUpgrade validates that a cluster can be upgraded from one Kubernetes version to another.
+func ValidateVersionUpgrade(currentVersion string, targetVersion string) error {
+	var violations []string
+
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("invalid current version %q", currentVersion))
+	}
+
+	target, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("invalid target version %q", targetVersion))
+	}
+
+	if len(violations) == 0 {
+		if target.Major() != current.Major() {
+			violations = append(violations, "major version upgrades are not supported")
+		} else if target.Minor() <= current.Minor() {
+			violations = append(violations, fmt.Sprintf("target version %q must be newer than the current version %q", targetVersion, currentVersion))
+		}
+
+		if _, err := GetAddonVersions(targetVersion); err != nil {
+			violations = append(violations, fmt.Sprintf("unsupported target version %q", targetVersion))
+		}
+	}
+
+	if len(violations) > 0 {
+		return cluster.NewValidationError("invalid cluster upgrade request", violations)
+	}
+
+	return nil
+}
+
+// UpgradePath returns the minor versions a control plane has to be upgraded to (one by one)
+// to reach the target version from the current one.
+func UpgradePath(currentVersion string, targetVersion string) ([]string, error) {
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid Kubernetes version", "version", currentVersion)
+	}
+
+	target, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid Kubernetes version", "version", targetVersion)
+	}
+
+	if target.Major() != current.Major() {
+		return nil, errors.NewWithDetails("major version upgrades are not supported", "current", currentVersion, "target", targetVersion)
+	}
+
+	var path []string
+
+	for minor := current.Minor() + 1; minor <= target.Minor(); minor++ {
+		path = append(path, fmt.Sprintf("%d.%d", current.Major(), minor))
+	}
+
+	return path, nil
+}
+
+func minorVersion(kubernetesVersion string) (string, error) {
+	version, err := semver.NewVersion(kubernetesVersion)
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "invalid Kubernetes version", "version", kubernetesVersion)
+	}
+
+	return fmt.Sprintf("%d.%d", version.Major(), version.Minor()), nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eks
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+func TestValidateVersionUpgrade(t *testing.T) {
+	tests := []struct {
+		current string
+		target  string
+		valid   bool
+	}{
+		{current: "1.13", target: "1.14", valid: true},
+		{current: "1.13", target: "1.15", valid: true},
+		{current: "1.14.9", target: "1.15", valid: true},
+		{current: "1.15", target: "1.15", valid: false},
+		{current: "1.15", target: "1.14", valid: false},
+		{current: "1.15", target: "1.19", valid: false},
+		{current: "1.15", target: "2.0", valid: false},
+		{current: "1.15", target: "invalid", valid: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.current+"-"+test.target, func(t *testing.T) {
+			err := ValidateVersionUpgrade(test.current, test.target)
+
+			if test.valid {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.As(err, &cluster.ValidationError{}))
+		})
+	}
+}
+
+func TestUpgradePath(t *testing.T) {
+	path, err := UpgradePath("1.13.12", "1.15")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.14", "1.15"}, path)
+
+	path, err = UpgradePath("1.15", "1.15")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	_, err = UpgradePath("1.15", "2.0")
+	require.Error(t, err)
+}
+
+func TestGetAddonVersions(t *testing.T) {
+	versions, err := GetAddonVersions("1.15.10")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.15.11", versions.KubeProxy)
+
+	_, err = GetAddonVersions("1.8")
+	require.Error(t, err)
+}
+
+func TestGetAddonImage(t *testing.T) {
+	assert.Equal(t, "602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:v1.15.11", GetAddonImage("eu-west-1", "eks/kube-proxy", "v1.15.11"))
+	assert.Equal(t, "800184023465.dkr.ecr.ap-east-1.amazonaws.com/eks/coredns:v1.6.6", GetAddonImage("ap-east-1", "eks/coredns", "v1.6.6"))
+}
@@ -0,0 +1,125 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter/clustermodel"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	azurePke "github.com/banzaicloud/pipeline/internal/providers/azure/pke"
+	internalPke "github.com/banzaicloud/pipeline/internal/providers/pke"
+	vspherePKE "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type clusterStore struct {
+	db              *gorm.DB
+	azureClusters   azurePke.ClusterStore
+	vsphereClusters vspherePKE.ClusterStore
+}
+
+// NewClusterStore returns a new pke.ClusterStore
+// that provides an interface to the persistence of PKE clusters on every supported cloud.
+func NewClusterStore(db *gorm.DB, azureClusters azurePke.ClusterStore, vsphereClusters vspherePKE.ClusterStore) pke.ClusterStore {
+	return clusterStore{
+		db:              db,
+		azureClusters:   azureClusters,
+		vsphereClusters: vsphereClusters,
+	}
+}
+
+func (s clusterStore) GetClusterVersion(_ context.Context, clusterID uint) (string, error) {
+	cloud, err := s.getCloud(clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	switch cloud {
+	case pkgCluster.Amazon:
+		var model internalPke.Kubernetes
+
+		err := s.db.Where(internalPke.Kubernetes{Model: internalPke.Model{ClusterID: clusterID}}).First(&model).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return "", errors.WithStack(cluster.NotFoundError{ClusterID: clusterID})
+		} else if err != nil {
+			return "", errors.WrapWithDetails(err, "failed to get cluster", "clusterId", clusterID)
+		}
+
+		return model.Version, nil
+
+	case pkgCluster.Azure:
+		c, err := s.azureClusters.GetByID(clusterID)
+		if err != nil {
+			return "", err
+		}
+
+		return c.Kubernetes.Version, nil
+
+	case pkgCluster.Vsphere:
+		c, err := s.vsphereClusters.GetByID(clusterID)
+		if err != nil {
+			return "", err
+		}
+
+		return c.Kubernetes.Version, nil
+	}
+
+	return "", errors.NewWithDetails("unsupported cloud", "clusterId", clusterID, "cloud", cloud)
+}
+
+func (s clusterStore) SetClusterVersion(_ context.Context, clusterID uint, version string) error {
+	cloud, err := s.getCloud(clusterID)
+	if err != nil {
+		return err
+	}
+
+	switch cloud {
+	case pkgCluster.Amazon:
+		err := s.db.Model(internalPke.Kubernetes{}).
+			Where(internalPke.Kubernetes{Model: internalPke.Model{ClusterID: clusterID}}).
+			UpdateColumn("version", version).Error
+		if err != nil {
+			return errors.WrapWithDetails(err, "failed to save cluster version", "clusterId", clusterID)
+		}
+
+		return nil
+
+	case pkgCluster.Azure:
+		return s.azureClusters.SetKubernetesVersion(clusterID, version)
+
+	case pkgCluster.Vsphere:
+		return s.vsphereClusters.SetKubernetesVersion(clusterID, version)
+	}
+
+	return errors.NewWithDetails("unsupported cloud", "clusterId", clusterID, "cloud", cloud)
+}
+
+func (s clusterStore) getCloud(clusterID uint) (string, error) {
+	var model clustermodel.ClusterModel
+
+	err := s.db.Where(clustermodel.ClusterModel{ID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return "", errors.WithStack(cluster.NotFoundError{ClusterID: clusterID})
+	} else if err != nil {
+		return "", errors.WrapWithDetails(err, "failed to get cluster", "clusterId", clusterID)
+	}
+
+	return model.Cloud, nil
+}
@@ -0,0 +1,158 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const CheckNodeHealthActivityName = "pke-check-node-health"
+
+// ErrReasonClusterUnhealthy cadence custom error reason that denotes a failed health check after upgrading a node
+const ErrReasonClusterUnhealthy = "PKE_CLUSTER_UNHEALTHY"
+
+// CheckNodeHealthActivity checks whether an upgraded node (and the cluster) became healthy.
+type CheckNodeHealthActivity struct {
+	clientFactory clusterworkflow.ClientFactory
+	timeout       time.Duration
+
+	pollInterval time.Duration
+}
+
+// CheckNodeHealthActivityInput holds the parameters for checking the health of an upgraded node.
+type CheckNodeHealthActivityInput struct {
+	ClusterID uint
+	NodeName  string
+	Version   string
+
+	// Master enables checking the control plane components of the node.
+	Master bool
+}
+
+// NewCheckNodeHealthActivity creates a new CheckNodeHealthActivity instance.
+func NewCheckNodeHealthActivity(clientFactory clusterworkflow.ClientFactory, timeout time.Duration) CheckNodeHealthActivity {
+	return CheckNodeHealthActivity{
+		clientFactory: clientFactory,
+		timeout:       timeout,
+
+		pollInterval: 10 * time.Second,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a CheckNodeHealthActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: CheckNodeHealthActivityName})
+}
+
+// Execute waits until the node and the cluster are healthy.
+// The problems found are recorded in the activity heartbeat.
+func (a CheckNodeHealthActivity) Execute(ctx context.Context, input CheckNodeHealthActivityInput) error {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return _cadence.WrapClientError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		problems := getHealthProblems(client, input)
+		if len(problems) == 0 {
+			return nil
+		}
+
+		activity.RecordHeartbeat(ctx, problems)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return cadence.NewCustomError(
+				ErrReasonClusterUnhealthy,
+				fmt.Sprintf("cluster is unhealthy after upgrading node %s: %s", input.NodeName, strings.Join(problems, "; ")),
+			)
+		}
+	}
+}
+
+// getHealthProblems returns the reasons why an upgraded node or the cluster is not healthy.
+func getHealthProblems(client kubernetes.Interface, input CheckNodeHealthActivityInput) []string {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return []string{errors.WrapIf(err, "failed to list nodes").Error()}
+	}
+
+	var problems []string
+
+	found := false
+
+	for _, node := range nodes.Items {
+		if !isNodeReady(node) {
+			problems = append(problems, fmt.Sprintf("node %s is not ready", node.Name))
+		}
+
+		if node.Name != input.NodeName {
+			continue
+		}
+
+		found = true
+
+		if version := node.Status.NodeInfo.KubeletVersion; !pke.IsVersion(version, input.Version) {
+			problems = append(problems, fmt.Sprintf("kubelet of node %s runs version %s", node.Name, version))
+		}
+	}
+
+	if !found {
+		problems = append(problems, fmt.Sprintf("node %s not found", input.NodeName))
+	}
+
+	if !input.Master {
+		return problems
+	}
+
+	for _, component := range controlPlaneComponents {
+		pod, err := getControlPlaneComponent(client, component, input.NodeName)
+		if err != nil {
+			problems = append(problems, errors.WrapIff(err, "failed to get %s", component).Error())
+
+			continue
+		}
+
+		if version := getImageVersion(*pod); !pke.IsVersion(version, input.Version) {
+			problems = append(problems, fmt.Sprintf("%s runs version %s", component, version))
+		}
+
+		if !isPodReady(*pod) {
+			problems = append(problems, fmt.Sprintf("%s is not ready", component))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,153 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const UpgradeClusterPreflightActivityName = "pke-upgrade-cluster-preflight"
+
+// ErrReasonUpgradePreflightFailed cadence custom error reason that denotes a failed pre-flight check
+const ErrReasonUpgradePreflightFailed = "PKE_UPGRADE_PREFLIGHT_FAILED"
+
+// UpgradeClusterPreflightActivity checks whether a cluster is ready to be upgraded
+// and collects the nodes that need to be upgraded.
+type UpgradeClusterPreflightActivity struct {
+	clientFactory clusterworkflow.ClientFactory
+}
+
+// UpgradeClusterPreflightActivityInput holds the parameters for the pre-flight check.
+type UpgradeClusterPreflightActivityInput struct {
+	ClusterID     uint
+	TargetVersion string
+}
+
+type UpgradeClusterPreflightActivityOutput struct {
+	// ControlPlaneUpgraded tells whether the control plane of a master node already runs the target version
+	// (eg. because of a previous, failed upgrade), so that the cluster configuration must not be upgraded again.
+	ControlPlaneUpgraded bool
+
+	// MasterNodes are the master nodes not running the target version yet.
+	MasterNodes []string
+
+	// WorkerNodes are the worker nodes not running the target version yet.
+	WorkerNodes []string
+}
+
+// NewUpgradeClusterPreflightActivity creates a new UpgradeClusterPreflightActivity instance.
+func NewUpgradeClusterPreflightActivity(clientFactory clusterworkflow.ClientFactory) UpgradeClusterPreflightActivity {
+	return UpgradeClusterPreflightActivity{
+		clientFactory: clientFactory,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a UpgradeClusterPreflightActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: UpgradeClusterPreflightActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a UpgradeClusterPreflightActivity) Execute(
+	ctx context.Context,
+	input UpgradeClusterPreflightActivityInput,
+) (UpgradeClusterPreflightActivityOutput, error) {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return UpgradeClusterPreflightActivityOutput{}, _cadence.WrapClientError(err)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return UpgradeClusterPreflightActivityOutput{}, errors.WrapIf(err, "failed to list nodes")
+	}
+
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+
+	var output UpgradeClusterPreflightActivityOutput
+	var violations []string
+
+	for _, node := range nodes.Items {
+		if !isNodeReady(node) {
+			violations = append(violations, fmt.Sprintf("node %s is not ready", node.Name))
+		}
+
+		master := isMasterNode(node)
+		upgraded := pke.IsVersion(node.Status.NodeInfo.KubeletVersion, input.TargetVersion)
+
+		// Version skew: kubelets must not be older than one minor version below the target version
+		if !upgraded {
+			err := pke.ValidateVersionUpgrade(strings.TrimPrefix(node.Status.NodeInfo.KubeletVersion, "v"), input.TargetVersion)
+
+			var validationErr interface{ Violations() []string }
+			if errors.As(err, &validationErr) {
+				violations = append(violations, fmt.Sprintf(
+					"node %s cannot be upgraded from %s: %s",
+					node.Name, node.Status.NodeInfo.KubeletVersion, strings.Join(validationErr.Violations(), ", "),
+				))
+			} else if err != nil {
+				return UpgradeClusterPreflightActivityOutput{}, err
+			}
+		}
+
+		if master {
+			pod, err := getControlPlaneComponent(client, "kube-apiserver", node.Name)
+			if err != nil {
+				return UpgradeClusterPreflightActivityOutput{}, errors.WrapIfWithDetails(err, "failed to get API server", "node", node.Name)
+			}
+
+			if pke.IsVersion(getImageVersion(*pod), input.TargetVersion) {
+				output.ControlPlaneUpgraded = true
+			} else {
+				upgraded = false
+			}
+		}
+
+		if upgraded {
+			continue
+		}
+
+		if master {
+			output.MasterNodes = append(output.MasterNodes, node.Name)
+		} else {
+			output.WorkerNodes = append(output.WorkerNodes, node.Name)
+		}
+	}
+
+	if len(output.MasterNodes) == 0 && !output.ControlPlaneUpgraded {
+		violations = append(violations, "no master nodes found")
+	}
+
+	if len(violations) > 0 {
+		return UpgradeClusterPreflightActivityOutput{}, cadence.NewCustomError(
+			ErrReasonUpgradePreflightFailed,
+			"cluster is not ready for upgrade: "+strings.Join(violations, "; "),
+		)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,203 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+)
+
+type staticClientFactory struct {
+	client kubernetes.Interface
+}
+
+func (f staticClientFactory) FromClusterID(_ context.Context, _ uint) (kubernetes.Interface, error) {
+	return f.client, nil
+}
+
+func newNode(name string, master bool, kubeletVersion string, ready bool) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+		},
+	}
+
+	if master {
+		node.Labels[intClusterK8s.MasterNodeLabel] = ""
+	}
+
+	if ready {
+		node.Status.Conditions[0].Status = corev1.ConditionTrue
+	}
+
+	return node
+}
+
+func newControlPlanePod(component string, node string, version string, ready bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component + "-" + node,
+			Namespace: controlPlaneNamespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: component, Image: "k8s.gcr.io/" + component + ":" + version}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+		},
+	}
+
+	if ready {
+		pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	}
+
+	return pod
+}
+
+func TestUpgradeClusterPreflightActivity(t *testing.T) {
+	input := UpgradeClusterPreflightActivityInput{ClusterID: 1, TargetVersion: "1.17.5"}
+
+	t.Run("OK", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			newNode("master-1", true, "v1.16.9", true),
+			newNode("master-0", true, "v1.16.9", true),
+			newNode("worker-0", false, "v1.16.9", true),
+			newControlPlanePod("kube-apiserver", "master-0", "v1.16.9", true),
+			newControlPlanePod("kube-apiserver", "master-1", "v1.16.9", true),
+		)
+
+		output, err := NewUpgradeClusterPreflightActivity(staticClientFactory{client}).Execute(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, UpgradeClusterPreflightActivityOutput{
+			MasterNodes: []string{"master-0", "master-1"},
+			WorkerNodes: []string{"worker-0"},
+		}, output)
+	})
+
+	t.Run("PartiallyUpgraded", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			newNode("master-0", true, "v1.17.5", true),
+			newNode("master-1", true, "v1.16.9", true),
+			newNode("worker-0", false, "v1.17.5", true),
+			newNode("worker-1", false, "v1.16.9", true),
+			newControlPlanePod("kube-apiserver", "master-0", "v1.17.5", true),
+			newControlPlanePod("kube-apiserver", "master-1", "v1.16.9", true),
+		)
+
+		output, err := NewUpgradeClusterPreflightActivity(staticClientFactory{client}).Execute(context.Background(), input)
+		require.NoError(t, err)
+
+		assert.Equal(t, UpgradeClusterPreflightActivityOutput{
+			ControlPlaneUpgraded: true,
+			MasterNodes:          []string{"master-1"},
+			WorkerNodes:          []string{"worker-1"},
+		}, output)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		objects := []runtime.Object{
+			newNode("master-0", true, "v1.16.9", true),
+			newNode("worker-0", false, "v1.15.11", true),
+			newNode("worker-1", false, "v1.16.9", false),
+			newControlPlanePod("kube-apiserver", "master-0", "v1.16.9", true),
+		}
+
+		_, err := NewUpgradeClusterPreflightActivity(staticClientFactory{fake.NewSimpleClientset(objects...)}).Execute(context.Background(), input)
+		require.Error(t, err)
+
+		var customErr *cadence.CustomError
+		require.True(t, errors.As(err, &customErr))
+		assert.Equal(t, ErrReasonUpgradePreflightFailed, customErr.Reason())
+
+		var message string
+		require.NoError(t, customErr.Details(&message))
+		assert.Contains(t, message, "node worker-0 cannot be upgraded from v1.15.11")
+		assert.Contains(t, message, "node worker-1 is not ready")
+	})
+}
+
+func TestGetHealthProblems(t *testing.T) {
+	input := CheckNodeHealthActivityInput{ClusterID: 1, NodeName: "master-0", Version: "1.17.5", Master: true}
+
+	t.Run("Healthy", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			newNode("master-0", true, "v1.17.5", true),
+			newNode("worker-0", false, "v1.16.9", true),
+			newControlPlanePod("kube-apiserver", "master-0", "v1.17.5", true),
+			newControlPlanePod("kube-controller-manager", "master-0", "v1.17.5", true),
+			newControlPlanePod("kube-scheduler", "master-0", "v1.17.5", true),
+		)
+
+		assert.Empty(t, getHealthProblems(client, input))
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			newNode("master-0", true, "v1.16.9", true),
+			newNode("worker-0", false, "v1.16.9", false),
+			newControlPlanePod("kube-apiserver", "master-0", "v1.17.5", false),
+			newControlPlanePod("kube-controller-manager", "master-0", "v1.16.9", true),
+		)
+
+		assert.Equal(
+			t,
+			[]string{
+				"kubelet of node master-0 runs version v1.16.9",
+				"node worker-0 is not ready",
+				"kube-apiserver is not ready",
+				"kube-controller-manager runs version v1.16.9",
+				`failed to get kube-scheduler: pods "kube-scheduler-master-0" not found`,
+			},
+			getHealthProblems(client, input),
+		)
+	})
+
+	t.Run("Worker", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			newNode("worker-0", false, "v1.17.5", true),
+		)
+
+		assert.Empty(t, getHealthProblems(client, CheckNodeHealthActivityInput{ClusterID: 1, NodeName: "worker-0", Version: "1.17.5"}))
+	})
+}
+
+func TestUpgradePod(t *testing.T) {
+	pod := upgradePod(UpgradeNodeActivityInput{ClusterID: 1, NodeName: "master-0", Version: "v1.17.5", Apply: true}, "busybox:1.31")
+
+	assert.Equal(t, "master-0", pod.Spec.NodeName)
+	assert.Equal(t, "busybox:1.31", pod.Spec.Containers[0].Image)
+	assert.Equal(t, []corev1.EnvVar{{Name: "VERSION", Value: "1.17.5"}, {Name: "APPLY", Value: "true"}}, pod.Spec.Containers[0].Env)
+	assert.True(t, *pod.Spec.Containers[0].SecurityContext.Privileged)
+}
@@ -0,0 +1,177 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+)
+
+const UpgradeNodeActivityName = "pke-upgrade-node"
+
+// ErrReasonNodeUpgradeFailed cadence custom error reason that denotes a failed node upgrade
+const ErrReasonNodeUpgradeFailed = "PKE_NODE_UPGRADE_FAILED"
+
+const (
+	upgradePodLabelName  = "app.kubernetes.io/name"
+	upgradePodLabelValue = "pipeline-upgrade"
+	upgradeContainer     = "upgrade"
+)
+
+// UpgradeNodeActivity upgrades the Kubernetes components of a node with kubeadm.
+//
+// The upgrade is executed by a privileged pod scheduled on the node,
+// which runs kubeadm and the package manager in the root file system of the node.
+type UpgradeNodeActivity struct {
+	clientFactory clusterworkflow.ClientFactory
+	image         string
+	timeout       time.Duration
+
+	pollInterval time.Duration
+}
+
+// UpgradeNodeActivityInput holds the parameters for upgrading a node.
+type UpgradeNodeActivityInput struct {
+	ClusterID uint
+	NodeName  string
+	Version   string
+
+	// Apply upgrades the cluster configuration and the control plane with "kubeadm upgrade apply".
+	// It must be set for the first master node only, other nodes are upgraded with "kubeadm upgrade node".
+	Apply bool
+}
+
+// NewUpgradeNodeActivity creates a new UpgradeNodeActivity instance.
+func NewUpgradeNodeActivity(clientFactory clusterworkflow.ClientFactory, image string, timeout time.Duration) UpgradeNodeActivity {
+	return UpgradeNodeActivity{
+		clientFactory: clientFactory,
+		image:         image,
+		timeout:       timeout,
+
+		pollInterval: 10 * time.Second,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a UpgradeNodeActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: UpgradeNodeActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a UpgradeNodeActivity) Execute(ctx context.Context, input UpgradeNodeActivityInput) error {
+	client, err := a.clientFactory.FromClusterID(ctx, input.ClusterID)
+	if err != nil {
+		return _cadence.WrapClientError(err)
+	}
+
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "node", input.NodeName, "version", input.Version)
+
+	pod, err := client.CoreV1().Pods(controlPlaneNamespace).Create(upgradePod(input, a.image))
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to create node upgrade pod", "node", input.NodeName)
+	}
+	defer func() {
+		_ = client.CoreV1().Pods(controlPlaneNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+	}()
+
+	logger.Infow("upgrading node", "pod", pod.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	// The API server is restarted when upgrading master nodes, so errors of getting the pod are tolerated
+	err = intClusterK8s.Poll(ctx, a.pollInterval, func() (bool, error) {
+		activity.RecordHeartbeat(ctx)
+
+		return intClusterK8s.IsPodCompleted(client, controlPlaneNamespace, pod.Name)
+	})
+
+	if ctx.Err() != nil {
+		return cadence.NewCustomError(ErrReasonNodeUpgradeFailed, fmt.Sprintf("node %s was not upgraded in time", input.NodeName))
+	}
+
+	if err != nil {
+		return cadence.NewCustomError(ErrReasonNodeUpgradeFailed, fmt.Sprintf("failed to upgrade node %s: %s", input.NodeName, err.Error()))
+	}
+
+	logger.Info("node upgraded")
+
+	return nil
+}
+
+// upgradeScript upgrades the Kubernetes components of a node installed by PKE.
+// It runs in the root file system of the node to use its package manager and kubeadm.
+// The kubelet is restarted last, the upgrade pod keeps running meanwhile.
+const upgradeScript = `
+set -eu
+
+install_package() {
+  if command -v yum >/dev/null 2>&1; then
+    yum install -y --disableexcludes=kubernetes "$1-$VERSION"
+  else
+    apt-get update -q
+    apt-get install -y -q --allow-change-held-packages "$1=$VERSION-00"
+  fi
+}
+
+install_package kubeadm
+
+if [ "$APPLY" = "true" ]; then
+  kubeadm upgrade apply --yes "v$VERSION"
+else
+  kubeadm upgrade node
+fi
+
+install_package kubelet
+install_package kubectl
+
+systemctl daemon-reload
+systemctl restart kubelet
+
+echo "node upgraded to $VERSION"
+`
+
+// upgradePod returns a privileged pod upgrading a node.
+func upgradePod(input UpgradeNodeActivityInput, image string) *corev1.Pod {
+	return intClusterK8s.NewNodePod(intClusterK8s.NodePodSpec{
+		Namespace:    controlPlaneNamespace,
+		GenerateName: "pipeline-upgrade-",
+		Labels:       map[string]string{upgradePodLabelName: upgradePodLabelValue},
+		NodeName:     input.NodeName,
+		Container:    upgradeContainer,
+		Image:        image,
+		Command:      intClusterK8s.HostRootCommand(upgradeScript),
+		Env: []corev1.EnvVar{
+			{Name: "VERSION", Value: strings.TrimPrefix(input.Version, "v")},
+			{Name: "APPLY", Value: fmt.Sprint(input.Apply)},
+		},
+		Privileged:  true,
+		HostNetwork: true,
+		HostPID:     true,
+		Volumes:     []intClusterK8s.NodePodVolume{intClusterK8s.HostRootVolume()},
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+)
+
+const controlPlaneNamespace = "kube-system"
+
+// controlPlaneComponents are the static pods kubeadm upgrades on every master node.
+// nolint: gochecknoglobals
+var controlPlaneComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+
+func setClusterStatus(ctx workflow.Context, clusterID uint, status, statusMessage string) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    2 * time.Minute,
+		WaitForCancellation:    true,
+	})
+
+	return workflow.ExecuteActivity(ctx, clusterworkflow.SetClusterStatusActivityName, clusterworkflow.SetClusterStatusActivityInput{
+		ClusterID:     clusterID,
+		Status:        status,
+		StatusMessage: statusMessage,
+	}).Get(ctx, nil)
+}
+
+func isMasterNode(node corev1.Node) bool {
+	_, ok := node.Labels[intClusterK8s.MasterNodeLabel]
+
+	return ok
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// getControlPlaneComponent returns the static (mirror) pod of a control plane component running on a master node.
+func getControlPlaneComponent(client kubernetes.Interface, component string, node string) (*corev1.Pod, error) {
+	return client.CoreV1().Pods(controlPlaneNamespace).Get(component+"-"+node, metav1.GetOptions{})
+}
+
+// getImageVersion returns the tag of the first container image of a pod.
+func getImageVersion(pod corev1.Pod) string {
+	if len(pod.Spec.Containers) == 0 {
+		return ""
+	}
+
+	image := pod.Spec.Containers[0].Image
+
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+
+	return image[i+1:]
+}
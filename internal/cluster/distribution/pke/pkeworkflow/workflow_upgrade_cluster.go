@@ -0,0 +1,309 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	_cadence "github.com/banzaicloud/pipeline/pkg/cadence"
+	"github.com/banzaicloud/pipeline/pkg/sdk/brn"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+const UpgradeClusterWorkflowName = "pke-upgrade-cluster"
+
+// UpgradeClusterWorkflow upgrades the Kubernetes version of a PKE cluster in place:
+// master nodes are upgraded with kubeadm one at a time, then worker nodes are drained and upgraded one by one.
+//
+// Every node has to become healthy after its upgrade, otherwise the upgrade stops.
+// Nodes already running the target version are skipped, so a failed upgrade can be continued by starting it again.
+type UpgradeClusterWorkflow struct {
+	processLogger processlog.ProcessLogger
+}
+
+// NewUpgradeClusterWorkflow returns a new UpgradeClusterWorkflow.
+func NewUpgradeClusterWorkflow(processLogger processlog.ProcessLogger) UpgradeClusterWorkflow {
+	return UpgradeClusterWorkflow{
+		processLogger: processLogger,
+	}
+}
+
+type UpgradeClusterWorkflowInput struct {
+	OrganizationID uint
+	ClusterID      uint
+	ClusterName    string
+
+	CurrentVersion string
+	TargetVersion  string
+}
+
+func (w UpgradeClusterWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: UpgradeClusterWorkflowName})
+}
+
+func (w UpgradeClusterWorkflow) Execute(ctx workflow.Context, input UpgradeClusterWorkflowInput) (err error) {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    2 * time.Minute,
+		WaitForCancellation:    true,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    10,
+			NonRetriableErrorReasons: []string{
+				"cadenceInternal:Panic",
+				_cadence.ClientErrorReason,
+			},
+		},
+	}
+
+	clusterID := brn.New(input.OrganizationID, brn.ClusterResourceType, fmt.Sprint(input.ClusterID))
+
+	process := w.processLogger.StartProcess(ctx, clusterID.String())
+	defer func() {
+		process.Finish(ctx, err)
+	}()
+	defer func() {
+		status := cluster.Running
+		statusMessage := cluster.RunningMessage
+
+		if err != nil {
+			if cadence.IsCanceledError(err) {
+				ctx, _ = workflow.NewDisconnectedContext(ctx)
+			}
+
+			status = cluster.Warning
+			statusMessage = fmt.Sprintf("failed to upgrade cluster: %s", err.Error())
+		}
+
+		_ = setClusterStatus(ctx, input.ClusterID, status, statusMessage)
+	}()
+
+	var preflight UpgradeClusterPreflightActivityOutput
+	{
+		activityInput := UpgradeClusterPreflightActivityInput{
+			ClusterID:     input.ClusterID,
+			TargetVersion: input.TargetVersion,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    5,
+			NonRetriableErrorReasons: []string{
+				"cadenceInternal:Panic",
+				_cadence.ClientErrorReason,
+				ErrReasonUpgradePreflightFailed,
+			},
+		}
+
+		processActivity := process.StartActivity(ctx, UpgradeClusterPreflightActivityName)
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			UpgradeClusterPreflightActivityName,
+			activityInput,
+		).Get(ctx, &preflight)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, nodeName := range preflight.MasterNodes {
+		err = w.upgradeNode(ctx, activityOptions, process, input, nodeName, true, i == 0 && !preflight.ControlPlaneUpgraded)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, nodeName := range preflight.WorkerNodes {
+		err = w.drainNode(ctx, activityOptions, process, input, nodeName)
+		if err != nil {
+			return err
+		}
+
+		// A node failing to upgrade is left cordoned
+		err = w.upgradeNode(ctx, activityOptions, process, input, nodeName, false, false)
+		if err != nil {
+			return err
+		}
+
+		activityInput := clusterworkflow.CordonNodesActivityInput{
+			ClusterID: input.ClusterID,
+			NodeNames: []string{nodeName},
+			Uncordon:  true,
+		}
+
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			clusterworkflow.CordonNodesActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The version is only saved once every node runs it (nodes joining the cluster from now on are installed with it),
+	// so that a failed upgrade can be continued by starting it again with the same target version.
+	{
+		activityInput := SaveClusterVersionActivityInput{
+			ClusterID: input.ClusterID,
+			Version:   input.TargetVersion,
+		}
+
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			SaveClusterVersionActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeNode upgrades a node and waits until it becomes healthy.
+func (w UpgradeClusterWorkflow) upgradeNode(
+	ctx workflow.Context,
+	activityOptions workflow.ActivityOptions,
+	process processlog.Process,
+	input UpgradeClusterWorkflowInput,
+	nodeName string,
+	master bool,
+	apply bool,
+) error {
+	{
+		activityInput := UpgradeNodeActivityInput{
+			ClusterID: input.ClusterID,
+			NodeName:  nodeName,
+			Version:   input.TargetVersion,
+			Apply:     apply,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 2 * time.Hour // the upgrade timeout is enforced by the activity itself
+		activityOptions.HeartbeatTimeout = 2 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    3,
+			NonRetriableErrorReasons: []string{
+				"cadenceInternal:Panic",
+				_cadence.ClientErrorReason,
+				ErrReasonNodeUpgradeFailed,
+			},
+		}
+
+		processActivity := process.StartActivity(ctx, UpgradeNodeActivityName)
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			UpgradeNodeActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	{
+		activityInput := CheckNodeHealthActivityInput{
+			ClusterID: input.ClusterID,
+			NodeName:  nodeName,
+			Version:   input.TargetVersion,
+			Master:    master,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = time.Hour // the health check timeout is enforced by the activity itself
+		activityOptions.HeartbeatTimeout = 2 * time.Minute
+		activityOptions.RetryPolicy = &cadence.RetryPolicy{
+			InitialInterval:    15 * time.Second,
+			BackoffCoefficient: 1.0,
+			MaximumAttempts:    3,
+			NonRetriableErrorReasons: []string{
+				"cadenceInternal:Panic",
+				_cadence.ClientErrorReason,
+				ErrReasonClusterUnhealthy,
+			},
+		}
+
+		processActivity := process.StartActivity(ctx, CheckNodeHealthActivityName)
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			CheckNodeHealthActivityName,
+			activityInput,
+		).Get(ctx, nil)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drainNode evicts the pods of a worker node before upgrading it.
+// If the node cannot be drained, it is made schedulable again.
+func (w UpgradeClusterWorkflow) drainNode(
+	ctx workflow.Context,
+	activityOptions workflow.ActivityOptions,
+	process processlog.Process,
+	input UpgradeClusterWorkflowInput,
+	nodeName string,
+) error {
+	activityInput := clusterworkflow.DrainNodeActivityInput{
+		ClusterID: input.ClusterID,
+		NodeName:  nodeName,
+	}
+
+	activityOptions.StartToCloseTimeout = 2 * time.Hour // the drain timeout is enforced by the activity itself
+	activityOptions.HeartbeatTimeout = time.Minute
+	activityOptions.RetryPolicy = &cadence.RetryPolicy{
+		InitialInterval:    15 * time.Second,
+		BackoffCoefficient: 1.0,
+		MaximumAttempts:    3,
+		NonRetriableErrorReasons: []string{
+			"cadenceInternal:Panic",
+			_cadence.ClientErrorReason,
+			clusterworkflow.ErrReasonNodeDrainTimeout,
+		},
+	}
+
+	processActivity := process.StartActivity(ctx, clusterworkflow.DrainNodeActivityName)
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, activityOptions),
+		clusterworkflow.DrainNodeActivityName,
+		activityInput,
+	).Get(ctx, nil)
+	processActivity.Finish(ctx, err)
+	if err != nil {
+		clusterworkflow.UncordonNodes(ctx, input.ClusterID, []string{nodeName})
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkeworkflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+// nolint: gochecknoinits
+func init() {
+	NewUpgradeClusterPreflightActivity(nil).Register()
+	NewUpgradeNodeActivity(nil, "", 0).Register()
+	NewCheckNodeHealthActivity(nil, 0).Register()
+	NewSaveClusterVersionActivity(nil).Register()
+
+	for name, fn := range map[string]interface{}{
+		clusterworkflow.SetClusterStatusActivityName: clusterworkflow.NewSetClusterStatusActivity(nil).Execute,
+		clusterworkflow.CordonNodesActivityName:      clusterworkflow.NewCordonNodesActivity(nil).Execute,
+		clusterworkflow.DrainNodeActivityName:        clusterworkflow.NewDrainNodeActivity(nil, kubernetes.NodeDrainOptions{}).Execute,
+	} {
+		activity.RegisterWithOptions(fn, activity.RegisterOptions{Name: name})
+	}
+}
+
+type noopProcessLogger struct{}
+
+func (noopProcessLogger) StartProcess(_ workflow.Context, _ string) processlog.Process {
+	return noopProcess{}
+}
+
+type noopProcess struct{}
+
+func (noopProcess) Finish(_ workflow.Context, _ error) {}
+
+func (noopProcess) StartActivity(_ workflow.Context, _ string) processlog.Activity {
+	return noopProcess{}
+}
+
+type UpgradeClusterWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestUpgradeClusterWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(UpgradeClusterWorkflowTestSuite))
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewUpgradeClusterWorkflow(noopProcessLogger{}).Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+var upgradeClusterWorkflowInput = UpgradeClusterWorkflowInput{ // nolint: gochecknoglobals
+	OrganizationID: 1,
+	ClusterID:      2,
+	ClusterName:    "cluster",
+	CurrentVersion: "1.16.9",
+	TargetVersion:  "1.17.5",
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) onPreflight() {
+	s.env.OnActivity(
+		UpgradeClusterPreflightActivityName,
+		mock.Anything,
+		UpgradeClusterPreflightActivityInput{ClusterID: 2, TargetVersion: "1.17.5"},
+	).Return(UpgradeClusterPreflightActivityOutput{
+		MasterNodes: []string{"master-0", "master-1"},
+		WorkerNodes: []string{"worker-0"},
+	}, nil)
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) onUpgradeNode(node string, master bool, apply bool) {
+	s.env.OnActivity(
+		UpgradeNodeActivityName,
+		mock.Anything,
+		UpgradeNodeActivityInput{ClusterID: 2, NodeName: node, Version: "1.17.5", Apply: apply},
+	).Return(nil).Once()
+
+	s.env.OnActivity(
+		CheckNodeHealthActivityName,
+		mock.Anything,
+		CheckNodeHealthActivityInput{ClusterID: 2, NodeName: node, Version: "1.17.5", Master: master},
+	).Return(nil).Once()
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) onSetClusterStatus(status string) {
+	s.env.OnActivity(
+		clusterworkflow.SetClusterStatusActivityName,
+		mock.Anything,
+		mock.MatchedBy(func(input clusterworkflow.SetClusterStatusActivityInput) bool {
+			return input.ClusterID == 2 && input.Status == status
+		}),
+	).Return(nil)
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) Test_Success() {
+	s.onPreflight()
+	s.onUpgradeNode("master-0", true, true)
+	s.onUpgradeNode("master-1", true, false)
+
+	s.env.OnActivity(
+		SaveClusterVersionActivityName,
+		mock.Anything,
+		SaveClusterVersionActivityInput{ClusterID: 2, Version: "1.17.5"},
+	).Return(nil)
+
+	s.env.OnActivity(
+		clusterworkflow.DrainNodeActivityName,
+		mock.Anything,
+		clusterworkflow.DrainNodeActivityInput{ClusterID: 2, NodeName: "worker-0"},
+	).Return(nil)
+	s.onUpgradeNode("worker-0", false, false)
+	s.env.OnActivity(
+		clusterworkflow.CordonNodesActivityName,
+		mock.Anything,
+		clusterworkflow.CordonNodesActivityInput{ClusterID: 2, NodeNames: []string{"worker-0"}, Uncordon: true},
+	).Return(nil).Once()
+
+	s.onSetClusterStatus(cluster.Running)
+
+	s.env.ExecuteWorkflow(s.T().Name(), upgradeClusterWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) Test_ControlPlaneUpgraded() {
+	s.env.OnActivity(
+		UpgradeClusterPreflightActivityName,
+		mock.Anything,
+		UpgradeClusterPreflightActivityInput{ClusterID: 2, TargetVersion: "1.17.5"},
+	).Return(UpgradeClusterPreflightActivityOutput{
+		ControlPlaneUpgraded: true,
+		MasterNodes:          []string{"master-1"},
+	}, nil)
+	s.onUpgradeNode("master-1", true, false)
+
+	s.env.OnActivity(
+		SaveClusterVersionActivityName,
+		mock.Anything,
+		SaveClusterVersionActivityInput{ClusterID: 2, Version: "1.17.5"},
+	).Return(nil)
+
+	s.onSetClusterStatus(cluster.Running)
+
+	s.env.ExecuteWorkflow(s.T().Name(), upgradeClusterWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) Test_UnhealthyMaster() {
+	s.onPreflight()
+
+	s.env.OnActivity(
+		UpgradeNodeActivityName,
+		mock.Anything,
+		UpgradeNodeActivityInput{ClusterID: 2, NodeName: "master-0", Version: "1.17.5", Apply: true},
+	).Return(nil).Once()
+	s.env.OnActivity(
+		CheckNodeHealthActivityName,
+		mock.Anything,
+		CheckNodeHealthActivityInput{ClusterID: 2, NodeName: "master-0", Version: "1.17.5", Master: true},
+	).Return(cadence.NewCustomError(ErrReasonClusterUnhealthy, "kube-apiserver is not ready")).Once()
+
+	s.onSetClusterStatus(cluster.Warning)
+
+	s.env.ExecuteWorkflow(s.T().Name(), upgradeClusterWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+
+	var customErr *cadence.CustomError
+	s.Require().True(errors.As(err, &customErr))
+	s.Equal(ErrReasonClusterUnhealthy, customErr.Reason())
+}
+
+func (s *UpgradeClusterWorkflowTestSuite) Test_DrainFailure() {
+	s.onPreflight()
+	s.onUpgradeNode("master-0", true, true)
+	s.onUpgradeNode("master-1", true, false)
+
+	s.env.OnActivity(
+		clusterworkflow.DrainNodeActivityName,
+		mock.Anything,
+		clusterworkflow.DrainNodeActivityInput{ClusterID: 2, NodeName: "worker-0"},
+	).Return(cadence.NewCustomError(clusterworkflow.ErrReasonNodeDrainTimeout, "timeout"))
+	s.env.OnActivity(
+		clusterworkflow.CordonNodesActivityName,
+		mock.Anything,
+		clusterworkflow.CordonNodesActivityInput{ClusterID: 2, NodeNames: []string{"worker-0"}, Uncordon: true},
+	).Return(nil).Once()
+
+	s.onSetClusterStatus(cluster.Warning)
+
+	s.env.ExecuteWorkflow(s.T().Name(), upgradeClusterWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	// the version is not saved, so the upgrade can be started again
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+
+	var customErr *cadence.CustomError
+	s.Require().True(errors.As(err, &customErr))
+	s.Equal(clusterworkflow.ErrReasonNodeDrainTimeout, customErr.Reason())
+}
+
+// Test_Resume continues an upgrade that failed after the masters were upgraded.
+func (s *UpgradeClusterWorkflowTestSuite) Test_Resume() {
+	s.env.OnActivity(
+		UpgradeClusterPreflightActivityName,
+		mock.Anything,
+		UpgradeClusterPreflightActivityInput{ClusterID: 2, TargetVersion: "1.17.5"},
+	).Return(UpgradeClusterPreflightActivityOutput{
+		ControlPlaneUpgraded: true,
+		WorkerNodes:          []string{"worker-0"},
+	}, nil)
+
+	s.env.OnActivity(
+		clusterworkflow.DrainNodeActivityName,
+		mock.Anything,
+		clusterworkflow.DrainNodeActivityInput{ClusterID: 2, NodeName: "worker-0"},
+	).Return(nil)
+	s.onUpgradeNode("worker-0", false, false)
+	s.env.OnActivity(
+		clusterworkflow.CordonNodesActivityName,
+		mock.Anything,
+		clusterworkflow.CordonNodesActivityInput{ClusterID: 2, NodeNames: []string{"worker-0"}, Uncordon: true},
+	).Return(nil).Once()
+
+	s.env.OnActivity(
+		SaveClusterVersionActivityName,
+		mock.Anything,
+		SaveClusterVersionActivityInput{ClusterID: 2, Version: "1.17.5"},
+	).Return(nil).Once()
+
+	s.onSetClusterStatus(cluster.Running)
+
+	s.env.ExecuteWorkflow(s.T().Name(), upgradeClusterWorkflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
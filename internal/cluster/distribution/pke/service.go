@@ -0,0 +1,111 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+// Service provides an interface to PKE clusters.
+type Service interface {
+	// UpgradeCluster upgrades the Kubernetes version of a cluster.
+	UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error)
+}
+
+// NewService returns a new Service instance
+func NewService(
+	genericClusters cluster.Store,
+	clusters ClusterStore,
+	clusterManager ClusterManager,
+) Service {
+	return service{
+		genericClusters: genericClusters,
+		clusters:        clusters,
+		clusterManager:  clusterManager,
+	}
+}
+
+type service struct {
+	genericClusters cluster.Store
+	clusters        ClusterStore
+	clusterManager  ClusterManager
+}
+
+// ClusterStore provides an interface for PKE cluster persistence.
+type ClusterStore interface {
+	// GetClusterVersion returns the Kubernetes version of a cluster.
+	GetClusterVersion(ctx context.Context, clusterID uint) (string, error)
+
+	// SetClusterVersion saves the Kubernetes version of a cluster.
+	// Nodes joining the cluster afterwards are installed with this version.
+	SetClusterVersion(ctx context.Context, clusterID uint, version string) error
+}
+
+// ClusterManager is responsible for managing clusters.
+type ClusterManager interface {
+	// UpgradeCluster upgrades the Kubernetes version of a cluster.
+	UpgradeCluster(ctx context.Context, c cluster.Cluster, upgrade ClusterUpgrade) (string, error)
+}
+
+// IsUpgradeSupported tells whether Kubernetes version upgrades are supported for PKE clusters on a cloud.
+func IsUpgradeSupported(cloud string) bool {
+	switch cloud {
+	case pkgCluster.Amazon, pkgCluster.Azure, pkgCluster.Vsphere:
+		return true
+	}
+
+	return false
+}
+
+func (s service) UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error) {
+	c, err := s.genericClusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	if !IsUpgradeSupported(c.Cloud) {
+		return "", errors.WithStack(cluster.NotSupportedDistributionError{
+			ID:           c.ID,
+			Cloud:        c.Cloud,
+			Distribution: c.Distribution,
+
+			Message: "Kubernetes version upgrades are not supported for PKE clusters on this cloud",
+		})
+	}
+
+	currentVersion, err := s.clusters.GetClusterVersion(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ValidateVersionUpgrade(currentVersion, version); err != nil {
+		return "", err
+	}
+
+	err = s.genericClusters.SetStatus(ctx, clusterID, cluster.Updating, "upgrading cluster")
+	if err != nil {
+		return "", err
+	}
+
+	return s.clusterManager.UpgradeCluster(ctx, c, ClusterUpgrade{
+		CurrentVersion: currentVersion,
+		TargetVersion:  version,
+	})
+}
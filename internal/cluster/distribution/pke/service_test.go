@@ -0,0 +1,123 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+type inMemoryGenericClusterStore struct {
+	cluster.Store
+
+	clusters map[uint]cluster.Cluster
+}
+
+func (s *inMemoryGenericClusterStore) GetCluster(_ context.Context, id uint) (cluster.Cluster, error) {
+	c, ok := s.clusters[id]
+	if !ok {
+		return cluster.Cluster{}, errors.New("cluster not found")
+	}
+
+	return c, nil
+}
+
+func (s *inMemoryGenericClusterStore) SetStatus(_ context.Context, id uint, status string, statusMessage string) error {
+	c := s.clusters[id]
+	c.Status = status
+	c.StatusMessage = statusMessage
+	s.clusters[id] = c
+
+	return nil
+}
+
+type inMemoryClusterStore struct {
+	versions map[uint]string
+}
+
+func (s *inMemoryClusterStore) GetClusterVersion(_ context.Context, clusterID uint) (string, error) {
+	return s.versions[clusterID], nil
+}
+
+func (s *inMemoryClusterStore) SetClusterVersion(_ context.Context, clusterID uint, version string) error {
+	s.versions[clusterID] = version
+
+	return nil
+}
+
+type recordingClusterManager struct {
+	upgrades []ClusterUpgrade
+}
+
+func (m *recordingClusterManager) UpgradeCluster(_ context.Context, _ cluster.Cluster, upgrade ClusterUpgrade) (string, error) {
+	m.upgrades = append(m.upgrades, upgrade)
+
+	return "process-id", nil
+}
+
+func TestService_UpgradeCluster(t *testing.T) {
+	newService := func(cloud string) (Service, *inMemoryGenericClusterStore, *recordingClusterManager) {
+		genericClusters := &inMemoryGenericClusterStore{
+			clusters: map[uint]cluster.Cluster{
+				1: {ID: 1, Name: "cluster", Cloud: cloud, Distribution: "pke", Status: cluster.Running},
+			},
+		}
+		clusterManager := &recordingClusterManager{}
+
+		return NewService(
+			genericClusters,
+			&inMemoryClusterStore{versions: map[uint]string{1: "1.16.9"}},
+			clusterManager,
+		), genericClusters, clusterManager
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		service, genericClusters, clusterManager := newService("azure")
+
+		processID, err := service.UpgradeCluster(context.Background(), 1, "1.17.5")
+		require.NoError(t, err)
+
+		assert.Equal(t, "process-id", processID)
+		assert.Equal(t, []ClusterUpgrade{{CurrentVersion: "1.16.9", TargetVersion: "1.17.5"}}, clusterManager.upgrades)
+		assert.Equal(t, cluster.Updating, genericClusters.clusters[1].Status)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		service, genericClusters, clusterManager := newService("vsphere")
+
+		_, err := service.UpgradeCluster(context.Background(), 1, "1.18.2")
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &cluster.ValidationError{}))
+		assert.Empty(t, clusterManager.upgrades)
+		assert.Equal(t, cluster.Running, genericClusters.clusters[1].Status)
+	})
+
+	t.Run("CloudNotSupported", func(t *testing.T) {
+		service, _, clusterManager := newService("hosts")
+
+		_, err := service.UpgradeCluster(context.Background(), 1, "1.17.5")
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &cluster.NotSupportedDistributionError{}))
+		assert.Empty(t, clusterManager.upgrades)
+	})
+}
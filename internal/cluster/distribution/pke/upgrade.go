@@ -0,0 +1,112 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+// UpgradeConfig contains the configuration of in-place cluster upgrades.
+type UpgradeConfig struct {
+	// Image of the privileged pods upgrading the nodes (it must contain chroot)
+	Image string
+
+	// Maximum time spent upgrading a single node
+	NodeTimeout time.Duration
+
+	// Maximum time waiting for an upgraded node (and the cluster) to become healthy
+	HealthCheckTimeout time.Duration
+}
+
+// Validate validates the configuration.
+func (c UpgradeConfig) Validate() error {
+	var err error
+
+	if c.Image == "" {
+		err = errors.Append(err, errors.New("PKE upgrade image is required"))
+	}
+
+	if c.NodeTimeout <= 0 {
+		err = errors.Append(err, errors.New("PKE upgrade node timeout must be positive"))
+	}
+
+	if c.HealthCheckTimeout <= 0 {
+		err = errors.Append(err, errors.New("PKE upgrade health check timeout must be positive"))
+	}
+
+	return err
+}
+
+// ClusterUpgrade describes a Kubernetes version upgrade of a PKE cluster.
+type ClusterUpgrade struct {
+	// CurrentVersion is the Kubernetes version the cluster is running.
+	CurrentVersion string
+
+	// TargetVersion is the Kubernetes version the cluster is upgraded to.
+	TargetVersion string
+}
+
+// ValidateVersionUpgrade validates that a cluster (or a node) can be upgraded from one Kubernetes version to another.
+//
+// kubeadm does not support downgrades or skipping minor versions,
+// so the target version must be newer, but at most one minor version ahead of the current one.
+func ValidateVersionUpgrade(currentVersion string, targetVersion string) error {
+	var violations []string
+
+	current, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("invalid current version %q", currentVersion))
+	}
+
+	target, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("invalid target version %q", targetVersion))
+	}
+
+	if len(violations) == 0 {
+		switch {
+		case target.Major() != current.Major():
+			violations = append(violations, "major version upgrades are not supported")
+
+		case !target.GreaterThan(current):
+			violations = append(violations, fmt.Sprintf("target version %q must be newer than the current version %q", targetVersion, currentVersion))
+
+		case target.Minor() > current.Minor()+1:
+			violations = append(violations, fmt.Sprintf(
+				"target version %q skips a minor version: upgrade to %d.%d first",
+				targetVersion, current.Major(), current.Minor()+1,
+			))
+		}
+	}
+
+	if len(violations) > 0 {
+		return cluster.NewValidationError("invalid cluster upgrade request", violations)
+	}
+
+	return nil
+}
+
+// IsVersion tells whether a Kubernetes version (optionally prefixed with "v", as reported by Kubernetes components)
+// is the same as the other one.
+func IsVersion(version string, other string) bool {
+	return strings.TrimPrefix(version, "v") == strings.TrimPrefix(other, "v")
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+func TestValidateVersionUpgrade(t *testing.T) {
+	tests := []struct {
+		current string
+		target  string
+		valid   bool
+	}{
+		{current: "1.16.9", target: "1.16.10", valid: true},
+		{current: "1.16.9", target: "1.17.5", valid: true},
+		{current: "1.16", target: "1.17", valid: true},
+		{current: "1.17.5", target: "1.17.5", valid: false},
+		{current: "1.17.5", target: "1.16.9", valid: false},
+		{current: "1.16.9", target: "1.18.2", valid: false},
+		{current: "1.17.5", target: "2.0.0", valid: false},
+		{current: "1.17.5", target: "invalid", valid: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.current+"-"+test.target, func(t *testing.T) {
+			err := ValidateVersionUpgrade(test.current, test.target)
+
+			if test.valid {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.As(err, &cluster.ValidationError{}))
+		})
+	}
+}
+
+func TestIsVersion(t *testing.T) {
+	assert.True(t, IsVersion("v1.17.5", "1.17.5"))
+	assert.True(t, IsVersion("1.17.5", "1.17.5"))
+	assert.False(t, IsVersion("v1.17.4", "1.17.5"))
+}
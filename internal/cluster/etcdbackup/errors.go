@@ -0,0 +1,166 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackup
+
+// ValidationError is returned when a request is semantically invalid.
+type ValidationError struct {
+	message    string
+	violations []string
+}
+
+// NewValidationError returns a new ValidationError.
+func NewValidationError(message string, violations []string) ValidationError {
+	return ValidationError{
+		message:    message,
+		violations: violations,
+	}
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return "invalid request"
+}
+
+// Violations returns details of the failed validation.
+func (e ValidationError) Violations() []string {
+	return e.violations[:]
+}
+
+// Validation tells a client that this error is related to a semantic validation of the request.
+// Can be used to translate the error to status codes for example.
+func (ValidationError) Validation() bool {
+	return true
+}
+
+// ServiceError tells the consumer whether this error is caused by invalid input supplied by the client.
+// Client errors are usually returned to the consumer without retrying the operation.
+func (ValidationError) ServiceError() bool {
+	return true
+}
+
+// SettingsNotFoundError is returned when etcd backups are not configured for a cluster.
+type SettingsNotFoundError struct {
+	ClusterID uint
+}
+
+// Error implements the error interface.
+func (SettingsNotFoundError) Error() string {
+	return "etcd backup settings not found"
+}
+
+// Details returns error details.
+func (e SettingsNotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (SettingsNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (SettingsNotFoundError) ServiceError() bool {
+	return true
+}
+
+// SnapshotNotFoundError is returned when an etcd snapshot cannot be found.
+type SnapshotNotFoundError struct {
+	ClusterID  uint
+	SnapshotID uint
+}
+
+// Error implements the error interface.
+func (SnapshotNotFoundError) Error() string {
+	return "etcd snapshot not found"
+}
+
+// Details returns error details.
+func (e SnapshotNotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID, "snapshotId", e.SnapshotID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (SnapshotNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (SnapshotNotFoundError) ServiceError() bool {
+	return true
+}
+
+// RestoreNotFoundError is returned when an etcd restore cannot be found.
+type RestoreNotFoundError struct {
+	ClusterID uint
+	RestoreID uint
+}
+
+// Error implements the error interface.
+func (RestoreNotFoundError) Error() string {
+	return "etcd restore not found"
+}
+
+// Details returns error details.
+func (e RestoreNotFoundError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID, "restoreId", e.RestoreID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (RestoreNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (RestoreNotFoundError) ServiceError() bool {
+	return true
+}
+
+// OperationInProgressError is returned when a snapshot or a restore is already running for a cluster.
+type OperationInProgressError struct {
+	ClusterID uint
+	Operation string
+}
+
+// Error implements the error interface.
+func (e OperationInProgressError) Error() string {
+	return "etcd " + e.Operation + " is already in progress"
+}
+
+// Details returns error details.
+func (e OperationInProgressError) Details() []interface{} {
+	return []interface{}{"clusterId", e.ClusterID}
+}
+
+// Conflict tells the consumer that this error is related to a conflicting request.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (OperationInProgressError) Conflict() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (OperationInProgressError) ServiceError() bool {
+	return true
+}
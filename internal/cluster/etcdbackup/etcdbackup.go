@@ -0,0 +1,562 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/objectstore"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+)
+
+// Config contains the configuration of etcd backups.
+type Config struct {
+	// Enabled turns the snapshot scheduler on.
+	Enabled bool
+
+	// Schedule is the cron schedule of checking which clusters are due for a snapshot.
+	Schedule string
+
+	// RestoreImage is the image of the pod restoring a snapshot on the master node.
+	// It must contain sh and etcdctl.
+	RestoreImage string
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	var err error
+
+	if c.Enabled && c.Schedule == "" {
+		err = errors.Append(err, errors.New("etcd backup schedule is required"))
+	}
+
+	if c.RestoreImage == "" {
+		err = errors.Append(err, errors.New("etcd backup restore image is required"))
+	}
+
+	return err
+}
+
+// MinInterval is the shortest interval scheduled snapshots can be taken with.
+const MinInterval = 15 * time.Minute
+
+// Bucket is an object store bucket of the organization snapshots are stored in.
+type Bucket struct {
+	Cloud    string `json:"cloud"`
+	Name     string `json:"name"`
+	SecretID string `json:"secretId"`
+
+	// Location is the region of the bucket.
+	Location string `json:"location,omitempty"`
+
+	// Azure specific parameters
+	ResourceGroup  string `json:"resourceGroup,omitempty"`
+	StorageAccount string `json:"storageAccount,omitempty"`
+}
+
+// Settings contain the etcd backup settings of a cluster.
+type Settings struct {
+	ClusterID uint
+
+	// Enabled turns scheduled snapshots on.
+	Enabled bool
+
+	Bucket Bucket
+
+	// Interval is the time between scheduled snapshots.
+	Interval time.Duration
+
+	// RetentionCount is the number of snapshots kept in the bucket (unlimited when zero).
+	RetentionCount int
+
+	// RetentionPeriod is the maximum age of snapshots kept in the bucket (unlimited when zero).
+	RetentionPeriod time.Duration
+
+	UpdatedAt time.Time
+}
+
+// Validate validates the settings.
+func (s Settings) Validate() error {
+	var violations []string
+
+	if err := providers.ValidateProvider(s.Bucket.Cloud); err != nil {
+		violations = append(violations, fmt.Sprintf("bucket cloud %q is not supported", s.Bucket.Cloud))
+	}
+
+	if s.Bucket.Name == "" {
+		violations = append(violations, "bucket name is required")
+	}
+
+	if s.Bucket.SecretID == "" {
+		violations = append(violations, "bucket secret ID is required")
+	}
+
+	switch s.Bucket.Cloud {
+	case providers.Amazon, providers.Alibaba, providers.Oracle:
+		if s.Bucket.Location == "" {
+			violations = append(violations, "bucket location is required")
+		}
+	case providers.Azure:
+		if s.Bucket.ResourceGroup == "" || s.Bucket.StorageAccount == "" {
+			violations = append(violations, "resource group and storage account are required for azure buckets")
+		}
+	}
+
+	if s.Interval < MinInterval {
+		violations = append(violations, fmt.Sprintf("interval cannot be less than %s", MinInterval))
+	}
+
+	if s.RetentionCount < 0 {
+		violations = append(violations, "retention count cannot be negative")
+	}
+
+	if s.RetentionPeriod < 0 {
+		violations = append(violations, "retention period cannot be negative")
+	}
+
+	if len(violations) > 0 {
+		return NewValidationError("invalid etcd backup settings", violations)
+	}
+
+	return nil
+}
+
+// Snapshot statuses.
+const (
+	SnapshotCreating = "CREATING"
+	SnapshotReady    = "READY"
+	SnapshotFailed   = "FAILED"
+
+	// SnapshotDeleted means that the snapshot was removed from the bucket by the retention policy.
+	SnapshotDeleted = "DELETED"
+)
+
+// Snapshot triggers.
+const (
+	TriggerScheduled = "SCHEDULED"
+	TriggerManual    = "MANUAL"
+)
+
+// Snapshot is an etcd snapshot of a cluster.
+type Snapshot struct {
+	ID            uint       `json:"id"`
+	ClusterID     uint       `json:"clusterId"`
+	Status        string     `json:"status"`
+	StatusMessage string     `json:"statusMessage,omitempty"`
+	Trigger       string     `json:"trigger"`
+	Bucket        Bucket     `json:"bucket"`
+	ObjectKey     string     `json:"objectKey,omitempty"`
+	Size          int64      `json:"size"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+// Restore statuses.
+const (
+	RestoreRunning   = "RUNNING"
+	RestoreSucceeded = "SUCCEEDED"
+	RestoreFailed    = "FAILED"
+)
+
+// Restore is the restoration of a cluster control plane from an etcd snapshot.
+type Restore struct {
+	ID            uint       `json:"id"`
+	ClusterID     uint       `json:"clusterId"`
+	SnapshotID    uint       `json:"snapshotId"`
+	Status        string     `json:"status"`
+	StatusMessage string     `json:"statusMessage,omitempty"`
+	StartedAt     time.Time  `json:"startedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service manages the etcd backups of PKE clusters.
+type Service interface {
+	// GetSettings returns the etcd backup settings of a cluster.
+	GetSettings(ctx context.Context, clusterID uint) (settings Settings, err error)
+
+	// UpdateSettings creates or replaces the etcd backup settings of a cluster.
+	UpdateSettings(ctx context.Context, clusterID uint, settings Settings) (updated Settings, err error)
+
+	// DeleteSettings turns etcd backups off for a cluster.
+	// Snapshots already in the bucket are kept.
+	DeleteSettings(ctx context.Context, clusterID uint) error
+
+	// ListSnapshots lists the snapshot history of a cluster (newest first).
+	ListSnapshots(ctx context.Context, clusterID uint) (snapshots []Snapshot, err error)
+
+	// GetSnapshot returns a snapshot of a cluster.
+	GetSnapshot(ctx context.Context, clusterID uint, snapshotID uint) (snapshot Snapshot, err error)
+
+	// CreateSnapshot starts taking a snapshot of a cluster.
+	CreateSnapshot(ctx context.Context, clusterID uint) (snapshot Snapshot, err error)
+
+	// RestoreSnapshot starts rebuilding the control plane of a cluster from a snapshot.
+	RestoreSnapshot(ctx context.Context, clusterID uint, snapshotID uint) (restore Restore, err error)
+
+	// ListRestores lists the restore history of a cluster (newest first).
+	ListRestores(ctx context.Context, clusterID uint) (restores []Restore, err error)
+}
+
+// +testify:mock:testOnly=true
+
+// Store persists etcd backup settings and history.
+type Store interface {
+	// GetSettings returns the etcd backup settings of a cluster.
+	// Returns a SettingsNotFoundError when backups are not configured for the cluster.
+	GetSettings(ctx context.Context, clusterID uint) (Settings, error)
+
+	// ListEnabledSettings lists the settings of every cluster with scheduled snapshots.
+	ListEnabledSettings(ctx context.Context) ([]Settings, error)
+
+	// PutSettings creates or replaces the etcd backup settings of a cluster.
+	PutSettings(ctx context.Context, settings Settings) error
+
+	// DeleteSettings deletes the etcd backup settings of a cluster.
+	DeleteSettings(ctx context.Context, clusterID uint) error
+
+	// CreateSnapshot records a new snapshot and returns its ID.
+	CreateSnapshot(ctx context.Context, snapshot Snapshot) (uint, error)
+
+	// GetSnapshot returns a snapshot of a cluster.
+	// Returns a SnapshotNotFoundError when the snapshot cannot be found.
+	GetSnapshot(ctx context.Context, clusterID uint, id uint) (Snapshot, error)
+
+	// ListSnapshots lists the snapshots of a cluster (newest first).
+	ListSnapshots(ctx context.Context, clusterID uint) ([]Snapshot, error)
+
+	// UpdateSnapshot updates the status, the object and the size of a snapshot.
+	UpdateSnapshot(ctx context.Context, snapshot Snapshot) error
+
+	// CreateRestore records a new restore and returns its ID.
+	CreateRestore(ctx context.Context, restore Restore) (uint, error)
+
+	// GetRestore returns a restore of a cluster.
+	// Returns a RestoreNotFoundError when the restore cannot be found.
+	GetRestore(ctx context.Context, clusterID uint, id uint) (Restore, error)
+
+	// ListRestores lists the restores of a cluster (newest first).
+	ListRestores(ctx context.Context, clusterID uint) ([]Restore, error)
+
+	// UpdateRestore updates the status of a restore.
+	UpdateRestore(ctx context.Context, restore Restore) error
+}
+
+// ClusterStore provides access to clusters.
+type ClusterStore interface {
+	// GetCluster returns a generic representation of a cluster.
+	GetCluster(ctx context.Context, id uint) (cluster.Cluster, error)
+
+	// SetStatus sets the cluster status.
+	SetStatus(ctx context.Context, id uint, status string, statusMessage string) error
+}
+
+// ObjectStoreFactory returns object stores of organization buckets.
+type ObjectStoreFactory interface {
+	// NewObjectStore returns an object store for accessing a bucket with the credentials of its secret.
+	NewObjectStore(ctx context.Context, organizationID uint, bucket Bucket) (objectstore.ObjectStore, error)
+}
+
+// +testify:mock:testOnly=true
+
+// Starter starts snapshots and restores in the background.
+type Starter interface {
+	// StartSnapshot starts taking a snapshot that is already recorded.
+	StartSnapshot(ctx context.Context, clusterID uint, snapshotID uint) error
+
+	// StartRestore starts a restore that is already recorded.
+	StartRestore(ctx context.Context, clusterID uint, restoreID uint) error
+}
+
+// NewService returns a new Service.
+func NewService(
+	store Store,
+	clusters ClusterStore,
+	secrets secret.Store,
+	objectStores ObjectStoreFactory,
+	starter Starter,
+	logger Logger,
+) Service {
+	return service{
+		store:        store,
+		clusters:     clusters,
+		secrets:      secrets,
+		objectStores: objectStores,
+		starter:      starter,
+
+		logger: logger,
+	}
+}
+
+type service struct {
+	store        Store
+	clusters     ClusterStore
+	secrets      secret.Store
+	objectStores ObjectStoreFactory
+	starter      Starter
+
+	logger Logger
+}
+
+func (s service) GetSettings(ctx context.Context, clusterID uint) (Settings, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return Settings{}, err
+	}
+
+	return s.store.GetSettings(ctx, clusterID)
+}
+
+func (s service) UpdateSettings(ctx context.Context, clusterID uint, settings Settings) (Settings, error) {
+	c, err := s.getCluster(ctx, clusterID)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	settings.ClusterID = clusterID
+
+	if err := settings.Validate(); err != nil {
+		return Settings{}, err
+	}
+
+	model, err := s.secrets.Get(ctx, c.OrganizationID, settings.Bucket.SecretID)
+	if errors.As(err, &secret.NotFoundError{}) {
+		return Settings{}, NewValidationError(
+			"invalid etcd backup settings",
+			[]string{fmt.Sprintf("bucket secret %s cannot be found", settings.Bucket.SecretID)},
+		)
+	} else if err != nil {
+		return Settings{}, err
+	}
+
+	if model.Type != settings.Bucket.Cloud {
+		return Settings{}, NewValidationError(
+			"invalid etcd backup settings",
+			[]string{fmt.Sprintf("bucket secret must be of type %s", settings.Bucket.Cloud)},
+		)
+	}
+
+	store, err := s.objectStores.NewObjectStore(ctx, c.OrganizationID, settings.Bucket)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	if err := store.CheckBucket(settings.Bucket.Name); err != nil {
+		return Settings{}, NewValidationError(
+			"invalid etcd backup settings",
+			[]string{fmt.Sprintf("bucket %s cannot be accessed: %s", settings.Bucket.Name, err.Error())},
+		)
+	}
+
+	if err := s.store.PutSettings(ctx, settings); err != nil {
+		return Settings{}, err
+	}
+
+	return s.store.GetSettings(ctx, clusterID)
+}
+
+func (s service) DeleteSettings(ctx context.Context, clusterID uint) error {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return err
+	}
+
+	return s.store.DeleteSettings(ctx, clusterID)
+}
+
+func (s service) ListSnapshots(ctx context.Context, clusterID uint) ([]Snapshot, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListSnapshots(ctx, clusterID)
+}
+
+func (s service) GetSnapshot(ctx context.Context, clusterID uint, snapshotID uint) (Snapshot, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return Snapshot{}, err
+	}
+
+	return s.store.GetSnapshot(ctx, clusterID, snapshotID)
+}
+
+func (s service) CreateSnapshot(ctx context.Context, clusterID uint) (Snapshot, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return Snapshot{}, err
+	}
+
+	settings, err := s.store.GetSettings(ctx, clusterID)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := checkIdle(ctx, s.store, clusterID); err != nil {
+		return Snapshot{}, err
+	}
+
+	id, err := s.store.CreateSnapshot(ctx, Snapshot{
+		ClusterID: clusterID,
+		Status:    SnapshotCreating,
+		Trigger:   TriggerManual,
+		Bucket:    settings.Bucket,
+	})
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot, err := s.store.GetSnapshot(ctx, clusterID, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := s.starter.StartSnapshot(ctx, clusterID, id); err != nil {
+		snapshot.Status = SnapshotFailed
+		snapshot.StatusMessage = "failed to start taking the snapshot"
+
+		if err := s.store.UpdateSnapshot(ctx, snapshot); err != nil {
+			s.logger.Warn("failed to update etcd snapshot status", map[string]interface{}{
+				"clusterId":  clusterID,
+				"snapshotId": id,
+				"error":      err.Error(),
+			})
+		}
+
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+func (s service) RestoreSnapshot(ctx context.Context, clusterID uint, snapshotID uint) (Restore, error) {
+	c, err := s.getCluster(ctx, clusterID)
+	if err != nil {
+		return Restore{}, err
+	}
+
+	if c.Status != cluster.Running && c.Status != cluster.Warning {
+		return Restore{}, NewValidationError(
+			"cannot restore etcd snapshot",
+			[]string{fmt.Sprintf("cluster must be running, current status is %s", c.Status)},
+		)
+	}
+
+	snapshot, err := s.store.GetSnapshot(ctx, clusterID, snapshotID)
+	if err != nil {
+		return Restore{}, err
+	}
+
+	if snapshot.Status != SnapshotReady {
+		return Restore{}, NewValidationError(
+			"cannot restore etcd snapshot",
+			[]string{fmt.Sprintf("snapshot must be %s, current status is %s", SnapshotReady, snapshot.Status)},
+		)
+	}
+
+	if err := checkIdle(ctx, s.store, clusterID); err != nil {
+		return Restore{}, err
+	}
+
+	id, err := s.store.CreateRestore(ctx, Restore{
+		ClusterID:  clusterID,
+		SnapshotID: snapshotID,
+		Status:     RestoreRunning,
+	})
+	if err != nil {
+		return Restore{}, err
+	}
+
+	restore, err := s.store.GetRestore(ctx, clusterID, id)
+	if err != nil {
+		return Restore{}, err
+	}
+
+	if err := s.starter.StartRestore(ctx, clusterID, id); err != nil {
+		now := time.Now()
+		restore.Status = RestoreFailed
+		restore.StatusMessage = "failed to start the restore"
+		restore.FinishedAt = &now
+
+		if err := s.store.UpdateRestore(ctx, restore); err != nil {
+			s.logger.Warn("failed to update etcd restore status", map[string]interface{}{
+				"clusterId": clusterID,
+				"restoreId": id,
+				"error":     err.Error(),
+			})
+		}
+
+		return Restore{}, err
+	}
+
+	return restore, nil
+}
+
+func (s service) ListRestores(ctx context.Context, clusterID uint) ([]Restore, error) {
+	if _, err := s.getCluster(ctx, clusterID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListRestores(ctx, clusterID)
+}
+
+// getCluster returns a cluster that supports etcd backups.
+func (s service) getCluster(ctx context.Context, clusterID uint) (cluster.Cluster, error) {
+	c, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return cluster.Cluster{}, err
+	}
+
+	if c.Distribution != pkgCluster.PKE {
+		return cluster.Cluster{}, NewValidationError(
+			"etcd backups are not supported",
+			[]string{"etcd backups are only supported for PKE clusters"},
+		)
+	}
+
+	return c, nil
+}
+
+// checkIdle returns an OperationInProgressError if a snapshot or a restore is running for a cluster.
+func checkIdle(ctx context.Context, store Store, clusterID uint) error {
+	snapshots, err := store.ListSnapshots(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Status == SnapshotCreating {
+			return errors.WithStack(OperationInProgressError{ClusterID: clusterID, Operation: "snapshot"})
+		}
+	}
+
+	restores, err := store.ListRestores(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	for _, restore := range restores {
+		if restore.Status == RestoreRunning {
+			return errors.WithStack(OperationInProgressError{ClusterID: clusterID, Operation: "restore"})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	internaltesting "github.com/banzaicloud/pipeline/internal/testing"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/objectstore"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+)
+
+type inMemoryClusterStore struct {
+	clusters map[uint]cluster.Cluster
+}
+
+func newInMemoryClusterStore(clusters ...cluster.Cluster) *inMemoryClusterStore {
+	store := &inMemoryClusterStore{clusters: make(map[uint]cluster.Cluster)}
+
+	for _, c := range clusters {
+		store.clusters[c.ID] = c
+	}
+
+	return store
+}
+
+func (s *inMemoryClusterStore) GetCluster(_ context.Context, id uint) (cluster.Cluster, error) {
+	c, ok := s.clusters[id]
+	if !ok {
+		return cluster.Cluster{}, cluster.NotFoundError{ClusterID: id}
+	}
+
+	return c, nil
+}
+
+func (s *inMemoryClusterStore) SetStatus(_ context.Context, id uint, status string, statusMessage string) error {
+	c := s.clusters[id]
+	c.Status = status
+	c.StatusMessage = statusMessage
+	s.clusters[id] = c
+
+	return nil
+}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string  { return "object not found" }
+func (notFoundError) NotFound() bool { return true }
+
+// inMemoryObjectStore implements the parts of objectstore.ObjectStore used by etcd backups.
+type inMemoryObjectStore struct {
+	objectstore.ObjectStore
+
+	objects map[string][]byte
+}
+
+func newInMemoryObjectStore() *inMemoryObjectStore {
+	return &inMemoryObjectStore{objects: make(map[string][]byte)}
+}
+
+func (s *inMemoryObjectStore) CheckBucket(_ string) error {
+	return nil
+}
+
+func (s *inMemoryObjectStore) GetObject(_ string, key string) (io.ReadCloser, error) {
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, notFoundError{}
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(object)), nil
+}
+
+func (s *inMemoryObjectStore) PutObject(_ string, key string, body io.Reader) error {
+	object, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.objects[key] = object
+
+	return nil
+}
+
+func (s *inMemoryObjectStore) DeleteObject(_ string, key string) error {
+	if _, ok := s.objects[key]; !ok {
+		return notFoundError{}
+	}
+
+	delete(s.objects, key)
+
+	return nil
+}
+
+type objectStoreFactory struct {
+	store objectstore.ObjectStore
+}
+
+func (f objectStoreFactory) NewObjectStore(_ context.Context, _ uint, _ Bucket) (objectstore.ObjectStore, error) {
+	return f.store, nil
+}
+
+func validSettings() Settings {
+	return Settings{
+		Enabled: true,
+		Bucket: Bucket{
+			Cloud:    providers.Amazon,
+			Name:     "backups",
+			SecretID: "secret",
+			Location: "eu-west-1",
+		},
+		Interval:       24 * time.Hour,
+		RetentionCount: 7,
+	}
+}
+
+func TestSettings_Validate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, validSettings().Validate())
+	})
+
+	tests := map[string]struct {
+		modify     func(s *Settings)
+		violations []string
+	}{
+		"UnknownCloud": {
+			modify:     func(s *Settings) { s.Bucket.Cloud = "unknown" },
+			violations: []string{`bucket cloud "unknown" is not supported`},
+		},
+		"MissingBucket": {
+			modify:     func(s *Settings) { s.Bucket.Name = ""; s.Bucket.SecretID = "" },
+			violations: []string{"bucket name is required", "bucket secret ID is required"},
+		},
+		"MissingLocation": {
+			modify:     func(s *Settings) { s.Bucket.Location = "" },
+			violations: []string{"bucket location is required"},
+		},
+		"AzureBucket": {
+			modify:     func(s *Settings) { s.Bucket.Cloud = providers.Azure; s.Bucket.StorageAccount = "account" },
+			violations: []string{"resource group and storage account are required for azure buckets"},
+		},
+		"ShortInterval": {
+			modify:     func(s *Settings) { s.Interval = time.Minute },
+			violations: []string{"interval cannot be less than 15m0s"},
+		},
+		"NegativeRetention": {
+			modify:     func(s *Settings) { s.RetentionCount = -1; s.RetentionPeriod = -time.Hour },
+			violations: []string{"retention count cannot be negative", "retention period cannot be negative"},
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			settings := validSettings()
+			test.modify(&settings)
+
+			err := settings.Validate()
+			require.Error(t, err)
+
+			var verr ValidationError
+			require.True(t, errors.As(err, &verr))
+			assert.Equal(t, test.violations, verr.Violations())
+		})
+	}
+}
+
+func TestService_UpdateSettings(t *testing.T) {
+	clusters := newInMemoryClusterStore(cluster.Cluster{ID: 1, OrganizationID: 1, Distribution: pkgCluster.PKE})
+	secrets := internaltesting.NewSecretStore(
+		t, 1,
+		secret.Model{ID: "secret", Type: providers.Amazon},
+		secret.Model{ID: "google", Type: providers.Google},
+	)
+
+	t.Run("Success", func(t *testing.T) {
+		store := new(MockStore)
+		store.On("PutSettings", mock.Anything, mock.MatchedBy(func(s Settings) bool { return s.ClusterID == 1 })).Return(nil)
+		store.On("GetSettings", mock.Anything, uint(1)).Return(Settings{ClusterID: 1}, nil)
+
+		service := NewService(store, clusters, secrets, objectStoreFactory{newInMemoryObjectStore()}, new(MockStarter), NoopLogger{})
+
+		updated, err := service.UpdateSettings(context.Background(), 1, validSettings())
+		require.NoError(t, err)
+
+		assert.Equal(t, uint(1), updated.ClusterID)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("SecretTypeMismatch", func(t *testing.T) {
+		settings := validSettings()
+		settings.Bucket.SecretID = "google"
+
+		service := NewService(new(MockStore), clusters, secrets, objectStoreFactory{newInMemoryObjectStore()}, new(MockStarter), NoopLogger{})
+
+		_, err := service.UpdateSettings(context.Background(), 1, settings)
+		require.Error(t, err)
+
+		var verr ValidationError
+		require.True(t, errors.As(err, &verr))
+		assert.Equal(t, []string{"bucket secret must be of type amazon"}, verr.Violations())
+	})
+}
+
+func TestService_NotPKE(t *testing.T) {
+	clusters := newInMemoryClusterStore(cluster.Cluster{ID: 1, OrganizationID: 1, Distribution: pkgCluster.EKS})
+
+	service := NewService(new(MockStore), clusters, internaltesting.NewSecretStore(t, 1), objectStoreFactory{}, new(MockStarter), NoopLogger{})
+
+	_, err := service.GetSettings(context.Background(), 1)
+	require.Error(t, err)
+
+	assert.True(t, errors.As(err, &ValidationError{}))
+}
+
+func TestService_CreateSnapshot(t *testing.T) {
+	clusters := newInMemoryClusterStore(cluster.Cluster{ID: 1, OrganizationID: 1, Distribution: pkgCluster.PKE})
+
+	t.Run("Success", func(t *testing.T) {
+		settings := validSettings()
+		settings.ClusterID = 1
+
+		store := new(MockStore)
+		store.On("GetSettings", mock.Anything, uint(1)).Return(settings, nil)
+		store.On("ListSnapshots", mock.Anything, uint(1)).Return([]Snapshot{{ID: 1, Status: SnapshotReady}}, nil)
+		store.On("ListRestores", mock.Anything, uint(1)).Return(nil, nil)
+		store.On("CreateSnapshot", mock.Anything, Snapshot{
+			ClusterID: 1,
+			Status:    SnapshotCreating,
+			Trigger:   TriggerManual,
+			Bucket:    settings.Bucket,
+		}).Return(uint(2), nil)
+		store.On("GetSnapshot", mock.Anything, uint(1), uint(2)).Return(Snapshot{ID: 2, ClusterID: 1, Status: SnapshotCreating}, nil)
+
+		starter := new(MockStarter)
+		starter.On("StartSnapshot", mock.Anything, uint(1), uint(2)).Return(nil)
+
+		service := NewService(store, clusters, internaltesting.NewSecretStore(t, 1), objectStoreFactory{}, starter, NoopLogger{})
+
+		snapshot, err := service.CreateSnapshot(context.Background(), 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint(2), snapshot.ID)
+		store.AssertExpectations(t)
+		starter.AssertExpectations(t)
+	})
+
+	t.Run("RestoreInProgress", func(t *testing.T) {
+		store := new(MockStore)
+		store.On("GetSettings", mock.Anything, uint(1)).Return(validSettings(), nil)
+		store.On("ListSnapshots", mock.Anything, uint(1)).Return(nil, nil)
+		store.On("ListRestores", mock.Anything, uint(1)).Return([]Restore{{ID: 1, Status: RestoreRunning}}, nil)
+
+		service := NewService(store, clusters, internaltesting.NewSecretStore(t, 1), objectStoreFactory{}, new(MockStarter), NoopLogger{})
+
+		_, err := service.CreateSnapshot(context.Background(), 1)
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &OperationInProgressError{}))
+	})
+}
+
+func TestService_RestoreSnapshot_NotReady(t *testing.T) {
+	clusters := newInMemoryClusterStore(cluster.Cluster{
+		ID:             1,
+		OrganizationID: 1,
+		Distribution:   pkgCluster.PKE,
+		Status:         cluster.Running,
+	})
+
+	store := new(MockStore)
+	store.On("GetSnapshot", mock.Anything, uint(1), uint(1)).Return(Snapshot{ID: 1, Status: SnapshotFailed}, nil)
+
+	service := NewService(store, clusters, internaltesting.NewSecretStore(t, 1), objectStoreFactory{}, new(MockStarter), NoopLogger{})
+
+	_, err := service.RestoreSnapshot(context.Background(), 1, 1)
+	require.Error(t, err)
+
+	var verr ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, []string{"snapshot must be READY, current status is FAILED"}, verr.Violations())
+}
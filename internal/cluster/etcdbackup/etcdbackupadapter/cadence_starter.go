@@ -0,0 +1,111 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup/etcdbackupworkflow"
+)
+
+// CadenceStarter starts etcd backup workflows.
+type CadenceStarter struct {
+	workflowClient client.Client
+}
+
+// NewCadenceStarter returns a new CadenceStarter.
+func NewCadenceStarter(workflowClient client.Client) CadenceStarter {
+	return CadenceStarter{
+		workflowClient: workflowClient,
+	}
+}
+
+// StartSnapshot implements the etcdbackup.Starter interface.
+func (s CadenceStarter) StartSnapshot(ctx context.Context, clusterID uint, snapshotID uint) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           etcdbackupworkflow.CreateSnapshotWorkflowID(clusterID),
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour,
+		WorkflowIDReusePolicy:        client.WorkflowIDReusePolicyAllowDuplicate,
+	}
+
+	input := etcdbackupworkflow.CreateSnapshotWorkflowInput{
+		ClusterID:  clusterID,
+		SnapshotID: snapshotID,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, etcdbackupworkflow.CreateSnapshotWorkflowName, input)
+	if isAlreadyStartedError(err) {
+		return errors.WithStack(etcdbackup.OperationInProgressError{ClusterID: clusterID, Operation: "snapshot"})
+	} else if err != nil {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", etcdbackupworkflow.CreateSnapshotWorkflowName)
+	}
+
+	return nil
+}
+
+// StartRestore implements the etcdbackup.Starter interface.
+func (s CadenceStarter) StartRestore(ctx context.Context, clusterID uint, restoreID uint) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           etcdbackupworkflow.RestoreSnapshotWorkflowID(clusterID),
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour,
+		WorkflowIDReusePolicy:        client.WorkflowIDReusePolicyAllowDuplicate,
+	}
+
+	input := etcdbackupworkflow.RestoreSnapshotWorkflowInput{
+		ClusterID: clusterID,
+		RestoreID: restoreID,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, etcdbackupworkflow.RestoreSnapshotWorkflowName, input)
+	if isAlreadyStartedError(err) {
+		return errors.WithStack(etcdbackup.OperationInProgressError{ClusterID: clusterID, Operation: "restore"})
+	} else if err != nil {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", etcdbackupworkflow.RestoreSnapshotWorkflowName)
+	}
+
+	return nil
+}
+
+// StartScheduler starts the cron workflow that takes scheduled snapshots.
+// It does nothing if the scheduler is already running.
+func (s CadenceStarter) StartScheduler(ctx context.Context, schedule string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           etcdbackupworkflow.SchedulerWorkflowName,
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 12 * time.Hour,
+		CronSchedule:                 schedule,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, etcdbackupworkflow.SchedulerWorkflowName)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", etcdbackupworkflow.SchedulerWorkflowName)
+	}
+
+	return nil
+}
+
+func isAlreadyStartedError(err error) bool {
+	var alreadyStartedErr *shared.WorkflowExecutionAlreadyStartedError
+
+	return errors.As(err, &alreadyStartedErr)
+}
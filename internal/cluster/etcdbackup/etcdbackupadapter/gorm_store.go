@@ -0,0 +1,349 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+// bucketModel describes the bucket columns of the etcd backup models.
+type bucketModel struct {
+	Cloud          string
+	Name           string
+	SecretID       string
+	Location       string
+	ResourceGroup  string
+	StorageAccount string
+}
+
+// settingsModel describes the etcd backup settings of a cluster.
+type settingsModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ClusterID uint `gorm:"unique_index:idx_etcd_backup_settings_cluster_id"`
+	Enabled   bool
+	Bucket    bucketModel `gorm:"embedded;embedded_prefix:bucket_"`
+
+	// Durations are stored in seconds
+	SnapshotInterval int64
+	RetentionCount   int
+	RetentionPeriod  int64
+}
+
+// TableName changes the default table name.
+func (settingsModel) TableName() string {
+	return "etcd_backup_settings"
+}
+
+// snapshotModel describes an etcd snapshot of a cluster.
+type snapshotModel struct {
+	ID            uint `gorm:"primary_key"`
+	CreatedAt     time.Time
+	ClusterID     uint `gorm:"index:idx_etcd_backup_snapshots_cluster_id"`
+	Status        string
+	StatusMessage string `gorm:"type:text"`
+	Trigger       string
+	Bucket        bucketModel `gorm:"embedded;embedded_prefix:bucket_"`
+	ObjectKey     string
+	Size          int64
+	CompletedAt   *time.Time
+}
+
+// TableName changes the default table name.
+func (snapshotModel) TableName() string {
+	return "etcd_backup_snapshots"
+}
+
+// restoreModel describes the restoration of a cluster from an etcd snapshot.
+type restoreModel struct {
+	ID            uint `gorm:"primary_key"`
+	ClusterID     uint `gorm:"index:idx_etcd_backup_restores_cluster_id"`
+	SnapshotID    uint
+	Status        string
+	StatusMessage string `gorm:"type:text"`
+	StartedAt     time.Time
+	FinishedAt    *time.Time
+}
+
+// TableName changes the default table name.
+func (restoreModel) TableName() string {
+	return "etcd_backup_restores"
+}
+
+// GormStore is an etcd backup store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// GetSettings implements the etcdbackup.Store interface.
+func (s GormStore) GetSettings(ctx context.Context, clusterID uint) (etcdbackup.Settings, error) {
+	var model settingsModel
+
+	err := s.db.Where(settingsModel{ClusterID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return etcdbackup.Settings{}, errors.WithStack(etcdbackup.SettingsNotFoundError{ClusterID: clusterID})
+	} else if err != nil {
+		return etcdbackup.Settings{}, errors.WrapIfWithDetails(err, "failed to get etcd backup settings", "clusterId", clusterID)
+	}
+
+	return toSettings(model), nil
+}
+
+// ListEnabledSettings implements the etcdbackup.Store interface.
+func (s GormStore) ListEnabledSettings(ctx context.Context) ([]etcdbackup.Settings, error) {
+	var models []settingsModel
+
+	if err := s.db.Where("enabled = ?", true).Order("cluster_id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIf(err, "failed to list etcd backup settings")
+	}
+
+	settings := make([]etcdbackup.Settings, 0, len(models))
+	for _, model := range models {
+		settings = append(settings, toSettings(model))
+	}
+
+	return settings, nil
+}
+
+// PutSettings implements the etcdbackup.Store interface.
+func (s GormStore) PutSettings(ctx context.Context, settings etcdbackup.Settings) error {
+	var model settingsModel
+
+	err := s.db.Where(settingsModel{ClusterID: settings.ClusterID}).First(&model).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return errors.WrapIfWithDetails(err, "failed to get etcd backup settings", "clusterId", settings.ClusterID)
+	}
+
+	model.ClusterID = settings.ClusterID
+	model.Enabled = settings.Enabled
+	model.Bucket = fromBucket(settings.Bucket)
+	model.SnapshotInterval = int64(settings.Interval / time.Second)
+	model.RetentionCount = settings.RetentionCount
+	model.RetentionPeriod = int64(settings.RetentionPeriod / time.Second)
+
+	if err := s.db.Save(&model).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to save etcd backup settings", "clusterId", settings.ClusterID)
+	}
+
+	return nil
+}
+
+// DeleteSettings implements the etcdbackup.Store interface.
+func (s GormStore) DeleteSettings(ctx context.Context, clusterID uint) error {
+	if err := s.db.Where(settingsModel{ClusterID: clusterID}).Delete(settingsModel{}).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to delete etcd backup settings", "clusterId", clusterID)
+	}
+
+	return nil
+}
+
+// CreateSnapshot implements the etcdbackup.Store interface.
+func (s GormStore) CreateSnapshot(ctx context.Context, snapshot etcdbackup.Snapshot) (uint, error) {
+	model := snapshotModel{
+		ClusterID:     snapshot.ClusterID,
+		Status:        snapshot.Status,
+		StatusMessage: snapshot.StatusMessage,
+		Trigger:       snapshot.Trigger,
+		Bucket:        fromBucket(snapshot.Bucket),
+	}
+
+	if err := s.db.Create(&model).Error; err != nil {
+		return 0, errors.WrapIfWithDetails(err, "failed to create etcd snapshot", "clusterId", snapshot.ClusterID)
+	}
+
+	return model.ID, nil
+}
+
+// GetSnapshot implements the etcdbackup.Store interface.
+func (s GormStore) GetSnapshot(ctx context.Context, clusterID uint, id uint) (etcdbackup.Snapshot, error) {
+	var model snapshotModel
+
+	err := s.db.Where(snapshotModel{ID: id, ClusterID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return etcdbackup.Snapshot{}, errors.WithStack(etcdbackup.SnapshotNotFoundError{ClusterID: clusterID, SnapshotID: id})
+	} else if err != nil {
+		return etcdbackup.Snapshot{}, errors.WrapIfWithDetails(err, "failed to get etcd snapshot", "clusterId", clusterID, "snapshotId", id)
+	}
+
+	return toSnapshot(model), nil
+}
+
+// ListSnapshots implements the etcdbackup.Store interface.
+func (s GormStore) ListSnapshots(ctx context.Context, clusterID uint) ([]etcdbackup.Snapshot, error) {
+	var models []snapshotModel
+
+	if err := s.db.Where(snapshotModel{ClusterID: clusterID}).Order("id desc").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list etcd snapshots", "clusterId", clusterID)
+	}
+
+	snapshots := make([]etcdbackup.Snapshot, 0, len(models))
+	for _, model := range models {
+		snapshots = append(snapshots, toSnapshot(model))
+	}
+
+	return snapshots, nil
+}
+
+// UpdateSnapshot implements the etcdbackup.Store interface.
+func (s GormStore) UpdateSnapshot(ctx context.Context, snapshot etcdbackup.Snapshot) error {
+	err := s.db.Model(snapshotModel{ID: snapshot.ID}).Where("cluster_id = ?", snapshot.ClusterID).Updates(map[string]interface{}{
+		"status":         snapshot.Status,
+		"status_message": snapshot.StatusMessage,
+		"object_key":     snapshot.ObjectKey,
+		"size":           snapshot.Size,
+		"completed_at":   snapshot.CompletedAt,
+	}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update etcd snapshot", "clusterId", snapshot.ClusterID, "snapshotId", snapshot.ID)
+	}
+
+	return nil
+}
+
+// CreateRestore implements the etcdbackup.Store interface.
+func (s GormStore) CreateRestore(ctx context.Context, restore etcdbackup.Restore) (uint, error) {
+	model := restoreModel{
+		ClusterID:     restore.ClusterID,
+		SnapshotID:    restore.SnapshotID,
+		Status:        restore.Status,
+		StatusMessage: restore.StatusMessage,
+		StartedAt:     time.Now(),
+	}
+
+	if err := s.db.Create(&model).Error; err != nil {
+		return 0, errors.WrapIfWithDetails(err, "failed to create etcd restore", "clusterId", restore.ClusterID)
+	}
+
+	return model.ID, nil
+}
+
+// GetRestore implements the etcdbackup.Store interface.
+func (s GormStore) GetRestore(ctx context.Context, clusterID uint, id uint) (etcdbackup.Restore, error) {
+	var model restoreModel
+
+	err := s.db.Where(restoreModel{ID: id, ClusterID: clusterID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return etcdbackup.Restore{}, errors.WithStack(etcdbackup.RestoreNotFoundError{ClusterID: clusterID, RestoreID: id})
+	} else if err != nil {
+		return etcdbackup.Restore{}, errors.WrapIfWithDetails(err, "failed to get etcd restore", "clusterId", clusterID, "restoreId", id)
+	}
+
+	return toRestore(model), nil
+}
+
+// ListRestores implements the etcdbackup.Store interface.
+func (s GormStore) ListRestores(ctx context.Context, clusterID uint) ([]etcdbackup.Restore, error) {
+	var models []restoreModel
+
+	if err := s.db.Where(restoreModel{ClusterID: clusterID}).Order("id desc").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list etcd restores", "clusterId", clusterID)
+	}
+
+	restores := make([]etcdbackup.Restore, 0, len(models))
+	for _, model := range models {
+		restores = append(restores, toRestore(model))
+	}
+
+	return restores, nil
+}
+
+// UpdateRestore implements the etcdbackup.Store interface.
+func (s GormStore) UpdateRestore(ctx context.Context, restore etcdbackup.Restore) error {
+	err := s.db.Model(restoreModel{ID: restore.ID}).Where("cluster_id = ?", restore.ClusterID).Updates(map[string]interface{}{
+		"status":         restore.Status,
+		"status_message": restore.StatusMessage,
+		"finished_at":    restore.FinishedAt,
+	}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to update etcd restore", "clusterId", restore.ClusterID, "restoreId", restore.ID)
+	}
+
+	return nil
+}
+
+func fromBucket(bucket etcdbackup.Bucket) bucketModel {
+	return bucketModel{
+		Cloud:          bucket.Cloud,
+		Name:           bucket.Name,
+		SecretID:       bucket.SecretID,
+		Location:       bucket.Location,
+		ResourceGroup:  bucket.ResourceGroup,
+		StorageAccount: bucket.StorageAccount,
+	}
+}
+
+func toBucket(model bucketModel) etcdbackup.Bucket {
+	return etcdbackup.Bucket{
+		Cloud:          model.Cloud,
+		Name:           model.Name,
+		SecretID:       model.SecretID,
+		Location:       model.Location,
+		ResourceGroup:  model.ResourceGroup,
+		StorageAccount: model.StorageAccount,
+	}
+}
+
+func toSettings(model settingsModel) etcdbackup.Settings {
+	return etcdbackup.Settings{
+		ClusterID:       model.ClusterID,
+		Enabled:         model.Enabled,
+		Bucket:          toBucket(model.Bucket),
+		Interval:        time.Duration(model.SnapshotInterval) * time.Second,
+		RetentionCount:  model.RetentionCount,
+		RetentionPeriod: time.Duration(model.RetentionPeriod) * time.Second,
+		UpdatedAt:       model.UpdatedAt,
+	}
+}
+
+func toSnapshot(model snapshotModel) etcdbackup.Snapshot {
+	return etcdbackup.Snapshot{
+		ID:            model.ID,
+		ClusterID:     model.ClusterID,
+		Status:        model.Status,
+		StatusMessage: model.StatusMessage,
+		Trigger:       model.Trigger,
+		Bucket:        toBucket(model.Bucket),
+		ObjectKey:     model.ObjectKey,
+		Size:          model.Size,
+		CreatedAt:     model.CreatedAt,
+		CompletedAt:   model.CompletedAt,
+	}
+}
+
+func toRestore(model restoreModel) etcdbackup.Restore {
+	return etcdbackup.Restore{
+		ID:            model.ID,
+		ClusterID:     model.ClusterID,
+		SnapshotID:    model.SnapshotID,
+		Status:        model.Status,
+		StatusMessage: model.StatusMessage,
+		StartedAt:     model.StartedAt,
+		FinishedAt:    model.FinishedAt,
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	"github.com/banzaicloud/pipeline/internal/common"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore_Settings(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.GetSettings(ctx, 1)
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &etcdbackup.SettingsNotFoundError{}))
+
+	settings := etcdbackup.Settings{
+		ClusterID: 1,
+		Enabled:   true,
+		Bucket: etcdbackup.Bucket{
+			Cloud:    "amazon",
+			Name:     "backups",
+			SecretID: "secret",
+			Location: "eu-west-1",
+		},
+		Interval:        24 * time.Hour,
+		RetentionCount:  7,
+		RetentionPeriod: 720 * time.Hour,
+	}
+
+	require.NoError(t, store.PutSettings(ctx, settings))
+
+	settings.Interval = 12 * time.Hour
+	require.NoError(t, store.PutSettings(ctx, settings))
+
+	require.NoError(t, store.PutSettings(ctx, etcdbackup.Settings{ClusterID: 2, Interval: time.Hour}))
+
+	stored, err := store.GetSettings(ctx, 1)
+	require.NoError(t, err)
+
+	stored.UpdatedAt = time.Time{}
+	assert.Equal(t, settings, stored)
+
+	enabled, err := store.ListEnabledSettings(ctx)
+	require.NoError(t, err)
+	require.Len(t, enabled, 1)
+	assert.Equal(t, uint(1), enabled[0].ClusterID)
+
+	require.NoError(t, store.DeleteSettings(ctx, 1))
+
+	_, err = store.GetSettings(ctx, 1)
+	assert.True(t, errors.As(err, &etcdbackup.SettingsNotFoundError{}))
+}
+
+func TestGormStore_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.GetSnapshot(ctx, 1, 1)
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &etcdbackup.SnapshotNotFoundError{}))
+
+	for i := 0; i < 2; i++ {
+		_, err := store.CreateSnapshot(ctx, etcdbackup.Snapshot{
+			ClusterID: 1,
+			Status:    etcdbackup.SnapshotCreating,
+			Trigger:   etcdbackup.TriggerScheduled,
+			Bucket:    etcdbackup.Bucket{Cloud: "amazon", Name: "backups"},
+		})
+		require.NoError(t, err)
+	}
+
+	snapshot, err := store.GetSnapshot(ctx, 1, 1)
+	require.NoError(t, err)
+
+	now := time.Now()
+	snapshot.Status = etcdbackup.SnapshotReady
+	snapshot.ObjectKey = "key"
+	snapshot.Size = 1024
+	snapshot.CompletedAt = &now
+
+	require.NoError(t, store.UpdateSnapshot(ctx, snapshot))
+
+	snapshots, err := store.ListSnapshots(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+
+	assert.Equal(t, uint(2), snapshots[0].ID)
+	assert.Equal(t, etcdbackup.SnapshotReady, snapshots[1].Status)
+	assert.Equal(t, "key", snapshots[1].ObjectKey)
+	assert.Equal(t, int64(1024), snapshots[1].Size)
+	assert.Equal(t, "backups", snapshots[1].Bucket.Name)
+	assert.NotNil(t, snapshots[1].CompletedAt)
+
+	_, err = store.GetSnapshot(ctx, 2, 1)
+	assert.True(t, errors.As(err, &etcdbackup.SnapshotNotFoundError{}))
+}
+
+func TestGormStore_Restores(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	id, err := store.CreateRestore(ctx, etcdbackup.Restore{ClusterID: 1, SnapshotID: 3, Status: etcdbackup.RestoreRunning})
+	require.NoError(t, err)
+
+	restore, err := store.GetRestore(ctx, 1, id)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), restore.SnapshotID)
+	assert.False(t, restore.StartedAt.IsZero())
+
+	now := time.Now()
+	restore.Status = etcdbackup.RestoreFailed
+	restore.StatusMessage = "failed"
+	restore.FinishedAt = &now
+
+	require.NoError(t, store.UpdateRestore(ctx, restore))
+
+	restores, err := store.ListRestores(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, restores, 1)
+
+	assert.Equal(t, etcdbackup.RestoreFailed, restores[0].Status)
+	assert.Equal(t, "failed", restores[0].StatusMessage)
+	assert.NotNil(t, restores[0].FinishedAt)
+
+	_, err = store.GetRestore(ctx, 1, 2)
+	assert.True(t, errors.As(err, &etcdbackup.RestoreNotFoundError{}))
+}
@@ -0,0 +1,457 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+)
+
+const (
+	etcdNamespace     = "kube-system"
+	etcdPodSelector   = "component=etcd,tier=control-plane"
+	restorePodLabel   = "app.kubernetes.io/name=pipeline-etcd-restore"
+	restoreContainer  = "restore"
+	snapshotMarker    = "pipeline-etcd-snapshot"
+	restoreMarker     = "pipeline-etcd-restore"
+	markerSnapshotKey = "snapshotId"
+)
+
+// KubernetesEtcd takes and restores snapshots of the etcd static pods (managed by kubeadm) of PKE clusters.
+//
+// Snapshots are streamed from the etcd pod through the Kubernetes API.
+// Before each snapshot a marker config map is written with the snapshot ID,
+// so that the snapshot a cluster was restored from can be identified once the control plane is back.
+//
+// Restores are executed by a privileged pod on the master node, which stops the control plane
+// by moving the static pod manifests away, replaces the etcd data directory and starts the control plane again.
+type KubernetesEtcd struct {
+	kubeConfigs  intClusterK8s.KubeConfigGetter
+	restoreImage string
+
+	pollInterval time.Duration
+}
+
+// NewKubernetesEtcd returns a new KubernetesEtcd.
+func NewKubernetesEtcd(kubeConfigs intClusterK8s.KubeConfigGetter, restoreImage string) KubernetesEtcd {
+	return KubernetesEtcd{
+		kubeConfigs:  kubeConfigs,
+		restoreImage: restoreImage,
+
+		pollInterval: 10 * time.Second,
+	}
+}
+
+// Snapshot implements the etcdbackup.Etcd interface.
+func (e KubernetesEtcd) Snapshot(ctx context.Context, clusterID uint, snapshotID uint, w io.Writer) error {
+	config, client, err := intClusterK8s.NewClusterClient(ctx, e.kubeConfigs, clusterID)
+	if err != nil {
+		return err
+	}
+
+	members, err := getEtcdMembers(client)
+	if err != nil {
+		return err
+	}
+
+	if len(members) == 0 {
+		return errors.New("running etcd pod not found")
+	}
+
+	member := members[0]
+
+	err = putMarker(client, snapshotMarker, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	file := path.Join(member.flags["data-dir"], fmt.Sprintf("pipeline-snapshot-%d.db", snapshotID))
+
+	// The snapshot is written into the data directory, since it is the only writable volume of the etcd pod
+	script := fmt.Sprintf(
+		"ETCDCTL_API=3 etcdctl --endpoints=%s --cacert=%s --cert=%s --key=%s snapshot save %s >&2 && cat %s; status=$?; rm -f %s; exit $status",
+		quote(member.endpoint()),
+		quote(member.flags["trusted-ca-file"]),
+		quote(member.flags["cert-file"]),
+		quote(member.flags["key-file"]),
+		quote(file),
+		quote(file),
+		quote(file),
+	)
+
+	err = intClusterK8s.ExecInPod(config, client, member.pod, "etcd", []string{"sh", "-c", script}, nil, w)
+
+	return errors.WrapIfWithDetails(err, "failed to save etcd snapshot", "pod", member.pod.Name)
+}
+
+// CheckRestore implements the etcdbackup.Etcd interface.
+func (e KubernetesEtcd) CheckRestore(ctx context.Context, clusterID uint) error {
+	_, client, err := intClusterK8s.NewClusterClient(ctx, e.kubeConfigs, clusterID)
+	if err != nil {
+		return err
+	}
+
+	_, err = getRestorableMember(client)
+
+	return err
+}
+
+// Restore implements the etcdbackup.Etcd interface.
+func (e KubernetesEtcd) Restore(ctx context.Context, clusterID uint, snapshotID uint, r io.Reader, progress func(message string)) error {
+	config, client, err := intClusterK8s.NewClusterClient(ctx, e.kubeConfigs, clusterID)
+	if err != nil {
+		return err
+	}
+
+	member, err := getRestorableMember(client)
+	if err != nil {
+		return err
+	}
+
+	pod, err := restorePod(member, e.restoreImage, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if err := putMarker(client, restoreMarker, snapshotID); err != nil {
+		return err
+	}
+
+	pod, err = client.CoreV1().Pods(etcdNamespace).Create(pod)
+	if err != nil {
+		return errors.WrapIf(err, "failed to create etcd restore pod")
+	}
+
+	progress("waiting for the etcd restore pod to start")
+
+	err = intClusterK8s.WaitForPodRunning(ctx, client, etcdNamespace, pod.Name, e.pollInterval)
+	if err != nil {
+		cleanUpRestore(client, pod.Name)
+
+		return errors.WrapIf(err, "etcd restore pod did not start")
+	}
+
+	progress("uploading etcd snapshot")
+
+	err = intClusterK8s.ExecInPod(config, client, *pod, restoreContainer, []string{"sh", "-c", "cat > /restore/snapshot.db && touch /restore/snapshot.done"}, r, nil)
+	if err != nil {
+		cleanUpRestore(client, pod.Name)
+
+		return errors.WrapIf(err, "failed to upload etcd snapshot")
+	}
+
+	progress("restoring etcd snapshot")
+
+	return e.waitForRestore(ctx, client, pod.Name, snapshotID, progress)
+}
+
+// waitForRestore waits until the control plane is back with the state of the snapshot.
+// The restore marker is not part of the snapshot, so it disappears once the snapshot is restored.
+func (e KubernetesEtcd) waitForRestore(ctx context.Context, client kubernetes.Interface, podName string, snapshotID uint, progress func(message string)) error {
+	err := intClusterK8s.Poll(ctx, e.pollInterval, func() (bool, error) {
+		restored, err := isRestored(client, podName, snapshotID)
+		if isTransientError(err) {
+			progress("waiting for the Kubernetes API server")
+
+			return false, nil
+		}
+
+		return restored, err
+	})
+
+	if ctx.Err() != nil {
+		return errors.WrapIf(err, "etcd snapshot was not restored in time")
+	}
+
+	if err != nil {
+		cleanUpRestore(client, podName)
+
+		return err
+	}
+
+	progress("etcd snapshot restored")
+
+	return nil
+}
+
+func isRestored(client kubernetes.Interface, podName string, snapshotID uint) (bool, error) {
+	pod, err := client.CoreV1().Pods(etcdNamespace).Get(podName, metav1.GetOptions{})
+	if err == nil && pod.Status.Phase == corev1.PodFailed {
+		return false, errors.Errorf("etcd restore failed: %s", intClusterK8s.PodTerminationMessage(*pod))
+	} else if err != nil && !k8serrors.IsNotFound(err) {
+		return false, transientError{err}
+	}
+
+	_, err = client.CoreV1().ConfigMaps(etcdNamespace).Get(restoreMarker, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return false, transientError{err}
+	}
+
+	marker, err := client.CoreV1().ConfigMaps(etcdNamespace).Get(snapshotMarker, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, transientError{err}
+	}
+
+	if err != nil || marker.Data[markerSnapshotKey] != strconv.FormatUint(uint64(snapshotID), 10) {
+		return false, errors.New("control plane is running with an unexpected etcd state")
+	}
+
+	return true, nil
+}
+
+// transientError is returned when the cluster is not reachable (eg. the API server is being restarted).
+type transientError struct {
+	error
+}
+
+func isTransientError(err error) bool {
+	return errors.As(err, &transientError{})
+}
+
+// cleanUpRestore removes the restore pod and the restore marker of a failed restore.
+func cleanUpRestore(client kubernetes.Interface, podName string) {
+	_ = client.CoreV1().Pods(etcdNamespace).Delete(podName, &metav1.DeleteOptions{})
+	_ = client.CoreV1().ConfigMaps(etcdNamespace).Delete(restoreMarker, &metav1.DeleteOptions{})
+}
+
+// etcdMember is an etcd static pod created by kubeadm.
+type etcdMember struct {
+	pod   corev1.Pod
+	flags map[string]string
+}
+
+func (m etcdMember) endpoint() string {
+	urls := strings.Split(m.flags["listen-client-urls"], ",")
+	if urls[0] == "" {
+		return "https://127.0.0.1:2379"
+	}
+
+	return urls[0]
+}
+
+func getEtcdMembers(client kubernetes.Interface) ([]etcdMember, error) {
+	pods, err := client.CoreV1().Pods(etcdNamespace).List(metav1.ListOptions{LabelSelector: etcdPodSelector})
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list etcd pods")
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	var members []etcdMember
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "etcd" {
+				members = append(members, etcdMember{pod: pod, flags: parseFlags(container)})
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// getRestorableMember returns the etcd member of a cluster if its control plane can be restored.
+// Restoring is only supported for clusters with a single master node.
+func getRestorableMember(client kubernetes.Interface) (etcdMember, error) {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: intClusterK8s.MasterNodeLabel})
+	if err != nil {
+		return etcdMember{}, errors.WrapIf(err, "failed to list master nodes")
+	}
+
+	members, err := getEtcdMembers(client)
+	if err != nil {
+		return etcdMember{}, err
+	}
+
+	if len(nodes.Items) != 1 || len(members) != 1 {
+		return etcdMember{}, etcdbackup.NewValidationError(
+			"cannot restore etcd snapshot",
+			[]string{"restoring is only supported for clusters with a single master node and a single etcd member"},
+		)
+	}
+
+	member := members[0]
+
+	for _, flag := range []string{"name", "data-dir", "initial-cluster", "initial-advertise-peer-urls", "trusted-ca-file", "cert-file", "key-file"} {
+		if member.flags[flag] == "" {
+			return etcdMember{}, errors.NewWithDetails("etcd pod is missing a required flag", "flag", flag, "pod", member.pod.Name)
+		}
+	}
+
+	return member, nil
+}
+
+// parseFlags returns the long flags of a container.
+func parseFlags(container corev1.Container) map[string]string {
+	flags := make(map[string]string)
+
+	for _, arg := range append(container.Command, container.Args...) {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimPrefix(arg, "--"), "=", 2)
+		if len(kv) == 2 {
+			flags[kv[0]] = kv[1]
+		}
+	}
+
+	return flags
+}
+
+func putMarker(client kubernetes.Interface, name string, snapshotID uint) error {
+	marker := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: etcdNamespace,
+		},
+		Data: map[string]string{
+			markerSnapshotKey: strconv.FormatUint(uint64(snapshotID), 10),
+		},
+	}
+
+	_, err := client.CoreV1().ConfigMaps(etcdNamespace).Update(marker)
+	if k8serrors.IsNotFound(err) {
+		_, err = client.CoreV1().ConfigMaps(etcdNamespace).Create(marker)
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to write etcd backup marker", "configMap", name)
+}
+
+const restoreScript = `set -eu
+export ETCDCTL_API=3
+
+health() {
+  etcdctl --endpoints="$RESTORE_ENDPOINT" --cacert="$RESTORE_CACERT" --cert="$RESTORE_CERT" --key="$RESTORE_KEY" endpoint health >/dev/null 2>&1
+}
+
+echo "waiting for the snapshot"
+while [ ! -f /restore/snapshot.done ]; do sleep 1; done
+
+health || { echo "etcd cannot be reached with the certificates of the etcd pod"; exit 1; }
+
+rm -rf "$RESTORE_DATA_DIR.restore"
+etcdctl snapshot restore /restore/snapshot.db \
+  --name="$RESTORE_NAME" \
+  --initial-cluster="$RESTORE_INITIAL_CLUSTER" \
+  --initial-advertise-peer-urls="$RESTORE_INITIAL_ADVERTISE_PEER_URLS" \
+  --data-dir="$RESTORE_DATA_DIR.restore"
+
+echo "stopping the control plane"
+mkdir -p /host/etc/kubernetes/manifests.etcd-restore
+trap 'mv /host/etc/kubernetes/manifests.etcd-restore/*.yaml /host/etc/kubernetes/manifests/ 2>/dev/null || true' EXIT
+mv /host/etc/kubernetes/manifests/*.yaml /host/etc/kubernetes/manifests.etcd-restore/
+
+while health; do sleep 5; done
+sleep 10
+
+echo "replacing the etcd data directory"
+mv "$RESTORE_DATA_DIR" "$RESTORE_DATA_DIR.before-restore-$RESTORE_SNAPSHOT_ID"
+if ! mv "$RESTORE_DATA_DIR.restore" "$RESTORE_DATA_DIR"; then
+  mv "$RESTORE_DATA_DIR.before-restore-$RESTORE_SNAPSHOT_ID" "$RESTORE_DATA_DIR"
+  exit 1
+fi
+
+echo "starting the control plane"
+`
+
+// restorePod returns a pod restoring a snapshot on the master node of an etcd member.
+// The pod waits for the snapshot to be uploaded before touching the control plane.
+func restorePod(member etcdMember, image string, snapshotID uint) (*corev1.Pod, error) {
+	const hostKubernetesDir = "/etc/kubernetes"
+
+	hostPath := func(p string) (string, error) {
+		if !strings.HasPrefix(p, hostKubernetesDir+"/") {
+			return "", errors.NewWithDetails("etcd certificates must be located in "+hostKubernetesDir, "path", p)
+		}
+
+		return path.Join("/host", p), nil
+	}
+
+	var env []corev1.EnvVar
+
+	for name, flag := range map[string]string{
+		"RESTORE_CACERT": "trusted-ca-file",
+		"RESTORE_CERT":   "cert-file",
+		"RESTORE_KEY":    "key-file",
+	} {
+		p, err := hostPath(member.flags[flag])
+		if err != nil {
+			return nil, err
+		}
+
+		env = append(env, corev1.EnvVar{Name: name, Value: p})
+	}
+
+	dataDir := path.Clean(member.flags["data-dir"])
+
+	env = append(
+		env,
+		corev1.EnvVar{Name: "RESTORE_ENDPOINT", Value: member.endpoint()},
+		corev1.EnvVar{Name: "RESTORE_NAME", Value: member.flags["name"]},
+		corev1.EnvVar{Name: "RESTORE_INITIAL_CLUSTER", Value: member.flags["initial-cluster"]},
+		corev1.EnvVar{Name: "RESTORE_INITIAL_ADVERTISE_PEER_URLS", Value: member.flags["initial-advertise-peer-urls"]},
+		corev1.EnvVar{Name: "RESTORE_DATA_DIR", Value: path.Join("/host/data", path.Base(dataDir))},
+		corev1.EnvVar{Name: "RESTORE_SNAPSHOT_ID", Value: strconv.FormatUint(uint64(snapshotID), 10)},
+	)
+
+	sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+
+	labels := strings.SplitN(restorePodLabel, "=", 2)
+
+	return intClusterK8s.NewNodePod(intClusterK8s.NodePodSpec{
+		Namespace:    etcdNamespace,
+		GenerateName: "pipeline-etcd-restore-",
+		Labels:       map[string]string{labels[0]: labels[1]},
+		NodeName:     member.pod.Spec.NodeName,
+		Container:    restoreContainer,
+		Image:        image,
+		Command:      []string{"sh", "-c", restoreScript},
+		Env:          env,
+		Privileged:   true,
+		HostNetwork:  true,
+		Volumes: []intClusterK8s.NodePodVolume{
+			{Name: "snapshot", MountPath: "/restore"},
+			{Name: "kubernetes", MountPath: path.Join("/host", hostKubernetesDir), HostPath: hostKubernetesDir},
+			{Name: "data", MountPath: "/host/data", HostPath: path.Dir(dataDir)},
+		},
+	}), nil
+}
+
+// quote quotes a string for sh.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,192 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+func masterNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"node-role.kubernetes.io/master": ""},
+		},
+	}
+}
+
+func etcdPod(name string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+			Labels:    map[string]string{"component": "etcd", "tier": "control-plane"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "etcd",
+					Command: []string{
+						"etcd",
+						"--name=master-0",
+						"--data-dir=/var/lib/etcd",
+						"--listen-client-urls=https://127.0.0.1:2379,https://10.0.0.1:2379",
+						"--initial-cluster=master-0=https://10.0.0.1:2380",
+						"--initial-advertise-peer-urls=https://10.0.0.1:2380",
+						"--trusted-ca-file=/etc/kubernetes/pki/etcd/ca.crt",
+						"--cert-file=/etc/kubernetes/pki/etcd/server.crt",
+						"--key-file=/etc/kubernetes/pki/etcd/server.key",
+						"--client-cert-auth",
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	flags := parseFlags(corev1.Container{
+		Command: []string{"etcd", "--name=master-0", "--client-cert-auth"},
+		Args:    []string{"--data-dir=/var/lib/etcd", "-v=2", "--initial-cluster=a=https://a,b=https://b"},
+	})
+
+	assert.Equal(t, map[string]string{
+		"name":            "master-0",
+		"data-dir":        "/var/lib/etcd",
+		"initial-cluster": "a=https://a,b=https://b",
+	}, flags)
+}
+
+func TestGetRestorableMember(t *testing.T) {
+	t.Run("SingleMaster", func(t *testing.T) {
+		client := fake.NewSimpleClientset(masterNode("master-0"), etcdPod("etcd-master-0", "master-0"))
+
+		member, err := getRestorableMember(client)
+		require.NoError(t, err)
+
+		assert.Equal(t, "etcd-master-0", member.pod.Name)
+		assert.Equal(t, "https://127.0.0.1:2379", member.endpoint())
+	})
+
+	t.Run("MultipleMasters", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			masterNode("master-0"),
+			masterNode("master-1"),
+			etcdPod("etcd-master-0", "master-0"),
+			etcdPod("etcd-master-1", "master-1"),
+		)
+
+		_, err := getRestorableMember(client)
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &etcdbackup.ValidationError{}))
+	})
+
+	t.Run("MissingFlag", func(t *testing.T) {
+		pod := etcdPod("etcd-master-0", "master-0")
+		pod.Spec.Containers[0].Command = pod.Spec.Containers[0].Command[:3]
+
+		client := fake.NewSimpleClientset([]runtime.Object{masterNode("master-0"), pod}...)
+
+		_, err := getRestorableMember(client)
+		require.Error(t, err)
+
+		assert.Equal(t, "etcd pod is missing a required flag", err.Error())
+	})
+}
+
+func TestRestorePod(t *testing.T) {
+	client := fake.NewSimpleClientset(masterNode("master-0"), etcdPod("etcd-master-0", "master-0"))
+
+	member, err := getRestorableMember(client)
+	require.NoError(t, err)
+
+	pod, err := restorePod(member, "etcd:3.4.3", 42)
+	require.NoError(t, err)
+
+	assert.Equal(t, "master-0", pod.Spec.NodeName)
+	assert.True(t, pod.Spec.HostNetwork)
+	assert.Equal(t, "/var/lib", pod.Spec.Volumes[2].HostPath.Path)
+
+	env := make(map[string]string)
+	for _, e := range pod.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+
+	assert.Equal(t, map[string]string{
+		"RESTORE_CACERT":                      "/host/etc/kubernetes/pki/etcd/ca.crt",
+		"RESTORE_CERT":                        "/host/etc/kubernetes/pki/etcd/server.crt",
+		"RESTORE_KEY":                         "/host/etc/kubernetes/pki/etcd/server.key",
+		"RESTORE_ENDPOINT":                    "https://127.0.0.1:2379",
+		"RESTORE_NAME":                        "master-0",
+		"RESTORE_INITIAL_CLUSTER":             "master-0=https://10.0.0.1:2380",
+		"RESTORE_INITIAL_ADVERTISE_PEER_URLS": "https://10.0.0.1:2380",
+		"RESTORE_DATA_DIR":                    "/host/data/etcd",
+		"RESTORE_SNAPSHOT_ID":                 "42",
+	}, env)
+
+	member.flags["key-file"] = "/var/lib/etcd/server.key"
+
+	_, err = restorePod(member, "etcd:3.4.3", 42)
+	assert.Error(t, err)
+}
+
+func TestIsRestored(t *testing.T) {
+	marker := func(name string, id string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+			Data:       map[string]string{"snapshotId": id},
+		}
+	}
+
+	t.Run("Restored", func(t *testing.T) {
+		client := fake.NewSimpleClientset(marker(snapshotMarker, "42"))
+
+		restored, err := isRestored(client, "pipeline-etcd-restore-abc", 42)
+		require.NoError(t, err)
+
+		assert.True(t, restored)
+	})
+
+	t.Run("InProgress", func(t *testing.T) {
+		client := fake.NewSimpleClientset(marker(snapshotMarker, "41"), marker(restoreMarker, "42"))
+
+		restored, err := isRestored(client, "pipeline-etcd-restore-abc", 42)
+		require.NoError(t, err)
+
+		assert.False(t, restored)
+	})
+
+	t.Run("UnexpectedState", func(t *testing.T) {
+		client := fake.NewSimpleClientset(marker(snapshotMarker, "41"))
+
+		_, err := isRestored(client, "pipeline-etcd-restore-abc", 42)
+		require.Error(t, err)
+
+		assert.False(t, isTransientError(err))
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	"github.com/banzaicloud/pipeline/pkg/objectstore"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+	alibabaObjectstore "github.com/banzaicloud/pipeline/pkg/providers/alibaba/objectstore"
+	amazonObjectstore "github.com/banzaicloud/pipeline/pkg/providers/amazon/objectstore"
+	"github.com/banzaicloud/pipeline/pkg/providers/azure"
+	azureObjectstore "github.com/banzaicloud/pipeline/pkg/providers/azure/objectstore"
+	googleObjectstore "github.com/banzaicloud/pipeline/pkg/providers/google/objectstore"
+	oracleObjectstore "github.com/banzaicloud/pipeline/pkg/providers/oracle/objectstore"
+)
+
+// ObjectStoreFactory creates object stores from the bucket secrets of organizations.
+type ObjectStoreFactory struct {
+	secrets secret.Store
+}
+
+// NewObjectStoreFactory returns a new ObjectStoreFactory.
+func NewObjectStoreFactory(secrets secret.Store) ObjectStoreFactory {
+	return ObjectStoreFactory{
+		secrets: secrets,
+	}
+}
+
+// NewObjectStore implements the etcdbackup.ObjectStoreFactory interface.
+func (f ObjectStoreFactory) NewObjectStore(ctx context.Context, organizationID uint, bucket etcdbackup.Bucket) (objectstore.ObjectStore, error) {
+	s, err := f.secrets.Get(ctx, organizationID, bucket.SecretID)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get bucket secret", "secretId", bucket.SecretID)
+	}
+
+	if s.Type != bucket.Cloud {
+		return nil, errors.NewWithDetails("bucket secret type does not match the cloud of the bucket", "secretType", s.Type, "cloud", bucket.Cloud)
+	}
+
+	values := s.Values
+
+	switch bucket.Cloud {
+	case providers.Alibaba:
+		return alibabaObjectstore.New(
+			alibabaObjectstore.Config{Region: bucket.Location},
+			alibabaObjectstore.Credentials{
+				AccessKeyID:     values[secrettype.AlibabaAccessKeyId],
+				SecretAccessKey: values[secrettype.AlibabaSecretAccessKey],
+			},
+		)
+
+	case providers.Amazon:
+		return amazonObjectstore.New(
+			amazonObjectstore.Config{Region: bucket.Location},
+			amazonObjectstore.Credentials{
+				AccessKeyID:     values[secrettype.AwsAccessKeyId],
+				SecretAccessKey: values[secrettype.AwsSecretAccessKey],
+			},
+		)
+
+	case providers.Azure:
+		return azureObjectstore.New(
+			azureObjectstore.Config{
+				StorageAccount: bucket.StorageAccount,
+				ResourceGroup:  bucket.ResourceGroup,
+			},
+			*azure.NewCredentials(values),
+		), nil
+
+	case providers.Google:
+		return googleObjectstore.New(
+			googleObjectstore.Config{Region: bucket.Location},
+			googleObjectstore.Credentials{
+				Type:                   values[secrettype.Type],
+				ProjectID:              values[secrettype.ProjectId],
+				PrivateKeyID:           values[secrettype.PrivateKeyId],
+				PrivateKey:             values[secrettype.PrivateKey],
+				ClientEmail:            values[secrettype.ClientEmail],
+				ClientID:               values[secrettype.ClientId],
+				AuthURI:                values[secrettype.AuthUri],
+				TokenURI:               values[secrettype.TokenUri],
+				AuthProviderX50CertURL: values[secrettype.AuthX509Url],
+				ClientX509CertURL:      values[secrettype.ClientX509Url],
+			},
+		)
+
+	case providers.Oracle:
+		return oracleObjectstore.New(
+			oracleObjectstore.Config{Region: bucket.Location},
+			oracleObjectstore.Credentials{
+				UserOCID:          values[secrettype.OracleUserOCID],
+				TenancyOCID:       values[secrettype.OracleTenancyOCID],
+				APIKey:            values[secrettype.OracleAPIKey],
+				APIKeyFingerprint: values[secrettype.OracleAPIKeyFingerprint],
+				CompartmentOCID:   values[secrettype.OracleCompartmentOCID],
+			},
+		)
+
+	default:
+		return nil, errors.NewWithDetails("unsupported bucket cloud", "cloud", bucket.Cloud)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupdriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("/settings").Handler(kithttp.NewServer(
+		endpoints.GetSettings,
+		decodeGetSettingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetSettingsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPut).Path("/settings").Handler(kithttp.NewServer(
+		endpoints.UpdateSettings,
+		decodeUpdateSettingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeUpdateSettingsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("/settings").Handler(kithttp.NewServer(
+		endpoints.DeleteSettings,
+		decodeDeleteSettingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/snapshots").Handler(kithttp.NewServer(
+		endpoints.ListSnapshots,
+		decodeListSnapshotsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListSnapshotsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPost).Path("/snapshots").Handler(kithttp.NewServer(
+		endpoints.CreateSnapshot,
+		decodeCreateSnapshotHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeCreateSnapshotHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/snapshots/{snapshotId}").Handler(kithttp.NewServer(
+		endpoints.GetSnapshot,
+		decodeGetSnapshotHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetSnapshotHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPost).Path("/snapshots/{snapshotId}/restore").Handler(kithttp.NewServer(
+		endpoints.RestoreSnapshot,
+		decodeRestoreSnapshotHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeRestoreSnapshotHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/restores").Handler(kithttp.NewServer(
+		endpoints.ListRestores,
+		decodeListRestoresHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListRestoresHTTPResponse, errorEncoder),
+		options...,
+	))
+}
+
+func decodeClusterID(r *http.Request) (uint, error) {
+	clusterIDStr, ok := mux.Vars(r)["clusterId"]
+	if !ok || clusterIDStr == "" {
+		return 0, errors.NewWithDetails("missing parameter from the URL", "param", "clusterId")
+	}
+
+	clusterID, err := strconv.ParseUint(clusterIDStr, 0, 0)
+	if err != nil {
+		return 0, errors.WrapIf(err, "invalid cluster ID format")
+	}
+
+	return uint(clusterID), nil
+}
+
+func decodeSnapshotParams(r *http.Request) (uint, uint, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	snapshotIDStr, ok := mux.Vars(r)["snapshotId"]
+	if !ok || snapshotIDStr == "" {
+		return 0, 0, errors.NewWithDetails("missing parameter from the URL", "param", "snapshotId")
+	}
+
+	snapshotID, err := strconv.ParseUint(snapshotIDStr, 0, 0)
+	if err != nil {
+		return 0, 0, errors.WrapIf(err, "invalid snapshot ID format")
+	}
+
+	return clusterID, uint(snapshotID), nil
+}
+
+// settingsBody is the transport representation of etcd backup settings.
+// Durations are represented as Go duration strings (eg. 24h).
+type settingsBody struct {
+	Enabled         bool              `json:"enabled"`
+	Bucket          etcdbackup.Bucket `json:"bucket"`
+	Interval        string            `json:"interval"`
+	RetentionCount  int               `json:"retentionCount"`
+	RetentionPeriod string            `json:"retentionPeriod,omitempty"`
+	UpdatedAt       *time.Time        `json:"updatedAt,omitempty"`
+}
+
+func toSettingsBody(settings etcdbackup.Settings) settingsBody {
+	body := settingsBody{
+		Enabled:        settings.Enabled,
+		Bucket:         settings.Bucket,
+		Interval:       settings.Interval.String(),
+		RetentionCount: settings.RetentionCount,
+		UpdatedAt:      &settings.UpdatedAt,
+	}
+
+	if settings.RetentionPeriod > 0 {
+		body.RetentionPeriod = settings.RetentionPeriod.String()
+	}
+
+	return body
+}
+
+func decodeGetSettingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSettingsRequest{ClusterID: clusterID}, nil
+}
+
+func encodeGetSettingsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetSettingsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, toSettingsBody(resp.Settings))
+}
+
+func decodeUpdateSettingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var body settingsBody
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	settings := etcdbackup.Settings{
+		Enabled:        body.Enabled,
+		Bucket:         body.Bucket,
+		RetentionCount: body.RetentionCount,
+	}
+
+	settings.Interval, err = time.ParseDuration(body.Interval)
+	if err != nil {
+		return nil, etcdbackup.NewValidationError("invalid etcd backup interval", []string{err.Error()})
+	}
+
+	if body.RetentionPeriod != "" {
+		settings.RetentionPeriod, err = time.ParseDuration(body.RetentionPeriod)
+		if err != nil {
+			return nil, etcdbackup.NewValidationError("invalid etcd backup retention period", []string{err.Error()})
+		}
+	}
+
+	return UpdateSettingsRequest{ClusterID: clusterID, Settings: settings}, nil
+}
+
+func encodeUpdateSettingsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(UpdateSettingsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, toSettingsBody(resp.Updated))
+}
+
+func decodeDeleteSettingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteSettingsRequest{ClusterID: clusterID}, nil
+}
+
+func decodeListSnapshotsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListSnapshotsRequest{ClusterID: clusterID}, nil
+}
+
+func encodeListSnapshotsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListSnapshotsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Snapshots)
+}
+
+func decodeCreateSnapshotHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateSnapshotRequest{ClusterID: clusterID}, nil
+}
+
+func encodeCreateSnapshotHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(CreateSnapshotResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(resp.Snapshot, http.StatusAccepted))
+}
+
+func decodeGetSnapshotHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, snapshotID, err := decodeSnapshotParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSnapshotRequest{ClusterID: clusterID, SnapshotID: snapshotID}, nil
+}
+
+func encodeGetSnapshotHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetSnapshotResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Snapshot)
+}
+
+func decodeRestoreSnapshotHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, snapshotID, err := decodeSnapshotParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return RestoreSnapshotRequest{ClusterID: clusterID, SnapshotID: snapshotID}, nil
+}
+
+func encodeRestoreSnapshotHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(RestoreSnapshotResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(resp.Restore, http.StatusAccepted))
+}
+
+func decodeListRestoresHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	clusterID, err := decodeClusterID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListRestoresRequest{ClusterID: clusterID}, nil
+}
+
+func encodeListRestoresHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListRestoresResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Restores)
+}
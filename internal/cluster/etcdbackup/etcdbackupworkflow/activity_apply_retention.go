@@ -0,0 +1,52 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+const ApplyRetentionActivityName = "etcd-backup-apply-retention"
+
+// ApplyRetentionActivity removes the etcd snapshots of a cluster that are not kept by its retention settings.
+type ApplyRetentionActivity struct {
+	manager etcdbackup.Manager
+}
+
+type ApplyRetentionActivityInput struct {
+	ClusterID uint
+}
+
+// NewApplyRetentionActivity returns a new ApplyRetentionActivity.
+func NewApplyRetentionActivity(manager etcdbackup.Manager) ApplyRetentionActivity {
+	return ApplyRetentionActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ApplyRetentionActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ApplyRetentionActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ApplyRetentionActivity) Execute(ctx context.Context, input ApplyRetentionActivityInput) error {
+	return a.manager.ApplyRetention(ctx, input.ClusterID, time.Now())
+}
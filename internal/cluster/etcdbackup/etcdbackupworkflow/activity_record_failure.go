@@ -0,0 +1,62 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+const RecordFailureActivityName = "etcd-backup-record-failure"
+
+// RecordFailureActivity records the failure of a snapshot or a restore that could not record it itself
+// (eg. because the activity timed out).
+type RecordFailureActivity struct {
+	manager etcdbackup.Manager
+}
+
+type RecordFailureActivityInput struct {
+	ClusterID uint
+
+	// Either SnapshotID or RestoreID is set
+	SnapshotID uint
+	RestoreID  uint
+
+	Message string
+}
+
+// NewRecordFailureActivity returns a new RecordFailureActivity.
+func NewRecordFailureActivity(manager etcdbackup.Manager) RecordFailureActivity {
+	return RecordFailureActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a RecordFailureActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: RecordFailureActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a RecordFailureActivity) Execute(ctx context.Context, input RecordFailureActivityInput) error {
+	if input.RestoreID != 0 {
+		return a.manager.FailRestore(ctx, input.ClusterID, input.RestoreID, input.Message)
+	}
+
+	return a.manager.FailSnapshot(ctx, input.ClusterID, input.SnapshotID, input.Message)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+const RestoreSnapshotActivityName = "etcd-backup-restore-snapshot"
+
+// RestoreSnapshotActivity rebuilds the control plane of a cluster from an etcd snapshot.
+type RestoreSnapshotActivity struct {
+	manager etcdbackup.Manager
+}
+
+type RestoreSnapshotActivityInput struct {
+	ClusterID uint
+	RestoreID uint
+}
+
+// NewRestoreSnapshotActivity returns a new RestoreSnapshotActivity.
+func NewRestoreSnapshotActivity(manager etcdbackup.Manager) RestoreSnapshotActivity {
+	return RestoreSnapshotActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a RestoreSnapshotActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: RestoreSnapshotActivityName})
+}
+
+// Execute is the main body of the activity.
+// The progress of the restore is recorded in the activity heartbeat.
+func (a RestoreSnapshotActivity) Execute(ctx context.Context, input RestoreSnapshotActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With("clusterID", input.ClusterID, "restoreID", input.RestoreID)
+
+	return a.manager.RestoreSnapshot(ctx, input.ClusterID, input.RestoreID, func(message string) {
+		logger.Info(message)
+
+		activity.RecordHeartbeat(ctx, message)
+	})
+}
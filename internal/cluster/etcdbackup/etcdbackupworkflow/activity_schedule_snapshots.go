@@ -0,0 +1,75 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+const ScheduleSnapshotsActivityName = "etcd-backup-schedule-snapshots"
+
+// ScheduleSnapshotsActivity records a snapshot for every cluster that is due for one.
+type ScheduleSnapshotsActivity struct {
+	manager etcdbackup.Manager
+}
+
+type ScheduleSnapshotsActivityInput struct{}
+
+type ScheduleSnapshotsActivityOutput struct {
+	Snapshots []SnapshotRef
+}
+
+// SnapshotRef identifies an etcd snapshot.
+type SnapshotRef struct {
+	ClusterID  uint
+	SnapshotID uint
+}
+
+// NewScheduleSnapshotsActivity returns a new ScheduleSnapshotsActivity.
+func NewScheduleSnapshotsActivity(manager etcdbackup.Manager) ScheduleSnapshotsActivity {
+	return ScheduleSnapshotsActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ScheduleSnapshotsActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ScheduleSnapshotsActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ScheduleSnapshotsActivity) Execute(ctx context.Context, _ ScheduleSnapshotsActivityInput) (ScheduleSnapshotsActivityOutput, error) {
+	snapshots, err := a.manager.ScheduleSnapshots(ctx, time.Now())
+	if err != nil {
+		return ScheduleSnapshotsActivityOutput{}, err
+	}
+
+	refs := make([]SnapshotRef, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		refs = append(refs, SnapshotRef{
+			ClusterID:  snapshot.ClusterID,
+			SnapshotID: snapshot.ID,
+		})
+	}
+
+	return ScheduleSnapshotsActivityOutput{
+		Snapshots: refs,
+	}, nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+const TakeSnapshotActivityName = "etcd-backup-take-snapshot"
+
+// TakeSnapshotActivity takes an etcd snapshot and uploads it to its bucket.
+type TakeSnapshotActivity struct {
+	manager etcdbackup.Manager
+}
+
+type TakeSnapshotActivityInput struct {
+	ClusterID  uint
+	SnapshotID uint
+}
+
+// NewTakeSnapshotActivity returns a new TakeSnapshotActivity.
+func NewTakeSnapshotActivity(manager etcdbackup.Manager) TakeSnapshotActivity {
+	return TakeSnapshotActivity{
+		manager: manager,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a TakeSnapshotActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: TakeSnapshotActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a TakeSnapshotActivity) Execute(ctx context.Context, input TakeSnapshotActivityInput) error {
+	heartbeat := startHeartbeat(ctx, 10*time.Second)
+	defer heartbeat.Stop()
+
+	return a.manager.TakeSnapshot(ctx, input.ClusterID, input.SnapshotID)
+}
@@ -0,0 +1,93 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const CreateSnapshotWorkflowName = "etcd-backup-create-snapshot"
+
+// CreateSnapshotWorkflowID returns the ID of the workflow taking a snapshot of a cluster.
+// There can be only one snapshot taken of a cluster at a time.
+func CreateSnapshotWorkflowID(clusterID uint) string {
+	return fmt.Sprintf("%s-%d", CreateSnapshotWorkflowName, clusterID)
+}
+
+// CreateSnapshotWorkflow takes an etcd snapshot of a cluster and applies the retention settings of the cluster.
+type CreateSnapshotWorkflow struct{}
+
+type CreateSnapshotWorkflowInput struct {
+	ClusterID  uint
+	SnapshotID uint
+}
+
+// NewCreateSnapshotWorkflow returns a new CreateSnapshotWorkflow.
+func NewCreateSnapshotWorkflow() CreateSnapshotWorkflow {
+	return CreateSnapshotWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w CreateSnapshotWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: CreateSnapshotWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+// Snapshots are not retried: a failed snapshot is recorded and the next one is taken by the scheduler.
+func (w CreateSnapshotWorkflow) Execute(ctx workflow.Context, input CreateSnapshotWorkflowInput) error {
+	snapshotCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    time.Hour,
+		HeartbeatTimeout:       time.Minute,
+	})
+
+	activityInput := TakeSnapshotActivityInput{
+		ClusterID:  input.ClusterID,
+		SnapshotID: input.SnapshotID,
+	}
+
+	err := workflow.ExecuteActivity(snapshotCtx, TakeSnapshotActivityName, activityInput).Get(ctx, nil)
+	if err != nil {
+		recordFailure(ctx, RecordFailureActivityInput{
+			ClusterID:  input.ClusterID,
+			SnapshotID: input.SnapshotID,
+			Message:    err.Error(),
+		})
+
+		return err
+	}
+
+	retentionCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+	})
+
+	return workflow.ExecuteActivity(retentionCtx, ApplyRetentionActivityName, ApplyRetentionActivityInput{ClusterID: input.ClusterID}).Get(ctx, nil)
+}
+
+// recordFailure records the failure of an operation in case the failed activity could not do it.
+func recordFailure(ctx workflow.Context, input RecordFailureActivityInput) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    time.Minute,
+	})
+
+	if err := workflow.ExecuteActivity(ctx, RecordFailureActivityName, input).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Sugar().Warnw("failed to record etcd backup failure", "clusterId", input.ClusterID, "error", err.Error())
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
+)
+
+// nolint: gochecknoinits
+func init() {
+	NewTakeSnapshotActivity(etcdbackup.Manager{}).Register()
+	NewApplyRetentionActivity(etcdbackup.Manager{}).Register()
+	NewRecordFailureActivity(etcdbackup.Manager{}).Register()
+}
+
+type CreateSnapshotWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestCreateSnapshotWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(CreateSnapshotWorkflowTestSuite))
+}
+
+func (s *CreateSnapshotWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewCreateSnapshotWorkflow().Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+}
+
+func (s *CreateSnapshotWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CreateSnapshotWorkflowTestSuite) Test_Success() {
+	s.env.OnActivity(TakeSnapshotActivityName, mock.Anything, TakeSnapshotActivityInput{ClusterID: 1, SnapshotID: 2}).Return(nil)
+	s.env.OnActivity(ApplyRetentionActivityName, mock.Anything, ApplyRetentionActivityInput{ClusterID: 1}).Return(nil)
+
+	s.env.ExecuteWorkflow(s.T().Name(), CreateSnapshotWorkflowInput{ClusterID: 1, SnapshotID: 2})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CreateSnapshotWorkflowTestSuite) Test_SnapshotFailed() {
+	s.env.OnActivity(TakeSnapshotActivityName, mock.Anything, TakeSnapshotActivityInput{ClusterID: 1, SnapshotID: 2}).Return(errors.New("etcd is down"))
+	s.env.OnActivity(RecordFailureActivityName, mock.Anything, mock.MatchedBy(func(input RecordFailureActivityInput) bool {
+		return input.ClusterID == 1 && input.SnapshotID == 2
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(s.T().Name(), CreateSnapshotWorkflowInput{ClusterID: 1, SnapshotID: 2})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
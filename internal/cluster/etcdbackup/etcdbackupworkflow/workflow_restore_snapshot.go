@@ -0,0 +1,76 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const RestoreSnapshotWorkflowName = "etcd-backup-restore-snapshot"
+
+// RestoreSnapshotWorkflowID returns the ID of the workflow restoring a cluster.
+// There can be only one restore running for a cluster at a time.
+func RestoreSnapshotWorkflowID(clusterID uint) string {
+	return fmt.Sprintf("%s-%d", RestoreSnapshotWorkflowName, clusterID)
+}
+
+// RestoreSnapshotWorkflow rebuilds the control plane of a cluster from an etcd snapshot.
+type RestoreSnapshotWorkflow struct{}
+
+type RestoreSnapshotWorkflowInput struct {
+	ClusterID uint
+	RestoreID uint
+}
+
+// NewRestoreSnapshotWorkflow returns a new RestoreSnapshotWorkflow.
+func NewRestoreSnapshotWorkflow() RestoreSnapshotWorkflow {
+	return RestoreSnapshotWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w RestoreSnapshotWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: RestoreSnapshotWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+// Restores are never retried, since they stop the control plane of the cluster.
+func (w RestoreSnapshotWorkflow) Execute(ctx workflow.Context, input RestoreSnapshotWorkflowInput) error {
+	restoreCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    time.Hour,
+		HeartbeatTimeout:       5 * time.Minute,
+	})
+
+	activityInput := RestoreSnapshotActivityInput{
+		ClusterID: input.ClusterID,
+		RestoreID: input.RestoreID,
+	}
+
+	err := workflow.ExecuteActivity(restoreCtx, RestoreSnapshotActivityName, activityInput).Get(ctx, nil)
+	if err != nil {
+		recordFailure(ctx, RecordFailureActivityInput{
+			ClusterID: input.ClusterID,
+			RestoreID: input.RestoreID,
+			Message:   err.Error(),
+		})
+
+		return err
+	}
+
+	return nil
+}
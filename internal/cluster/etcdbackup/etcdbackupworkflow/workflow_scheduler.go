@@ -0,0 +1,99 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackupworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const SchedulerWorkflowName = "etcd-backup-scheduler"
+
+// SchedulerWorkflow takes a snapshot of every cluster that is due for one.
+type SchedulerWorkflow struct{}
+
+// NewSchedulerWorkflow returns a new SchedulerWorkflow.
+func NewSchedulerWorkflow() SchedulerWorkflow {
+	return SchedulerWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w SchedulerWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: SchedulerWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w SchedulerWorkflow) Execute(ctx workflow.Context) error {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	var output ScheduleSnapshotsActivityOutput
+
+	err := workflow.ExecuteActivity(ctx, ScheduleSnapshotsActivityName, ScheduleSnapshotsActivityInput{}).Get(ctx, &output)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	futures := make([]workflow.ChildWorkflowFuture, 0, len(output.Snapshots))
+
+	for _, ref := range output.Snapshots {
+		childWorkflowOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:                   CreateSnapshotWorkflowID(ref.ClusterID),
+			ExecutionStartToCloseTimeout: 2 * time.Hour,
+			TaskStartToCloseTimeout:      30 * time.Second,
+		}
+
+		workflowInput := CreateSnapshotWorkflowInput{
+			ClusterID:  ref.ClusterID,
+			SnapshotID: ref.SnapshotID,
+		}
+
+		futures = append(futures, workflow.ExecuteChildWorkflow(
+			workflow.WithChildOptions(ctx, childWorkflowOptions),
+			CreateSnapshotWorkflowName,
+			workflowInput,
+		))
+	}
+
+	// Snapshot failures are recorded in the snapshot history, they should not stop the scheduler
+	for i, future := range futures {
+		if err := future.Get(ctx, nil); err != nil {
+			ref := output.Snapshots[i]
+
+			logger.Warnw(
+				"failed to take etcd snapshot",
+				"clusterId", ref.ClusterID,
+				"snapshotId", ref.SnapshotID,
+				"error", err.Error(),
+			)
+
+			// The snapshot is left in progress if the child workflow could not even start
+			recordFailure(ctx, RecordFailureActivityInput{
+				ClusterID:  ref.ClusterID,
+				SnapshotID: ref.SnapshotID,
+				Message:    err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,410 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/pkg/objectstore"
+)
+
+// Etcd takes and restores snapshots of the etcd of clusters.
+type Etcd interface {
+	// Snapshot saves a snapshot of the etcd of a cluster to w.
+	// The snapshot contains a marker with the snapshot ID, so that a restored cluster can be identified.
+	Snapshot(ctx context.Context, clusterID uint, snapshotID uint, w io.Writer) error
+
+	// CheckRestore checks whether the control plane of a cluster can be restored from a snapshot.
+	CheckRestore(ctx context.Context, clusterID uint) error
+
+	// Restore rebuilds the control plane of a cluster from the snapshot read from r
+	// and waits until the cluster is back with the restored state.
+	Restore(ctx context.Context, clusterID uint, snapshotID uint, r io.Reader, progress func(message string)) error
+}
+
+// Manager takes, expires and restores snapshots.
+type Manager struct {
+	store        Store
+	clusters     ClusterStore
+	objectStores ObjectStoreFactory
+	etcd         Etcd
+
+	logger Logger
+}
+
+// NewManager returns a new Manager.
+func NewManager(
+	store Store,
+	clusters ClusterStore,
+	objectStores ObjectStoreFactory,
+	etcd Etcd,
+	logger Logger,
+) Manager {
+	return Manager{
+		store:        store,
+		clusters:     clusters,
+		objectStores: objectStores,
+		etcd:         etcd,
+
+		logger: logger,
+	}
+}
+
+// ScheduleSnapshots records a snapshot for every cluster that is due for one and returns the recorded snapshots.
+// A cluster is due when no snapshot was started for it within its interval.
+func (m Manager) ScheduleSnapshots(ctx context.Context, now time.Time) ([]Snapshot, error) {
+	settings, err := m.store.ListEnabledSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+
+	for _, s := range settings {
+		logger := m.logger.WithFields(map[string]interface{}{"clusterId": s.ClusterID})
+
+		c, err := m.clusters.GetCluster(ctx, s.ClusterID)
+		if cluster.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if c.Status != cluster.Running && c.Status != cluster.Warning {
+			logger.Debug("skipping etcd snapshot of cluster that is not running")
+
+			continue
+		}
+
+		history, err := m.store.ListSnapshots(ctx, s.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(history) > 0 && now.Sub(history[0].CreatedAt) < s.Interval {
+			continue
+		}
+
+		err = checkIdle(ctx, m.store, s.ClusterID)
+		if errors.As(err, &OperationInProgressError{}) {
+			logger.Debug("skipping etcd snapshot of busy cluster")
+
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		id, err := m.store.CreateSnapshot(ctx, Snapshot{
+			ClusterID: s.ClusterID,
+			Status:    SnapshotCreating,
+			Trigger:   TriggerScheduled,
+			Bucket:    s.Bucket,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot, err := m.store.GetSnapshot(ctx, s.ClusterID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// TakeSnapshot takes a recorded snapshot and uploads it to its bucket.
+// The outcome is recorded in the status of the snapshot.
+func (m Manager) TakeSnapshot(ctx context.Context, clusterID uint, snapshotID uint) error {
+	snapshot, err := m.store.GetSnapshot(ctx, clusterID, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if snapshot.Status != SnapshotCreating {
+		return nil
+	}
+
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	store, err := m.objectStores.NewObjectStore(ctx, c.OrganizationID, snapshot.Bucket)
+	if err != nil {
+		return m.failSnapshot(ctx, snapshot, errors.WrapIf(err, "failed to access bucket"))
+	}
+
+	key := fmt.Sprintf("etcd-snapshots/%s/%s-%d.db", c.UID, snapshot.CreatedAt.UTC().Format("20060102T150405Z"), snapshot.ID)
+
+	size, err := m.upload(ctx, store, snapshot, key)
+	if err != nil {
+		// Remove the partially uploaded object (if any)
+		_ = store.DeleteObject(snapshot.Bucket.Name, key)
+
+		return m.failSnapshot(ctx, snapshot, err)
+	}
+
+	now := time.Now()
+	snapshot.Status = SnapshotReady
+	snapshot.StatusMessage = ""
+	snapshot.ObjectKey = key
+	snapshot.Size = size
+	snapshot.CompletedAt = &now
+
+	return m.store.UpdateSnapshot(ctx, snapshot)
+}
+
+// upload streams a snapshot into the bucket and returns its size.
+func (m Manager) upload(ctx context.Context, store objectstore.ObjectStore, snapshot Snapshot, key string) (int64, error) {
+	r, w := io.Pipe()
+	counter := &countingWriter{w: w}
+
+	done := make(chan error, 1)
+
+	go func() {
+		err := m.etcd.Snapshot(ctx, snapshot.ClusterID, snapshot.ID, counter)
+		_ = w.CloseWithError(err)
+
+		done <- err
+	}()
+
+	uploadErr := store.PutObject(snapshot.Bucket.Name, key, r)
+
+	// Unblock the snapshot if the upload stopped reading
+	_ = r.CloseWithError(errors.New("upload finished"))
+
+	if err := <-done; err != nil {
+		return 0, errors.WrapIf(err, "failed to take etcd snapshot")
+	}
+
+	if uploadErr != nil {
+		return 0, errors.WrapIf(uploadErr, "failed to upload etcd snapshot")
+	}
+
+	if counter.n == 0 {
+		return 0, errors.New("etcd snapshot is empty")
+	}
+
+	return counter.n, nil
+}
+
+// FailSnapshot records the failure of a snapshot that is still being taken.
+func (m Manager) FailSnapshot(ctx context.Context, clusterID uint, snapshotID uint, message string) error {
+	snapshot, err := m.store.GetSnapshot(ctx, clusterID, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if snapshot.Status != SnapshotCreating {
+		return nil
+	}
+
+	return m.failSnapshot(ctx, snapshot, errors.New(message))
+}
+
+func (m Manager) failSnapshot(ctx context.Context, snapshot Snapshot, cause error) error {
+	now := time.Now()
+	snapshot.Status = SnapshotFailed
+	snapshot.StatusMessage = cause.Error()
+	snapshot.CompletedAt = &now
+
+	if err := m.store.UpdateSnapshot(ctx, snapshot); err != nil {
+		return errors.Combine(cause, err)
+	}
+
+	return cause
+}
+
+// ApplyRetention removes the snapshots of a cluster from their bucket that are not kept by the retention settings.
+// The latest snapshot is always kept.
+func (m Manager) ApplyRetention(ctx context.Context, clusterID uint, now time.Time) error {
+	settings, err := m.store.GetSettings(ctx, clusterID)
+	if errors.As(err, &SettingsNotFoundError{}) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if settings.RetentionCount == 0 && settings.RetentionPeriod == 0 {
+		return nil
+	}
+
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := m.store.ListSnapshots(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	var kept int
+
+	for _, snapshot := range snapshots {
+		if snapshot.Status != SnapshotReady {
+			continue
+		}
+
+		kept++
+
+		expired := settings.RetentionPeriod > 0 && now.Sub(snapshot.CreatedAt) > settings.RetentionPeriod
+		if kept == 1 || ((settings.RetentionCount == 0 || kept <= settings.RetentionCount) && !expired) {
+			continue
+		}
+
+		if err := m.deleteSnapshot(ctx, c.OrganizationID, snapshot); err != nil {
+			errs = errors.Append(errs, errors.WrapIfWithDetails(err, "failed to delete etcd snapshot", "snapshotId", snapshot.ID))
+		}
+	}
+
+	return errs
+}
+
+func (m Manager) deleteSnapshot(ctx context.Context, organizationID uint, snapshot Snapshot) error {
+	store, err := m.objectStores.NewObjectStore(ctx, organizationID, snapshot.Bucket)
+	if err != nil {
+		return err
+	}
+
+	if err := store.DeleteObject(snapshot.Bucket.Name, snapshot.ObjectKey); err != nil && !objectstore.IsNotFoundError(err) {
+		return err
+	}
+
+	snapshot.Status = SnapshotDeleted
+	snapshot.StatusMessage = "removed by the retention policy"
+
+	return m.store.UpdateSnapshot(ctx, snapshot)
+}
+
+// RestoreSnapshot rebuilds the control plane of a cluster from the snapshot of a recorded restore.
+// The outcome is recorded in the status of the restore.
+func (m Manager) RestoreSnapshot(ctx context.Context, clusterID uint, restoreID uint, progress func(message string)) error {
+	restore, err := m.store.GetRestore(ctx, clusterID, restoreID)
+	if err != nil {
+		return err
+	}
+
+	if restore.Status != RestoreRunning {
+		return nil
+	}
+
+	snapshot, err := m.store.GetSnapshot(ctx, clusterID, restore.SnapshotID)
+	if err != nil {
+		return m.failRestore(ctx, restore, err, false)
+	}
+
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return m.failRestore(ctx, restore, err, false)
+	}
+
+	if err := m.etcd.CheckRestore(ctx, clusterID); err != nil {
+		return m.failRestore(ctx, restore, err, false)
+	}
+
+	store, err := m.objectStores.NewObjectStore(ctx, c.OrganizationID, snapshot.Bucket)
+	if err != nil {
+		return m.failRestore(ctx, restore, errors.WrapIf(err, "failed to access bucket"), false)
+	}
+
+	object, err := store.GetObject(snapshot.Bucket.Name, snapshot.ObjectKey)
+	if err != nil {
+		return m.failRestore(ctx, restore, errors.WrapIf(err, "failed to download etcd snapshot"), false)
+	}
+	defer object.Close()
+
+	if err := m.clusters.SetStatus(ctx, clusterID, cluster.Updating, "Restoring etcd snapshot"); err != nil {
+		return m.failRestore(ctx, restore, err, false)
+	}
+
+	if err := m.etcd.Restore(ctx, clusterID, snapshot.ID, object, progress); err != nil {
+		return m.failRestore(ctx, restore, err, true)
+	}
+
+	if err := m.clusters.SetStatus(ctx, clusterID, cluster.Running, cluster.RunningMessage); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	restore.Status = RestoreSucceeded
+	restore.StatusMessage = ""
+	restore.FinishedAt = &now
+
+	return m.store.UpdateRestore(ctx, restore)
+}
+
+// FailRestore records the failure of a restore that is still running.
+func (m Manager) FailRestore(ctx context.Context, clusterID uint, restoreID uint, message string) error {
+	restore, err := m.store.GetRestore(ctx, clusterID, restoreID)
+	if err != nil {
+		return err
+	}
+
+	if restore.Status != RestoreRunning {
+		return nil
+	}
+
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return m.failRestore(ctx, restore, errors.New(message), c.Status == cluster.Updating)
+}
+
+func (m Manager) failRestore(ctx context.Context, restore Restore, cause error, clusterChanged bool) error {
+	now := time.Now()
+	restore.Status = RestoreFailed
+	restore.StatusMessage = cause.Error()
+	restore.FinishedAt = &now
+
+	var errs error
+
+	if clusterChanged {
+		err := m.clusters.SetStatus(ctx, restore.ClusterID, cluster.Warning, "Failed to restore etcd snapshot: "+cause.Error())
+		errs = errors.Append(errs, err)
+	}
+
+	errs = errors.Append(errs, m.store.UpdateRestore(ctx, restore))
+
+	if errs != nil {
+		return errors.Combine(cause, errs)
+	}
+
+	return cause
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,351 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdbackup
+
+import (
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type inMemoryStore struct {
+	settings  map[uint]Settings
+	snapshots map[uint]Snapshot
+	restores  map[uint]Restore
+	now       time.Time
+}
+
+func newInMemoryStore(now time.Time) *inMemoryStore {
+	return &inMemoryStore{
+		settings:  make(map[uint]Settings),
+		snapshots: make(map[uint]Snapshot),
+		restores:  make(map[uint]Restore),
+		now:       now,
+	}
+}
+
+func (s *inMemoryStore) GetSettings(_ context.Context, clusterID uint) (Settings, error) {
+	settings, ok := s.settings[clusterID]
+	if !ok {
+		return Settings{}, SettingsNotFoundError{ClusterID: clusterID}
+	}
+
+	return settings, nil
+}
+
+func (s *inMemoryStore) ListEnabledSettings(_ context.Context) ([]Settings, error) {
+	var settings []Settings
+
+	for _, item := range s.settings {
+		if item.Enabled {
+			settings = append(settings, item)
+		}
+	}
+
+	sort.Slice(settings, func(i, j int) bool { return settings[i].ClusterID < settings[j].ClusterID })
+
+	return settings, nil
+}
+
+func (s *inMemoryStore) PutSettings(_ context.Context, settings Settings) error {
+	s.settings[settings.ClusterID] = settings
+
+	return nil
+}
+
+func (s *inMemoryStore) DeleteSettings(_ context.Context, clusterID uint) error {
+	delete(s.settings, clusterID)
+
+	return nil
+}
+
+func (s *inMemoryStore) CreateSnapshot(_ context.Context, snapshot Snapshot) (uint, error) {
+	snapshot.ID = uint(len(s.snapshots) + 1)
+	snapshot.CreatedAt = s.now
+	s.snapshots[snapshot.ID] = snapshot
+
+	return snapshot.ID, nil
+}
+
+func (s *inMemoryStore) GetSnapshot(_ context.Context, clusterID uint, id uint) (Snapshot, error) {
+	snapshot, ok := s.snapshots[id]
+	if !ok || snapshot.ClusterID != clusterID {
+		return Snapshot{}, SnapshotNotFoundError{ClusterID: clusterID, SnapshotID: id}
+	}
+
+	return snapshot, nil
+}
+
+func (s *inMemoryStore) ListSnapshots(_ context.Context, clusterID uint) ([]Snapshot, error) {
+	var snapshots []Snapshot
+
+	for _, snapshot := range s.snapshots {
+		if snapshot.ClusterID == clusterID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID > snapshots[j].ID })
+
+	return snapshots, nil
+}
+
+func (s *inMemoryStore) UpdateSnapshot(_ context.Context, snapshot Snapshot) error {
+	s.snapshots[snapshot.ID] = snapshot
+
+	return nil
+}
+
+func (s *inMemoryStore) CreateRestore(_ context.Context, restore Restore) (uint, error) {
+	restore.ID = uint(len(s.restores) + 1)
+	restore.StartedAt = s.now
+	s.restores[restore.ID] = restore
+
+	return restore.ID, nil
+}
+
+func (s *inMemoryStore) GetRestore(_ context.Context, clusterID uint, id uint) (Restore, error) {
+	restore, ok := s.restores[id]
+	if !ok || restore.ClusterID != clusterID {
+		return Restore{}, RestoreNotFoundError{ClusterID: clusterID, RestoreID: id}
+	}
+
+	return restore, nil
+}
+
+func (s *inMemoryStore) ListRestores(_ context.Context, clusterID uint) ([]Restore, error) {
+	var restores []Restore
+
+	for _, restore := range s.restores {
+		if restore.ClusterID == clusterID {
+			restores = append(restores, restore)
+		}
+	}
+
+	sort.Slice(restores, func(i, j int) bool { return restores[i].ID > restores[j].ID })
+
+	return restores, nil
+}
+
+func (s *inMemoryStore) UpdateRestore(_ context.Context, restore Restore) error {
+	s.restores[restore.ID] = restore
+
+	return nil
+}
+
+type fakeEtcd struct {
+	snapshot   []byte
+	restored   []byte
+	restoreErr error
+}
+
+func (e *fakeEtcd) Snapshot(_ context.Context, _ uint, _ uint, w io.Writer) error {
+	_, err := w.Write(e.snapshot)
+
+	return err
+}
+
+func (e *fakeEtcd) CheckRestore(_ context.Context, _ uint) error {
+	return nil
+}
+
+func (e *fakeEtcd) Restore(_ context.Context, _ uint, _ uint, r io.Reader, progress func(message string)) error {
+	if e.restoreErr != nil {
+		return e.restoreErr
+	}
+
+	var buf = make([]byte, 1024)
+	n, _ := io.ReadFull(r, buf)
+	e.restored = buf[:n]
+
+	progress("restored")
+
+	return nil
+}
+
+func pkeCluster(id uint, status string) cluster.Cluster {
+	return cluster.Cluster{
+		ID:             id,
+		UID:            "uid",
+		OrganizationID: 1,
+		Distribution:   pkgCluster.PKE,
+		Status:         status,
+	}
+}
+
+func TestManager_ScheduleSnapshots(t *testing.T) {
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	store := newInMemoryStore(now)
+	clusters := newInMemoryClusterStore(
+		pkeCluster(1, cluster.Running),
+		pkeCluster(2, cluster.Running),
+		pkeCluster(3, cluster.Updating),
+		pkeCluster(4, cluster.Running),
+	)
+
+	for id := uint(1); id <= 5; id++ {
+		settings := validSettings()
+		settings.ClusterID = id
+		store.settings[id] = settings
+	}
+
+	// cluster 2 had a snapshot recently
+	store.snapshots[1] = Snapshot{ID: 1, ClusterID: 2, Status: SnapshotReady, CreatedAt: now.Add(-time.Hour)}
+
+	// cluster 4 is being restored
+	store.restores[1] = Restore{ID: 1, ClusterID: 4, Status: RestoreRunning}
+
+	manager := NewManager(store, clusters, objectStoreFactory{}, &fakeEtcd{}, NoopLogger{})
+
+	snapshots, err := manager.ScheduleSnapshots(context.Background(), now)
+	require.NoError(t, err)
+
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, uint(1), snapshots[0].ClusterID)
+	assert.Equal(t, SnapshotCreating, snapshots[0].Status)
+	assert.Equal(t, TriggerScheduled, snapshots[0].Trigger)
+}
+
+func TestManager_TakeSnapshot(t *testing.T) {
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	store := newInMemoryStore(now)
+	objects := newInMemoryObjectStore()
+	clusters := newInMemoryClusterStore(pkeCluster(1, cluster.Running))
+
+	id, err := store.CreateSnapshot(context.Background(), Snapshot{ClusterID: 1, Status: SnapshotCreating, Bucket: validSettings().Bucket})
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		manager := NewManager(store, clusters, objectStoreFactory{objects}, &fakeEtcd{snapshot: []byte("snapshot")}, NoopLogger{})
+
+		err := manager.TakeSnapshot(context.Background(), 1, id)
+		require.NoError(t, err)
+
+		snapshot := store.snapshots[id]
+		assert.Equal(t, SnapshotReady, snapshot.Status)
+		assert.Equal(t, "etcd-snapshots/uid/20200515T120000Z-1.db", snapshot.ObjectKey)
+		assert.Equal(t, int64(8), snapshot.Size)
+		assert.Equal(t, []byte("snapshot"), objects.objects[snapshot.ObjectKey])
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		id, err := store.CreateSnapshot(context.Background(), Snapshot{ClusterID: 1, Status: SnapshotCreating})
+		require.NoError(t, err)
+
+		manager := NewManager(store, clusters, objectStoreFactory{objects}, &fakeEtcd{}, NoopLogger{})
+
+		err = manager.TakeSnapshot(context.Background(), 1, id)
+		require.Error(t, err)
+
+		assert.Equal(t, SnapshotFailed, store.snapshots[id].Status)
+		assert.Equal(t, "etcd snapshot is empty", store.snapshots[id].StatusMessage)
+		assert.Len(t, objects.objects, 1)
+	})
+}
+
+func TestManager_ApplyRetention(t *testing.T) {
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	store := newInMemoryStore(now)
+	objects := newInMemoryObjectStore()
+	clusters := newInMemoryClusterStore(pkeCluster(1, cluster.Running))
+
+	settings := validSettings()
+	settings.ClusterID = 1
+	settings.RetentionCount = 2
+	settings.RetentionPeriod = 72 * time.Hour
+	store.settings[1] = settings
+
+	snapshots := []Snapshot{
+		{ID: 1, Status: SnapshotReady, CreatedAt: now.Add(-96 * time.Hour)},
+		{ID: 2, Status: SnapshotReady, CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: 3, Status: SnapshotReady, CreatedAt: now.Add(-24 * time.Hour)},
+		{ID: 4, Status: SnapshotFailed, CreatedAt: now.Add(-12 * time.Hour)},
+		{ID: 5, Status: SnapshotReady, CreatedAt: now},
+	}
+
+	for _, snapshot := range snapshots {
+		snapshot.ClusterID = 1
+		snapshot.ObjectKey = string(rune('a' + snapshot.ID))
+		store.snapshots[snapshot.ID] = snapshot
+
+		if snapshot.Status == SnapshotReady && snapshot.ID != 2 {
+			objects.objects[snapshot.ObjectKey] = []byte("snapshot")
+		}
+	}
+
+	manager := NewManager(store, clusters, objectStoreFactory{objects}, &fakeEtcd{}, NoopLogger{})
+
+	err := manager.ApplyRetention(context.Background(), 1, now)
+	require.NoError(t, err)
+
+	var statuses []string
+	for id := uint(1); id <= 5; id++ {
+		statuses = append(statuses, store.snapshots[id].Status)
+	}
+
+	assert.Equal(t, []string{SnapshotDeleted, SnapshotDeleted, SnapshotReady, SnapshotFailed, SnapshotReady}, statuses)
+	assert.Len(t, objects.objects, 2)
+}
+
+func TestManager_RestoreSnapshot(t *testing.T) {
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	setup := func(etcd Etcd) (*inMemoryStore, *inMemoryClusterStore, Manager) {
+		store := newInMemoryStore(now)
+		objects := newInMemoryObjectStore()
+		clusters := newInMemoryClusterStore(pkeCluster(1, cluster.Running))
+
+		store.snapshots[1] = Snapshot{ID: 1, ClusterID: 1, Status: SnapshotReady, ObjectKey: "key"}
+		store.restores[1] = Restore{ID: 1, ClusterID: 1, SnapshotID: 1, Status: RestoreRunning}
+		objects.objects["key"] = []byte("snapshot")
+
+		return store, clusters, NewManager(store, clusters, objectStoreFactory{objects}, etcd, NoopLogger{})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		etcd := &fakeEtcd{}
+		store, clusters, manager := setup(etcd)
+
+		err := manager.RestoreSnapshot(context.Background(), 1, 1, func(string) {})
+		require.NoError(t, err)
+
+		assert.Equal(t, []byte("snapshot"), etcd.restored)
+		assert.Equal(t, RestoreSucceeded, store.restores[1].Status)
+		assert.NotNil(t, store.restores[1].FinishedAt)
+		assert.Equal(t, cluster.Running, clusters.clusters[1].Status)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		store, clusters, manager := setup(&fakeEtcd{restoreErr: errors.New("etcd is down")})
+
+		err := manager.RestoreSnapshot(context.Background(), 1, 1, func(string) {})
+		require.Error(t, err)
+
+		assert.Equal(t, RestoreFailed, store.restores[1].Status)
+		assert.Equal(t, cluster.Warning, clusters.clusters[1].Status)
+	})
+}
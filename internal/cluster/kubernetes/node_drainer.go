@@ -0,0 +1,279 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	clientretry "k8s.io/client-go/util/retry"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+// DrainingTaintKey is the taint put on nodes that are being drained.
+// Nodes with this taint are not counted as available node pool capacity.
+const DrainingTaintKey = "node.banzaicloud.io/draining"
+
+// ErrNodeDrainTimeout is returned when pods could not be evicted from a node in time.
+const ErrNodeDrainTimeout = errors.Sentinel("timed out waiting for pods to be evicted")
+
+// mirrorPodAnnotationKey marks static pods mirrored by the API server.
+const mirrorPodAnnotationKey = "kubernetes.io/config.mirror"
+
+// NodeDrainOptions configures node draining.
+type NodeDrainOptions struct {
+	// GracePeriod overrides the termination grace period of evicted pods.
+	// When zero, the grace period of the pod is used.
+	GracePeriod time.Duration
+
+	// Timeout is the maximum time spent draining a single node.
+	Timeout time.Duration
+
+	// RetryInterval is the time waited between eviction attempts.
+	// Evictions are retried when they would violate a PodDisruptionBudget.
+	RetryInterval time.Duration
+}
+
+// NodeDrainProgress is reported periodically while a node is being drained.
+type NodeDrainProgress struct {
+	NodeName      string
+	RemainingPods int
+}
+
+// NodeDrainer cordons nodes and evicts their pods respecting PodDisruptionBudgets.
+type NodeDrainer struct {
+	client  kubernetes.Interface
+	options NodeDrainOptions
+}
+
+// NewNodeDrainer returns a new NodeDrainer.
+func NewNodeDrainer(client kubernetes.Interface, options NodeDrainOptions) NodeDrainer {
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = 5 * time.Second
+	}
+
+	return NodeDrainer{
+		client:  client,
+		options: options,
+	}
+}
+
+// ListNodePoolNodes returns the names of the nodes that belong to a node pool.
+// Nodes that are already running the given node pool version are skipped unless the version is empty.
+func (d NodeDrainer) ListNodePoolNodes(nodePoolName string, skipVersion string) ([]string, error) {
+	selector := labels.SelectorFromSet(labels.Set{cluster.NodePoolNameLabelKey: nodePoolName})
+
+	nodes, err := d.client.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list node pool nodes", "nodePool", nodePoolName)
+	}
+
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		if skipVersion != "" && node.Labels[cluster.NodePoolVersionLabelKey] == skipVersion {
+			continue
+		}
+
+		nodeNames = append(nodeNames, node.Name)
+	}
+
+	return nodeNames, nil
+}
+
+// Cordon marks a node unschedulable and puts the draining taint on it.
+func (d NodeDrainer) Cordon(nodeName string) error {
+	return d.updateNode(nodeName, func(node *corev1.Node) bool {
+		if node.Spec.Unschedulable && hasDrainingTaint(node) {
+			return false
+		}
+
+		node.Spec.Unschedulable = true
+
+		if !hasDrainingTaint(node) {
+			node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+				Key:    DrainingTaintKey,
+				Effect: corev1.TaintEffectNoSchedule,
+			})
+		}
+
+		return true
+	})
+}
+
+// Uncordon marks a node schedulable and removes the draining taint from it.
+func (d NodeDrainer) Uncordon(nodeName string) error {
+	return d.updateNode(nodeName, func(node *corev1.Node) bool {
+		if !node.Spec.Unschedulable && !hasDrainingTaint(node) {
+			return false
+		}
+
+		node.Spec.Unschedulable = false
+
+		taints := node.Spec.Taints[:0]
+		for _, taint := range node.Spec.Taints {
+			if taint.Key != DrainingTaintKey {
+				taints = append(taints, taint)
+			}
+		}
+		node.Spec.Taints = taints
+
+		return true
+	})
+}
+
+func (d NodeDrainer) updateNode(nodeName string, update func(node *corev1.Node) bool) error {
+	err := clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		node, err := d.client.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if !update(node) {
+			return nil
+		}
+
+		_, err = d.client.CoreV1().Nodes().Update(node)
+
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to update node", "node", nodeName)
+}
+
+func hasDrainingTaint(node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == DrainingTaintKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Drain cordons a node and evicts every pod from it except DaemonSet and mirror pods.
+// Evictions blocked by a PodDisruptionBudget are retried until the drain timeout expires.
+func (d NodeDrainer) Drain(ctx context.Context, nodeName string, progress func(NodeDrainProgress)) error {
+	if err := d.Cordon(nodeName); err != nil {
+		return err
+	}
+
+	if d.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.options.Timeout)
+		defer cancel()
+	}
+
+	for {
+		pods, err := d.evictablePods(nodeName)
+		if err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(NodeDrainProgress{NodeName: nodeName, RemainingPods: len(pods)})
+		}
+
+		if len(pods) == 0 {
+			return nil
+		}
+
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+
+			err := d.evict(pod)
+			if apierrors.IsNotFound(err) || apierrors.IsTooManyRequests(err) {
+				// the pod is gone or its disruption budget does not allow eviction yet
+				continue
+			} else if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to evict pod", "node", nodeName, "namespace", pod.Namespace, "pod", pod.Name)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.WithDetails(errors.WithStack(ErrNodeDrainTimeout), "node", nodeName, "remainingPods", len(pods))
+			}
+
+			return ctx.Err()
+
+		case <-time.After(d.options.RetryInterval):
+		}
+	}
+}
+
+func (d NodeDrainer) evict(pod corev1.Pod) error {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+
+	if d.options.GracePeriod > 0 {
+		gracePeriodSeconds := int64(d.options.GracePeriod.Seconds())
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds}
+	}
+
+	return d.client.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction)
+}
+
+func (d NodeDrainer) evictablePods(nodeName string) ([]corev1.Pod, error) {
+	podList, err := d.client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list pods", "node", nodeName)
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range podList.Items {
+		if isEvictablePod(pod) {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+func isEvictablePod(pod corev1.Pod) bool {
+	if _, ok := pod.Annotations[mirrorPodAnnotationKey]; ok {
+		return false
+	}
+
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+
+	if controller := metav1.GetControllerOf(&pod); controller != nil && controller.Kind == "DaemonSet" {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+)
+
+func testNode(name string, nodePool string, version string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				cluster.NodePoolNameLabelKey:    nodePool,
+				cluster.NodePoolVersionLabelKey: version,
+			},
+		},
+	}
+}
+
+func testPod(name string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+// evictionReactor deletes evicted pods, rejecting the first few evictions of a pod like a PodDisruptionBudget would.
+func evictionReactor(client *fake.Clientset, rejections map[string]int) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+
+		if rejections[eviction.Name] > 0 {
+			rejections[eviction.Name]--
+
+			return true, nil, apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+		}
+
+		err := client.Tracker().Delete(schema.GroupVersionResource{Version: "v1", Resource: "pods"}, eviction.Namespace, eviction.Name)
+
+		return true, nil, err
+	}
+}
+
+func TestNodeDrainer_ListNodePoolNodes(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testNode("node1", "pool1", "v1"),
+		testNode("node2", "pool1", "v2"),
+		testNode("node3", "pool2", "v1"),
+	)
+
+	drainer := NewNodeDrainer(client, NodeDrainOptions{})
+
+	nodes, err := drainer.ListNodePoolNodes("pool1", "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"node1", "node2"}, nodes)
+
+	nodes, err = drainer.ListNodePoolNodes("pool1", "v2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node1"}, nodes)
+}
+
+func TestNodeDrainer_CordonUncordon(t *testing.T) {
+	node := testNode("node1", "pool1", "v1")
+	node.Spec.Taints = []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoExecute}}
+
+	client := fake.NewSimpleClientset(node)
+
+	drainer := NewNodeDrainer(client, NodeDrainOptions{})
+
+	require.NoError(t, drainer.Cordon("node1"))
+	require.NoError(t, drainer.Cordon("node1"))
+
+	node, err := client.CoreV1().Nodes().Get("node1", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(
+		t,
+		[]corev1.Taint{
+			{Key: "other", Effect: corev1.TaintEffectNoExecute},
+			{Key: DrainingTaintKey, Effect: corev1.TaintEffectNoSchedule},
+		},
+		node.Spec.Taints,
+	)
+
+	require.NoError(t, drainer.Uncordon("node1"))
+
+	node, err = client.CoreV1().Nodes().Get("node1", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.False(t, node.Spec.Unschedulable)
+	assert.Equal(t, []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoExecute}}, node.Spec.Taints)
+
+	// missing nodes are ignored
+	require.NoError(t, drainer.Cordon("node2"))
+}
+
+func TestNodeDrainer_Drain(t *testing.T) {
+	daemonSetPod := testPod("daemon", "node1")
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "DaemonSet", Name: "daemon", Controller: &[]bool{true}[0]},
+	}
+
+	mirrorPod := testPod("mirror", "node1")
+	mirrorPod.Annotations = map[string]string{mirrorPodAnnotationKey: "mirror"}
+
+	completedPod := testPod("completed", "node1")
+	completedPod.Status.Phase = corev1.PodSucceeded
+
+	client := fake.NewSimpleClientset(
+		testNode("node1", "pool1", "v1"),
+		testPod("app1", "node1"),
+		testPod("app2", "node1"),
+		daemonSetPod,
+		mirrorPod,
+		completedPod,
+	)
+	client.PrependReactor("create", "pods", evictionReactor(client, map[string]int{"app2": 2}))
+
+	drainer := NewNodeDrainer(client, NodeDrainOptions{
+		Timeout:       time.Second,
+		RetryInterval: time.Millisecond,
+	})
+
+	var progress []int
+
+	err := drainer.Drain(context.Background(), "node1", func(p NodeDrainProgress) {
+		progress = append(progress, p.RemainingPods)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{2, 1, 1, 0}, progress)
+
+	pods, err := client.CoreV1().Pods("default").List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 3)
+
+	node, err := client.CoreV1().Nodes().Get("node1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+}
+
+func TestNodeDrainer_Drain_Timeout(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testNode("node1", "pool1", "v1"),
+		testPod("app1", "node1"),
+	)
+	client.PrependReactor("create", "pods", evictionReactor(client, map[string]int{"app1": 1000}))
+
+	drainer := NewNodeDrainer(client, NodeDrainOptions{
+		Timeout:       50 * time.Millisecond,
+		RetryInterval: time.Millisecond,
+	})
+
+	err := drainer.Drain(context.Background(), "node1", nil)
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, ErrNodeDrainTimeout))
+}
@@ -0,0 +1,274 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// MasterNodeLabel is the label kubeadm puts on master nodes.
+const MasterNodeLabel = "node-role.kubernetes.io/master"
+
+// HostRootPath is the path the root file system of a node is mounted at by HostRootVolume.
+const HostRootPath = "/host"
+
+// podStartTimeout is the maximum time waited for a pod to start running.
+const podStartTimeout = 5 * time.Minute
+
+// KubeConfigGetter returns the Kubernetes configuration of clusters.
+type KubeConfigGetter interface {
+	// GetKubeConfig returns the Kubernetes configuration of a cluster.
+	GetKubeConfig(ctx context.Context, clusterID uint) (*rest.Config, error)
+}
+
+// NewClusterClient returns the Kubernetes configuration of a cluster and a client created from it.
+func NewClusterClient(ctx context.Context, kubeConfigs KubeConfigGetter, clusterID uint) (*rest.Config, kubernetes.Interface, error) {
+	config, err := kubeConfigs.GetKubeConfig(ctx, clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, errors.WrapIf(err, "failed to create Kubernetes client")
+	}
+
+	return config, client, nil
+}
+
+// NodePodSpec describes a pod running a single container on a specific node.
+type NodePodSpec struct {
+	Namespace    string
+	GenerateName string
+	Labels       map[string]string
+	NodeName     string
+
+	Container string
+	Image     string
+	Command   []string
+	Env       []corev1.EnvVar
+
+	// Privileged runs the container in privileged mode.
+	Privileged bool
+
+	// HostNetwork and HostPID run the pod in the network and PID namespaces of the node.
+	HostNetwork bool
+	HostPID     bool
+
+	Volumes []NodePodVolume
+}
+
+// NodePodVolume is a volume mounted into the container of a node pod.
+type NodePodVolume struct {
+	Name      string
+	MountPath string
+	ReadOnly  bool
+
+	// HostPath is the directory of the node mounted into the container.
+	// When empty, an empty directory is mounted.
+	HostPath string
+}
+
+// HostRootVolume returns a volume mounting the root file system of the node at HostRootPath.
+func HostRootVolume() NodePodVolume {
+	return NodePodVolume{Name: "root", MountPath: HostRootPath, HostPath: "/"}
+}
+
+// HostRootCommand returns a command running a shell script in the root file system of the node
+// (mounted with HostRootVolume), so that the script can use the binaries installed on the node.
+func HostRootCommand(script string) []string {
+	return []string{"chroot", HostRootPath, "sh", "-c", script}
+}
+
+// NewNodePod returns a pod that is never restarted.
+// The pod tolerates every taint, so that it can be scheduled on master and cordoned nodes as well.
+func NewNodePod(spec NodePodSpec) *corev1.Pod {
+	directory := corev1.HostPathDirectory
+
+	var mounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+
+	for _, v := range spec.Volumes {
+		mounts = append(mounts, corev1.VolumeMount{Name: v.Name, MountPath: v.MountPath, ReadOnly: v.ReadOnly})
+
+		source := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+		if v.HostPath != "" {
+			source = corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{
+				Path: v.HostPath,
+				Type: &directory,
+			}}
+		}
+
+		volumes = append(volumes, corev1.Volume{Name: v.Name, VolumeSource: source})
+	}
+
+	container := corev1.Container{
+		Name:                     spec.Container,
+		Image:                    spec.Image,
+		Command:                  spec.Command,
+		Env:                      spec.Env,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts:             mounts,
+	}
+
+	if spec.Privileged {
+		privileged := true
+		container.SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: spec.GenerateName,
+			Namespace:    spec.Namespace,
+			Labels:       spec.Labels,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      spec.NodeName,
+			HostNetwork:   spec.HostNetwork,
+			HostPID:       spec.HostPID,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers:    []corev1.Container{container},
+			Volumes:       volumes,
+		},
+	}
+}
+
+// Poll calls a condition immediately and then periodically
+// until it is done, it returns an error or the context is done.
+func Poll(ctx context.Context, interval time.Duration, condition func() (done bool, err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := condition()
+		if done || err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// WaitForPodRunning waits until a pod is running.
+// An error is returned if the pod stops or it does not start in time.
+func WaitForPodRunning(ctx context.Context, client kubernetes.Interface, namespace string, name string, pollInterval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, podStartTimeout)
+	defer cancel()
+
+	err := Poll(ctx, pollInterval, func() (bool, error) {
+		pod, err := client.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.WrapIfWithDetails(err, "failed to get pod", "pod", name)
+		}
+
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, errors.NewWithDetails("pod stopped unexpectedly: "+PodTerminationMessage(*pod), "pod", name)
+		}
+
+		return false, nil
+	})
+
+	if ctx.Err() != nil {
+		return errors.WrapIfWithDetails(err, "pod did not start in time", "pod", name)
+	}
+
+	return err
+}
+
+// IsPodCompleted tells whether a pod completed and returns its termination message as an error if it failed.
+// Errors of getting the pod are ignored, since pods managing the control plane may restart the API server.
+func IsPodCompleted(client kubernetes.Interface, namespace string, name string) (bool, error) {
+	pod, err := client.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return false, nil
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return true, nil
+
+	case corev1.PodFailed:
+		return true, errors.New(PodTerminationMessage(*pod))
+	}
+
+	return false, nil
+}
+
+// PodTerminationMessage returns the termination message of the first terminated container of a pod,
+// or the phase of the pod if none of its containers has one.
+func PodTerminationMessage(pod corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+			return strings.TrimSpace(status.State.Terminated.Message)
+		}
+	}
+
+	return string(pod.Status.Phase)
+}
+
+// ExecInPod executes a command in a container of a running pod.
+// Stdin and stdout are only attached when they are not nil, stderr is returned in the details of the error.
+func ExecInPod(config *rest.Config, client kubernetes.Interface, pod corev1.Pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := client.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	if err != nil {
+		return errors.WrapIf(err, "failed to create executor")
+	}
+
+	var stderr bytes.Buffer
+
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+
+	return errors.WrapIfWithDetails(err, "command failed", "stderr", strings.TrimSpace(stderr.String()))
+}
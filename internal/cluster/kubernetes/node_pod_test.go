@@ -0,0 +1,132 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func nodePod(name string, phase corev1.PodPhase, message string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+
+	if message != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message + "\n"}}},
+		}
+	}
+
+	return pod
+}
+
+func TestNewNodePod(t *testing.T) {
+	pod := NewNodePod(NodePodSpec{
+		Namespace:    "kube-system",
+		GenerateName: "upgrade-",
+		NodeName:     "master-0",
+		Container:    "upgrade",
+		Image:        "busybox",
+		Command:      HostRootCommand("echo"),
+		Privileged:   true,
+		HostPID:      true,
+		Volumes: []NodePodVolume{
+			HostRootVolume(),
+			{Name: "scratch", MountPath: "/scratch"},
+		},
+	})
+
+	assert.Equal(t, "master-0", pod.Spec.NodeName)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	assert.Equal(t, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, pod.Spec.Tolerations)
+	assert.True(t, pod.Spec.HostPID)
+	assert.False(t, pod.Spec.HostNetwork)
+
+	require.Len(t, pod.Spec.Containers, 1)
+
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, []string{"chroot", "/host", "sh", "-c", "echo"}, container.Command)
+	assert.True(t, *container.SecurityContext.Privileged)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "root", MountPath: "/host"},
+		{Name: "scratch", MountPath: "/scratch"},
+	}, container.VolumeMounts)
+
+	require.Len(t, pod.Spec.Volumes, 2)
+	assert.Equal(t, "/", pod.Spec.Volumes[0].HostPath.Path)
+	assert.NotNil(t, pod.Spec.Volumes[1].EmptyDir)
+
+	pod = NewNodePod(NodePodSpec{Container: "reader"})
+	assert.Nil(t, pod.Spec.Containers[0].SecurityContext)
+}
+
+func TestWaitForPodRunning(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		nodePod("running", corev1.PodRunning, ""),
+		nodePod("failed", corev1.PodFailed, "no space left on device"),
+		nodePod("pending", corev1.PodPending, ""),
+	)
+
+	err := WaitForPodRunning(context.Background(), client, "kube-system", "running", time.Millisecond)
+	assert.NoError(t, err)
+
+	err = WaitForPodRunning(context.Background(), client, "kube-system", "failed", time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no space left on device")
+
+	err = WaitForPodRunning(context.Background(), client, "kube-system", "missing", time.Millisecond)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = WaitForPodRunning(ctx, client, "kube-system", "pending", time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pod did not start in time")
+}
+
+func TestIsPodCompleted(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		nodePod("running", corev1.PodRunning, ""),
+		nodePod("succeeded", corev1.PodSucceeded, ""),
+		nodePod("failed", corev1.PodFailed, "kubeadm failed"),
+	)
+
+	done, err := IsPodCompleted(client, "kube-system", "running")
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	// The API server may be unavailable while the control plane is restarted
+	done, err = IsPodCompleted(client, "kube-system", "missing")
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	done, err = IsPodCompleted(client, "kube-system", "succeeded")
+	assert.True(t, done)
+	assert.NoError(t, err)
+
+	done, err = IsPodCompleted(client, "kube-system", "failed")
+	assert.True(t, done)
+	assert.EqualError(t, err, "kubeadm failed")
+}
@@ -201,6 +201,9 @@ type Service interface {
 
 	// DeleteNodePool deletes a node pool from a cluster.
 	DeleteNodePool(ctx context.Context, clusterID uint, name string) (deleted bool, err error)
+
+	// UpgradeCluster upgrades the Kubernetes version of a cluster.
+	UpgradeCluster(ctx context.Context, clusterID uint, version string) (processID string, err error)
 }
 
 // DeleteClusterOptions represents cluster deletion options.
@@ -298,6 +301,25 @@ func (s service) DeleteCluster(ctx context.Context, clusterIdentifier Identifier
 	return false, nil
 }
 
+// UpgradeCluster upgrades the Kubernetes version of a cluster.
+func (s service) UpgradeCluster(ctx context.Context, clusterID uint, version string) (string, error) {
+	c, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkCluster(c); err != nil {
+		return "", err
+	}
+
+	service, err := s.getDistributionService(c)
+	if err != nil {
+		return "", err
+	}
+
+	return service.UpgradeCluster(ctx, clusterID, version)
+}
+
 // NotSupportedDistributionError is returned if an API does not support a certain distribution.
 type NotSupportedDistributionError struct {
 	ID           uint
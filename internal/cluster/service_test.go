@@ -0,0 +1,87 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_UpgradeCluster(t *testing.T) {
+	cluster := Cluster{
+		ID:            1,
+		UID:           "1",
+		Name:          "cluster",
+		Status:        Running,
+		StatusMessage: RunningMessage,
+		Cloud:         "amazon",
+		Distribution:  "eks",
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+
+		clusterStore := new(MockStore)
+		clusterStore.On("GetCluster", ctx, cluster.ID).Return(cluster, nil)
+
+		distributionService := new(MockService)
+		distributionService.On("UpgradeCluster", ctx, cluster.ID, "1.15").Return("process-id", nil)
+
+		service := NewService(clusterStore, nil, nil, map[string]Service{"eks": distributionService}, nil, nil, nil, nil)
+
+		processID, err := service.UpgradeCluster(ctx, cluster.ID, "1.15")
+		require.NoError(t, err)
+
+		assert.Equal(t, "process-id", processID)
+
+		clusterStore.AssertExpectations(t)
+		distributionService.AssertExpectations(t)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		ctx := context.Background()
+
+		updatingCluster := cluster
+		updatingCluster.Status = Updating
+
+		clusterStore := new(MockStore)
+		clusterStore.On("GetCluster", ctx, cluster.ID).Return(updatingCluster, nil)
+
+		service := NewService(clusterStore, nil, nil, map[string]Service{}, nil, nil, nil, nil)
+
+		_, err := service.UpgradeCluster(ctx, cluster.ID, "1.15")
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &NotReadyError{}))
+	})
+
+	t.Run("DistributionNotSupported", func(t *testing.T) {
+		ctx := context.Background()
+
+		clusterStore := new(MockStore)
+		clusterStore.On("GetCluster", ctx, cluster.ID).Return(cluster, nil)
+
+		service := NewService(clusterStore, nil, nil, map[string]Service{}, nil, nil, nil, nil)
+
+		_, err := service.UpgradeCluster(ctx, cluster.ID, "1.15")
+		require.Error(t, err)
+
+		assert.True(t, errors.As(err, &NotSupportedDistributionError{}))
+	})
+}
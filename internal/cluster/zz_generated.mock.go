@@ -90,3 +90,24 @@ func (_m *MockService) UpdateNodePool(ctx context.Context, clusterID uint, nodeP
 
 	return r0, r1
 }
+
+// UpgradeCluster provides a mock function.
+func (_m *MockService) UpgradeCluster(ctx context.Context, clusterID uint, version string) (processID string, err error) {
+	ret := _m.Called(ctx, clusterID, version)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) string); ok {
+		r0 = rf(ctx, clusterID, version)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(ctx, clusterID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -22,20 +22,30 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
 	"github.com/banzaicloud/pipeline/internal/cluster/clusterconfig"
+	"github.com/banzaicloud/pipeline/internal/cluster/distribution/pke"
+	"github.com/banzaicloud/pipeline/internal/cluster/etcdbackup"
 	"github.com/banzaicloud/pipeline/internal/federation"
 	"github.com/banzaicloud/pipeline/internal/helm"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/dns"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/ingress"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/logging"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/monitoring"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services/policy"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/vault"
 	"github.com/banzaicloud/pipeline/internal/istio/istiofeature"
+	"github.com/banzaicloud/pipeline/internal/metricsfederation"
 	"github.com/banzaicloud/pipeline/internal/platform/cadence"
 	"github.com/banzaicloud/pipeline/internal/platform/database"
 	"github.com/banzaicloud/pipeline/internal/platform/errorhandler"
 	"github.com/banzaicloud/pipeline/internal/platform/log"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry"
 	"github.com/banzaicloud/pipeline/pkg/cluster"
 	"github.com/banzaicloud/pipeline/pkg/values"
 )
@@ -84,6 +94,9 @@ type Config struct {
 			Amazon struct {
 				GlobalRegion string
 			}
+
+			// In-place Kubernetes version upgrades
+			Upgrade pke.UpgradeConfig
 		}
 	}
 
@@ -107,9 +120,17 @@ type Config struct {
 	Log log.Config
 
 	Secret struct {
+		// Store configures where organization secrets are stored
+		Store SecretStoreConfig
+
 		TLS struct {
 			DefaultValidity time.Duration
 		}
+
+		Rotation rotation.Config
+
+		// Bindings of Pipeline secrets to Kubernetes secrets
+		Binding binding.Config
 	}
 
 	// Telemetry configuration
@@ -127,12 +148,19 @@ func (c Config) Validate() error {
 
 	err = errors.Append(err, c.Database.Validate())
 
+	err = errors.Append(err, c.Distribution.PKE.Upgrade.Validate())
+
 	err = errors.Append(err, c.Errors.Validate())
 
 	err = errors.Append(err, c.Telemetry.Validate())
 
 	err = errors.Append(err, c.Helm.Validate())
 
+	err = errors.Append(err, c.Secret.Store.Validate())
+
+	err = errors.Append(err, c.Secret.Rotation.Validate())
+	err = errors.Append(err, c.Secret.Binding.Validate())
+
 	return err
 }
 
@@ -164,21 +192,34 @@ type ClusterConfig struct {
 
 	Backyards istiofeature.StaticConfig
 
+	// Control plane certificates of PKE clusters
+	Certificates clustercert.Config
+
 	DisasterRecovery ClusterDisasterRecoveryConfig
 
 	DNS ClusterDNSConfig
 
+	Drain ClusterDrainConfig
+
+	// Snapshots of the etcd of PKE clusters
+	EtcdBackup etcdbackup.Config
+
 	Expiry ClusterExpiryConfig
 
 	Federation federation.StaticConfig
 
 	Ingress ClusterIngressConfig
 
+	// Kubeconfigs issued by Pipeline
+	Kubeconfig clusteraccess.Config
+
 	Labels clusterconfig.LabelConfig
 
 	// Initial manifest
 	Manifest string
 
+	MetricsFederation metricsfederation.StaticConfig
+
 	Monitoring ClusterMonitoringConfig
 
 	Logging ClusterLoggingConfig
@@ -186,6 +227,8 @@ type ClusterConfig struct {
 	// Namespace to install Pipeline components to
 	Namespace string
 
+	Policy ClusterPolicyConfig
+
 	// Posthook configs
 	PostHook cluster.PostHookConfig
 
@@ -198,10 +241,18 @@ type ClusterConfig struct {
 func (c ClusterConfig) Validate() error {
 	var errs error
 
+	errs = errors.Append(errs, c.Certificates.Validate())
+
 	errs = errors.Append(errs, c.DNS.Validate())
 
+	errs = errors.Append(errs, c.Drain.Validate())
+
+	errs = errors.Append(errs, c.EtcdBackup.Validate())
+
 	errs = errors.Append(errs, c.Ingress.Validate())
 
+	errs = errors.Append(errs, c.Kubeconfig.Validate())
+
 	errs = errors.Append(errs, c.Labels.Validate())
 
 	errs = errors.Append(errs, c.Logging.Validate())
@@ -220,6 +271,8 @@ func (c ClusterConfig) Validate() error {
 		errs = errors.Append(errs, errors.New("cluster namespace is required"))
 	}
 
+	errs = errors.Append(errs, c.Policy.Validate())
+
 	errs = errors.Append(errs, c.SecurityScan.Validate())
 
 	errs = errors.Append(errs, c.Vault.Validate())
@@ -341,6 +394,29 @@ func (c ClusterDNSConfig) Validate() error {
 	return errs
 }
 
+// ClusterDrainConfig contains node draining configuration.
+type ClusterDrainConfig struct {
+	// Overrides the termination grace period of evicted pods (the pod's own is used when zero)
+	GracePeriod time.Duration
+
+	// Maximum time spent draining a single node
+	Timeout time.Duration
+}
+
+func (c ClusterDrainConfig) Validate() error {
+	var errs error
+
+	if c.GracePeriod < 0 {
+		errs = errors.Append(errs, errors.New("cluster drain grace period cannot be negative"))
+	}
+
+	if c.Timeout <= 0 {
+		errs = errors.Append(errs, errors.New("cluster drain timeout must be positive"))
+	}
+
+	return errs
+}
+
 type ClusterExpiryConfig struct {
 	Enabled bool
 }
@@ -400,11 +476,32 @@ func (c ClusterMonitoringConfig) Validate() error {
 	return errs
 }
 
+// ClusterPolicyConfig contains cluster policy enforcement configuration.
+type ClusterPolicyConfig struct {
+	Enabled bool
+
+	policy.Config `mapstructure:",squash"`
+}
+
+func (c ClusterPolicyConfig) Validate() error {
+	var errs error
+
+	if c.Enabled {
+		errs = errors.Append(errs, c.Config.Validate())
+	}
+
+	return errs
+}
+
 // ClusterSecurityScanConfig contains cluster security scan configuration.
 type ClusterSecurityScanConfig struct {
 	Enabled bool
 
 	securityscan.Config `mapstructure:",squash"`
+
+	Report vulnreport.Config
+
+	WhitelistExpiry whitelistexpiry.Config
 }
 
 func (c ClusterSecurityScanConfig) Validate() error {
@@ -412,6 +509,7 @@ func (c ClusterSecurityScanConfig) Validate() error {
 
 	if c.Enabled {
 		err = errors.Append(err, c.Config.Validate())
+		err = errors.Append(err, c.WhitelistExpiry.Validate())
 	}
 
 	return err
@@ -629,6 +727,20 @@ ssl:
   enabled: true
   generateTLS: true
 `)
+	v.SetDefault("cluster::ingress::charts::nginx::chart", "ingress-nginx/ingress-nginx")
+	v.SetDefault("cluster::ingress::charts::nginx::version", "2.11.1")
+	v.SetDefault("cluster::ingress::charts::nginx::values", `
+controller:
+  publishService:
+    enabled: true
+`)
+	v.SetDefault("cluster::ingress::charts::certManager::chart", "jetstack/cert-manager")
+	v.SetDefault("cluster::ingress::charts::certManager::version", "v0.15.2")
+	v.SetDefault("cluster::ingress::charts::certManager::values", `
+installCRDs: true
+`)
+	v.SetDefault("cluster::ingress::certManager::namespace", "cert-manager")
+	v.SetDefault("cluster::ingress::certManager::releaseName", "ingress-cert-manager")
 	v.SetDefault("cluster::ingress::cert::source", "file")
 	v.SetDefault("cluster::ingress::cert::path", "config/certs")
 
@@ -683,9 +795,20 @@ ssl:
 	v.SetDefault("cluster::securityScan::anchore::password", "")
 	v.SetDefault("cluster::securityScan::anchore::insecure", false)
 	v.SetDefault("cluster::securityScan::webhook::chart", "banzaicloud-stable/anchore-policy-validator")
-	v.SetDefault("cluster::securityScan::webhook::version", "0.5.8")
+	v.SetDefault("cluster::securityScan::webhook::version", securityscan.TrivyWebhookChartMinVersion)
 	v.SetDefault("cluster::securityScan::webhook::release", "anchore")
 	v.SetDefault("cluster::securityScan::webhook::namespace", "pipeline-system")
+	v.SetDefault("cluster::securityScan::report::cacheTTL", 10*time.Minute)
+	v.SetDefault("cluster::securityScan::report::trendDays", 30)
+	v.SetDefault("cluster::securityScan::report::scanConcurrency", 10)
+	v.SetDefault("cluster::securityScan::whitelistExpiry::enabled", true)
+	v.SetDefault("cluster::securityScan::whitelistExpiry::schedule", "0 * * * *")
+	v.SetDefault("cluster::securityScan::whitelistExpiry::notifyBefore", 72*time.Hour)
+	v.SetDefault("cluster::securityScan::trivy::chart", "aquasecurity/trivy")
+	v.SetDefault("cluster::securityScan::trivy::version", "0.2.0")
+	v.SetDefault("cluster::securityScan::trivy::release", "trivy")
+	v.SetDefault("cluster::securityScan::trivy::namespace", "pipeline-system")
+	v.SetDefault("cluster::securityScan::trivy::values", map[string]interface{}{})
 	// v.SetDefault("cluster::securityScan::webhook::values", map[string]interface{}{
 	//	"image": map[string]interface{}{
 	//		"repository": "banzaicloud/ark",
@@ -694,8 +817,29 @@ ssl:
 	//	},
 	// })
 
+	v.SetDefault("cluster::kubeconfig::defaultTTL", 8*time.Hour)
+	v.SetDefault("cluster::kubeconfig::maxTTL", 24*time.Hour)
+
+	v.SetDefault("cluster::policy::enabled", true)
+	v.SetDefault("cluster::policy::namespace", "gatekeeper-system")
+	v.SetDefault("cluster::policy::charts::gatekeeper::chart", "gatekeeper/gatekeeper")
+	v.SetDefault("cluster::policy::charts::gatekeeper::version", "3.1.0")
+	v.SetDefault("cluster::policy::charts::gatekeeper::values", map[string]interface{}{})
+
 	v.SetDefault("cluster::expiry::enabled", true)
 
+	v.SetDefault("cluster::drain::gracePeriod", 0)
+	v.SetDefault("cluster::drain::timeout", 10*time.Minute)
+
+	v.SetDefault("cluster::etcdBackup::enabled", true)
+	v.SetDefault("cluster::etcdBackup::schedule", "*/10 * * * *")
+	v.SetDefault("cluster::etcdBackup::restoreImage", "k8s.gcr.io/etcd:3.4.3-0")
+
+	v.SetDefault("cluster::certificates::enabled", true)
+	v.SetDefault("cluster::certificates::schedule", "0 6 * * *")
+	v.SetDefault("cluster::certificates::warningThreshold", 30*24*time.Hour)
+	v.SetDefault("cluster::certificates::image", "busybox:1.31")
+
 	// ingress controller config
 	v.SetDefault("cluster::posthook::ingress::enabled", true)
 	v.SetDefault("cluster::posthook::ingress::chart", "banzaicloud-stable/pipeline-cluster-ingress")
@@ -801,6 +945,23 @@ traefik:
 		},
 	})
 
+	v.SetDefault("cluster::metricsFederation::charts::prometheus::chart", "stable/prometheus")
+	v.SetDefault("cluster::metricsFederation::charts::prometheus::version", "11.0.4")
+	v.SetDefault("cluster::metricsFederation::charts::prometheus::values", map[string]interface{}{
+		"alertmanager": map[string]interface{}{
+			"enabled": false,
+		},
+		"kubeStateMetrics": map[string]interface{}{
+			"enabled": false,
+		},
+		"nodeExporter": map[string]interface{}{
+			"enabled": false,
+		},
+		"pushgateway": map[string]interface{}{
+			"enabled": false,
+		},
+	})
+
 	// Helm configuration
 	v.SetDefault("helm::tiller::version", "v2.16.3")
 	v.SetDefault("helm::home", "./var/cache")
@@ -808,6 +969,10 @@ traefik:
 	v.SetDefault("helm::repositories::stable", "https://kubernetes-charts.storage.googleapis.com")
 	v.SetDefault("helm::repositories::banzaicloud-stable", "https://kubernetes-charts.banzaicloud.com")
 	v.SetDefault("helm::repositories::loki", "https://grafana.github.io/loki/charts")
+	v.SetDefault("helm::repositories::ingress-nginx", "https://kubernetes.github.io/ingress-nginx")
+	v.SetDefault("helm::repositories::jetstack", "https://charts.jetstack.io")
+	v.SetDefault("helm::repositories::aquasecurity", "https://aquasecurity.github.io/helm-charts")
+	v.SetDefault("helm::repositories::gatekeeper", "https://open-policy-agent.github.io/gatekeeper/charts")
 
 	// Cloud configuration
 	v.SetDefault("cloud::amazon::defaultRegion", "us-west-1")
@@ -818,12 +983,23 @@ traefik:
 	v.SetDefault("distribution::eks::ssh::generate", true)
 
 	v.SetDefault("distribution::pke::amazon::globalRegion", "us-east-1")
+	v.SetDefault("distribution::pke::upgrade::image", "busybox:1.31")
+	v.SetDefault("distribution::pke::upgrade::nodeTimeout", 20*time.Minute)
+	v.SetDefault("distribution::pke::upgrade::healthCheckTimeout", 10*time.Minute)
 
 	v.SetDefault("cloudinfo::endpoint", "")
 	v.SetDefault("hollowtrees::endpoint", "")
 	v.SetDefault("hollowtrees::tokenSigningKey", "")
 
+	v.SetDefault("secret::store::backend", SecretStoreBackendVault)
+	v.SetDefault("secret::store::sql::kek", "")
+	v.SetDefault("secret::store::kubernetes::namespace", "pipeline-system")
+	v.SetDefault("secret::store::kubernetes::kubeconfig", "")
 	v.SetDefault("secret::tls::defaultValidity", "8760h") // 1 year
+	v.SetDefault("secret::rotation::enabled", false)
+	v.SetDefault("secret::rotation::schedule", "0 * * * *")
+	v.SetDefault("secret::binding::enabled", true)
+	v.SetDefault("secret::binding::schedule", "*/5 * * * *")
 
 	// Telemetry configuration
 	v.SetDefault("telemetry::enabled", false)
@@ -76,6 +76,17 @@ func TestConfigure_DefaultValueBinding(t *testing.T) {
 						"traefik",
 					},
 					Charts: ingress.ChartsConfig{
+						Nginx: ingress.NginxChartConfig{
+							Chart:   "ingress-nginx/ingress-nginx",
+							Version: "2.11.1",
+							Values: values.Config(map[string]interface{}{
+								"controller": map[string]interface{}{
+									"publishService": map[string]interface{}{
+										"enabled": true,
+									},
+								},
+							}),
+						},
 						Traefik: ingress.TraefikChartConfig{
 							Chart:   "stable/traefik",
 							Version: "1.86.2",
@@ -86,6 +97,17 @@ func TestConfigure_DefaultValueBinding(t *testing.T) {
 								},
 							}),
 						},
+						CertManager: ingress.CertManagerChartConfig{
+							Chart:   "jetstack/cert-manager",
+							Version: "v0.15.2",
+							Values: values.Config(map[string]interface{}{
+								"installCRDs": true,
+							}),
+						},
+					},
+					CertManager: ingress.CertManagerConfig{
+						Namespace:   "cert-manager",
+						ReleaseName: "ingress-cert-manager",
 					},
 				},
 				Cert: struct {
@@ -0,0 +1,148 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/bank-vaults/pkg/sdk/vault"
+	"github.com/jinzhu/gorm"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/helm/helmadapter"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+	"github.com/banzaicloud/pipeline/internal/secret/usage/usageadapter"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+)
+
+// Secret store backends.
+const (
+	SecretStoreBackendVault      = "vault"
+	SecretStoreBackendSQL        = "sql"
+	SecretStoreBackendKubernetes = "kubernetes"
+)
+
+// SecretStoreConfig contains configuration for the store of organization secrets.
+type SecretStoreConfig struct {
+	// Backend is one of vault, sql or kubernetes
+	Backend string
+
+	SQL struct {
+		// KEK is the base64 encoded, 32 bytes long key encryption key
+		KEK string
+	}
+
+	Kubernetes struct {
+		Namespace string
+
+		// Kubeconfig is the path of a kubeconfig file (in-cluster configuration is used when empty)
+		Kubeconfig string
+	}
+}
+
+// Validate validates the configuration.
+func (c SecretStoreConfig) Validate() error {
+	return c.validateBackend(c.Backend)
+}
+
+func (c SecretStoreConfig) validateBackend(backend string) error {
+	switch backend {
+	case SecretStoreBackendVault:
+		return nil
+
+	case SecretStoreBackendSQL:
+		if c.SQL.KEK == "" {
+			return errors.New("secret store key encryption key is required for the sql backend")
+		}
+
+		_, err := secretadapter.ParseKEK(c.SQL.KEK)
+
+		return err
+
+	case SecretStoreBackendKubernetes:
+		if c.Kubernetes.Namespace == "" {
+			return errors.New("secret store namespace is required for the kubernetes backend")
+		}
+
+		return nil
+
+	default:
+		return errors.NewWithDetails("unknown secret store backend", "backend", backend)
+	}
+}
+
+// CreateSecretStore utility function for assembling the secret store of a backend
+// The Vault client is only used (and required) by the vault backend.
+func CreateSecretStore(backend string, config SecretStoreConfig, vaultClient *vault.Client, db *gorm.DB) (secret.Store, error) {
+	if err := config.validateBackend(backend); err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case SecretStoreBackendSQL:
+		kek, err := secretadapter.ParseKEK(config.SQL.KEK)
+		if err != nil {
+			return nil, err
+		}
+
+		return secretadapter.NewSQLStore(db, kek)
+
+	case SecretStoreBackendKubernetes:
+		var client kubernetes.Interface
+		var err error
+
+		if config.Kubernetes.Kubeconfig != "" {
+			kubeconfig, rerr := ioutil.ReadFile(config.Kubernetes.Kubeconfig)
+			if rerr != nil {
+				return nil, errors.Wrap(rerr, "failed to read kubeconfig")
+			}
+
+			client, err = k8sclient.NewClientFromKubeConfig(kubeconfig)
+		} else {
+			client, err = k8sclient.NewInClusterClient()
+		}
+
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to create Kubernetes client")
+		}
+
+		return secretadapter.NewKubernetesStore(client, config.Kubernetes.Namespace), nil
+
+	default:
+		if vaultClient == nil {
+			return nil, errors.New("vault client is required for the vault backend")
+		}
+
+		return secretadapter.NewVaultStore(vaultClient, "secret"), nil
+	}
+}
+
+// CreateSecretUsageIndex utility function for assembling the index of resources referencing secrets
+func CreateSecretUsageIndex(db *gorm.DB, logger common.Logger) usage.Index {
+	clusters := clusteradapter.NewClusters(db)
+
+	return usage.NewIndex(
+		usageadapter.NewClusterSource(clusters),
+		usageadapter.NewHelmRepositorySource(helmadapter.NewHelmRepoStore(db, logger)),
+		usageadapter.NewIntegratedServiceSource(clusters, integratedserviceadapter.NewGormIntegratedServiceRepository(db, logger)),
+		usageadapter.NewBucketSource(db),
+	)
+}
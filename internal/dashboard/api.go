@@ -31,10 +31,12 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 
 	clusterAuth "github.com/banzaicloud/pipeline/internal/cluster/auth"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
 	"github.com/banzaicloud/pipeline/internal/cluster/oidc"
 	"github.com/banzaicloud/pipeline/internal/cluster/resourcesummary"
 	"github.com/banzaicloud/pipeline/internal/clustergroup"
 	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	"github.com/banzaicloud/pipeline/pkg/k8sutil"
@@ -51,6 +53,8 @@ type DashboardAPI struct {
 
 	authConfig         auth.Config
 	clientSecretGetter clusterAuth.ClusterClientSecretGetter
+
+	certificateExpiries clustercert.ExpiryGetter
 }
 
 func NewDashboardAPI(
@@ -60,6 +64,7 @@ func NewDashboardAPI(
 	errorHandler emperror.Handler,
 	authConfig auth.Config,
 	clientSecretGetter clusterAuth.ClusterClientSecretGetter,
+	certificateExpiries clustercert.ExpiryGetter,
 ) *DashboardAPI {
 	return &DashboardAPI{
 		clusterManager:      clusterManager,
@@ -68,6 +73,7 @@ func NewDashboardAPI(
 		errorHandler:        errorHandler,
 		authConfig:          authConfig,
 		clientSecretGetter:  clientSecretGetter,
+		certificateExpiries: certificateExpiries,
 	}
 }
 
@@ -221,6 +227,15 @@ func (d *DashboardAPI) getClusterDashboardInfo(logger *logrus.Entry, commonClust
 		}
 	}
 
+	if clusterInfo.Distribution == pkgCluster.PKE {
+		expiry, err := d.certificateExpiries.GetExpiry(context.Background(), commonCluster.GetID())
+		if err != nil {
+			d.logger.Warn(err.Error())
+		} else {
+			clusterInfo.Certificates = expiry
+		}
+	}
+
 	endPoint, err := commonCluster.GetAPIEndpoint()
 	if err != nil {
 		d.logger.Warn(err.Error())
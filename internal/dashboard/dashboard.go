@@ -16,6 +16,8 @@ package dashboard
 
 import (
 	"time"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
 )
 
 type Allocatable struct {
@@ -101,6 +103,7 @@ type ClusterInfo struct {
 	StorageUsagePercent float64             `json:"storageUsagePercent"`
 	MemoryUsagePercent  float64             `json:"memoryUsagePercent"`
 	OIDC                OIDC                `json:"oidc"`
+	Certificates        *clustercert.Expiry `json:"certificates,omitempty"`
 }
 
 type OIDC struct {
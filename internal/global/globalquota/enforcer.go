@@ -0,0 +1,43 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalquota
+
+import (
+	"sync"
+
+	"github.com/banzaicloud/pipeline/internal/quota"
+)
+
+// nolint: gochecknoglobals
+var enforcer quota.Enforcer = quota.NewNopEnforcer()
+
+// nolint: gochecknoglobals
+var enforcerMu sync.Mutex
+
+// Enforcer returns a global quota enforcer.
+func Enforcer() quota.Enforcer {
+	enforcerMu.Lock()
+	defer enforcerMu.Unlock()
+
+	return enforcer
+}
+
+// SetEnforcer configures a global quota enforcer.
+func SetEnforcer(e quota.Enforcer) {
+	enforcerMu.Lock()
+	defer enforcerMu.Unlock()
+
+	enforcer = e
+}
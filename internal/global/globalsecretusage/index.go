@@ -0,0 +1,43 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globalsecretusage
+
+import (
+	"sync"
+
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+)
+
+// nolint: gochecknoglobals
+var index = usage.NewIndex()
+
+// nolint: gochecknoglobals
+var indexMu sync.Mutex
+
+// Index returns a global secret usage index.
+func Index() usage.Index {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	return index
+}
+
+// SetIndex configures a global secret usage index.
+func SetIndex(i usage.Index) {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	index = i
+}
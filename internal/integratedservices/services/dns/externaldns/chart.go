@@ -24,18 +24,21 @@ const (
 
 // ChartValues describes external-dns helm chart values (https://hub.helm.sh/charts/stable/external-dns)
 type ChartValues struct {
-	Sources       []string          `json:"sources,omitempty"`
-	RBAC          *RBACSettings     `json:"rbac,omitempty"`
-	Image         *ImageSettings    `json:"image,omitempty"`
-	DomainFilters []string          `json:"domainFilters,omitempty"`
-	Policy        string            `json:"policy,omitempty"`
-	TXTOwnerID    string            `json:"txtOwnerId,omitempty"`
-	ExtraArgs     map[string]string `json:"extraArgs,omitempty"`
-	TXTPrefix     string            `json:"txtPrefix,omitempty"`
-	Azure         *AzureSettings    `json:"azure,omitempty"`
-	AWS           *AWSSettings      `json:"aws,omitempty"`
-	Google        *GoogleSettings   `json:"google,omitempty"`
-	Provider      string            `json:"provider"`
+	Sources       []string              `json:"sources,omitempty"`
+	RBAC          *RBACSettings         `json:"rbac,omitempty"`
+	Image         *ImageSettings        `json:"image,omitempty"`
+	DomainFilters []string              `json:"domainFilters,omitempty"`
+	Policy        string                `json:"policy,omitempty"`
+	TXTOwnerID    string                `json:"txtOwnerId,omitempty"`
+	ExtraArgs     map[string]string     `json:"extraArgs,omitempty"`
+	TXTPrefix     string                `json:"txtPrefix,omitempty"`
+	Azure         *AzureSettings        `json:"azure,omitempty"`
+	AWS           *AWSSettings          `json:"aws,omitempty"`
+	Google        *GoogleSettings       `json:"google,omitempty"`
+	Cloudflare    *CloudflareSettings   `json:"cloudflare,omitempty"`
+	DigitalOcean  *DigitalOceanSettings `json:"digitalocean,omitempty"`
+	RFC2136       *RFC2136Settings      `json:"rfc2136,omitempty"`
+	Provider      string                `json:"provider"`
 }
 
 type RBACSettings struct {
@@ -75,3 +78,25 @@ type GoogleSettings struct {
 	ServiceAccountSecret string `json:"serviceAccountSecret"`
 	ServiceAccountKey    string `json:"serviceAccountKey"`
 }
+
+type CloudflareSettings struct {
+	APIKey     string `json:"apiKey,omitempty"`
+	Email      string `json:"email,omitempty"`
+	SecretName string `json:"secretName,omitempty"`
+	Proxied    bool   `json:"proxied"`
+}
+
+type DigitalOceanSettings struct {
+	APIToken   string `json:"apiToken,omitempty"`
+	SecretName string `json:"secretName,omitempty"`
+}
+
+type RFC2136Settings struct {
+	Host          string `json:"host"`
+	Port          uint   `json:"port"`
+	Zone          string `json:"zone"`
+	TSIGSecret    string `json:"tsigSecret,omitempty"`
+	TSIGSecretAlg string `json:"tsigSecretAlg,omitempty"`
+	TSIGKeyname   string `json:"tsigKeyname,omitempty"`
+	TSIGAxfr      bool   `json:"tsigAxfr"`
+}
@@ -0,0 +1,165 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services/dns/externaldns"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/src/auth"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+func TestIntegration(t *testing.T) {
+	if m := flag.Lookup("test.run").Value.String(); m == "" || !regexp.MustCompile(m).MatchString(t.Name()) {
+		t.Skip("skipping as execution was not requested explicitly using go test -run")
+	}
+
+	t.Run("RFC2136", testIntegrationRFC2136)
+}
+
+// testIntegrationRFC2136 checks that the external-dns RFC2136 settings rendered by the operator
+// can be used to update records on a BIND server.
+//
+// Start the bind service from docker-compose.override.yml.dist and run the test with:
+//
+//	RFC2136_HOST=127.0.0.1 RFC2136_PORT=5353 RFC2136_ZONE=example.org \
+//	TSIG_KEY_NAME=externaldns-key TSIG_SECRET=<secret from etc/config/bind/named.conf> \
+//	go test -run ^TestIntegration$ ./internal/integratedservices/services/dns/
+func testIntegrationRFC2136(t *testing.T) {
+	host := os.Getenv("RFC2136_HOST")
+	if host == "" {
+		t.Skip("skipping as RFC2136_HOST is not explicitly defined")
+	}
+
+	nsupdate, err := exec.LookPath("nsupdate")
+	if err != nil {
+		t.Skip("skipping as nsupdate is not installed")
+	}
+
+	var port uint
+	if p := os.Getenv("RFC2136_PORT"); p != "" {
+		v, err := strconv.ParseUint(p, 10, 16)
+		require.NoError(t, err)
+
+		port = uint(v)
+	}
+
+	clusterID := uint(1)
+	orgID := uint(1)
+
+	orgSecretStore := dummyOrganizationalSecretStore{
+		Secrets: map[uint]map[string]*secret.SecretItemResponse{
+			orgID: {
+				"rfc2136": {
+					Type: secrettype.RFC2136SecretType,
+					Values: map[string]string{
+						secrettype.RFC2136TSIGKeyName:   os.Getenv("TSIG_KEY_NAME"),
+						secrettype.RFC2136TSIGSecret:    os.Getenv("TSIG_SECRET"),
+						secrettype.RFC2136TSIGSecretAlg: os.Getenv("TSIG_SECRET_ALG"),
+					},
+				},
+			},
+		},
+	}
+	clusterGetter := dummyClusterGetter{
+		Clusters: map[uint]dummyCluster{
+			clusterID: {
+				OrgID:  orgID,
+				Status: pkgCluster.Running,
+			},
+		},
+	}
+	secretStore := commonadapter.NewSecretStore(orgSecretStore, commonadapter.OrgIDContextExtractorFunc(auth.GetCurrentOrganizationID))
+	op := MakeIntegratedServiceOperator(clusterGetter, nil, nil, services.NoopLogger{}, nil, secretStore, Config{})
+
+	spec := dnsIntegratedServiceSpec{
+		ClusterDomain: clusterDomainSpec(os.Getenv("RFC2136_ZONE")),
+		ExternalDNS: externalDNSSpec{
+			Provider: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "rfc2136",
+				Options: &providerOptions{
+					RFC2136Host: host,
+					RFC2136Port: port,
+					RFC2136Zone: os.Getenv("RFC2136_ZONE"),
+				},
+			},
+		},
+	}
+	require.NoError(t, spec.Validate())
+
+	ctx := auth.SetCurrentOrganizationID(context.Background(), orgID)
+
+	rawValues, err := op.getChartValues(ctx, clusterID, spec)
+	require.NoError(t, err)
+
+	var values externaldns.ChartValues
+	require.NoError(t, json.Unmarshal(rawValues, &values))
+	require.NotNil(t, values.RFC2136)
+
+	settings := *values.RFC2136
+	record := fmt.Sprintf("pipeline-integration-%d.%s", time.Now().Unix(), settings.Zone)
+
+	runNSUpdate := func(command string) {
+		script := strings.Join([]string{
+			fmt.Sprintf("server %s %d", settings.Host, settings.Port),
+			fmt.Sprintf("zone %s", settings.Zone),
+			command,
+			"send",
+		}, "\n") + "\n"
+
+		cmd := exec.Command(nsupdate, "-y", fmt.Sprintf("%s:%s:%s", settings.TSIGSecretAlg, settings.TSIGKeyname, settings.TSIGSecret))
+		cmd.Stdin = strings.NewReader(script)
+
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	runNSUpdate(fmt.Sprintf("update add %s 60 TXT \"pipeline\"", record))
+	defer runNSUpdate(fmt.Sprintf("update delete %s TXT", record))
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+
+			return dialer.DialContext(ctx, network, net.JoinHostPort(settings.Host, strconv.Itoa(int(settings.Port))))
+		},
+	}
+
+	txts, err := resolver.LookupTXT(context.Background(), record)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"pipeline"}, txts)
+}
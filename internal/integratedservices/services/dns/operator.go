@@ -27,6 +27,7 @@ import (
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services/dns/externaldns"
 	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
 	"github.com/banzaicloud/pipeline/src/auth"
 	"github.com/banzaicloud/pipeline/src/cluster"
 	"github.com/banzaicloud/pipeline/src/dns/route53"
@@ -75,12 +76,27 @@ func MakeIntegratedServiceOperator(
 
 const (
 	// supported DNS provider names
-	dnsRoute53 = "route53"
-	dnsAzure   = "azure"
-	dnsGoogle  = "google"
-	dnsBanzai  = "banzaicloud-dns"
+	dnsRoute53      = "route53"
+	dnsAzure        = "azure"
+	dnsGoogle       = "google"
+	dnsCloudflare   = "cloudflare"
+	dnsDigitalOcean = "digitalocean"
+	dnsRFC2136      = "rfc2136"
+	dnsBanzai       = "banzaicloud-dns"
 )
 
+// defaultRFC2136Port is the port of the DNS server used when none is specified
+const defaultRFC2136Port = 53
+
+func isSupportedProvider(name string) bool {
+	switch name {
+	case dnsRoute53, dnsAzure, dnsGoogle, dnsCloudflare, dnsDigitalOcean, dnsRFC2136, dnsBanzai:
+		return true
+	default:
+		return false
+	}
+}
+
 // Name returns the name of the DNS integrated service
 func (op IntegratedServiceOperator) Name() string {
 	return IntegratedServiceName
@@ -234,6 +250,42 @@ func (op IntegratedServiceOperator) getChartValues(ctx context.Context, clusterI
 			chartValues.Google.Project = options.GoogleProject
 		}
 
+	case dnsCloudflare:
+		chartValues.Cloudflare = &externaldns.CloudflareSettings{
+			APIKey: secretValues[secrettype.CfApiKey],
+			Email:  secretValues[secrettype.CfApiEmail],
+		}
+
+		if options := spec.ExternalDNS.Provider.Options; options != nil {
+			chartValues.Cloudflare.Proxied = options.CloudflareProxied
+		}
+
+	case dnsDigitalOcean:
+		chartValues.DigitalOcean = &externaldns.DigitalOceanSettings{
+			APIToken: secretValues[secrettype.DoToken],
+		}
+
+	case dnsRFC2136:
+		options := spec.ExternalDNS.Provider.Options
+
+		chartValues.RFC2136 = &externaldns.RFC2136Settings{
+			Host:          options.RFC2136Host,
+			Port:          options.RFC2136Port,
+			Zone:          options.RFC2136Zone,
+			TSIGKeyname:   secretValues[secrettype.RFC2136TSIGKeyName],
+			TSIGSecret:    secretValues[secrettype.RFC2136TSIGSecret],
+			TSIGSecretAlg: secretValues[secrettype.RFC2136TSIGSecretAlg],
+			TSIGAxfr:      options.RFC2136TSIGAxfr,
+		}
+
+		if chartValues.RFC2136.Port == 0 {
+			chartValues.RFC2136.Port = defaultRFC2136Port
+		}
+
+		if chartValues.RFC2136.TSIGSecretAlg == "" {
+			chartValues.RFC2136.TSIGSecretAlg = types.RFC2136DefaultTSIGSecretAlg
+		}
+
 	default:
 	}
 
@@ -16,9 +16,11 @@ package dns
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
@@ -184,6 +186,131 @@ func TestIntegratedServiceOperator_Apply(t *testing.T) {
 	}
 }
 
+func TestIntegratedServiceOperator_getChartValues(t *testing.T) {
+	clusterID := uint(42)
+	orgID := uint(13)
+
+	secrets := map[string]*secret.SecretItemResponse{
+		"cloudflare": {
+			Type: secrettype.CloudFlareSecretType,
+			Values: map[string]string{
+				secrettype.CfApiKey:   "cf-api-key",
+				secrettype.CfApiEmail: "cf@example.com",
+			},
+		},
+		"digitalocean": {
+			Type: secrettype.DigitalOceanSecretType,
+			Values: map[string]string{
+				secrettype.DoToken: "do-token",
+			},
+		},
+		"rfc2136": {
+			Type: secrettype.RFC2136SecretType,
+			Values: map[string]string{
+				secrettype.RFC2136TSIGKeyName: "externaldns-key",
+				secrettype.RFC2136TSIGSecret:  "c2VjcmV0",
+			},
+		},
+	}
+
+	orgSecretStore := dummyOrganizationalSecretStore{
+		Secrets: map[uint]map[string]*secret.SecretItemResponse{
+			orgID: secrets,
+		},
+	}
+	clusterGetter := dummyClusterGetter{
+		Clusters: map[uint]dummyCluster{
+			clusterID: {
+				OrgID:  orgID,
+				Status: pkgCluster.Running,
+			},
+		},
+	}
+	secretStore := commonadapter.NewSecretStore(orgSecretStore, commonadapter.OrgIDContextExtractorFunc(auth.GetCurrentOrganizationID))
+	op := MakeIntegratedServiceOperator(clusterGetter, nil, nil, services.NoopLogger{}, nil, secretStore, Config{})
+
+	cases := map[string]struct {
+		Provider providerSpec
+		Values   obj
+	}{
+		"cloudflare": {
+			Provider: providerSpec{
+				Name:     dnsCloudflare,
+				SecretID: "cloudflare",
+				Options: &providerOptions{
+					CloudflareProxied: true,
+				},
+			},
+			Values: obj{
+				"provider": "cloudflare",
+				"cloudflare": obj{
+					"apiKey":  "cf-api-key",
+					"email":   "cf@example.com",
+					"proxied": true,
+				},
+			},
+		},
+		"digitalocean": {
+			Provider: providerSpec{
+				Name:     dnsDigitalOcean,
+				SecretID: "digitalocean",
+			},
+			Values: obj{
+				"provider": "digitalocean",
+				"digitalocean": obj{
+					"apiToken": "do-token",
+				},
+			},
+		},
+		"rfc2136": {
+			Provider: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "rfc2136",
+				Options: &providerOptions{
+					RFC2136Host: "ns1.example.com",
+					RFC2136Zone: "example.com",
+				},
+			},
+			Values: obj{
+				"provider": "rfc2136",
+				"rfc2136": obj{
+					"host":          "ns1.example.com",
+					"port":          float64(53),
+					"zone":          "example.com",
+					"tsigKeyname":   "externaldns-key",
+					"tsigSecret":    "c2VjcmV0",
+					"tsigSecretAlg": "hmac-sha256",
+					"tsigAxfr":      false,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ctx := auth.SetCurrentOrganizationID(context.Background(), orgID)
+
+			spec := dnsIntegratedServiceSpec{
+				ClusterDomain: "cluster.example.com",
+				ExternalDNS: externalDNSSpec{
+					Provider: tc.Provider,
+				},
+			}
+
+			rawValues, err := op.getChartValues(ctx, clusterID, spec)
+			require.NoError(t, err)
+
+			var values obj
+			require.NoError(t, json.Unmarshal(rawValues, &values))
+
+			for key, expected := range tc.Values {
+				assert.Equal(t, expected, values[key], key)
+			}
+		})
+	}
+}
+
 func TestIntegratedServiceOperator_Deactivate(t *testing.T) {
 	clusterID := uint(42)
 
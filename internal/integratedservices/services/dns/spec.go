@@ -77,6 +77,8 @@ func (s providerSpec) Validate() error {
 
 	if s.Name == "" {
 		errs = errors.Append(errs, requiredStringFieldError{fieldName: "name"})
+	} else if !isSupportedProvider(s.Name) {
+		errs = errors.Append(errs, errors.Errorf("DNS provider %q is not supported", s.Name))
 	}
 
 	if s.Name == dnsBanzai {
@@ -98,6 +100,11 @@ type providerOptions struct {
 	GoogleProject      string `json:"project,omitempty" mapstructure:"project"`
 	Region             string `json:"region,omitempty" mapstructure:"region"`
 	BatchChangeSize    uint   `json:"batchSize,omitempty" mapstructure:"batchSize"`
+	CloudflareProxied  bool   `json:"proxied,omitempty" mapstructure:"proxied"`
+	RFC2136Host        string `json:"host,omitempty" mapstructure:"host"`
+	RFC2136Port        uint   `json:"port,omitempty" mapstructure:"port"`
+	RFC2136Zone        string `json:"zone,omitempty" mapstructure:"zone"`
+	RFC2136TSIGAxfr    bool   `json:"tsigAxfr,omitempty" mapstructure:"tsigAxfr"`
 }
 
 func (o *providerOptions) Validate(provider string) error {
@@ -114,6 +121,22 @@ func (o *providerOptions) Validate(provider string) error {
 				fieldName: "project",
 			}
 		}
+	case dnsRFC2136:
+		var errs error
+
+		if o == nil || o.RFC2136Host == "" {
+			errs = errors.Append(errs, requiredStringFieldError{fieldName: "host"})
+		}
+
+		if o == nil || o.RFC2136Zone == "" {
+			errs = errors.Append(errs, requiredStringFieldError{fieldName: "zone"})
+		}
+
+		if o != nil && o.RFC2136Port > 65535 {
+			errs = errors.Append(errs, errors.Errorf("invalid port: %d", o.RFC2136Port))
+		}
+
+		return errs
 	}
 
 	return nil
@@ -103,6 +103,67 @@ func TestProviderSpec_Validate(t *testing.T) {
 			},
 			Valid: true,
 		},
+		"unsupported provider": {
+			Spec: providerSpec{
+				Name:     "bind",
+				SecretID: "0123456789abcdef",
+			},
+			Valid: false,
+		},
+		"valid cloudflare": {
+			Spec: providerSpec{
+				Name:     dnsCloudflare,
+				SecretID: "0123456789abcdef",
+			},
+			Valid: true,
+		},
+		"valid digitalocean": {
+			Spec: providerSpec{
+				Name:     dnsDigitalOcean,
+				SecretID: "0123456789abcdef",
+			},
+			Valid: true,
+		},
+		"missing options (rfc2136)": {
+			Spec: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "0123456789abcdef",
+			},
+			Valid: false,
+		},
+		"missing zone": {
+			Spec: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "0123456789abcdef",
+				Options: &providerOptions{
+					RFC2136Host: "ns1.example.com",
+				},
+			},
+			Valid: false,
+		},
+		"invalid port": {
+			Spec: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "0123456789abcdef",
+				Options: &providerOptions{
+					RFC2136Host: "ns1.example.com",
+					RFC2136Port: 70000,
+					RFC2136Zone: "example.com",
+				},
+			},
+			Valid: false,
+		},
+		"valid rfc2136": {
+			Spec: providerSpec{
+				Name:     dnsRFC2136,
+				SecretID: "0123456789abcdef",
+				Options: &providerOptions{
+					RFC2136Host: "ns1.example.com",
+					RFC2136Zone: "example.com",
+				},
+			},
+			Valid: true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -0,0 +1,427 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	"github.com/banzaicloud/pipeline/pkg/jsonstructure"
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+// ClusterIssuerName is the name of the cert-manager ClusterIssuer managed by the integrated service.
+const ClusterIssuerName = "pipeline-issuer"
+
+const (
+	clusterIssuerSecretName           = "pipeline-issuer-credentials"
+	clusterIssuerAccountKeySecretName = "pipeline-issuer-account-key"
+
+	defaultACMEServer = "https://acme-v02.api.letsencrypt.org/directory"
+)
+
+// DNS provider names as used by the DNS integrated service
+const (
+	dnsProviderAzure        = "azure"
+	dnsProviderCloudflare   = "cloudflare"
+	dnsProviderDigitalOcean = "digitalocean"
+	dnsProviderGoogle       = "google"
+	dnsProviderRFC2136      = "rfc2136"
+	dnsProviderRoute53      = "route53"
+
+	defaultRFC2136Port = 53
+)
+
+var clusterIssuerGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1alpha2",
+	Kind:    "ClusterIssuer",
+}
+
+type certManager struct {
+	clusters          OperatorClusterStore
+	config            Config
+	dnsProviders      DNSProviderStore
+	helmService       services.HelmService
+	kubernetesService KubernetesService
+	secretStore       services.SecretStore
+}
+
+func (m certManager) Deploy(ctx context.Context, clusterID uint, spec Spec) error {
+	chartValues, err := jsonstructure.CopyObject(m.config.Charts.CertManager.Values)
+	if err != nil {
+		return errors.WrapIf(err, "failed to copy default chart values from config")
+	}
+
+	chartValuesBytes, err := json.Marshal(chartValues)
+	if err != nil {
+		return errors.WrapIf(err, "failed to marshal chart values to JSON")
+	}
+
+	if err := m.helmService.ApplyDeployment(
+		ctx,
+		clusterID,
+		m.config.CertManager.Namespace,
+		m.config.Charts.CertManager.Chart,
+		m.config.CertManager.ReleaseName,
+		chartValuesBytes,
+		m.config.Charts.CertManager.Version,
+	); err != nil {
+		return errors.WrapIf(err, "failed to apply deployment")
+	}
+
+	cluster, err := m.clusters.Get(ctx, clusterID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get cluster")
+	}
+
+	ctx = auth.SetCurrentOrganizationID(ctx, cluster.OrganizationID)
+
+	issuerSpec, secret, err := m.compileClusterIssuer(ctx, clusterID, spec)
+	if err != nil {
+		return errors.WrapIf(err, "failed to compile cluster issuer")
+	}
+
+	if secret != nil {
+		if err := m.ensureSecret(ctx, clusterID, secret); err != nil {
+			return errors.WrapIf(err, "failed to ensure cluster issuer secret")
+		}
+	} else {
+		if err := m.kubernetesService.DeleteObject(ctx, clusterID, m.newSecret(clusterIssuerSecretName)); err != nil {
+			return errors.WrapIf(err, "failed to delete cluster issuer secret")
+		}
+	}
+
+	if err := m.ensureClusterIssuer(ctx, clusterID, issuerSpec); err != nil {
+		return errors.WrapIf(err, "failed to ensure cluster issuer")
+	}
+
+	return nil
+}
+
+func (m certManager) Remove(ctx context.Context, clusterID uint) error {
+	if err := m.kubernetesService.DeleteObject(ctx, clusterID, newClusterIssuer()); err != nil && !meta.IsNoMatchError(errors.Cause(err)) {
+		return errors.WrapIf(err, "failed to delete cluster issuer")
+	}
+
+	for _, name := range []string{clusterIssuerSecretName, clusterIssuerAccountKeySecretName} {
+		if err := m.kubernetesService.DeleteObject(ctx, clusterID, m.newSecret(name)); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to delete secret", "secret", name)
+		}
+	}
+
+	return errors.WrapIf(m.helmService.DeleteDeployment(ctx, clusterID, m.config.CertManager.ReleaseName, m.config.CertManager.Namespace), "failed to delete deployment")
+}
+
+// IssuerStatus returns whether the cluster issuer is ready to issue certificates along with the reason reported by cert-manager.
+func (m certManager) IssuerStatus(ctx context.Context, clusterID uint) (bool, string, error) {
+	issuer := newClusterIssuer()
+	if err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{Name: ClusterIssuerName}, issuer); err != nil {
+		return false, "", errors.WrapIf(err, "failed to get cluster issuer")
+	}
+
+	conditions, _, err := unstructured.NestedSlice(issuer.Object, "status", "conditions")
+	if err != nil {
+		return false, "", errors.WrapIf(err, "failed to get cluster issuer conditions")
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		message, _ := condition["message"].(string)
+
+		return condition["status"] == "True", message, nil
+	}
+
+	return false, "", nil
+}
+
+func (m certManager) compileClusterIssuer(ctx context.Context, clusterID uint, spec Spec) (map[string]interface{}, *corev1.Secret, error) {
+	issuer := spec.CertManager.Issuer
+
+	switch issuer.Type {
+	case IssuerTypeACME:
+		server := issuer.ACME.Server
+		if server == "" {
+			server = defaultACMEServer
+		}
+
+		var (
+			solver map[string]interface{}
+			secret *corev1.Secret
+		)
+
+		switch issuer.ACME.Challenge {
+		case ACMEChallengeHTTP01:
+			ingress := make(map[string]interface{})
+			if spec.IngressClass != "" {
+				ingress["class"] = spec.IngressClass
+			}
+
+			solver = map[string]interface{}{
+				"http01": map[string]interface{}{
+					"ingress": ingress,
+				},
+			}
+
+		case ACMEChallengeDNS01:
+			var err error
+
+			solver, secret, err = m.compileDNS01Solver(ctx, clusterID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+		default:
+			return nil, nil, unsupportedACMEChallengeError{Challenge: issuer.ACME.Challenge}
+		}
+
+		return map[string]interface{}{
+			"acme": map[string]interface{}{
+				"server": server,
+				"email":  issuer.ACME.Email,
+				"privateKeySecretRef": map[string]interface{}{
+					"name": clusterIssuerAccountKeySecretName,
+				},
+				"solvers": []interface{}{solver},
+			},
+		}, secret, nil
+
+	case IssuerTypeCA:
+		values, err := m.secretStore.GetSecretValues(ctx, issuer.CA.SecretID)
+		if err != nil {
+			return nil, nil, errors.WrapIf(err, "failed to get CA secret")
+		}
+
+		if values[secrettype.CACert] == "" || values[secrettype.CAKey] == "" {
+			return nil, nil, errors.NewWithDetails("CA secret must contain a CA certificate and key", "secretId", issuer.CA.SecretID)
+		}
+
+		secret := m.newSecret(clusterIssuerSecretName)
+		secret.Type = corev1.SecretTypeTLS
+		secret.StringData = map[string]string{
+			corev1.TLSCertKey:       values[secrettype.CACert],
+			corev1.TLSPrivateKeyKey: values[secrettype.CAKey],
+		}
+
+		return map[string]interface{}{
+			"ca": map[string]interface{}{
+				"secretName": clusterIssuerSecretName,
+			},
+		}, secret, nil
+
+	default:
+		return nil, nil, unsupportedIssuerTypeError{IssuerType: issuer.Type}
+	}
+}
+
+// compileDNS01Solver creates an ACME DNS-01 solver using the provider and credentials of the DNS integrated service.
+func (m certManager) compileDNS01Solver(ctx context.Context, clusterID uint) (map[string]interface{}, *corev1.Secret, error) {
+	provider, err := m.dnsProviders.GetDNSProvider(ctx, clusterID)
+	if err != nil {
+		return nil, nil, errors.WrapIf(err, "failed to get DNS provider")
+	}
+
+	values, err := m.secretStore.GetSecretValues(ctx, provider.SecretID)
+	if err != nil {
+		return nil, nil, errors.WrapIf(err, "failed to get DNS provider secret")
+	}
+
+	secretKeyRef := func(key string) map[string]interface{} {
+		return map[string]interface{}{
+			"name": clusterIssuerSecretName,
+			"key":  key,
+		}
+	}
+
+	secret := m.newSecret(clusterIssuerSecretName)
+	secret.StringData = make(map[string]string)
+
+	var solver map[string]interface{}
+
+	switch provider.Name {
+	case dnsProviderRoute53:
+		region := provider.Region
+		if region == "" {
+			region = values[secrettype.AwsRegion]
+		}
+
+		secret.StringData["secret-access-key"] = values[secrettype.AwsSecretAccessKey]
+		solver = map[string]interface{}{
+			"route53": map[string]interface{}{
+				"region":                   region,
+				"accessKeyID":              values[secrettype.AwsAccessKeyId],
+				"secretAccessKeySecretRef": secretKeyRef("secret-access-key"),
+			},
+		}
+
+	case dnsProviderAzure:
+		secret.StringData["client-secret"] = values[secrettype.AzureClientSecret]
+		solver = map[string]interface{}{
+			"azuredns": map[string]interface{}{
+				"clientID":              values[secrettype.AzureClientID],
+				"clientSecretSecretRef": secretKeyRef("client-secret"),
+				"subscriptionID":        values[secrettype.AzureSubscriptionID],
+				"tenantID":              values[secrettype.AzureTenantID],
+				"resourceGroupName":     provider.AzureResourceGroup,
+			},
+		}
+
+	case dnsProviderGoogle:
+		serviceAccount, err := json.Marshal(values)
+		if err != nil {
+			return nil, nil, errors.WrapIf(err, "failed to marshal service account")
+		}
+
+		project := provider.GoogleProject
+		if project == "" {
+			project = values[secrettype.ProjectId]
+		}
+
+		secret.StringData["key.json"] = string(serviceAccount)
+		solver = map[string]interface{}{
+			"clouddns": map[string]interface{}{
+				"project":                 project,
+				"serviceAccountSecretRef": secretKeyRef("key.json"),
+			},
+		}
+
+	case dnsProviderCloudflare:
+		secret.StringData["api-key"] = values[secrettype.CfApiKey]
+		solver = map[string]interface{}{
+			"cloudflare": map[string]interface{}{
+				"email":           values[secrettype.CfApiEmail],
+				"apiKeySecretRef": secretKeyRef("api-key"),
+			},
+		}
+
+	case dnsProviderDigitalOcean:
+		secret.StringData["access-token"] = values[secrettype.DoToken]
+		solver = map[string]interface{}{
+			"digitalocean": map[string]interface{}{
+				"tokenSecretRef": secretKeyRef("access-token"),
+			},
+		}
+
+	case dnsProviderRFC2136:
+		port := provider.RFC2136Port
+		if port == 0 {
+			port = defaultRFC2136Port
+		}
+
+		algorithm := values[secrettype.RFC2136TSIGSecretAlg]
+		if algorithm == "" {
+			algorithm = types.RFC2136DefaultTSIGSecretAlg
+		}
+
+		secret.StringData["tsig-secret"] = values[secrettype.RFC2136TSIGSecret]
+		solver = map[string]interface{}{
+			"rfc2136": map[string]interface{}{
+				"nameserver":          net.JoinHostPort(provider.RFC2136Host, strconv.FormatUint(uint64(port), 10)),
+				"tsigKeyName":         values[secrettype.RFC2136TSIGKeyName],
+				"tsigAlgorithm":       tsigAlgorithm(algorithm),
+				"tsigSecretSecretRef": secretKeyRef("tsig-secret"),
+			},
+		}
+
+	default:
+		return nil, nil, unsupportedDNSProviderError{Provider: provider.Name}
+	}
+
+	return map[string]interface{}{"dns01": solver}, secret, nil
+}
+
+// tsigAlgorithm converts a TSIG algorithm name (eg. hmac-sha256) to the form expected by cert-manager (eg. HMACSHA256).
+func tsigAlgorithm(algorithm string) string {
+	return strings.ToUpper(strings.ReplaceAll(algorithm, "-", ""))
+}
+
+func (m certManager) ensureSecret(ctx context.Context, clusterID uint, secret *corev1.Secret) error {
+	var oldSecret corev1.Secret
+	if err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	}, &oldSecret); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return m.kubernetesService.EnsureObject(ctx, clusterID, secret)
+		}
+
+		return errors.WrapIf(err, "failed to get secret")
+	}
+
+	if oldSecret.Type != secret.Type {
+		// the type of a secret is immutable, recreate it
+		if err := m.kubernetesService.DeleteObject(ctx, clusterID, &oldSecret); err != nil {
+			return errors.WrapIf(err, "failed to delete secret")
+		}
+
+		return m.kubernetesService.EnsureObject(ctx, clusterID, secret)
+	}
+
+	secret.ResourceVersion = oldSecret.ResourceVersion
+	return m.kubernetesService.Update(ctx, clusterID, secret)
+}
+
+func (m certManager) ensureClusterIssuer(ctx context.Context, clusterID uint, spec map[string]interface{}) error {
+	issuer := newClusterIssuer()
+	issuer.Object["spec"] = spec
+
+	oldIssuer := newClusterIssuer()
+	if err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{Name: ClusterIssuerName}, oldIssuer); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return m.kubernetesService.EnsureObject(ctx, clusterID, issuer)
+		}
+
+		return errors.WrapIf(err, "failed to get cluster issuer")
+	}
+
+	issuer.SetResourceVersion(oldIssuer.GetResourceVersion())
+	return m.kubernetesService.Update(ctx, clusterID, issuer)
+}
+
+func (m certManager) newSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: m.config.CertManager.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+func newClusterIssuer() *unstructured.Unstructured {
+	issuer := &unstructured.Unstructured{}
+	issuer.SetGroupVersionKind(clusterIssuerGVK)
+	issuer.SetName(ClusterIssuerName)
+
+	return issuer
+}
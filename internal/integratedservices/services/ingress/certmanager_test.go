@@ -0,0 +1,400 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCertManager_CompileClusterIssuer(t *testing.T) {
+	type arr = []interface{}
+	type obj = map[string]interface{}
+
+	secrets := dummySecretStore{
+		"ca": {
+			"caCert": "ca-cert",
+			"caKey":  "ca-key",
+		},
+		"aws": {
+			"AWS_ACCESS_KEY_ID":     "access-key",
+			"AWS_SECRET_ACCESS_KEY": "secret-key",
+			"AWS_REGION":            "eu-west-1",
+		},
+		"tsig": {
+			"TSIG_KEY_NAME":   "externaldns-key",
+			"TSIG_SECRET":     "secret",
+			"TSIG_SECRET_ALG": "hmac-sha512",
+		},
+	}
+
+	testCases := map[string]struct {
+		Spec               Spec
+		DNSProvider        DNSProvider
+		ExpectedIssuer     map[string]interface{}
+		ExpectedSecret     map[string]string
+		ExpectedSecretType corev1.SecretType
+		Error              interface{}
+	}{
+		"acme http01": {
+			Spec: Spec{
+				IngressClass: "nginx",
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeACME,
+						ACME: ACMEIssuerSpec{
+							Email:     "admin@my.domain.org",
+							Challenge: ACMEChallengeHTTP01,
+						},
+					},
+				},
+			},
+			ExpectedIssuer: obj{
+				"acme": obj{
+					"server": defaultACMEServer,
+					"email":  "admin@my.domain.org",
+					"privateKeySecretRef": obj{
+						"name": clusterIssuerAccountKeySecretName,
+					},
+					"solvers": arr{
+						obj{
+							"http01": obj{
+								"ingress": obj{
+									"class": "nginx",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"acme dns01 route53": {
+			Spec: Spec{
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeACME,
+						ACME: ACMEIssuerSpec{
+							Server:    "https://acme-staging-v02.api.letsencrypt.org/directory",
+							Email:     "admin@my.domain.org",
+							Challenge: ACMEChallengeDNS01,
+						},
+					},
+				},
+			},
+			DNSProvider: DNSProvider{
+				Name:     "route53",
+				SecretID: "aws",
+			},
+			ExpectedIssuer: obj{
+				"acme": obj{
+					"server": "https://acme-staging-v02.api.letsencrypt.org/directory",
+					"email":  "admin@my.domain.org",
+					"privateKeySecretRef": obj{
+						"name": clusterIssuerAccountKeySecretName,
+					},
+					"solvers": arr{
+						obj{
+							"dns01": obj{
+								"route53": obj{
+									"region":      "eu-west-1",
+									"accessKeyID": "access-key",
+									"secretAccessKeySecretRef": obj{
+										"name": clusterIssuerSecretName,
+										"key":  "secret-access-key",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedSecret: map[string]string{
+				"secret-access-key": "secret-key",
+			},
+			ExpectedSecretType: corev1.SecretTypeOpaque,
+		},
+		"acme dns01 rfc2136": {
+			Spec: Spec{
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeACME,
+						ACME: ACMEIssuerSpec{
+							Email:     "admin@my.domain.org",
+							Challenge: ACMEChallengeDNS01,
+						},
+					},
+				},
+			},
+			DNSProvider: DNSProvider{
+				Name:        "rfc2136",
+				SecretID:    "tsig",
+				RFC2136Host: "10.0.0.53",
+			},
+			ExpectedIssuer: obj{
+				"acme": obj{
+					"server": defaultACMEServer,
+					"email":  "admin@my.domain.org",
+					"privateKeySecretRef": obj{
+						"name": clusterIssuerAccountKeySecretName,
+					},
+					"solvers": arr{
+						obj{
+							"dns01": obj{
+								"rfc2136": obj{
+									"nameserver":    "10.0.0.53:53",
+									"tsigKeyName":   "externaldns-key",
+									"tsigAlgorithm": "HMACSHA512",
+									"tsigSecretSecretRef": obj{
+										"name": clusterIssuerSecretName,
+										"key":  "tsig-secret",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedSecret: map[string]string{
+				"tsig-secret": "secret",
+			},
+			ExpectedSecretType: corev1.SecretTypeOpaque,
+		},
+		"acme dns01 unsupported provider": {
+			Spec: Spec{
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeACME,
+						ACME: ACMEIssuerSpec{
+							Email:     "admin@my.domain.org",
+							Challenge: ACMEChallengeDNS01,
+						},
+					},
+				},
+			},
+			DNSProvider: DNSProvider{
+				Name:     "unknown",
+				SecretID: "aws",
+			},
+			Error: unsupportedDNSProviderError{
+				Provider: "unknown",
+			},
+		},
+		"ca": {
+			Spec: Spec{
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeCA,
+						CA: CAIssuerSpec{
+							SecretID: "ca",
+						},
+					},
+				},
+			},
+			ExpectedIssuer: obj{
+				"ca": obj{
+					"secretName": clusterIssuerSecretName,
+				},
+			},
+			ExpectedSecret: map[string]string{
+				"tls.crt": "ca-cert",
+				"tls.key": "ca-key",
+			},
+			ExpectedSecretType: corev1.SecretTypeTLS,
+		},
+		"ca with invalid secret": {
+			Spec: Spec{
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: IssuerTypeCA,
+						CA: CAIssuerSpec{
+							SecretID: "aws",
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+	}
+
+	clusterID := uint(1)
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			m := certManager{
+				config: Config{
+					CertManager: CertManagerConfig{
+						Namespace: "cert-manager",
+					},
+				},
+				dnsProviders: dummyDNSProviderStore{
+					providers: map[uint]DNSProvider{
+						clusterID: testCase.DNSProvider,
+					},
+				},
+				secretStore: secrets,
+			}
+
+			issuer, secret, err := m.compileClusterIssuer(context.Background(), clusterID, testCase.Spec)
+
+			switch testCase.Error {
+			case nil, false:
+				require.NoError(t, err)
+			case true:
+				require.Error(t, err)
+				return
+			default:
+				require.Equal(t, testCase.Error, errors.Cause(err))
+				return
+			}
+
+			assert.Equal(t, testCase.ExpectedIssuer, issuer)
+
+			if testCase.ExpectedSecret == nil {
+				assert.Nil(t, secret)
+				return
+			}
+
+			require.NotNil(t, secret)
+			assert.Equal(t, clusterIssuerSecretName, secret.Name)
+			assert.Equal(t, "cert-manager", secret.Namespace)
+			assert.Equal(t, testCase.ExpectedSecretType, secret.Type)
+			assert.Equal(t, testCase.ExpectedSecret, secret.StringData)
+		})
+	}
+}
+
+func TestCertManager_IssuerStatus(t *testing.T) {
+	type arr = []interface{}
+	type obj = map[string]interface{}
+
+	testCases := map[string]struct {
+		Status          obj
+		ExpectedReady   bool
+		ExpectedMessage string
+	}{
+		"ready": {
+			Status: obj{
+				"conditions": arr{
+					obj{
+						"type":    "Ready",
+						"status":  "True",
+						"message": "The ACME account was registered with the ACME server",
+					},
+				},
+			},
+			ExpectedReady:   true,
+			ExpectedMessage: "The ACME account was registered with the ACME server",
+		},
+		"not ready": {
+			Status: obj{
+				"conditions": arr{
+					obj{
+						"type":    "Ready",
+						"status":  "False",
+						"message": "Error initializing issuer: secret not found",
+					},
+				},
+			},
+			ExpectedMessage: "Error initializing issuer: secret not found",
+		},
+		"no status": {},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			m := certManager{
+				kubernetesService: dummyKubernetesService{
+					status: testCase.Status,
+				},
+			}
+
+			ready, message, err := m.IssuerStatus(context.Background(), 1)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.ExpectedReady, ready)
+			assert.Equal(t, testCase.ExpectedMessage, message)
+		})
+	}
+}
+
+type dummySecretStore map[string]map[string]string
+
+func (d dummySecretStore) GetSecretValues(ctx context.Context, secretID string) (map[string]string, error) {
+	if values, ok := d[secretID]; ok {
+		return values, nil
+	}
+	return nil, errors.New("secret not found")
+}
+
+func (d dummySecretStore) GetNameByID(ctx context.Context, secretID string) (string, error) {
+	return secretID, nil
+}
+
+func (d dummySecretStore) GetIDByName(ctx context.Context, secretName string) (string, error) {
+	return secretName, nil
+}
+
+func (d dummySecretStore) Delete(ctx context.Context, secretID string) error {
+	return nil
+}
+
+type dummyDNSProviderStore struct {
+	providers map[uint]DNSProvider
+}
+
+func (d dummyDNSProviderStore) GetDNSProvider(ctx context.Context, clusterID uint) (DNSProvider, error) {
+	if p, ok := d.providers[clusterID]; ok {
+		return p, nil
+	}
+	return DNSProvider{}, errors.New("DNS provider not found")
+}
+
+type dummyKubernetesService struct {
+	status map[string]interface{}
+}
+
+func (d dummyKubernetesService) EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	return nil
+}
+
+func (d dummyKubernetesService) Update(ctx context.Context, clusterID uint, o runtime.Object) error {
+	return nil
+}
+
+func (d dummyKubernetesService) DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	return nil
+}
+
+func (d dummyKubernetesService) GetObject(ctx context.Context, clusterID uint, objRef corev1.ObjectReference, obj runtime.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok && d.status != nil {
+		u.Object["status"] = d.status
+	}
+	return nil
+}
@@ -20,9 +20,20 @@ import "context"
 const ServiceName = "ingress"
 
 const (
+	ControllerNginx   = "nginx"
 	ControllerTraefik = "traefik"
 )
 
+const (
+	IssuerTypeACME = "acme"
+	IssuerTypeCA   = "ca"
+)
+
+const (
+	ACMEChallengeHTTP01 = "http01"
+	ACMEChallengeDNS01  = "dns01"
+)
+
 const (
 	ServiceTypeClusterIP    = "ClusterIP"
 	ServiceTypeLoadBalancer = "LoadBalancer"
@@ -46,3 +57,19 @@ type OrgDomain struct {
 	Name         string
 	WildcardName string
 }
+
+// DNSProviderStore returns the DNS provider configured by the DNS integrated service of a cluster.
+type DNSProviderStore interface {
+	GetDNSProvider(ctx context.Context, clusterID uint) (DNSProvider, error)
+}
+
+// DNSProvider contains the provider settings of the DNS integrated service required to solve ACME DNS-01 challenges.
+type DNSProvider struct {
+	Name               string
+	SecretID           string
+	Region             string
+	AzureResourceGroup string
+	GoogleProject      string
+	RFC2136Host        string
+	RFC2136Port        uint
+}
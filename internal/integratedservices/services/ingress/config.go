@@ -25,6 +25,7 @@ type Config struct {
 	ReleaseName string
 	Controllers []string
 	Charts      ChartsConfig
+	CertManager CertManagerConfig
 }
 
 func (c Config) Validate() error {
@@ -40,7 +41,7 @@ func (c Config) Validate() error {
 
 	for _, ctrl := range c.Controllers {
 		switch ctrl {
-		case ControllerTraefik:
+		case ControllerNginx, ControllerTraefik:
 			// ok
 		default:
 			errs = errors.Append(errs, unsupportedControllerError{
@@ -49,11 +50,23 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if c.CertManager.Namespace == "" {
+		errs = errors.Append(errs, errors.New("cert-manager namespace is required"))
+	}
+
 	return errs
 }
 
 type ChartsConfig struct {
-	Traefik TraefikChartConfig
+	Nginx       NginxChartConfig
+	Traefik     TraefikChartConfig
+	CertManager CertManagerChartConfig
+}
+
+type NginxChartConfig struct {
+	Chart   string
+	Version string
+	Values  values.Config
 }
 
 type TraefikChartConfig struct {
@@ -61,3 +74,14 @@ type TraefikChartConfig struct {
 	Version string
 	Values  values.Config
 }
+
+type CertManagerChartConfig struct {
+	Chart   string
+	Version string
+	Values  values.Config
+}
+
+type CertManagerConfig struct {
+	Namespace   string
+	ReleaseName string
+}
@@ -53,3 +53,50 @@ type unsupportedServiceTypeError struct {
 func (e unsupportedServiceTypeError) Error() string {
 	return fmt.Sprintf("service type %q is not supported", e.ServiceType)
 }
+
+type unsupportedIssuerTypeError struct {
+	IssuerType string
+
+	pkgerrors.BadRequestBehavior
+	pkgerrors.ClientErrorBehavior
+	pkgerrors.ValidationBehavior
+}
+
+func (e unsupportedIssuerTypeError) Error() string {
+	return fmt.Sprintf("issuer type %q is not supported", e.IssuerType)
+}
+
+type unsupportedACMEChallengeError struct {
+	Challenge string
+
+	pkgerrors.BadRequestBehavior
+	pkgerrors.ClientErrorBehavior
+	pkgerrors.ValidationBehavior
+}
+
+func (e unsupportedACMEChallengeError) Error() string {
+	return fmt.Sprintf("ACME challenge type %q is not supported", e.Challenge)
+}
+
+type invalidSpecFieldError struct {
+	FieldName string
+	Reason    string
+
+	pkgerrors.BadRequestBehavior
+	pkgerrors.ClientErrorBehavior
+	pkgerrors.ValidationBehavior
+}
+
+func (e invalidSpecFieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.FieldName, e.Reason)
+}
+
+type unsupportedDNSProviderError struct {
+	Provider string
+
+	pkgerrors.ClientErrorBehavior
+}
+
+func (e unsupportedDNSProviderError) Error() string {
+	return fmt.Sprintf("DNS provider %q cannot be used to solve ACME DNS-01 challenges", e.Provider)
+}
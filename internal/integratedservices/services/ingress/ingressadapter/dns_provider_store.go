@@ -0,0 +1,99 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingressadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services/dns"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services/ingress"
+	"github.com/banzaicloud/pipeline/src/dns/route53"
+)
+
+// providerBanzaiCloud is the name of the DNS provider backed by the Route53 zone managed by Pipeline.
+const providerBanzaiCloud = "banzaicloud-dns"
+
+// DNSProviderStore reads the DNS provider from the spec of the DNS integrated service.
+type DNSProviderStore struct {
+	repository integratedservices.IntegratedServiceRepository
+}
+
+func NewDNSProviderStore(repository integratedservices.IntegratedServiceRepository) DNSProviderStore {
+	return DNSProviderStore{
+		repository: repository,
+	}
+}
+
+func (s DNSProviderStore) GetDNSProvider(ctx context.Context, clusterID uint) (ingress.DNSProvider, error) {
+	service, err := s.repository.GetIntegratedService(ctx, clusterID, dns.IntegratedServiceName)
+	if err != nil {
+		if integratedservices.IsIntegratedServiceNotFoundError(err) {
+			return ingress.DNSProvider{}, errors.New("the DNS integrated service must be activated to solve ACME DNS-01 challenges")
+		}
+
+		return ingress.DNSProvider{}, errors.WrapIf(err, "failed to get DNS integrated service")
+	}
+
+	var spec struct {
+		ExternalDNS struct {
+			Provider struct {
+				Name     string `mapstructure:"name"`
+				SecretID string `mapstructure:"secretId"`
+				Options  struct {
+					AzureResourceGroup string `mapstructure:"resourceGroup"`
+					GoogleProject      string `mapstructure:"project"`
+					Region             string `mapstructure:"region"`
+					RFC2136Host        string `mapstructure:"host"`
+					RFC2136Port        uint   `mapstructure:"port"`
+				} `mapstructure:"options"`
+			} `mapstructure:"provider"`
+		} `mapstructure:"externalDns"`
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           &spec,
+	})
+	if err != nil {
+		return ingress.DNSProvider{}, errors.WrapIf(err, "failed to create decoder")
+	}
+
+	if err := decoder.Decode(service.Spec); err != nil {
+		return ingress.DNSProvider{}, errors.WrapIf(err, "failed to decode DNS integrated service spec")
+	}
+
+	provider := spec.ExternalDNS.Provider
+
+	result := ingress.DNSProvider{
+		Name:               provider.Name,
+		SecretID:           provider.SecretID,
+		Region:             provider.Options.Region,
+		AzureResourceGroup: provider.Options.AzureResourceGroup,
+		GoogleProject:      provider.Options.GoogleProject,
+		RFC2136Host:        provider.Options.RFC2136Host,
+		RFC2136Port:        provider.Options.RFC2136Port,
+	}
+
+	if result.Name == providerBanzaiCloud {
+		result.Name = "route53"
+		result.SecretID = route53.IAMUserAccessKeySecretID
+	}
+
+	return result, nil
+}
@@ -28,14 +28,19 @@ type Manager struct {
 
 	config      Config
 	helmService services.HelmService
+	certManager certManager
 	logger      services.Logger
 }
 
-func NewManager(config Config, helmService services.HelmService, logger services.Logger) Manager {
+func NewManager(config Config, helmService services.HelmService, kubernetesService KubernetesService, logger services.Logger) Manager {
 	return Manager{
 		config:      config,
 		helmService: helmService,
-		logger:      logger,
+		certManager: certManager{
+			config:            config,
+			kubernetesService: kubernetesService,
+		},
+		logger: logger,
 	}
 }
 
@@ -53,29 +58,62 @@ func (m Manager) GetOutput(ctx context.Context, clusterID uint, spec integrateds
 	}
 
 	switch boundSpec.Controller.Type {
+	case ControllerNginx:
+		output = set(output, "nginx", map[string]interface{}{
+			"version": m.getChartVersion(ctx, clusterID, m.config.ReleaseName, m.config.Namespace, m.config.Charts.Nginx.Version),
+		})
 	case ControllerTraefik:
-		traefikOutput := make(map[string]interface{})
+		output = set(output, "traefik", map[string]interface{}{
+			"version": m.getChartVersion(ctx, clusterID, m.config.ReleaseName, m.config.Namespace, m.config.Charts.Traefik.Version),
+		})
+	}
+
+	if boundSpec.CertManager.Enabled {
+		issuerOutput := map[string]interface{}{
+			"name":  ClusterIssuerName,
+			"type":  boundSpec.CertManager.Issuer.Type,
+			"ready": false,
+		}
 
-		rel, err := m.helmService.GetDeployment(ctx, clusterID, m.config.ReleaseName, m.config.Namespace)
+		ready, message, err := m.certManager.IssuerStatus(ctx, clusterID)
 		if err != nil {
 			m.logger.Warn(err.Error(), map[string]interface{}{
-				"clusterId":   clusterID,
-				"releaseName": m.config.ReleaseName,
+				"clusterId": clusterID,
+				"issuer":    ClusterIssuerName,
 			})
-		}
-
-		if rel != nil {
-			traefikOutput["version"] = rel.ChartVersion
 		} else {
-			traefikOutput["version"] = m.config.Charts.Traefik.Version
+			issuerOutput["ready"] = ready
+			if message != "" {
+				issuerOutput["message"] = message
+			}
 		}
 
-		output = set(output, "traefik", traefikOutput)
+		output = set(output, "certManager", map[string]interface{}{
+			"version": m.getChartVersion(ctx, clusterID, m.config.CertManager.ReleaseName, m.config.CertManager.Namespace, m.config.Charts.CertManager.Version),
+			"issuer":  issuerOutput,
+		})
 	}
 
 	return output, nil
 }
 
+// getChartVersion returns the chart version of a release or the configured version if the release cannot be found.
+func (m Manager) getChartVersion(ctx context.Context, clusterID uint, releaseName string, namespace string, defaultVersion string) string {
+	rel, err := m.helmService.GetDeployment(ctx, clusterID, releaseName, namespace)
+	if err != nil {
+		m.logger.Warn(err.Error(), map[string]interface{}{
+			"clusterId":   clusterID,
+			"releaseName": releaseName,
+		})
+	}
+
+	if rel != nil {
+		return rel.ChartVersion
+	}
+
+	return defaultVersion
+}
+
 func (m Manager) ValidateSpec(ctx context.Context, spec integratedservices.IntegratedServiceSpec) error {
 	var boundSpec Spec
 	if err := services.BindIntegratedServiceSpec(spec, &boundSpec); err != nil {
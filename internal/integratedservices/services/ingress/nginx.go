@@ -0,0 +1,143 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+
+	"emperror.dev/errors"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/pkg/any"
+	pkgcluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/jsonstructure"
+)
+
+type nginxManager struct {
+	clusters    OperatorClusterStore
+	config      Config
+	helmService services.HelmService
+}
+
+func (m nginxManager) Deploy(ctx context.Context, clusterID uint, spec Spec) error {
+	chartValues, err := m.compileChartValues(ctx, clusterID, spec)
+	if err != nil {
+		return errors.WrapIf(err, "failed to compile nginx chart values")
+	}
+
+	chartValuesBytes, err := json.Marshal(chartValues)
+	if err != nil {
+		return errors.WrapIf(err, "failed to marshal chart values to JSON")
+	}
+
+	if err := m.helmService.ApplyDeployment(
+		ctx,
+		clusterID,
+		m.config.Namespace,
+		m.config.Charts.Nginx.Chart,
+		m.config.ReleaseName,
+		chartValuesBytes,
+		m.config.Charts.Nginx.Version,
+	); err != nil {
+		return errors.WrapIf(err, "failed to apply deployment")
+	}
+
+	return nil
+}
+
+func (m nginxManager) Remove(ctx context.Context, clusterID uint) error {
+	return errors.WrapIf(m.helmService.DeleteDeployment(ctx, clusterID, m.config.ReleaseName, m.config.Namespace), "failed to delete deployment")
+}
+
+func (m nginxManager) compileChartValues(ctx context.Context, clusterID uint, spec Spec) (interface{}, error) {
+	defaultValues, err := jsonstructure.CopyObject(m.config.Charts.Nginx.Values)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to copy default chart values from config")
+	}
+
+	type nginxServiceValues struct {
+		Type        string            `json:"type,omitempty" mapstructure:"type"`
+		Annotations map[string]string `json:"annotations,omitempty" mapstructure:"annotations"`
+	}
+
+	type nginxControllerValues struct {
+		IngressClass string             `json:"ingressClass,omitempty" mapstructure:"ingressClass"`
+		ReplicaCount int                `json:"replicaCount,omitempty" mapstructure:"replicaCount"`
+		Config       map[string]string  `json:"config,omitempty" mapstructure:"config"`
+		ExtraArgs    map[string]string  `json:"extraArgs,omitempty" mapstructure:"extraArgs"`
+		Service      nginxServiceValues `json:"service,omitempty" mapstructure:"service"`
+	}
+
+	type nginxValues struct {
+		Controller nginxControllerValues `json:"controller,omitempty" mapstructure:"controller"`
+	}
+
+	var typedValues nginxValues
+	if err := mapstructure.Decode(defaultValues, &typedValues); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode default chart values")
+	}
+
+	typedValues.Controller.IngressClass = spec.IngressClass
+	typedValues.Controller.Service.Type = spec.Service.Type
+	typedValues.Controller.Service.Annotations = mergeServiceAnnotations(typedValues.Controller.Service.Annotations, spec.Service.Annotations)
+
+	nginxConfig, err := spec.Controller.NginxConfig()
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get nginx config")
+	}
+
+	if nginxConfig.ReplicaCount > 0 {
+		typedValues.Controller.ReplicaCount = nginxConfig.ReplicaCount
+	}
+
+	if len(nginxConfig.Config) != 0 {
+		if typedValues.Controller.Config == nil {
+			typedValues.Controller.Config = make(map[string]string)
+		}
+		for k, v := range nginxConfig.Config {
+			typedValues.Controller.Config[k] = v
+		}
+	}
+
+	if nginxConfig.DefaultSSLCertificate != "" {
+		if typedValues.Controller.ExtraArgs == nil {
+			typedValues.Controller.ExtraArgs = make(map[string]string)
+		}
+		typedValues.Controller.ExtraArgs["default-ssl-certificate"] = nginxConfig.DefaultSSLCertificate
+	}
+
+	cluster, err := m.clusters.Get(ctx, clusterID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get cluster")
+	}
+
+	if cluster.Cloud == pkgcluster.Amazon {
+		typedValues.Controller.Service.Annotations = addAWSLoadBalancerTags(typedValues.Controller.Service.Annotations)
+	}
+
+	untypedValues, err := jsonstructure.Encode(typedValues, jsonstructure.WithZeroStructsAsEmpty)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to encode chart values as JSON structure")
+	}
+
+	finalValues, err := any.Merge(defaultValues, untypedValues, jsonstructure.DefaultMergeOptions())
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to merge chart values")
+	}
+
+	return finalValues, nil
+}
@@ -0,0 +1,158 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNginxManager_CompileChartValues(t *testing.T) {
+	type obj = map[string]interface{}
+
+	config := Config{
+		Namespace:   "default",
+		ReleaseName: "ingress",
+		Controllers: []string{"nginx"},
+		Charts: ChartsConfig{
+			Nginx: NginxChartConfig{
+				Chart:   "ingress-nginx/ingress-nginx",
+				Version: "6.6.6",
+				Values: obj{
+					"controller": obj{
+						"publishService": obj{
+							"enabled": true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		Cluster  OperatorCluster
+		Spec     Spec
+		Expected interface{}
+	}{
+		"default config": {
+			Cluster: OperatorCluster{
+				Cloud: "azure",
+			},
+			Spec: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+				},
+			},
+			Expected: obj{
+				"controller": obj{
+					"publishService": obj{
+						"enabled": true,
+					},
+				},
+			},
+		},
+		"custom config": {
+			Cluster: OperatorCluster{
+				Cloud: "azure",
+			},
+			Spec: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+					RawConfig: obj{
+						"replicaCount":          2,
+						"defaultSSLCertificate": "default/my-cert",
+						"config": obj{
+							"use-forwarded-headers": "true",
+						},
+					},
+				},
+				IngressClass: "public",
+				Service: ServiceSpec{
+					Type: "LoadBalancer",
+					Annotations: map[string]string{
+						"foo": "bar",
+					},
+				},
+			},
+			Expected: obj{
+				"controller": obj{
+					"ingressClass": "public",
+					"replicaCount": 2.0,
+					"config": obj{
+						"use-forwarded-headers": "true",
+					},
+					"extraArgs": obj{
+						"default-ssl-certificate": "default/my-cert",
+					},
+					"publishService": obj{
+						"enabled": true,
+					},
+					"service": obj{
+						"type": "LoadBalancer",
+						"annotations": obj{
+							"foo": "bar",
+						},
+					},
+				},
+			},
+		},
+		"append amazon lb additional tags": {
+			Cluster: OperatorCluster{
+				Cloud: "amazon",
+			},
+			Spec: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+				},
+			},
+			Expected: obj{
+				"controller": obj{
+					"publishService": obj{
+						"enabled": true,
+					},
+					"service": obj{
+						"annotations": obj{
+							"service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags": "banzaicloud-pipeline-managed=true",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clusterID := uint(1)
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			m := nginxManager{
+				clusters: dummyOperatorClusterStore{
+					clusters: map[uint]OperatorCluster{
+						clusterID: testCase.Cluster,
+					},
+				},
+				config: config,
+			}
+
+			values, err := m.compileChartValues(context.Background(), clusterID, testCase.Spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.Expected, values)
+		})
+	}
+}
@@ -25,7 +25,9 @@ import (
 
 type Operator struct {
 	clusterService integratedservices.ClusterService
+	nginxManager   nginxManager
 	traefikManager traefikManager
+	certManager    certManager
 }
 
 func NewOperator(
@@ -34,15 +36,31 @@ func NewOperator(
 	config Config,
 	helmService services.HelmService,
 	orgDomainService OrgDomainService,
+	kubernetesService KubernetesService,
+	secretStore services.SecretStore,
+	dnsProviders DNSProviderStore,
 ) Operator {
 	return Operator{
 		clusterService: clusterService,
+		nginxManager: nginxManager{
+			clusters:    clusters,
+			config:      config,
+			helmService: helmService,
+		},
 		traefikManager: traefikManager{
 			clusters:         clusters,
 			config:           config,
 			helmService:      helmService,
 			orgDomainService: orgDomainService,
 		},
+		certManager: certManager{
+			clusters:          clusters,
+			config:            config,
+			dnsProviders:      dnsProviders,
+			helmService:       helmService,
+			kubernetesService: kubernetesService,
+			secretStore:       secretStore,
+		},
 	}
 }
 
@@ -63,6 +81,10 @@ func (op Operator) Apply(ctx context.Context, clusterID uint, spec integratedser
 	}
 
 	switch controllerType := boundSpec.Controller.Type; controllerType {
+	case ControllerNginx:
+		if err := op.nginxManager.Deploy(ctx, clusterID, boundSpec); err != nil {
+			return errors.WrapIf(err, "failed to deploy nginx")
+		}
 	case ControllerTraefik:
 		if err := op.traefikManager.Deploy(ctx, clusterID, boundSpec); err != nil {
 			return errors.WrapIf(err, "failed to deploy traefik")
@@ -71,6 +93,16 @@ func (op Operator) Apply(ctx context.Context, clusterID uint, spec integratedser
 		return errors.Errorf("unhandled controller type %q", controllerType)
 	}
 
+	if boundSpec.CertManager.Enabled {
+		if err := op.certManager.Deploy(ctx, clusterID, boundSpec); err != nil {
+			return errors.WrapIf(err, "failed to deploy cert-manager")
+		}
+	} else {
+		if err := op.certManager.Remove(ctx, clusterID); err != nil {
+			return errors.WrapIf(err, "failed to remove cert-manager")
+		}
+	}
+
 	return nil
 }
 
@@ -81,7 +113,17 @@ func (op Operator) Deactivate(ctx context.Context, clusterID uint, spec integrat
 		return errors.WrapIf(err, "failed to bind spec")
 	}
 
+	if boundSpec.CertManager.Enabled {
+		if err := op.certManager.Remove(ctx, clusterID); err != nil {
+			return errors.WrapIf(err, "failed to remove cert-manager")
+		}
+	}
+
 	switch controllerType := boundSpec.Controller.Type; controllerType {
+	case ControllerNginx:
+		if err := op.nginxManager.Remove(ctx, clusterID); err != nil {
+			return errors.WrapIf(err, "failed to remove nginx")
+		}
 	case ControllerTraefik:
 		if err := op.traefikManager.Remove(ctx, clusterID); err != nil {
 			return errors.WrapIf(err, "failed to remove traefik")
@@ -15,23 +15,27 @@
 package ingress
 
 import (
+	"strings"
+
 	"emperror.dev/errors"
 	"github.com/mitchellh/mapstructure"
 )
 
 type Spec struct {
-	Controller   ControllerSpec `json:"controller" mapstructure:"controller"`
-	IngressClass string         `json:"ingressClass" mapstructure:"ingressClass"`
-	Service      ServiceSpec    `json:"service" mapstructure:"service"`
+	Controller   ControllerSpec  `json:"controller" mapstructure:"controller"`
+	IngressClass string          `json:"ingressClass" mapstructure:"ingressClass"`
+	Service      ServiceSpec     `json:"service" mapstructure:"service"`
+	CertManager  CertManagerSpec `json:"certManager" mapstructure:"certManager"`
 }
 
 func (s Spec) Validate(config Config) error {
-	return errors.Combine(s.Controller.Validate(config), s.Service.Validate())
+	return errors.Combine(s.Controller.Validate(config), s.Service.Validate(), s.CertManager.Validate())
 }
 
 type ControllerSpec struct {
 	Type          string                 `json:"type" mapstructure:"type"`
 	RawConfig     map[string]interface{} `json:"config" mapstructure:"config"`
+	nginxConfig   *NginxConfigSpec
 	traefikConfig *TraefikConfigSpec
 }
 
@@ -45,6 +49,13 @@ func (s ControllerSpec) Validate(config Config) error {
 	}
 
 	switch s.Type {
+	case ControllerNginx:
+		cfg, err := s.NginxConfig()
+		if err != nil {
+			errs = errors.Append(errs, err)
+		}
+
+		errs = errors.Append(errs, cfg.Validate())
 	case ControllerTraefik:
 		cfg, err := s.TraefikConfig()
 		if err != nil {
@@ -57,6 +68,16 @@ func (s ControllerSpec) Validate(config Config) error {
 	return errs
 }
 
+func (s *ControllerSpec) NginxConfig() (NginxConfigSpec, error) {
+	if s.nginxConfig == nil {
+		s.nginxConfig = new(NginxConfigSpec)
+		if err := mapstructure.Decode(s.RawConfig, s.nginxConfig); err != nil {
+			return NginxConfigSpec{}, errors.WrapIf(err, "failed to decode config values as nginx config")
+		}
+	}
+	return *s.nginxConfig, nil
+}
+
 func (s *ControllerSpec) TraefikConfig() (TraefikConfigSpec, error) {
 	if s.traefikConfig == nil {
 		s.traefikConfig = new(TraefikConfigSpec)
@@ -67,6 +88,34 @@ func (s *ControllerSpec) TraefikConfig() (TraefikConfigSpec, error) {
 	return *s.traefikConfig, nil
 }
 
+type NginxConfigSpec struct {
+	ReplicaCount          int               `json:"replicaCount" mapstructure:"replicaCount"`
+	DefaultSSLCertificate string            `json:"defaultSSLCertificate" mapstructure:"defaultSSLCertificate"`
+	Config                map[string]string `json:"config" mapstructure:"config"`
+}
+
+func (s NginxConfigSpec) Validate() error {
+	var errs error
+
+	if s.ReplicaCount < 0 {
+		errs = errors.Append(errs, invalidSpecFieldError{
+			FieldName: "replicaCount",
+			Reason:    "must not be negative",
+		})
+	}
+
+	if s.DefaultSSLCertificate != "" {
+		if parts := strings.Split(s.DefaultSSLCertificate, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = errors.Append(errs, invalidSpecFieldError{
+				FieldName: "defaultSSLCertificate",
+				Reason:    "must be in namespace/name format",
+			})
+		}
+	}
+
+	return errs
+}
+
 type TraefikConfigSpec struct {
 	SSL TraefikSSLSpec `json:"ssl" mapstructure:"ssl"`
 }
@@ -97,6 +146,80 @@ func (s ServiceSpec) Validate() error {
 	}
 }
 
+type CertManagerSpec struct {
+	Enabled bool       `json:"enabled" mapstructure:"enabled"`
+	Issuer  IssuerSpec `json:"issuer" mapstructure:"issuer"`
+}
+
+func (s CertManagerSpec) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	return s.Issuer.Validate()
+}
+
+type IssuerSpec struct {
+	Type string         `json:"type" mapstructure:"type"`
+	ACME ACMEIssuerSpec `json:"acme" mapstructure:"acme"`
+	CA   CAIssuerSpec   `json:"ca" mapstructure:"ca"`
+}
+
+func (s IssuerSpec) Validate() error {
+	switch s.Type {
+	case IssuerTypeACME:
+		return s.ACME.Validate()
+	case IssuerTypeCA:
+		return s.CA.Validate()
+	default:
+		return unsupportedIssuerTypeError{
+			IssuerType: s.Type,
+		}
+	}
+}
+
+type ACMEIssuerSpec struct {
+	Server    string `json:"server" mapstructure:"server"`
+	Email     string `json:"email" mapstructure:"email"`
+	Challenge string `json:"challenge" mapstructure:"challenge"`
+}
+
+func (s ACMEIssuerSpec) Validate() error {
+	var errs error
+
+	if s.Email == "" {
+		errs = errors.Append(errs, invalidSpecFieldError{
+			FieldName: "acme.email",
+			Reason:    "is required",
+		})
+	}
+
+	switch s.Challenge {
+	case ACMEChallengeHTTP01, ACMEChallengeDNS01:
+	default:
+		errs = errors.Append(errs, unsupportedACMEChallengeError{
+			Challenge: s.Challenge,
+		})
+	}
+
+	return errs
+}
+
+type CAIssuerSpec struct {
+	SecretID string `json:"secretId" mapstructure:"secretId"`
+}
+
+func (s CAIssuerSpec) Validate() error {
+	if s.SecretID == "" {
+		return invalidSpecFieldError{
+			FieldName: "ca.secretId",
+			Reason:    "is required",
+		}
+	}
+
+	return nil
+}
+
 func contains(slice []string, str string) bool {
 	for _, e := range slice {
 		if e == str {
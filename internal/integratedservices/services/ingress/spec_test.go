@@ -211,6 +211,168 @@ func TestSpec(t *testing.T) {
 				ServiceType: "NotAServiceType",
 			},
 		},
+		"nginx with config": {
+			Input: obj{
+				"controller": obj{
+					"type": "nginx",
+					"config": obj{
+						"replicaCount":          2,
+						"defaultSSLCertificate": "default/my-cert",
+					},
+				},
+			},
+			Config: Config{
+				Controllers: []string{
+					"nginx",
+				},
+			},
+			Expected: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+					RawConfig: obj{
+						"replicaCount":          2,
+						"defaultSSLCertificate": "default/my-cert",
+					},
+				},
+			},
+		},
+		"nginx with invalid default certificate": {
+			Input: obj{
+				"controller": obj{
+					"type": "nginx",
+					"config": obj{
+						"defaultSSLCertificate": "my-cert",
+					},
+				},
+			},
+			Config: Config{
+				Controllers: []string{
+					"nginx",
+				},
+			},
+			Expected: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+					RawConfig: obj{
+						"defaultSSLCertificate": "my-cert",
+					},
+				},
+			},
+			Validation: invalidSpecFieldError{
+				FieldName: "defaultSSLCertificate",
+				Reason:    "must be in namespace/name format",
+			},
+		},
+		"cert-manager with ACME issuer": {
+			Input: obj{
+				"controller": obj{
+					"type": "nginx",
+				},
+				"certManager": obj{
+					"enabled": true,
+					"issuer": obj{
+						"type": "acme",
+						"acme": obj{
+							"email":     "admin@my.domain.org",
+							"challenge": "dns01",
+						},
+					},
+				},
+			},
+			Config: Config{
+				Controllers: []string{
+					"nginx",
+				},
+			},
+			Expected: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+				},
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: "acme",
+						ACME: ACMEIssuerSpec{
+							Email:     "admin@my.domain.org",
+							Challenge: "dns01",
+						},
+					},
+				},
+			},
+		},
+		"cert-manager with unsupported ACME challenge": {
+			Input: obj{
+				"controller": obj{
+					"type": "nginx",
+				},
+				"certManager": obj{
+					"enabled": true,
+					"issuer": obj{
+						"type": "acme",
+						"acme": obj{
+							"email":     "admin@my.domain.org",
+							"challenge": "tls-alpn-01",
+						},
+					},
+				},
+			},
+			Config: Config{
+				Controllers: []string{
+					"nginx",
+				},
+			},
+			Expected: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+				},
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: "acme",
+						ACME: ACMEIssuerSpec{
+							Email:     "admin@my.domain.org",
+							Challenge: "tls-alpn-01",
+						},
+					},
+				},
+			},
+			Validation: unsupportedACMEChallengeError{
+				Challenge: "tls-alpn-01",
+			},
+		},
+		"cert-manager with CA issuer without secret": {
+			Input: obj{
+				"controller": obj{
+					"type": "nginx",
+				},
+				"certManager": obj{
+					"enabled": true,
+					"issuer": obj{
+						"type": "ca",
+					},
+				},
+			},
+			Config: Config{
+				Controllers: []string{
+					"nginx",
+				},
+			},
+			Expected: Spec{
+				Controller: ControllerSpec{
+					Type: "nginx",
+				},
+				CertManager: CertManagerSpec{
+					Enabled: true,
+					Issuer: IssuerSpec{
+						Type: "ca",
+					},
+				},
+			},
+			Validation: invalidSpecFieldError{
+				FieldName: "ca.secretId",
+				Reason:    "is required",
+			},
+		},
 		"unavailable controller type": {
 			Input: obj{
 				"controller": obj{
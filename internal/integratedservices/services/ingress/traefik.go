@@ -144,25 +144,7 @@ func (m traefikManager) compileChartValues(ctx context.Context, clusterID uint,
 	}
 
 	if cluster.Cloud == pkgcluster.Amazon {
-		const (
-			tagsKey = "service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags"
-			sep     = ","
-		)
-
-		var tags []string
-
-		if tagsVal := typedValues.Service.Annotations[tagsKey]; tagsVal != "" {
-			tags = strings.Split(tagsVal, sep)
-		}
-
-		for _, tag := range amazon.PipelineTags() {
-			tags = append(tags, fmt.Sprintf("%s=%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
-		}
-
-		if typedValues.Service.Annotations == nil {
-			typedValues.Service.Annotations = make(map[string]string)
-		}
-		typedValues.Service.Annotations[tagsKey] = strings.Join(tags, sep)
+		typedValues.Service.Annotations = addAWSLoadBalancerTags(typedValues.Service.Annotations)
 	}
 
 	untypedValues, err := jsonstructure.Encode(typedValues, jsonstructure.WithZeroStructsAsEmpty)
@@ -178,6 +160,31 @@ func (m traefikManager) compileChartValues(ctx context.Context, clusterID uint,
 	return finalValues, nil
 }
 
+// addAWSLoadBalancerTags adds the Pipeline tags to the additional resource tags annotation of the load balancer service.
+func addAWSLoadBalancerTags(annotations map[string]string) map[string]string {
+	const (
+		tagsKey = "service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags"
+		sep     = ","
+	)
+
+	var tags []string
+
+	if tagsVal := annotations[tagsKey]; tagsVal != "" {
+		tags = strings.Split(tagsVal, sep)
+	}
+
+	for _, tag := range amazon.PipelineTags() {
+		tags = append(tags, fmt.Sprintf("%s=%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[tagsKey] = strings.Join(tags, sep)
+
+	return annotations
+}
+
 func mergeServiceAnnotations(dst map[string]string, src map[string]string) map[string]string {
 	if len(src) == 0 {
 		return dst
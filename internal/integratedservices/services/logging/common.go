@@ -27,6 +27,10 @@ const (
 	providerAzure      = "azure"
 	providerLoki       = "loki"
 
+	providerElasticsearch = "elasticsearch"
+	providerKafka         = "kafka"
+	providerSyslog        = "syslog"
+
 	tlsSecretName              = "logging-tls-secret"
 	loggingOperatorReleaseName = "logging-operator"
 	lokiReleaseName            = "loki"
@@ -44,6 +48,11 @@ const (
 	outputDefinitionSecretKeyGCS                 = "credentials.json"
 	outputDefinitionSecretKeyAzureStorageAccount = "azureStorageAccount"
 	outputDefinitionSecretKeyAzureStorageAccess  = "azureStorageAccessKey"
+	outputDefinitionSecretKeyUsername            = "username"
+	outputDefinitionSecretKeyPassword            = "password"
+	outputDefinitionSecretKeyCACert              = "ca.crt"
+	outputDefinitionSecretKeyClientCert          = "tls.crt"
+	outputDefinitionSecretKeyClientKey           = "tls.key"
 
 	lokiOutputDefinitionName = "loki-output"
 	flowResourceName         = "banzai-logging-flow"
@@ -16,9 +16,11 @@ package logging
 
 import (
 	"context"
+	"strings"
 
 	"emperror.dev/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
@@ -169,10 +171,16 @@ func (d dummyHelmService) GetDeployment(ctx context.Context, clusterID uint, rel
 }
 
 type dummyKubernetesService struct {
+	Objects []unstructured.Unstructured
+	Deleted []string
 }
 
 // DeleteObject deletes an Object from a specific cluster.
 func (s *dummyKubernetesService) DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	if u, ok := o.(*unstructured.Unstructured); ok {
+		s.Deleted = append(s.Deleted, u.GetKind()+"/"+u.GetNamespace()+"/"+u.GetName())
+	}
+
 	return nil
 }
 
@@ -182,6 +190,15 @@ func (s *dummyKubernetesService) EnsureObject(ctx context.Context, clusterID uin
 }
 
 func (s *dummyKubernetesService) List(ctx context.Context, clusterID uint, labels map[string]string, o runtime.Object) error {
+	if list, ok := o.(*unstructured.UnstructuredList); ok {
+		kind := strings.TrimSuffix(list.GetKind(), "List")
+		for _, item := range s.Objects {
+			if item.GetKind() == kind {
+				list.Items = append(list.Items, item)
+			}
+		}
+	}
+
 	return nil
 }
 
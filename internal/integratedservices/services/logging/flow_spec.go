@@ -0,0 +1,41 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+)
+
+// flowResourceSpec describes the spec of Flow and ClusterFlow resources with match rules,
+// grep filters and global output references, that are supported by the logging operator,
+// but not available in the SDK version in use.
+type flowResourceSpec struct {
+	Match            []flowMatchSpec `json:"match,omitempty"`
+	Filters          []flowFilter    `json:"filters,omitempty"`
+	GlobalOutputRefs []string        `json:"globalOutputRefs"`
+}
+
+type flowFilter struct {
+	v1beta1.Filter `json:",inline"`
+
+	Grep *grepFilterConfig `json:"grep,omitempty"`
+}
+
+type grepFilterConfig struct {
+	// Keep records whose key matches the pattern
+	Regexp []grepPatternSpec `json:"regexp,omitempty"`
+	// Drop records whose key matches the pattern
+	Exclude []grepPatternSpec `json:"exclude,omitempty"`
+}
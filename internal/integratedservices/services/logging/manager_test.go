@@ -245,6 +245,296 @@ func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
 			},
 			Error: true,
 		},
+		"multiple providers": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name":     "elasticsearch",
+							"secretId": "essecret",
+							"elasticsearch": obj{
+								"host":   "elasticsearch.logging.svc",
+								"scheme": "https",
+							},
+						},
+						obj{
+							"name": "kafka",
+							"kafka": obj{
+								"brokers": []interface{}{"kafka-0:9092", "kafka-1:9092"},
+								"topic":   "cluster-logs",
+							},
+						},
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host":      "syslog.example.org",
+								"transport": "tcp",
+							},
+						},
+					},
+				},
+			},
+			Error: false,
+		},
+		"duplicated provider": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"provider": obj{
+						"name": "syslog",
+						"syslog": obj{
+							"host": "syslog.example.org",
+						},
+					},
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog2.example.org",
+							},
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+		"multiple outputs of the same provider": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"provider": obj{
+						"name": "syslog",
+						"syslog": obj{
+							"host": "syslog.example.org",
+						},
+					},
+					"providers": []interface{}{
+						obj{
+							"name":       "syslog",
+							"outputName": "audit-syslog",
+							"syslog": obj{
+								"host": "audit.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":      "audit",
+						"namespace": "audit",
+						"outputs":   []interface{}{"audit-syslog"},
+					},
+				},
+			},
+			Error: false,
+		},
+		"invalid output name": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name":       "syslog",
+							"outputName": "Audit_Syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+		"reserved output name": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name":       "syslog",
+							"outputName": "loki",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+		"kafka topic required": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "kafka",
+							"kafka": obj{
+								"brokers": []interface{}{"kafka-0:9092"},
+							},
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid syslog transport": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host":      "syslog.example.org",
+								"transport": "http",
+							},
+						},
+					},
+				},
+			},
+			Error: true,
+		},
+		"valid flows": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":      "team-a",
+						"namespace": "team-a",
+						"match": []interface{}{
+							obj{"select": obj{"labels": obj{"app": "web"}}},
+						},
+						"filters": []interface{}{
+							obj{"grep": obj{"exclude": []interface{}{obj{"key": "path", "pattern": "^/healthz$"}}}},
+							obj{"parser": obj{"type": "json", "reserveData": true}},
+						},
+						"outputs": []interface{}{"syslog"},
+					},
+					obj{
+						"name": "system",
+						"match": []interface{}{
+							obj{"select": obj{"namespaces": []interface{}{"kube-system"}}},
+						},
+						"outputs": []interface{}{"syslog"},
+					},
+				},
+			},
+			Error: false,
+		},
+		"flow output not enabled": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":      "team-a",
+						"namespace": "team-a",
+						"outputs":   []interface{}{"kafka"},
+					},
+				},
+			},
+			Error: true,
+		},
+		"flow with fluentd regular expressions": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":      "team-a",
+						"namespace": "team-a",
+						"filters": []interface{}{
+							obj{"grep": obj{"include": []interface{}{obj{"key": "log", "pattern": "(?<level>error|warn)"}}}},
+							obj{"parser": obj{"type": "regexp", "expression": "/^(?<time>[^ ]*) (?<message>.*)$/"}},
+						},
+						"outputs": []interface{}{"syslog"},
+					},
+				},
+			},
+			Error: false,
+		},
+		"namespace match in namespaced flow": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":      "team-a",
+						"namespace": "team-a",
+						"match": []interface{}{
+							obj{"select": obj{"namespaces": []interface{}{"team-b"}}},
+						},
+						"outputs": []interface{}{"syslog"},
+					},
+				},
+			},
+			Error: true,
+		},
+		"duplicated flow": {
+			Spec: integratedservices.IntegratedServiceSpec{
+				"clusterOutput": obj{
+					"enabled": true,
+					"providers": []interface{}{
+						obj{
+							"name": "syslog",
+							"syslog": obj{
+								"host": "syslog.example.org",
+							},
+						},
+					},
+				},
+				"flows": []interface{}{
+					obj{
+						"name":    "system",
+						"outputs": []interface{}{"syslog"},
+					},
+					obj{
+						"name":    "system",
+						"outputs": []interface{}{"syslog"},
+					},
+				},
+			},
+			Error: true,
+		},
 		"invalid bucket provider": {
 			Spec: integratedservices.IntegratedServiceSpec{
 				"loki": obj{
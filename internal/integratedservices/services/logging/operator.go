@@ -124,10 +124,14 @@ func (op IntegratedServiceOperator) Apply(ctx context.Context, clusterID uint, s
 		return errors.WrapIf(err, "failed to create cluster output definitions")
 	}
 
-	if err := op.createClusterFlowResource(ctx, outputManagers, cl.GetID()); err != nil {
+	if err := op.createClusterFlowResource(ctx, outputManagers, boundSpec.Flows, cl.GetID()); err != nil {
 		return errors.WrapIf(err, "failed to create cluster flow resource")
 	}
 
+	if err := op.createFlowResources(ctx, outputManagers, boundSpec.Flows, cl.GetID()); err != nil {
+		return errors.WrapIf(err, "failed to create flow resources")
+	}
+
 	return nil
 }
 
@@ -142,6 +146,11 @@ func (op IntegratedServiceOperator) Deactivate(ctx context.Context, clusterID ui
 		return err
 	}
 
+	// delete flows (namespaced flows are outside of the logging namespace, so they are not removed with the releases)
+	if err := op.deleteFlowResources(ctx, clusterID, nil); err != nil {
+		return errors.WrapIf(err, "failed to delete flow resources")
+	}
+
 	// delete Loki deployment
 	if err := op.helmService.DeleteDeployment(ctx, clusterID, lokiReleaseName, op.config.Namespace); err != nil {
 		return errors.WrapIfWithDetails(err, "failed to delete deployment", "release", lokiReleaseName)
@@ -16,21 +16,47 @@ package logging
 
 import (
 	"context"
+	"encoding/json"
 
 	"emperror.dev/errors"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/model/filter"
 	corev1 "k8s.io/api/core/v1"
 	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func (op IntegratedServiceOperator) createClusterFlowResource(ctx context.Context, managers []outputDefinitionManager, clusterID uint) error {
-	if len(managers) == 0 {
-		// create flow only in case of non empty output list
-		return nil
+func (op IntegratedServiceOperator) createClusterFlowResource(ctx context.Context, managers []outputDefinitionManager, flows []flowSpec, clusterID uint) error {
+	// outputs that are referenced by flows only receive the logs of those flows
+	var routedOutputs = make(map[string]bool)
+	for _, flow := range flows {
+		for _, output := range flow.Outputs {
+			routedOutputs[output] = true
+		}
+	}
+
+	var defaultManagers []outputDefinitionManager
+	for _, m := range managers {
+		if !routedOutputs[getOutputName(m)] {
+			defaultManagers = append(defaultManagers, m)
+		}
 	}
 
-	var flowResource = op.generateFlowResource(managers)
+	if len(defaultManagers) == 0 {
+		// remove the default flow in case no output receives every log
+		err := op.kubernetesService.DeleteObject(ctx, clusterID, &v1beta1.ClusterFlow{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      flowResourceName,
+				Namespace: op.config.Namespace,
+			},
+		})
+
+		return errors.WrapIf(err, "failed to delete ClusterFlow resource")
+	}
+
+	var flowResource = op.generateFlowResource(defaultManagers)
 
 	var oldFlow v1beta1.ClusterFlow
 	if err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
@@ -67,3 +93,173 @@ func (op IntegratedServiceOperator) generateFlowResource(definitions []outputDef
 		},
 	}
 }
+
+// createFlowResources renders the flows of the spec into Flow and ClusterFlow resources and
+// removes the ones that are no longer part of the spec.
+func (op IntegratedServiceOperator) createFlowResources(ctx context.Context, managers []outputDefinitionManager, flows []flowSpec, clusterID uint) error {
+	var outputNames = make(map[string]string, len(managers))
+	for _, m := range managers {
+		outputNames[getOutputName(m)] = m.getName()
+	}
+
+	var desired = make(map[string]bool, len(flows))
+	for _, flow := range flows {
+		resource, err := op.generateFlow(flow, outputNames)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to generate flow resource", "flow", flow.Name)
+		}
+		desired[flowResourceKey(resource.GetKind(), resource.GetNamespace(), resource.GetName())] = true
+
+		var oldResource unstructured.Unstructured
+		oldResource.SetGroupVersionKind(resource.GroupVersionKind())
+		if err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+			Namespace: resource.GetNamespace(),
+			Name:      resource.GetName(),
+		}, &oldResource); err != nil {
+			if !k8sapierrors.IsNotFound(err) {
+				return errors.WrapIfWithDetails(err, "failed to get flow resource", "flow", flow.Name)
+			}
+
+			if err := op.kubernetesService.EnsureObject(ctx, clusterID, resource); err != nil {
+				return errors.WrapIfWithDetails(err, "failed to create flow resource", "flow", flow.Name)
+			}
+
+			continue
+		}
+
+		resource.SetResourceVersion(oldResource.GetResourceVersion())
+		if err := op.kubernetesService.Update(ctx, clusterID, resource); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to update flow resource", "flow", flow.Name)
+		}
+	}
+
+	// remove old flows with integrated service labels
+	return op.deleteFlowResources(ctx, clusterID, desired)
+}
+
+// deleteFlowResources removes the Flow and ClusterFlow resources created for the flows of the spec,
+// except the ones in keep (keyed by flowResourceKey).
+func (op IntegratedServiceOperator) deleteFlowResources(ctx context.Context, clusterID uint, keep map[string]bool) error {
+	for _, kind := range []string{"Flow", "ClusterFlow"} {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(v1beta1.GroupVersion.WithKind(kind + "List"))
+		if err := op.kubernetesService.List(ctx, clusterID, map[string]string{resourceLabelKey: integratedServiceName}, &list); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to list flow resources", "kind", kind)
+		}
+
+		for i := range list.Items {
+			var item = &list.Items[i]
+			if item.GetName() == flowResourceName && kind == "ClusterFlow" {
+				// the default cluster flow is managed separately
+				continue
+			}
+
+			if keep[flowResourceKey(kind, item.GetNamespace(), item.GetName())] {
+				continue
+			}
+
+			item.SetGroupVersionKind(v1beta1.GroupVersion.WithKind(kind))
+			if err := op.kubernetesService.DeleteObject(ctx, clusterID, item); err != nil {
+				return errors.WrapIfWithDetails(err, "failed to delete flow resource", "kind", kind, "name", item.GetName())
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateFlow renders a flow into a Flow resource in case it has a namespace,
+// and into a ClusterFlow resource in the logging namespace otherwise.
+func (op IntegratedServiceOperator) generateFlow(flow flowSpec, outputNames map[string]string) (*unstructured.Unstructured, error) {
+	var spec = flowResourceSpec{
+		Match: flow.Match,
+	}
+
+	for _, output := range flow.Outputs {
+		name, ok := outputNames[output]
+		if !ok {
+			return nil, errors.Errorf("output %q is not enabled", output)
+		}
+		spec.GlobalOutputRefs = append(spec.GlobalOutputRefs, name)
+	}
+
+	for _, f := range flow.Filters {
+		spec.Filters = append(spec.Filters, generateFlowFilter(f))
+	}
+
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to marshal flow spec")
+	}
+
+	var flowSpec map[string]interface{}
+	if err := json.Unmarshal(rawSpec, &flowSpec); err != nil {
+		return nil, errors.WrapIf(err, "failed to unmarshal flow spec")
+	}
+
+	var gvk schema.GroupVersionKind
+	var namespace string
+	if flow.Namespace != "" {
+		gvk = v1beta1.GroupVersion.WithKind("Flow")
+		namespace = flow.Namespace
+	} else {
+		gvk = v1beta1.GroupVersion.WithKind("ClusterFlow")
+		namespace = op.config.Namespace
+	}
+
+	resource := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": flowSpec,
+		},
+	}
+	resource.SetGroupVersionKind(gvk)
+	resource.SetName(flow.Name)
+	resource.SetNamespace(namespace)
+	resource.SetLabels(map[string]string{resourceLabelKey: integratedServiceName})
+
+	return resource, nil
+}
+
+func generateFlowFilter(spec flowFilterSpec) flowFilter {
+	var f flowFilter
+	if spec.Grep != nil {
+		f.Grep = &grepFilterConfig{
+			Regexp:  spec.Grep.Include,
+			Exclude: spec.Grep.Exclude,
+		}
+	}
+
+	if spec.Parser != nil {
+		var keyName = spec.Parser.KeyName
+		if keyName == "" {
+			keyName = "message"
+		}
+
+		f.Parser = &filter.ParserConfig{
+			KeyName:            keyName,
+			ReserveData:        spec.Parser.ReserveData,
+			RemoveKeyNameField: spec.Parser.RemoveKeyNameField,
+			Parse: filter.ParseSection{
+				Type:       spec.Parser.Type,
+				Expression: spec.Parser.Expression,
+				TimeKey:    spec.Parser.TimeKey,
+				TimeFormat: spec.Parser.TimeFormat,
+			},
+		}
+	}
+
+	return f
+}
+
+// getOutputName returns the name flows use to reference the output of a manager.
+func getOutputName(m outputDefinitionManager) string {
+	if _, ok := m.(outputDefinitionManagerLoki); ok {
+		return providerLoki
+	}
+
+	return m.getProviderSpec().getOutputName()
+}
+
+func flowResourceKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
@@ -0,0 +1,94 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFlow(t *testing.T) {
+	op := IntegratedServiceOperator{config: Config{Namespace: "logging"}}
+	outputNames := map[string]string{
+		providerSyslog: "syslog-output",
+		providerLoki:   lokiOutputDefinitionName,
+	}
+
+	t.Run("namespaced flow", func(t *testing.T) {
+		flow := flowSpec{
+			Name:      "team-a",
+			Namespace: "team-a",
+			Match: []flowMatchSpec{
+				{Select: &flowSelectorSpec{Labels: map[string]string{"app": "web"}}},
+			},
+			Filters: []flowFilterSpec{
+				{Grep: &grepFilterSpec{Exclude: []grepPatternSpec{{Key: "path", Pattern: "^/healthz$"}}}},
+				{Parser: &parserFilterSpec{Type: "json", ReserveData: true}},
+			},
+			Outputs: []string{providerSyslog, providerLoki},
+		}
+
+		resource, err := op.generateFlow(flow, outputNames)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Flow", resource.GetKind())
+		assert.Equal(t, "team-a", resource.GetNamespace())
+		assert.Equal(t, "team-a", resource.GetName())
+		assert.Equal(t, map[string]string{resourceLabelKey: integratedServiceName}, resource.GetLabels())
+		assert.Equal(t, obj{
+			"match": []interface{}{
+				obj{"select": obj{"labels": obj{"app": "web"}}},
+			},
+			"filters": []interface{}{
+				obj{"grep": obj{"exclude": []interface{}{obj{"key": "path", "pattern": "^/healthz$"}}}},
+				obj{"parser": obj{
+					"key_name":     "message",
+					"reserve_data": true,
+					"parse":        obj{"type": "json"},
+				}},
+			},
+			"globalOutputRefs": []interface{}{"syslog-output", lokiOutputDefinitionName},
+		}, resource.Object["spec"])
+	})
+
+	t.Run("cluster flow", func(t *testing.T) {
+		flow := flowSpec{
+			Name: "system",
+			Match: []flowMatchSpec{
+				{Select: &flowSelectorSpec{Namespaces: []string{"kube-system"}}},
+			},
+			Outputs: []string{providerSyslog},
+		}
+
+		resource, err := op.generateFlow(flow, outputNames)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ClusterFlow", resource.GetKind())
+		assert.Equal(t, "logging", resource.GetNamespace())
+		assert.Equal(t, obj{
+			"match": []interface{}{
+				obj{"select": obj{"namespaces": []interface{}{"kube-system"}}},
+			},
+			"globalOutputRefs": []interface{}{"syslog-output"},
+		}, resource.Object["spec"])
+	})
+
+	t.Run("disabled output", func(t *testing.T) {
+		_, err := op.generateFlow(flowSpec{Name: "system", Outputs: []string{providerKafka}}, outputNames)
+		assert.Error(t, err)
+	})
+}
@@ -26,21 +26,27 @@ import (
 func (op IntegratedServiceOperator) createClusterOutputDefinitions(ctx context.Context, spec integratedServiceSpec, cl integratedserviceadapter.Cluster) ([]outputDefinitionManager, error) {
 	var creators []outputManagerCreator
 	if spec.ClusterOutput.Enabled {
-		// install secrets to cluster
-		sourceSecretName, err := op.secretStore.GetNameByID(ctx, spec.ClusterOutput.Provider.SecretID)
-		if err != nil {
-			return nil, errors.WrapIfWithDetails(err, "failed to get secret name", "secretID", spec.ClusterOutput.Provider.SecretID)
-		}
-
-		if err := op.installSecretForOutput(ctx, spec.ClusterOutput, sourceSecretName, cl); err != nil {
-			return nil, errors.WrapIf(err, "failed to install secret to cluster for cluster output")
+		for _, provider := range spec.ClusterOutput.getProviders() {
+			var sourceSecretName string
+			if provider.SecretID != "" {
+				// install secrets to cluster
+				var err error
+				sourceSecretName, err = op.secretStore.GetNameByID(ctx, provider.SecretID)
+				if err != nil {
+					return nil, errors.WrapIfWithDetails(err, "failed to get secret name", "secretID", provider.SecretID)
+				}
+
+				if err := op.installSecretForOutput(ctx, provider, sourceSecretName, cl); err != nil {
+					return nil, errors.WrapIf(err, "failed to install secret to cluster for cluster output")
+				}
+			}
+
+			creators = append(creators, outputManagerCreator{
+				name:             provider.Name,
+				sourceSecretName: sourceSecretName,
+				providerSpec:     provider,
+			})
 		}
-
-		creators = append(creators, outputManagerCreator{
-			name:             spec.ClusterOutput.Provider.Name,
-			sourceSecretName: sourceSecretName,
-			providerSpec:     spec.ClusterOutput.Provider,
-		})
 	}
 
 	if spec.Loki.Enabled {
@@ -94,18 +100,18 @@ func (op IntegratedServiceOperator) getLokiServiceURL(cl integratedserviceadapte
 	return op.endpointsService.GetServiceURL(k8sConfig, lokiServiceName, op.config.Namespace)
 }
 
-func (op IntegratedServiceOperator) installSecretForOutput(ctx context.Context, spec clusterOutputSpec, sourceSecretName string, cl integratedserviceadapter.Cluster) error {
-	secretManager, err := newOutputSecretInstallManager(spec.Provider.Name, sourceSecretName, op.config.Namespace)
+func (op IntegratedServiceOperator) installSecretForOutput(ctx context.Context, spec providerSpec, sourceSecretName string, cl integratedserviceadapter.Cluster) error {
+	secretManager, err := newOutputSecretInstallManager(spec.Name, sourceSecretName, op.config.Namespace)
 	if err != nil {
 		return errors.WrapIf(err, "failed to create output secret installer")
 	}
 
-	secretValues, err := op.secretStore.GetSecretValues(ctx, spec.Provider.SecretID)
+	secretValues, err := op.secretStore.GetSecretValues(ctx, spec.SecretID)
 	if err != nil {
-		return errors.WrapIfWithDetails(err, "failed to get secret values", "secretID", spec.Provider.SecretID)
+		return errors.WrapIfWithDetails(err, "failed to get secret values", "secretID", spec.SecretID)
 	}
 
-	installSecretRequest, err := secretManager.generateSecretRequest(secretValues, spec.Provider.Bucket)
+	installSecretRequest, err := secretManager.generateSecretRequest(secretValues, spec.Bucket)
 	if err != nil {
 		return errors.WrapIf(err, "failed to generate install secret request")
 	}
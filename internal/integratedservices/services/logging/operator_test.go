@@ -18,7 +18,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
@@ -156,10 +158,27 @@ func TestIntegratedServiceOperator_Deactivate(t *testing.T) {
 	}
 	secretStore := commonadapter.NewSecretStore(orgSecretStore, commonadapter.OrgIDContextExtractorFunc(auth.GetCurrentOrganizationID))
 	logger := services.NoopLogger{}
-	kubernetesService := dummyKubernetesService{}
+	kubernetesService := dummyKubernetesService{
+		Objects: []unstructured.Unstructured{
+			newFlowResource("Flow", "team-a", "team-a"),
+			newFlowResource("ClusterFlow", "logging", "system"),
+			newFlowResource("ClusterFlow", "logging", flowResourceName),
+		},
+	}
 	op := MakeIntegratedServicesOperator(clusterGetter, clusterService, helmService, &kubernetesService, endpointService, Config{}, logger, secretStore)
 
 	ctx := context.Background()
 
 	_ = op.Deactivate(ctx, clusterID, nil)
+
+	assert.Equal(t, []string{"Flow/team-a/team-a", "ClusterFlow/logging/system"}, kubernetesService.Deleted)
+}
+
+func newFlowResource(kind, namespace, name string) unstructured.Unstructured {
+	var resource unstructured.Unstructured
+	resource.SetGroupVersionKind(v1beta1.GroupVersion.WithKind(kind))
+	resource.SetNamespace(namespace)
+	resource.SetName(name)
+
+	return resource
 }
@@ -17,9 +17,11 @@ package logging
 import (
 	"context"
 
+	"encoding/json"
+
 	"emperror.dev/errors"
 	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/banzaicloud/pipeline/internal/common"
 )
@@ -32,7 +34,7 @@ type outputManagerCreator struct {
 }
 
 type outputDefinitionManager interface {
-	getOutputSpec(bucketSpec, bucketOptions) v1beta1.ClusterOutputSpec
+	getOutputSpec(bucketSpec, bucketOptions) outputSpec
 	getProviderSpec() providerSpec
 	getName() string
 }
@@ -52,6 +54,12 @@ func newOutputDefinitionManager(creators []outputManagerCreator) (managers []out
 			managers = append(managers, outputDefinitionManagerAzure{baseOutputManager: baseManager})
 		case providerAlibabaOSS:
 			managers = append(managers, outputDefinitionManagerOSS{baseOutputManager: baseManager})
+		case providerElasticsearch:
+			managers = append(managers, outputDefinitionManagerElasticsearch{baseOutputManager: baseManager})
+		case providerKafka:
+			managers = append(managers, outputDefinitionManagerKafka{baseOutputManager: baseManager})
+		case providerSyslog:
+			managers = append(managers, outputDefinitionManagerSyslog{baseOutputManager: baseManager})
 		case providerLoki:
 			managers = append(managers, outputDefinitionManagerLoki{serviceURL: creator.serviceURL})
 		}
@@ -66,7 +74,7 @@ func generateOutputDefinition(
 	secretStore common.SecretStore,
 	namespace string,
 	orgID uint,
-) (*v1beta1.ClusterOutput, error) {
+) (*unstructured.Unstructured, error) {
 	var spec = m.getProviderSpec()
 	var bucketOptions = &bucketOptions{}
 	if spec.SecretID != "" {
@@ -81,12 +89,27 @@ func generateOutputDefinition(
 		}
 	}
 
-	return &v1beta1.ClusterOutput{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      m.getName(),
-			Namespace: namespace,
-			Labels:    map[string]string{resourceLabelKey: integratedServiceName},
+	// the output spec can contain settings that are not available in the logging operator SDK,
+	// so the resource is created as an unstructured object
+	rawSpec, err := json.Marshal(m.getOutputSpec(spec.Bucket, *bucketOptions))
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to marshal output spec")
+	}
+
+	var outputSpec map[string]interface{}
+	if err := json.Unmarshal(rawSpec, &outputSpec); err != nil {
+		return nil, errors.WrapIf(err, "failed to unmarshal output spec")
+	}
+
+	outputDefinition := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": outputSpec,
 		},
-		Spec: m.getOutputSpec(spec.Bucket, *bucketOptions),
-	}, nil
+	}
+	outputDefinition.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("ClusterOutput"))
+	outputDefinition.SetName(m.getName())
+	outputDefinition.SetNamespace(namespace)
+	outputDefinition.SetLabels(map[string]string{resourceLabelKey: integratedServiceName})
+
+	return outputDefinition, nil
 }
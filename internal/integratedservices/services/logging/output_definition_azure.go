@@ -24,12 +24,8 @@ type outputDefinitionManagerAzure struct {
 	baseOutputManager
 }
 
-func (outputDefinitionManagerAzure) getName() string {
-	return "azure-output"
-}
-
-func (m outputDefinitionManagerAzure) getOutputSpec(spec bucketSpec, _ bucketOptions) v1beta1.ClusterOutputSpec {
-	return v1beta1.ClusterOutputSpec{
+func (m outputDefinitionManagerAzure) getOutputSpec(spec bucketSpec, _ bucketOptions) outputSpec {
+	return outputSpec{
 		OutputSpec: v1beta1.OutputSpec{
 			AzureStorage: &output.AzureStorage{
 				Path: m.getPathSpec(),
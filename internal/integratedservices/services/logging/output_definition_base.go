@@ -14,7 +14,10 @@
 
 package logging
 
-import "github.com/banzaicloud/logging-operator/pkg/sdk/model/output"
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/model/output"
+	loggingSecret "github.com/banzaicloud/logging-operator/pkg/sdk/model/secret"
+)
 
 type baseOutputManager struct {
 	sourceSecretName string
@@ -36,3 +39,18 @@ func (baseOutputManager) getPathSpec() string {
 func (b baseOutputManager) getProviderSpec() providerSpec {
 	return b.providerSpec
 }
+
+func (b baseOutputManager) getName() string {
+	return b.providerSpec.getOutputName() + "-output"
+}
+
+func (b baseOutputManager) getSecretRef(key string) *loggingSecret.Secret {
+	return &loggingSecret.Secret{
+		ValueFrom: &loggingSecret.ValueFrom{
+			SecretKeyRef: &loggingSecret.KubernetesSecret{
+				Name: b.sourceSecretName,
+				Key:  key,
+			},
+		},
+	}
+}
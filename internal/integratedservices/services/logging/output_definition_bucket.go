@@ -33,6 +33,16 @@ type bucketOptions struct {
 	gcs *struct {
 		project string
 	}
+	elasticsearch *struct {
+		user string
+		tls  bool
+	}
+	kafka *struct {
+		sasl bool
+	}
+	syslog *struct {
+		tls bool
+	}
 }
 
 func generateBucketOptions(spec providerSpec, secretValues map[string]string, orgID uint) (*bucketOptions, error) {
@@ -46,6 +56,12 @@ func generateBucketOptions(spec providerSpec, secretValues map[string]string, or
 		return generateGCSBucketOptions(secretValues), nil
 	case providerAlibabaOSS:
 		return generateOSSBucketOptions(spec, secretItems, orgID)
+	case providerElasticsearch:
+		return generateElasticsearchOptions(secretValues), nil
+	case providerKafka:
+		return generateKafkaOptions(secretValues), nil
+	case providerSyslog:
+		return generateSyslogOptions(secretValues), nil
 	default:
 		return &bucketOptions{}, nil
 	}
@@ -88,3 +104,35 @@ func generateGCSBucketOptions(secretValues map[string]string) *bucketOptions {
 		},
 	}
 }
+
+func generateElasticsearchOptions(secretValues map[string]string) *bucketOptions {
+	return &bucketOptions{
+		elasticsearch: &struct {
+			user string
+			tls  bool
+		}{
+			user: secretValues[secrettype.Username],
+			tls:  secretValues[secrettype.ClientCert] != "",
+		},
+	}
+}
+
+func generateKafkaOptions(secretValues map[string]string) *bucketOptions {
+	return &bucketOptions{
+		kafka: &struct {
+			sasl bool
+		}{
+			sasl: secretValues[secrettype.Username] != "",
+		},
+	}
+}
+
+func generateSyslogOptions(secretValues map[string]string) *bucketOptions {
+	return &bucketOptions{
+		syslog: &struct {
+			tls bool
+		}{
+			tls: secretValues[secrettype.CACert] != "",
+		},
+	}
+}
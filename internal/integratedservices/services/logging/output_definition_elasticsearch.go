@@ -0,0 +1,69 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/model/output"
+)
+
+type outputDefinitionManagerElasticsearch struct {
+	baseOutputManager
+}
+
+func (m outputDefinitionManagerElasticsearch) getOutputSpec(_ bucketSpec, op bucketOptions) outputSpec {
+	spec := m.providerSpec.Elasticsearch
+
+	config := &elasticsearchOutputConfig{
+		ElasticsearchOutput: output.ElasticsearchOutput{
+			Host:           spec.Host,
+			Port:           spec.Port,
+			Scheme:         spec.Scheme,
+			Path:           spec.Path,
+			SslVerify:      !spec.SkipSSLVerify,
+			LogstashFormat: true,
+			LogstashPrefix: spec.LogstashPrefix,
+			Buffer:         m.getBufferSpec(),
+		},
+	}
+
+	if op.elasticsearch != nil {
+		if op.elasticsearch.user != "" {
+			config.User = op.elasticsearch.user
+			config.Password = m.getSecretRef(outputDefinitionSecretKeyPassword)
+		}
+
+		if op.elasticsearch.tls {
+			config.CAFile = m.getSecretRef(outputDefinitionSecretKeyCACert)
+			config.ClientCert = m.getSecretRef(outputDefinitionSecretKeyClientCert)
+			config.ClientKey = m.getSecretRef(outputDefinitionSecretKeyClientKey)
+
+			if config.Scheme == "" {
+				config.Scheme = "https"
+			}
+		}
+	}
+
+	if config.Port == 0 {
+		config.Port = 9200
+	}
+
+	if config.LogstashPrefix == "" {
+		config.LogstashPrefix = "logstash"
+	}
+
+	return outputSpec{
+		ElasticsearchOutput: config,
+	}
+}
@@ -24,12 +24,8 @@ type outputDefinitionManagerGCS struct {
 	baseOutputManager
 }
 
-func (outputDefinitionManagerGCS) getName() string {
-	return "gcs-output"
-}
-
-func (m outputDefinitionManagerGCS) getOutputSpec(spec bucketSpec, op bucketOptions) v1beta1.ClusterOutputSpec {
-	return v1beta1.ClusterOutputSpec{
+func (m outputDefinitionManagerGCS) getOutputSpec(spec bucketSpec, op bucketOptions) outputSpec {
+	return outputSpec{
 		OutputSpec: v1beta1.OutputSpec{
 			GCSOutput: &output.GCSOutput{
 				Project: op.gcs.project,
@@ -0,0 +1,51 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"strings"
+
+	"github.com/banzaicloud/logging-operator/pkg/sdk/model/output"
+)
+
+type outputDefinitionManagerKafka struct {
+	baseOutputManager
+}
+
+func (m outputDefinitionManagerKafka) getOutputSpec(_ bucketSpec, op bucketOptions) outputSpec {
+	spec := m.providerSpec.Kafka
+
+	config := &kafkaOutputConfig{
+		KafkaOutputConfig: output.KafkaOutputConfig{
+			Brokers:      strings.Join(spec.Brokers, ","),
+			DefaultTopic: spec.Topic,
+			SaslOverSSL:  spec.SASLOverSSL,
+			Format: &output.Format{
+				Type: "json",
+			},
+			Buffer: m.getBufferSpec(),
+		},
+	}
+
+	if op.kafka != nil && op.kafka.sasl {
+		config.Username = m.getSecretRef(outputDefinitionSecretKeyUsername)
+		config.Password = m.getSecretRef(outputDefinitionSecretKeyPassword)
+		config.ScramMechanism = spec.ScramMechanism
+	}
+
+	return outputSpec{
+		KafkaOutputConfig: config,
+	}
+}
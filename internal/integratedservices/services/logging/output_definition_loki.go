@@ -25,8 +25,8 @@ type outputDefinitionManagerLoki struct {
 	serviceURL string
 }
 
-func (o outputDefinitionManagerLoki) getOutputSpec(_ bucketSpec, _ bucketOptions) v1beta1.ClusterOutputSpec {
-	return v1beta1.ClusterOutputSpec{
+func (o outputDefinitionManagerLoki) getOutputSpec(_ bucketSpec, _ bucketOptions) outputSpec {
+	return outputSpec{
 		OutputSpec: v1beta1.OutputSpec{
 			LokiOutput: &output.LokiOutput{
 				Url:                       fmt.Sprintf("http://%s", o.serviceURL),
@@ -24,12 +24,8 @@ type outputDefinitionManagerOSS struct {
 	baseOutputManager
 }
 
-func (outputDefinitionManagerOSS) getName() string {
-	return "oss-output"
-}
-
-func (m outputDefinitionManagerOSS) getOutputSpec(spec bucketSpec, op bucketOptions) v1beta1.ClusterOutputSpec {
-	return v1beta1.ClusterOutputSpec{
+func (m outputDefinitionManagerOSS) getOutputSpec(spec bucketSpec, op bucketOptions) outputSpec {
+	return outputSpec{
 		OutputSpec: v1beta1.OutputSpec{
 			OSSOutput: &output.OSSOutput{
 				Endpoint: "",
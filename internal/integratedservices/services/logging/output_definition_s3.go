@@ -24,12 +24,8 @@ type outputDefinitionManagerS3 struct {
 	baseOutputManager
 }
 
-func (outputDefinitionManagerS3) getName() string {
-	return "s3-output"
-}
-
-func (m outputDefinitionManagerS3) getOutputSpec(spec bucketSpec, op bucketOptions) v1beta1.ClusterOutputSpec {
-	return v1beta1.ClusterOutputSpec{
+func (m outputDefinitionManagerS3) getOutputSpec(spec bucketSpec, op bucketOptions) outputSpec {
+	return outputSpec{
 		OutputSpec: v1beta1.OutputSpec{
 			S3OutputConfig: &output.S3OutputConfig{
 				AwsAccessKey: &loggingSecret.Secret{
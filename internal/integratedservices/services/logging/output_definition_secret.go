@@ -51,6 +51,11 @@ func newOutputSecretInstallManager(providerName, sourceSecretName, namespace str
 			sourceSecretName: sourceSecretName,
 			namespace:        namespace,
 		}}, nil
+	case providerElasticsearch, providerKafka, providerSyslog:
+		return outputSecretInstallManagerCredentials{baseOutputSecretInstallManager{
+			sourceSecretName: sourceSecretName,
+			namespace:        namespace,
+		}}, nil
 	default:
 		return nil, errors.NewWithDetails("unsupported provider", "provider", providerName)
 	}
@@ -0,0 +1,50 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	pkgCluster "github.com/banzaicloud/pipeline/src/cluster"
+)
+
+// outputSecretInstallManagerCredentials installs basic auth (password) and TLS secrets
+// used by the Elasticsearch, Kafka and syslog outputs.
+type outputSecretInstallManagerCredentials struct {
+	baseOutputSecretInstallManager
+}
+
+func (m outputSecretInstallManagerCredentials) generateSecretRequest(secretValues map[string]string, _ bucketSpec) (*pkgCluster.InstallSecretRequest, error) {
+	keys := map[string]string{
+		outputDefinitionSecretKeyUsername:   secrettype.Username,
+		outputDefinitionSecretKeyPassword:   secrettype.Password,
+		outputDefinitionSecretKeyCACert:     secrettype.CACert,
+		outputDefinitionSecretKeyClientCert: secrettype.ClientCert,
+		outputDefinitionSecretKeyClientKey:  secrettype.ClientKey,
+	}
+
+	spec := make(map[string]pkgCluster.InstallSecretRequestSpecItem)
+	for key, sourceKey := range keys {
+		if value := secretValues[sourceKey]; value != "" {
+			spec[key] = pkgCluster.InstallSecretRequestSpecItem{Value: value}
+		}
+	}
+
+	return &pkgCluster.InstallSecretRequest{
+		SourceSecretName: m.sourceSecretName,
+		Namespace:        m.namespace,
+		Spec:             spec,
+		Update:           true,
+	}, nil
+}
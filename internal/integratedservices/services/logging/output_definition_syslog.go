@@ -0,0 +1,46 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+type outputDefinitionManagerSyslog struct {
+	baseOutputManager
+}
+
+func (m outputDefinitionManagerSyslog) getOutputSpec(_ bucketSpec, op bucketOptions) outputSpec {
+	spec := m.providerSpec.Syslog
+
+	config := &syslogOutputConfig{
+		Host:      spec.Host,
+		Port:      spec.Port,
+		Transport: spec.Transport,
+		Insecure:  spec.Insecure,
+		Format: &syslogFormat{
+			Type: "json",
+		},
+		Buffer: m.getBufferSpec(),
+	}
+
+	if config.Port == 0 {
+		config.Port = 514
+	}
+
+	if op.syslog != nil && op.syslog.tls {
+		config.TrustedCaPath = m.getSecretRef(outputDefinitionSecretKeyCACert)
+	}
+
+	return outputSpec{
+		SyslogOutputConfig: config,
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	"github.com/banzaicloud/pipeline/src/auth"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+func TestGenerateOutputDefinition(t *testing.T) {
+	orgID := uint(13)
+
+	orgSecretStore := dummyOrganizationalSecretStore{
+		Secrets: map[uint]map[string]*secret.SecretItemResponse{
+			orgID: {
+				"essecret": {
+					ID:     "essecret",
+					Name:   "es-credentials",
+					Type:   secrettype.PasswordSecretType,
+					Values: map[string]string{secrettype.Username: "elastic", secrettype.Password: "pass"},
+				},
+				"kafkasecret": {
+					ID:     "kafkasecret",
+					Name:   "kafka-credentials",
+					Type:   secrettype.PasswordSecretType,
+					Values: map[string]string{secrettype.Username: "producer", secrettype.Password: "pass"},
+				},
+			},
+		},
+	}
+	secretStore := commonadapter.NewSecretStore(orgSecretStore, commonadapter.OrgIDContextExtractorFunc(auth.GetCurrentOrganizationID))
+
+	secretRef := func(name, key string) obj {
+		return obj{
+			"valueFrom": obj{
+				"secretKeyRef": obj{
+					"name": name,
+					"key":  key,
+				},
+			},
+		}
+	}
+
+	buffer := obj{
+		"timekey":         "1m",
+		"timekey_wait":    "10s",
+		"timekey_use_utc": true,
+	}
+
+	testCases := map[string]struct {
+		Creator      outputManagerCreator
+		ExpectedName string
+		ExpectedSpec obj
+	}{
+		"elasticsearch with basic auth": {
+			Creator: outputManagerCreator{
+				name:             providerElasticsearch,
+				sourceSecretName: "es-credentials",
+				providerSpec: providerSpec{
+					Name:     providerElasticsearch,
+					SecretID: "essecret",
+					Elasticsearch: elasticsearchSpec{
+						Host:   "elasticsearch.logging.svc",
+						Scheme: "https",
+					},
+				},
+			},
+			ExpectedName: "elasticsearch-output",
+			ExpectedSpec: obj{
+				"elasticsearch": obj{
+					"host":            "elasticsearch.logging.svc",
+					"port":            9200.0,
+					"scheme":          "https",
+					"ssl_verify":      true,
+					"logstash_format": true,
+					"logstash_prefix": "logstash",
+					"user":            "elastic",
+					"password":        secretRef("es-credentials", "password"),
+					"buffer":          buffer,
+				},
+			},
+		},
+		"kafka with sasl": {
+			Creator: outputManagerCreator{
+				name:             providerKafka,
+				sourceSecretName: "kafka-credentials",
+				providerSpec: providerSpec{
+					Name:     providerKafka,
+					SecretID: "kafkasecret",
+					Kafka: kafkaSpec{
+						Brokers:        []string{"kafka-0:9092", "kafka-1:9092"},
+						Topic:          "cluster-logs",
+						SASLOverSSL:    true,
+						ScramMechanism: "sha512",
+					},
+				},
+			},
+			ExpectedName: "kafka-output",
+			ExpectedSpec: obj{
+				"kafka": obj{
+					"brokers":         "kafka-0:9092,kafka-1:9092",
+					"default_topic":   "cluster-logs",
+					"sasl_over_ssl":   true,
+					"username":        secretRef("kafka-credentials", "username"),
+					"password":        secretRef("kafka-credentials", "password"),
+					"scram_mechanism": "sha512",
+					"format": obj{
+						"type": "json",
+					},
+					"buffer": buffer,
+				},
+			},
+		},
+		"syslog without secret": {
+			Creator: outputManagerCreator{
+				name: providerSyslog,
+				providerSpec: providerSpec{
+					Name: providerSyslog,
+					Syslog: syslogSpec{
+						Host:      "syslog.example.org",
+						Transport: "tcp",
+					},
+				},
+			},
+			ExpectedName: "syslog-output",
+			ExpectedSpec: obj{
+				"syslog": obj{
+					"host":      "syslog.example.org",
+					"port":      514.0,
+					"transport": "tcp",
+					"format": obj{
+						"type": "json",
+					},
+					"buffer": buffer,
+				},
+			},
+		},
+		"named syslog output": {
+			Creator: outputManagerCreator{
+				name: providerSyslog,
+				providerSpec: providerSpec{
+					Name:       providerSyslog,
+					OutputName: "audit-syslog",
+					Syslog: syslogSpec{
+						Host: "audit.example.org",
+						Port: 601,
+					},
+				},
+			},
+			ExpectedName: "audit-syslog-output",
+			ExpectedSpec: obj{
+				"syslog": obj{
+					"host": "audit.example.org",
+					"port": 601.0,
+					"format": obj{
+						"type": "json",
+					},
+					"buffer": buffer,
+				},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ctx := auth.SetCurrentOrganizationID(context.Background(), orgID)
+
+			managers := newOutputDefinitionManager([]outputManagerCreator{tc.Creator})
+			require.Len(t, managers, 1)
+
+			outputDefinition, err := generateOutputDefinition(ctx, managers[0], secretStore, "logging", orgID)
+			require.NoError(t, err)
+
+			assert.Equal(t, "ClusterOutput", outputDefinition.GetKind())
+			assert.Equal(t, tc.ExpectedName, outputDefinition.GetName())
+			assert.Equal(t, "logging", outputDefinition.GetNamespace())
+			assert.Equal(t, map[string]string{resourceLabelKey: integratedServiceName}, outputDefinition.GetLabels())
+			assert.Equal(t, tc.ExpectedSpec, outputDefinition.Object["spec"])
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/banzaicloud/logging-operator/pkg/sdk/api/v1beta1"
+	"github.com/banzaicloud/logging-operator/pkg/sdk/model/output"
+	loggingSecret "github.com/banzaicloud/logging-operator/pkg/sdk/model/secret"
+)
+
+// outputSpec extends the output spec of the logging operator SDK with plugin settings
+// that are supported by the logging operator, but not available in the SDK version in use.
+type outputSpec struct {
+	v1beta1.OutputSpec `json:",inline"`
+
+	ElasticsearchOutput *elasticsearchOutputConfig `json:"elasticsearch,omitempty"`
+	KafkaOutputConfig   *kafkaOutputConfig         `json:"kafka,omitempty"`
+	SyslogOutputConfig  *syslogOutputConfig        `json:"syslog,omitempty"`
+}
+
+type elasticsearchOutputConfig struct {
+	output.ElasticsearchOutput `json:",inline"`
+
+	// CA certificate
+	CAFile *loggingSecret.Secret `json:"ca_file,omitempty"`
+	// Client certificate
+	ClientCert *loggingSecret.Secret `json:"client_cert,omitempty"`
+	// Client certificate key
+	ClientKey *loggingSecret.Secret `json:"client_key,omitempty"`
+}
+
+type kafkaOutputConfig struct {
+	output.KafkaOutputConfig `json:",inline"`
+
+	// Username when using SASL authentication
+	Username *loggingSecret.Secret `json:"username,omitempty"`
+	// Password when using SASL authentication
+	Password *loggingSecret.Secret `json:"password,omitempty"`
+	// If set, use SCRAM authentication with the specified mechanism (sha256, sha512)
+	ScramMechanism string `json:"scram_mechanism,omitempty"`
+}
+
+type syslogOutputConfig struct {
+	// Destination host address
+	Host string `json:"host"`
+	// Destination host port (default: 514)
+	Port int `json:"port,omitempty"`
+	// Transport protocol: udp, tcp or tls (default: tls)
+	Transport string `json:"transport,omitempty"`
+	// Skip the verification of the server certificate
+	Insecure bool `json:"insecure,omitempty"`
+	// CA certificate to verify the server certificate with
+	TrustedCaPath *loggingSecret.Secret `json:"trusted_ca_path,omitempty"`
+	Format        *syslogFormat         `json:"format,omitempty"`
+	Buffer        *output.Buffer        `json:"buffer,omitempty"`
+}
+
+type syslogFormat struct {
+	Type string `json:"type,omitempty"`
+}
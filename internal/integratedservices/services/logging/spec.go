@@ -15,8 +15,11 @@
 package logging
 
 import (
+	"strings"
+
 	"emperror.dev/errors"
 	"github.com/mitchellh/mapstructure"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
 	"github.com/banzaicloud/pipeline/src/dns"
@@ -26,6 +29,7 @@ type integratedServiceSpec struct {
 	Loki          lokiSpec          `json:"loki" mapstructure:"loki"`
 	Logging       loggingSpec       `json:"logging" mapstructure:"logging"`
 	ClusterOutput clusterOutputSpec `json:"clusterOutput" mapstructure:"clusterOutput"`
+	Flows         []flowSpec        `json:"flows" mapstructure:"flows"`
 }
 
 type lokiSpec struct {
@@ -46,14 +50,107 @@ type loggingSpec struct {
 }
 
 type clusterOutputSpec struct {
-	Enabled  bool         `json:"enabled" mapstructure:"enabled"`
-	Provider providerSpec `json:"provider" mapstructure:"provider"`
+	Enabled   bool           `json:"enabled" mapstructure:"enabled"`
+	Provider  providerSpec   `json:"provider" mapstructure:"provider"`
+	Providers []providerSpec `json:"providers" mapstructure:"providers"`
+}
+
+// getProviders returns every configured output provider, including the single provider of older specs.
+func (s clusterOutputSpec) getProviders() []providerSpec {
+	var providers []providerSpec
+	if s.Provider.Name != "" || s.Provider.SecretID != "" || len(s.Providers) == 0 {
+		providers = append(providers, s.Provider)
+	}
+
+	return append(providers, s.Providers...)
 }
 
 type providerSpec struct {
-	Name     string     `json:"name" mapstructure:"name"`
-	Bucket   bucketSpec `json:"bucket" mapstructure:"bucket"`
-	SecretID string     `json:"secretId" mapstructure:"secretId"`
+	Name          string            `json:"name" mapstructure:"name"`
+	OutputName    string            `json:"outputName" mapstructure:"outputName"`
+	Bucket        bucketSpec        `json:"bucket" mapstructure:"bucket"`
+	SecretID      string            `json:"secretId" mapstructure:"secretId"`
+	Elasticsearch elasticsearchSpec `json:"elasticsearch" mapstructure:"elasticsearch"`
+	Kafka         kafkaSpec         `json:"kafka" mapstructure:"kafka"`
+	Syslog        syslogSpec        `json:"syslog" mapstructure:"syslog"`
+}
+
+// getOutputName returns the name that identifies the output, which defaults to the provider name.
+func (s providerSpec) getOutputName() string {
+	if s.OutputName != "" {
+		return s.OutputName
+	}
+
+	return s.Name
+}
+
+type elasticsearchSpec struct {
+	Host           string `json:"host" mapstructure:"host"`
+	Port           int    `json:"port" mapstructure:"port"`
+	Scheme         string `json:"scheme" mapstructure:"scheme"`
+	Path           string `json:"path" mapstructure:"path"`
+	LogstashPrefix string `json:"logstashPrefix" mapstructure:"logstashPrefix"`
+	SkipSSLVerify  bool   `json:"skipSslVerify" mapstructure:"skipSslVerify"`
+}
+
+type kafkaSpec struct {
+	Brokers        []string `json:"brokers" mapstructure:"brokers"`
+	Topic          string   `json:"topic" mapstructure:"topic"`
+	SASLOverSSL    bool     `json:"saslOverSsl" mapstructure:"saslOverSsl"`
+	ScramMechanism string   `json:"scramMechanism" mapstructure:"scramMechanism"`
+}
+
+type syslogSpec struct {
+	Host      string `json:"host" mapstructure:"host"`
+	Port      int    `json:"port" mapstructure:"port"`
+	Transport string `json:"transport" mapstructure:"transport"`
+	Insecure  bool   `json:"insecure" mapstructure:"insecure"`
+}
+
+// flowSpec describes a log flow routed to a subset of the configured outputs.
+// Flows with a namespace are rendered into a Flow in that namespace, others into a ClusterFlow.
+type flowSpec struct {
+	Name      string           `json:"name" mapstructure:"name"`
+	Namespace string           `json:"namespace" mapstructure:"namespace"`
+	Match     []flowMatchSpec  `json:"match" mapstructure:"match"`
+	Filters   []flowFilterSpec `json:"filters" mapstructure:"filters"`
+	Outputs   []string         `json:"outputs" mapstructure:"outputs"`
+}
+
+type flowMatchSpec struct {
+	Select  *flowSelectorSpec `json:"select,omitempty" mapstructure:"select"`
+	Exclude *flowSelectorSpec `json:"exclude,omitempty" mapstructure:"exclude"`
+}
+
+type flowSelectorSpec struct {
+	Labels     map[string]string `json:"labels,omitempty" mapstructure:"labels"`
+	Hosts      []string          `json:"hosts,omitempty" mapstructure:"hosts"`
+	Namespaces []string          `json:"namespaces,omitempty" mapstructure:"namespaces"`
+}
+
+type flowFilterSpec struct {
+	Grep   *grepFilterSpec   `json:"grep,omitempty" mapstructure:"grep"`
+	Parser *parserFilterSpec `json:"parser,omitempty" mapstructure:"parser"`
+}
+
+type grepFilterSpec struct {
+	Include []grepPatternSpec `json:"include,omitempty" mapstructure:"include"`
+	Exclude []grepPatternSpec `json:"exclude,omitempty" mapstructure:"exclude"`
+}
+
+type grepPatternSpec struct {
+	Key     string `json:"key" mapstructure:"key"`
+	Pattern string `json:"pattern" mapstructure:"pattern"`
+}
+
+type parserFilterSpec struct {
+	KeyName            string `json:"keyName" mapstructure:"keyName"`
+	Type               string `json:"type" mapstructure:"type"`
+	Expression         string `json:"expression" mapstructure:"expression"`
+	TimeKey            string `json:"timeKey" mapstructure:"timeKey"`
+	TimeFormat         string `json:"timeFormat" mapstructure:"timeFormat"`
+	ReserveData        bool   `json:"reserveData" mapstructure:"reserveData"`
+	RemoveKeyNameField bool   `json:"removeKeyNameField" mapstructure:"removeKeyNameField"`
 }
 
 type bucketSpec struct {
@@ -83,9 +180,39 @@ func (s integratedServiceSpec) Validate() error {
 		return err
 	}
 
+	var outputs = s.getOutputNames()
+	var flowNames = make(map[string]bool)
+	for _, flow := range s.Flows {
+		if err := flow.Validate(outputs); err != nil {
+			return errors.WrapIfWithDetails(err, "error during validating flow", "flow", flow.Name)
+		}
+
+		var key = flow.Namespace + "/" + flow.Name
+		if flowNames[key] {
+			return errors.Errorf("flow %q can only be configured once", key)
+		}
+		flowNames[key] = true
+	}
+
 	return nil
 }
 
+// getOutputNames returns the names flows can use to reference the enabled outputs.
+func (s integratedServiceSpec) getOutputNames() map[string]bool {
+	var names = make(map[string]bool)
+	if s.ClusterOutput.Enabled {
+		for _, provider := range s.ClusterOutput.getProviders() {
+			names[provider.getOutputName()] = true
+		}
+	}
+
+	if s.Loki.Enabled {
+		names[providerLoki] = true
+	}
+
+	return names
+}
+
 func (s lokiSpec) Validate() error {
 	if s.Enabled {
 		if err := s.Ingress.Validate(); err != nil {
@@ -115,8 +242,17 @@ func (s ingressSpec) Validate() error {
 
 func (s clusterOutputSpec) Validate() error {
 	if s.Enabled {
-		if err := s.Provider.Validate(); err != nil {
-			return errors.WrapIf(err, "error during validating provider")
+		var outputNames = make(map[string]bool)
+		for _, provider := range s.getProviders() {
+			if err := provider.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validating provider")
+			}
+
+			var outputName = provider.getOutputName()
+			if outputNames[outputName] {
+				return errors.Errorf("output %q can only be configured once", outputName)
+			}
+			outputNames[outputName] = true
 		}
 	}
 
@@ -124,22 +260,90 @@ func (s clusterOutputSpec) Validate() error {
 }
 
 func (s providerSpec) Validate() error {
-	if s.SecretID == "" {
-		return requiredFieldError{name: "secretId"}
-	}
-
 	if s.Name == "" {
 		return requiredFieldError{name: "name"}
 	}
 
+	if s.OutputName != "" {
+		// the output name is part of the name of the ClusterOutput resource
+		if errs := validation.IsDNS1123Label(s.OutputName); len(errs) > 0 {
+			return errors.Errorf("invalid output name %q: %s", s.OutputName, strings.Join(errs, ", "))
+		}
+
+		if s.OutputName == providerLoki {
+			return errors.Errorf("output name %q is reserved", providerLoki)
+		}
+	}
+
 	switch s.Name {
 	case providerAmazonS3, providerAzure, providerAlibabaOSS, providerGoogleGCS:
+		if s.SecretID == "" {
+			return requiredFieldError{name: "secretId"}
+		}
+
+		if err := s.Bucket.Validate(s.Name); err != nil {
+			return errors.WrapIf(err, "error during bucket validation")
+		}
+	case providerElasticsearch:
+		if err := s.Elasticsearch.Validate(); err != nil {
+			return errors.WrapIf(err, "error during Elasticsearch validation")
+		}
+	case providerKafka:
+		if err := s.Kafka.Validate(); err != nil {
+			return errors.WrapIf(err, "error during Kafka validation")
+		}
+	case providerSyslog:
+		if err := s.Syslog.Validate(); err != nil {
+			return errors.WrapIf(err, "error during syslog validation")
+		}
 	default:
 		return errors.New("invalid provider name")
 	}
 
-	if err := s.Bucket.Validate(s.Name); err != nil {
-		return errors.WrapIf(err, "error during bucket validation")
+	return nil
+}
+
+func (s elasticsearchSpec) Validate() error {
+	if s.Host == "" {
+		return requiredFieldError{name: "host"}
+	}
+
+	switch s.Scheme {
+	case "", "http", "https":
+	default:
+		return errors.Errorf("invalid scheme %q", s.Scheme)
+	}
+
+	return nil
+}
+
+func (s kafkaSpec) Validate() error {
+	if len(s.Brokers) == 0 {
+		return requiredFieldError{name: "brokers"}
+	}
+
+	if s.Topic == "" {
+		return requiredFieldError{name: "topic"}
+	}
+
+	switch s.ScramMechanism {
+	case "", "sha256", "sha512":
+	default:
+		return errors.Errorf("invalid SCRAM mechanism %q", s.ScramMechanism)
+	}
+
+	return nil
+}
+
+func (s syslogSpec) Validate() error {
+	if s.Host == "" {
+		return requiredFieldError{name: "host"}
+	}
+
+	switch s.Transport {
+	case "", "udp", "tcp", "tls":
+	default:
+		return errors.Errorf("invalid transport %q", s.Transport)
 	}
 
 	return nil
@@ -162,3 +366,96 @@ func (s bucketSpec) Validate(provider string) error {
 
 	return nil
 }
+
+func (s flowSpec) Validate(outputs map[string]bool) error {
+	if s.Name == "" {
+		return requiredFieldError{name: "name"}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(s.Name); len(errs) > 0 {
+		return errors.Errorf("invalid flow name %q: %s", s.Name, strings.Join(errs, ", "))
+	}
+
+	if s.Namespace == "" && s.Name == flowResourceName {
+		return errors.Errorf("flow name %q is reserved", s.Name)
+	}
+
+	if len(s.Outputs) == 0 {
+		return requiredFieldError{name: "outputs"}
+	}
+
+	for _, output := range s.Outputs {
+		if !outputs[output] {
+			return errors.Errorf("output %q is not enabled", output)
+		}
+	}
+
+	for _, match := range s.Match {
+		if match.Select == nil && match.Exclude == nil {
+			return errors.New("match rule must have either select or exclude")
+		}
+
+		if match.Select != nil && match.Exclude != nil {
+			return errors.New("match rule cannot have both select and exclude")
+		}
+
+		for _, selector := range []*flowSelectorSpec{match.Select, match.Exclude} {
+			if selector != nil && s.Namespace != "" && len(selector.Namespaces) > 0 {
+				return errors.New("namespaces can only be matched by cluster-wide flows")
+			}
+		}
+	}
+
+	for _, filter := range s.Filters {
+		if err := filter.Validate(); err != nil {
+			return errors.WrapIf(err, "error during validating filter")
+		}
+	}
+
+	return nil
+}
+
+func (s flowFilterSpec) Validate() error {
+	switch {
+	case s.Grep != nil && s.Parser != nil:
+		return errors.New("filter must have exactly one of grep or parser")
+	case s.Grep != nil:
+		return s.Grep.Validate()
+	case s.Parser != nil:
+		return s.Parser.Validate()
+	default:
+		return errors.New("filter must have exactly one of grep or parser")
+	}
+}
+
+func (s grepFilterSpec) Validate() error {
+	if len(s.Include) == 0 && len(s.Exclude) == 0 {
+		return errors.New("grep filter must have at least one include or exclude pattern")
+	}
+
+	// patterns are Ruby regular expressions evaluated by fluentd, so they are not compiled here
+	for _, p := range append(append([]grepPatternSpec{}, s.Include...), s.Exclude...) {
+		if p.Key == "" {
+			return requiredFieldError{name: "key"}
+		}
+	}
+
+	return nil
+}
+
+func (s parserFilterSpec) Validate() error {
+	switch s.Type {
+	case "":
+		return requiredFieldError{name: "type"}
+	case "regexp", "multiline":
+		// the expression is a Ruby regular expression (eg. with (?<name>...) groups) evaluated by fluentd
+		if s.Expression == "" {
+			return requiredFieldError{name: "expression"}
+		}
+	case "apache2", "apache_error", "nginx", "syslog", "csv", "tsv", "ltsv", "json", "none":
+	default:
+		return errors.Errorf("invalid parser type %q", s.Type)
+	}
+
+	return nil
+}
@@ -39,6 +39,23 @@ const (
 
 	alertmanagerProviderSlack     = "slack"
 	alertmanagerProviderPagerDuty = "pagerDuty"
+	alertmanagerProviderEmail     = "email"
+	alertmanagerProviderOpsgenie  = "opsgenie"
+	alertmanagerProviderWebhook   = "webhook"
+
+	bearerTokenSecretKey = "token"
+	alertSeverityLabel   = "severity"
+
+	remoteWriteAuthBasic  = "basic"
+	remoteWriteAuthBearer = "bearer"
+
+	resourceLabelKey       = "banzaicloud.io/service"
+	prometheusSecretsMount = "/etc/prometheus/secrets"
+
+	grafanaDashboardLabel         = "grafana_dashboard"
+	grafanaDatasourceLabel        = "grafana_datasource"
+	grafanaDatasourceAccessProxy  = "proxy"
+	grafanaDatasourceAccessDirect = "direct"
 )
 
 func getClusterNameSecretTag(clusterName string) string {
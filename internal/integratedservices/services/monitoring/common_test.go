@@ -18,7 +18,11 @@ import (
 	"context"
 
 	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
 	"github.com/banzaicloud/pipeline/pkg/helm"
@@ -174,6 +178,37 @@ func (d dummyHelmService) GetDeployment(ctx context.Context, clusterID uint, rel
 }
 
 type dummyKubernetesService struct {
+	Objects []runtime.Object
+}
+
+func (s *dummyKubernetesService) EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	s.Objects = append(s.Objects, o)
+	return nil
+}
+
+func (s *dummyKubernetesService) Update(ctx context.Context, clusterID uint, o runtime.Object) error {
+	return nil
+}
+
+func (s *dummyKubernetesService) DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	return nil
+}
+
+func (s *dummyKubernetesService) GetObject(ctx context.Context, clusterID uint, objRef corev1.ObjectReference, obj runtime.Object) error {
+	for _, o := range s.Objects {
+		if u, ok := o.(*unstructured.Unstructured); ok && u.GetNamespace() == objRef.Namespace && u.GetName() == objRef.Name {
+			return nil
+		}
+
+		if cm, ok := o.(*corev1.ConfigMap); ok && cm.Namespace == objRef.Namespace && cm.Name == objRef.Name {
+			if target, ok := obj.(*corev1.ConfigMap); ok {
+				cm.DeepCopyInto(target)
+				return nil
+			}
+		}
+	}
+
+	return k8sapierrors.NewNotFound(schema.GroupResource{}, objRef.Name)
 }
 
 func (s *dummyKubernetesService) List(ctx context.Context, clusterID uint, labels map[string]string, o runtime.Object) error {
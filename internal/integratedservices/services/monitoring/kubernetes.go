@@ -17,10 +17,23 @@ package monitoring
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type KubernetesService interface {
+	// EnsureObject makes sure that a given Object is on the cluster and returns it.
+	EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error
+
+	// Update updates a given Object on the cluster and returns it.
+	Update(ctx context.Context, clusterID uint, o runtime.Object) error
+
+	// DeleteObject deletes an Object from a specific cluster.
+	DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error
+
+	// GetObject gets an Object from a specific cluster.
+	GetObject(ctx context.Context, clusterID uint, objRef corev1.ObjectReference, obj runtime.Object) error
+
 	// List lists Objects on specific cluster.
 	List(ctx context.Context, clusterID uint, labels map[string]string, o runtime.Object) error
 }
@@ -19,6 +19,9 @@ import (
 	"fmt"
 
 	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/banzaicloud/pipeline/internal/cluster/endpoints"
 	"github.com/banzaicloud/pipeline/internal/common"
@@ -32,12 +35,13 @@ import (
 type IntegratedServiceManager struct {
 	integratedservices.PassthroughIntegratedServiceSpecPreparer
 
-	clusterGetter    integratedserviceadapter.ClusterGetter
-	secretStore      services.SecretStore
-	endpointsService endpoints.EndpointService
-	helmService      services.HelmService
-	config           Config
-	logger           common.Logger
+	clusterGetter     integratedserviceadapter.ClusterGetter
+	secretStore       services.SecretStore
+	endpointsService  endpoints.EndpointService
+	helmService       services.HelmService
+	kubernetesService KubernetesService
+	config            Config
+	logger            common.Logger
 }
 
 func MakeIntegratedServiceManager(
@@ -45,16 +49,18 @@ func MakeIntegratedServiceManager(
 	secretStore services.SecretStore,
 	endpointsService endpoints.EndpointService,
 	helmService services.HelmService,
+	kubernetesService KubernetesService,
 	config Config,
 	logger common.Logger,
 ) IntegratedServiceManager {
 	return IntegratedServiceManager{
-		clusterGetter:    clusterGetter,
-		secretStore:      secretStore,
-		endpointsService: endpointsService,
-		helmService:      helmService,
-		config:           config,
-		logger:           logger,
+		clusterGetter:     clusterGetter,
+		secretStore:       secretStore,
+		endpointsService:  endpointsService,
+		helmService:       helmService,
+		kubernetesService: kubernetesService,
+		config:            config,
+		logger:            logger,
 	}
 }
 
@@ -91,9 +97,14 @@ func (m IntegratedServiceManager) GetOutput(ctx context.Context, clusterID uint,
 	var operatorValues = m.config.Charts.Operator.Values
 	var pushgatewayValues = m.config.Charts.Pushgateway.Values
 
+	prometheusOutput := m.getComponentOutput(ctx, clusterID, newPrometheusOutputHelper(kubeConfig, boundSpec), endpoints, m.config.Namespace, prometheusOperatorReleaseName, operatorValues, m.config.Images.Prometheus)
+	if boundSpec.Prometheus.Enabled {
+		m.writePrometheusResourcesOutput(ctx, clusterID, boundSpec.Prometheus, prometheusOutput)
+	}
+
 	out := integratedservices.IntegratedServiceOutput{
 		"grafana":      m.getComponentOutput(ctx, clusterID, newGrafanaOutputHelper(kubeConfig, boundSpec), endpoints, m.config.Namespace, prometheusOperatorReleaseName, operatorValues, m.config.Images.Grafana),
-		"prometheus":   m.getComponentOutput(ctx, clusterID, newPrometheusOutputHelper(kubeConfig, boundSpec), endpoints, m.config.Namespace, prometheusOperatorReleaseName, operatorValues, m.config.Images.Prometheus),
+		"prometheus":   prometheusOutput,
 		"alertmanager": m.getComponentOutput(ctx, clusterID, newAlertmanagerOutputHelper(kubeConfig, boundSpec), endpoints, m.config.Namespace, prometheusOperatorReleaseName, operatorValues, m.config.Images.Alertmanager),
 		"pushgateway":  m.getComponentOutput(ctx, clusterID, newPushgatewayOutputHelper(kubeConfig, boundSpec), endpoints, m.config.Namespace, prometheusPushgatewayReleaseName, pushgatewayValues, m.config.Images.Pushgateway),
 		"prometheusOperator": map[string]interface{}{
@@ -153,3 +164,68 @@ func (m IntegratedServiceManager) getComponentOutput(
 
 	return out
 }
+
+// writePrometheusResourcesOutput writes the remote write endpoints and the state of the
+// ServiceMonitor and PrometheusRule resources of the spec to the Prometheus output.
+func (m IntegratedServiceManager) writePrometheusResourcesOutput(
+	ctx context.Context,
+	clusterID uint,
+	spec prometheusSpec,
+	output map[string]interface{},
+) {
+	if len(spec.RemoteWrite) > 0 {
+		var remoteWrite []map[string]interface{}
+		for _, rw := range spec.RemoteWrite {
+			remoteWrite = append(remoteWrite, map[string]interface{}{
+				"name": rw.Name,
+				urlKey: rw.URL,
+			})
+		}
+		output[remoteWriteKey] = remoteWrite
+	}
+
+	if len(spec.ServiceMonitors) > 0 {
+		var serviceMonitors []map[string]interface{}
+		for _, sm := range spec.ServiceMonitors {
+			resource := generateServiceMonitor(sm, m.config.Namespace)
+			serviceMonitors = append(serviceMonitors, map[string]interface{}{
+				"name":      resource.GetName(),
+				"namespace": resource.GetNamespace(),
+				statusKey:   m.getResourceStatus(ctx, clusterID, resource),
+			})
+		}
+		output[serviceMonitorsKey] = serviceMonitors
+	}
+
+	if len(spec.RuleGroups) > 0 {
+		var ruleGroups []map[string]interface{}
+		for _, rg := range spec.RuleGroups {
+			resource := generatePrometheusRule(rg, m.config.Namespace)
+			ruleGroups = append(ruleGroups, map[string]interface{}{
+				"name":    rg.Name,
+				"rules":   len(rg.Rules),
+				statusKey: m.getResourceStatus(ctx, clusterID, resource),
+			})
+		}
+		output[ruleGroupsKey] = ruleGroups
+	}
+}
+
+func (m IntegratedServiceManager) getResourceStatus(ctx context.Context, clusterID uint, resource *unstructured.Unstructured) string {
+	var actual unstructured.Unstructured
+	actual.SetGroupVersionKind(resource.GroupVersionKind())
+	err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}, &actual)
+
+	switch {
+	case err == nil:
+		return resourceStatusCreated
+	case k8sapierrors.IsNotFound(err):
+		return resourceStatusMissing
+	default:
+		m.logger.Warn(fmt.Sprintf("failed to get %s %s/%s: %s", resource.GetKind(), resource.GetNamespace(), resource.GetName(), err.Error()))
+		return resourceStatusUnknown
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"emperror.dev/errors"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/banzaicloud/pipeline/internal/common/commonadapter"
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
@@ -30,7 +31,7 @@ import (
 )
 
 func TestIntegratedServiceManager_Name(t *testing.T) {
-	mng := MakeIntegratedServiceManager(nil, nil, nil, nil, Config{}, nil)
+	mng := MakeIntegratedServiceManager(nil, nil, nil, nil, nil, Config{}, nil)
 
 	assert.Equal(t, "monitoring", mng.Name())
 }
@@ -109,7 +110,10 @@ func TestIntegratedServiceManager_GetOutput(t *testing.T) {
 	helmService := dummyHelmService{}
 	endpointService := dummyEndpointService{}
 	logger := services.NoopLogger{}
-	mng := MakeIntegratedServiceManager(clusterGetter, secretStore, endpointService, helmService, config, logger)
+	kubernetesService := &dummyKubernetesService{
+		Objects: []runtime.Object{newResource(serviceMonitorGVK, "apps", "api", nil)},
+	}
+	mng := MakeIntegratedServiceManager(clusterGetter, secretStore, endpointService, helmService, kubernetesService, config, logger)
 	ctx := auth.SetCurrentOrganizationID(context.Background(), orgID)
 
 	spec := obj{
@@ -134,6 +138,15 @@ func TestIntegratedServiceManager_GetOutput(t *testing.T) {
 				"path":    "/prometheus",
 			},
 			"secretId": prometheusSecretID,
+			"remoteWrite": []interface{}{
+				obj{"name": "central", "url": "https://metrics.example.org/api/v1/write"},
+			},
+			"serviceMonitors": []interface{}{
+				obj{"name": "api", "namespace": "apps", "selector": obj{"app": "api"}, "endpoints": []interface{}{obj{"port": "http"}}},
+			},
+			"ruleGroups": []interface{}{
+				obj{"name": "api-alerts", "rules": []interface{}{obj{"alert": "APIDown", "expr": "up{job=\"api\"} == 0"}}},
+			},
 		},
 	}
 
@@ -150,6 +163,15 @@ func TestIntegratedServiceManager_GetOutput(t *testing.T) {
 			"serviceUrl": serviceUrl,
 			"url":        prometheusURL,
 			"version":    "v0.1.2",
+			"remoteWrite": []obj{
+				{"name": "central", "url": "https://metrics.example.org/api/v1/write"},
+			},
+			"serviceMonitors": []obj{
+				{"name": "api", "namespace": "apps", "status": "created"},
+			},
+			"ruleGroups": []obj{
+				{"name": "api-alerts", "rules": 1, "status": "missing"},
+			},
 		},
 		"prometheusOperator": obj{
 			"version": config.Charts.Operator.Version,
@@ -165,7 +187,7 @@ func TestIntegratedServiceManager_GetOutput(t *testing.T) {
 }
 
 func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
-	mng := MakeIntegratedServiceManager(nil, nil, nil, nil, Config{}, nil)
+	mng := MakeIntegratedServiceManager(nil, nil, nil, nil, nil, Config{}, nil)
 
 	cases := map[string]struct {
 		Spec  integratedservices.IntegratedServiceSpec
@@ -272,6 +294,463 @@ func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
 			},
 			Error: true,
 		},
+		"Prometheus remote write, scrape configs and rules": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"remoteWrite": []interface{}{
+						obj{
+							"name": "central",
+							"url":  "https://metrics.example.org/api/v1/write",
+							"auth": obj{"type": "basic", "secretId": "remotewritesecret"},
+						},
+					},
+					"scrapeConfigs": []interface{}{
+						obj{"jobName": "external", "targets": []interface{}{"10.0.0.1:9100"}, "interval": "30s"},
+					},
+					"serviceMonitors": []interface{}{
+						obj{
+							"name":      "api",
+							"namespace": "apps",
+							"selector":  obj{"app": "api"},
+							"endpoints": []interface{}{obj{"port": "http", "path": "/metrics"}},
+						},
+					},
+					"ruleGroups": []interface{}{
+						obj{
+							"name": "api",
+							"rules": []interface{}{
+								obj{"record": "job:http_requests:rate5m", "expr": "sum by (job) (rate(http_requests_total[5m]))"},
+								obj{"alert": "APIDown", "expr": "up{job=\"api\"} == 0", "for": "5m", "labels": obj{"severity": "critical"}},
+							},
+						},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: false,
+		},
+		"remote write auth secret required": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"remoteWrite": []interface{}{
+						obj{"url": "https://metrics.example.org/api/v1/write", "auth": obj{"type": "bearer"}},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid remote write url": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"remoteWrite": []interface{}{
+						obj{"url": "metrics.example.org"},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"service monitor selector required": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"serviceMonitors": []interface{}{
+						obj{"name": "api", "endpoints": []interface{}{obj{"port": "http"}}},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid rule expression": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"ruleGroups": []interface{}{
+						obj{
+							"name":  "api",
+							"rules": []interface{}{obj{"alert": "APIDown", "expr": "sum(up{job=\"api\"} == 0"}},
+						},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"recording rule with for": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+					"ruleGroups": []interface{}{
+						obj{
+							"name":  "api",
+							"rules": []interface{}{obj{"record": "job:up:sum", "expr": "sum(up) by (job)", "for": "5m"}},
+						},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"Alertmanager email, Opsgenie and webhook receivers": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"alertmanager": obj{
+					"enabled": true,
+					"provider": obj{
+						"email": obj{"enabled": true, "secretId": "smtpsecret", "to": "oncall@example.org"},
+						"opsgenie": obj{
+							"enabled":  true,
+							"secretId": "opsgeniesecret",
+							"priority": "P2",
+							"route":    obj{"severities": []interface{}{"critical"}},
+						},
+						"webhook": obj{
+							"enabled": true,
+							"url":     "https://hooks.example.org/alerts",
+							"route":   obj{"matchers": obj{"team": "platform"}},
+						},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: false,
+		},
+		"email recipient required": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"alertmanager": obj{
+					"enabled": true,
+					"provider": obj{
+						"email": obj{"enabled": true, "secretId": "smtpsecret"},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid Opsgenie priority": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"alertmanager": obj{
+					"enabled": true,
+					"provider": obj{
+						"opsgenie": obj{"enabled": true, "secretId": "opsgeniesecret", "priority": "P9"},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid webhook route matcher": {
+			Spec: obj{
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"alertmanager": obj{
+					"enabled": true,
+					"provider": obj{
+						"webhook": obj{
+							"enabled": true,
+							"url":     "https://hooks.example.org/alerts",
+							"route":   obj{"matchers": obj{"team-name": "platform"}},
+						},
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"Grafana dashboards and datasources": {
+			Spec: obj{
+				"grafana": obj{
+					"enabled": true,
+					"ingress": obj{
+						"enabled": true,
+						"path":    grafanaPath,
+					},
+					"dashboards": []interface{}{
+						obj{"name": "inline", "json": `{"title":"Inline"}`},
+						obj{"name": "api", "configMap": obj{"namespace": "apps", "name": "dashboards", "key": "api.json"}},
+					},
+					"datasources": []interface{}{
+						obj{"name": "Loki", "type": "loki", "url": "http://loki.pipeline-system:3100"},
+					},
+				},
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: false,
+		},
+		"invalid Grafana dashboard JSON": {
+			Spec: obj{
+				"grafana": obj{
+					"enabled": true,
+					"ingress": obj{
+						"enabled": true,
+						"path":    grafanaPath,
+					},
+					"dashboards": []interface{}{
+						obj{"name": "inline", "json": `{"title":`},
+					},
+				},
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"Grafana dashboard without source": {
+			Spec: obj{
+				"grafana": obj{
+					"enabled": true,
+					"ingress": obj{
+						"enabled": true,
+						"path":    grafanaPath,
+					},
+					"dashboards": []interface{}{
+						obj{"name": "api"},
+					},
+				},
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"duplicated Grafana datasource": {
+			Spec: obj{
+				"grafana": obj{
+					"enabled": true,
+					"ingress": obj{
+						"enabled": true,
+						"path":    grafanaPath,
+					},
+					"datasources": []interface{}{
+						obj{"name": "Loki", "type": "loki", "url": "http://loki.pipeline-system:3100"},
+						obj{"name": "Loki", "type": "loki", "url": "http://loki.logging:3100"},
+					},
+				},
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
+		"invalid Grafana datasource access": {
+			Spec: obj{
+				"grafana": obj{
+					"enabled": true,
+					"ingress": obj{
+						"enabled": true,
+						"path":    grafanaPath,
+					},
+					"datasources": []interface{}{
+						obj{"name": "Loki", "type": "loki", "url": "http://loki.pipeline-system:3100", "access": "server"},
+					},
+				},
+				"prometheus": obj{
+					"enabled": true,
+					"storage": obj{
+						"size":      100,
+						"retention": "10m",
+					},
+				},
+				"exporters": obj{
+					"enabled": true,
+					"nodeExporter": obj{
+						"enabled": true,
+					},
+					"kubeStateMetrics": obj{
+						"enabled": true,
+					},
+				},
+			},
+			Error: true,
+		},
 		"disabled exporters": {
 			Spec: obj{
 				"grafana": obj{
@@ -18,6 +18,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"emperror.dev/errors"
 	"github.com/mitchellh/copystructure"
@@ -149,11 +152,21 @@ func (op IntegratedServiceOperator) Apply(ctx context.Context, clusterID uint, s
 		}
 	}
 
+	// Grafana dashboards and datasources
+	if err := op.ensureGrafanaResources(ctx, clusterID, boundSpec.Grafana); err != nil {
+		return errors.WrapIf(err, "failed to ensure Grafana resources")
+	}
+
 	// install Prometheus Operator
 	if err := op.installPrometheusOperator(ctx, cluster, logger, boundSpec, grafanaSecretID, prometheusSecretName, alertmanagerSecretName); err != nil {
 		return errors.WrapIf(err, "failed to install Prometheus operator")
 	}
 
+	// ServiceMonitors and PrometheusRules
+	if err := op.ensurePrometheusResources(ctx, clusterID, boundSpec.Prometheus); err != nil {
+		return errors.WrapIf(err, "failed to ensure Prometheus resources")
+	}
+
 	// Pushgateway
 	if boundSpec.Pushgateway.Enabled {
 		// install Prometheus Pushgateway
@@ -198,6 +211,16 @@ func (op IntegratedServiceOperator) Deactivate(ctx context.Context, clusterID ui
 		}
 	}
 
+	// delete ServiceMonitors and PrometheusRules managed by the integrated service
+	if err := op.ensurePrometheusResources(ctx, clusterID, prometheusSpec{}); err != nil {
+		return errors.WrapIf(err, "failed to delete Prometheus resources")
+	}
+
+	// delete Grafana dashboards and datasources managed by the integrated service
+	if err := op.ensureGrafanaResources(ctx, clusterID, grafanaSpec{}); err != nil {
+		return errors.WrapIf(err, "failed to delete Grafana resources")
+	}
+
 	// delete prometheus operator deployment
 	if err := op.helmService.DeleteDeployment(ctx, clusterID, prometheusOperatorReleaseName, op.config.Namespace); err != nil {
 		return errors.WrapIfWithDetails(err, "failed to delete deployment", "release", prometheusOperatorReleaseName)
@@ -292,6 +315,26 @@ func (op IntegratedServiceOperator) installPrometheusOperator(
 		Prometheus:   valuesManager.generatePrometheusChartValues(ctx, spec.Prometheus, prometheusSecretName, op.config.Images.Prometheus),
 	}
 
+	if spec.Prometheus.Enabled {
+		remoteWrite, secrets, err := op.generateRemoteWriteValues(ctx, cluster, spec.Prometheus.RemoteWrite)
+		if err != nil {
+			return errors.WrapIf(err, "failed to generate Prometheus remote write values")
+		}
+
+		chartValues.Prometheus.Spec.RemoteWrite = remoteWrite
+		chartValues.Prometheus.Spec.Secrets = secrets
+		chartValues.Prometheus.Spec.AdditionalScrapeConfigs = generateScrapeConfigValues(spec.Prometheus.ScrapeConfigs)
+	}
+
+	if spec.Grafana.Enabled && len(spec.Grafana.Datasources) > 0 {
+		checksum, err := grafanaDatasourcesChecksum(spec.Grafana.Datasources)
+		if err != nil {
+			return errors.WrapIf(err, "failed to generate Grafana datasources checksum")
+		}
+
+		chartValues.Grafana.PodAnnotations = map[string]string{grafanaDatasourcesChecksumAnnotation: checksum}
+	}
+
 	if spec.Exporters.Enabled {
 		chartValues.KubeStateMetrics = valuesManager.generateKubeStateMetricsChartValues(spec.Exporters.KubeStateMetrics)
 		if spec.Exporters.KubeStateMetrics.Enabled {
@@ -452,66 +495,140 @@ func (op IntegratedServiceOperator) ensureOrgIDInContext(ctx context.Context, cl
 }
 
 func (op IntegratedServiceOperator) generateAlertManagerProvidersConfig(ctx context.Context, spec map[string]interface{}) (*configValues, error) {
-	var err error
-	var hasProvider bool
+	var defaultReceiver = receiverItemValues{Name: alertManagerProviderConfigName}
+	var receivers []receiverItemValues
+	var routes = []interface{}{}
+
+	// providers with a route get their own receiver, the others are merged into the default one
+	addReceiver := func(provider string, route routeSpec, receiver receiverItemValues) {
+		if route.isEmpty() {
+			defaultReceiver.merge(receiver)
+			return
+		}
+
+		receiver.Name = provider
+		receivers = append(receivers, receiver)
+		routes = append(routes, generateChildRoute(provider, route))
+	}
 
 	// generate Slack configs
-	var slackConfigs []slackConfigValues
 	if slackProv, ok := spec[alertmanagerProviderSlack]; ok {
 		var slack slackSpec
 		if err := mapstructure.Decode(slackProv, &slack); err != nil {
 			return nil, errors.WrapIf(err, "failed to bind Slack config")
 		}
 		if slack.Enabled {
-			hasProvider = true
-			slackConfigs, err = op.generateSlackConfig(ctx, slack)
+			slackConfigs, err := op.generateSlackConfig(ctx, slack)
 			if err != nil {
 				return nil, errors.WrapIf(err, "failed to generate Slack config")
 			}
+			addReceiver(alertmanagerProviderSlack, slack.Route, receiverItemValues{SlackConfigs: slackConfigs})
 		}
 	}
 
 	// generate PagerDuty configs
-	var pageDutyConfigs []pagerdutyConfigValues
 	if pdProv, ok := spec[alertmanagerProviderPagerDuty]; ok {
 		var pd pagerDutySpec
 		if err := mapstructure.Decode(pdProv, &pd); err != nil {
 			return nil, errors.WrapIf(err, "failed to bind PagerDuty config")
 		}
 		if pd.Enabled {
-			hasProvider = true
-			pageDutyConfigs, err = op.generatePagerdutyConfig(ctx, pd)
+			pageDutyConfigs, err := op.generatePagerdutyConfig(ctx, pd)
 			if err != nil {
 				return nil, errors.WrapIf(err, "failed to generate PagerDuty config")
 			}
+			addReceiver(alertmanagerProviderPagerDuty, pd.Route, receiverItemValues{PagerdutyConfigs: pageDutyConfigs})
 		}
 	}
 
-	var receiverName = alertManagerNullReceiverName
-	if hasProvider {
-		receiverName = alertManagerProviderConfigName
+	// generate email configs
+	if emailProv, ok := spec[alertmanagerProviderEmail]; ok {
+		var email emailSpec
+		if err := mapstructure.Decode(emailProv, &email); err != nil {
+			return nil, errors.WrapIf(err, "failed to bind email config")
+		}
+		if email.Enabled {
+			emailConfigs, err := op.generateEmailConfig(ctx, email)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to generate email config")
+			}
+			addReceiver(alertmanagerProviderEmail, email.Route, receiverItemValues{EmailConfigs: emailConfigs})
+		}
 	}
-	var result = &configValues{
-		Receivers: []receiverItemValues{
-			{
-				Name: receiverName,
-			},
-		},
+
+	// generate Opsgenie configs
+	if opsgenieProv, ok := spec[alertmanagerProviderOpsgenie]; ok {
+		var opsgenie opsgenieSpec
+		if err := mapstructure.Decode(opsgenieProv, &opsgenie); err != nil {
+			return nil, errors.WrapIf(err, "failed to bind Opsgenie config")
+		}
+		if opsgenie.Enabled {
+			opsgenieConfigs, err := op.generateOpsgenieConfig(ctx, opsgenie)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to generate Opsgenie config")
+			}
+			addReceiver(alertmanagerProviderOpsgenie, opsgenie.Route, receiverItemValues{OpsgenieConfigs: opsgenieConfigs})
+		}
+	}
+
+	// generate webhook configs
+	if webhookProv, ok := spec[alertmanagerProviderWebhook]; ok {
+		var webhook webhookSpec
+		if err := mapstructure.Decode(webhookProv, &webhook); err != nil {
+			return nil, errors.WrapIf(err, "failed to bind webhook config")
+		}
+		if webhook.Enabled {
+			webhookConfigs, err := op.generateWebhookConfig(ctx, webhook)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to generate webhook config")
+			}
+			addReceiver(alertmanagerProviderWebhook, webhook.Route, receiverItemValues{WebhookConfigs: webhookConfigs})
+		}
+	}
+
+	if defaultReceiver.isEmpty() {
+		defaultReceiver.Name = alertManagerNullReceiverName
+	}
+
+	return &configValues{
+		Receivers: append([]receiverItemValues{defaultReceiver}, receivers...),
 		Route: routeValues{
-			Receiver: receiverName,
-			Routes:   []interface{}{},
+			Receiver: defaultReceiver.Name,
+			Routes:   routes,
 		},
+	}, nil
+}
+
+func generateChildRoute(receiver string, spec routeSpec) childRouteValues {
+	var route = childRouteValues{
+		Receiver: receiver,
 	}
 
-	if slackConfigs != nil {
-		result.Receivers[0].SlackConfigs = slackConfigs
+	if len(spec.Matchers) > 0 {
+		route.Match = make(map[string]string, len(spec.Matchers))
+		for label, value := range spec.Matchers {
+			route.Match[label] = value
+		}
 	}
 
-	if pageDutyConfigs != nil {
-		result.Receivers[0].PagerdutyConfigs = pageDutyConfigs
+	switch len(spec.Severities) {
+	case 0:
+	case 1:
+		if route.Match == nil {
+			route.Match = make(map[string]string, 1)
+		}
+		route.Match[alertSeverityLabel] = spec.Severities[0]
+	default:
+		var severities = make([]string, 0, len(spec.Severities))
+		for _, severity := range spec.Severities {
+			severities = append(severities, regexp.QuoteMeta(severity))
+		}
+		route.MatchRE = map[string]string{
+			alertSeverityLabel: strings.Join(severities, "|"),
+		}
 	}
 
-	return result, nil
+	return route
 }
 
 func (op IntegratedServiceOperator) generateSlackConfig(ctx context.Context, config slackSpec) ([]slackConfigValues, error) {
@@ -558,6 +675,83 @@ func (op IntegratedServiceOperator) generatePagerdutyConfig(ctx context.Context,
 	return nil, nil
 }
 
+func (op IntegratedServiceOperator) generateEmailConfig(ctx context.Context, config emailSpec) ([]emailConfigValues, error) {
+	if config.Enabled {
+		smtpSecret, err := op.secretStore.GetSecretValues(ctx, config.SecretID)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to get SMTP secret")
+		}
+
+		var emailConfig = emailConfigValues{
+			To:           config.To,
+			From:         smtpSecret[secrettype.SMTPFrom],
+			Smarthost:    smtpSecret[secrettype.SMTPSmarthost],
+			AuthUsername: smtpSecret[secrettype.SMTPUsername],
+			AuthPassword: smtpSecret[secrettype.SMTPPassword],
+			SendResolved: config.SendResolved,
+		}
+
+		if value := smtpSecret[secrettype.SMTPRequireTLS]; value != "" {
+			requireTLS, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.WrapIf(err, "invalid requireTls value in SMTP secret")
+			}
+			emailConfig.RequireTLS = &requireTLS
+		}
+
+		return []emailConfigValues{emailConfig}, nil
+	}
+
+	return nil, nil
+}
+
+func (op IntegratedServiceOperator) generateOpsgenieConfig(ctx context.Context, config opsgenieSpec) ([]opsgenieConfigValues, error) {
+	if config.Enabled {
+		opsgenieSecret, err := op.secretStore.GetSecretValues(ctx, config.SecretID)
+		if err != nil {
+			return nil, errors.WrapIf(err, "failed to get Opsgenie secret")
+		}
+
+		return []opsgenieConfigValues{
+			{
+				ApiKey:       opsgenieSecret[secrettype.OpsgenieAPIKey],
+				ApiUrl:       config.URL,
+				Priority:     config.Priority,
+				SendResolved: config.SendResolved,
+			},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (op IntegratedServiceOperator) generateWebhookConfig(ctx context.Context, config webhookSpec) ([]webhookConfigValues, error) {
+	if config.Enabled {
+		var webhookConfig = webhookConfigValues{
+			Url:          config.URL,
+			SendResolved: config.SendResolved,
+		}
+
+		if config.SecretID != "" {
+			webhookSecret, err := op.secretStore.GetSecretValues(ctx, config.SecretID)
+			if err != nil {
+				return nil, errors.WrapIf(err, "failed to get webhook secret")
+			}
+
+			token := webhookSecret[bearerTokenSecretKey]
+			if token == "" {
+				return nil, errors.NewWithDetails("webhook secret must contain a token", "secretID", config.SecretID)
+			}
+
+			webhookConfig.HTTPConfig = &httpConfigValues{BearerToken: token}
+		}
+
+		return []webhookConfigValues{webhookConfig}, nil
+	}
+
+	return nil, nil
+}
+
 func isSecretNotFoundError(err error) bool {
 	errCause := errors.Cause(err)
 	if errCause == secret.ErrSecretNotExists {
@@ -599,9 +793,13 @@ func (m chartValuesManager) generateGrafanaChartValues(
 			Sidecar: sidecar{
 				Datasources: datasources{
 					Enabled:         true,
-					Label:           "grafana_datasource",
+					Label:           grafanaDatasourceLabel,
 					SearchNamespace: "ALL",
 				},
+				Dashboards: dashboards{
+					Enabled: true,
+					Label:   grafanaDashboardLabel,
+				},
 			},
 		}
 	}
@@ -713,6 +911,7 @@ func (m chartValuesManager) generatePrometheusChartValues(
 					},
 				},
 				ServiceMonitorSelectorNilUsesHelmValues: false,
+				RuleSelectorNilUsesHelmValues:           false,
 			},
 		}
 	}
@@ -0,0 +1,228 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	grafanaDatasourcesConfigMapName      = "monitoring-grafana-datasources"
+	grafanaDatasourcesFileName           = "datasources.yaml"
+	grafanaDatasourcesChecksumAnnotation = "checksum/datasources"
+)
+
+type grafanaDatasourcesFile struct {
+	APIVersion  int                       `json:"apiVersion"`
+	Datasources []grafanaDatasourceValues `json:"datasources"`
+}
+
+type grafanaDatasourceValues struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	URL      string                 `json:"url"`
+	Access   string                 `json:"access"`
+	Editable bool                   `json:"editable"`
+	JSONData map[string]interface{} `json:"jsonData,omitempty"`
+}
+
+// ensureGrafanaResources creates or updates the ConfigMaps of the custom Grafana dashboards and datasources
+// picked up by the Grafana sidecars and removes the ones that are no longer part of the spec.
+func (op IntegratedServiceOperator) ensureGrafanaResources(ctx context.Context, clusterID uint, spec grafanaSpec) error {
+	var configMaps []*corev1.ConfigMap
+
+	if spec.Enabled {
+		for _, dashboard := range spec.CustomDashboards {
+			content, err := op.getGrafanaDashboardContent(ctx, clusterID, dashboard)
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to get Grafana dashboard", "name", dashboard.Name)
+			}
+
+			configMaps = append(configMaps, generateGrafanaDashboardConfigMap(dashboard.Name, content, op.config.Namespace))
+		}
+
+		if len(spec.Datasources) > 0 {
+			configMap, err := generateGrafanaDatasourcesConfigMap(spec.Datasources, op.config.Namespace)
+			if err != nil {
+				return errors.WrapIf(err, "failed to generate Grafana datasources")
+			}
+
+			configMaps = append(configMaps, configMap)
+		}
+	}
+
+	if len(configMaps) > 0 {
+		// the ConfigMaps are created before the chart is installed for the datasources to be loaded on startup
+		if err := op.kubernetesService.EnsureObject(ctx, clusterID, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: op.config.Namespace},
+		}); err != nil {
+			return errors.WrapIf(err, "failed to ensure namespace")
+		}
+	}
+
+	var desired = make(map[string]bool, len(configMaps))
+	for _, configMap := range configMaps {
+		if err := op.ensureConfigMap(ctx, clusterID, configMap); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to ensure Grafana ConfigMap", "name", configMap.Name)
+		}
+		desired[configMap.Name] = true
+	}
+
+	// remove old ConfigMaps with integrated service labels
+	var list corev1.ConfigMapList
+	if err := op.kubernetesService.List(ctx, clusterID, map[string]string{resourceLabelKey: integratedServiceName}, &list); err != nil {
+		return errors.WrapIf(err, "failed to list Grafana ConfigMaps")
+	}
+
+	for i := range list.Items {
+		var item = &list.Items[i]
+		if item.Namespace != op.config.Namespace || desired[item.Name] {
+			continue
+		}
+
+		if err := op.kubernetesService.DeleteObject(ctx, clusterID, item); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to delete Grafana ConfigMap", "name", item.Name)
+		}
+	}
+
+	return nil
+}
+
+func (op IntegratedServiceOperator) getGrafanaDashboardContent(ctx context.Context, clusterID uint, spec grafanaDashboardSpec) (string, error) {
+	if spec.ConfigMap == nil {
+		return spec.JSON, nil
+	}
+
+	var namespace = spec.ConfigMap.Namespace
+	if namespace == "" {
+		namespace = op.config.Namespace
+	}
+
+	var configMap corev1.ConfigMap
+	if err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+		Namespace: namespace,
+		Name:      spec.ConfigMap.Name,
+	}, &configMap); err != nil {
+		return "", errors.WrapIfWithDetails(err, "failed to get dashboard ConfigMap", "namespace", namespace, "name", spec.ConfigMap.Name)
+	}
+
+	content, ok := configMap.Data[spec.ConfigMap.Key]
+	if !ok {
+		return "", errors.NewWithDetails("dashboard ConfigMap has no such key", "namespace", namespace, "name", spec.ConfigMap.Name, "key", spec.ConfigMap.Key)
+	}
+
+	return content, nil
+}
+
+func (op IntegratedServiceOperator) ensureConfigMap(ctx context.Context, clusterID uint, configMap *corev1.ConfigMap) error {
+	var oldConfigMap corev1.ConfigMap
+	if err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+		Namespace: configMap.Namespace,
+		Name:      configMap.Name,
+	}, &oldConfigMap); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return op.kubernetesService.EnsureObject(ctx, clusterID, configMap)
+		}
+
+		return errors.WrapIf(err, "failed to get ConfigMap")
+	}
+
+	configMap.ResourceVersion = oldConfigMap.ResourceVersion
+	return op.kubernetesService.Update(ctx, clusterID, configMap)
+}
+
+func generateGrafanaDashboardConfigMap(name string, content string, namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      fmt.Sprintf("monitoring-grafana-dashboard-%s", name),
+			Labels: map[string]string{
+				resourceLabelKey:      integratedServiceName,
+				grafanaDashboardLabel: "1",
+			},
+		},
+		Data: map[string]string{
+			fmt.Sprintf("%s.json", name): content,
+		},
+	}
+}
+
+func generateGrafanaDatasourcesConfigMap(specs []grafanaDatasourceSpec, namespace string) (*corev1.ConfigMap, error) {
+	content, err := renderGrafanaDatasources(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      grafanaDatasourcesConfigMapName,
+			Labels: map[string]string{
+				resourceLabelKey:       integratedServiceName,
+				grafanaDatasourceLabel: "1",
+			},
+		},
+		Data: map[string]string{
+			grafanaDatasourcesFileName: string(content),
+		},
+	}, nil
+}
+
+func renderGrafanaDatasources(specs []grafanaDatasourceSpec) ([]byte, error) {
+	var file = grafanaDatasourcesFile{
+		APIVersion: 1,
+	}
+
+	for _, spec := range specs {
+		var access = spec.Access
+		if access == "" {
+			access = grafanaDatasourceAccessProxy
+		}
+
+		file.Datasources = append(file.Datasources, grafanaDatasourceValues{
+			Name:     spec.Name,
+			Type:     spec.Type,
+			URL:      spec.URL,
+			Access:   access,
+			JSONData: spec.JSONData,
+		})
+	}
+
+	content, err := yaml.Marshal(file)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to marshal Grafana datasources")
+	}
+
+	return content, nil
+}
+
+// grafanaDatasourcesChecksum returns the checksum of the rendered datasources,
+// Grafana loads datasources only on startup so it has to be restarted when they change.
+func grafanaDatasourcesChecksum(specs []grafanaDatasourceSpec) (string, error) {
+	content, err := renderGrafanaDatasources(specs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
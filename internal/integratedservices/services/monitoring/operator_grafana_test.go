@@ -0,0 +1,112 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+)
+
+func TestIntegratedServiceOperator_ensureGrafanaResources(t *testing.T) {
+	kubernetesService := dummyKubernetesService{
+		Objects: []runtime.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "dashboards"},
+				Data:       map[string]string{"api.json": `{"title":"API"}`},
+			},
+		},
+	}
+	op := MakeIntegratedServiceOperator(nil, nil, nil, &kubernetesService, Config{Namespace: "pipeline-system"}, services.NoopLogger{}, nil)
+
+	err := op.ensureGrafanaResources(context.Background(), 42, grafanaSpec{
+		Enabled: true,
+		CustomDashboards: []grafanaDashboardSpec{
+			{Name: "inline", JSON: `{"title":"Inline"}`},
+			{Name: "api", ConfigMap: &configMapKeySpec{Namespace: "apps", Name: "dashboards", Key: "api.json"}},
+		},
+		Datasources: []grafanaDatasourceSpec{
+			{Name: "Loki", Type: "loki", URL: "http://loki.pipeline-system:3100"},
+		},
+	})
+	require.NoError(t, err)
+
+	var configMaps = make(map[string]*corev1.ConfigMap)
+	for _, o := range kubernetesService.Objects[1:] {
+		if cm, ok := o.(*corev1.ConfigMap); ok {
+			configMaps[cm.Name] = cm
+		}
+	}
+
+	require.Contains(t, configMaps, "monitoring-grafana-dashboard-inline")
+	assert.Equal(t, map[string]string{"inline.json": `{"title":"Inline"}`}, configMaps["monitoring-grafana-dashboard-inline"].Data)
+	assert.Equal(t, "1", configMaps["monitoring-grafana-dashboard-inline"].Labels[grafanaDashboardLabel])
+
+	require.Contains(t, configMaps, "monitoring-grafana-dashboard-api")
+	assert.Equal(t, map[string]string{"api.json": `{"title":"API"}`}, configMaps["monitoring-grafana-dashboard-api"].Data)
+
+	require.Contains(t, configMaps, grafanaDatasourcesConfigMapName)
+	assert.Equal(t, "1", configMaps[grafanaDatasourcesConfigMapName].Labels[grafanaDatasourceLabel])
+}
+
+func TestIntegratedServiceOperator_ensureGrafanaResources_MissingKey(t *testing.T) {
+	kubernetesService := dummyKubernetesService{
+		Objects: []runtime.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "pipeline-system", Name: "dashboards"},
+			},
+		},
+	}
+	op := MakeIntegratedServiceOperator(nil, nil, nil, &kubernetesService, Config{Namespace: "pipeline-system"}, services.NoopLogger{}, nil)
+
+	err := op.ensureGrafanaResources(context.Background(), 42, grafanaSpec{
+		Enabled: true,
+		CustomDashboards: []grafanaDashboardSpec{
+			{Name: "api", ConfigMap: &configMapKeySpec{Name: "dashboards", Key: "api.json"}},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestRenderGrafanaDatasources(t *testing.T) {
+	content, err := renderGrafanaDatasources([]grafanaDatasourceSpec{
+		{Name: "Loki", Type: "loki", URL: "http://loki.pipeline-system:3100", JSONData: map[string]interface{}{"maxLines": 1000}},
+		{Name: "External", Type: "prometheus", URL: "https://prometheus.example.org", Access: grafanaDatasourceAccessDirect},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `apiVersion: 1
+datasources:
+- access: proxy
+  editable: false
+  jsonData:
+    maxLines: 1000
+  name: Loki
+  type: loki
+  url: http://loki.pipeline-system:3100
+- access: direct
+  editable: false
+  name: External
+  type: prometheus
+  url: https://prometheus.example.org
+`, string(content))
+}
@@ -0,0 +1,301 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	pkgCluster "github.com/banzaicloud/pipeline/src/cluster"
+)
+
+// nolint: gochecknoglobals
+var (
+	serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+// generateRemoteWriteValues installs the credentials of the remote write endpoints to the cluster
+// and returns the remote write chart values along with the secrets to mount into Prometheus.
+func (op IntegratedServiceOperator) generateRemoteWriteValues(
+	ctx context.Context,
+	cluster integratedserviceadapter.Cluster,
+	specs []remoteWriteSpec,
+) ([]remoteWriteValues, []string, error) {
+	var values []remoteWriteValues
+	var secrets []string
+	for _, spec := range specs {
+		var rw = remoteWriteValues{
+			Name: spec.Name,
+			URL:  spec.URL,
+		}
+
+		if spec.Auth.Type != "" {
+			secretName, err := op.secretStore.GetNameByID(ctx, spec.Auth.SecretID)
+			if err != nil {
+				return nil, nil, errors.WrapIfWithDetails(err, "failed to get remote write secret", "secretID", spec.Auth.SecretID)
+			}
+
+			secretValues, err := op.secretStore.GetSecretValues(ctx, spec.Auth.SecretID)
+			if err != nil {
+				return nil, nil, errors.WrapIfWithDetails(err, "failed to get remote write secret values", "secretID", spec.Auth.SecretID)
+			}
+
+			var installSecretSpec map[string]pkgCluster.InstallSecretRequestSpecItem
+			switch spec.Auth.Type {
+			case remoteWriteAuthBasic:
+				if secretValues[secrettype.Username] == "" || secretValues[secrettype.Password] == "" {
+					return nil, nil, errors.NewWithDetails("remote write secret must contain username and password", "secretID", spec.Auth.SecretID)
+				}
+
+				installSecretSpec = map[string]pkgCluster.InstallSecretRequestSpecItem{
+					secrettype.Username: {Source: secrettype.Username},
+					secrettype.Password: {Source: secrettype.Password},
+				}
+			case remoteWriteAuthBearer:
+				if secretValues[bearerTokenSecretKey] == "" {
+					return nil, nil, errors.NewWithDetails("remote write secret must contain a token", "secretID", spec.Auth.SecretID)
+				}
+
+				installSecretSpec = map[string]pkgCluster.InstallSecretRequestSpecItem{
+					bearerTokenSecretKey: {Source: bearerTokenSecretKey},
+				}
+			}
+
+			k8sSecretName, err := op.installSecret(ctx, cluster.GetID(), secretName, pkgCluster.InstallSecretRequest{
+				SourceSecretName: secretName,
+				Namespace:        op.config.Namespace,
+				Spec:             installSecretSpec,
+				Update:           true,
+			})
+			if err != nil {
+				return nil, nil, errors.WrapIf(err, "failed to install remote write secret")
+			}
+
+			switch spec.Auth.Type {
+			case remoteWriteAuthBasic:
+				rw.BasicAuth = &basicAuthValues{
+					Username: secretKeySelectorValues{Name: k8sSecretName, Key: secrettype.Username},
+					Password: secretKeySelectorValues{Name: k8sSecretName, Key: secrettype.Password},
+				}
+			case remoteWriteAuthBearer:
+				// secrets listed in the Prometheus spec are mounted under /etc/prometheus/secrets
+				rw.BearerTokenFile = path.Join(prometheusSecretsMount, k8sSecretName, bearerTokenSecretKey)
+				secrets = append(secrets, k8sSecretName)
+			}
+		}
+
+		values = append(values, rw)
+	}
+
+	return values, secrets, nil
+}
+
+func generateScrapeConfigValues(specs []scrapeConfigSpec) []scrapeConfigValues {
+	var values []scrapeConfigValues
+	for _, spec := range specs {
+		values = append(values, scrapeConfigValues{
+			JobName:        spec.JobName,
+			MetricsPath:    spec.MetricsPath,
+			Scheme:         spec.Scheme,
+			ScrapeInterval: spec.Interval,
+			StaticConfigs:  []staticConfigValues{{Targets: spec.Targets}},
+		})
+	}
+
+	return values
+}
+
+// ensurePrometheusResources creates or updates the ServiceMonitor and PrometheusRule resources
+// of the spec and removes the ones that are no longer part of it.
+func (op IntegratedServiceOperator) ensurePrometheusResources(ctx context.Context, clusterID uint, spec prometheusSpec) error {
+	var desired = make(map[string]bool)
+
+	for _, sm := range spec.ServiceMonitors {
+		resource := generateServiceMonitor(sm, op.config.Namespace)
+		if err := op.ensureResource(ctx, clusterID, resource); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to ensure service monitor", "name", sm.Name)
+		}
+		desired[resourceKey(resource)] = true
+	}
+
+	for _, rg := range spec.RuleGroups {
+		resource := generatePrometheusRule(rg, op.config.Namespace)
+		if err := op.ensureResource(ctx, clusterID, resource); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to ensure Prometheus rule", "name", rg.Name)
+		}
+		desired[resourceKey(resource)] = true
+	}
+
+	// remove old resources with integrated service labels
+	for _, gvk := range []schema.GroupVersionKind{serviceMonitorGVK, prometheusRuleGVK} {
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := op.kubernetesService.List(ctx, clusterID, map[string]string{resourceLabelKey: integratedServiceName}, &list); err != nil {
+			if meta.IsNoMatchError(err) {
+				// the Prometheus operator CRDs are not installed, there is nothing to remove
+				continue
+			}
+
+			return errors.WrapIfWithDetails(err, "failed to list resources", "kind", gvk.Kind)
+		}
+
+		for i := range list.Items {
+			var item = &list.Items[i]
+			item.SetGroupVersionKind(gvk)
+			if desired[resourceKey(item)] {
+				continue
+			}
+
+			if err := op.kubernetesService.DeleteObject(ctx, clusterID, item); err != nil {
+				return errors.WrapIfWithDetails(err, "failed to delete resource", "kind", gvk.Kind, "name", item.GetName())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (op IntegratedServiceOperator) ensureResource(ctx context.Context, clusterID uint, resource *unstructured.Unstructured) error {
+	var oldResource unstructured.Unstructured
+	oldResource.SetGroupVersionKind(resource.GroupVersionKind())
+	if err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}, &oldResource); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return op.kubernetesService.EnsureObject(ctx, clusterID, resource)
+		}
+
+		return errors.WrapIf(err, "failed to get resource")
+	}
+
+	resource.SetResourceVersion(oldResource.GetResourceVersion())
+	return op.kubernetesService.Update(ctx, clusterID, resource)
+}
+
+func generateServiceMonitor(spec serviceMonitorSpec, defaultNamespace string) *unstructured.Unstructured {
+	var namespace = spec.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	var matchLabels = make(map[string]interface{}, len(spec.Selector))
+	for key, value := range spec.Selector {
+		matchLabels[key] = value
+	}
+
+	var endpoints []interface{}
+	for _, e := range spec.Endpoints {
+		var endpoint = map[string]interface{}{
+			"port": e.Port,
+		}
+		setIfNotEmpty(endpoint, "path", e.Path)
+		setIfNotEmpty(endpoint, "scheme", e.Scheme)
+		setIfNotEmpty(endpoint, "interval", e.Interval)
+		endpoints = append(endpoints, endpoint)
+	}
+
+	var resourceSpec = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": matchLabels,
+		},
+		"endpoints": endpoints,
+	}
+
+	if len(spec.NamespaceSelector) > 0 {
+		var namespaces []interface{}
+		for _, ns := range spec.NamespaceSelector {
+			namespaces = append(namespaces, ns)
+		}
+		resourceSpec["namespaceSelector"] = map[string]interface{}{
+			"matchNames": namespaces,
+		}
+	}
+
+	return newResource(serviceMonitorGVK, namespace, spec.Name, resourceSpec)
+}
+
+func generatePrometheusRule(spec ruleGroupSpec, namespace string) *unstructured.Unstructured {
+	var rules []interface{}
+	for _, r := range spec.Rules {
+		var rule = map[string]interface{}{
+			"expr": r.Expr,
+		}
+		setIfNotEmpty(rule, "alert", r.Alert)
+		setIfNotEmpty(rule, "record", r.Record)
+		setIfNotEmpty(rule, "for", r.For)
+		if len(r.Labels) > 0 {
+			rule["labels"] = stringMapToInterfaceMap(r.Labels)
+		}
+		if len(r.Annotations) > 0 {
+			rule["annotations"] = stringMapToInterfaceMap(r.Annotations)
+		}
+		rules = append(rules, rule)
+	}
+
+	var group = map[string]interface{}{
+		"name":  spec.Name,
+		"rules": rules,
+	}
+	setIfNotEmpty(group, "interval", spec.Interval)
+
+	return newResource(prometheusRuleGVK, namespace, spec.Name, map[string]interface{}{
+		"groups": []interface{}{group},
+	})
+}
+
+func newResource(gvk schema.GroupVersionKind, namespace string, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	resource.SetGroupVersionKind(gvk)
+	resource.SetNamespace(namespace)
+	resource.SetName(name)
+	resource.SetLabels(map[string]string{resourceLabelKey: integratedServiceName})
+
+	return resource
+}
+
+func resourceKey(resource *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", resource.GetKind(), resource.GetNamespace(), resource.GetName())
+}
+
+func setIfNotEmpty(m map[string]interface{}, key string, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func stringMapToInterfaceMap(m map[string]string) map[string]interface{} {
+	var result = make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+
+	return result
+}
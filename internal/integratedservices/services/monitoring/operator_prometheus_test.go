@@ -0,0 +1,101 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateServiceMonitor(t *testing.T) {
+	resource := generateServiceMonitor(serviceMonitorSpec{
+		Name:              "api",
+		Selector:          map[string]string{"app": "api"},
+		NamespaceSelector: []string{"apps"},
+		Endpoints: []serviceMonitorEndpointSpec{
+			{Port: "http", Path: "/metrics", Interval: "30s"},
+		},
+	}, "pipeline-system")
+
+	assert.Equal(t, serviceMonitorGVK, resource.GroupVersionKind())
+	assert.Equal(t, "pipeline-system", resource.GetNamespace())
+	assert.Equal(t, "api", resource.GetName())
+	assert.Equal(t, map[string]string{resourceLabelKey: integratedServiceName}, resource.GetLabels())
+	assert.Equal(t, obj{
+		"selector": obj{
+			"matchLabels": obj{"app": "api"},
+		},
+		"namespaceSelector": obj{
+			"matchNames": []interface{}{"apps"},
+		},
+		"endpoints": []interface{}{
+			obj{"port": "http", "path": "/metrics", "interval": "30s"},
+		},
+	}, resource.Object["spec"])
+}
+
+func TestGeneratePrometheusRule(t *testing.T) {
+	resource := generatePrometheusRule(ruleGroupSpec{
+		Name:     "api",
+		Interval: "1m",
+		Rules: []ruleSpec{
+			{Record: "job:http_requests:rate5m", Expr: "sum by (job) (rate(http_requests_total[5m]))"},
+			{
+				Alert:       "APIDown",
+				Expr:        `up{job="api"} == 0`,
+				For:         "5m",
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "API is down"},
+			},
+		},
+	}, "pipeline-system")
+
+	assert.Equal(t, prometheusRuleGVK, resource.GroupVersionKind())
+	assert.Equal(t, "pipeline-system", resource.GetNamespace())
+	assert.Equal(t, obj{
+		"groups": []interface{}{
+			obj{
+				"name":     "api",
+				"interval": "1m",
+				"rules": []interface{}{
+					obj{"record": "job:http_requests:rate5m", "expr": "sum by (job) (rate(http_requests_total[5m]))"},
+					obj{
+						"alert":       "APIDown",
+						"expr":        `up{job="api"} == 0`,
+						"for":         "5m",
+						"labels":      obj{"severity": "critical"},
+						"annotations": obj{"summary": "API is down"},
+					},
+				},
+			},
+		},
+	}, resource.Object["spec"])
+}
+
+func TestGenerateScrapeConfigValues(t *testing.T) {
+	values := generateScrapeConfigValues([]scrapeConfigSpec{
+		{JobName: "external", Targets: []string{"10.0.0.1:9100"}, Scheme: "https", Interval: "30s"},
+	})
+
+	assert.Equal(t, []scrapeConfigValues{
+		{
+			JobName:        "external",
+			Scheme:         "https",
+			ScrapeInterval: "30s",
+			StaticConfigs:  []staticConfigValues{{Targets: []string{"10.0.0.1:9100"}}},
+		},
+	}, values)
+}
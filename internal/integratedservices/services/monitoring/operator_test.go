@@ -172,3 +172,112 @@ func TestIntegratedServiceOperator_Deactivate(t *testing.T) {
 
 	_ = op.Deactivate(ctx, clusterID, nil)
 }
+
+func TestIntegratedServiceOperator_generateAlertManagerProvidersConfig(t *testing.T) {
+	orgID := uint(13)
+
+	orgSecretStore := dummyOrganizationalSecretStore{
+		Secrets: map[uint]map[string]*secret.SecretItemResponse{
+			orgID: {
+				"smtpsecret": {
+					ID:   "smtpsecret",
+					Type: secrettype.SMTPSecretType,
+					Values: map[string]string{
+						secrettype.SMTPSmarthost:  "smtp.example.org:587",
+						secrettype.SMTPFrom:       "alerts@example.org",
+						secrettype.SMTPUsername:   "alerts",
+						secrettype.SMTPPassword:   "pass",
+						secrettype.SMTPRequireTLS: "true",
+					},
+				},
+				"opsgeniesecret": {
+					ID:     "opsgeniesecret",
+					Type:   secrettype.OpsgenieSecretType,
+					Values: map[string]string{secrettype.OpsgenieAPIKey: "key"},
+				},
+				"webhooksecret": {
+					ID:     "webhooksecret",
+					Type:   secrettype.GenericSecret,
+					Values: map[string]string{bearerTokenSecretKey: "token"},
+				},
+			},
+		},
+	}
+
+	secretStore := commonadapter.NewSecretStore(orgSecretStore, commonadapter.OrgIDContextExtractorFunc(auth.GetCurrentOrganizationID))
+	op := MakeIntegratedServiceOperator(nil, nil, nil, nil, Config{}, services.NoopLogger{}, secretStore)
+
+	ctx := auth.SetCurrentOrganizationID(context.Background(), orgID)
+
+	config, err := op.generateAlertManagerProvidersConfig(ctx, map[string]interface{}{
+		alertmanagerProviderEmail: obj{
+			"enabled":      true,
+			"secretId":     "smtpsecret",
+			"to":           "oncall@example.org",
+			"sendResolved": true,
+		},
+		alertmanagerProviderOpsgenie: obj{
+			"enabled":  true,
+			"secretId": "opsgeniesecret",
+			"priority": "P1",
+			"route": obj{
+				"severities": []interface{}{"critical", "page"},
+			},
+		},
+		alertmanagerProviderWebhook: obj{
+			"enabled":  true,
+			"url":      "https://hooks.example.org/alerts",
+			"secretId": "webhooksecret",
+			"route": obj{
+				"matchers": obj{"team": "platform"},
+			},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	requireTLS := true
+	assert.Equal(t, []receiverItemValues{
+		{
+			Name: alertManagerProviderConfigName,
+			EmailConfigs: []emailConfigValues{
+				{
+					To:           "oncall@example.org",
+					From:         "alerts@example.org",
+					Smarthost:    "smtp.example.org:587",
+					AuthUsername: "alerts",
+					AuthPassword: "pass",
+					RequireTLS:   &requireTLS,
+					SendResolved: true,
+				},
+			},
+		},
+		{
+			Name:            alertmanagerProviderOpsgenie,
+			OpsgenieConfigs: []opsgenieConfigValues{{ApiKey: "key", Priority: "P1"}},
+		},
+		{
+			Name: alertmanagerProviderWebhook,
+			WebhookConfigs: []webhookConfigValues{
+				{
+					Url:        "https://hooks.example.org/alerts",
+					HTTPConfig: &httpConfigValues{BearerToken: "token"},
+				},
+			},
+		},
+	}, config.Receivers)
+	assert.Equal(t, routeValues{
+		Receiver: alertManagerProviderConfigName,
+		Routes: []interface{}{
+			childRouteValues{
+				Receiver: alertmanagerProviderOpsgenie,
+				MatchRE:  map[string]string{alertSeverityLabel: "critical|page"},
+			},
+			childRouteValues{
+				Receiver: alertmanagerProviderWebhook,
+				Match:    map[string]string{"team": "platform"},
+			},
+		},
+	}, config.Route)
+}
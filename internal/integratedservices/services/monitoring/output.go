@@ -31,6 +31,15 @@ const (
 	secretIDKey   = "secretId"
 	versionKey    = "version"
 	serviceURLKey = "serviceUrl"
+
+	remoteWriteKey     = "remoteWrite"
+	serviceMonitorsKey = "serviceMonitors"
+	ruleGroupsKey      = "ruleGroups"
+	statusKey          = "status"
+
+	resourceStatusCreated = "created"
+	resourceStatusMissing = "missing"
+	resourceStatusUnknown = "unknown"
 )
 
 type baseOutput struct {
@@ -15,13 +15,19 @@
 package monitoring
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"regexp"
+	"strings"
 
 	"emperror.dev/errors"
 	"github.com/mitchellh/mapstructure"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/pkg/promql"
 	"github.com/banzaicloud/pipeline/src/dns"
 )
 
@@ -34,16 +40,91 @@ type integratedServiceSpec struct {
 }
 
 type prometheusSpec struct {
-	Enabled bool                  `json:"enabled" mapstructure:"enabled"`
-	Storage storageSpec           `json:"storage" mapstructure:"storage"`
-	Ingress ingressSpecWithSecret `json:"ingress" mapstructure:"ingress"`
+	Enabled         bool                  `json:"enabled" mapstructure:"enabled"`
+	Storage         storageSpec           `json:"storage" mapstructure:"storage"`
+	Ingress         ingressSpecWithSecret `json:"ingress" mapstructure:"ingress"`
+	RemoteWrite     []remoteWriteSpec     `json:"remoteWrite" mapstructure:"remoteWrite"`
+	ScrapeConfigs   []scrapeConfigSpec    `json:"scrapeConfigs" mapstructure:"scrapeConfigs"`
+	ServiceMonitors []serviceMonitorSpec  `json:"serviceMonitors" mapstructure:"serviceMonitors"`
+	RuleGroups      []ruleGroupSpec       `json:"ruleGroups" mapstructure:"ruleGroups"`
+}
+
+type remoteWriteSpec struct {
+	Name string              `json:"name" mapstructure:"name"`
+	URL  string              `json:"url" mapstructure:"url"`
+	Auth remoteWriteAuthSpec `json:"auth" mapstructure:"auth"`
+}
+
+type remoteWriteAuthSpec struct {
+	Type     string `json:"type" mapstructure:"type"`
+	SecretID string `json:"secretId" mapstructure:"secretId"`
+}
+
+type scrapeConfigSpec struct {
+	JobName     string   `json:"jobName" mapstructure:"jobName"`
+	Targets     []string `json:"targets" mapstructure:"targets"`
+	MetricsPath string   `json:"metricsPath" mapstructure:"metricsPath"`
+	Scheme      string   `json:"scheme" mapstructure:"scheme"`
+	Interval    string   `json:"interval" mapstructure:"interval"`
+}
+
+type serviceMonitorSpec struct {
+	Name              string                       `json:"name" mapstructure:"name"`
+	Namespace         string                       `json:"namespace" mapstructure:"namespace"`
+	Selector          map[string]string            `json:"selector" mapstructure:"selector"`
+	NamespaceSelector []string                     `json:"namespaceSelector" mapstructure:"namespaceSelector"`
+	Endpoints         []serviceMonitorEndpointSpec `json:"endpoints" mapstructure:"endpoints"`
+}
+
+type serviceMonitorEndpointSpec struct {
+	Port     string `json:"port" mapstructure:"port"`
+	Path     string `json:"path" mapstructure:"path"`
+	Scheme   string `json:"scheme" mapstructure:"scheme"`
+	Interval string `json:"interval" mapstructure:"interval"`
+}
+
+type ruleGroupSpec struct {
+	Name     string     `json:"name" mapstructure:"name"`
+	Interval string     `json:"interval" mapstructure:"interval"`
+	Rules    []ruleSpec `json:"rules" mapstructure:"rules"`
+}
+
+type ruleSpec struct {
+	Alert       string            `json:"alert" mapstructure:"alert"`
+	Record      string            `json:"record" mapstructure:"record"`
+	Expr        string            `json:"expr" mapstructure:"expr"`
+	For         string            `json:"for" mapstructure:"for"`
+	Labels      map[string]string `json:"labels" mapstructure:"labels"`
+	Annotations map[string]string `json:"annotations" mapstructure:"annotations"`
 }
 
 type grafanaSpec struct {
-	Enabled    bool            `json:"enabled" mapstructure:"enabled"`
-	SecretId   string          `json:"secretId" mapstructure:"secretId"`
-	Dashboards bool            `json:"defaultDashboards" mapstructure:"defaultDashboards"`
-	Ingress    baseIngressSpec `json:"ingress" mapstructure:"ingress"`
+	Enabled          bool                    `json:"enabled" mapstructure:"enabled"`
+	SecretId         string                  `json:"secretId" mapstructure:"secretId"`
+	Dashboards       bool                    `json:"defaultDashboards" mapstructure:"defaultDashboards"`
+	Ingress          baseIngressSpec         `json:"ingress" mapstructure:"ingress"`
+	CustomDashboards []grafanaDashboardSpec  `json:"dashboards" mapstructure:"dashboards"`
+	Datasources      []grafanaDatasourceSpec `json:"datasources" mapstructure:"datasources"`
+}
+
+type grafanaDashboardSpec struct {
+	Name      string            `json:"name" mapstructure:"name"`
+	JSON      string            `json:"json" mapstructure:"json"`
+	ConfigMap *configMapKeySpec `json:"configMap" mapstructure:"configMap"`
+}
+
+type configMapKeySpec struct {
+	Namespace string `json:"namespace" mapstructure:"namespace"`
+	Name      string `json:"name" mapstructure:"name"`
+	Key       string `json:"key" mapstructure:"key"`
+}
+
+type grafanaDatasourceSpec struct {
+	Name     string                 `json:"name" mapstructure:"name"`
+	Type     string                 `json:"type" mapstructure:"type"`
+	URL      string                 `json:"url" mapstructure:"url"`
+	Access   string                 `json:"access" mapstructure:"access"`
+	JSONData map[string]interface{} `json:"jsonData" mapstructure:"jsonData"`
 }
 
 type storageSpec struct {
@@ -84,18 +165,56 @@ type pushgatewaySpec struct {
 }
 
 type pagerDutySpec struct {
-	Enabled         bool   `json:"enabled" mapstructure:"enabled"`
-	URL             string `json:"url" mapstructure:"url"`
-	SecretID        string `json:"secretId" mapstructure:"secretId"`
-	IntegrationType string `json:"integrationType" mapstructure:"integrationType"`
-	SendResolved    bool   `json:"sendResolved" mapstructure:"sendResolved"`
+	Enabled         bool      `json:"enabled" mapstructure:"enabled"`
+	URL             string    `json:"url" mapstructure:"url"`
+	SecretID        string    `json:"secretId" mapstructure:"secretId"`
+	IntegrationType string    `json:"integrationType" mapstructure:"integrationType"`
+	SendResolved    bool      `json:"sendResolved" mapstructure:"sendResolved"`
+	Route           routeSpec `json:"route" mapstructure:"route"`
 }
 
 type slackSpec struct {
-	Enabled      bool   `json:"enabled" mapstructure:"enabled"`
-	SecretID     string `json:"secretId" mapstructure:"secretId"`
-	Channel      string `json:"channel" mapstructure:"channel"`
-	SendResolved bool   `json:"sendResolved" mapstructure:"sendResolved"`
+	Enabled      bool      `json:"enabled" mapstructure:"enabled"`
+	SecretID     string    `json:"secretId" mapstructure:"secretId"`
+	Channel      string    `json:"channel" mapstructure:"channel"`
+	SendResolved bool      `json:"sendResolved" mapstructure:"sendResolved"`
+	Route        routeSpec `json:"route" mapstructure:"route"`
+}
+
+type emailSpec struct {
+	Enabled      bool      `json:"enabled" mapstructure:"enabled"`
+	SecretID     string    `json:"secretId" mapstructure:"secretId"`
+	To           string    `json:"to" mapstructure:"to"`
+	SendResolved bool      `json:"sendResolved" mapstructure:"sendResolved"`
+	Route        routeSpec `json:"route" mapstructure:"route"`
+}
+
+type opsgenieSpec struct {
+	Enabled      bool      `json:"enabled" mapstructure:"enabled"`
+	SecretID     string    `json:"secretId" mapstructure:"secretId"`
+	URL          string    `json:"url" mapstructure:"url"`
+	Priority     string    `json:"priority" mapstructure:"priority"`
+	SendResolved bool      `json:"sendResolved" mapstructure:"sendResolved"`
+	Route        routeSpec `json:"route" mapstructure:"route"`
+}
+
+type webhookSpec struct {
+	Enabled      bool      `json:"enabled" mapstructure:"enabled"`
+	URL          string    `json:"url" mapstructure:"url"`
+	SecretID     string    `json:"secretId" mapstructure:"secretId"`
+	SendResolved bool      `json:"sendResolved" mapstructure:"sendResolved"`
+	Route        routeSpec `json:"route" mapstructure:"route"`
+}
+
+// routeSpec selects the alerts sent to a notification provider.
+// Providers without a route receive every alert that is not routed elsewhere.
+type routeSpec struct {
+	Severities []string          `json:"severities" mapstructure:"severities"`
+	Matchers   map[string]string `json:"matchers" mapstructure:"matchers"`
+}
+
+func (s routeSpec) isEmpty() bool {
+	return len(s.Severities) == 0 && len(s.Matchers) == 0
 }
 
 func (s integratedServiceSpec) Validate() error {
@@ -145,9 +264,218 @@ func (s prometheusSpec) Validate() error {
 		return err
 	}
 
+	var remoteWriteNames = make(map[string]bool)
+	for _, rw := range s.RemoteWrite {
+		if err := rw.Validate(); err != nil {
+			return errors.WrapIf(err, "error during validating remote write")
+		}
+
+		if rw.Name != "" {
+			if remoteWriteNames[rw.Name] {
+				return errors.Errorf("remote write %q can only be configured once", rw.Name)
+			}
+			remoteWriteNames[rw.Name] = true
+		}
+	}
+
+	var jobNames = make(map[string]bool)
+	for _, sc := range s.ScrapeConfigs {
+		if err := sc.Validate(); err != nil {
+			return errors.WrapIf(err, "error during validating scrape config")
+		}
+
+		if jobNames[sc.JobName] {
+			return errors.Errorf("scrape job %q can only be configured once", sc.JobName)
+		}
+		jobNames[sc.JobName] = true
+	}
+
+	var serviceMonitorNames = make(map[string]bool)
+	for _, sm := range s.ServiceMonitors {
+		if err := sm.Validate(); err != nil {
+			return errors.WrapIf(err, "error during validating service monitor")
+		}
+
+		var key = sm.Namespace + "/" + sm.Name
+		if serviceMonitorNames[key] {
+			return errors.Errorf("service monitor %q can only be configured once", key)
+		}
+		serviceMonitorNames[key] = true
+	}
+
+	var ruleGroupNames = make(map[string]bool)
+	for _, rg := range s.RuleGroups {
+		if err := rg.Validate(); err != nil {
+			return errors.WrapIf(err, "error during validating rule group")
+		}
+
+		if ruleGroupNames[rg.Name] {
+			return errors.Errorf("rule group %q can only be configured once", rg.Name)
+		}
+		ruleGroupNames[rg.Name] = true
+	}
+
+	return nil
+}
+
+func (s remoteWriteSpec) Validate() error {
+	if s.URL == "" {
+		return requiredFieldError{fieldName: "url"}
+	}
+
+	if err := validateURL(s.URL); err != nil {
+		return err
+	}
+
+	switch s.Auth.Type {
+	case "":
+		if s.Auth.SecretID != "" {
+			return requiredFieldError{fieldName: "auth type"}
+		}
+	case remoteWriteAuthBasic, remoteWriteAuthBearer:
+		if s.Auth.SecretID == "" {
+			return requiredFieldError{fieldName: "auth secretId"}
+		}
+	default:
+		return errors.Errorf("invalid remote write auth type %q", s.Auth.Type)
+	}
+
 	return nil
 }
 
+func (s scrapeConfigSpec) Validate() error {
+	if s.JobName == "" {
+		return requiredFieldError{fieldName: "jobName"}
+	}
+
+	if len(s.Targets) == 0 {
+		return requiredFieldError{fieldName: "targets"}
+	}
+
+	if err := validateScheme(s.Scheme); err != nil {
+		return err
+	}
+
+	return validateDuration("interval", s.Interval)
+}
+
+func (s serviceMonitorSpec) Validate() error {
+	if s.Name == "" {
+		return requiredFieldError{fieldName: "name"}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(s.Name); len(errs) > 0 {
+		return errors.Errorf("invalid service monitor name %q: %s", s.Name, strings.Join(errs, ", "))
+	}
+
+	if len(s.Selector) == 0 {
+		return requiredFieldError{fieldName: "selector"}
+	}
+
+	if len(s.Endpoints) == 0 {
+		return requiredFieldError{fieldName: "endpoints"}
+	}
+
+	for _, endpoint := range s.Endpoints {
+		if endpoint.Port == "" {
+			return requiredFieldError{fieldName: "port"}
+		}
+
+		if err := validateScheme(endpoint.Scheme); err != nil {
+			return err
+		}
+
+		if err := validateDuration("interval", endpoint.Interval); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s ruleGroupSpec) Validate() error {
+	if s.Name == "" {
+		return requiredFieldError{fieldName: "name"}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(s.Name); len(errs) > 0 {
+		return errors.Errorf("invalid rule group name %q: %s", s.Name, strings.Join(errs, ", "))
+	}
+
+	if err := validateDuration("interval", s.Interval); err != nil {
+		return err
+	}
+
+	if len(s.Rules) == 0 {
+		return requiredFieldError{fieldName: "rules"}
+	}
+
+	for i, rule := range s.Rules {
+		if err := rule.Validate(); err != nil {
+			return errors.WrapIfWithDetails(err, "error during validating rule", "group", s.Name, "rule", i)
+		}
+	}
+
+	return nil
+}
+
+func (s ruleSpec) Validate() error {
+	switch {
+	case s.Alert == "" && s.Record == "":
+		return errors.New("either alert or record must be set")
+	case s.Alert != "" && s.Record != "":
+		return errors.New("only one of alert or record can be set")
+	case s.Record != "":
+		if !metricNameRegexp.MatchString(s.Record) {
+			return errors.Errorf("invalid recording rule name %q", s.Record)
+		}
+
+		if s.For != "" {
+			return errors.New("for can only be set for alerting rules")
+		}
+	}
+
+	if err := promql.Validate(s.Expr); err != nil {
+		return errors.WrapIf(err, "invalid rule expression")
+	}
+
+	return validateDuration("for", s.For)
+}
+
+// nolint: gochecknoglobals
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// nolint: gochecknoglobals
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// nolint: gochecknoglobals
+var durationRegexp = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+func validateDuration(fieldName string, duration string) error {
+	if duration != "" && !durationRegexp.MatchString(duration) {
+		return errors.Errorf("invalid %s %q", fieldName, duration)
+	}
+
+	return nil
+}
+
+func validateURL(rawURL string) error {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid url %q", rawURL)
+	}
+
+	return nil
+}
+
+func validateScheme(scheme string) error {
+	switch scheme {
+	case "", "http", "https":
+		return nil
+	default:
+		return errors.Errorf("invalid scheme %q", scheme)
+	}
+}
+
 func (s ingressSpecWithSecret) Validate(ingressType string) error {
 	return s.baseIngressSpec.Validate(ingressType)
 }
@@ -195,6 +523,87 @@ func (s grafanaSpec) Validate() error {
 		if err := s.Ingress.Validate(ingressTypeGrafana); err != nil {
 			return errors.WrapIf(err, "error during validate Grafana ingress")
 		}
+
+		var dashboardNames = make(map[string]bool, len(s.CustomDashboards))
+		for _, dashboard := range s.CustomDashboards {
+			if err := dashboard.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validate Grafana dashboard")
+			}
+
+			if dashboardNames[dashboard.Name] {
+				return errors.Errorf("duplicated Grafana dashboard name %q", dashboard.Name)
+			}
+			dashboardNames[dashboard.Name] = true
+		}
+
+		var datasourceNames = make(map[string]bool, len(s.Datasources))
+		for _, datasource := range s.Datasources {
+			if err := datasource.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validate Grafana datasource")
+			}
+
+			if datasourceNames[datasource.Name] {
+				return errors.Errorf("duplicated Grafana datasource name %q", datasource.Name)
+			}
+			datasourceNames[datasource.Name] = true
+		}
+	}
+
+	return nil
+}
+
+func (s grafanaDashboardSpec) Validate() error {
+	if s.Name == "" {
+		return requiredFieldError{fieldName: "name"}
+	}
+
+	if errs := validation.IsDNS1123Label(s.Name); len(errs) > 0 {
+		return errors.Errorf("invalid dashboard name %q: %s", s.Name, strings.Join(errs, ", "))
+	}
+
+	switch {
+	case s.JSON != "" && s.ConfigMap != nil:
+		return errors.Errorf("dashboard %q must have either inline JSON or a ConfigMap reference, not both", s.Name)
+	case s.JSON != "":
+		if !json.Valid([]byte(s.JSON)) {
+			return errors.Errorf("dashboard %q is not a valid JSON document", s.Name)
+		}
+	case s.ConfigMap != nil:
+		if s.ConfigMap.Name == "" {
+			return requiredFieldError{fieldName: "configMap name"}
+		}
+
+		if s.ConfigMap.Key == "" {
+			return requiredFieldError{fieldName: "configMap key"}
+		}
+	default:
+		return errors.Errorf("dashboard %q must have either inline JSON or a ConfigMap reference", s.Name)
+	}
+
+	return nil
+}
+
+func (s grafanaDatasourceSpec) Validate() error {
+	if s.Name == "" {
+		return requiredFieldError{fieldName: "name"}
+	}
+
+	if s.Type == "" {
+		return requiredFieldError{fieldName: "type"}
+	}
+
+	if s.URL == "" {
+		return requiredFieldError{fieldName: "url"}
+	}
+
+	if err := validateURL(s.URL); err != nil {
+		return err
+	}
+
+	switch s.Access {
+	case "", grafanaDatasourceAccessProxy, grafanaDatasourceAccessDirect:
+	default:
+		return errors.Errorf("invalid datasource access mode %q, must be %q or %q", s.Access, grafanaDatasourceAccessProxy, grafanaDatasourceAccessDirect)
 	}
 
 	return nil
@@ -228,6 +637,39 @@ func (s alertmanagerSpec) Validate() error {
 				return errors.WrapIf(err, "error during validating PagerDuty")
 			}
 		}
+
+		// validate email notification provider
+		if emailProv, ok := s.Provider[alertmanagerProviderEmail]; ok {
+			var email emailSpec
+			if err := mapstructure.Decode(emailProv, &email); err != nil {
+				return errors.WrapIf(err, "failed to bind email config")
+			}
+			if err := email.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validating email")
+			}
+		}
+
+		// validate Opsgenie notification provider
+		if opsgenieProv, ok := s.Provider[alertmanagerProviderOpsgenie]; ok {
+			var opsgenie opsgenieSpec
+			if err := mapstructure.Decode(opsgenieProv, &opsgenie); err != nil {
+				return errors.WrapIf(err, "failed to bind Opsgenie config")
+			}
+			if err := opsgenie.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validating Opsgenie")
+			}
+		}
+
+		// validate webhook notification provider
+		if webhookProv, ok := s.Provider[alertmanagerProviderWebhook]; ok {
+			var webhook webhookSpec
+			if err := mapstructure.Decode(webhookProv, &webhook); err != nil {
+				return errors.WrapIf(err, "failed to bind webhook config")
+			}
+			if err := webhook.Validate(); err != nil {
+				return errors.WrapIf(err, "error during validating webhook")
+			}
+		}
 	}
 
 	return nil
@@ -242,6 +684,8 @@ func (s slackSpec) Validate() error {
 		if s.Channel == "" {
 			return requiredFieldError{fieldName: "channel"}
 		}
+
+		return s.Route.Validate()
 	}
 
 	return nil
@@ -264,6 +708,84 @@ func (s pagerDutySpec) Validate() error {
 		if s.IntegrationType != pagerDutyIntegrationEventApiV2 && s.IntegrationType != pagerDutyIntegrationPrometheus {
 			return errors.New(fmt.Sprintf("integration type should be only just: %s or %s", pagerDutyIntegrationEventApiV2, pagerDutyIntegrationPrometheus))
 		}
+
+		return s.Route.Validate()
+	}
+
+	return nil
+}
+
+func (s emailSpec) Validate() error {
+	if s.Enabled {
+		if s.SecretID == "" {
+			return requiredFieldError{fieldName: "secretId"}
+		}
+
+		if s.To == "" {
+			return requiredFieldError{fieldName: "to"}
+		}
+
+		if _, err := mail.ParseAddressList(s.To); err != nil {
+			return errors.Errorf("invalid email recipient %q", s.To)
+		}
+
+		return s.Route.Validate()
+	}
+
+	return nil
+}
+
+func (s opsgenieSpec) Validate() error {
+	if s.Enabled {
+		if s.SecretID == "" {
+			return requiredFieldError{fieldName: "secretId"}
+		}
+
+		if s.URL != "" {
+			if err := validateURL(s.URL); err != nil {
+				return err
+			}
+		}
+
+		switch s.Priority {
+		case "", "P1", "P2", "P3", "P4", "P5":
+		default:
+			return errors.Errorf("invalid Opsgenie priority %q", s.Priority)
+		}
+
+		return s.Route.Validate()
+	}
+
+	return nil
+}
+
+func (s webhookSpec) Validate() error {
+	if s.Enabled {
+		if s.URL == "" {
+			return requiredFieldError{fieldName: "url"}
+		}
+
+		if err := validateURL(s.URL); err != nil {
+			return err
+		}
+
+		return s.Route.Validate()
+	}
+
+	return nil
+}
+
+func (s routeSpec) Validate() error {
+	for _, severity := range s.Severities {
+		if severity == "" {
+			return errors.New("route severity cannot be empty")
+		}
+	}
+
+	for label := range s.Matchers {
+		if !labelNameRegexp.MatchString(label) {
+			return errors.Errorf("invalid route matcher label %q", label)
+		}
 	}
 
 	return nil
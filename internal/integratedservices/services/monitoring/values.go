@@ -60,10 +60,17 @@ type grafanaValues struct {
 	Image                    imageValues       `json:"image"`
 	Persistence              persistenceValues `json:"persistence"`
 	Sidecar                  sidecar           `json:"sidecar"`
+	PodAnnotations           map[string]string `json:"podAnnotations,omitempty"`
 }
 
 type sidecar struct {
 	Datasources datasources `json:"datasources"`
+	Dashboards  dashboards  `json:"dashboards"`
+}
+
+type dashboards struct {
+	Enabled bool   `json:"enabled"`
+	Label   string `json:"label"`
 }
 
 type datasources struct {
@@ -101,10 +108,32 @@ type routeValues struct {
 	Routes   []interface{} `json:"routes"`
 }
 
+type childRouteValues struct {
+	Receiver string            `json:"receiver"`
+	Match    map[string]string `json:"match,omitempty"`
+	MatchRE  map[string]string `json:"match_re,omitempty"`
+}
+
 type receiverItemValues struct {
 	Name             string                  `json:"name"`
 	SlackConfigs     []slackConfigValues     `json:"slack_configs,omitempty"`
-	PagerdutyConfigs []pagerdutyConfigValues `json:"pagerduty_config,omitempty"`
+	PagerdutyConfigs []pagerdutyConfigValues `json:"pagerduty_configs,omitempty"`
+	EmailConfigs     []emailConfigValues     `json:"email_configs,omitempty"`
+	OpsgenieConfigs  []opsgenieConfigValues  `json:"opsgenie_configs,omitempty"`
+	WebhookConfigs   []webhookConfigValues   `json:"webhook_configs,omitempty"`
+}
+
+func (r receiverItemValues) isEmpty() bool {
+	return len(r.SlackConfigs) == 0 && len(r.PagerdutyConfigs) == 0 && len(r.EmailConfigs) == 0 &&
+		len(r.OpsgenieConfigs) == 0 && len(r.WebhookConfigs) == 0
+}
+
+func (r *receiverItemValues) merge(other receiverItemValues) {
+	r.SlackConfigs = append(r.SlackConfigs, other.SlackConfigs...)
+	r.PagerdutyConfigs = append(r.PagerdutyConfigs, other.PagerdutyConfigs...)
+	r.EmailConfigs = append(r.EmailConfigs, other.EmailConfigs...)
+	r.OpsgenieConfigs = append(r.OpsgenieConfigs, other.OpsgenieConfigs...)
+	r.WebhookConfigs = append(r.WebhookConfigs, other.WebhookConfigs...)
 }
 
 type slackConfigValues struct {
@@ -120,6 +149,33 @@ type pagerdutyConfigValues struct {
 	SendResolved bool   `json:"send_resolved"`
 }
 
+type emailConfigValues struct {
+	To           string `json:"to"`
+	From         string `json:"from"`
+	Smarthost    string `json:"smarthost"`
+	AuthUsername string `json:"auth_username,omitempty"`
+	AuthPassword string `json:"auth_password,omitempty"`
+	RequireTLS   *bool  `json:"require_tls,omitempty"`
+	SendResolved bool   `json:"send_resolved"`
+}
+
+type opsgenieConfigValues struct {
+	ApiKey       string `json:"api_key"`
+	ApiUrl       string `json:"api_url,omitempty"`
+	Priority     string `json:"priority,omitempty"`
+	SendResolved bool   `json:"send_resolved"`
+}
+
+type webhookConfigValues struct {
+	Url          string            `json:"url"`
+	HTTPConfig   *httpConfigValues `json:"http_config,omitempty"`
+	SendResolved bool              `json:"send_resolved"`
+}
+
+type httpConfigValues struct {
+	BearerToken string `json:"bearer_token,omitempty"`
+}
+
 type baseSpecValues struct {
 	RoutePrefix string      `json:"routePrefix"`
 	Image       imageValues `json:"image"`
@@ -131,6 +187,39 @@ type PrometheusSpecValues struct {
 	Retention                               string                 `json:"retention"`
 	StorageSpec                             map[string]interface{} `json:"storageSpec"`
 	ServiceMonitorSelectorNilUsesHelmValues bool                   `json:"serviceMonitorSelectorNilUsesHelmValues"`
+	RuleSelectorNilUsesHelmValues           bool                   `json:"ruleSelectorNilUsesHelmValues"`
+	RemoteWrite                             []remoteWriteValues    `json:"remoteWrite,omitempty"`
+	Secrets                                 []string               `json:"secrets,omitempty"`
+	AdditionalScrapeConfigs                 []scrapeConfigValues   `json:"additionalScrapeConfigs,omitempty"`
+}
+
+type remoteWriteValues struct {
+	Name            string           `json:"name,omitempty"`
+	URL             string           `json:"url"`
+	BasicAuth       *basicAuthValues `json:"basicAuth,omitempty"`
+	BearerTokenFile string           `json:"bearerTokenFile,omitempty"`
+}
+
+type basicAuthValues struct {
+	Username secretKeySelectorValues `json:"username"`
+	Password secretKeySelectorValues `json:"password"`
+}
+
+type secretKeySelectorValues struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+type scrapeConfigValues struct {
+	JobName        string               `json:"job_name"`
+	MetricsPath    string               `json:"metrics_path,omitempty"`
+	Scheme         string               `json:"scheme,omitempty"`
+	ScrapeInterval string               `json:"scrape_interval,omitempty"`
+	StaticConfigs  []staticConfigValues `json:"static_configs"`
+}
+
+type staticConfigValues struct {
+	Targets []string `json:"targets"`
 }
 
 type prometheusValues struct {
@@ -0,0 +1,192 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/policy"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+)
+
+type obj = map[string]interface{}
+
+type dummyClusterGetter struct {
+	Clusters map[uint]dummyCluster
+}
+
+func (d dummyClusterGetter) GetClusterByIDOnly(ctx context.Context, clusterID uint) (integratedserviceadapter.Cluster, error) {
+	return d.Clusters[clusterID], nil
+}
+
+func (d dummyClusterGetter) GetClusterStatus(ctx context.Context, clusterID uint) (string, error) {
+	if c, ok := d.Clusters[clusterID]; ok {
+		return c.Status, nil
+	}
+	return "", errors.New("cluster not found")
+}
+
+type dummyCluster struct {
+	OrgID  uint
+	ID     uint
+	Status string
+}
+
+func (d dummyCluster) GetK8sConfig() ([]byte, error) {
+	return nil, nil
+}
+
+func (d dummyCluster) GetName() string {
+	return "cluster"
+}
+
+func (d dummyCluster) GetOrganizationId() uint {
+	return d.OrgID
+}
+
+func (d dummyCluster) GetUID() string {
+	return ""
+}
+
+func (d dummyCluster) GetID() uint {
+	return d.ID
+}
+
+func (d dummyCluster) NodePoolExists(nodePoolName string) bool {
+	return false
+}
+
+func (d dummyCluster) RbacEnabled() bool {
+	return true
+}
+
+type dummyHelmService struct {
+	releases map[string]string
+}
+
+func (d *dummyHelmService) ApplyDeployment(
+	ctx context.Context,
+	clusterID uint,
+	namespace string,
+	chartName string,
+	releaseName string,
+	values []byte,
+	chartVersion string,
+) error {
+	d.releases[releaseName] = chartName
+
+	return nil
+}
+
+func (d *dummyHelmService) DeleteDeployment(ctx context.Context, clusterID uint, releaseName, namespace string) error {
+	delete(d.releases, releaseName)
+
+	return nil
+}
+
+func (d *dummyHelmService) GetDeployment(ctx context.Context, clusterID uint, releaseName, namespace string) (*pkgHelm.GetDeploymentResponse, error) {
+	return &pkgHelm.GetDeploymentResponse{ReleaseName: releaseName}, nil
+}
+
+// inMemoryKubernetesService stores unstructured cluster scoped objects by kind and name.
+type inMemoryKubernetesService struct {
+	objects map[string]*unstructured.Unstructured
+}
+
+func newInMemoryKubernetesService() *inMemoryKubernetesService {
+	return &inMemoryKubernetesService{objects: make(map[string]*unstructured.Unstructured)}
+}
+
+func objectKey(kind string, name string) string {
+	return kind + "/" + name
+}
+
+func (s *inMemoryKubernetesService) EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	u := o.(*unstructured.Unstructured)
+	if _, ok := s.objects[objectKey(u.GetKind(), u.GetName())]; !ok {
+		s.objects[objectKey(u.GetKind(), u.GetName())] = u.DeepCopy()
+	}
+
+	return nil
+}
+
+func (s *inMemoryKubernetesService) Update(ctx context.Context, clusterID uint, o runtime.Object) error {
+	u := o.(*unstructured.Unstructured)
+	s.objects[objectKey(u.GetKind(), u.GetName())] = u.DeepCopy()
+
+	return nil
+}
+
+func (s *inMemoryKubernetesService) DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error {
+	u := o.(*unstructured.Unstructured)
+	delete(s.objects, objectKey(u.GetKind(), u.GetName()))
+
+	return nil
+}
+
+func (s *inMemoryKubernetesService) GetObject(ctx context.Context, clusterID uint, objRef corev1.ObjectReference, o runtime.Object) error {
+	u := o.(*unstructured.Unstructured)
+
+	stored, ok := s.objects[objectKey(u.GetKind(), objRef.Name)]
+	if !ok {
+		return k8sapierrors.NewNotFound(schema.GroupResource{Resource: u.GetKind()}, objRef.Name)
+	}
+
+	stored.DeepCopyInto(u)
+
+	return nil
+}
+
+func (s *inMemoryKubernetesService) List(ctx context.Context, clusterID uint, labels map[string]string, o runtime.Object) error {
+	list := o.(*unstructured.UnstructuredList)
+	kind := strings.TrimSuffix(list.GetKind(), "List")
+
+Objects:
+	for _, stored := range s.objects {
+		if stored.GetKind() != kind {
+			continue
+		}
+
+		for k, v := range labels {
+			if stored.GetLabels()[k] != v {
+				continue Objects
+			}
+		}
+
+		list.Items = append(list.Items, *stored.DeepCopy())
+	}
+
+	return nil
+}
+
+type inMemoryTemplateGetter map[string]policy.Template
+
+func (g inMemoryTemplateGetter) GetTemplate(ctx context.Context, organizationID uint, name string) (policy.Template, error) {
+	template, ok := g[name]
+	if !ok {
+		return policy.Template{}, policy.TemplateNotFoundError{OrganizationID: organizationID, Name: name}
+	}
+
+	return template, nil
+}
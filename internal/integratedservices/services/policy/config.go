@@ -0,0 +1,48 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"emperror.dev/errors"
+)
+
+// Config contains configuration for the policy integrated service.
+type Config struct {
+	Namespace string
+	Charts    ChartsConfig
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.Namespace == "" {
+		return errors.New("policy namespace is required")
+	}
+
+	if c.Charts.Gatekeeper.Chart == "" {
+		return errors.New("policy gatekeeper chart is required")
+	}
+
+	return nil
+}
+
+type ChartsConfig struct {
+	Gatekeeper ChartConfig
+}
+
+type ChartConfig struct {
+	Chart   string
+	Version string
+	Values  map[string]interface{}
+}
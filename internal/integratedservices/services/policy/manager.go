@@ -0,0 +1,119 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+)
+
+// IntegratedServiceManager implements the policy integrated service manager
+type IntegratedServiceManager struct {
+	integratedservices.PassthroughIntegratedServiceSpecPreparer
+
+	kubernetesService KubernetesService
+	config            Config
+	logger            services.Logger
+}
+
+// MakeIntegratedServiceManager builds a new integrated service manager component
+func MakeIntegratedServiceManager(kubernetesService KubernetesService, config Config, logger services.Logger) IntegratedServiceManager {
+	return IntegratedServiceManager{
+		kubernetesService: kubernetesService,
+		config:            config,
+		logger:            logger,
+	}
+}
+
+// Name returns the integrated service' name
+func (m IntegratedServiceManager) Name() string {
+	return integratedServiceName
+}
+
+// GetOutput returns the policy integrated service' output including the violations found by the Gatekeeper audit
+func (m IntegratedServiceManager) GetOutput(ctx context.Context, clusterID uint, spec integratedservices.IntegratedServiceSpec) (integratedservices.IntegratedServiceOutput, error) {
+	boundSpec, err := bindIntegratedServiceSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make([]map[string]interface{}, 0, len(boundSpec.Constraints))
+
+	for _, constraintSpec := range boundSpec.Constraints {
+		constraints = append(constraints, m.getConstraintOutput(ctx, clusterID, constraintSpec))
+	}
+
+	out := map[string]interface{}{
+		"gatekeeper": map[string]interface{}{
+			"version": m.config.Charts.Gatekeeper.Version,
+		},
+		"constraints": constraints,
+	}
+
+	return out, nil
+}
+
+// getConstraintOutput returns the audit results of a constraint.
+// Constraints that cannot be read (eg. the audit has not run yet) are reported with an error instead of failing the output.
+func (m IntegratedServiceManager) getConstraintOutput(ctx context.Context, clusterID uint, spec constraintSpec) map[string]interface{} {
+	out := map[string]interface{}{
+		"name":              spec.Name,
+		"template":          spec.Template,
+		"enforcementAction": spec.enforcementAction(),
+	}
+
+	template := newConstraintTemplateObject(spec.Template)
+	if err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{Name: spec.Template}, template); err != nil {
+		out["error"] = errors.WrapIf(err, "failed to get constraint template").Error()
+
+		return out
+	}
+
+	o := newConstraintObject(constraintTemplateKindOf(template), spec.Name)
+	if err := m.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{Name: spec.Name}, o); err != nil {
+		out["error"] = errors.WrapIf(err, "failed to get constraint").Error()
+
+		return out
+	}
+
+	totalViolations, violations := constraintViolations(o)
+
+	out["totalViolations"] = totalViolations
+	out["violations"] = violations
+
+	return out
+}
+
+// ValidateSpec validates a policy integrated service specification
+func (m IntegratedServiceManager) ValidateSpec(ctx context.Context, spec integratedservices.IntegratedServiceSpec) error {
+	boundSpec, err := bindIntegratedServiceSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	if err := boundSpec.Validate(); err != nil {
+		return integratedservices.InvalidIntegratedServiceSpecError{
+			IntegratedServiceName: integratedServiceName,
+			Problem:               err.Error(),
+		}
+	}
+
+	return nil
+}
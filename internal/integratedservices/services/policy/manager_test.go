@@ -0,0 +1,104 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/internal/policy"
+)
+
+func TestIntegratedServiceManager_Name(t *testing.T) {
+	mng := MakeIntegratedServiceManager(nil, Config{}, services.NoopLogger{})
+
+	assert.Equal(t, "policy", mng.Name())
+}
+
+func TestIntegratedServiceManager_GetOutput(t *testing.T) {
+	clusterID := uint(42)
+	kubernetesService := newInMemoryKubernetesService()
+
+	var requiredLabels policy.Template
+	for _, template := range policy.BuiltInTemplates() {
+		if template.Name == "k8srequiredlabels" {
+			requiredLabels = template
+		}
+	}
+
+	require.NoError(t, kubernetesService.EnsureObject(context.Background(), clusterID, constraintTemplate(requiredLabels)))
+
+	o := constraint(constraintSpec{Name: "must-have-team", Template: "k8srequiredlabels"}, "K8sRequiredLabels")
+	o.Object["status"] = map[string]interface{}{
+		"totalViolations": int64(1),
+		"violations": []interface{}{
+			map[string]interface{}{
+				"kind":              "Namespace",
+				"name":              "default",
+				"message":           "you must provide labels: {\"team\"}",
+				"enforcementAction": "deny",
+			},
+		},
+	}
+	require.NoError(t, kubernetesService.EnsureObject(context.Background(), clusterID, o))
+
+	mng := MakeIntegratedServiceManager(
+		kubernetesService,
+		Config{Charts: ChartsConfig{Gatekeeper: ChartConfig{Version: "3.1.0"}}},
+		services.NoopLogger{},
+	)
+
+	spec := integratedservices.IntegratedServiceSpec{
+		"constraints": []obj{
+			{"name": "must-have-team", "template": "k8srequiredlabels"},
+			{"name": "no-privileged", "template": "k8spspprivilegedcontainer"},
+		},
+	}
+
+	output, err := mng.GetOutput(context.Background(), clusterID, spec)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"version": "3.1.0"}, output["gatekeeper"])
+
+	constraints := output["constraints"].([]map[string]interface{})
+	require.Len(t, constraints, 2)
+
+	assert.Equal(t, int64(1), constraints[0]["totalViolations"])
+	assert.Equal(t, []violation{
+		{
+			Kind:              "Namespace",
+			Name:              "default",
+			Message:           "you must provide labels: {\"team\"}",
+			EnforcementAction: "deny",
+		},
+	}, constraints[0]["violations"])
+
+	assert.Equal(t, "no-privileged", constraints[1]["name"])
+	assert.Contains(t, constraints[1], "error")
+}
+
+func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
+	mng := MakeIntegratedServiceManager(nil, Config{}, services.NoopLogger{})
+
+	err := mng.ValidateSpec(context.Background(), integratedservices.IntegratedServiceSpec{
+		"constraints": []obj{{"name": "policy"}},
+	})
+	assert.True(t, integratedservices.IsInputValidationError(err))
+}
@@ -0,0 +1,194 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/banzaicloud/pipeline/internal/policy"
+)
+
+func managedLabels() map[string]string {
+	return map[string]string{managedByLabel: managedByValue}
+}
+
+func newConstraintTemplateObject(name string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion(constraintTemplateAPIVersion)
+	o.SetKind(constraintTemplateKind)
+	o.SetName(name)
+
+	return o
+}
+
+func newConstraintTemplateListObject() *unstructured.UnstructuredList {
+	o := &unstructured.UnstructuredList{}
+	o.SetAPIVersion(constraintTemplateAPIVersion)
+	o.SetKind(constraintTemplateKind + "List")
+
+	return o
+}
+
+// constraintTemplate builds the ConstraintTemplate resource of a policy template.
+func constraintTemplate(template policy.Template) *unstructured.Unstructured {
+	crdSpec := map[string]interface{}{
+		"names": map[string]interface{}{
+			"kind": template.Kind,
+		},
+	}
+
+	if template.Parameters != nil {
+		crdSpec["validation"] = map[string]interface{}{
+			"openAPIV3Schema": template.Parameters,
+		}
+	}
+
+	o := newConstraintTemplateObject(template.Name)
+	o.SetLabels(managedLabels())
+	o.Object["spec"] = map[string]interface{}{
+		"crd": map[string]interface{}{
+			"spec": crdSpec,
+		},
+		"targets": []interface{}{
+			map[string]interface{}{
+				"target": admissionTarget,
+				"rego":   template.Rego,
+			},
+		},
+	}
+
+	return o
+}
+
+// constraintTemplateKindOf returns the kind of constraints created from a ConstraintTemplate resource.
+func constraintTemplateKindOf(o *unstructured.Unstructured) string {
+	kind, _, _ := unstructured.NestedString(o.Object, "spec", "crd", "spec", "names", "kind")
+
+	return kind
+}
+
+func newConstraintObject(kind string, name string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion(constraintAPIVersion)
+	o.SetKind(kind)
+	o.SetName(name)
+
+	return o
+}
+
+func newConstraintListObject(kind string) *unstructured.UnstructuredList {
+	o := &unstructured.UnstructuredList{}
+	o.SetAPIVersion(constraintAPIVersion)
+	o.SetKind(kind + "List")
+
+	return o
+}
+
+// constraint builds the constraint resource of a constraint spec.
+func constraint(spec constraintSpec, kind string) *unstructured.Unstructured {
+	match := make(map[string]interface{})
+
+	if len(spec.Match.Kinds) > 0 {
+		kinds := make([]interface{}, 0, len(spec.Match.Kinds))
+		for _, k := range spec.Match.Kinds {
+			apiGroups := k.APIGroups
+			if len(apiGroups) == 0 {
+				apiGroups = []string{""}
+			}
+
+			kinds = append(kinds, map[string]interface{}{
+				"apiGroups": toInterfaceSlice(apiGroups),
+				"kinds":     toInterfaceSlice(k.Kinds),
+			})
+		}
+
+		match["kinds"] = kinds
+	}
+
+	if len(spec.Match.Namespaces) > 0 {
+		match["namespaces"] = toInterfaceSlice(spec.Match.Namespaces)
+	}
+
+	if len(spec.Match.ExcludedNamespaces) > 0 {
+		match["excludedNamespaces"] = toInterfaceSlice(spec.Match.ExcludedNamespaces)
+	}
+
+	constraintSpec := map[string]interface{}{
+		"enforcementAction": spec.enforcementAction(),
+		"match":             match,
+	}
+
+	if spec.Parameters != nil {
+		constraintSpec["parameters"] = spec.Parameters
+	}
+
+	labels := managedLabels()
+	labels[templateLabel] = spec.Template
+
+	o := newConstraintObject(kind, spec.Name)
+	o.SetLabels(labels)
+	o.Object["spec"] = constraintSpec
+
+	return o
+}
+
+func toInterfaceSlice(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+
+	return result
+}
+
+// violation is a resource violating a constraint as reported by the Gatekeeper audit.
+type violation struct {
+	Kind              string `json:"kind"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace,omitempty"`
+	Message           string `json:"message"`
+	EnforcementAction string `json:"enforcementAction,omitempty"`
+}
+
+// constraintViolations returns the violations reported in the status of a constraint resource.
+func constraintViolations(o *unstructured.Unstructured) (int64, []violation) {
+	total, _, _ := unstructured.NestedInt64(o.Object, "status", "totalViolations")
+
+	items, _, _ := unstructured.NestedSlice(o.Object, "status", "violations")
+
+	violations := make([]violation, 0, len(items))
+	for _, item := range items {
+		v, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		violations = append(violations, violation{
+			Kind:              stringField(v, "kind"),
+			Name:              stringField(v, "name"),
+			Namespace:         stringField(v, "namespace"),
+			Message:           stringField(v, "message"),
+			EnforcementAction: stringField(v, "enforcementAction"),
+		})
+	}
+
+	return total, violations
+}
+
+func stringField(o map[string]interface{}, field string) string {
+	value, _ := o[field].(string)
+
+	return value
+}
@@ -0,0 +1,269 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/internal/policy"
+	"github.com/banzaicloud/pipeline/pkg/backoff"
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+// TemplateGetter returns templates from the policy library of an organization.
+type TemplateGetter interface {
+	// GetTemplate returns a template of an organization.
+	GetTemplate(ctx context.Context, organizationID uint, name string) (policy.Template, error)
+}
+
+// IntegratedServiceOperator implements the policy integrated service operator
+type IntegratedServiceOperator struct {
+	clusterGetter     integratedserviceadapter.ClusterGetter
+	clusterService    integratedservices.ClusterService
+	helmService       services.HelmService
+	kubernetesService KubernetesService
+	templates         TemplateGetter
+	config            Config
+	logger            services.Logger
+
+	// constraints can only be created after Gatekeeper has created their CRDs from the templates
+	backoffConfig backoff.ConstantBackoffConfig
+}
+
+// MakeIntegratedServiceOperator returns a policy integrated service operator
+func MakeIntegratedServiceOperator(
+	clusterGetter integratedserviceadapter.ClusterGetter,
+	clusterService integratedservices.ClusterService,
+	helmService services.HelmService,
+	kubernetesService KubernetesService,
+	templates TemplateGetter,
+	config Config,
+	logger services.Logger,
+) IntegratedServiceOperator {
+	return IntegratedServiceOperator{
+		clusterGetter:     clusterGetter,
+		clusterService:    clusterService,
+		helmService:       helmService,
+		kubernetesService: kubernetesService,
+		templates:         templates,
+		config:            config,
+		logger:            logger,
+
+		backoffConfig: backoff.ConstantBackoffConfig{
+			Delay:      5 * time.Second,
+			MaxRetries: 24,
+		},
+	}
+}
+
+// Name returns the name of the policy integrated service
+func (op IntegratedServiceOperator) Name() string {
+	return integratedServiceName
+}
+
+// Apply installs Gatekeeper and makes the constraints on the cluster match the specification
+func (op IntegratedServiceOperator) Apply(ctx context.Context, clusterID uint, spec integratedservices.IntegratedServiceSpec) error {
+	ctx, err := op.ensureOrgIDInContext(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := op.clusterService.CheckClusterReady(ctx, clusterID); err != nil {
+		return err
+	}
+
+	logger := op.logger.WithContext(ctx).WithFields(map[string]interface{}{"cluster": clusterID, "integrated service": integratedServiceName})
+
+	boundSpec, err := bindIntegratedServiceSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	if err := boundSpec.Validate(); err != nil {
+		return integratedservices.InvalidIntegratedServiceSpecError{
+			IntegratedServiceName: integratedServiceName,
+			Problem:               err.Error(),
+		}
+	}
+
+	orgID, ok := auth.GetCurrentOrganizationID(ctx)
+	if !ok {
+		return errors.New("organization ID missing from context")
+	}
+
+	if err := op.installOrUpdateGatekeeper(ctx, clusterID); err != nil {
+		return errors.WrapIf(err, "failed to deploy helm chart for integrated service")
+	}
+
+	logger.Info("gatekeeper deployed")
+
+	kinds := make(map[string]string)
+
+	for _, name := range boundSpec.templates() {
+		template, err := op.templates.GetTemplate(ctx, orgID, name)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to get policy template", "template", name)
+		}
+
+		if err := op.applyObject(ctx, clusterID, constraintTemplate(template)); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to apply constraint template", "template", name)
+		}
+
+		kinds[name] = template.Kind
+	}
+
+	for _, constraintSpec := range boundSpec.Constraints {
+		o := constraint(constraintSpec, kinds[constraintSpec.Template])
+
+		err := backoff.Retry(func() error {
+			return op.applyObject(ctx, clusterID, o.DeepCopy())
+		}, backoff.NewConstantBackoffPolicy(op.backoffConfig))
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to apply constraint", "constraint", constraintSpec.Name)
+		}
+	}
+
+	logger.Info("policy constraints applied", map[string]interface{}{"constraints": len(boundSpec.Constraints)})
+
+	return op.removeStaleObjects(ctx, clusterID, boundSpec)
+}
+
+// Deactivate removes the constraints and templates created by Pipeline and uninstalls Gatekeeper
+func (op IntegratedServiceOperator) Deactivate(ctx context.Context, clusterID uint, spec integratedservices.IntegratedServiceSpec) error {
+	ctx, err := op.ensureOrgIDInContext(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := op.clusterService.CheckClusterReady(ctx, clusterID); err != nil {
+		return err
+	}
+
+	// constraints are removed by Gatekeeper together with the CRDs of their templates
+	if err := op.removeStaleObjects(ctx, clusterID, policyIntegratedServiceSpec{}); err != nil {
+		return err
+	}
+
+	if err := op.helmService.DeleteDeployment(ctx, clusterID, gatekeeperReleaseName, op.config.Namespace); err != nil {
+		return errors.WrapIf(err, "failed to uninstall integrated service")
+	}
+
+	return nil
+}
+
+func (op IntegratedServiceOperator) installOrUpdateGatekeeper(ctx context.Context, clusterID uint) error {
+	values := op.config.Charts.Gatekeeper.Values
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	valuesBytes, err := json.Marshal(values)
+	if err != nil {
+		return errors.WrapIf(err, "failed to marshal chart values")
+	}
+
+	return op.helmService.ApplyDeployment(
+		ctx,
+		clusterID,
+		op.config.Namespace,
+		op.config.Charts.Gatekeeper.Chart,
+		gatekeeperReleaseName,
+		valuesBytes,
+		op.config.Charts.Gatekeeper.Version,
+	)
+}
+
+// applyObject creates or updates a cluster scoped object.
+func (op IntegratedServiceOperator) applyObject(ctx context.Context, clusterID uint, o *unstructured.Unstructured) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(o.GroupVersionKind())
+
+	err := op.kubernetesService.GetObject(ctx, clusterID, corev1.ObjectReference{Name: o.GetName()}, current)
+	if k8sapierrors.IsNotFound(err) {
+		return op.kubernetesService.EnsureObject(ctx, clusterID, o)
+	} else if err != nil {
+		return errors.WrapIf(err, "failed to get object")
+	}
+
+	o.SetResourceVersion(current.GetResourceVersion())
+
+	return op.kubernetesService.Update(ctx, clusterID, o)
+}
+
+// removeStaleObjects deletes the constraints and templates created by Pipeline that are not in the specification.
+func (op IntegratedServiceOperator) removeStaleObjects(ctx context.Context, clusterID uint, spec policyIntegratedServiceSpec) error {
+	templates := newConstraintTemplateListObject()
+	if err := op.kubernetesService.List(ctx, clusterID, managedLabels(), templates); err != nil {
+		return errors.WrapIf(err, "failed to list constraint templates")
+	}
+
+	usedTemplates := make(map[string]bool)
+	for _, name := range spec.templates() {
+		usedTemplates[name] = true
+	}
+
+	constraints := make(map[string]bool)
+	for _, constraintSpec := range spec.Constraints {
+		constraints[constraintSpec.Name] = true
+	}
+
+	for i := range templates.Items {
+		template := &templates.Items[i]
+
+		if !usedTemplates[template.GetName()] {
+			if err := op.kubernetesService.DeleteObject(ctx, clusterID, template); err != nil {
+				return errors.WrapIfWithDetails(err, "failed to delete constraint template", "template", template.GetName())
+			}
+
+			continue
+		}
+
+		list := newConstraintListObject(constraintTemplateKindOf(template))
+		if err := op.kubernetesService.List(ctx, clusterID, managedLabels(), list); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to list constraints", "template", template.GetName())
+		}
+
+		for j := range list.Items {
+			if !constraints[list.Items[j].GetName()] {
+				if err := op.kubernetesService.DeleteObject(ctx, clusterID, &list.Items[j]); err != nil {
+					return errors.WrapIfWithDetails(err, "failed to delete constraint", "constraint", list.Items[j].GetName())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (op IntegratedServiceOperator) ensureOrgIDInContext(ctx context.Context, clusterID uint) (context.Context, error) {
+	if _, ok := auth.GetCurrentOrganizationID(ctx); !ok {
+		cluster, err := op.clusterGetter.GetClusterByIDOnly(ctx, clusterID)
+		if err != nil {
+			return ctx, errors.WrapIf(err, "failed to get cluster by ID")
+		}
+		ctx = auth.SetCurrentOrganizationID(ctx, cluster.GetOrganizationId())
+	}
+	return ctx, nil
+}
@@ -0,0 +1,171 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/internal/policy"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestIntegratedServiceOperator_Name(t *testing.T) {
+	op := MakeIntegratedServiceOperator(nil, nil, nil, nil, nil, Config{}, nil)
+
+	assert.Equal(t, "policy", op.Name())
+}
+
+func TestIntegratedServiceOperator_Apply(t *testing.T) {
+	clusterID := uint(42)
+	orgID := uint(13)
+
+	clusterGetter := dummyClusterGetter{
+		Clusters: map[uint]dummyCluster{
+			clusterID: {OrgID: orgID, ID: clusterID, Status: pkgCluster.Running},
+		},
+	}
+	helmService := &dummyHelmService{releases: make(map[string]string)}
+	kubernetesService := newInMemoryKubernetesService()
+
+	templates := inMemoryTemplateGetter{}
+	for _, template := range policy.BuiltInTemplates() {
+		templates[template.Name] = template
+	}
+
+	config := Config{
+		Namespace: "gatekeeper-system",
+		Charts: ChartsConfig{
+			Gatekeeper: ChartConfig{Chart: "gatekeeper/gatekeeper", Version: "3.1.0"},
+		},
+	}
+
+	op := MakeIntegratedServiceOperator(
+		clusterGetter,
+		integratedserviceadapter.NewClusterService(clusterGetter),
+		helmService,
+		kubernetesService,
+		templates,
+		config,
+		services.NoopLogger{},
+	)
+
+	ctx := context.Background()
+
+	spec := integratedservices.IntegratedServiceSpec{
+		"constraints": []obj{
+			{
+				"name":       "must-have-team",
+				"template":   "k8srequiredlabels",
+				"parameters": obj{"labels": []interface{}{"team"}},
+				"match": obj{
+					"kinds":              []obj{{"kinds": []interface{}{"Namespace"}}},
+					"excludedNamespaces": []interface{}{"kube-system"},
+				},
+			},
+			{
+				"name":              "no-privileged",
+				"template":          "k8spspprivilegedcontainer",
+				"enforcementAction": "dryrun",
+			},
+		},
+	}
+
+	err := op.Apply(ctx, clusterID, spec)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gatekeeper/gatekeeper", helmService.releases[gatekeeperReleaseName])
+
+	template := kubernetesService.objects[objectKey(constraintTemplateKind, "k8srequiredlabels")]
+	require.NotNil(t, template)
+	assert.Equal(t, "K8sRequiredLabels", constraintTemplateKindOf(template))
+
+	requiredLabels := kubernetesService.objects[objectKey("K8sRequiredLabels", "must-have-team")]
+	require.NotNil(t, requiredLabels)
+
+	enforcementAction, _, _ := unstructured.NestedString(requiredLabels.Object, "spec", "enforcementAction")
+	assert.Equal(t, "deny", enforcementAction)
+
+	labels, _, _ := unstructured.NestedStringSlice(requiredLabels.Object, "spec", "parameters", "labels")
+	assert.Equal(t, []string{"team"}, labels)
+
+	excludedNamespaces, _, _ := unstructured.NestedStringSlice(requiredLabels.Object, "spec", "match", "excludedNamespaces")
+	assert.Equal(t, []string{"kube-system"}, excludedNamespaces)
+
+	privileged := kubernetesService.objects[objectKey("K8sPSPPrivilegedContainer", "no-privileged")]
+	require.NotNil(t, privileged)
+
+	enforcementAction, _, _ = unstructured.NestedString(privileged.Object, "spec", "enforcementAction")
+	assert.Equal(t, "dryrun", enforcementAction)
+
+	// removing a constraint removes its unused template as well
+	spec = integratedservices.IntegratedServiceSpec{
+		"constraints": []obj{
+			{
+				"name":       "must-have-owner",
+				"template":   "k8srequiredlabels",
+				"parameters": obj{"labels": []interface{}{"owner"}},
+			},
+		},
+	}
+
+	err = op.Apply(ctx, clusterID, spec)
+	require.NoError(t, err)
+
+	assert.Contains(t, kubernetesService.objects, objectKey("K8sRequiredLabels", "must-have-owner"))
+	assert.NotContains(t, kubernetesService.objects, objectKey("K8sRequiredLabels", "must-have-team"))
+	assert.NotContains(t, kubernetesService.objects, objectKey(constraintTemplateKind, "k8spspprivilegedcontainer"))
+
+	err = op.Deactivate(ctx, clusterID, spec)
+	require.NoError(t, err)
+
+	assert.NotContains(t, kubernetesService.objects, objectKey(constraintTemplateKind, "k8srequiredlabels"))
+	assert.NotContains(t, helmService.releases, gatekeeperReleaseName)
+}
+
+func TestIntegratedServiceOperator_Apply_UnknownTemplate(t *testing.T) {
+	clusterID := uint(42)
+
+	clusterGetter := dummyClusterGetter{
+		Clusters: map[uint]dummyCluster{
+			clusterID: {OrgID: 13, ID: clusterID, Status: pkgCluster.Running},
+		},
+	}
+
+	op := MakeIntegratedServiceOperator(
+		clusterGetter,
+		integratedserviceadapter.NewClusterService(clusterGetter),
+		&dummyHelmService{releases: make(map[string]string)},
+		newInMemoryKubernetesService(),
+		inMemoryTemplateGetter{},
+		Config{},
+		services.NoopLogger{},
+	)
+
+	spec := integratedservices.IntegratedServiceSpec{
+		"constraints": []obj{{"name": "policy", "template": "k8sunknown"}},
+	}
+
+	err := op.Apply(context.Background(), clusterID, spec)
+	require.Error(t, err)
+	assert.True(t, policy.IsTemplateNotFoundError(err))
+}
@@ -0,0 +1,112 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"emperror.dev/errors"
+	"github.com/mitchellh/mapstructure"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+)
+
+type policyIntegratedServiceSpec struct {
+	Constraints []constraintSpec `json:"constraints" mapstructure:"constraints"`
+}
+
+type constraintSpec struct {
+	Name              string                 `json:"name" mapstructure:"name"`
+	Template          string                 `json:"template" mapstructure:"template"`
+	EnforcementAction string                 `json:"enforcementAction" mapstructure:"enforcementAction"`
+	Match             matchSpec              `json:"match" mapstructure:"match"`
+	Parameters        map[string]interface{} `json:"parameters" mapstructure:"parameters"`
+}
+
+type matchSpec struct {
+	Kinds              []kindsSpec `json:"kinds" mapstructure:"kinds"`
+	Namespaces         []string    `json:"namespaces" mapstructure:"namespaces"`
+	ExcludedNamespaces []string    `json:"excludedNamespaces" mapstructure:"excludedNamespaces"`
+}
+
+type kindsSpec struct {
+	APIGroups []string `json:"apiGroups" mapstructure:"apiGroups"`
+	Kinds     []string `json:"kinds" mapstructure:"kinds"`
+}
+
+func bindIntegratedServiceSpec(spec integratedservices.IntegratedServiceSpec) (policyIntegratedServiceSpec, error) {
+	var integratedServiceSpec policyIntegratedServiceSpec
+	if err := mapstructure.Decode(spec, &integratedServiceSpec); err != nil {
+		return integratedServiceSpec, integratedservices.InvalidIntegratedServiceSpecError{
+			IntegratedServiceName: integratedServiceName,
+			Problem:               "failed to bind integrated service spec",
+		}
+	}
+
+	return integratedServiceSpec, nil
+}
+
+func (s policyIntegratedServiceSpec) Validate() error {
+	names := make(map[string]bool, len(s.Constraints))
+
+	for _, constraint := range s.Constraints {
+		if errs := validation.IsDNS1123Subdomain(constraint.Name); len(errs) > 0 {
+			return errors.Errorf("invalid constraint name %q", constraint.Name)
+		}
+
+		if names[constraint.Name] {
+			return errors.Errorf("duplicate constraint name %q", constraint.Name)
+		}
+		names[constraint.Name] = true
+
+		if constraint.Template == "" {
+			return errors.Errorf("template of constraint %q is required", constraint.Name)
+		}
+
+		switch constraint.EnforcementAction {
+		case "", enforcementActionDeny, enforcementActionDryRun:
+		default:
+			return errors.Errorf(
+				"enforcement action of constraint %q must be %s or %s",
+				constraint.Name, enforcementActionDeny, enforcementActionDryRun,
+			)
+		}
+	}
+
+	return nil
+}
+
+// templates returns the names of the templates used by the constraints.
+func (s policyIntegratedServiceSpec) templates() []string {
+	var templates []string
+
+	seen := make(map[string]bool)
+
+	for _, constraint := range s.Constraints {
+		if !seen[constraint.Template] {
+			seen[constraint.Template] = true
+			templates = append(templates, constraint.Template)
+		}
+	}
+
+	return templates
+}
+
+func (s constraintSpec) enforcementAction() string {
+	if s.EnforcementAction == "" {
+		return enforcementActionDeny
+	}
+
+	return s.EnforcementAction
+}
@@ -0,0 +1,94 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+)
+
+func TestPolicyIntegratedServiceSpec_Validate(t *testing.T) {
+	tests := map[string]struct {
+		spec  integratedservices.IntegratedServiceSpec
+		error string
+	}{
+		"valid": {
+			spec: integratedservices.IntegratedServiceSpec{
+				"constraints": []obj{
+					{
+						"name":              "must-have-team",
+						"template":          "k8srequiredlabels",
+						"enforcementAction": "dryrun",
+						"parameters":        obj{"labels": []interface{}{"team"}},
+					},
+					{
+						"name":     "no-privileged",
+						"template": "k8spspprivilegedcontainer",
+					},
+				},
+			},
+		},
+		"empty": {
+			spec: integratedservices.IntegratedServiceSpec{},
+		},
+		"invalid name": {
+			spec: integratedservices.IntegratedServiceSpec{
+				"constraints": []obj{{"name": "Must_Have_Team", "template": "k8srequiredlabels"}},
+			},
+			error: "invalid constraint name",
+		},
+		"duplicate name": {
+			spec: integratedservices.IntegratedServiceSpec{
+				"constraints": []obj{
+					{"name": "policy", "template": "k8srequiredlabels"},
+					{"name": "policy", "template": "k8sallowedrepos"},
+				},
+			},
+			error: "duplicate constraint name",
+		},
+		"missing template": {
+			spec: integratedservices.IntegratedServiceSpec{
+				"constraints": []obj{{"name": "policy"}},
+			},
+			error: "template of constraint",
+		},
+		"invalid enforcement action": {
+			spec: integratedservices.IntegratedServiceSpec{
+				"constraints": []obj{{"name": "policy", "template": "k8srequiredlabels", "enforcementAction": "warn"}},
+			},
+			error: "enforcement action",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			spec, err := bindIntegratedServiceSpec(test.spec)
+			assert.NoError(t, err)
+
+			err = spec.Validate()
+			if test.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.error)
+			}
+		})
+	}
+}
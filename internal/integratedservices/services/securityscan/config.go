@@ -19,6 +19,7 @@ import (
 	"net/url"
 
 	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
 
 	"github.com/banzaicloud/pipeline/internal/anchore"
 	"github.com/banzaicloud/pipeline/internal/common"
@@ -31,6 +32,7 @@ type Config struct {
 	Anchore           AnchoreConfig
 	PipelineNamespace string
 	Webhook           WebhookConfig
+	Trivy             TrivyConfig
 }
 
 func (c Config) Validate() error {
@@ -175,6 +177,10 @@ func (p CustomAnchoreConfigProvider) GetConfiguration(ctx context.Context, clust
 	}, nil
 }
 
+// TrivyWebhookChartMinVersion is the first image validator chart version that understands the scanner values.
+// Earlier versions ignore them and keep validating images with Anchore.
+const TrivyWebhookChartMinVersion = "0.6.0"
+
 // WebhookConfig encapsulates configuration of the image validator webhook
 // sensitive defaults provided through env vars
 type WebhookConfig struct {
@@ -184,3 +190,30 @@ type WebhookConfig struct {
 	Namespace string
 	Values    map[string]interface{}
 }
+
+// SupportsTrivy checks whether the configured chart version can connect the webhook to Trivy.
+func (c WebhookConfig) SupportsTrivy() error {
+	version, err := semver.NewVersion(c.Version)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "invalid image validator chart version", "version", c.Version)
+	}
+
+	if version.LessThan(semver.MustParse(TrivyWebhookChartMinVersion)) {
+		return errors.Errorf(
+			"the trivy scanner requires image validator chart version %s or later (configured: %s)",
+			TrivyWebhookChartMinVersion,
+			c.Version,
+		)
+	}
+
+	return nil
+}
+
+// TrivyConfig contains the chart configuration of the in-cluster Trivy server.
+type TrivyConfig struct {
+	Chart     string
+	Version   string
+	Release   string
+	Namespace string
+	Values    map[string]interface{}
+}
@@ -57,6 +57,15 @@ func (f IntegratedServiceManager) ValidateSpec(ctx context.Context, spec integra
 		}
 	}
 
+	if securityScanSpec.Scanner.getType() == ScannerTypeTrivy {
+		if err := f.config.Webhook.SupportsTrivy(); err != nil {
+			return integratedservices.InvalidIntegratedServiceSpecError{
+				IntegratedServiceName: IntegratedServiceName,
+				Problem:               err.Error(),
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -73,5 +82,20 @@ func (f IntegratedServiceManager) GetOutput(ctx context.Context, clusterID uint,
 		},
 	}
 
+	boundSpec, err := bindIntegratedServiceSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	out["scanner"] = map[string]interface{}{
+		"type": boundSpec.Scanner.getType(),
+	}
+
+	if boundSpec.Scanner.getType() == ScannerTypeTrivy && boundSpec.Scanner.Trivy.URL == "" {
+		out["trivy"] = map[string]interface{}{
+			"version": f.config.Trivy.Version,
+		}
+	}
+
 	return out, nil
 }
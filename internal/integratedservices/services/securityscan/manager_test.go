@@ -75,11 +75,28 @@ func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
 				return false
 			},
 		},
+		{
+			name: "trivy scanner with an in-cluster server",
+			spec: integratedservices.IntegratedServiceSpec{
+				"scanner": obj{
+					"type": "trivy",
+					"trivy": obj{
+						"offlineDb":  true,
+						"severities": []string{"HIGH", "CRITICAL"},
+					},
+				},
+				"webhookConfig": obj{
+					"enabled":    true,
+					"selector":   "include",
+					"namespaces": []string{"default"},
+				},
+			},
+		},
 		// todo add more test fixtures here
 	}
 
 	ctx := context.Background()
-	integratedServiceManager := MakeIntegratedServiceManager(nil, Config{})
+	integratedServiceManager := MakeIntegratedServiceManager(nil, Config{Webhook: WebhookConfig{Version: TrivyWebhookChartMinVersion}})
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			err := integratedServiceManager.ValidateSpec(ctx, test.spec)
@@ -89,3 +106,36 @@ func TestIntegratedServiceManager_ValidateSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegratedServiceManager_ValidateSpec_TrivyWebhookChartVersion(t *testing.T) {
+	spec := integratedservices.IntegratedServiceSpec{
+		"scanner": obj{
+			"type": "trivy",
+		},
+	}
+
+	tests := []struct {
+		version string
+		valid   bool
+	}{
+		{version: "0.5.8", valid: false},
+		{version: "", valid: false},
+		{version: TrivyWebhookChartMinVersion, valid: true},
+		{version: "0.7.1", valid: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.version, func(t *testing.T) {
+			integratedServiceManager := MakeIntegratedServiceManager(nil, Config{Webhook: WebhookConfig{Version: test.version}})
+
+			err := integratedServiceManager.ValidateSpec(context.Background(), spec)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, integratedservices.IsInputValidationError(err), "expected a validation error, got: %v", err)
+			}
+		})
+	}
+}
@@ -19,14 +19,12 @@ import (
 	"encoding/json"
 
 	"emperror.dev/errors"
-	"github.com/mitchellh/mapstructure"
 
 	"github.com/banzaicloud/pipeline/internal/common"
 	"github.com/banzaicloud/pipeline/internal/integratedservices"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
 	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
 	"github.com/banzaicloud/pipeline/src/auth"
-	"github.com/banzaicloud/pipeline/src/secret"
 )
 
 const (
@@ -100,22 +98,19 @@ func (op IntegratedServiceOperator) Apply(ctx context.Context, clusterID uint, s
 		return errors.WrapIf(err, "failed to apply integrated service")
 	}
 
-	var anchoreValues AnchoreValues
-	if boundSpec.CustomAnchore.Enabled {
-		anchoreValues, err = op.getCustomAnchoreValues(ctx, boundSpec.CustomAnchore)
-		if err != nil {
-			return errors.WrapIf(err, "failed to get default anchore values")
-		}
-	} else {
-		anchoreValues, err = op.getDefaultAnchoreValues(ctx, clusterID)
-		if err != nil {
-			return errors.WrapIf(err, "failed to get default anchore values")
-		}
+	scanner, err := op.getScanner(boundSpec.Scanner)
+	if err != nil {
+		return errors.WrapIf(err, "failed to apply integrated service")
+	}
+
+	chartValues := boundSpec.WebhookConfig.GetValues()
+	if err := scanner.Apply(ctx, clusterID, boundSpec, &chartValues); err != nil {
+		return errors.WrapIf(err, "failed to set up scanner")
 	}
 
-	values, err := assembleChartValues(anchoreValues, boundSpec.WebhookConfig)
+	values, err := json.Marshal(chartValues)
 	if err != nil {
-		return errors.WrapIf(err, "failed to assemble chart values")
+		return errors.WrapIf(err, "failed to marshal chart values")
 	}
 
 	if err = op.helmService.ApplyDeployment(ctx, clusterID, op.config.Webhook.Namespace, op.config.Webhook.Chart, op.config.Webhook.Release,
@@ -148,11 +143,6 @@ func (op IntegratedServiceOperator) Deactivate(ctx context.Context, clusterID ui
 		return errors.WrapIf(err, "failed to deactivate integrated service")
 	}
 
-	cl, err := op.clusterGetter.GetClusterByIDOnly(ctx, clusterID)
-	if err != nil {
-		return errors.WrapIf(err, "failed to get cluster by ID")
-	}
-
 	boundSpec, err := bindIntegratedServiceSpec(spec)
 	if err != nil {
 		op.logger.Debug("failed to bind the spec")
@@ -165,20 +155,19 @@ func (op IntegratedServiceOperator) Deactivate(ctx context.Context, clusterID ui
 			"clusterID", clusterID)
 	}
 
+	scanner, err := op.getScanner(boundSpec.Scanner)
+	if err != nil {
+		return errors.WrapIf(err, "failed to deactivate integrated service")
+	}
+
+	if err := scanner.Deactivate(ctx, clusterID, boundSpec); err != nil {
+		return errors.WrapIf(err, "failed to deactivate scanner")
+	}
+
 	if err := op.namespaceService.CleanupLabels(ctx, clusterID, []string{labelKey}); err != nil {
 		// if the operation fails for some reason (eg. non-existent namespaces) we notice that and let the deactivation succeed
 		op.logger.Warn("failed to delete namespace labels", map[string]interface{}{"clusterID": clusterID})
 		op.errorHandler.HandleContext(ctx, err)
-
-		return nil
-	}
-
-	if !boundSpec.CustomAnchore.Enabled {
-		if err = op.anchoreService.DeleteUser(ctx, cl.GetOrganizationId(), clusterID); err != nil {
-			// deactivation succeeds even in case the generated anchore user is not deleted!
-			op.logger.Warn("failed to delete the anchore user generated for the cluster", map[string]interface{}{"clusterID": clusterID})
-			return nil
-		}
 	}
 
 	return nil
@@ -195,82 +184,6 @@ func (op IntegratedServiceOperator) ensureOrgIDInContext(ctx context.Context, cl
 	return ctx, nil
 }
 
-func (op IntegratedServiceOperator) createAnchoreUserForCluster(ctx context.Context, clusterID uint) (string, error) {
-	cl, err := op.clusterGetter.GetClusterByIDOnly(ctx, clusterID)
-	if err != nil {
-		return "", errors.WrapIf(err, "error retrieving cluster")
-	}
-
-	userName, err := op.anchoreService.GenerateUser(ctx, cl.GetOrganizationId(), clusterID)
-	if err != nil {
-		return "", errors.WrapIf(err, "error creating anchore user")
-	}
-
-	return userName, nil
-}
-
-// assembleChartValues is in charge to assemble the values json for the chart based on the input and configuration
-func assembleChartValues(anchoreValues AnchoreValues, webhookConfigSpec webHookConfigSpec) ([]byte, error) {
-	chartValues := webhookConfigSpec.GetValues()
-	chartValues.ExternalAnchore = &anchoreValues
-
-	valuesBytes, err := json.Marshal(chartValues)
-	if err != nil {
-		return nil, errors.WrapIf(err, "failed to marshal chart values")
-	}
-
-	return valuesBytes, nil
-}
-
-func (op IntegratedServiceOperator) getCustomAnchoreValues(ctx context.Context, customAnchore anchoreSpec) (AnchoreValues, error) {
-	if !customAnchore.Enabled { // this is already checked
-		return AnchoreValues{}, errors.NewWithDetails("custom anchore disabled")
-	}
-
-	anchoreUserSecret, err := op.secretStore.GetSecretValues(ctx, customAnchore.SecretID)
-	if err != nil {
-		return AnchoreValues{}, errors.WrapWithDetails(err, "failed to get anchore secret", "secretId", customAnchore.SecretID)
-	}
-
-	var anchoreValues AnchoreValues
-	if err := mapstructure.Decode(anchoreUserSecret, &anchoreValues); err != nil {
-		return AnchoreValues{}, errors.WrapIf(err, "failed to extract anchore secret values")
-	}
-
-	anchoreValues.Host = customAnchore.Url
-	anchoreValues.Insecure = customAnchore.Insecure
-
-	return anchoreValues, nil
-}
-
-func (op IntegratedServiceOperator) getDefaultAnchoreValues(ctx context.Context, clusterID uint) (AnchoreValues, error) {
-	// default (pipeline hosted) anchore
-	if !op.config.Anchore.Enabled {
-		return AnchoreValues{}, errors.NewWithDetails("default anchore is not enabled")
-	}
-
-	secretName, err := op.createAnchoreUserForCluster(ctx, clusterID)
-	if err != nil {
-		return AnchoreValues{}, errors.WrapIf(err, "failed to create anchore user")
-	}
-
-	anchoreSecretID := secret.GenerateSecretIDFromName(secretName)
-	anchoreUserSecret, err := op.secretStore.GetSecretValues(ctx, anchoreSecretID)
-	if err != nil {
-		return AnchoreValues{}, errors.WrapWithDetails(err, "failed to get anchore secret", "secretId", anchoreSecretID)
-	}
-
-	var anchoreValues AnchoreValues
-	if err := mapstructure.Decode(anchoreUserSecret, &anchoreValues); err != nil {
-		return AnchoreValues{}, errors.WrapIf(err, "failed to extract anchore secret values")
-	}
-
-	anchoreValues.Host = op.config.Anchore.Endpoint
-	anchoreValues.Insecure = op.config.Anchore.Insecure
-
-	return anchoreValues, nil
-}
-
 // performs namespace labeling based on the provided input
 func (op *IntegratedServiceOperator) applyLabelsForSecurityScan(ctx context.Context, clusterID uint, whConfig webHookConfigSpec) error {
 	// possible label values that are used to make decisions by the webhook
@@ -33,6 +33,9 @@ import (
 	"github.com/banzaicloud/pipeline/pkg/security"
 )
 
+// whitelistItemOwner is the owner of the whitelist items managed by the integrated service
+const whitelistItemOwner = "pipeline"
+
 // IntegratedServiceWhiteListService handles whitelist creation and removal
 type IntegratedServiceWhiteListService interface {
 	// EnsureReleaseWhiteList makes sure that the passed whitelist is applied to the cluster
@@ -78,10 +81,18 @@ func (wls *integratedServiceWhiteListService) EnsureReleaseWhiteList(ctx context
 
 	var toBeAdded []releaseSpec
 
+	// changes made by the integrated service are recorded on behalf of pipeline
+	ctx = anchore.WithWhitelistActor(ctx, whitelistItemOwner)
+
 	// find items to be installed
 	for _, releaseItem := range items {
 		installed, ok := installedItemsMap[releaseItem.Name]
 		if !ok {
+			if expiresAt, _ := releaseItem.expiresAt(); expiresAt != nil && !expiresAt.After(time.Now()) {
+				// expired items are not reinstalled
+				continue
+			}
+
 			// the release is not installed
 			toBeAdded = append(toBeAdded, releaseItem)
 			continue
@@ -120,11 +131,18 @@ func (wls *integratedServiceWhiteListService) removeItems(ctx context.Context, c
 func (wls *integratedServiceWhiteListService) installItems(ctx context.Context, cluster integratedserviceadapter.Cluster, items []releaseSpec) error {
 	var collectedErrors error
 	for _, item := range items {
+		expiresAt, err := item.expiresAt()
+		if err != nil {
+			collectedErrors = errors.Append(collectedErrors, errors.WrapIff(err, "invalid expiry of whitelist item %s", item.Name))
+			continue
+		}
+
 		wlItem := security.ReleaseWhiteListItem{
-			Name:   item.Name,
-			Owner:  "pipeline",
-			Reason: item.Reason,
-			Regexp: item.Regexp,
+			Name:      item.Name,
+			Owner:     whitelistItemOwner,
+			Reason:    item.Reason,
+			Regexp:    item.Regexp,
+			ExpiresAt: expiresAt,
 		}
 
 		if _, err := wls.whiteListService.CreateWhitelist(ctx, cluster, wlItem); err != nil {
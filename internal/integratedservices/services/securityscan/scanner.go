@@ -0,0 +1,275 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package securityscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/mitchellh/copystructure"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/integratedserviceadapter"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+// Vulnerability scanner types.
+const (
+	ScannerTypeAnchore = "anchore"
+	ScannerTypeTrivy   = "trivy"
+)
+
+const (
+	trivyServerPort     = 4954
+	trivyTokenSecretKey = "token"
+)
+
+// scanner is the vulnerability scanner backend the image validator webhook of a cluster relies on
+type scanner interface {
+	// Apply makes sure the scanner is available for the cluster and sets the webhook chart values connecting to it
+	Apply(ctx context.Context, clusterID uint, spec integratedServiceSpec, values *ImageValidatorChartValues) error
+
+	// Deactivate removes the resources created for the cluster by Apply
+	Deactivate(ctx context.Context, clusterID uint, spec integratedServiceSpec) error
+}
+
+func (op IntegratedServiceOperator) getScanner(spec scannerSpec) (scanner, error) {
+	switch spec.getType() {
+	case ScannerTypeAnchore:
+		return anchoreScanner{
+			config:         op.config.Anchore,
+			clusterGetter:  op.clusterGetter,
+			secretStore:    op.secretStore,
+			anchoreService: op.anchoreService,
+			logger:         op.logger,
+		}, nil
+	case ScannerTypeTrivy:
+		return trivyScanner{
+			config:        op.config.Trivy,
+			webhookConfig: op.config.Webhook,
+			helmService:   op.helmService,
+			secretStore:   op.secretStore,
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported scanner type %q", spec.Type)
+	}
+}
+
+// anchoreScanner connects the webhook to the Pipeline hosted or a custom Anchore Engine
+type anchoreScanner struct {
+	config         AnchoreConfig
+	clusterGetter  integratedserviceadapter.ClusterGetter
+	secretStore    services.SecretStore
+	anchoreService IntegratedServiceAnchoreService
+	logger         common.Logger
+}
+
+func (s anchoreScanner) Apply(ctx context.Context, clusterID uint, spec integratedServiceSpec, values *ImageValidatorChartValues) error {
+	var anchoreValues AnchoreValues
+	var err error
+	if spec.CustomAnchore.Enabled {
+		anchoreValues, err = s.getCustomAnchoreValues(ctx, spec.CustomAnchore)
+		if err != nil {
+			return errors.WrapIf(err, "failed to get custom anchore values")
+		}
+	} else {
+		anchoreValues, err = s.getDefaultAnchoreValues(ctx, clusterID)
+		if err != nil {
+			return errors.WrapIf(err, "failed to get default anchore values")
+		}
+	}
+
+	values.ExternalAnchore = &anchoreValues
+
+	return nil
+}
+
+func (s anchoreScanner) Deactivate(ctx context.Context, clusterID uint, spec integratedServiceSpec) error {
+	if spec.CustomAnchore.Enabled {
+		return nil
+	}
+
+	cl, err := s.clusterGetter.GetClusterByIDOnly(ctx, clusterID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get cluster by ID")
+	}
+
+	if err = s.anchoreService.DeleteUser(ctx, cl.GetOrganizationId(), clusterID); err != nil {
+		// deactivation succeeds even in case the generated anchore user is not deleted!
+		s.logger.Warn("failed to delete the anchore user generated for the cluster", map[string]interface{}{"clusterID": clusterID})
+	}
+
+	return nil
+}
+
+func (s anchoreScanner) createAnchoreUserForCluster(ctx context.Context, clusterID uint) (string, error) {
+	cl, err := s.clusterGetter.GetClusterByIDOnly(ctx, clusterID)
+	if err != nil {
+		return "", errors.WrapIf(err, "error retrieving cluster")
+	}
+
+	userName, err := s.anchoreService.GenerateUser(ctx, cl.GetOrganizationId(), clusterID)
+	if err != nil {
+		return "", errors.WrapIf(err, "error creating anchore user")
+	}
+
+	return userName, nil
+}
+
+func (s anchoreScanner) getCustomAnchoreValues(ctx context.Context, customAnchore anchoreSpec) (AnchoreValues, error) {
+	if !customAnchore.Enabled { // this is already checked
+		return AnchoreValues{}, errors.NewWithDetails("custom anchore disabled")
+	}
+
+	anchoreUserSecret, err := s.secretStore.GetSecretValues(ctx, customAnchore.SecretID)
+	if err != nil {
+		return AnchoreValues{}, errors.WrapWithDetails(err, "failed to get anchore secret", "secretId", customAnchore.SecretID)
+	}
+
+	var anchoreValues AnchoreValues
+	if err := mapstructure.Decode(anchoreUserSecret, &anchoreValues); err != nil {
+		return AnchoreValues{}, errors.WrapIf(err, "failed to extract anchore secret values")
+	}
+
+	anchoreValues.Host = customAnchore.Url
+	anchoreValues.Insecure = customAnchore.Insecure
+
+	return anchoreValues, nil
+}
+
+func (s anchoreScanner) getDefaultAnchoreValues(ctx context.Context, clusterID uint) (AnchoreValues, error) {
+	// default (pipeline hosted) anchore
+	if !s.config.Enabled {
+		return AnchoreValues{}, errors.NewWithDetails("default anchore is not enabled")
+	}
+
+	secretName, err := s.createAnchoreUserForCluster(ctx, clusterID)
+	if err != nil {
+		return AnchoreValues{}, errors.WrapIf(err, "failed to create anchore user")
+	}
+
+	anchoreSecretID := secret.GenerateSecretIDFromName(secretName)
+	anchoreUserSecret, err := s.secretStore.GetSecretValues(ctx, anchoreSecretID)
+	if err != nil {
+		return AnchoreValues{}, errors.WrapWithDetails(err, "failed to get anchore secret", "secretId", anchoreSecretID)
+	}
+
+	var anchoreValues AnchoreValues
+	if err := mapstructure.Decode(anchoreUserSecret, &anchoreValues); err != nil {
+		return AnchoreValues{}, errors.WrapIf(err, "failed to extract anchore secret values")
+	}
+
+	anchoreValues.Host = s.config.Endpoint
+	anchoreValues.Insecure = s.config.Insecure
+
+	return anchoreValues, nil
+}
+
+// trivyScanner connects the webhook to a Trivy server, either an external one or one deployed to the cluster
+type trivyScanner struct {
+	config        TrivyConfig
+	webhookConfig WebhookConfig
+	helmService   services.HelmService
+	secretStore   services.SecretStore
+}
+
+func (s trivyScanner) Apply(ctx context.Context, clusterID uint, spec integratedServiceSpec, values *ImageValidatorChartValues) error {
+	// older charts silently ignore the scanner values and keep using Anchore
+	if err := s.webhookConfig.SupportsTrivy(); err != nil {
+		return err
+	}
+
+	var trivyValues = TrivyValues{
+		ServerURL:     spec.Scanner.Trivy.URL,
+		Insecure:      spec.Scanner.Trivy.Insecure,
+		Severities:    spec.Scanner.Trivy.getSeverities(),
+		IgnoreUnfixed: spec.Scanner.Trivy.IgnoreUnfixed,
+	}
+
+	if spec.Scanner.Trivy.URL != "" {
+		if spec.Scanner.Trivy.SecretID != "" {
+			trivySecret, err := s.secretStore.GetSecretValues(ctx, spec.Scanner.Trivy.SecretID)
+			if err != nil {
+				return errors.WrapWithDetails(err, "failed to get Trivy secret", "secretId", spec.Scanner.Trivy.SecretID)
+			}
+
+			trivyValues.Token = trivySecret[trivyTokenSecretKey]
+			if trivyValues.Token == "" {
+				return errors.NewWithDetails("Trivy secret must contain a token", "secretId", spec.Scanner.Trivy.SecretID)
+			}
+		}
+	} else {
+		if err := s.installServer(ctx, clusterID, spec.Scanner.Trivy); err != nil {
+			return errors.WrapIf(err, "failed to install Trivy server")
+		}
+
+		trivyValues.ServerURL = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", s.config.Release, s.config.Namespace, trivyServerPort)
+	}
+
+	values.Scanner = &ScannerValues{
+		Type:  ScannerTypeTrivy,
+		Trivy: &trivyValues,
+	}
+
+	return nil
+}
+
+func (s trivyScanner) Deactivate(ctx context.Context, clusterID uint, spec integratedServiceSpec) error {
+	if spec.Scanner.Trivy.URL != "" {
+		return nil
+	}
+
+	if err := s.helmService.DeleteDeployment(ctx, clusterID, s.config.Release, s.config.Namespace); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to uninstall Trivy server", "clusterID", clusterID)
+	}
+
+	return nil
+}
+
+func (s trivyScanner) installServer(ctx context.Context, clusterID uint, spec trivySpec) error {
+	if s.config.Chart == "" {
+		return errors.New("Trivy server deployment is not configured, an external Trivy server URL is required")
+	}
+
+	valuesCopy, err := copystructure.Copy(s.config.Values)
+	if err != nil {
+		return errors.WrapIf(err, "failed to copy Trivy values")
+	}
+
+	values, ok := valuesCopy.(map[string]interface{})
+	if !ok || values == nil {
+		values = make(map[string]interface{})
+	}
+
+	trivyValues, ok := values["trivy"].(map[string]interface{})
+	if !ok {
+		trivyValues = make(map[string]interface{})
+	}
+
+	// an offline server uses the vulnerability DB shipped with its image instead of downloading it
+	trivyValues["skipUpdate"] = spec.OfflineDB
+	values["trivy"] = trivyValues
+
+	valuesBytes, err := json.Marshal(values)
+	if err != nil {
+		return errors.WrapIf(err, "failed to marshal Trivy values")
+	}
+
+	return s.helmService.ApplyDeployment(ctx, clusterID, s.config.Namespace, s.config.Chart, s.config.Release, valuesBytes, s.config.Version)
+}
@@ -0,0 +1,64 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package securityscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrivyScanner_Apply_UnsupportedWebhookChart(t *testing.T) {
+	scanner := trivyScanner{
+		webhookConfig: WebhookConfig{Version: "0.5.8"},
+	}
+
+	values := ImageValidatorChartValues{}
+
+	err := scanner.Apply(context.Background(), 1, integratedServiceSpec{
+		Scanner: scannerSpec{
+			Type:  ScannerTypeTrivy,
+			Trivy: trivySpec{URL: "https://trivy.example.com:4954"},
+		},
+	}, &values)
+
+	assert.Error(t, err)
+	assert.Nil(t, values.Scanner, "scanner values must not be passed to a chart that ignores them")
+}
+
+func TestTrivyScanner_Apply_ExternalServer(t *testing.T) {
+	scanner := trivyScanner{
+		webhookConfig: WebhookConfig{Version: TrivyWebhookChartMinVersion},
+	}
+
+	values := ImageValidatorChartValues{}
+
+	err := scanner.Apply(context.Background(), 1, integratedServiceSpec{
+		Scanner: scannerSpec{
+			Type:  ScannerTypeTrivy,
+			Trivy: trivySpec{URL: "https://trivy.example.com:4954"},
+		},
+	}, &values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &ScannerValues{
+		Type: ScannerTypeTrivy,
+		Trivy: &TrivyValues{
+			ServerURL:  "https://trivy.example.com:4954",
+			Severities: trivySpec{}.getSeverities(),
+		},
+	}, values.Scanner)
+}
@@ -15,6 +15,9 @@
 package securityscan
 
 import (
+	"net/url"
+	"time"
+
 	"emperror.dev/errors"
 	"github.com/mitchellh/mapstructure"
 
@@ -23,6 +26,7 @@ import (
 
 //integratedServiceSpec security scan cluster integrated service specific specification
 type integratedServiceSpec struct {
+	Scanner          scannerSpec       `json:"scanner" mapstructure:"scanner"`
 	CustomAnchore    anchoreSpec       `json:"customAnchore" mapstructure:"customAnchore"`
 	Policy           policySpec        `json:"policy" mapstructure:"policy"`
 	ReleaseWhiteList []releaseSpec     `json:"releaseWhiteList,omitempty" mapstructure:"releaseWhiteList"`
@@ -33,11 +37,18 @@ type integratedServiceSpec struct {
 func (s integratedServiceSpec) Validate(pipelineNamespace string) error {
 	var validationErrors error
 
+	validationErrors = s.Scanner.Validate()
+
 	if s.CustomAnchore.Enabled {
-		validationErrors = s.CustomAnchore.Validate()
+		if s.Scanner.getType() != ScannerTypeAnchore {
+			validationErrors = errors.Combine(validationErrors, errors.New("customAnchore can only be enabled with the anchore scanner"))
+		}
+
+		validationErrors = errors.Combine(validationErrors, s.CustomAnchore.Validate())
 	}
 
-	if !s.Policy.CustomPolicy.Enabled && s.Policy.PolicyID == "" {
+	// policies are evaluated by Anchore, Trivy relies on the severity settings instead
+	if s.Scanner.getType() == ScannerTypeAnchore && !s.Policy.CustomPolicy.Enabled && s.Policy.PolicyID == "" {
 		validationErrors = errors.Combine(validationErrors, errors.New("policyId is required"))
 	}
 
@@ -50,6 +61,82 @@ func (s integratedServiceSpec) Validate(pipelineNamespace string) error {
 	return validationErrors
 }
 
+type scannerSpec struct {
+	Type  string    `json:"type" mapstructure:"type"`
+	Trivy trivySpec `json:"trivy" mapstructure:"trivy"`
+}
+
+func (s scannerSpec) Validate() error {
+	switch s.getType() {
+	case ScannerTypeAnchore:
+		return nil
+	case ScannerTypeTrivy:
+		return s.Trivy.Validate()
+	default:
+		return errors.Errorf("unsupported scanner type: %q", s.Type)
+	}
+}
+
+// getType returns the scanner type, defaulting to Anchore for backwards compatibility
+func (s scannerSpec) getType() string {
+	if s.Type == "" {
+		return ScannerTypeAnchore
+	}
+
+	return s.Type
+}
+
+type trivySpec struct {
+	URL           string   `json:"url,omitempty" mapstructure:"url"`
+	SecretID      string   `json:"secretId,omitempty" mapstructure:"secretId"`
+	Insecure      bool     `json:"insecure" mapstructure:"insecure"`
+	OfflineDB     bool     `json:"offlineDb" mapstructure:"offlineDb"`
+	Severities    []string `json:"severities,omitempty" mapstructure:"severities"`
+	IgnoreUnfixed bool     `json:"ignoreUnfixed" mapstructure:"ignoreUnfixed"`
+}
+
+func (t trivySpec) Validate() error {
+	var validationErrors error
+
+	if t.URL != "" {
+		if u, err := url.Parse(t.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			validationErrors = errors.Combine(validationErrors, errors.New("trivy url must be a valid URL"))
+		}
+
+		if t.OfflineDB {
+			validationErrors = errors.Combine(validationErrors, errors.New("offlineDb is only supported for the in-cluster trivy server"))
+		}
+	} else if t.SecretID != "" {
+		validationErrors = errors.Combine(validationErrors, errors.New("trivy secretId requires url"))
+	}
+
+	for _, severity := range t.Severities {
+		if !isValidTrivySeverity(severity) {
+			validationErrors = errors.Combine(validationErrors, errors.Errorf("unsupported trivy severity: %q", severity))
+		}
+	}
+
+	return validationErrors
+}
+
+// getSeverities returns the severities blocking admission, defaulting to the ones Anchore policies reject by default
+func (t trivySpec) getSeverities() []string {
+	if len(t.Severities) == 0 {
+		return []string{"HIGH", "CRITICAL"}
+	}
+
+	return t.Severities
+}
+
+func isValidTrivySeverity(severity string) bool {
+	switch severity {
+	case "UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL":
+		return true
+	default:
+		return false
+	}
+}
+
 type anchoreSpec struct {
 	Enabled  bool   `json:"enabled" mapstructure:"enabled"`
 	Url      string `json:"url" mapstructure:"url"`
@@ -78,9 +165,10 @@ type customPolicySpec struct {
 }
 
 type releaseSpec struct {
-	Name   string `json:"name" mapstructure:"name"`
-	Reason string `json:"reason" mapstructure:"reason"`
-	Regexp string `json:"regexp,omitempty" mapstructure:"regexp"`
+	Name      string `json:"name" mapstructure:"name"`
+	Reason    string `json:"reason" mapstructure:"reason"`
+	Regexp    string `json:"regexp,omitempty" mapstructure:"regexp"`
+	ExpiresAt string `json:"expiresAt,omitempty" mapstructure:"expiresAt"`
 }
 
 func (r releaseSpec) Validate() error {
@@ -88,9 +176,27 @@ func (r releaseSpec) Validate() error {
 		return errors.NewPlain("both name and reason must be specified")
 	}
 
+	if _, err := r.expiresAt(); err != nil {
+		return errors.Errorf("expiresAt of %s must be an RFC 3339 timestamp", r.Name)
+	}
+
 	return nil
 }
 
+// expiresAt returns the parsed expiry of the whitelist item, nil if it never expires
+func (r releaseSpec) expiresAt() (*time.Time, error) {
+	if r.ExpiresAt == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, r.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expiresAt, nil
+}
+
 type webHookConfigSpec struct {
 	Enabled    bool     `json:"enabled" mapstructure:"enabled"`
 	Selector   string   `json:"selector" mapstructure:"selector"`
@@ -128,6 +234,16 @@ func bindIntegratedServiceSpec(spec integratedservices.IntegratedServiceSpec) (i
 	return boundSpec, nil
 }
 
+// ScannerType returns the type of the vulnerability scanner selected by a security scan spec.
+func ScannerType(spec integratedservices.IntegratedServiceSpec) (string, error) {
+	boundSpec, err := bindIntegratedServiceSpec(spec)
+	if err != nil {
+		return "", err
+	}
+
+	return boundSpec.Scanner.getType(), nil
+}
+
 func (w webHookConfigSpec) GetValues() ImageValidatorChartValues {
 	var (
 		namespaceSelector *SetBasedSelector
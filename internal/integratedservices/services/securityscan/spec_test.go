@@ -120,3 +120,131 @@ func TestMarshalValues(t *testing.T) {
 		})
 	}
 }
+
+func Test_integratedServiceSpec_Validate_scanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    integratedServiceSpec
+		wantErr bool
+	}{
+		{
+			name: "anchore is the default scanner",
+			spec: integratedServiceSpec{
+				Policy: policySpec{PolicyID: "policy"},
+			},
+		},
+		{
+			name:    "policy is required for anchore",
+			spec:    integratedServiceSpec{},
+			wantErr: true,
+		},
+		{
+			name: "trivy does not require a policy",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{Type: ScannerTypeTrivy},
+			},
+		},
+		{
+			name: "external trivy server",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{
+					Type: ScannerTypeTrivy,
+					Trivy: trivySpec{
+						URL:      "https://trivy.example.com:4954",
+						SecretID: "secret",
+					},
+				},
+			},
+		},
+		{
+			name: "unknown scanner",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{Type: "clair"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom anchore with trivy",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{Type: ScannerTypeTrivy},
+				CustomAnchore: anchoreSpec{
+					Enabled:  true,
+					Url:      "https://anchore.example.com",
+					SecretID: "secret",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "trivy secret without url",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{
+					Type:  ScannerTypeTrivy,
+					Trivy: trivySpec{SecretID: "secret"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "offline DB with an external trivy server",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{
+					Type:  ScannerTypeTrivy,
+					Trivy: trivySpec{URL: "https://trivy.example.com", OfflineDB: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid trivy severity",
+			spec: integratedServiceSpec{
+				Scanner: scannerSpec{
+					Type:  ScannerTypeTrivy,
+					Trivy: trivySpec{Severities: []string{"high"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate("pipeline-system"); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_releaseSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    releaseSpec
+		wantErr bool
+	}{
+		{
+			name: "never expires",
+			spec: releaseSpec{Name: "release", Reason: "reason"},
+		},
+		{
+			name: "expires",
+			spec: releaseSpec{Name: "release", Reason: "reason", ExpiresAt: "2020-06-01T00:00:00Z"},
+		},
+		{
+			name:    "invalid expiry",
+			spec:    releaseSpec{Name: "release", Reason: "reason", ExpiresAt: "2020-06-01"},
+			wantErr: true,
+		},
+		{
+			name:    "missing reason",
+			spec:    releaseSpec{Name: "release"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -19,6 +19,22 @@ type ImageValidatorChartValues struct {
 	ExternalAnchore   *AnchoreValues    `json:"externalAnchore,omitempty" mapstructure:"externalAnchore"`
 	NamespaceSelector *SetBasedSelector `json:"namespaceSelector,omitempty" mapstructure:"namespaceSelector"`
 	ObjectSelector    *SetBasedSelector `json:"objectSelector,omitempty" mapstructure:"objectSelector"`
+	Scanner           *ScannerValues    `json:"scanner,omitempty" mapstructure:"scanner"`
+}
+
+// ScannerValues selects the scanner backend of the image validator webhook; Anchore is used when omitted
+type ScannerValues struct {
+	Type  string       `json:"type" mapstructure:"type"`
+	Trivy *TrivyValues `json:"trivy,omitempty" mapstructure:"trivy"`
+}
+
+// TrivyValues struct used to build the chart values connecting the webhook to a Trivy server
+type TrivyValues struct {
+	ServerURL     string   `json:"serverUrl" mapstructure:"serverUrl"`
+	Token         string   `json:"token,omitempty" mapstructure:"token"`
+	Insecure      bool     `json:"insecureSkipVerify" mapstructure:"insecure"`
+	Severities    []string `json:"severities,omitempty" mapstructure:"severities"`
+	IgnoreUnfixed bool     `json:"ignoreUnfixed" mapstructure:"ignoreUnfixed"`
 }
 
 // AnchoreValues struct used to build chart values and to extract anchore data from secret values
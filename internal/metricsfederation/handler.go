@@ -0,0 +1,193 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"emperror.dev/emperror"
+	"emperror.dev/errors"
+	"github.com/gofrs/uuid"
+	"github.com/mitchellh/mapstructure"
+	"github.com/sirupsen/logrus"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup/api"
+)
+
+const FeatureName = "metrics"
+
+type Handler struct {
+	monitoringNamespace string
+	logger              logrus.FieldLogger
+	errorHandler        emperror.Handler
+	staticConfig        StaticConfig
+	helmService         HelmService
+}
+
+// NewHandler returns a new Handler instance.
+func NewHandler(
+	monitoringNamespace string,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+	staticConfig StaticConfig,
+	helmService HelmService,
+) *Handler {
+	return &Handler{
+		monitoringNamespace: monitoringNamespace,
+		logger:              logger.WithField("feature", FeatureName),
+		errorHandler:        errorHandler,
+		staticConfig:        staticConfig,
+		helmService:         helmService,
+	}
+}
+
+func (h *Handler) ReconcileState(featureState api.Feature) error {
+	cid, err := uuid.NewV4()
+	if err != nil {
+		return errors.WrapIf(err, "could not generate uuid")
+	}
+	logger := h.logger.WithFields(logrus.Fields{
+		"correlationID":    cid,
+		"clusterGroupID":   featureState.ClusterGroup.Id,
+		"clusterGroupName": featureState.ClusterGroup.Name,
+		"enabled":          featureState.Enabled,
+	})
+
+	logger.Info("start reconciling metrics federation state")
+	defer logger.Info("finished reconciling metrics federation state")
+
+	config, err := h.getConfigFromState(featureState)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	reconciler, err := NewReconciler(*config, h.monitoringNamespace, logger, h.helmService)
+	if err != nil {
+		return err
+	}
+
+	err = reconciler.Reconcile()
+	if err != nil {
+		h.errorHandler.Handle(err)
+		return errors.WrapIf(err, "could not reconcile metrics federation")
+	}
+
+	return nil
+}
+
+func (h *Handler) ValidateState(featureState api.Feature) error {
+	var config Config
+	err := mapstructure.Decode(featureState.Properties, &config)
+	if err != nil {
+		return errors.WrapIf(err, "could not decode properties into config")
+	}
+
+	if featureState.ClusterGroup.Clusters[config.HostClusterID] == nil {
+		return errors.New("host cluster cannot be removed from the group")
+	}
+
+	return nil
+}
+
+func (h *Handler) ValidateProperties(clusterGroup api.ClusterGroup, currentProperties, properties interface{}) error {
+	var currentConfig Config
+	err := mapstructure.Decode(currentProperties, &currentConfig)
+	if err != nil {
+		return errors.WrapIf(err, "could not decode current properties into config")
+	}
+
+	var config Config
+	err = mapstructure.Decode(properties, &config)
+	if err != nil {
+		return errors.WrapIf(err, "could not decode new properties into config")
+	}
+
+	if config.HostClusterID == 0 {
+		return errors.New("host cluster ID is required")
+	}
+
+	if currentConfig.HostClusterID > 0 && config.HostClusterID != currentConfig.HostClusterID {
+		return errors.New("host cluster ID cannot be changed")
+	}
+
+	hostClusterIsAMember := false
+	for _, member := range clusterGroup.Members {
+		if member.ID == config.HostClusterID {
+			hostClusterIsAMember = true
+		}
+	}
+
+	if !hostClusterIsAMember {
+		return errors.New("the specified host cluster is not a member of the cluster group")
+	}
+
+	if config.ScrapeInterval != "" && !durationRegexp.MatchString(config.ScrapeInterval) {
+		return errors.Errorf("invalid scrape interval %q", config.ScrapeInterval)
+	}
+
+	if config.ClusterLabel != "" && !labelNameRegexp.MatchString(config.ClusterLabel) {
+		return errors.Errorf("invalid cluster label name %q", config.ClusterLabel)
+	}
+
+	for _, match := range config.Match {
+		if match == "" {
+			return errors.New("federation match selector cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) GetMembersStatus(featureState api.Feature) (map[uint]string, error) {
+	config, err := h.getConfigFromState(featureState)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	reconciler, err := NewReconciler(*config, h.monitoringNamespace, h.logger, h.helmService)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciler.GetStatus(), nil
+}
+
+func (h *Handler) getConfigFromState(state api.Feature) (*Config, error) {
+	var config Config
+	err := mapstructure.Decode(state.Properties, &config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not decode properties into config")
+	}
+
+	config.enabled = state.Enabled
+	config.clusterGroup = state.ClusterGroup
+	config.staticConfig = h.staticConfig
+
+	if config.TargetNamespace == "" {
+		config.TargetNamespace = defaultTargetNamespace
+	}
+
+	if config.ClusterLabel == "" {
+		config.ClusterLabel = defaultClusterLabel
+	}
+
+	if config.ScrapeInterval == "" {
+		config.ScrapeInterval = defaultScrapeInterval
+	}
+
+	if len(config.Match) == 0 {
+		config.Match = []string{defaultMatch}
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup/api"
+)
+
+func TestHandler_ValidateProperties(t *testing.T) {
+	handler := NewHandler("pipeline-system", logrus.New(), nil, StaticConfig{}, nil)
+
+	clusterGroup := api.ClusterGroup{
+		Members: []api.Member{{ID: 1}, {ID: 2}},
+	}
+
+	cases := map[string]struct {
+		CurrentProperties interface{}
+		Properties        interface{}
+		Error             bool
+	}{
+		"valid properties": {
+			Properties: map[string]interface{}{"hostClusterID": 1, "scrapeInterval": "30s", "clusterLabel": "cluster_name"},
+		},
+		"missing host cluster": {
+			Properties: map[string]interface{}{},
+			Error:      true,
+		},
+		"host cluster is not a member": {
+			Properties: map[string]interface{}{"hostClusterID": 3},
+			Error:      true,
+		},
+		"host cluster changed": {
+			CurrentProperties: map[string]interface{}{"hostClusterID": 1},
+			Properties:        map[string]interface{}{"hostClusterID": 2},
+			Error:             true,
+		},
+		"invalid scrape interval": {
+			Properties: map[string]interface{}{"hostClusterID": 1, "scrapeInterval": "1 minute"},
+			Error:      true,
+		},
+		"invalid cluster label": {
+			Properties: map[string]interface{}{"hostClusterID": 1, "clusterLabel": "cluster-name"},
+			Error:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := handler.ValidateProperties(clusterGroup, tc.CurrentProperties, tc.Properties)
+			if tc.Error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// memberAccessName is the name of the service account, role and role binding created on the members
+	memberAccessName = "metrics-federation"
+	// memberTokenSecretName is the name of the token secret of the federation service account on the members
+	memberTokenSecretName = "metrics-federation-token"
+
+	memberTokenPollInterval = time.Second
+	memberTokenPollTimeout  = 30 * time.Second
+)
+
+// memberAccess manages the service account on a member cluster
+// that is only allowed to proxy requests to the member's Prometheus service.
+type memberAccess struct {
+	client    kubernetes.Interface
+	namespace string
+
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+}
+
+func newMemberAccess(client kubernetes.Interface, namespace string) memberAccess {
+	return memberAccess{
+		client:       client,
+		namespace:    namespace,
+		pollInterval: memberTokenPollInterval,
+		pollTimeout:  memberTokenPollTimeout,
+	}
+}
+
+// Ensure creates the federation service account and its RBAC rules on the member cluster
+// and returns the token of the service account.
+func (a memberAccess) Ensure() ([]byte, error) {
+	if err := a.ensureServiceAccount(); err != nil {
+		return nil, err
+	}
+
+	if err := a.ensureRole(); err != nil {
+		return nil, err
+	}
+
+	if err := a.ensureRoleBinding(); err != nil {
+		return nil, err
+	}
+
+	if err := a.ensureTokenSecret(); err != nil {
+		return nil, err
+	}
+
+	return a.getToken()
+}
+
+// Remove deletes the federation service account and its RBAC rules from the member cluster.
+func (a memberAccess) Remove() error {
+	deleteOptions := &metav1.DeleteOptions{}
+
+	err := a.client.CoreV1().Secrets(a.namespace).Delete(memberTokenSecretName, deleteOptions)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WrapIf(err, "could not remove federation token secret")
+	}
+
+	err = a.client.RbacV1().RoleBindings(a.namespace).Delete(memberAccessName, deleteOptions)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WrapIf(err, "could not remove federation role binding")
+	}
+
+	err = a.client.RbacV1().Roles(a.namespace).Delete(memberAccessName, deleteOptions)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WrapIf(err, "could not remove federation role")
+	}
+
+	err = a.client.CoreV1().ServiceAccounts(a.namespace).Delete(memberAccessName, deleteOptions)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WrapIf(err, "could not remove federation service account")
+	}
+
+	return nil
+}
+
+func (a memberAccess) objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: a.namespace,
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "pipeline",
+			"app.kubernetes.io/name":       memberAccessName,
+		},
+	}
+}
+
+func (a memberAccess) ensureServiceAccount() error {
+	_, err := a.client.CoreV1().ServiceAccounts(a.namespace).Create(&corev1.ServiceAccount{
+		ObjectMeta: a.objectMeta(memberAccessName),
+	})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.WrapIf(err, "could not create federation service account")
+	}
+
+	return nil
+}
+
+func (a memberAccess) ensureRole() error {
+	role := &rbacv1.Role{
+		ObjectMeta: a.objectMeta(memberAccessName),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"services/proxy"},
+				// the proxy subresource is addressed either by the service name or by name:port
+				ResourceNames: []string{prometheusServiceName, prometheusServiceName + ":" + prometheusServicePort},
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+
+	roles := a.client.RbacV1().Roles(a.namespace)
+
+	current, err := roles.Get(memberAccessName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = roles.Create(role)
+		return errors.WrapIf(err, "could not create federation role")
+	}
+	if err != nil {
+		return errors.WrapIf(err, "could not get federation role")
+	}
+
+	role.ResourceVersion = current.ResourceVersion
+	_, err = roles.Update(role)
+
+	return errors.WrapIf(err, "could not update federation role")
+}
+
+func (a memberAccess) ensureRoleBinding() error {
+	_, err := a.client.RbacV1().RoleBindings(a.namespace).Create(&rbacv1.RoleBinding{
+		ObjectMeta: a.objectMeta(memberAccessName),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     memberAccessName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      memberAccessName,
+				Namespace: a.namespace,
+			},
+		},
+	})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.WrapIf(err, "could not create federation role binding")
+	}
+
+	return nil
+}
+
+func (a memberAccess) ensureTokenSecret() error {
+	objectMeta := a.objectMeta(memberTokenSecretName)
+	objectMeta.Annotations = map[string]string{
+		corev1.ServiceAccountNameKey: memberAccessName,
+	}
+
+	_, err := a.client.CoreV1().Secrets(a.namespace).Create(&corev1.Secret{
+		ObjectMeta: objectMeta,
+		Type:       corev1.SecretTypeServiceAccountToken,
+	})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.WrapIf(err, "could not create federation token secret")
+	}
+
+	return nil
+}
+
+// getToken waits for the token controller to populate the token secret of the service account
+func (a memberAccess) getToken() ([]byte, error) {
+	var token []byte
+
+	err := wait.PollImmediate(a.pollInterval, a.pollTimeout, func() (bool, error) {
+		secret, err := a.client.CoreV1().Secrets(a.namespace).Get(memberTokenSecretName, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.WrapIf(err, "could not get federation token secret")
+		}
+
+		token = secret.Data[corev1.ServiceAccountTokenKey]
+
+		return len(token) > 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, errors.New("federation token secret is not populated yet")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMemberAccess(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      memberTokenSecretName,
+			Namespace: "pipeline-system",
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+		Data: map[string][]byte{
+			corev1.ServiceAccountTokenKey: []byte("token"),
+		},
+	})
+
+	access := newMemberAccess(client, "pipeline-system")
+
+	token, err := access.Ensure()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("token"), token)
+
+	_, err = client.CoreV1().ServiceAccounts("pipeline-system").Get(memberAccessName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	role, err := client.RbacV1().Roles("pipeline-system").Get(memberAccessName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, role.Rules, 1)
+	assert.Equal(t, []string{"services/proxy"}, role.Rules[0].Resources)
+	assert.Equal(t, []string{"get"}, role.Rules[0].Verbs)
+	assert.Equal(
+		t,
+		[]string{prometheusServiceName, prometheusServiceName + ":" + prometheusServicePort},
+		role.Rules[0].ResourceNames,
+	)
+
+	binding, err := client.RbacV1().RoleBindings("pipeline-system").Get(memberAccessName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, memberAccessName, binding.RoleRef.Name)
+	require.Len(t, binding.Subjects, 1)
+	assert.Equal(t, memberAccessName, binding.Subjects[0].Name)
+
+	// ensuring again does not fail on the existing objects
+	_, err = access.Ensure()
+	require.NoError(t, err)
+
+	require.NoError(t, access.Remove())
+
+	_, err = client.RbacV1().Roles("pipeline-system").Get(memberAccessName, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// removing again ignores the missing objects
+	assert.NoError(t, access.Remove())
+}
+
+func TestMemberAccess_TokenNotPopulated(t *testing.T) {
+	access := newMemberAccess(fake.NewSimpleClientset(), "pipeline-system")
+	access.pollInterval = time.Millisecond
+	access.pollTimeout = 10 * time.Millisecond
+
+	_, err := access.Ensure()
+	assert.Error(t, err)
+}
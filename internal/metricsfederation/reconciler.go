@@ -0,0 +1,371 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup/api"
+	internalHelm "github.com/banzaicloud/pipeline/internal/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/src/helm"
+)
+
+type Config struct {
+	// HostClusterID contains the cluster ID where the central Prometheus runs
+	HostClusterID uint `json:"hostClusterID"`
+	// TargetNamespace target namespace for the central Prometheus
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// ClusterLabel is the name of the label identifying the source cluster of the federated series
+	ClusterLabel string `json:"clusterLabel,omitempty"`
+	// ScrapeInterval is the interval of federating the member Prometheus instances
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+	// Match contains the series selectors passed to the federate endpoint of the members
+	Match []string `json:"match,omitempty"`
+
+	enabled      bool
+	clusterGroup api.ClusterGroup
+	staticConfig StaticConfig
+}
+
+type Reconciler struct {
+	Configuration Config
+	Host          api.Cluster
+	Members       []api.Cluster
+
+	monitoringNamespace string
+	logger              logrus.FieldLogger
+	helmService         HelmService
+}
+
+const (
+	releaseName           = "metrics-federation"
+	credentialsSecretName = "metrics-federation-credentials"
+	credentialsMountPath  = "/etc/prometheus/federation"
+
+	// prometheusServiceName is the name of the Prometheus service deployed by the monitoring integrated service
+	prometheusServiceName = "monitor-prometheus-operato-prometheus"
+	prometheusServicePort = "web"
+
+	defaultTargetNamespace = "pipeline-system"
+	defaultClusterLabel    = "cluster"
+	defaultScrapeInterval  = "1m"
+	defaultMatch           = `{job!=""}`
+
+	statusReady                = "ready"
+	statusMonitoringNotEnabled = "monitoring not enabled"
+	statusUnreachable          = "unreachable"
+)
+
+var (
+	durationRegexp  = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// NewReconciler creates a new feature reconciler for metrics federation
+func NewReconciler(
+	config Config,
+	monitoringNamespace string,
+	logger logrus.FieldLogger,
+	helmService HelmService,
+) (*Reconciler, error) {
+	host := config.clusterGroup.Clusters[config.HostClusterID]
+	if host == nil {
+		return nil, errors.NewWithDetails("host cluster is not a member of the cluster group", "clusterID", config.HostClusterID)
+	}
+
+	members := make([]api.Cluster, 0, len(config.clusterGroup.Clusters))
+	for _, c := range config.clusterGroup.Clusters {
+		members = append(members, c)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].GetID() < members[j].GetID()
+	})
+
+	return &Reconciler{
+		Configuration:       config,
+		Host:                host,
+		Members:             members,
+		monitoringNamespace: monitoringNamespace,
+		logger: logger.WithFields(logrus.Fields{
+			"clusterID":   host.GetID(),
+			"clusterName": host.GetName(),
+		}),
+		helmService: helmService,
+	}, nil
+}
+
+// Reconcile installs or updates the central Prometheus on the host cluster with the actual members of the group,
+// or removes it when the feature is disabled
+func (r *Reconciler) Reconcile() error {
+	hostClient, err := r.getClient(r.Host)
+	if err != nil {
+		return err
+	}
+
+	if !r.Configuration.enabled {
+		return r.uninstall(hostClient)
+	}
+
+	var targets []federationTarget
+	for _, member := range r.Members {
+		logger := r.logger.WithField("memberClusterID", member.GetID())
+
+		target, err := r.getTarget(member)
+		if err != nil {
+			logger.WithError(err).Warn("member cluster is skipped from metrics federation")
+			continue
+		}
+
+		targets = append(targets, target)
+	}
+
+	if err := r.ensureNamespace(hostClient); err != nil {
+		return err
+	}
+
+	if err := r.ensureCredentialsSecret(hostClient, targets); err != nil {
+		return err
+	}
+
+	values, err := r.generateValues(targets)
+	if err != nil {
+		return err
+	}
+
+	err = r.helmService.InstallOrUpgrade(
+		r.Host,
+		internalHelm.Release{
+			ReleaseName: releaseName,
+			ChartName:   r.Configuration.staticConfig.Charts.Prometheus.Chart,
+			Namespace:   r.Configuration.TargetNamespace,
+			Values:      values,
+			Version:     r.Configuration.staticConfig.Charts.Prometheus.Version,
+		},
+		internalHelm.Options{
+			Namespace: r.Configuration.TargetNamespace,
+			Wait:      true,
+			Install:   true,
+		},
+	)
+	if err != nil {
+		return errors.WrapIf(err, "could not install federating Prometheus")
+	}
+
+	return nil
+}
+
+// GetStatus returns whether the monitoring service of the members can be federated
+func (r *Reconciler) GetStatus() map[uint]string {
+	statusMap := make(map[uint]string, len(r.Members))
+
+	for _, member := range r.Members {
+		client, err := r.getClient(member)
+		if err != nil {
+			statusMap[member.GetID()] = statusUnreachable
+			continue
+		}
+
+		enabled, err := r.isMonitoringEnabled(client)
+		switch {
+		case err != nil:
+			statusMap[member.GetID()] = statusUnreachable
+		case !enabled:
+			statusMap[member.GetID()] = statusMonitoringNotEnabled
+		default:
+			statusMap[member.GetID()] = statusReady
+		}
+	}
+
+	return statusMap
+}
+
+func (r *Reconciler) getTarget(member api.Cluster) (federationTarget, error) {
+	kubeConfig, err := member.GetK8sConfig()
+	if err != nil {
+		return federationTarget{}, errors.WrapIf(err, "could not get k8s config")
+	}
+
+	clientConfig, err := k8sclient.NewClientConfig(kubeConfig)
+	if err != nil {
+		return federationTarget{}, errors.WrapIf(err, "could not create client config from kubeconfig")
+	}
+
+	client, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return federationTarget{}, errors.WrapIf(err, "could not create client")
+	}
+
+	enabled, err := r.isMonitoringEnabled(client)
+	if err != nil {
+		return federationTarget{}, err
+	}
+
+	if !enabled {
+		return federationTarget{}, errors.New("monitoring is not enabled on the cluster")
+	}
+
+	token, err := newMemberAccess(client, r.monitoringNamespace).Ensure()
+	if err != nil {
+		return federationTarget{}, err
+	}
+
+	return newFederationTarget(member.GetID(), member.GetName(), clientConfig, token)
+}
+
+func (r *Reconciler) isMonitoringEnabled(client kubernetes.Interface) (bool, error) {
+	_, err := client.CoreV1().Services(r.monitoringNamespace).Get(prometheusServiceName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WrapIf(err, "could not get Prometheus service")
+	}
+
+	return true, nil
+}
+
+func (r *Reconciler) generateValues(targets []federationTarget) (map[string]interface{}, error) {
+	scrapeConfigs := make([]interface{}, 0, len(targets))
+	for _, target := range targets {
+		scrapeConfigs = append(scrapeConfigs, target.scrapeConfig(r.Configuration, r.monitoringNamespace))
+	}
+
+	// copy the static values so that merging does not modify the configuration
+	var values map[string]interface{}
+	staticValues, err := json.Marshal(r.Configuration.staticConfig.Charts.Prometheus.Values)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not marshal chart values")
+	}
+	if err := json.Unmarshal(staticValues, &values); err != nil {
+		return nil, errors.WrapIf(err, "could not unmarshal chart values")
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	return helm.MergeValues(values, map[string]interface{}{
+		"server": map[string]interface{}{
+			"extraSecretMounts": []interface{}{
+				map[string]interface{}{
+					"name":       credentialsSecretName,
+					"mountPath":  credentialsMountPath,
+					"secretName": credentialsSecretName,
+					"readOnly":   true,
+				},
+			},
+		},
+		"serverFiles": map[string]interface{}{
+			"prometheus.yml": map[string]interface{}{
+				"scrape_configs": scrapeConfigs,
+			},
+		},
+	}), nil
+}
+
+func (r *Reconciler) ensureNamespace(client kubernetes.Interface) error {
+	_, err := client.CoreV1().Namespaces().Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.Configuration.TargetNamespace,
+		},
+	})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.WrapIf(err, "could not create namespace")
+	}
+
+	return nil
+}
+
+func (r *Reconciler) ensureCredentialsSecret(client kubernetes.Interface, targets []federationTarget) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName,
+			Namespace: r.Configuration.TargetNamespace,
+		},
+		Data: make(map[string][]byte),
+	}
+	for _, target := range targets {
+		for key, value := range target.files {
+			secret.Data[key] = value
+		}
+	}
+
+	secrets := client.CoreV1().Secrets(r.Configuration.TargetNamespace)
+
+	current, err := secrets.Get(credentialsSecretName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = secrets.Create(secret)
+		return errors.WrapIf(err, "could not create federation credentials secret")
+	}
+	if err != nil {
+		return errors.WrapIf(err, "could not get federation credentials secret")
+	}
+
+	secret.ResourceVersion = current.ResourceVersion
+	_, err = secrets.Update(secret)
+
+	return errors.WrapIf(err, "could not update federation credentials secret")
+}
+
+func (r *Reconciler) uninstall(client kubernetes.Interface) error {
+	err := r.helmService.Delete(r.Host, releaseName, r.Configuration.TargetNamespace)
+	if err != nil {
+		return errors.WrapIf(err, "could not remove federating Prometheus")
+	}
+
+	err = client.CoreV1().Secrets(r.Configuration.TargetNamespace).Delete(credentialsSecretName, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.WrapIf(err, "could not remove federation credentials secret")
+	}
+
+	for _, member := range r.Members {
+		logger := r.logger.WithField("memberClusterID", member.GetID())
+
+		memberClient, err := r.getClient(member)
+		if err != nil {
+			logger.WithError(err).Warn("could not remove metrics federation access from member cluster")
+			continue
+		}
+
+		if err := newMemberAccess(memberClient, r.monitoringNamespace).Remove(); err != nil {
+			logger.WithError(err).Warn("could not remove metrics federation access from member cluster")
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) getClient(c api.Cluster) (kubernetes.Interface, error) {
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create client from kubeconfig")
+	}
+
+	return client, nil
+}
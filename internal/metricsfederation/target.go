@@ -0,0 +1,153 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/client-go/rest"
+)
+
+// federationTarget describes how the federating Prometheus reaches the Prometheus of a member cluster
+// through the service proxy of the member's API server
+type federationTarget struct {
+	clusterID   uint
+	clusterName string
+
+	scheme             string
+	address            string
+	pathPrefix         string
+	insecureSkipVerify bool
+
+	// files contains the credentials of the target keyed by their name in the credentials secret
+	files map[string][]byte
+}
+
+// newFederationTarget returns a target scraped with a (service account) token
+// that only grants access to the Prometheus service of the member cluster.
+// The admin credentials in the config are never used: only the API server address and CA are taken from it.
+func newFederationTarget(clusterID uint, clusterName string, config *rest.Config, token []byte) (federationTarget, error) {
+	if len(token) == 0 {
+		return federationTarget{}, errors.NewWithDetails("missing federation token", "clusterID", clusterID)
+	}
+
+	apiServerURL, err := url.Parse(config.Host)
+	if err != nil {
+		return federationTarget{}, errors.WrapIf(err, "could not parse API server URL")
+	}
+
+	// hosts without a scheme are parsed as paths
+	if apiServerURL.Host == "" {
+		apiServerURL, err = url.Parse("https://" + config.Host)
+		if err != nil {
+			return federationTarget{}, errors.WrapIf(err, "could not parse API server URL")
+		}
+	}
+
+	target := federationTarget{
+		clusterID:          clusterID,
+		clusterName:        clusterName,
+		scheme:             apiServerURL.Scheme,
+		address:            apiServerURL.Host,
+		pathPrefix:         strings.TrimSuffix(apiServerURL.Path, "/"),
+		insecureSkipVerify: config.Insecure,
+		files:              make(map[string][]byte),
+	}
+
+	if apiServerURL.Port() == "" {
+		port := "443"
+		if target.scheme == "http" {
+			port = "80"
+		}
+		target.address = net.JoinHostPort(apiServerURL.Hostname(), port)
+	}
+
+	if len(config.CAData) > 0 {
+		target.files[target.fileName("ca.crt")] = config.CAData
+	}
+
+	target.files[target.fileName("token")] = token
+
+	return target, nil
+}
+
+func (t federationTarget) fileName(name string) string {
+	return fmt.Sprintf("%d-%s", t.clusterID, name)
+}
+
+func (t federationTarget) filePath(name string) string {
+	return path.Join(credentialsMountPath, t.fileName(name))
+}
+
+func (t federationTarget) hasFile(name string) bool {
+	_, ok := t.files[t.fileName(name)]
+	return ok
+}
+
+func (t federationTarget) scrapeConfig(config Config, monitoringNamespace string) map[string]interface{} {
+	match := make([]interface{}, 0, len(config.Match))
+	for _, m := range config.Match {
+		match = append(match, m)
+	}
+
+	tlsConfig := map[string]interface{}{
+		"insecure_skip_verify": t.insecureSkipVerify,
+	}
+	if t.hasFile("ca.crt") {
+		tlsConfig["ca_file"] = t.filePath("ca.crt")
+	}
+
+	scrapeConfig := map[string]interface{}{
+		"job_name":        fmt.Sprintf("federate-%d", t.clusterID),
+		"honor_labels":    true,
+		"scrape_interval": config.ScrapeInterval,
+		"scheme":          t.scheme,
+		"metrics_path": fmt.Sprintf(
+			"%s/api/v1/namespaces/%s/services/%s:%s/proxy/federate",
+			t.pathPrefix, monitoringNamespace, prometheusServiceName, prometheusServicePort,
+		),
+		"params": map[string]interface{}{
+			"match[]": match,
+		},
+		"tls_config": tlsConfig,
+		"static_configs": []interface{}{
+			map[string]interface{}{
+				"targets": []interface{}{t.address},
+				"labels": map[string]interface{}{
+					config.ClusterLabel: t.clusterName,
+				},
+			},
+		},
+		// labels scraped from the member override target labels because of honor_labels,
+		// so the cluster label is enforced after the scrape
+		"metric_relabel_configs": []interface{}{
+			map[string]interface{}{
+				"target_label": config.ClusterLabel,
+				"replacement":  t.clusterName,
+			},
+		},
+	}
+
+	if t.hasFile("token") {
+		scrapeConfig["bearer_token_file"] = t.filePath("token")
+	}
+
+	return scrapeConfig
+}
@@ -0,0 +1,109 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricsfederation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewFederationTarget(t *testing.T) {
+	t.Run("token with CA", func(t *testing.T) {
+		target, err := newFederationTarget(7, "member", &rest.Config{
+			Host: "https://10.0.0.1:6443",
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData:   []byte("ca"),
+				CertData: []byte("cert"),
+				KeyData:  []byte("key"),
+			},
+		}, []byte("token"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "10.0.0.1:6443", target.address)
+		assert.Equal(t, map[string][]byte{
+			"7-ca.crt": []byte("ca"),
+			"7-token":  []byte("token"),
+		}, target.files)
+	})
+
+	t.Run("admin credentials are not copied", func(t *testing.T) {
+		target, err := newFederationTarget(8, "member", &rest.Config{
+			Host:        "https://api.example.org/k8s/",
+			BearerToken: "admin-token",
+			Username:    "admin",
+			Password:    "password",
+		}, []byte("token"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "api.example.org:443", target.address)
+		assert.Equal(t, "/k8s", target.pathPrefix)
+		assert.Equal(t, map[string][]byte{"8-token": []byte("token")}, target.files)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		_, err := newFederationTarget(9, "member", &rest.Config{
+			Host:        "https://10.0.0.1:6443",
+			BearerToken: "admin-token",
+		}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestFederationTarget_ScrapeConfig(t *testing.T) {
+	target, err := newFederationTarget(7, "member", &rest.Config{
+		Host: "https://10.0.0.1:6443",
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: []byte("ca"),
+		},
+	}, []byte("token"))
+	require.NoError(t, err)
+
+	scrapeConfig := target.scrapeConfig(Config{
+		ClusterLabel:   "cluster",
+		ScrapeInterval: "1m",
+		Match:          []string{`{job!=""}`},
+	}, "pipeline-system")
+
+	assert.Equal(t, map[string]interface{}{
+		"job_name":        "federate-7",
+		"honor_labels":    true,
+		"scrape_interval": "1m",
+		"scheme":          "https",
+		"metrics_path":    "/api/v1/namespaces/pipeline-system/services/monitor-prometheus-operato-prometheus:web/proxy/federate",
+		"params": map[string]interface{}{
+			"match[]": []interface{}{`{job!=""}`},
+		},
+		"tls_config": map[string]interface{}{
+			"insecure_skip_verify": false,
+			"ca_file":              "/etc/prometheus/federation/7-ca.crt",
+		},
+		"static_configs": []interface{}{
+			map[string]interface{}{
+				"targets": []interface{}{"10.0.0.1:6443"},
+				"labels":  map[string]interface{}{"cluster": "member"},
+			},
+		},
+		"metric_relabel_configs": []interface{}{
+			map[string]interface{}{
+				"target_label": "cluster",
+				"replacement":  "member",
+			},
+		},
+		"bearer_token_file": "/etc/prometheus/federation/7-token",
+	}, scrapeConfig)
+}
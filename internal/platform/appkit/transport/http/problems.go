@@ -22,4 +22,5 @@ import (
 // nolint: gochecknoglobals
 var DefaultProblemMatchers = append([]appkithttp.ProblemMatcher{
 	NewValidationWithViolationsProblemMatcher(),
+	NewForbiddenProblemMatcher(),
 }, appkithttp.DefaultProblemMatchers...)
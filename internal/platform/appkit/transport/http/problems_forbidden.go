@@ -0,0 +1,45 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	appkithttp "github.com/sagikazarmark/appkit/transport/http"
+)
+
+type forbidden interface {
+	Forbidden() bool
+}
+
+// IsForbiddenError checks if an error is related to an operation being forbidden (eg. by a quota).
+// An error is considered to be a Forbidden error if it implements the following interface:
+//
+// 	type forbidden interface {
+// 		Forbidden() bool
+// 	}
+//
+// and `Forbidden` returns true.
+func IsForbiddenError(err error) bool {
+	var e forbidden
+
+	return errors.As(err, &e) && e.Forbidden()
+}
+
+// NewForbiddenProblemMatcher returns a problem matcher for errors forbidding an operation.
+func NewForbiddenProblemMatcher() appkithttp.ProblemMatcher {
+	return appkithttp.NewStatusProblemMatcher(http.StatusForbidden, IsForbiddenError)
+}
@@ -65,6 +65,16 @@ func (conflictStub) Conflict() bool {
 	return true
 }
 
+type forbiddenStub struct{}
+
+func (forbiddenStub) Error() string {
+	return "forbidden"
+}
+
+func (forbiddenStub) Forbidden() bool {
+	return true
+}
+
 func TestDefaultProblemMatchers(t *testing.T) {
 	tests := []struct {
 		err            error
@@ -86,6 +96,10 @@ func TestDefaultProblemMatchers(t *testing.T) {
 			err:            conflictStub{},
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			err:            forbiddenStub{},
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
 	converter := NewDefaultProblemConverter()
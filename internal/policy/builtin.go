@@ -0,0 +1,117 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+// Built-in templates are adapted from the Gatekeeper policy library (https://github.com/open-policy-agent/gatekeeper-library).
+
+const privilegedContainerRego = `package k8spspprivilegedcontainer
+
+violation[{"msg": msg, "details": {}}] {
+  c := input_containers[_]
+  c.securityContext.privileged
+  msg := sprintf("Privileged container is not allowed: %v, securityContext: %v", [c.name, c.securityContext])
+}
+
+input_containers[c] {
+  c := input.review.object.spec.containers[_]
+}
+
+input_containers[c] {
+  c := input.review.object.spec.initContainers[_]
+}
+`
+
+const requiredLabelsRego = `package k8srequiredlabels
+
+violation[{"msg": msg, "details": {"missing_labels": missing}}] {
+  provided := {label | input.review.object.metadata.labels[label]}
+  required := {label | label := input.parameters.labels[_]}
+  missing := required - provided
+  count(missing) > 0
+  msg := sprintf("you must provide labels: %v", [missing])
+}
+`
+
+const allowedReposRego = `package k8sallowedrepos
+
+violation[{"msg": msg}] {
+  container := input_containers[_]
+  satisfied := [good | repo = input.parameters.repos[_]; good = startswith(container.image, repo)]
+  not any(satisfied)
+  msg := sprintf("container <%v> has an invalid image repo <%v>, allowed repos are %v", [container.name, container.image, input.parameters.repos])
+}
+
+input_containers[c] {
+  c := input.review.object.spec.containers[_]
+}
+
+input_containers[c] {
+  c := input.review.object.spec.initContainers[_]
+}
+`
+
+func stringArraySchema(name string, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			name: map[string]interface{}{
+				"type":        "array",
+				"description": description,
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		},
+	}
+}
+
+// BuiltInTemplates returns the templates available to every organization.
+func BuiltInTemplates() []Template {
+	return []Template{
+		{
+			Name:        "k8spspprivilegedcontainer",
+			Kind:        "K8sPSPPrivilegedContainer",
+			Description: "Disallows privileged containers.",
+			Rego:        privilegedContainerRego,
+			BuiltIn:     true,
+		},
+		{
+			Name:        "k8srequiredlabels",
+			Kind:        "K8sRequiredLabels",
+			Description: "Requires resources to have the specified labels.",
+			Rego:        requiredLabelsRego,
+			Parameters:  stringArraySchema("labels", "Labels every resource must have."),
+			BuiltIn:     true,
+		},
+		{
+			Name:        "k8sallowedrepos",
+			Kind:        "K8sAllowedRepos",
+			Description: "Requires container images to come from one of the specified repositories.",
+			Rego:        allowedReposRego,
+			Parameters:  stringArraySchema("repos", "Image repository prefixes containers may use."),
+			BuiltIn:     true,
+		},
+	}
+}
+
+func builtInTemplate(name string) (Template, bool) {
+	for _, template := range BuiltInTemplates() {
+		if template.Name == name {
+			return template, true
+		}
+	}
+
+	return Template{}, false
+}
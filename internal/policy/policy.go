@@ -0,0 +1,214 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// Template is a Gatekeeper constraint template in the policy library of an organization.
+type Template struct {
+	// Name is the name of the ConstraintTemplate resource (the lowercase kind).
+	Name string `json:"name"`
+
+	// Kind is the kind of the constraints created from the template.
+	Kind string `json:"kind"`
+
+	// Description is a human readable description of the policy.
+	Description string `json:"description,omitempty"`
+
+	// Rego is the Rego source of the policy.
+	Rego string `json:"rego"`
+
+	// Parameters is the OpenAPI v3 schema of the constraint parameters.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+
+	// BuiltIn tells whether the template is provided by Pipeline.
+	BuiltIn bool `json:"builtIn"`
+}
+
+var kindRegexp = regexp.MustCompile("^[A-Z][A-Za-z0-9]*$")
+
+// Validate validates a template.
+func (t Template) Validate() error {
+	var violations []string
+
+	if !kindRegexp.MatchString(t.Kind) {
+		violations = append(violations, "kind must be an alphanumeric CamelCase name")
+	}
+
+	if t.Name != strings.ToLower(t.Kind) {
+		violations = append(violations, "name must be the lowercase kind")
+	}
+
+	if !strings.Contains(t.Rego, "package ") {
+		violations = append(violations, "rego must declare a package")
+	}
+
+	if !strings.Contains(t.Rego, "violation") {
+		violations = append(violations, "rego must define violation rules")
+	}
+
+	if len(violations) > 0 {
+		return NewValidationError("invalid policy template", violations)
+	}
+
+	return nil
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service manages the policy library of organizations.
+type Service interface {
+	// ListTemplates lists the built-in templates and the templates of an organization.
+	ListTemplates(ctx context.Context, organizationID uint) (templates []Template, err error)
+
+	// GetTemplate returns a template of an organization.
+	GetTemplate(ctx context.Context, organizationID uint, name string) (template Template, err error)
+
+	// PutTemplate creates or replaces a template of an organization.
+	PutTemplate(ctx context.Context, organizationID uint, name string, template Template) error
+
+	// DeleteTemplate deletes a template of an organization.
+	DeleteTemplate(ctx context.Context, organizationID uint, name string) error
+}
+
+// +testify:mock:testOnly=true
+
+// Store persists the policy templates of organizations.
+type Store interface {
+	// Get returns a template of an organization.
+	// Returns a TemplateNotFoundError when the template does not exist.
+	Get(ctx context.Context, organizationID uint, name string) (Template, error)
+
+	// Put creates or replaces a template of an organization.
+	Put(ctx context.Context, organizationID uint, template Template) error
+
+	// Delete deletes a template of an organization.
+	Delete(ctx context.Context, organizationID uint, name string) error
+
+	// List lists the templates of an organization.
+	List(ctx context.Context, organizationID uint) ([]Template, error)
+}
+
+// NewService returns a new Service.
+func NewService(store Store) Service {
+	return service{
+		store: store,
+	}
+}
+
+type service struct {
+	store Store
+}
+
+func (s service) ListTemplates(ctx context.Context, organizationID uint) ([]Template, error) {
+	templates, err := s.store.List(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates = append(BuiltInTemplates(), templates...)
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+func (s service) GetTemplate(ctx context.Context, organizationID uint, name string) (Template, error) {
+	if template, ok := builtInTemplate(name); ok {
+		return template, nil
+	}
+
+	return s.store.Get(ctx, organizationID, name)
+}
+
+func (s service) PutTemplate(ctx context.Context, organizationID uint, name string, template Template) error {
+	if template.Name == "" {
+		template.Name = name
+	}
+
+	if template.Name != name {
+		return NewValidationError("invalid policy template", []string{"name must match the name in the URL"})
+	}
+
+	if _, ok := builtInTemplate(name); ok {
+		return NewValidationError(
+			"invalid policy template",
+			[]string{fmt.Sprintf("built-in template %s cannot be replaced", name)},
+		)
+	}
+
+	template.BuiltIn = false
+
+	if err := template.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.Put(ctx, organizationID, template)
+}
+
+func (s service) DeleteTemplate(ctx context.Context, organizationID uint, name string) error {
+	if _, ok := builtInTemplate(name); ok {
+		return NewValidationError(
+			"invalid policy template",
+			[]string{fmt.Sprintf("built-in template %s cannot be deleted", name)},
+		)
+	}
+
+	return s.store.Delete(ctx, organizationID, name)
+}
+
+// TemplateNotFoundError is returned when a policy template cannot be found.
+type TemplateNotFoundError struct {
+	OrganizationID uint
+	Name           string
+}
+
+// Error implements the error interface.
+func (TemplateNotFoundError) Error() string {
+	return "policy template not found"
+}
+
+// Details returns error details.
+func (e TemplateNotFoundError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "template", e.Name}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (TemplateNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (TemplateNotFoundError) ServiceError() bool {
+	return true
+}
+
+// IsTemplateNotFoundError tells whether an error is a TemplateNotFoundError.
+func IsTemplateNotFoundError(err error) bool {
+	return errors.As(err, &TemplateNotFoundError{})
+}
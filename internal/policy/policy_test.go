@@ -0,0 +1,153 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customRego = `package k8sdenyall
+
+violation[{"msg": "denied"}] {
+  true
+}
+`
+
+func TestTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template Template
+		valid    bool
+	}{
+		{
+			name:     "valid",
+			template: Template{Name: "k8sdenyall", Kind: "K8sDenyAll", Rego: customRego},
+			valid:    true,
+		},
+		{
+			name:     "name does not match kind",
+			template: Template{Name: "denyall", Kind: "K8sDenyAll", Rego: customRego},
+		},
+		{
+			name:     "invalid kind",
+			template: Template{Name: "k8s-deny-all", Kind: "k8s-deny-all", Rego: customRego},
+		},
+		{
+			name:     "missing rego",
+			template: Template{Name: "k8sdenyall", Kind: "K8sDenyAll"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := test.template.Validate()
+
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.As(err, &ValidationError{}))
+			}
+		})
+	}
+}
+
+func TestBuiltInTemplates_Valid(t *testing.T) {
+	for _, template := range BuiltInTemplates() {
+		assert.NoError(t, template.Validate(), template.Name)
+	}
+}
+
+func TestService_ListTemplates(t *testing.T) {
+	store := new(MockStore)
+	store.On("List", context.Background(), uint(1)).Return([]Template{{Name: "k8sdenyall", Kind: "K8sDenyAll"}}, nil)
+
+	templates, err := NewService(store).ListTemplates(context.Background(), 1)
+	require.NoError(t, err)
+
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+
+	assert.Equal(t, []string{"k8sallowedrepos", "k8sdenyall", "k8spspprivilegedcontainer", "k8srequiredlabels"}, names)
+
+	store.AssertExpectations(t)
+}
+
+func TestService_GetTemplate(t *testing.T) {
+	store := new(MockStore)
+	store.On("Get", context.Background(), uint(1), "k8sdenyall").Return(Template{Name: "k8sdenyall"}, nil)
+
+	service := NewService(store)
+
+	template, err := service.GetTemplate(context.Background(), 1, "k8srequiredlabels")
+	require.NoError(t, err)
+	assert.True(t, template.BuiltIn)
+
+	template, err = service.GetTemplate(context.Background(), 1, "k8sdenyall")
+	require.NoError(t, err)
+	assert.Equal(t, "k8sdenyall", template.Name)
+
+	store.AssertExpectations(t)
+}
+
+func TestService_PutTemplate(t *testing.T) {
+	t.Run("custom", func(t *testing.T) {
+		template := Template{Kind: "K8sDenyAll", Rego: customRego, BuiltIn: true}
+
+		store := new(MockStore)
+		store.On("Put", context.Background(), uint(1), Template{Name: "k8sdenyall", Kind: "K8sDenyAll", Rego: customRego}).Return(nil)
+
+		err := NewService(store).PutTemplate(context.Background(), 1, "k8sdenyall", template)
+		require.NoError(t, err)
+
+		store.AssertExpectations(t)
+	})
+
+	t.Run("built-in", func(t *testing.T) {
+		template := Template{Name: "k8srequiredlabels", Kind: "K8sRequiredLabels", Rego: requiredLabelsRego}
+
+		err := NewService(new(MockStore)).PutTemplate(context.Background(), 1, "k8srequiredlabels", template)
+		assert.True(t, errors.As(err, &ValidationError{}))
+	})
+
+	t.Run("name mismatch", func(t *testing.T) {
+		template := Template{Name: "k8sdenyall", Kind: "K8sDenyAll", Rego: customRego}
+
+		err := NewService(new(MockStore)).PutTemplate(context.Background(), 1, "k8sdenynone", template)
+		assert.True(t, errors.As(err, &ValidationError{}))
+	})
+}
+
+func TestService_DeleteTemplate(t *testing.T) {
+	store := new(MockStore)
+	store.On("Delete", context.Background(), uint(1), "k8sdenyall").Return(nil)
+
+	service := NewService(store)
+
+	require.NoError(t, service.DeleteTemplate(context.Background(), 1, "k8sdenyall"))
+
+	err := service.DeleteTemplate(context.Background(), 1, "k8spspprivilegedcontainer")
+	assert.True(t, errors.As(err, &ValidationError{}))
+
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,164 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyadapter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/policy"
+)
+
+// templateModel describes the policy template model.
+type templateModel struct {
+	ID             uint `gorm:"primary_key"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	OrganizationID uint   `gorm:"unique_index:idx_policy_templates_org_name"`
+	Name           string `gorm:"unique_index:idx_policy_templates_org_name"`
+	Kind           string
+	Description    string `gorm:"type:text"`
+	Rego           string `gorm:"type:text"`
+	Parameters     string `gorm:"type:text"`
+}
+
+// TableName changes the default table name.
+func (templateModel) TableName() string {
+	return "policy_templates"
+}
+
+// GormStore is a policy template store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// Get implements the policy.Store interface.
+func (s GormStore) Get(ctx context.Context, organizationID uint, name string) (policy.Template, error) {
+	var model templateModel
+
+	err := s.db.Where(templateModel{OrganizationID: organizationID, Name: name}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return policy.Template{}, errors.WithStack(policy.TemplateNotFoundError{
+			OrganizationID: organizationID,
+			Name:           name,
+		})
+	} else if err != nil {
+		return policy.Template{}, errors.WrapIfWithDetails(
+			err, "failed to get policy template",
+			"organizationId", organizationID,
+			"template", name,
+		)
+	}
+
+	return toTemplate(model)
+}
+
+// Put implements the policy.Store interface.
+func (s GormStore) Put(ctx context.Context, organizationID uint, template policy.Template) error {
+	var parameters []byte
+	if template.Parameters != nil {
+		var err error
+
+		parameters, err = json.Marshal(template.Parameters)
+		if err != nil {
+			return errors.WrapIf(err, "failed to encode policy template parameters")
+		}
+	}
+
+	var model templateModel
+
+	err := s.db.
+		Where(templateModel{OrganizationID: organizationID, Name: template.Name}).
+		Assign(map[string]interface{}{ // Zero values are ignored when a struct is used here
+			"kind":        template.Kind,
+			"description": template.Description,
+			"rego":        template.Rego,
+			"parameters":  string(parameters),
+		}).
+		FirstOrCreate(&model).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to save policy template",
+			"organizationId", organizationID,
+			"template", template.Name,
+		)
+	}
+
+	return nil
+}
+
+// Delete implements the policy.Store interface.
+func (s GormStore) Delete(ctx context.Context, organizationID uint, name string) error {
+	err := s.db.Where(templateModel{OrganizationID: organizationID, Name: name}).Delete(templateModel{}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to delete policy template",
+			"organizationId", organizationID,
+			"template", name,
+		)
+	}
+
+	return nil
+}
+
+// List implements the policy.Store interface.
+func (s GormStore) List(ctx context.Context, organizationID uint) ([]policy.Template, error) {
+	var models []templateModel
+
+	if err := s.db.Where(templateModel{OrganizationID: organizationID}).Order("name").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list policy templates", "organizationId", organizationID)
+	}
+
+	templates := make([]policy.Template, 0, len(models))
+
+	for _, model := range models {
+		template, err := toTemplate(model)
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+func toTemplate(model templateModel) (policy.Template, error) {
+	template := policy.Template{
+		Name:        model.Name,
+		Kind:        model.Kind,
+		Description: model.Description,
+		Rego:        model.Rego,
+	}
+
+	if model.Parameters != "" {
+		if err := json.Unmarshal([]byte(model.Parameters), &template.Parameters); err != nil {
+			return policy.Template{}, errors.WrapIfWithDetails(err, "failed to decode policy template parameters", "template", model.Name)
+		}
+	}
+
+	return template, nil
+}
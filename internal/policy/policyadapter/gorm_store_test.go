@@ -0,0 +1,80 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyadapter
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/policy"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.Get(ctx, 1, "k8sdenyall")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &policy.TemplateNotFoundError{}))
+
+	template := policy.Template{
+		Name: "k8sdenyall",
+		Kind: "K8sDenyAll",
+		Rego: "package k8sdenyall",
+	}
+
+	err = store.Put(ctx, 1, template)
+	require.NoError(t, err)
+
+	template.Description = "Denies everything."
+	template.Parameters = map[string]interface{}{"type": "object"}
+
+	err = store.Put(ctx, 1, template)
+	require.NoError(t, err)
+
+	err = store.Put(ctx, 2, policy.Template{Name: "k8sallowall", Kind: "K8sAllowAll"})
+	require.NoError(t, err)
+
+	actual, err := store.Get(ctx, 1, "k8sdenyall")
+	require.NoError(t, err)
+	assert.Equal(t, template, actual)
+
+	templates, err := store.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []policy.Template{template}, templates)
+
+	err = store.Delete(ctx, 1, "k8sdenyall")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, 1, "k8sdenyall")
+	assert.True(t, errors.As(err, &policy.TemplateNotFoundError{}))
+}
@@ -0,0 +1,145 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policydriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+	"github.com/banzaicloud/pipeline/internal/policy"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("/templates").Handler(kithttp.NewServer(
+		endpoints.ListTemplates,
+		decodeListTemplatesHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListTemplatesHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/templates/{name}").Handler(kithttp.NewServer(
+		endpoints.GetTemplate,
+		decodeGetTemplateHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetTemplateHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPut).Path("/templates/{name}").Handler(kithttp.NewServer(
+		endpoints.PutTemplate,
+		decodePutTemplateHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("/templates/{name}").Handler(kithttp.NewServer(
+		endpoints.DeleteTemplate,
+		decodeDeleteTemplateHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+}
+
+func decodeOrganizationID(r *http.Request) (uint, error) {
+	orgIDStr, ok := mux.Vars(r)["orgId"]
+	if !ok || orgIDStr == "" {
+		return 0, errors.NewWithDetails("missing parameter from the URL", "param", "orgId")
+	}
+
+	orgID, err := strconv.ParseUint(orgIDStr, 0, 0)
+	if err != nil {
+		return 0, errors.WrapIf(err, "invalid organization ID format")
+	}
+
+	return uint(orgID), nil
+}
+
+func decodeTemplateParams(r *http.Request) (uint, string, error) {
+	orgID, err := decodeOrganizationID(r)
+	if err != nil {
+		return 0, "", err
+	}
+
+	name, ok := mux.Vars(r)["name"]
+	if !ok || name == "" {
+		return 0, "", errors.NewWithDetails("missing parameter from the URL", "param", "name")
+	}
+
+	return orgID, name, nil
+}
+
+func decodeListTemplatesHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, err := decodeOrganizationID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListTemplatesRequest{OrganizationID: orgID}, nil
+}
+
+func encodeListTemplatesHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListTemplatesResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Templates)
+}
+
+func decodeGetTemplateHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, name, err := decodeTemplateParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetTemplateRequest{OrganizationID: orgID, Name: name}, nil
+}
+
+func encodeGetTemplateHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetTemplateResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Template)
+}
+
+func decodePutTemplateHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, name, err := decodeTemplateParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var template policy.Template
+
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	return PutTemplateRequest{OrganizationID: orgID, Name: name, Template: template}, nil
+}
+
+func decodeDeleteTemplateHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, name, err := decodeTemplateParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteTemplateRequest{OrganizationID: orgID, Name: name}, nil
+}
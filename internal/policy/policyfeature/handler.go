@@ -0,0 +1,191 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyfeature
+
+import (
+	"context"
+
+	"emperror.dev/emperror"
+	"emperror.dev/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup/api"
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+)
+
+// FeatureName is the name of the cluster group feature applying a policy set to every member cluster.
+const FeatureName = "policy"
+
+// IntegratedServiceName is the name of the integrated service enforcing policies on a cluster.
+const IntegratedServiceName = "policy"
+
+// IntegratedServiceService manages the integrated services of clusters.
+type IntegratedServiceService interface {
+	// Activate activates a integrated service.
+	Activate(ctx context.Context, clusterID uint, serviceName string, spec map[string]interface{}) error
+
+	// Deactivate deactivates a integrated service.
+	Deactivate(ctx context.Context, clusterID uint, serviceName string) error
+
+	// Update updates a integrated service.
+	Update(ctx context.Context, clusterID uint, serviceName string, spec map[string]interface{}) error
+}
+
+// SpecValidator validates policy integrated service specifications.
+type SpecValidator interface {
+	ValidateSpec(ctx context.Context, spec integratedservices.IntegratedServiceSpec) error
+}
+
+// Handler applies the policy set in the feature properties to every member of a cluster group
+// through the policy integrated service of the members.
+type Handler struct {
+	integratedServices IntegratedServiceService
+	repository         integratedservices.IntegratedServiceRepository
+	validator          SpecValidator
+	logger             logrus.FieldLogger
+	errorHandler       emperror.Handler
+}
+
+// NewHandler returns a new Handler instance.
+func NewHandler(
+	integratedServices IntegratedServiceService,
+	repository integratedservices.IntegratedServiceRepository,
+	validator SpecValidator,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Handler {
+	return &Handler{
+		integratedServices: integratedServices,
+		repository:         repository,
+		validator:          validator,
+		logger:             logger.WithField("feature", FeatureName),
+		errorHandler:       errorHandler,
+	}
+}
+
+// ReconcileState activates or updates the policy integrated service on every member when the feature is enabled,
+// and deactivates it when the feature is disabled.
+// A failing member does not stop reconciling the rest of them.
+func (h *Handler) ReconcileState(featureState api.Feature) error {
+	logger := h.logger.WithFields(logrus.Fields{
+		"clusterGroupID":   featureState.ClusterGroup.Id,
+		"clusterGroupName": featureState.ClusterGroup.Name,
+		"enabled":          featureState.Enabled,
+	})
+
+	logger.Info("start reconciling policy state")
+	defer logger.Info("finished reconciling policy state")
+
+	ctx := context.Background()
+
+	spec, err := specFromProperties(featureState.Properties)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+
+	for _, member := range featureState.ClusterGroup.Members {
+		var err error
+
+		if featureState.Enabled {
+			err = h.applyToMember(ctx, member.ID, spec)
+		} else {
+			err = h.removeFromMember(ctx, member.ID)
+		}
+
+		if err != nil {
+			errs = errors.Append(errs, errors.WrapIfWithDetails(err, "failed to reconcile cluster policies", "clusterId", member.ID, "clusterName", member.Name))
+		}
+	}
+
+	if errs != nil {
+		h.errorHandler.Handle(errs)
+
+		return errors.WrapIf(errs, "could not reconcile policies")
+	}
+
+	return nil
+}
+
+func (h *Handler) applyToMember(ctx context.Context, clusterID uint, spec map[string]interface{}) error {
+	_, err := h.repository.GetIntegratedService(ctx, clusterID, IntegratedServiceName)
+	if integratedservices.IsIntegratedServiceNotFoundError(err) {
+		return h.integratedServices.Activate(ctx, clusterID, IntegratedServiceName, spec)
+	} else if err != nil {
+		return err
+	}
+
+	return h.integratedServices.Update(ctx, clusterID, IntegratedServiceName, spec)
+}
+
+func (h *Handler) removeFromMember(ctx context.Context, clusterID uint) error {
+	_, err := h.repository.GetIntegratedService(ctx, clusterID, IntegratedServiceName)
+	if integratedservices.IsIntegratedServiceNotFoundError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return h.integratedServices.Deactivate(ctx, clusterID, IntegratedServiceName)
+}
+
+func (h *Handler) ValidateState(featureState api.Feature) error {
+	return nil
+}
+
+func (h *Handler) ValidateProperties(clusterGroup api.ClusterGroup, currentProperties, properties interface{}) error {
+	spec, err := specFromProperties(properties)
+	if err != nil {
+		return err
+	}
+
+	return h.validator.ValidateSpec(context.Background(), spec)
+}
+
+// GetMembersStatus returns the status of the policy integrated service on every member.
+func (h *Handler) GetMembersStatus(featureState api.Feature) (map[uint]string, error) {
+	ctx := context.Background()
+
+	status := make(map[uint]string, len(featureState.ClusterGroup.Members))
+
+	for _, member := range featureState.ClusterGroup.Members {
+		service, err := h.repository.GetIntegratedService(ctx, member.ID, IntegratedServiceName)
+		if integratedservices.IsIntegratedServiceNotFoundError(err) {
+			status[member.ID] = integratedservices.IntegratedServiceStatusInactive
+
+			continue
+		} else if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get policy integrated service", "clusterId", member.ID)
+		}
+
+		status[member.ID] = service.Status
+	}
+
+	return status, nil
+}
+
+func specFromProperties(properties interface{}) (map[string]interface{}, error) {
+	if properties == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	spec, ok := properties.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("policy feature properties must be an object")
+	}
+
+	return spec, nil
+}
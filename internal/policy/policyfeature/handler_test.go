@@ -0,0 +1,165 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policyfeature
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/emperror"
+	"emperror.dev/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup/api"
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+)
+
+type call struct {
+	Operation string
+	ClusterID uint
+}
+
+type fakeIntegratedServiceService struct {
+	calls    []call
+	failures map[uint]error
+}
+
+func (s *fakeIntegratedServiceService) record(operation string, clusterID uint) error {
+	s.calls = append(s.calls, call{Operation: operation, ClusterID: clusterID})
+
+	return s.failures[clusterID]
+}
+
+func (s *fakeIntegratedServiceService) Activate(ctx context.Context, clusterID uint, serviceName string, spec map[string]interface{}) error {
+	return s.record("activate", clusterID)
+}
+
+func (s *fakeIntegratedServiceService) Deactivate(ctx context.Context, clusterID uint, serviceName string) error {
+	return s.record("deactivate", clusterID)
+}
+
+func (s *fakeIntegratedServiceService) Update(ctx context.Context, clusterID uint, serviceName string, spec map[string]interface{}) error {
+	return s.record("update", clusterID)
+}
+
+type specValidatorFunc func(spec integratedservices.IntegratedServiceSpec) error
+
+func (f specValidatorFunc) ValidateSpec(ctx context.Context, spec integratedservices.IntegratedServiceSpec) error {
+	return f(spec)
+}
+
+func newTestHandler(service *fakeIntegratedServiceService) *Handler {
+	repository := integratedservices.NewInMemoryIntegratedServiceRepository(map[uint][]integratedservices.IntegratedService{
+		2: {{Name: IntegratedServiceName, Status: integratedservices.IntegratedServiceStatusActive}},
+	})
+
+	validator := specValidatorFunc(func(spec integratedservices.IntegratedServiceSpec) error {
+		if _, ok := spec["constraints"]; !ok {
+			return errors.New("constraints are required")
+		}
+
+		return nil
+	})
+
+	return NewHandler(service, repository, validator, logrus.New(), emperror.NewNoopHandler())
+}
+
+func testFeature(enabled bool) api.Feature {
+	return api.Feature{
+		ClusterGroup: api.ClusterGroup{
+			Id:      1,
+			Name:    "group",
+			Members: []api.Member{{ID: 1}, {ID: 2}},
+		},
+		Enabled:    enabled,
+		Properties: map[string]interface{}{"constraints": []interface{}{}},
+	}
+}
+
+func TestHandler_ReconcileState(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		service := &fakeIntegratedServiceService{}
+
+		err := newTestHandler(service).ReconcileState(testFeature(true))
+		require.NoError(t, err)
+
+		assert.Equal(t, []call{{"activate", 1}, {"update", 2}}, service.calls)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := &fakeIntegratedServiceService{}
+
+		err := newTestHandler(service).ReconcileState(testFeature(false))
+		require.NoError(t, err)
+
+		assert.Equal(t, []call{{"deactivate", 2}}, service.calls)
+	})
+
+	t.Run("failing member", func(t *testing.T) {
+		service := &fakeIntegratedServiceService{
+			failures: map[uint]error{1: errors.New("cluster is not ready")},
+		}
+
+		err := newTestHandler(service).ReconcileState(testFeature(true))
+		require.Error(t, err)
+
+		assert.Equal(t, []call{{"activate", 1}, {"update", 2}}, service.calls)
+	})
+}
+
+func TestHandler_ValidateProperties(t *testing.T) {
+	handler := newTestHandler(&fakeIntegratedServiceService{})
+
+	cases := map[string]struct {
+		Properties interface{}
+		Error      bool
+	}{
+		"valid properties": {
+			Properties: map[string]interface{}{"constraints": []interface{}{}},
+		},
+		"invalid spec": {
+			Properties: map[string]interface{}{},
+			Error:      true,
+		},
+		"not an object": {
+			Properties: []interface{}{},
+			Error:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := handler.ValidateProperties(api.ClusterGroup{}, nil, tc.Properties)
+			if tc.Error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHandler_GetMembersStatus(t *testing.T) {
+	status, err := newTestHandler(&fakeIntegratedServiceService{}).GetMembersStatus(testFeature(true))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[uint]string{
+		1: integratedservices.IntegratedServiceStatusInactive,
+		2: integratedservices.IntegratedServiceStatusActive,
+	}, status)
+}
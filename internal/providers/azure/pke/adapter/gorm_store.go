@@ -551,6 +551,18 @@ func (s ClusterStore) SetSSHSecretID(clusterID uint, secretID string) error {
 	return getError(s.db.Model(&model).Updates(fields), "failed to update cluster model")
 }
 
+func (s ClusterStore) SetKubernetesVersion(clusterID uint, version string) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := clusterModel{
+		ClusterID: clusterID,
+	}
+
+	return getError(s.db.Model(&model).Where("cluster_id = ?", clusterID).Update("KubernetesVersion", version), "failed to update PKE-on-Azure cluster model")
+}
+
 func (s ClusterStore) GetConfigSecretID(clusterID uint) (string, error) {
 	if err := validateClusterID(clusterID); err != nil {
 		return "", errors.WrapIf(err, "invalid cluster ID")
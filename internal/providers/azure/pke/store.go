@@ -51,6 +51,7 @@ type ClusterStore interface {
 	SetActiveWorkflowID(clusterID uint, workflowID string) error
 	SetConfigSecretID(clusterID uint, secretID string) error
 	SetSSHSecretID(clusterID uint, sshSecretID string) error
+	SetKubernetesVersion(clusterID uint, version string) error
 	SetNodePoolSizes(clusterID uint, nodePoolName string, min, max, desiredCount uint, autoscaling bool) error
 	UpdateClusterAccessPoints(clusterID uint, accessPoints AccessPoints) error
 }
@@ -0,0 +1,561 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"emperror.dev/emperror"
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter/clustermodel"
+	sqlJson "github.com/banzaicloud/pipeline/internal/database/sql/json"
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+const (
+	ClustersTableName  = "hosts_pke_clusters"
+	NodePoolsTableName = "hosts_pke_node_pools"
+	HostsTableName     = "hosts_pke_hosts"
+)
+
+type gormHostsPKEClusterStore struct {
+	db *gorm.DB
+}
+
+func NewClusterStore(db *gorm.DB) pke.ClusterStore {
+	return gormHostsPKEClusterStore{
+		db: db,
+	}
+}
+
+type rolesModel []string
+
+func (m *rolesModel) Scan(v interface{}) error {
+	return sqlJson.Scan(v, m)
+}
+
+func (m rolesModel) Value() (driver.Value, error) {
+	return sqlJson.Value(m)
+}
+
+type labelsModel map[string]string
+
+func (m *labelsModel) Scan(v interface{}) error {
+	return sqlJson.Scan(v, m)
+}
+
+func (m labelsModel) Value() (driver.Value, error) {
+	return sqlJson.Value(m)
+}
+
+type hostModel struct {
+	ID          uint   `gorm:"primary_key"`
+	ClusterID   uint   `gorm:"unique_index:idx_hosts_pke_host_cluster_id_address"`
+	NodePoolID  uint   `gorm:"index:idx_hosts_pke_host_node_pool_id"`
+	Address     string `gorm:"unique_index:idx_hosts_pke_host_cluster_id_address"`
+	Port        int
+	SSHSecretID string
+	Labels      labelsModel `gorm:"type:json"`
+	NodeName    string
+
+	SSHHostKeyFingerprint string
+}
+
+func (hostModel) TableName() string {
+	return HostsTableName
+}
+
+type nodePoolModel struct {
+	ID        uint `gorm:"primary_key"`
+	ClusterID uint `gorm:"unique_index:idx_hosts_pke_np_cluster_id_name"`
+	CreatedBy uint
+	Name      string      `gorm:"unique_index:idx_hosts_pke_np_cluster_id_name"`
+	Roles     rolesModel  `gorm:"type:json"`
+	Labels    labelsModel `gorm:"type:json"`
+	Hosts     []hostModel `gorm:"foreignkey:NodePoolID"`
+}
+
+func (nodePoolModel) TableName() string {
+	return NodePoolsTableName
+}
+
+type hostsPkeCluster struct {
+	ID        uint                      `gorm:"primary_key"`
+	ClusterID uint                      `gorm:"unique_index:idx_hosts_pke_cluster_id"`
+	Cluster   clustermodel.ClusterModel `gorm:"foreignkey:ClusterID"`
+	Spec      ProviderSpec              `gorm:"type:json"`
+	NodePools []nodePoolModel           `gorm:"foreignkey:ClusterID;association_foreignkey:ClusterID"`
+}
+
+func (hostsPkeCluster) TableName() string {
+	return ClustersTableName
+}
+
+type ProviderSpec struct {
+	Kubernetes       intPKE.Kubernetes
+	ActiveWorkflowID string
+	HTTPProxy        intPKE.HTTPProxy
+	APIServerAddress string
+}
+
+func (m *ProviderSpec) Scan(v interface{}) error {
+	if s, ok := v.(string); ok {
+		v = []byte(s)
+	}
+	return json.Unmarshal(v.([]byte), m)
+}
+
+func (m ProviderSpec) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+type recordNotFoundError struct{}
+
+func (recordNotFoundError) Error() string {
+	return "record was not found"
+}
+
+func (recordNotFoundError) NotFound() bool {
+	return true
+}
+
+func fillClusterFromClusterModel(cl *pke.PKEOnHostsCluster, model clustermodel.ClusterModel) {
+	cl.CreatedBy = model.CreatedBy
+	cl.CreationTime = model.CreatedAt
+	cl.ID = model.ID
+	cl.K8sSecretID = model.ConfigSecretID
+	cl.Name = model.Name
+	cl.OrganizationID = model.OrganizationID
+	cl.SecretID = model.SecretID
+	cl.SSHSecretID = model.SSHSecretID
+	cl.Status = model.Status
+	cl.StatusMessage = model.StatusMessage
+	cl.UID = model.UID
+
+	cl.ScaleOptions.DesiredCpu = model.ScaleOptions.DesiredCpu
+	cl.ScaleOptions.DesiredGpu = model.ScaleOptions.DesiredGpu
+	cl.ScaleOptions.DesiredMem = model.ScaleOptions.DesiredMem
+	cl.ScaleOptions.Enabled = model.ScaleOptions.Enabled
+	cl.ScaleOptions.Excludes = unmarshalStringSlice(model.ScaleOptions.Excludes)
+	cl.ScaleOptions.KeepDesiredCapacity = model.ScaleOptions.KeepDesiredCapacity
+	cl.ScaleOptions.OnDemandPct = model.ScaleOptions.OnDemandPct
+
+	cl.Kubernetes.RBAC = model.RbacEnabled
+	cl.Kubernetes.OIDC.Enabled = model.OidcEnabled
+}
+
+func fillClusterFromModel(cluster *pke.PKEOnHostsCluster, model hostsPkeCluster) {
+	fillClusterFromClusterModel(cluster, model.Cluster)
+
+	cluster.NodePools = make([]pke.NodePool, len(model.NodePools))
+	for i, np := range model.NodePools {
+		fillNodePoolFromModel(&cluster.NodePools[i], np)
+	}
+
+	cluster.Kubernetes = model.Spec.Kubernetes
+	cluster.Kubernetes.RBAC = model.Cluster.RbacEnabled
+	cluster.Kubernetes.OIDC.Enabled = model.Cluster.OidcEnabled
+	cluster.ActiveWorkflowID = model.Spec.ActiveWorkflowID
+	cluster.HTTPProxy = model.Spec.HTTPProxy
+	cluster.APIServerAddress = model.Spec.APIServerAddress
+}
+
+func fillNodePoolFromModel(nodePool *pke.NodePool, model nodePoolModel) {
+	nodePool.CreatedBy = model.CreatedBy
+	nodePool.Name = model.Name
+	nodePool.Roles = model.Roles
+	nodePool.Labels = model.Labels
+
+	nodePool.Hosts = make([]pke.Host, len(model.Hosts))
+	for i, h := range model.Hosts {
+		fillHostFromModel(&nodePool.Hosts[i], h)
+	}
+}
+
+func fillModelFromNodePool(model *nodePoolModel, nodePool pke.NodePool) {
+	model.CreatedBy = nodePool.CreatedBy
+	model.Name = nodePool.Name
+	model.Roles = nodePool.Roles
+	model.Labels = nodePool.Labels
+
+	model.Hosts = make([]hostModel, len(nodePool.Hosts))
+	for i, h := range nodePool.Hosts {
+		fillModelFromHost(&model.Hosts[i], h)
+	}
+}
+
+func fillHostFromModel(host *pke.Host, model hostModel) {
+	host.Address = model.Address
+	host.Port = model.Port
+	host.SSHSecretID = model.SSHSecretID
+	host.SSHHostKeyFingerprint = model.SSHHostKeyFingerprint
+	host.Labels = model.Labels
+	host.NodeName = model.NodeName
+}
+
+func fillModelFromHost(model *hostModel, host pke.Host) {
+	model.Address = host.Address
+	model.Port = host.Port
+	model.SSHSecretID = host.SSHSecretID
+	model.SSHHostKeyFingerprint = host.SSHHostKeyFingerprint
+	model.Labels = host.Labels
+	model.NodeName = host.NodeName
+}
+
+func (s gormHostsPKEClusterStore) CreateNodePool(clusterID uint, nodePool pke.NodePool) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	var np nodePoolModel
+	fillModelFromNodePool(&np, nodePool)
+	np.ClusterID = clusterID
+	for i := range np.Hosts {
+		np.Hosts[i].ClusterID = clusterID
+	}
+	return getError(s.db.Create(&np), "failed to create node pool model")
+}
+
+func (s gormHostsPKEClusterStore) Create(params pke.CreateParams) (c pke.PKEOnHostsCluster, err error) {
+	nodePools := make([]nodePoolModel, len(params.NodePools))
+	for i, np := range params.NodePools {
+		fillModelFromNodePool(&nodePools[i], np)
+	}
+
+	model := hostsPkeCluster{
+		Cluster: clustermodel.ClusterModel{
+			CreatedBy:      params.CreatedBy,
+			Name:           params.Name,
+			Cloud:          pkgCluster.Hosts,
+			Distribution:   pkgCluster.PKE,
+			OrganizationID: params.OrganizationID,
+			SecretID:       params.SecretID,
+			SSHSecretID:    params.SecretID,
+			Status:         pkgCluster.Creating,
+			StatusMessage:  pkgCluster.CreatingMessage,
+			RbacEnabled:    params.RBAC,
+			OidcEnabled:    params.OIDC,
+			ScaleOptions: clustermodel.ScaleOptions{
+				Enabled:             params.ScaleOptions.Enabled,
+				DesiredCpu:          params.ScaleOptions.DesiredCpu,
+				DesiredMem:          params.ScaleOptions.DesiredMem,
+				DesiredGpu:          params.ScaleOptions.DesiredGpu,
+				OnDemandPct:         params.ScaleOptions.OnDemandPct,
+				Excludes:            marshalStringSlice(params.ScaleOptions.Excludes),
+				KeepDesiredCapacity: params.ScaleOptions.KeepDesiredCapacity,
+			},
+		},
+		Spec: ProviderSpec{
+			Kubernetes:       params.Kubernetes,
+			HTTPProxy:        params.HTTPProxy,
+			APIServerAddress: params.APIServerAddress,
+		},
+	}
+
+	if err = getError(s.db.Preload("Cluster").Create(&model), "failed to create cluster model"); err != nil {
+		return
+	}
+
+	// hosts reference both the cluster and their node pool, so node pools are created once the cluster ID is known
+	for i := range nodePools {
+		nodePools[i].ClusterID = model.ClusterID
+		for j := range nodePools[i].Hosts {
+			nodePools[i].Hosts[j].ClusterID = model.ClusterID
+		}
+		if err = getError(s.db.Create(&nodePools[i]), "failed to create node pool model"); err != nil {
+			return
+		}
+	}
+	model.NodePools = nodePools
+
+	fillClusterFromModel(&c, model)
+	return
+}
+
+func (s gormHostsPKEClusterStore) getNodePool(clusterID uint, nodePoolName string) (nodePoolModel, error) {
+	if err := validateClusterID(clusterID); err != nil {
+		return nodePoolModel{}, errors.WrapIf(err, "invalid cluster ID")
+	}
+	if nodePoolName == "" {
+		return nodePoolModel{}, errors.New("empty node pool name")
+	}
+
+	model := nodePoolModel{
+		ClusterID: clusterID,
+		Name:      nodePoolName,
+	}
+	err := getError(s.db.Where(model).First(&model), "failed to load model from database")
+
+	return model, err
+}
+
+func (s gormHostsPKEClusterStore) DeleteNodePool(clusterID uint, nodePoolName string) error {
+	model, err := s.getNodePool(clusterID, nodePoolName)
+	if err != nil {
+		return err
+	}
+
+	if err := getError(s.db.Where(hostModel{NodePoolID: model.ID}).Delete(hostModel{}), "failed to delete node pool hosts from database"); err != nil {
+		return err
+	}
+
+	return getError(s.db.Delete(model), "failed to delete model from database")
+}
+
+func (s gormHostsPKEClusterStore) AddHost(clusterID uint, nodePoolName string, host pke.Host) error {
+	nodePool, err := s.getNodePool(clusterID, nodePoolName)
+	if err != nil {
+		return err
+	}
+
+	var model hostModel
+	fillModelFromHost(&model, host)
+	model.ClusterID = clusterID
+	model.NodePoolID = nodePool.ID
+
+	return getError(s.db.Create(&model), "failed to create host model")
+}
+
+func (s gormHostsPKEClusterStore) getHost(clusterID uint, address string) (hostModel, error) {
+	if err := validateClusterID(clusterID); err != nil {
+		return hostModel{}, errors.WrapIf(err, "invalid cluster ID")
+	}
+	if address == "" {
+		return hostModel{}, errors.New("empty host address")
+	}
+
+	model := hostModel{
+		ClusterID: clusterID,
+		Address:   address,
+	}
+	err := getError(s.db.Where(model).First(&model), "failed to load model from database")
+
+	return model, err
+}
+
+func (s gormHostsPKEClusterStore) DeleteHost(clusterID uint, address string) error {
+	model, err := s.getHost(clusterID, address)
+	if err != nil {
+		return err
+	}
+
+	return getError(s.db.Delete(model), "failed to delete model from database")
+}
+
+func (s gormHostsPKEClusterStore) SetHostNodeName(clusterID uint, address string, nodeName string) error {
+	model, err := s.getHost(clusterID, address)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"node_name": nodeName,
+	}
+
+	return getError(s.db.Model(&model).Updates(fields), "failed to update model in database")
+}
+
+func (s gormHostsPKEClusterStore) Delete(clusterID uint) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := clustermodel.ClusterModel{
+		ID: clusterID,
+	}
+	if err := getError(s.db.Where(model).First(&model), "failed to load model from database"); err != nil {
+		return err
+	}
+
+	if err := getError(s.db.Where(hostModel{ClusterID: clusterID}).Delete(hostModel{}), "failed to delete hosts from database"); err != nil {
+		return err
+	}
+
+	return getError(s.db.Delete(model), "failed to soft-delete model from database")
+}
+
+func (s gormHostsPKEClusterStore) GetByID(clusterID uint) (cluster pke.PKEOnHostsCluster, _ error) {
+	if err := validateClusterID(clusterID); err != nil {
+		return cluster, errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := hostsPkeCluster{
+		ClusterID: clusterID,
+	}
+	if err := getError(s.db.Preload("Cluster").Preload("NodePools").Preload("NodePools.Hosts").Where(&model).First(&model), "failed to load model from database"); err != nil {
+		return cluster, err
+	}
+	fillClusterFromModel(&cluster, model)
+	return
+}
+
+func (s gormHostsPKEClusterStore) SetStatus(clusterID uint, status, message string) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := clustermodel.ClusterModel{
+		ID: clusterID,
+	}
+	if err := getError(s.db.Where(&model).First(&model), "failed to load cluster model"); err != nil {
+		return err
+	}
+
+	if status != model.Status || message != model.StatusMessage {
+		fields := map[string]interface{}{
+			"status":        status,
+			"statusMessage": message,
+		}
+
+		statusHistory := clustermodel.StatusHistoryModel{
+			ClusterID:   model.ID,
+			ClusterName: model.Name,
+
+			FromStatus:        model.Status,
+			FromStatusMessage: model.StatusMessage,
+			ToStatus:          status,
+			ToStatusMessage:   message,
+		}
+		if err := getError(s.db.Save(&statusHistory), "failed to save status history"); err != nil {
+			return err
+		}
+
+		return getError(s.db.Model(&model).Updates(fields), "failed to update cluster model")
+	}
+
+	return nil
+}
+
+func (s gormHostsPKEClusterStore) getProviderData(clusterID uint) (ProviderSpec, error) {
+	if err := validateClusterID(clusterID); err != nil {
+		return ProviderSpec{}, errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := hostsPkeCluster{
+		ClusterID: clusterID,
+	}
+	if err := getError(s.db.Where(&model).First(&model), "failed to load cluster model"); err != nil {
+		return ProviderSpec{}, err
+	}
+
+	return model.Spec, nil
+}
+
+func (s gormHostsPKEClusterStore) updateProviderData(clusterID uint, data ProviderSpec) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := hostsPkeCluster{
+		ClusterID: clusterID,
+	}
+
+	return getError(s.db.Model(&model).Where("cluster_id = ?", clusterID).Update("Spec", data), "failed to update PKE-on-hosts cluster model")
+}
+
+func (s gormHostsPKEClusterStore) SetActiveWorkflowID(clusterID uint, workflowID string) error {
+	data, err := s.getProviderData(clusterID)
+	if err != nil {
+		return err
+	}
+
+	data.ActiveWorkflowID = workflowID
+
+	return s.updateProviderData(clusterID, data)
+}
+
+func (s gormHostsPKEClusterStore) SetConfigSecretID(clusterID uint, secretID string) error {
+	if err := validateClusterID(clusterID); err != nil {
+		return errors.WrapIf(err, "invalid cluster ID")
+	}
+
+	model := clustermodel.ClusterModel{
+		ID: clusterID,
+	}
+
+	fields := map[string]interface{}{
+		"ConfigSecretID": secretID,
+	}
+
+	return getError(s.db.Model(&model).Updates(fields), "failed to update cluster model")
+}
+
+// Migrate executes the table migrations for the provider.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&hostsPkeCluster{},
+		&nodePoolModel{},
+		&hostModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"provider":    pke.PKEOnHosts,
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating provider tables")
+
+	return db.AutoMigrate(tables...).Error
+}
+
+func validateClusterID(clusterID uint) error {
+	if clusterID == 0 {
+		return errors.New("cluster ID cannot be 0")
+	}
+	return nil
+}
+
+func getError(db *gorm.DB, message string, args ...interface{}) error {
+	err := db.Error
+	if gorm.IsRecordNotFoundError(err) {
+		err = recordNotFoundError{}
+	}
+	if len(args) == 0 {
+		err = errors.WrapIf(err, message)
+	} else {
+		err = errors.WrapIff(err, message, args...)
+	}
+	return err
+}
+
+func marshalStringSlice(s []string) string {
+	data, err := json.Marshal(s)
+	emperror.Panic(errors.WrapIf(err, "failed to marshal string slice"))
+	return string(data)
+}
+
+func unmarshalStringSlice(s string) (result []string) {
+	if s == "" {
+		// empty list in legacy format
+		return nil
+	}
+	err := errors.WrapIf(json.Unmarshal([]byte(s), &result), "failed to unmarshal string slice")
+	if err != nil {
+		// try to parse legacy format
+		result = strings.Split(s, ",")
+	}
+	return
+}
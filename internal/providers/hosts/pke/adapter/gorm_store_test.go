@@ -0,0 +1,84 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+)
+
+func TestFillNodePoolFromModel(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    nodePoolModel
+		expected pke.NodePool
+	}{
+		{
+			name:     "empty node pool model",
+			input:    nodePoolModel{},
+			expected: pke.NodePool{Hosts: []pke.Host{}},
+		},
+		{
+			name: "node pool model with hosts",
+			input: nodePoolModel{
+				CreatedBy: 1,
+				Name:      "workers",
+				Roles:     rolesModel{"worker"},
+				Labels:    labelsModel{"pool": "workers"},
+				Hosts: []hostModel{
+					{
+						ClusterID:   1,
+						NodePoolID:  2,
+						Address:     "10.0.0.10",
+						Port:        2222,
+						SSHSecretID: "ssh-secret",
+						Labels:      labelsModel{"disk": "ssd"},
+						NodeName:    "worker-0",
+					},
+				},
+			},
+			expected: pke.NodePool{
+				CreatedBy: 1,
+				Name:      "workers",
+				Roles:     []string{"worker"},
+				Labels:    map[string]string{"pool": "workers"},
+				Hosts: []pke.Host{
+					{
+						Address:     "10.0.0.10",
+						Port:        2222,
+						SSHSecretID: "ssh-secret",
+						Labels:      map[string]string{"disk": "ssd"},
+						NodeName:    "worker-0",
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result pke.NodePool
+			fillNodePoolFromModel(&result, tc.input)
+			assert.Equal(t, tc.expected, result)
+
+			var model nodePoolModel
+			fillModelFromNodePool(&model, result)
+			assert.Equal(t, tc.input.Name, model.Name)
+			assert.Len(t, model.Hosts, len(tc.input.Hosts))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterbase"
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	pkgPKE "github.com/banzaicloud/pipeline/pkg/cluster/pke"
+)
+
+const PKEOnHosts = "pke-on-hosts"
+
+// DefaultSSHPort is used when a host does not specify its SSH port.
+const DefaultSSHPort = 22
+
+// Host is an existing machine that PKE is installed on over SSH.
+type Host struct {
+	Address     string
+	Port        int
+	SSHSecretID string
+	Labels      map[string]string
+
+	// SSHHostKeyFingerprint is the SHA256 fingerprint of the SSH host key of the host.
+	SSHHostKeyFingerprint string
+
+	// NodeName is the name of the Kubernetes node once the host joined the cluster.
+	NodeName string
+}
+
+type NodePool struct {
+	CreatedBy uint
+	Name      string
+	Roles     []string
+	Labels    map[string]string
+	Hosts     []Host
+}
+
+func (np NodePool) Size() int {
+	return len(np.Hosts)
+}
+
+func (np NodePool) HasRole(role pkgPKE.Role) bool {
+	for _, r := range np.Roles {
+		if r == string(role) {
+			return true
+		}
+	}
+	return false
+}
+
+type PKEOnHostsCluster struct {
+	clusterbase.ClusterBase
+
+	NodePools        []NodePool
+	Kubernetes       intPKE.Kubernetes
+	ActiveWorkflowID string
+	HTTPProxy        intPKE.HTTPProxy
+
+	// APIServerAddress is the address (eg. a load balancer or a virtual IP) the API server is reachable on.
+	// The address of the first master host is used if empty.
+	APIServerAddress string
+}
+
+func (c PKEOnHostsCluster) HasActiveWorkflow() bool {
+	return c.ActiveWorkflowID != ""
+}
+
+// GetAPIServerAddress returns the address nodes should use to reach the API server.
+func (c PKEOnHostsCluster) GetAPIServerAddress() string {
+	if c.APIServerAddress != "" {
+		return c.APIServerAddress
+	}
+
+	for _, np := range c.NodePools {
+		if np.HasRole(pkgPKE.RoleMaster) && len(np.Hosts) > 0 {
+			return np.Hosts[0].Address
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,400 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/client"
+	corev1 "k8s.io/api/core/v1"
+
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver/commoncluster"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/workflow"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgPKE "github.com/banzaicloud/pipeline/pkg/cluster/pke"
+	"github.com/banzaicloud/pipeline/src/auth"
+	"github.com/banzaicloud/pipeline/src/cluster"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+const pkeVersion = "0.4.26"
+const MasterNodeTaint = pkgPKE.TaintKeyMaster + ":" + string(corev1.TaintEffectNoSchedule)
+
+func MakePKEOnHostsClusterCreator(
+	logger Logger,
+	config ClusterConfig,
+	k8sPreparer intPKE.KubernetesPreparer,
+	organizations OrganizationStore,
+	secrets ClusterCreatorSecretStore,
+	store pke.ClusterStore,
+	workflowClient client.Client,
+) PKEOnHostsClusterCreator {
+	return PKEOnHostsClusterCreator{
+		logger:           logger,
+		config:           config,
+		creationPreparer: MakePKEOnHostsClusterCreationParamsPreparer(logger, k8sPreparer, secrets),
+		organizations:    organizations,
+		secrets:          secrets,
+		store:            store,
+		workflowClient:   workflowClient,
+	}
+}
+
+// PKEOnHostsClusterCreator creates new PKE clusters on existing hosts
+type PKEOnHostsClusterCreator struct {
+	logger           Logger
+	config           ClusterConfig
+	creationPreparer PKEOnHostsClusterCreationParamsPreparer
+	organizations    OrganizationStore
+	secrets          ClusterCreatorSecretStore
+	store            pke.ClusterStore
+	workflowClient   client.Client
+}
+
+type OrganizationStore interface {
+	Get(ctx context.Context, id uint) (auth.Organization, error)
+}
+
+type ClusterCreatorSecretStore interface {
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+	GetByName(organizationID uint, secretName string) (*secret.SecretItemResponse, error)
+}
+
+type ClusterConfig struct {
+	OIDCIssuerURL               string
+	PipelineExternalURL         string
+	PipelineExternalURLInsecure bool
+}
+
+// Host describes an existing host of a node pool
+type Host struct {
+	Address               string
+	Port                  int
+	SSHSecretID           string
+	SSHHostKeyFingerprint string
+	Labels                map[string]string
+}
+
+func (h Host) toPke() pke.Host {
+	return pke.Host{
+		Address:               h.Address,
+		Port:                  h.Port,
+		SSHSecretID:           h.SSHSecretID,
+		SSHHostKeyFingerprint: h.SSHHostKeyFingerprint,
+		Labels:                h.Labels,
+	}
+}
+
+type NodePool struct {
+	CreatedBy uint
+	Name      string
+	Roles     []string
+	Labels    map[string]string
+	Hosts     []Host
+}
+
+func (np NodePool) hasRole(role pkgPKE.Role) bool {
+	for _, r := range np.Roles {
+		if r == string(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (np NodePool) toPke() pke.NodePool {
+	hosts := make([]pke.Host, len(np.Hosts))
+	for i, h := range np.Hosts {
+		hosts[i] = h.toPke()
+	}
+
+	return pke.NodePool{
+		CreatedBy: np.CreatedBy,
+		Name:      np.Name,
+		Roles:     np.Roles,
+		Labels:    np.Labels,
+		Hosts:     hosts,
+	}
+}
+
+// PKEOnHostsClusterCreationParams defines parameters for PKE-on-hosts cluster creation
+type PKEOnHostsClusterCreationParams struct {
+	CreatedBy        uint
+	Name             string
+	NodePools        []NodePool
+	OrganizationID   uint
+	ScaleOptions     pkgCluster.ScaleOptions
+	SecretID         string
+	HTTPProxy        intPKE.HTTPProxy
+	Kubernetes       intPKE.Kubernetes
+	APIServerAddress string
+}
+
+// Create
+func (cc PKEOnHostsClusterCreator) Create(ctx context.Context, params PKEOnHostsClusterCreationParams) (cl pke.PKEOnHostsCluster, err error) {
+	if err = cc.creationPreparer.Prepare(ctx, &params); err != nil {
+		return
+	}
+
+	nodePools := make([]pke.NodePool, len(params.NodePools))
+	for i, np := range params.NodePools {
+		nodePools[i] = np.toPke()
+	}
+	createParams := pke.CreateParams{
+		Name:             params.Name,
+		OrganizationID:   params.OrganizationID,
+		CreatedBy:        params.CreatedBy,
+		SecretID:         params.SecretID,
+		RBAC:             params.Kubernetes.RBAC,
+		OIDC:             params.Kubernetes.OIDC.Enabled,
+		ScaleOptions:     params.ScaleOptions,
+		NodePools:        nodePools,
+		HTTPProxy:        params.HTTPProxy,
+		Kubernetes:       params.Kubernetes,
+		APIServerAddress: params.APIServerAddress,
+	}
+	cl, err = cc.store.Create(createParams)
+	if err != nil {
+		return
+	}
+
+	tf := nodeTemplateFactory{
+		ClusterID:                   cl.ID,
+		ClusterName:                 cl.Name,
+		KubernetesVersion:           cl.Kubernetes.Version,
+		NoProxy:                     strings.Join(cl.HTTPProxy.Exceptions, ","),
+		OrganizationID:              cl.OrganizationID,
+		PipelineExternalURL:         cc.config.PipelineExternalURL,
+		PipelineExternalURLInsecure: cc.config.PipelineExternalURLInsecure,
+		SingleNodePool:              len(cl.NodePools) == 1,
+	}
+
+	if cl.Kubernetes.OIDC.Enabled {
+		tf.OIDCIssuerURL = cc.config.OIDCIssuerURL
+		tf.OIDCClientID = cl.UID
+	}
+
+	var nodes []workflow.Node
+	for _, np := range cl.NodePools {
+		for _, host := range np.Hosts {
+			nodes = append(nodes, tf.getNode(np, host))
+		}
+	}
+
+	org, err := cc.organizations.Get(ctx, cl.OrganizationID)
+	if err != nil {
+		return cl, errors.WrapIf(err, "failed to get organization")
+	}
+
+	var labelsMap map[string]map[string]string
+	{
+		var commonCluster cluster.CommonCluster
+		commonCluster, err = commoncluster.MakeCommonClusterGetter(cc.secrets, cc.store).GetByID(cl.ID)
+		if err != nil {
+			_ = cc.handleError(cl.ID, err)
+			return
+		}
+
+		nodePoolLabels := make([]cluster.NodePoolLabels, 0)
+		for _, np := range params.NodePools {
+			nodePoolLabels = append(nodePoolLabels, cluster.NodePoolLabels{
+				NodePoolName: np.Name,
+				Existing:     false,
+				CustomLabels: np.Labels,
+			})
+		}
+
+		labelsMap, err = cluster.GetDesiredLabelsForCluster(ctx, commonCluster, nodePoolLabels)
+		if err != nil {
+			_ = cc.handleError(cl.ID, err)
+			return
+		}
+	}
+
+	input := workflow.CreateClusterWorkflowInput{
+		ClusterID:        cl.ID,
+		ClusterName:      cl.Name,
+		ClusterUID:       cl.UID,
+		OrganizationID:   cl.OrganizationID,
+		OrganizationName: org.Name,
+		OIDCEnabled:      cl.Kubernetes.OIDC.Enabled,
+		APIServerAddress: cl.GetAPIServerAddress(),
+		Nodes:            nodes,
+		HTTPProxy:        cl.HTTPProxy,
+		NodePoolLabels:   labelsMap,
+	}
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour, // installation on the hosts may include package downloads
+	}
+
+	wfexec, err := cc.workflowClient.StartWorkflow(ctx, workflowOptions, workflow.CreateClusterWorkflowName, input)
+	if err != nil {
+		_ = cc.handleError(cl.ID, err)
+		return
+	}
+
+	if err = cc.store.SetActiveWorkflowID(cl.ID, wfexec.ID); err != nil {
+		_ = cc.handleError(cl.ID, err)
+		return
+	}
+
+	return
+}
+
+func (cc PKEOnHostsClusterCreator) handleError(clusterID uint, err error) error {
+	return handleClusterError(cc.logger, cc.store, pkgCluster.Error, clusterID, err)
+}
+
+// PKEOnHostsClusterCreationParamsPreparer implements PKEOnHostsClusterCreationParams preparation
+type PKEOnHostsClusterCreationParamsPreparer struct {
+	k8sPreparer intPKE.KubernetesPreparer
+	logger      Logger
+	secrets     ClusterCreatorSecretStore
+}
+
+// MakePKEOnHostsClusterCreationParamsPreparer returns an instance of PKEOnHostsClusterCreationParamsPreparer
+func MakePKEOnHostsClusterCreationParamsPreparer(logger Logger, k8sPreparer intPKE.KubernetesPreparer, secrets ClusterCreatorSecretStore) PKEOnHostsClusterCreationParamsPreparer {
+	return PKEOnHostsClusterCreationParamsPreparer{
+		k8sPreparer: k8sPreparer,
+		logger:      logger,
+		secrets:     secrets,
+	}
+}
+
+// Prepare validates and provides defaults for PKEOnHostsClusterCreationParams fields
+func (p PKEOnHostsClusterCreationParamsPreparer) Prepare(ctx context.Context, params *PKEOnHostsClusterCreationParams) error {
+	if params.Name == "" {
+		return validationErrorf("Name cannot be empty")
+	}
+	if params.OrganizationID == 0 {
+		return validationErrorf("OrganizationID cannot be 0")
+	}
+
+	_, err := auth.GetOrganizationById(params.OrganizationID)
+	if err != nil {
+		return validationErrorf("OrganizationID cannot be found %s", err.Error())
+	}
+
+	// validate secretID, the SSH secret used for hosts not specifying their own
+	if params.SecretID == "" {
+		return validationErrorf("SecretID cannot be empty")
+	}
+	secretVerifier := newSSHSecretVerifier(params.OrganizationID, p.secrets)
+	if err := secretVerifier.verify(params.SecretID); err != nil {
+		return err
+	}
+
+	if err := p.k8sPreparer.Prepare(&params.Kubernetes); err != nil {
+		return errors.WrapIf(err, "failed to prepare k8s network")
+	}
+
+	nodePoolsPreparer := NodePoolsPreparer{
+		logger:             p.logger,
+		dataProvider:       clusterCreatorNodePoolPreparerDataProvider{},
+		defaultSSHSecretID: params.SecretID,
+		secretVerifier:     secretVerifier,
+	}
+	if err := nodePoolsPreparer.Prepare(ctx, params.NodePools); err != nil {
+		return errors.WrapIf(err, "failed to prepare node pools")
+	}
+
+	return nil
+}
+
+type clusterCreatorNodePoolPreparerDataProvider struct {
+}
+
+func (p clusterCreatorNodePoolPreparerDataProvider) getExistingNodePools(ctx context.Context) ([]pke.NodePool, error) {
+	return nil, nil
+}
+
+func (p clusterCreatorNodePoolPreparerDataProvider) getExistingNodePoolByName(ctx context.Context, nodePoolName string) (pke.NodePool, error) {
+	return pke.NodePool{}, notExistsYetError{}
+}
+
+// The scripts are run over SSH on the hosts, hence they skip installation on hosts that already joined a cluster
+// to keep retries safe.
+
+const masterScriptTemplate = `#!/bin/sh
+set -e
+
+if [ -f /etc/kubernetes/kubelet.conf ]; then
+  echo "PKE is already installed on this host"
+  exit 0
+fi
+
+export HTTP_PROXY="{{ .HttpProxy }}"
+export HTTPS_PROXY="{{ .HttpsProxy }}"
+export NO_PROXY="{{ .NoProxy }}"
+
+PRIVATE_IP=$(hostname -I | cut -d" " -f 1)
+PUBLIC_ADDRESS="{{ if .PublicAddress }}{{ .PublicAddress }}{{ else }}$PRIVATE_IP{{ end }}"
+
+until curl -fsSL https://banzaicloud.com/downloads/pke/pke-{{ .PKEVersion }} -o /usr/local/bin/pke; do sleep 10; done
+chmod +x /usr/local/bin/pke
+export PATH=$PATH:/usr/local/bin/
+
+pke install master --pipeline-url="{{ .PipelineURL }}" \
+--pipeline-insecure="{{ .PipelineURLInsecure }}" \
+--pipeline-token="{{ .PipelineToken }}" \
+--pipeline-org-id={{ .OrgID }} \
+--pipeline-cluster-id={{ .ClusterID}} \
+--kubernetes-cluster-name={{ .ClusterName }} \
+--pipeline-nodepool={{ .NodePoolName }} \
+--taints={{ .Taints }} \
+--kubernetes-advertise-address=$PRIVATE_IP:6443 \
+--kubernetes-api-server=$PUBLIC_ADDRESS:6443 \
+--kubernetes-infrastructure-cidr=$PRIVATE_IP/32 \
+--kubernetes-version={{ .KubernetesVersion }} \
+--kubernetes-master-mode={{ .KubernetesMasterMode }} \
+--kubernetes-api-server-cert-sans="${PUBLIC_ADDRESS}"{{ if .OIDCIssuerURL }} \
+--kubernetes-oidc-issuer-url="{{ .OIDCIssuerURL }}" \
+--kubernetes-oidc-client-id="{{ .OIDCClientID }}"{{ end }}`
+
+const workerScriptTemplate = `#!/bin/sh
+set -e
+
+if [ -f /etc/kubernetes/kubelet.conf ]; then
+  echo "PKE is already installed on this host"
+  exit 0
+fi
+
+export HTTP_PROXY="{{ .HttpProxy }}"
+export HTTPS_PROXY="{{ .HttpsProxy }}"
+export NO_PROXY="{{ .NoProxy }}"
+
+until curl -fsSL https://banzaicloud.com/downloads/pke/pke-{{ .PKEVersion }} -o /usr/local/bin/pke; do sleep 10; done
+chmod +x /usr/local/bin/pke
+export PATH=$PATH:/usr/local/bin/
+
+PRIVATE_IP=$(hostname -I | cut -d" " -f 1)
+
+pke install worker --pipeline-url="{{ .PipelineURL }}" \
+--pipeline-insecure="{{ .PipelineURLInsecure }}" \
+--pipeline-token="{{ .PipelineToken }}" \
+--pipeline-org-id={{ .OrgID }} \
+--pipeline-cluster-id={{ .ClusterID}} \
+--pipeline-nodepool={{ .NodePoolName }} \
+--taints={{ .Taints }} \
+--kubernetes-api-server={{ .PublicAddress }}:6443 \
+--kubernetes-infrastructure-cidr=$PRIVATE_IP/32 \
+--kubernetes-version={{ .KubernetesVersion }} \
+--kubernetes-pod-network-cidr=""`
@@ -0,0 +1,336 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver/commoncluster"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/workflow"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pipCluster "github.com/banzaicloud/pipeline/src/cluster"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+type ClusterUpdater struct {
+	logger         Logger
+	paramsPreparer ClusterUpdateParamsPreparer
+	config         ClusterConfig
+	organizations  OrganizationStore
+	secrets        ClusterUpdaterSecretStore
+	store          pke.ClusterStore
+	workflowClient client.Client
+}
+
+type ClusterUpdaterSecretStore interface {
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+	GetByName(organizationID uint, secretName string) (*secret.SecretItemResponse, error)
+}
+
+func MakeClusterUpdater(
+	logger Logger,
+	config ClusterConfig,
+	organizations OrganizationStore,
+	secrets ClusterUpdaterSecretStore,
+	store pke.ClusterStore,
+	workflowClient client.Client,
+) ClusterUpdater {
+	return ClusterUpdater{
+		logger: logger,
+		paramsPreparer: ClusterUpdateParamsPreparer{
+			logger:  logger,
+			secrets: secrets,
+			store:   store,
+		},
+		config:         config,
+		organizations:  organizations,
+		secrets:        secrets,
+		store:          store,
+		workflowClient: workflowClient,
+	}
+}
+
+type PKEOnHostsClusterUpdateParams struct {
+	ClusterID uint
+	NodePools []NodePool
+}
+
+func (cu ClusterUpdater) Update(ctx context.Context, params PKEOnHostsClusterUpdateParams) error {
+	logger := cu.logger.WithFields(map[string]interface{}{"clusterID": params.ClusterID})
+
+	logger.Info("updating cluster")
+
+	if err := cu.paramsPreparer.Prepare(ctx, &params); err != nil {
+		return errors.WrapIf(err, "params preparation failed")
+	}
+
+	cluster, err := cu.store.GetByID(params.ClusterID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get cluster by ID")
+	}
+
+	nodePoolsToCreate, nodePoolsToUpdate, nodePoolsToDelete := sortNodePools(params.NodePools, cluster.NodePools)
+	var nodePoolLabels []pipCluster.NodePoolLabels
+
+	tf := nodeTemplateFactory{
+		ClusterID:                   cluster.ID,
+		ClusterName:                 cluster.Name,
+		KubernetesVersion:           cluster.Kubernetes.Version,
+		NoProxy:                     strings.Join(cluster.HTTPProxy.Exceptions, ","),
+		OrganizationID:              cluster.OrganizationID,
+		PipelineExternalURL:         cu.config.PipelineExternalURL,
+		PipelineExternalURLInsecure: cu.config.PipelineExternalURLInsecure,
+		SingleNodePool:              len(cluster.NodePools) == 1,
+	}
+
+	nodesToCreate := make([]workflow.Node, 0)
+	nodesToDelete := make([]workflow.Node, 0)
+
+	for _, np := range nodePoolsToCreate {
+		nodePool := np.toPke()
+
+		if err := cu.store.CreateNodePool(cluster.ID, nodePool); err != nil {
+			err = errors.WrapIfWithDetails(err, "failed to create new node pool", "clusterID", cluster.ID, "nodePoolName", np.Name)
+			return cu.handleError(cluster.ID, err)
+		}
+
+		for _, host := range nodePool.Hosts {
+			nodesToCreate = append(nodesToCreate, tf.getNode(nodePool, host))
+		}
+
+		nodePoolLabels = append(nodePoolLabels, pipCluster.NodePoolLabels{
+			NodePoolName: np.Name,
+			Existing:     false,
+			CustomLabels: np.Labels,
+		})
+	}
+
+	existingNodePoolSet := make(map[string]pke.NodePool)
+	for _, np := range cluster.NodePools {
+		existingNodePoolSet[np.Name] = np
+	}
+
+	for _, np := range nodePoolsToUpdate {
+		existingNodePool := existingNodePoolSet[np.Name]
+
+		hostsToAdd, hostsToDelete := sortHosts(np.Hosts, existingNodePool.Hosts)
+
+		for _, h := range hostsToAdd {
+			host := h.toPke()
+
+			if err := cu.store.AddHost(cluster.ID, np.Name, host); err != nil {
+				err = errors.WrapIfWithDetails(err, "failed to add host", "clusterID", cluster.ID, "nodePoolName", np.Name, "address", h.Address)
+				return cu.handleError(cluster.ID, err)
+			}
+
+			nodesToCreate = append(nodesToCreate, tf.getNode(existingNodePool, host))
+		}
+
+		for _, host := range hostsToDelete {
+			nodesToDelete = append(nodesToDelete, tf.getNode(existingNodePool, host))
+		}
+
+		nodePoolLabels = append(nodePoolLabels, pipCluster.NodePoolLabels{
+			NodePoolName: np.Name,
+			Existing:     true,
+			CustomLabels: np.Labels,
+		})
+	}
+
+	nodePoolNamesToDelete := make([]string, 0, len(nodePoolsToDelete))
+	for _, np := range nodePoolsToDelete {
+		for _, host := range np.Hosts {
+			nodesToDelete = append(nodesToDelete, tf.getNode(np, host))
+		}
+		nodePoolNamesToDelete = append(nodePoolNamesToDelete, np.Name)
+	}
+
+	org, err := cu.organizations.Get(ctx, cluster.OrganizationID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get organization")
+	}
+
+	var labelsMap map[string]map[string]string
+	{
+		commonCluster, err := commoncluster.MakeCommonClusterGetter(cu.secrets, cu.store).GetByID(cluster.ID)
+		if err != nil {
+			return errors.WrapIf(err, "failed to get PKE on hosts common cluster by ID")
+		}
+
+		labelsMap, err = pipCluster.GetDesiredLabelsForCluster(ctx, commonCluster, nodePoolLabels)
+		if err != nil {
+			return errors.WrapIf(err, "failed to get desired labels for cluster")
+		}
+	}
+
+	input := workflow.UpdateClusterWorkflowInput{
+		ClusterID:         cluster.ID,
+		ClusterName:       cluster.Name,
+		ClusterUID:        cluster.UID,
+		OrganizationID:    cluster.OrganizationID,
+		OrganizationName:  org.Name,
+		K8sSecretID:       cluster.K8sSecretID,
+		APIServerAddress:  cluster.GetAPIServerAddress(),
+		NodesToCreate:     nodesToCreate,
+		NodesToDelete:     nodesToDelete,
+		NodePoolsToDelete: nodePoolNamesToDelete,
+		HTTPProxy:         cluster.HTTPProxy,
+		NodePoolLabels:    labelsMap,
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour, // installation on the hosts may include package downloads
+	}
+
+	if err := cu.store.SetStatus(cluster.ID, pkgCluster.Updating, pkgCluster.UpdatingMessage); err != nil {
+		return errors.WrapIf(err, "failed to set cluster status")
+	}
+
+	wfexec, err := cu.workflowClient.StartWorkflow(ctx, workflowOptions, workflow.UpdateClusterWorkflowName, input)
+	if err := errors.WrapIfWithDetails(err, "failed to start workflow", "workflow", workflow.UpdateClusterWorkflowName); err != nil {
+		_ = cu.handleError(cluster.ID, err)
+		return err
+	}
+
+	if err := cu.store.SetActiveWorkflowID(cluster.ID, wfexec.ID); err != nil {
+		err = errors.WrapIfWithDetails(err, "failed to set active workflow ID", "clusterID", cluster.ID, "workflowID", wfexec.ID)
+		_ = cu.handleError(cluster.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (cu ClusterUpdater) handleError(clusterID uint, err error) error {
+	return handleClusterError(cu.logger, cu.store, pkgCluster.Warning, clusterID, err)
+}
+
+func sortNodePools(incoming []NodePool, clusterNodePools []pke.NodePool) (toCreate, toUpdate []NodePool, toDelete []pke.NodePool) {
+	existingNodePoolSet := make(map[string]pke.NodePool)
+	for _, np := range clusterNodePools {
+		existingNodePoolSet[np.Name] = np
+	}
+	for _, np := range incoming {
+		if _, ok := existingNodePoolSet[np.Name]; ok {
+			delete(existingNodePoolSet, np.Name)
+			toUpdate = append(toUpdate, np)
+		} else {
+			toCreate = append(toCreate, np)
+		}
+	}
+	toDelete = make([]pke.NodePool, 0, len(existingNodePoolSet))
+	for _, np := range clusterNodePools {
+		if _, ok := existingNodePoolSet[np.Name]; ok {
+			toDelete = append(toDelete, np)
+		}
+	}
+	return
+}
+
+// sortHosts compares the incoming hosts of a node pool to the existing ones by address
+func sortHosts(incoming []Host, existing []pke.Host) (toAdd []Host, toDelete []pke.Host) {
+	incomingAddresses := make(map[string]bool, len(incoming))
+	for _, h := range incoming {
+		incomingAddresses[h.Address] = true
+	}
+
+	existingAddresses := make(map[string]bool, len(existing))
+	for _, h := range existing {
+		existingAddresses[h.Address] = true
+
+		if !incomingAddresses[h.Address] {
+			toDelete = append(toDelete, h)
+		}
+	}
+
+	for _, h := range incoming {
+		if !existingAddresses[h.Address] {
+			toAdd = append(toAdd, h)
+		}
+	}
+
+	return
+}
+
+type ClusterUpdateParamsPreparer struct {
+	logger  Logger
+	secrets secretGetter
+	store   pke.ClusterStore
+}
+
+func (p ClusterUpdateParamsPreparer) Prepare(ctx context.Context, params *PKEOnHostsClusterUpdateParams) error {
+	if params.ClusterID == 0 {
+		return validationErrorf("ClusterID cannot be 0")
+	}
+	cluster, err := p.store.GetByID(params.ClusterID)
+	if pke.IsNotFound(err) {
+		return validationErrorf("ClusterID must refer to an existing cluster")
+	} else if err != nil {
+		return errors.WrapIf(err, "failed to get cluster by ID")
+	}
+
+	nodePoolsPreparer := NodePoolsPreparer{
+		logger: p.logger,
+		dataProvider: clusterUpdaterNodePoolPreparerDataProvider{
+			cluster: cluster,
+		},
+		defaultSSHSecretID: cluster.SecretID,
+		secretVerifier:     newSSHSecretVerifier(cluster.OrganizationID, p.secrets),
+	}
+	if err := nodePoolsPreparer.Prepare(ctx, params.NodePools); err != nil {
+		return errors.WrapIf(err, "failed to prepare node pools")
+	}
+
+	// hosts have to be removed from a node pool before they can be added to another one
+	existingHostPools := make(map[string]string)
+	for _, np := range cluster.NodePools {
+		for _, h := range np.Hosts {
+			existingHostPools[h.Address] = np.Name
+		}
+	}
+	for _, np := range params.NodePools {
+		for _, h := range np.Hosts {
+			if existing, ok := existingHostPools[h.Address]; ok && existing != np.Name {
+				return validationErrorf("host %q already belongs to node pool %q", h.Address, existing)
+			}
+		}
+	}
+
+	return nil
+}
+
+type clusterUpdaterNodePoolPreparerDataProvider struct {
+	cluster pke.PKEOnHostsCluster
+}
+
+func (p clusterUpdaterNodePoolPreparerDataProvider) getExistingNodePools(ctx context.Context) ([]pke.NodePool, error) {
+	return p.cluster.NodePools, nil
+}
+
+func (p clusterUpdaterNodePoolPreparerDataProvider) getExistingNodePoolByName(ctx context.Context, nodePoolName string) (pke.NodePool, error) {
+	for _, np := range p.cluster.NodePools {
+		if np.Name == nodePoolName {
+			return np, nil
+		}
+	}
+	return pke.NodePool{}, notExistsYetError{}
+}
@@ -0,0 +1,165 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+func TestSortHosts(t *testing.T) {
+	existing := []pke.Host{
+		{Address: "10.0.0.1", NodeName: "node-1"},
+		{Address: "10.0.0.2", NodeName: "node-2"},
+	}
+	incoming := []Host{
+		{Address: "10.0.0.2"},
+		{Address: "10.0.0.3"},
+	}
+
+	toAdd, toDelete := sortHosts(incoming, existing)
+
+	assert.Equal(t, []Host{{Address: "10.0.0.3"}}, toAdd)
+	assert.Equal(t, []pke.Host{{Address: "10.0.0.1", NodeName: "node-1"}}, toDelete)
+}
+
+func TestSortNodePools(t *testing.T) {
+	existing := []pke.NodePool{
+		{Name: "master"},
+		{Name: "workers"},
+	}
+	incoming := []NodePool{
+		{Name: "master"},
+		{Name: "gpu"},
+	}
+
+	toCreate, toUpdate, toDelete := sortNodePools(incoming, existing)
+
+	assert.Equal(t, []NodePool{{Name: "gpu"}}, toCreate)
+	assert.Equal(t, []NodePool{{Name: "master"}}, toUpdate)
+	assert.Equal(t, []pke.NodePool{{Name: "workers"}}, toDelete)
+}
+
+const hostKeyFingerprint = "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error) {
+	secretType, ok := s[secretID]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+
+	return &secret.SecretItemResponse{ID: secretID, Type: secretType}, nil
+}
+
+func TestNodePoolsPreparer_Prepare(t *testing.T) {
+	secrets := staticSecrets{
+		"ssh":   secrettype.SSHSecretType,
+		"other": secrettype.SSHSecretType,
+		"aws":   secrettype.Amazon,
+	}
+
+	preparer := func(cluster pke.PKEOnHostsCluster) NodePoolsPreparer {
+		return NodePoolsPreparer{
+			logger:             common.NoopLogger{},
+			dataProvider:       clusterUpdaterNodePoolPreparerDataProvider{cluster: cluster},
+			defaultSSHSecretID: "ssh",
+			secretVerifier:     newSSHSecretVerifier(1, secrets),
+		}
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		nodePools := []NodePool{
+			{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			{Name: "workers", Hosts: []Host{{Address: "10.0.0.2", Port: 2222, SSHSecretID: "other", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+		}
+
+		err := preparer(pke.PKEOnHostsCluster{}).Prepare(context.Background(), nodePools)
+		require.NoError(t, err)
+
+		assert.Equal(t, Host{Address: "10.0.0.1", Port: 22, SSHSecretID: "ssh", SSHHostKeyFingerprint: hostKeyFingerprint}, nodePools[0].Hosts[0])
+		assert.Equal(t, Host{Address: "10.0.0.2", Port: 2222, SSHSecretID: "other", SSHHostKeyFingerprint: hostKeyFingerprint}, nodePools[1].Hosts[0])
+		assert.Equal(t, []string{"worker"}, nodePools[1].Roles)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		existing := pke.PKEOnHostsCluster{
+			NodePools: []pke.NodePool{
+				{Name: "master", Roles: []string{"master"}, Hosts: []pke.Host{{Address: "10.0.0.1"}}},
+			},
+		}
+
+		tests := map[string][]NodePool{
+			"no master": {
+				{Name: "workers", Hosts: []Host{{Address: "10.0.0.2", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"multiple masters": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+				{Name: "master2", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.2", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"duplicate host": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+				{Name: "workers", Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"empty node pool": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+				{Name: "workers"},
+			},
+			"invalid secret type": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHSecretID: "aws", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"missing host key fingerprint": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1"}}},
+			},
+			"invalid host key fingerprint": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: "MD5:16:27:ac:a5:76:28:2d:36:63:1b:56:4d:eb:df:a6:48"}}},
+			},
+			"changed master hosts": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}, {Address: "10.0.0.2", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"removed master host": {
+				{Name: "master", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.3", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"replaced master node pool": {
+				{Name: "master2", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.2", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+			"removed master role": {
+				{Name: "master", Roles: []string{"worker"}, Hosts: []Host{{Address: "10.0.0.1", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+				{Name: "master2", Roles: []string{"master"}, Hosts: []Host{{Address: "10.0.0.2", SSHHostKeyFingerprint: hostKeyFingerprint}}},
+			},
+		}
+
+		for name, nodePools := range tests {
+			nodePools := nodePools
+
+			t.Run(name, func(t *testing.T) {
+				err := preparer(existing).Prepare(context.Background(), nodePools)
+
+				var validationErr interface{ InputValidationError() bool }
+				assert.True(t, errors.As(err, &validationErr), "expected validation error, got: %v", err)
+			})
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/workflow"
+	pkgPKE "github.com/banzaicloud/pipeline/pkg/cluster/pke"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+)
+
+type Logger = common.Logger
+
+type nodeTemplateFactory struct {
+	ClusterID                   uint
+	ClusterName                 string
+	KubernetesVersion           string
+	OrganizationID              uint
+	PipelineExternalURL         string
+	PipelineExternalURLInsecure bool
+	SingleNodePool              bool
+	OIDCClientID                string
+	OIDCIssuerURL               string
+	NoProxy                     string
+}
+
+func (f nodeTemplateFactory) getNode(np pke.NodePool, host pke.Host) workflow.Node {
+	node := workflow.Node{
+		Host: workflow.Host{
+			Address:               host.Address,
+			Port:                  host.Port,
+			SSHSecretID:           host.SSHSecretID,
+			SSHHostKeyFingerprint: host.SSHHostKeyFingerprint,
+		},
+		NodePoolName:   np.Name,
+		NodeName:       host.NodeName,
+		Master:         np.HasRole(pkgPKE.RoleMaster),
+		Labels:         host.Labels,
+		ScriptTemplate: workerScriptTemplate,
+	}
+
+	k8sMasterMode := "default"
+	taints := ""
+
+	if np.HasRole(pkgPKE.RoleMaster) {
+		if f.SingleNodePool {
+			taints = "," // do not taint single node pool cluster's master node
+		} else {
+			taints = MasterNodeTaint
+		}
+
+		node.ScriptTemplate = masterScriptTemplate
+
+		// master hosts cannot be changed after creation (see NodePoolPreparer), so the mode is final
+		if np.Size() > 1 {
+			k8sMasterMode = "ha"
+		}
+	}
+
+	if np.HasRole(pkgPKE.RolePipelineSystem) {
+		if !f.SingleNodePool {
+			taints = fmt.Sprintf("%s=%s:%s", pkgCommon.NodePoolNameTaintKey, np.Name, corev1.TaintEffectPreferNoSchedule)
+		}
+	}
+
+	// HttpProxy settings and the public address will be set in workflow
+	node.ScriptParams = map[string]string{
+		"ClusterID":            strconv.FormatUint(uint64(f.ClusterID), 10),
+		"ClusterName":          f.ClusterName,
+		"NodePoolName":         np.Name,
+		"Taints":               taints,
+		"OrgID":                strconv.FormatUint(uint64(f.OrganizationID), 10),
+		"PipelineURL":          f.PipelineExternalURL,
+		"PipelineURLInsecure":  strconv.FormatBool(f.PipelineExternalURLInsecure),
+		"PipelineToken":        "<not yet set>",
+		"PKEVersion":           pkeVersion,
+		"KubernetesVersion":    f.KubernetesVersion,
+		"KubernetesMasterMode": k8sMasterMode,
+		"NoProxy":              f.NoProxy,
+		"OIDCIssuerURL":        f.OIDCIssuerURL,
+		"OIDCClientID":         f.OIDCClientID,
+	}
+	return node
+}
+
+func handleClusterError(logger Logger, store pke.ClusterStore, status string, clusterID uint, err error) error {
+	if clusterID != 0 && err != nil {
+		if err := store.SetStatus(clusterID, status, err.Error()); err != nil {
+			logger.Error("failed to set cluster error status: " + err.Error())
+		}
+	}
+	return err
+}
+
+type notExistsYetError struct{}
+
+func (notExistsYetError) Error() string {
+	return "this resource does not exist yet"
+}
+
+func (notExistsYetError) NotFound() bool {
+	return true
+}
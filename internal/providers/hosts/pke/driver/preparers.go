@@ -0,0 +1,331 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+	pkgPKE "github.com/banzaicloud/pipeline/pkg/cluster/pke"
+	"github.com/banzaicloud/pipeline/src/secret"
+)
+
+type secretGetter interface {
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+}
+
+// sshSecretVerifier checks that secrets exist and are of type SSH, remembering the ones already checked
+type sshSecretVerifier struct {
+	organizationID uint
+	secrets        secretGetter
+	verified       map[string]bool
+}
+
+func newSSHSecretVerifier(organizationID uint, secrets secretGetter) *sshSecretVerifier {
+	return &sshSecretVerifier{
+		organizationID: organizationID,
+		secrets:        secrets,
+		verified:       make(map[string]bool),
+	}
+}
+
+func (v *sshSecretVerifier) verify(secretID string) error {
+	if v.verified[secretID] {
+		return nil
+	}
+	s, err := v.secrets.Get(v.organizationID, secretID)
+	if err != nil {
+		return validationErrorf("failed to get secret %s", secretID)
+	}
+	if s.Type != secrettype.SSHSecretType {
+		return validationErrorf("%s should be of type SSH", secretID)
+	}
+	v.verified[secretID] = true
+	return nil
+}
+
+// NodePoolsPreparer implements []NodePool preparation
+type NodePoolsPreparer struct {
+	logger             Logger
+	namespace          string
+	dataProvider       nodePoolsDataProvider
+	defaultSSHSecretID string
+	secretVerifier     *sshSecretVerifier
+}
+
+type nodePoolsDataProvider interface {
+	getExistingNodePools(ctx context.Context) ([]pke.NodePool, error)
+	getExistingNodePoolByName(ctx context.Context, nodePoolName string) (pke.NodePool, error)
+}
+
+func (p NodePoolsPreparer) getNodePoolPreparer(i int) NodePoolPreparer {
+	return NodePoolPreparer{
+		logger:             p.logger,
+		namespace:          fmt.Sprintf("%s[%d]", p.namespace, i),
+		dataProvider:       p.dataProvider,
+		defaultSSHSecretID: p.defaultSSHSecretID,
+		secretVerifier:     p.secretVerifier,
+	}
+}
+
+// Prepare validates and provides defaults for a set of NodePools
+func (p NodePoolsPreparer) Prepare(ctx context.Context, nodePools []NodePool) error {
+	// check incoming node pool list item uniqueness
+	{
+		names := make(map[string]bool)
+		addresses := make(map[string]string)
+		for _, np := range nodePools {
+			if names[np.Name] {
+				return validationErrorf("multiple node pools named %q", np.Name)
+			}
+			names[np.Name] = true
+
+			for _, h := range np.Hosts {
+				if other, ok := addresses[h.Address]; ok {
+					return validationErrorf("host %q is listed in node pool %q and %q", h.Address, other, np.Name)
+				}
+				addresses[h.Address] = np.Name
+			}
+		}
+	}
+
+	masterNodePools := 0
+	for i := range nodePools {
+		np := &nodePools[i]
+
+		if err := p.getNodePoolPreparer(i).Prepare(ctx, np); err != nil {
+			return errors.WrapIf(err, "failed to prepare node pool")
+		}
+
+		if np.hasRole(pkgPKE.RoleMaster) {
+			masterNodePools++
+		}
+	}
+
+	if masterNodePools != 1 {
+		return validationErrorf("exactly one master node pool must be specified")
+	}
+
+	// the master node pool of an existing cluster cannot be replaced by another one
+	existingNodePools, err := p.dataProvider.getExistingNodePools(ctx)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get existing node pools")
+	}
+
+	for _, existing := range existingNodePools {
+		if !existing.HasRole(pkgPKE.RoleMaster) {
+			continue
+		}
+
+		found := false
+		for _, np := range nodePools {
+			if np.Name == existing.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return validationErrorf("master node pool %q cannot be removed", existing.Name)
+		}
+	}
+
+	return nil
+}
+
+// NodePoolPreparer implements NodePool preparation
+type NodePoolPreparer struct {
+	logger       Logger
+	namespace    string
+	dataProvider interface {
+		getExistingNodePoolByName(ctx context.Context, nodePoolName string) (pke.NodePool, error)
+	}
+	defaultSSHSecretID string
+	secretVerifier     *sshSecretVerifier
+}
+
+// Prepare validates and provides defaults for NodePool fields
+func (p NodePoolPreparer) Prepare(ctx context.Context, nodePool *NodePool) error {
+	if nodePool == nil {
+		return nil
+	}
+
+	if nodePool.Name == "" {
+		return validationErrorf("%s.Name must be specified", p.namespace)
+	}
+
+	if len(nodePool.Hosts) == 0 {
+		return validationErrorf("%s.Hosts must contain at least one host", p.namespace)
+	}
+
+	for i := range nodePool.Hosts {
+		if err := p.prepareHost(fmt.Sprintf("%s.Hosts[%d]", p.namespace, i), &nodePool.Hosts[i]); err != nil {
+			return err
+		}
+	}
+
+	np, err := p.dataProvider.getExistingNodePoolByName(ctx, nodePool.Name)
+	if pke.IsNotFound(err) {
+		return p.prepareNewNodePool(ctx, nodePool)
+	} else if err != nil {
+		return errors.WrapIf(err, "failed to get node pool by name")
+	}
+
+	return p.prepareExistingNodePool(ctx, nodePool, np)
+}
+
+func (p NodePoolPreparer) prepareHost(namespace string, host *Host) error {
+	if host.Address == "" {
+		return validationErrorf("%s.Address must be specified", namespace)
+	}
+
+	if host.Port == 0 {
+		host.Port = pke.DefaultSSHPort
+	} else if host.Port < 0 || host.Port > 65535 {
+		return validationErrorf("%s.Port must be a valid port number", namespace)
+	}
+
+	if host.SSHSecretID == "" {
+		host.SSHSecretID = p.defaultSSHSecretID
+	}
+	if host.SSHSecretID == "" {
+		return validationErrorf("%s.SSHSecretID must be specified if the cluster has no SSH secret", namespace)
+	}
+
+	if err := validateHostKeyFingerprint(host.SSHHostKeyFingerprint); err != nil {
+		return validationErrorf("%s.SSHHostKeyFingerprint %s", namespace, err.Error())
+	}
+
+	return p.secretVerifier.verify(host.SSHSecretID)
+}
+
+// validateHostKeyFingerprint checks that a fingerprint has the format printed by ssh-keygen -l
+func validateHostKeyFingerprint(fingerprint string) error {
+	if fingerprint == "" {
+		return errors.New("must be specified")
+	}
+
+	hash := strings.TrimPrefix(fingerprint, "SHA256:")
+	if hash == fingerprint {
+		return errors.New("must be a SHA256 fingerprint")
+	}
+
+	if digest, err := base64.RawStdEncoding.DecodeString(hash); err != nil || len(digest) != sha256.Size {
+		return errors.New("must be a valid SHA256 fingerprint")
+	}
+
+	return nil
+}
+
+func (p NodePoolPreparer) prepareNewNodePool(ctx context.Context, nodePool *NodePool) error {
+	if len(nodePool.Roles) == 0 {
+		nodePool.Roles = []string{"worker"}
+		p.logger.Debug(fmt.Sprintf("%s.Roles not specified, defaulting to %v", p.namespace, nodePool.Roles))
+	}
+
+	return nil
+}
+
+func (p NodePoolPreparer) prepareExistingNodePool(ctx context.Context, nodePool *NodePool, existing pke.NodePool) error {
+	if nodePool.CreatedBy != existing.CreatedBy {
+		if nodePool.CreatedBy != 0 {
+			p.logMismatch("CreatedBy", existing.CreatedBy, nodePool.CreatedBy)
+		}
+		nodePool.CreatedBy = existing.CreatedBy
+	}
+	if !stringSliceSetEqual(nodePool.Roles, existing.Roles) {
+		if nodePool.Roles != nil {
+			if nodePool.hasRole(pkgPKE.RoleMaster) != existing.HasRole(pkgPKE.RoleMaster) {
+				return validationErrorf("%s.Roles cannot add or remove the master role of an existing node pool", p.namespace)
+			}
+			p.logMismatch("Roles", existing.Roles, nodePool.Roles)
+		}
+		nodePool.Roles = existing.Roles
+	}
+
+	// the master mode (single or HA) is decided when the cluster is created,
+	// so master hosts can neither be added nor removed later
+	if existing.HasRole(pkgPKE.RoleMaster) {
+		hostsToAdd, hostsToDelete := sortHosts(nodePool.Hosts, existing.Hosts)
+		if len(hostsToAdd) > 0 {
+			return validationErrorf("%s.Hosts cannot be changed: adding master host %q is not supported", p.namespace, hostsToAdd[0].Address)
+		}
+		if len(hostsToDelete) > 0 {
+			return validationErrorf("%s.Hosts cannot be changed: removing master host %q is not supported", p.namespace, hostsToDelete[0].Address)
+		}
+	}
+
+	return nil
+}
+
+func (p NodePoolPreparer) logMismatch(fieldName string, currentValue, incomingValue interface{}) {
+	p.logger.Warn(fmt.Sprintf("%s.%s does not match existing value", p.namespace, fieldName), map[string]interface{}{"current": currentValue, "incoming": incomingValue})
+}
+
+func stringSliceSetEqual(lhs, rhs []string) bool {
+	lset := make(map[string]bool, len(lhs))
+	for _, e := range lhs {
+		lset[e] = true
+	}
+	if len(lhs) != len(lset) {
+		return false // duplicates in lhs
+	}
+
+	rset := make(map[string]bool, len(rhs))
+	for _, e := range rhs {
+		rset[e] = true
+	}
+	if len(rhs) != len(rset) {
+		return false // duplicates in rhs
+	}
+
+	if len(lset) != len(rset) {
+		return false // different element counts
+	}
+	for e := range lset {
+		if !rset[e] {
+			return false // element in lhs missing from rhs
+		}
+	}
+	return true
+}
+
+type validationError struct {
+	msg string
+}
+
+func validationErrorf(msg string, args ...interface{}) validationError {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return validationError{
+		msg: msg,
+	}
+}
+
+func (e validationError) Error() string {
+	return e.msg
+}
+
+func (e validationError) InputValidationError() bool {
+	return true
+}
@@ -0,0 +1,338 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustersetup"
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	intPKEWorkflow "github.com/banzaicloud/pipeline/internal/pke/workflow"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/banzaicloud/pipeline/pkg/brn"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/src/cluster"
+)
+
+const CreateClusterWorkflowName = "pke-hosts-create-cluster"
+
+// CreateClusterWorkflowInput
+type CreateClusterWorkflowInput struct {
+	ClusterID        uint
+	ClusterName      string
+	ClusterUID       string
+	OrganizationID   uint
+	OrganizationName string
+	OIDCEnabled      bool
+	APIServerAddress string
+	Nodes            []Node
+	HTTPProxy        intPKE.HTTPProxy
+	NodePoolLabels   map[string]map[string]string
+}
+
+func CreateClusterWorkflow(ctx workflow.Context, input CreateClusterWorkflowInput) error {
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	// Generate CA certificates
+	{
+		activityInput := pkeworkflow.GenerateCertificatesActivityInput{ClusterID: input.ClusterID}
+
+		err := workflow.ExecuteActivity(ctx, pkeworkflow.GenerateCertificatesActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	// Create dex client for the cluster
+	if input.OIDCEnabled {
+		activityInput := pkeworkflow.CreateDexClientActivityInput{
+			ClusterID: input.ClusterID,
+		}
+		err := workflow.ExecuteActivity(ctx, pkeworkflow.CreateDexClientActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	httpProxy, err := assembleHTTPProxySettings(ctx, input.OrganizationID, input.HTTPProxy)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	var masters, workers []Node
+	for _, node := range input.Nodes {
+		if node.Master {
+			masters = append(masters, node)
+		} else {
+			workers = append(workers, node)
+		}
+	}
+
+	installInput := installNodesInput{
+		OrganizationID:   input.OrganizationID,
+		ClusterID:        input.ClusterID,
+		ClusterName:      input.ClusterName,
+		APIServerAddress: input.APIServerAddress,
+		HTTPProxy:        httpProxy,
+	}
+
+	// Install PKE on master hosts
+	masterNodeNames, err := installNodes(ctx, installInput, masters)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	_ = setClusterStatus(ctx, input.ClusterID, pkgCluster.Creating, "waiting for Kubernetes master") // nolint: errcheck
+
+	// Join worker hosts
+	workerNodeNames, err := installNodes(ctx, installInput, workers)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	if err := waitForMasterReadySignal(ctx, 1*time.Hour); err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	var configSecretID string
+	{
+		activityInput := cluster.DownloadK8sConfigActivityInput{
+			ClusterID: input.ClusterID,
+		}
+		future := workflow.ExecuteActivity(ctx, cluster.DownloadK8sConfigActivityName, activityInput)
+		if err := future.Get(ctx, &configSecretID); err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	{
+		workflowInput := clustersetup.WorkflowInput{
+			ConfigSecretID: brn.New(input.OrganizationID, brn.SecretResourceType, configSecretID).String(),
+			Cluster: clustersetup.Cluster{
+				ID:   input.ClusterID,
+				UID:  input.ClusterUID,
+				Name: input.ClusterName,
+			},
+			Organization: clustersetup.Organization{
+				ID:   input.OrganizationID,
+				Name: input.OrganizationName,
+			},
+			NodePoolLabels: input.NodePoolLabels,
+		}
+
+		future := workflow.ExecuteChildWorkflow(ctx, clustersetup.WorkflowName, workflowInput)
+		if err := future.Get(ctx, nil); err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	nodeNames := make(map[string]string, len(masterNodeNames)+len(workerNodeNames))
+	for address, name := range masterNodeNames {
+		nodeNames[address] = name
+	}
+	for address, name := range workerNodeNames {
+		nodeNames[address] = name
+	}
+
+	if err := labelNodes(ctx, input.OrganizationID, input.ClusterName, configSecretID, input.Nodes, nodeNames); err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	postHookWorkflowInput := cluster.RunPostHooksWorkflowInput{
+		ClusterID: input.ClusterID,
+		PostHooks: cluster.BuildWorkflowPostHookFunctions(nil, true),
+	}
+
+	err = workflow.ExecuteChildWorkflow(ctx, cluster.RunPostHooksWorkflowName, postHookWorkflowInput).Get(ctx, nil)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	return nil
+}
+
+func defaultActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    10 * time.Minute,
+		ScheduleToCloseTimeout: 15 * time.Minute,
+		WaitForCancellation:    true,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          2 * time.Second,
+			BackoffCoefficient:       1.5,
+			MaximumInterval:          30 * time.Second,
+			MaximumAttempts:          5,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic"},
+		},
+	}
+}
+
+// installActivityOptions returns the activity options for running PKE installation on hosts,
+// which downloads packages and images, hence takes considerably longer than other activities.
+func installActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		ScheduleToStartTimeout: 5 * time.Minute,
+		StartToCloseTimeout:    30 * time.Minute,
+		ScheduleToCloseTimeout: 95 * time.Minute,
+		WaitForCancellation:    true,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          10 * time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          time.Minute,
+			MaximumAttempts:          3,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic"},
+		},
+	}
+}
+
+func assembleHTTPProxySettings(ctx workflow.Context, organizationID uint, proxy intPKE.HTTPProxy) (intPKEWorkflow.HTTPProxy, error) {
+	activityInput := intPKEWorkflow.AssembleHTTPProxySettingsActivityInput{
+		OrganizationID:     organizationID,
+		HTTPProxyHostPort:  getHostPort(proxy.HTTP),
+		HTTPProxySecretID:  proxy.HTTP.SecretID,
+		HTTPProxyScheme:    proxy.HTTP.Scheme,
+		HTTPSProxyHostPort: getHostPort(proxy.HTTPS),
+		HTTPSProxySecretID: proxy.HTTPS.SecretID,
+		HTTPSProxyScheme:   proxy.HTTPS.Scheme,
+	}
+	var output intPKEWorkflow.AssembleHTTPProxySettingsActivityOutput
+	err := workflow.ExecuteActivity(ctx, intPKEWorkflow.AssembleHTTPProxySettingsActivityName, activityInput).Get(ctx, &output)
+
+	return output.Settings, err
+}
+
+type installNodesInput struct {
+	OrganizationID   uint
+	ClusterID        uint
+	ClusterName      string
+	APIServerAddress string
+	HTTPProxy        intPKEWorkflow.HTTPProxy
+}
+
+// installNodes installs PKE on the given hosts in parallel and returns the node names by host address
+func installNodes(ctx workflow.Context, input installNodesInput, nodes []Node) (map[string]string, error) {
+	ctx = workflow.WithActivityOptions(ctx, installActivityOptions())
+
+	futures := make(map[string]workflow.Future, len(nodes))
+
+	for _, node := range nodes {
+		params := make(map[string]string, len(node.ScriptParams)+3)
+		for k, v := range node.ScriptParams {
+			params[k] = v
+		}
+		if params["PublicAddress"] == "" {
+			params["PublicAddress"] = input.APIServerAddress
+		}
+		params["HttpProxy"] = input.HTTPProxy.HTTPProxyURL
+		params["HttpsProxy"] = input.HTTPProxy.HTTPSProxyURL
+		node.ScriptParams = params
+
+		activityInput := InstallNodeActivityInput{
+			OrganizationID: input.OrganizationID,
+			ClusterID:      input.ClusterID,
+			ClusterName:    input.ClusterName,
+			Node:           node,
+		}
+		futures[node.Address] = workflow.ExecuteActivity(ctx, InstallNodeActivityName, activityInput)
+	}
+
+	nodeNames := make(map[string]string, len(nodes))
+	errs := []error{}
+
+	for address, future := range futures {
+		var nodeName string
+		errs = append(errs, errors.WrapIff(future.Get(ctx, &nodeName), "installing PKE on host %q", address))
+		nodeNames[address] = nodeName
+	}
+
+	return nodeNames, errors.Combine(errs...)
+}
+
+// labelNodes applies the host specific labels to the nodes of the hosts
+func labelNodes(ctx workflow.Context, organizationID uint, clusterName string, k8sSecretID string, nodes []Node, nodeNames map[string]string) error {
+	futures := make(map[string]workflow.Future)
+
+	for _, node := range nodes {
+		if len(node.Labels) == 0 {
+			continue
+		}
+
+		activityInput := LabelK8sNodeActivityInput{
+			OrganizationID: organizationID,
+			ClusterName:    clusterName,
+			K8sSecretID:    k8sSecretID,
+			Name:           nodeNames[node.Address],
+			Labels:         node.Labels,
+		}
+		futures[node.Address] = workflow.ExecuteActivity(ctx, LabelK8sNodeActivityName, activityInput)
+	}
+
+	errs := []error{}
+
+	for address, future := range futures {
+		errs = append(errs, errors.WrapIff(future.Get(ctx, nil), "labeling node of host %q", address))
+	}
+
+	return errors.Combine(errs...)
+}
+
+func getHostPort(o intPKE.HTTPProxyOptions) string {
+	if o.Host == "" {
+		return ""
+	}
+	if o.Port == 0 {
+		return o.Host
+	}
+	return net.JoinHostPort(o.Host, strconv.FormatUint(uint64(o.Port), 10))
+}
+
+func waitForMasterReadySignal(ctx workflow.Context, timeout time.Duration) error {
+	signalName := "master-ready"
+	signalChan := workflow.GetSignalChannel(ctx, signalName)
+	signalTimeoutTimer := workflow.NewTimer(ctx, timeout)
+	signalTimeout := false
+
+	signalSelector := workflow.NewSelector(ctx).AddReceive(signalChan, func(c workflow.Channel, more bool) {
+		c.Receive(ctx, nil)
+		workflow.GetLogger(ctx).Info("Received signal!", zap.String("signal", signalName))
+	}).AddFuture(signalTimeoutTimer, func(workflow.Future) {
+		signalTimeout = true
+	})
+
+	signalSelector.Select(ctx) // wait for signal
+
+	if signalTimeout {
+		return fmt.Errorf("timeout while waiting for %q signal", signalName)
+	}
+	return nil
+}
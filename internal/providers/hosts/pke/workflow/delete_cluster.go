@@ -0,0 +1,137 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"fmt"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/workflow"
+
+	intClusterWorkflow "github.com/banzaicloud/pipeline/internal/cluster/workflow"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+const DeleteClusterWorkflowName = "pke-hosts-delete-cluster"
+
+// DeleteClusterWorkflowInput
+type DeleteClusterWorkflowInput struct {
+	ClusterID      uint
+	ClusterName    string
+	ClusterUID     string
+	K8sSecretID    string
+	OrganizationID uint
+	MasterNodes    []Node
+	Nodes          []Node
+	Forced         bool
+}
+
+// DeleteClusterWorkflow removes Kubernetes from the hosts of the cluster. The hosts themselves are left intact.
+func DeleteClusterWorkflow(ctx workflow.Context, input DeleteClusterWorkflowInput) error {
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	// delete k8s resources
+	if input.K8sSecretID != "" {
+		wfInput := intClusterWorkflow.DeleteK8sResourcesWorkflowInput{
+			OrganizationID: input.OrganizationID,
+			ClusterName:    input.ClusterName,
+			K8sSecretID:    input.K8sSecretID,
+		}
+		if err := workflow.ExecuteChildWorkflow(ctx, intClusterWorkflow.DeleteK8sResourcesWorkflowName, wfInput).Get(ctx, nil); err != nil {
+			if input.Forced {
+				logger.Errorw("deleting k8s resources failed", "error", err)
+			} else {
+				_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+				return err
+			}
+		}
+	}
+
+	// Reset worker hosts first, then the masters
+	for _, nodes := range [][]Node{input.Nodes, input.MasterNodes} {
+		futures := make(map[string]workflow.Future, len(nodes))
+
+		for _, node := range nodes {
+			activityInput := ResetNodeActivityInput{
+				OrganizationID: input.OrganizationID,
+				ClusterName:    input.ClusterName,
+				Host:           node.Host,
+			}
+			futures[node.Address] = workflow.ExecuteActivity(ctx, ResetNodeActivityName, activityInput)
+		}
+
+		errs := []error{}
+
+		for address, future := range futures {
+			errs = append(errs, errors.WrapIff(future.Get(ctx, nil), "resetting host %q", address))
+		}
+
+		if err := errors.Combine(errs...); err != nil {
+			if input.Forced {
+				logger.Errorw("reset host failed", "error", err)
+			} else {
+				_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+				return err
+			}
+		}
+	}
+
+	// delete unused secrets
+	{
+		activityInput := intClusterWorkflow.DeleteUnusedClusterSecretsActivityInput{
+			OrganizationID: input.OrganizationID,
+			ClusterUID:     input.ClusterUID,
+		}
+		if err := workflow.ExecuteActivity(ctx, intClusterWorkflow.DeleteUnusedClusterSecretsActivityName, activityInput).Get(ctx, nil); err != nil {
+			_ = setClusterStatus(ctx, input.ClusterID, pkgCluster.Warning, fmt.Sprintf("failed to delete unused cluster secrets: %v", err)) // nolint: errcheck
+		}
+	}
+
+	// remove dex client (if we created it)
+	{
+		deleteDexClientActivityInput := &pkeworkflow.DeleteDexClientActivityInput{
+			ClusterID: input.ClusterID,
+		}
+		if err := workflow.ExecuteActivity(ctx, pkeworkflow.DeleteDexClientActivityName, deleteDexClientActivityInput).Get(ctx, nil); err != nil {
+			if input.Forced {
+				logger.Errorw("delete dex client failed", "error", err)
+			} else {
+				_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+				return err
+			}
+		}
+	}
+
+	// delete cluster from data store
+	{
+		activityInput := DeleteClusterFromStoreActivityInput{
+			ClusterID: input.ClusterID,
+		}
+		err := workflow.ExecuteActivity(ctx, DeleteClusterFromStoreActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			if input.Forced {
+				logger.Errorw("delete cluster from data store", "error", err)
+			} else {
+				_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
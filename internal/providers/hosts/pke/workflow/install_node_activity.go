@@ -0,0 +1,132 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow/pkeworkflowadapter"
+)
+
+// InstallNodeActivityName is the default registration name of the activity
+const InstallNodeActivityName = "pke-hosts-install-node"
+
+// InstallNodeActivity represents an activity for installing PKE on a host over SSH
+type InstallNodeActivity struct {
+	sshConnector   SSHConnector
+	tokenGenerator pkeworkflowadapter.TokenGenerator
+	store          pke.ClusterStore
+}
+
+// MakeInstallNodeActivity returns a new InstallNodeActivity
+func MakeInstallNodeActivity(sshConnector SSHConnector, tokenGenerator pkeworkflowadapter.TokenGenerator, store pke.ClusterStore) InstallNodeActivity {
+	return InstallNodeActivity{
+		sshConnector:   sshConnector,
+		tokenGenerator: tokenGenerator,
+		store:          store,
+	}
+}
+
+// InstallNodeActivityInput represents the input needed for executing an InstallNodeActivity
+type InstallNodeActivityInput struct {
+	OrganizationID uint
+	ClusterID      uint
+	ClusterName    string
+	Node
+}
+
+// Node represents a host of a node pool
+type Node struct {
+	Host
+	NodePoolName   string
+	NodeName       string
+	Master         bool
+	Labels         map[string]string
+	ScriptParams   map[string]string
+	ScriptTemplate string
+}
+
+// Execute performs the activity and returns the name of the Kubernetes node
+func (a InstallNodeActivity) Execute(ctx context.Context, input InstallNodeActivityInput) (string, error) {
+	logger := activity.GetLogger(ctx).Sugar().With(
+		"organization", input.OrganizationID,
+		"cluster", input.ClusterName,
+		"host", input.Address,
+	)
+
+	_, token, err := a.tokenGenerator.GenerateClusterToken(input.OrganizationID, input.ClusterID)
+	if err != nil {
+		return "", errors.WrapIf(err, "failed to generate cluster token")
+	}
+
+	params := make(map[string]string, len(input.ScriptParams)+1)
+	for k, v := range input.ScriptParams {
+		params[k] = v
+	}
+	params["PipelineToken"] = token
+
+	script, err := renderScript(input.Address, input.ScriptTemplate, params)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := a.sshConnector.Connect(ctx, input.OrganizationID, input.Host)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	logger.Info("installing PKE")
+
+	if _, err := client.Run(ctx, "sh -s", script); err != nil {
+		return "", errors.WrapIf(err, "failed to install PKE")
+	}
+
+	hostname, err := client.Run(ctx, "hostname", "")
+	if err != nil {
+		return "", errors.WrapIf(err, "failed to get host name")
+	}
+
+	// kubelet registers the node with the lower case host name
+	nodeName := strings.ToLower(strings.TrimSpace(hostname))
+
+	if err := a.store.SetHostNodeName(input.ClusterID, input.Address, nodeName); err != nil {
+		return "", errors.WrapIf(err, "failed to save node name")
+	}
+
+	logger.Infow("PKE installed", "node", nodeName)
+
+	return nodeName, nil
+}
+
+func renderScript(name string, scriptTemplate string, params map[string]string) (string, error) {
+	tmpl, err := template.New(name + "Script").Parse(scriptTemplate)
+	if err != nil {
+		return "", errors.WrapIf(err, "failed to parse script template")
+	}
+
+	var script strings.Builder
+	if err := tmpl.Execute(&script, params); err != nil {
+		return "", errors.WrapIf(err, "failed to execute script template")
+	}
+
+	return script.String(), nil
+}
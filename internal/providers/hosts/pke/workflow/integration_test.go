@@ -0,0 +1,116 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+)
+
+func TestIntegration(t *testing.T) {
+	if m := flag.Lookup("test.run").Value.String(); m == "" || !regexp.MustCompile(m).MatchString(t.Name()) {
+		t.Skip("skipping as execution was not requested explicitly using go test -run")
+	}
+
+	t.Run("SSH", testIntegrationSSH)
+}
+
+// testIntegrationSSH checks that commands can be run with root privileges on a host over SSH.
+//
+// Generate a key pair, start the sshd service from docker-compose.override.yml.dist and run the test with:
+//
+//	ssh-keygen -t rsa -N "" -f .docker/volumes/sshd/test_key
+//	SSH_HOST=127.0.0.1 SSH_PORT=2222 SSH_USER=pke SSH_PRIVATE_KEY_FILE=.docker/volumes/sshd/test_key \
+//	SSH_HOST_KEY_FINGERPRINT=$(ssh-keyscan -p 2222 127.0.0.1 2>/dev/null | ssh-keygen -lf - | awk 'NR==1 {print $2}') \
+//	go test -run ^TestIntegration$ ./internal/providers/hosts/pke/workflow/
+//
+// Any VM accepting the key works the same way.
+func testIntegrationSSH(t *testing.T) {
+	host := os.Getenv("SSH_HOST")
+	if host == "" {
+		t.Skip("SSH_HOST is not set")
+	}
+
+	port := 22
+	if p := os.Getenv("SSH_PORT"); p != "" {
+		var err error
+		port, err = strconv.Atoi(p)
+		require.NoError(t, err)
+	}
+
+	privateKey, err := ioutil.ReadFile(os.Getenv("SSH_PRIVATE_KEY_FILE"))
+	require.NoError(t, err)
+
+	connector := NewSSHConnector(staticSecretStore{
+		"ssh": {
+			secrettype.User:           os.Getenv("SSH_USER"),
+			secrettype.PrivateKeyData: string(privateKey),
+		},
+	})
+
+	ctx := context.Background()
+
+	client, err := connector.Connect(ctx, 1, Host{Address: host, Port: port, SSHSecretID: "ssh", SSHHostKeyFingerprint: os.Getenv("SSH_HOST_KEY_FINGERPRINT")})
+	require.NoError(t, err)
+	defer client.Close()
+
+	out, err := client.Run(ctx, "sh -s", "id -u\n")
+	require.NoError(t, err)
+	assert.Equal(t, "0", strings.TrimSpace(out))
+
+	hostname, err := client.Run(ctx, "hostname", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, strings.TrimSpace(hostname))
+
+	_, err = client.Run(ctx, "sh -s", resetScript)
+	require.NoError(t, err)
+
+	_, err = client.Run(ctx, "sh -s", "exit 3\n")
+	assert.Error(t, err)
+}
+
+type staticSecretStore map[string]map[string]string
+
+func (s staticSecretStore) GetSecret(organizationID uint, secretID string) (pkeworkflow.Secret, error) {
+	values, ok := s[secretID]
+	if !ok {
+		return nil, errors.Errorf("secret %q not found", secretID)
+	}
+
+	return staticSecret(values), nil
+}
+
+type staticSecret map[string]string
+
+func (s staticSecret) GetValues() map[string]string {
+	return s
+}
+
+func (s staticSecret) ValidateSecretType(t string) error {
+	return nil
+}
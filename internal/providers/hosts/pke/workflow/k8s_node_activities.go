@@ -0,0 +1,125 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/activity"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+)
+
+const DeleteK8sNodeActivityName = "pke-hosts-delete-k8s-node"
+
+const LabelK8sNodeActivityName = "pke-hosts-label-k8s-node"
+
+type K8sConfigGetter interface {
+	Get(organizationID uint, k8sSecretID string) ([]byte, error)
+}
+
+type DeleteK8sNodeActivityInput struct {
+	OrganizationID uint
+	ClusterName    string
+	K8sSecretID    string
+	Name           string
+}
+
+type DeleteK8sNodeActivity struct {
+	k8sConfigGetter K8sConfigGetter
+}
+
+func MakeDeleteK8sNodeActivity(k8sConfigGetter K8sConfigGetter) DeleteK8sNodeActivity {
+	return DeleteK8sNodeActivity{
+		k8sConfigGetter: k8sConfigGetter,
+	}
+}
+
+func (a DeleteK8sNodeActivity) Execute(ctx context.Context, input DeleteK8sNodeActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With(
+		"organization", input.OrganizationID,
+		"cluster", input.ClusterName,
+		"node", input.Name,
+	)
+
+	k8sConfig, err := a.k8sConfigGetter.Get(input.OrganizationID, input.K8sSecretID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(k8sConfig)
+	if err != nil {
+		return err
+	}
+
+	err = client.CoreV1().Nodes().Delete(input.Name, &metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		logger.Info("node already deleted from Kubernetes")
+		return nil
+	}
+
+	return errors.WrapIff(err, "failed to delete node %q", input.Name)
+}
+
+type LabelK8sNodeActivityInput struct {
+	OrganizationID uint
+	ClusterName    string
+	K8sSecretID    string
+	Name           string
+	Labels         map[string]string
+}
+
+type LabelK8sNodeActivity struct {
+	k8sConfigGetter K8sConfigGetter
+}
+
+func MakeLabelK8sNodeActivity(k8sConfigGetter K8sConfigGetter) LabelK8sNodeActivity {
+	return LabelK8sNodeActivity{
+		k8sConfigGetter: k8sConfigGetter,
+	}
+}
+
+// Execute adds the host specific labels to a node
+func (a LabelK8sNodeActivity) Execute(ctx context.Context, input LabelK8sNodeActivityInput) error {
+	k8sConfig, err := a.k8sConfigGetter.Get(input.OrganizationID, input.K8sSecretID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(k8sConfig)
+	if err != nil {
+		return err
+	}
+
+	// the node may not be registered yet right after joining, the activity is retried in that case
+	node, err := client.CoreV1().Nodes().Get(input.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.WrapIff(err, "failed to get node %q", input.Name)
+	}
+
+	if node.Labels == nil {
+		node.Labels = make(map[string]string, len(input.Labels))
+	}
+	for k, v := range input.Labels {
+		node.Labels[k] = v
+	}
+
+	_, err = client.CoreV1().Nodes().Update(node)
+
+	return errors.WrapIff(err, "failed to update node %q", input.Name)
+}
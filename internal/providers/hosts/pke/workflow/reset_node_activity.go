@@ -0,0 +1,78 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/activity"
+)
+
+// ResetNodeActivityName is the default registration name of the activity
+const ResetNodeActivityName = "pke-hosts-reset-node"
+
+// resetScript removes Kubernetes and PKE from a host, leaving the host itself intact.
+const resetScript = `#!/bin/sh
+export PATH=$PATH:/usr/local/bin/
+
+if command -v kubeadm >/dev/null 2>&1; then
+  kubeadm reset --force
+fi
+
+systemctl disable --now kubelet >/dev/null 2>&1 || true
+
+rm -rf /etc/kubernetes /var/lib/kubelet /var/lib/etcd /etc/cni/net.d /var/lib/cni /usr/local/bin/pke
+`
+
+// ResetNodeActivity represents an activity for removing PKE from a host over SSH
+type ResetNodeActivity struct {
+	sshConnector SSHConnector
+}
+
+// MakeResetNodeActivity returns a new ResetNodeActivity
+func MakeResetNodeActivity(sshConnector SSHConnector) ResetNodeActivity {
+	return ResetNodeActivity{
+		sshConnector: sshConnector,
+	}
+}
+
+// ResetNodeActivityInput represents the input needed for executing a ResetNodeActivity
+type ResetNodeActivityInput struct {
+	OrganizationID uint
+	ClusterName    string
+	Host
+}
+
+// Execute performs the activity
+func (a ResetNodeActivity) Execute(ctx context.Context, input ResetNodeActivityInput) error {
+	logger := activity.GetLogger(ctx).Sugar().With(
+		"organization", input.OrganizationID,
+		"cluster", input.ClusterName,
+		"host", input.Address,
+	)
+
+	client, err := a.sshConnector.Connect(ctx, input.OrganizationID, input.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	logger.Info("resetting host")
+
+	_, err = client.Run(ctx, "sh -s", resetScript)
+
+	return errors.WrapIf(err, "failed to reset host")
+}
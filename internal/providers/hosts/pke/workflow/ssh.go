@@ -0,0 +1,186 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+)
+
+// SSHClient executes commands on a remote host.
+type SSHClient interface {
+	// Run executes a command with the given standard input and returns its standard output.
+	// Commands are executed with root privileges.
+	Run(ctx context.Context, command string, stdin string) (string, error)
+
+	// Close closes the connection.
+	Close() error
+}
+
+// SSHConnector opens SSH connections to hosts.
+type SSHConnector interface {
+	Connect(ctx context.Context, organizationID uint, host Host) (SSHClient, error)
+}
+
+// Host represents an existing machine accessible over SSH.
+type Host struct {
+	Address     string
+	Port        int
+	SSHSecretID string
+
+	// SSHHostKeyFingerprint is the SHA256 fingerprint of the host key the host must present.
+	SSHHostKeyFingerprint string
+}
+
+// NewSSHConnector returns an SSHConnector that authenticates with keys stored in SSH secrets.
+func NewSSHConnector(secrets pkeworkflow.SecretStore) SSHConnector {
+	return sshConnector{
+		secrets: secrets,
+		timeout: 30 * time.Second,
+	}
+}
+
+type sshConnector struct {
+	secrets pkeworkflow.SecretStore
+	timeout time.Duration
+}
+
+func (c sshConnector) Connect(ctx context.Context, organizationID uint, host Host) (SSHClient, error) {
+	s, err := c.secrets.GetSecret(organizationID, host.SSHSecretID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get SSH secret")
+	}
+	if err := s.ValidateSecretType(secrettype.SSHSecretType); err != nil {
+		return nil, err
+	}
+	values := s.GetValues()
+
+	signer, err := ssh.ParsePrivateKey([]byte(values[secrettype.PrivateKeyData]))
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to parse SSH private key")
+	}
+
+	user := values[secrettype.User]
+	if user == "" {
+		user = "root"
+	}
+
+	config := ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeys(signer),
+		},
+		HostKeyCallback: hostKeyCallback(host.SSHHostKeyFingerprint),
+		Timeout:         c.timeout,
+	}
+
+	client, err := ssh.Dial("tcp", host.hostPort(), &config)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to connect to host", "host", host.Address)
+	}
+
+	return sshClient{
+		client: client,
+		sudo:   user != "root",
+	}, nil
+}
+
+// hostKeyCallback accepts only host keys with the given SHA256 fingerprint.
+func hostKeyCallback(fingerprint string) ssh.HostKeyCallback {
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		if fingerprint == "" {
+			return errors.NewWithDetails("no host key fingerprint is known for host", "host", hostname)
+		}
+
+		actual := ssh.FingerprintSHA256(key)
+		if subtle.ConstantTimeCompare([]byte(actual), []byte(fingerprint)) != 1 {
+			return errors.NewWithDetails("host key fingerprint mismatch", "host", hostname, "fingerprint", actual)
+		}
+
+		return nil
+	}
+}
+
+func (h Host) hostPort() string {
+	port := h.Port
+	if port == 0 {
+		port = pke.DefaultSSHPort
+	}
+
+	return net.JoinHostPort(h.Address, strconv.Itoa(port))
+}
+
+type sshClient struct {
+	client *ssh.Client
+	sudo   bool
+}
+
+func (c sshClient) Run(ctx context.Context, command string, stdin string) (string, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return "", errors.WrapIf(err, "failed to open SSH session")
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = strings.NewReader(stdin)
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	if c.sudo {
+		command = "sudo -n " + command
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = session.Close()
+		case <-done:
+		}
+	}()
+
+	if err := session.Run(command); err != nil {
+		return stdout.String(), errors.WrapIfWithDetails(err, "failed to run command", "stderr", tail(stderr.String(), 2048))
+	}
+
+	return stdout.String(), nil
+}
+
+func (c sshClient) Close() error {
+	return c.client.Close()
+}
+
+// tail returns the last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[len(s)-n:]
+}
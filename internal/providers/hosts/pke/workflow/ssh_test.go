@@ -0,0 +1,43 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestHostKeyCallback(t *testing.T) {
+	newKey := func(t *testing.T) ssh.PublicKey {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		key, err := ssh.NewPublicKey(publicKey)
+		require.NoError(t, err)
+
+		return key
+	}
+
+	key := newKey(t)
+
+	assert.NoError(t, hostKeyCallback(ssh.FingerprintSHA256(key))("10.0.0.1:22", nil, key))
+	assert.Error(t, hostKeyCallback(ssh.FingerprintSHA256(newKey(t)))("10.0.0.1:22", nil, key), "other host key")
+	assert.Error(t, hostKeyCallback("")("10.0.0.1:22", nil, key), "unknown host key")
+}
@@ -0,0 +1,130 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+const SetClusterStatusActivityName = "pke-hosts-set-cluster-status"
+
+type SetClusterStatusActivity struct {
+	store pke.ClusterStore
+}
+
+func MakeSetClusterStatusActivity(store pke.ClusterStore) SetClusterStatusActivity {
+	return SetClusterStatusActivity{
+		store: store,
+	}
+}
+
+type SetClusterStatusActivityInput struct {
+	ClusterID     uint
+	Status        string
+	StatusMessage string
+}
+
+func (a SetClusterStatusActivity) Execute(ctx context.Context, input SetClusterStatusActivityInput) error {
+	return a.store.SetStatus(input.ClusterID, input.Status, input.StatusMessage)
+}
+
+func setClusterStatus(ctx workflow.Context, clusterID uint, status, statusMessage string) error {
+	return workflow.ExecuteActivity(ctx, SetClusterStatusActivityName, SetClusterStatusActivityInput{
+		ClusterID:     clusterID,
+		Status:        status,
+		StatusMessage: statusMessage,
+	}).Get(ctx, nil)
+}
+
+func setClusterErrorStatus(ctx workflow.Context, clusterID uint, err error) error {
+	return setClusterStatus(ctx, clusterID, pkgCluster.Error, err.Error())
+}
+
+const DeleteClusterFromStoreActivityName = "pke-hosts-delete-cluster-from-store"
+
+type DeleteClusterFromStoreActivity struct {
+	store pke.ClusterStore
+}
+
+func MakeDeleteClusterFromStoreActivity(store pke.ClusterStore) DeleteClusterFromStoreActivity {
+	return DeleteClusterFromStoreActivity{
+		store: store,
+	}
+}
+
+type DeleteClusterFromStoreActivityInput struct {
+	ClusterID uint
+}
+
+func (a DeleteClusterFromStoreActivity) Execute(ctx context.Context, input DeleteClusterFromStoreActivityInput) error {
+	return a.store.Delete(input.ClusterID)
+}
+
+const DeleteHostFromStoreActivityName = "pke-hosts-delete-host-from-store"
+
+type DeleteHostFromStoreActivity struct {
+	store pke.ClusterStore
+}
+
+func MakeDeleteHostFromStoreActivity(store pke.ClusterStore) DeleteHostFromStoreActivity {
+	return DeleteHostFromStoreActivity{
+		store: store,
+	}
+}
+
+type DeleteHostFromStoreActivityInput struct {
+	ClusterID uint
+	Address   string
+}
+
+func (a DeleteHostFromStoreActivity) Execute(ctx context.Context, input DeleteHostFromStoreActivityInput) error {
+	err := a.store.DeleteHost(input.ClusterID, input.Address)
+	if pke.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+const DeleteNodePoolFromStoreActivityName = "pke-hosts-delete-node-pool-from-store"
+
+type DeleteNodePoolFromStoreActivity struct {
+	store pke.ClusterStore
+}
+
+func MakeDeleteNodePoolFromStoreActivity(store pke.ClusterStore) DeleteNodePoolFromStoreActivity {
+	return DeleteNodePoolFromStoreActivity{
+		store: store,
+	}
+}
+
+type DeleteNodePoolFromStoreActivityInput struct {
+	ClusterID    uint
+	NodePoolName string
+}
+
+func (a DeleteNodePoolFromStoreActivity) Execute(ctx context.Context, input DeleteNodePoolFromStoreActivityInput) error {
+	err := a.store.DeleteNodePool(input.ClusterID, input.NodePoolName)
+	if pke.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,220 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"sort"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustersetup"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	"github.com/banzaicloud/pipeline/pkg/brn"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+const UpdateClusterWorkflowName = "pke-hosts-update-cluster"
+
+// UpdateClusterWorkflowInput
+type UpdateClusterWorkflowInput struct {
+	ClusterID         uint
+	ClusterName       string
+	ClusterUID        string
+	OrganizationID    uint
+	OrganizationName  string
+	K8sSecretID       string
+	APIServerAddress  string
+	NodesToCreate     []Node
+	NodesToDelete     []Node
+	NodePoolsToDelete []string
+	HTTPProxy         intPKE.HTTPProxy
+	NodePoolLabels    map[string]map[string]string
+}
+
+func UpdateClusterWorkflow(ctx workflow.Context, input UpdateClusterWorkflowInput) error {
+	ctx = workflow.WithActivityOptions(ctx, defaultActivityOptions())
+
+	httpProxy, err := assembleHTTPProxySettings(ctx, input.OrganizationID, input.HTTPProxy)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	// set up node pool labels set
+	{
+		activityInput := clustersetup.ConfigureNodePoolLabelsActivityInput{
+			ConfigSecretID: brn.New(input.OrganizationID, brn.SecretResourceType, input.K8sSecretID).String(),
+			Labels:         input.NodePoolLabels,
+		}
+		err := workflow.ExecuteActivity(ctx, clustersetup.ConfigureNodePoolLabelsActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			err = errors.WrapIff(err, "%q activity failed", clustersetup.ConfigureNodePoolLabelsActivityName)
+			setClusterStatus(ctx, input.ClusterID, pkgCluster.Warning, err.Error()) // nolint: errcheck
+			return err
+		}
+	}
+
+	// Join new hosts
+	{
+		installInput := installNodesInput{
+			OrganizationID:   input.OrganizationID,
+			ClusterID:        input.ClusterID,
+			ClusterName:      input.ClusterName,
+			APIServerAddress: input.APIServerAddress,
+			HTTPProxy:        httpProxy,
+		}
+
+		nodeNames, err := installNodes(ctx, installInput, input.NodesToCreate)
+		if err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+
+		if err := labelNodes(ctx, input.OrganizationID, input.ClusterName, input.K8sSecretID, input.NodesToCreate, nodeNames); err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	// Remove hosts
+	if err := removeNodes(ctx, input.ClusterID, input.ClusterName, input.OrganizationID, input.K8sSecretID, input.NodesToDelete); err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	// Delete node pools
+	{
+		errs := []error{}
+
+		for _, name := range input.NodePoolsToDelete {
+			activityInput := DeleteNodePoolFromStoreActivityInput{
+				ClusterID:    input.ClusterID,
+				NodePoolName: name,
+			}
+			err := workflow.ExecuteActivity(ctx, DeleteNodePoolFromStoreActivityName, activityInput).Get(ctx, nil)
+			errs = append(errs, errors.WrapIff(err, "deleting node pool %q", name))
+		}
+
+		if err := errors.Combine(errs...); err != nil {
+			_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+			return err
+		}
+	}
+
+	err = setClusterStatus(ctx, input.ClusterID, pkgCluster.Running, pkgCluster.RunningMessage)
+	if err != nil {
+		_ = setClusterErrorStatus(ctx, input.ClusterID, err)
+		return err
+	}
+
+	return nil
+}
+
+// removeNodes drains the nodes of the hosts, removes them from Kubernetes, resets the hosts and removes them from the data store
+func removeNodes(ctx workflow.Context, clusterID uint, clusterName string, organizationID uint, k8sSecretID string, nodes []Node) error {
+	// hosts are processed in a deterministic order, regardless of the order they are given in
+	nodes = append([]Node(nil), nodes...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Address < nodes[j].Address })
+
+	var nodeNames []string
+	for _, node := range nodes {
+		if node.NodeName != "" {
+			nodeNames = append(nodeNames, node.NodeName)
+		}
+	}
+
+	// evicted pods should not be rescheduled to other nodes being removed
+	if len(nodeNames) > 0 {
+		activityInput := clusterworkflow.CordonNodesActivityInput{
+			ClusterID: clusterID,
+			NodeNames: nodeNames,
+		}
+
+		err := workflow.ExecuteActivity(ctx, clusterworkflow.CordonNodesActivityName, activityInput).Get(ctx, nil)
+		if err != nil {
+			workflow.GetLogger(ctx).Sugar().Warnf("failed to cordon nodes: %s", err)
+		}
+	}
+
+	futures := make([]workflow.Future, 0, len(nodes))
+
+	for _, node := range nodes {
+		node := node
+
+		futures = append(futures, executeSequence(ctx,
+			func(ctx workflow.Context) error {
+				if node.NodeName == "" {
+					// the host never joined the cluster
+					return nil
+				}
+
+				// the node is removed anyway, even if its pods cannot be evicted
+				if err := clusterworkflow.DrainNode(ctx, nil, clusterID, node.NodeName); err != nil {
+					workflow.GetLogger(ctx).Sugar().Warnf("failed to drain node %s: %s", node.NodeName, err)
+				}
+
+				activityInput := DeleteK8sNodeActivityInput{
+					OrganizationID: organizationID,
+					ClusterName:    clusterName,
+					K8sSecretID:    k8sSecretID,
+					Name:           node.NodeName,
+				}
+				return errors.WrapIf(workflow.ExecuteActivity(ctx, DeleteK8sNodeActivityName, activityInput).Get(ctx, nil), "deleting kubernetes node")
+			},
+			func(ctx workflow.Context) error {
+				activityInput := ResetNodeActivityInput{
+					OrganizationID: organizationID,
+					ClusterName:    clusterName,
+					Host:           node.Host,
+				}
+				return errors.WrapIf(workflow.ExecuteActivity(ctx, ResetNodeActivityName, activityInput).Get(ctx, nil), "resetting host")
+			},
+			func(ctx workflow.Context) error {
+				activityInput := DeleteHostFromStoreActivityInput{
+					ClusterID: clusterID,
+					Address:   node.Address,
+				}
+				return errors.WrapIf(workflow.ExecuteActivity(ctx, DeleteHostFromStoreActivityName, activityInput).Get(ctx, nil), "deleting host from data store")
+			},
+		))
+	}
+
+	errs := []error{}
+
+	for i, future := range futures {
+		errs = append(errs, errors.WrapIff(future.Get(ctx, nil), "removing host %q", nodes[i].Address))
+	}
+
+	return errors.Combine(errs...)
+}
+
+// executeSequence runs the steps one after the other in a separate coroutine and returns a future for the result
+func executeSequence(ctx workflow.Context, steps ...func(ctx workflow.Context) error) workflow.Future {
+	future, settable := workflow.NewFuture(ctx)
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for _, step := range steps {
+			if err := step(ctx); err != nil {
+				settable.SetError(err)
+				return
+			}
+		}
+		settable.Set(nil, nil)
+	})
+
+	return future
+}
@@ -0,0 +1,228 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clustersetup"
+	"github.com/banzaicloud/pipeline/internal/cluster/clusterworkflow"
+	"github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
+	intClusterWorkflow "github.com/banzaicloud/pipeline/internal/cluster/workflow"
+	intPKEWorkflow "github.com/banzaicloud/pipeline/internal/pke/workflow"
+	"github.com/banzaicloud/pipeline/internal/providers/pke/pkeworkflow"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+// nolint: gochecknoinits
+func init() {
+	workflow.RegisterWithOptions(UpdateClusterWorkflow, workflow.RegisterOptions{Name: UpdateClusterWorkflowName})
+	workflow.RegisterWithOptions(DeleteClusterWorkflow, workflow.RegisterOptions{Name: DeleteClusterWorkflowName})
+
+	activity.RegisterWithOptions(intPKEWorkflow.AssembleHTTPProxySettingsActivity{}.Execute, activity.RegisterOptions{Name: intPKEWorkflow.AssembleHTTPProxySettingsActivityName})
+	activity.RegisterWithOptions(clustersetup.ConfigureNodePoolLabelsActivity{}.Execute, activity.RegisterOptions{Name: clustersetup.ConfigureNodePoolLabelsActivityName})
+	activity.RegisterWithOptions(intClusterWorkflow.DeleteUnusedClusterSecretsActivity{}.Execute, activity.RegisterOptions{Name: intClusterWorkflow.DeleteUnusedClusterSecretsActivityName})
+	activity.RegisterWithOptions((&pkeworkflow.DeleteDexClientActivity{}).Execute, activity.RegisterOptions{Name: pkeworkflow.DeleteDexClientActivityName})
+	activity.RegisterWithOptions(InstallNodeActivity{}.Execute, activity.RegisterOptions{Name: InstallNodeActivityName})
+	activity.RegisterWithOptions(ResetNodeActivity{}.Execute, activity.RegisterOptions{Name: ResetNodeActivityName})
+	activity.RegisterWithOptions(LabelK8sNodeActivity{}.Execute, activity.RegisterOptions{Name: LabelK8sNodeActivityName})
+	activity.RegisterWithOptions(DeleteK8sNodeActivity{}.Execute, activity.RegisterOptions{Name: DeleteK8sNodeActivityName})
+	activity.RegisterWithOptions(SetClusterStatusActivity{}.Execute, activity.RegisterOptions{Name: SetClusterStatusActivityName})
+	activity.RegisterWithOptions(DeleteClusterFromStoreActivity{}.Execute, activity.RegisterOptions{Name: DeleteClusterFromStoreActivityName})
+	activity.RegisterWithOptions(DeleteHostFromStoreActivity{}.Execute, activity.RegisterOptions{Name: DeleteHostFromStoreActivityName})
+	activity.RegisterWithOptions(DeleteNodePoolFromStoreActivity{}.Execute, activity.RegisterOptions{Name: DeleteNodePoolFromStoreActivityName})
+	activity.RegisterWithOptions(clusterworkflow.NewCordonNodesActivity(nil).Execute, activity.RegisterOptions{Name: clusterworkflow.CordonNodesActivityName})
+	activity.RegisterWithOptions(clusterworkflow.NewDrainNodeActivity(nil, kubernetes.NodeDrainOptions{}).Execute, activity.RegisterOptions{Name: clusterworkflow.DrainNodeActivityName})
+}
+
+type ClusterWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestClusterWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(ClusterWorkflowTestSuite))
+}
+
+func (s *ClusterWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+}
+
+func (s *ClusterWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ClusterWorkflowTestSuite) Test_UpdateCluster_JoinsAndRemovesHosts() {
+	newNode := Node{
+		Host:           Host{Address: "10.0.0.12", Port: 22, SSHSecretID: "ssh"},
+		NodePoolName:   "workers",
+		Labels:         map[string]string{"disk": "ssd"},
+		ScriptTemplate: "pke install worker",
+	}
+	removedNode := Node{
+		Host:         Host{Address: "10.0.0.11", Port: 22, SSHSecretID: "ssh"},
+		NodePoolName: "old",
+		NodeName:     "worker-11",
+	}
+
+	s.env.OnActivity(intPKEWorkflow.AssembleHTTPProxySettingsActivityName, mock.Anything, mock.Anything).Return(intPKEWorkflow.AssembleHTTPProxySettingsActivityOutput{}, nil)
+	s.env.OnActivity(clustersetup.ConfigureNodePoolLabelsActivityName, mock.Anything, mock.Anything).Return(nil)
+
+	s.env.OnActivity(InstallNodeActivityName, mock.Anything, mock.MatchedBy(func(input InstallNodeActivityInput) bool {
+		return input.Address == newNode.Address && input.ScriptParams["PublicAddress"] == "10.0.0.10"
+	})).Return("worker-12", nil)
+	s.env.OnActivity(LabelK8sNodeActivityName, mock.Anything, LabelK8sNodeActivityInput{
+		OrganizationID: 1,
+		ClusterName:    "hosts",
+		K8sSecretID:    "config",
+		Name:           "worker-12",
+		Labels:         newNode.Labels,
+	}).Return(nil)
+
+	s.env.OnActivity(clusterworkflow.CordonNodesActivityName, mock.Anything, clusterworkflow.CordonNodesActivityInput{
+		ClusterID: 1,
+		NodeNames: []string{"worker-11"},
+	}).Return(nil).Once()
+	s.env.OnActivity(clusterworkflow.DrainNodeActivityName, mock.Anything, clusterworkflow.DrainNodeActivityInput{
+		ClusterID: 1,
+		NodeName:  "worker-11",
+	}).Return(nil).Once()
+	s.env.OnActivity(DeleteK8sNodeActivityName, mock.Anything, DeleteK8sNodeActivityInput{
+		OrganizationID: 1,
+		ClusterName:    "hosts",
+		K8sSecretID:    "config",
+		Name:           "worker-11",
+	}).Return(nil)
+	s.env.OnActivity(ResetNodeActivityName, mock.Anything, ResetNodeActivityInput{
+		OrganizationID: 1,
+		ClusterName:    "hosts",
+		Host:           removedNode.Host,
+	}).Return(nil)
+	s.env.OnActivity(DeleteHostFromStoreActivityName, mock.Anything, DeleteHostFromStoreActivityInput{ClusterID: 1, Address: removedNode.Address}).Return(nil)
+	s.env.OnActivity(DeleteNodePoolFromStoreActivityName, mock.Anything, DeleteNodePoolFromStoreActivityInput{ClusterID: 1, NodePoolName: "old"}).Return(nil)
+
+	s.env.OnActivity(SetClusterStatusActivityName, mock.Anything, SetClusterStatusActivityInput{
+		ClusterID:     1,
+		Status:        pkgCluster.Running,
+		StatusMessage: pkgCluster.RunningMessage,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(UpdateClusterWorkflowName, UpdateClusterWorkflowInput{
+		ClusterID:         1,
+		ClusterName:       "hosts",
+		OrganizationID:    1,
+		K8sSecretID:       "config",
+		APIServerAddress:  "10.0.0.10",
+		NodesToCreate:     []Node{newNode},
+		NodesToDelete:     []Node{removedNode},
+		NodePoolsToDelete: []string{"old"},
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ClusterWorkflowTestSuite) Test_UpdateCluster_ResetFails() {
+	removedNode := Node{
+		Host: Host{Address: "10.0.0.11", Port: 22, SSHSecretID: "ssh"},
+	}
+
+	s.env.OnActivity(intPKEWorkflow.AssembleHTTPProxySettingsActivityName, mock.Anything, mock.Anything).Return(intPKEWorkflow.AssembleHTTPProxySettingsActivityOutput{}, nil)
+	s.env.OnActivity(clustersetup.ConfigureNodePoolLabelsActivityName, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(ResetNodeActivityName, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	s.env.OnActivity(SetClusterStatusActivityName, mock.Anything, mock.MatchedBy(func(input SetClusterStatusActivityInput) bool {
+		return input.Status == pkgCluster.Error
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(UpdateClusterWorkflowName, UpdateClusterWorkflowInput{
+		ClusterID:      1,
+		ClusterName:    "hosts",
+		OrganizationID: 1,
+		K8sSecretID:    "config",
+		NodesToDelete:  []Node{removedNode},
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *ClusterWorkflowTestSuite) Test_UpdateCluster_RemovesHostsInOrder() {
+	nodes := []Node{
+		{Host: Host{Address: "10.0.0.13"}, NodeName: "worker-13"},
+		{Host: Host{Address: "10.0.0.11"}, NodeName: "worker-11"},
+		{Host: Host{Address: "10.0.0.12"}, NodeName: "worker-12"},
+	}
+
+	s.env.OnActivity(intPKEWorkflow.AssembleHTTPProxySettingsActivityName, mock.Anything, mock.Anything).Return(intPKEWorkflow.AssembleHTTPProxySettingsActivityOutput{}, nil)
+	s.env.OnActivity(clustersetup.ConfigureNodePoolLabelsActivityName, mock.Anything, mock.Anything).Return(nil)
+
+	s.env.OnActivity(clusterworkflow.CordonNodesActivityName, mock.Anything, clusterworkflow.CordonNodesActivityInput{
+		ClusterID: 1,
+		NodeNames: []string{"worker-11", "worker-12", "worker-13"},
+	}).Return(nil).Once()
+
+	// nodes that cannot be drained are removed anyway
+	s.env.OnActivity(clusterworkflow.DrainNodeActivityName, mock.Anything, mock.Anything).
+		Return(cadence.NewCustomError(clusterworkflow.ErrReasonNodeDrainTimeout)).Times(3)
+	s.env.OnActivity(DeleteK8sNodeActivityName, mock.Anything, mock.Anything).Return(nil).Times(3)
+	s.env.OnActivity(ResetNodeActivityName, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	s.env.OnActivity(SetClusterStatusActivityName, mock.Anything, mock.MatchedBy(func(input SetClusterStatusActivityInput) bool {
+		return input.Status == pkgCluster.Error
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(UpdateClusterWorkflowName, UpdateClusterWorkflowInput{
+		ClusterID:      1,
+		ClusterName:    "hosts",
+		OrganizationID: 1,
+		K8sSecretID:    "config",
+		NodesToDelete:  nodes,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+
+	err := s.env.GetWorkflowError()
+	s.Require().Error(err)
+	s.Regexp(`(?s)10\.0\.0\.11.*10\.0\.0\.12.*10\.0\.0\.13`, err.Error())
+}
+
+func (s *ClusterWorkflowTestSuite) Test_DeleteCluster_ForcedIgnoresUnreachableHosts() {
+	s.env.OnActivity(ResetNodeActivityName, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	s.env.OnActivity(intClusterWorkflow.DeleteUnusedClusterSecretsActivityName, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(pkeworkflow.DeleteDexClientActivityName, mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity(DeleteClusterFromStoreActivityName, mock.Anything, DeleteClusterFromStoreActivityInput{ClusterID: 1}).Return(nil)
+
+	s.env.ExecuteWorkflow(DeleteClusterWorkflowName, DeleteClusterWorkflowInput{
+		ClusterID:      1,
+		ClusterName:    "hosts",
+		OrganizationID: 1,
+		MasterNodes:    []Node{{Host: Host{Address: "10.0.0.10"}}},
+		Nodes:          []Node{{Host: Host{Address: "10.0.0.11"}}},
+		Forced:         true,
+	})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
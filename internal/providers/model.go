@@ -25,6 +25,7 @@ import (
 	"github.com/banzaicloud/pipeline/internal/providers/azure"
 	"github.com/banzaicloud/pipeline/internal/providers/azure/pke/adapter"
 	"github.com/banzaicloud/pipeline/internal/providers/google"
+	hosts "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/adapter"
 	"github.com/banzaicloud/pipeline/internal/providers/oracle"
 	"github.com/banzaicloud/pipeline/internal/providers/pke"
 	vsphere "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/adapter"
@@ -72,5 +73,9 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		return err
 	}
 
+	if err := hosts.Migrate(db, logger); err != nil {
+		return err
+	}
+
 	return nil
 }
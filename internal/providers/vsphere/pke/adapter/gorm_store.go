@@ -425,6 +425,17 @@ func (s gormVspherePKEClusterStore) SetSSHSecretID(clusterID uint, secretID stri
 	return getError(s.db.Model(&model).Updates(fields), "failed to update cluster model")
 }
 
+func (s gormVspherePKEClusterStore) SetKubernetesVersion(clusterID uint, version string) error {
+	data, err := s.getProviderData(clusterID)
+	if err != nil {
+		return err
+	}
+
+	data.Kubernetes.Version = version
+
+	return s.updateProviderData(clusterID, data)
+}
+
 func (s gormVspherePKEClusterStore) GetConfigSecretID(clusterID uint) (string, error) {
 	if err := validateClusterID(clusterID); err != nil {
 		return "", errors.WrapIf(err, "invalid cluster ID")
@@ -54,6 +54,7 @@ type ClusterStore interface {
 	SetActiveWorkflowID(clusterID uint, workflowID string) error
 	SetConfigSecretID(clusterID uint, secretID string) error
 	SetSSHSecretID(clusterID uint, sshSecretID string) error
+	SetKubernetesVersion(clusterID uint, version string) error
 }
 
 // IsNotFound returns true if the error is about a resource not being found
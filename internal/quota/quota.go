@@ -0,0 +1,284 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+)
+
+// Resource is a kind of resource limited by organization quotas.
+type Resource string
+
+// Resources limited by quotas.
+const (
+	ResourceClusters  Resource = "clusters"
+	ResourceNodes     Resource = "nodes"
+	ResourceNodePools Resource = "nodePools"
+	ResourceReleases  Resource = "releases"
+	ResourceSecrets   Resource = "secrets"
+)
+
+// Resources returns every resource limited by quotas in a stable order.
+func Resources() []Resource {
+	return []Resource{
+		ResourceClusters,
+		ResourceNodes,
+		ResourceNodePools,
+		ResourceReleases,
+		ResourceSecrets,
+	}
+}
+
+// Limits holds the maximum amount of resources an organization may have.
+// Zero means unlimited.
+type Limits struct {
+	Clusters  int
+	Nodes     int
+	NodePools int
+	Releases  int
+	Secrets   int
+}
+
+// Get returns the limit for a resource.
+func (l Limits) Get(resource Resource) int {
+	switch resource {
+	case ResourceClusters:
+		return l.Clusters
+	case ResourceNodes:
+		return l.Nodes
+	case ResourceNodePools:
+		return l.NodePools
+	case ResourceReleases:
+		return l.Releases
+	case ResourceSecrets:
+		return l.Secrets
+	default:
+		return 0
+	}
+}
+
+// Validate validates the limits.
+func (l Limits) Validate() error {
+	var err error
+
+	for _, resource := range Resources() {
+		if l.Get(resource) < 0 {
+			err = errors.Append(err, errors.Errorf("%s quota cannot be negative", resource))
+		}
+	}
+
+	return err
+}
+
+// Config contains the quota configuration.
+type Config struct {
+	Enabled bool
+
+	// Default limits applied to every organization
+	Default Limits
+
+	// Organization specific limits (keyed by organization ID) replacing the default limits
+	Organizations map[uint]Limits
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	var err error
+
+	err = errors.Append(err, c.Default.Validate())
+
+	for orgID, limits := range c.Organizations {
+		err = errors.Append(err, errors.WrapIff(limits.Validate(), "organization %d", orgID))
+	}
+
+	return err
+}
+
+// LimitsFor returns the limits applied to an organization.
+func (c Config) LimitsFor(organizationID uint) Limits {
+	if !c.Enabled {
+		return Limits{}
+	}
+
+	if limits, ok := c.Organizations[organizationID]; ok {
+		return limits
+	}
+
+	return c.Default
+}
+
+// ResourceUsage describes the usage of a resource by an organization.
+type ResourceUsage struct {
+	Resource Resource `json:"resource"`
+	Limit    int      `json:"limit"`
+	Used     int      `json:"used"`
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service provides information about organization quotas.
+type Service interface {
+	// GetUsage returns the quota usage of an organization.
+	GetUsage(ctx context.Context, organizationID uint) (usage []ResourceUsage, err error)
+}
+
+// +testify:mock
+
+// Enforcer checks whether an operation fits into the quotas of an organization.
+type Enforcer interface {
+	// Enforce returns an ExceededError if the requested amount of resources exceeds a quota.
+	Enforce(ctx context.Context, organizationID uint, request Request) error
+}
+
+// Request is the amount of resources an operation is about to add to an organization.
+type Request map[Resource]int
+
+// +testify:mock:testOnly=true
+
+// UsageCounter counts the resources currently used by an organization.
+type UsageCounter interface {
+	// CountUsage returns the amount of a resource used by an organization.
+	CountUsage(ctx context.Context, organizationID uint, resource Resource) (int, error)
+}
+
+// NewService returns a new Service.
+func NewService(config Config, counter UsageCounter) Service {
+	return quotas{
+		config:  config,
+		counter: counter,
+	}
+}
+
+// NewEnforcer returns a new Enforcer.
+func NewEnforcer(config Config, counter UsageCounter) Enforcer {
+	return quotas{
+		config:  config,
+		counter: counter,
+	}
+}
+
+type quotas struct {
+	config  Config
+	counter UsageCounter
+}
+
+func (q quotas) GetUsage(ctx context.Context, organizationID uint) ([]ResourceUsage, error) {
+	limits := q.config.LimitsFor(organizationID)
+
+	usage := make([]ResourceUsage, 0, len(Resources()))
+
+	for _, resource := range Resources() {
+		used, err := q.counter.CountUsage(ctx, organizationID, resource)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to count resource usage", "resource", resource)
+		}
+
+		usage = append(usage, ResourceUsage{
+			Resource: resource,
+			Limit:    limits.Get(resource),
+			Used:     used,
+		})
+	}
+
+	return usage, nil
+}
+
+func (q quotas) Enforce(ctx context.Context, organizationID uint, request Request) error {
+	limits := q.config.LimitsFor(organizationID)
+
+	for _, resource := range Resources() {
+		requested := request[resource]
+		limit := limits.Get(resource)
+
+		if requested <= 0 || limit == 0 {
+			continue
+		}
+
+		used, err := q.counter.CountUsage(ctx, organizationID, resource)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to count resource usage", "resource", resource)
+		}
+
+		if used+requested > limit {
+			return ExceededError{
+				OrganizationID: organizationID,
+				Resource:       resource,
+				Limit:          limit,
+				Used:           used,
+				Requested:      requested,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExceededError is returned when an operation would exceed a quota of an organization.
+type ExceededError struct {
+	OrganizationID uint
+	Resource       Resource
+	Limit          int
+	Used           int
+	Requested      int
+}
+
+// Error implements the error interface.
+func (e ExceededError) Error() string {
+	return fmt.Sprintf(
+		"%s quota exceeded: limit is %d, %d in use, %d requested",
+		e.Resource,
+		e.Limit,
+		e.Used,
+		e.Requested,
+	)
+}
+
+// Details returns error details.
+func (e ExceededError) Details() []interface{} {
+	return []interface{}{
+		"organizationId", e.OrganizationID,
+		"resource", e.Resource,
+		"limit", e.Limit,
+		"used", e.Used,
+		"requested", e.Requested,
+	}
+}
+
+// Forbidden tells a client that this error is related to an operation forbidden by a quota.
+// Can be used to translate the error to status codes for example.
+func (ExceededError) Forbidden() bool {
+	return true
+}
+
+// ServiceError tells the consumer whether this error is caused by invalid input supplied by the client.
+// Client errors are usually returned to the consumer without retrying the operation.
+func (ExceededError) ServiceError() bool {
+	return true
+}
+
+type nopEnforcer struct{}
+
+// NewNopEnforcer returns an Enforcer that allows every operation.
+func NewNopEnforcer() Enforcer {
+	return nopEnforcer{}
+}
+
+func (nopEnforcer) Enforce(_ context.Context, _ uint, _ Request) error {
+	return nil
+}
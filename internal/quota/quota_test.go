@@ -0,0 +1,142 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_LimitsFor(t *testing.T) {
+	config := Config{
+		Enabled: true,
+		Default: Limits{Clusters: 2},
+		Organizations: map[uint]Limits{
+			2: {Clusters: 10, Secrets: 5},
+		},
+	}
+
+	assert.Equal(t, Limits{Clusters: 2}, config.LimitsFor(1))
+	assert.Equal(t, Limits{Clusters: 10, Secrets: 5}, config.LimitsFor(2))
+
+	config.Enabled = false
+
+	assert.Equal(t, Limits{}, config.LimitsFor(2))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := Config{
+		Default: Limits{Clusters: -1},
+		Organizations: map[uint]Limits{
+			2: {Secrets: -5},
+		},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+
+	assert.Len(t, errors.GetErrors(err), 2)
+}
+
+func TestService_GetUsage(t *testing.T) {
+	ctx := context.Background()
+
+	counter := new(MockUsageCounter)
+	counter.On("CountUsage", ctx, uint(1), ResourceClusters).Return(1, nil)
+	counter.On("CountUsage", ctx, uint(1), ResourceNodes).Return(6, nil)
+	counter.On("CountUsage", ctx, uint(1), ResourceNodePools).Return(2, nil)
+	counter.On("CountUsage", ctx, uint(1), ResourceReleases).Return(0, nil)
+	counter.On("CountUsage", ctx, uint(1), ResourceSecrets).Return(3, nil)
+
+	service := NewService(Config{Enabled: true, Default: Limits{Clusters: 2, Nodes: 10}}, counter)
+
+	usage, err := service.GetUsage(ctx, 1)
+	require.NoError(t, err)
+
+	expected := []ResourceUsage{
+		{Resource: ResourceClusters, Limit: 2, Used: 1},
+		{Resource: ResourceNodes, Limit: 10, Used: 6},
+		{Resource: ResourceNodePools, Limit: 0, Used: 2},
+		{Resource: ResourceReleases, Limit: 0, Used: 0},
+		{Resource: ResourceSecrets, Limit: 0, Used: 3},
+	}
+
+	assert.Equal(t, expected, usage)
+
+	counter.AssertExpectations(t)
+}
+
+func TestEnforcer_Enforce(t *testing.T) {
+	ctx := context.Background()
+
+	config := Config{
+		Enabled: true,
+		Default: Limits{Clusters: 2, Nodes: 10},
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		counter := new(MockUsageCounter)
+		counter.On("CountUsage", ctx, uint(1), ResourceClusters).Return(1, nil)
+		counter.On("CountUsage", ctx, uint(1), ResourceNodes).Return(7, nil)
+
+		enforcer := NewEnforcer(config, counter)
+
+		err := enforcer.Enforce(ctx, 1, Request{ResourceClusters: 1, ResourceNodes: 3, ResourceNodePools: 1})
+		require.NoError(t, err)
+
+		counter.AssertExpectations(t)
+	})
+
+	t.Run("Exceeded", func(t *testing.T) {
+		counter := new(MockUsageCounter)
+		counter.On("CountUsage", ctx, uint(1), ResourceClusters).Return(1, nil)
+		counter.On("CountUsage", ctx, uint(1), ResourceNodes).Return(8, nil)
+
+		enforcer := NewEnforcer(config, counter)
+
+		err := enforcer.Enforce(ctx, 1, Request{ResourceClusters: 1, ResourceNodes: 3})
+		require.Error(t, err)
+
+		var exceededErr ExceededError
+		require.True(t, errors.As(err, &exceededErr))
+
+		assert.Equal(t, ExceededError{
+			OrganizationID: 1,
+			Resource:       ResourceNodes,
+			Limit:          10,
+			Used:           8,
+			Requested:      3,
+		}, exceededErr)
+		assert.True(t, exceededErr.Forbidden())
+
+		counter.AssertExpectations(t)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		counter := new(MockUsageCounter)
+
+		enforcer := NewEnforcer(Config{Default: Limits{Clusters: 1}}, counter)
+
+		err := enforcer.Enforce(ctx, 1, Request{ResourceClusters: 5})
+		require.NoError(t, err)
+
+		counter.AssertNotCalled(t, "CountUsage", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
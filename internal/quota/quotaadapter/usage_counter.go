@@ -0,0 +1,217 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/helm"
+	"github.com/banzaicloud/pipeline/internal/quota"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/src/cluster"
+	pipelineSecret "github.com/banzaicloud/pipeline/src/secret"
+)
+
+// ClusterLister lists the clusters of an organization.
+type ClusterLister interface {
+	GetClusters(ctx context.Context, organizationID uint) ([]cluster.CommonCluster, error)
+}
+
+// SecretLister lists the secrets of an organization.
+type SecretLister interface {
+	List(ctx context.Context, organizationID uint) ([]secret.Model, error)
+}
+
+// ReleaseLister lists the Helm releases installed to a cluster.
+type ReleaseLister interface {
+	ListReleases(ctx context.Context, organizationID uint, clusterID uint, filters helm.ReleaseFilter, options helm.Options) ([]helm.Release, error)
+}
+
+// UsageCounter counts organization resources using the cluster, secret and Helm services.
+type UsageCounter struct {
+	clusters ClusterLister
+	secrets  SecretLister
+	releases ReleaseLister
+
+	logger common.Logger
+}
+
+// NewUsageCounter returns a new UsageCounter.
+func NewUsageCounter(clusters ClusterLister, secrets SecretLister, releases ReleaseLister, logger common.Logger) UsageCounter {
+	return UsageCounter{
+		clusters: clusters,
+		secrets:  secrets,
+		releases: releases,
+
+		logger: logger,
+	}
+}
+
+// CountUsage returns the amount of a resource used by an organization.
+func (c UsageCounter) CountUsage(ctx context.Context, organizationID uint, resource quota.Resource) (int, error) {
+	switch resource {
+	case quota.ResourceClusters:
+		clusters, err := c.clusters.GetClusters(ctx, organizationID)
+		if err != nil {
+			return 0, errors.WrapIf(err, "failed to list clusters")
+		}
+
+		return len(clusters), nil
+
+	case quota.ResourceNodePools, quota.ResourceNodes:
+		return c.countNodes(ctx, organizationID, resource)
+
+	case quota.ResourceReleases:
+		return c.countReleases(ctx, organizationID)
+
+	case quota.ResourceSecrets:
+		return c.countSecrets(ctx, organizationID)
+
+	default:
+		return 0, errors.NewWithDetails("unknown quota resource", "resource", resource)
+	}
+}
+
+func (c UsageCounter) countNodes(ctx context.Context, organizationID uint, resource quota.Resource) (int, error) {
+	clusters, err := c.clusters.GetClusters(ctx, organizationID)
+	if err != nil {
+		return 0, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var count int
+
+	for _, cluster := range clusters {
+		status, err := cluster.GetStatus()
+		if err != nil {
+			return 0, errors.WrapIfWithDetails(err, "failed to get cluster status", "clusterId", cluster.GetID())
+		}
+
+		if resource == quota.ResourceNodePools {
+			count += len(status.NodePools)
+
+			continue
+		}
+
+		for _, nodePool := range status.NodePools {
+			if nodePool != nil {
+				count += nodePool.Count
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// countReleases counts the releases installed to the running clusters of an organization.
+// Clusters whose releases cannot be listed are skipped, so that an unreachable cluster does not block every install.
+func (c UsageCounter) countReleases(ctx context.Context, organizationID uint) (int, error) {
+	clusters, err := c.clusters.GetClusters(ctx, organizationID)
+	if err != nil {
+		return 0, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var count int
+
+	for _, cluster := range clusters {
+		status, err := cluster.GetStatus()
+		if err != nil || status.Status != pkgCluster.Running {
+			continue
+		}
+
+		releases, err := c.releases.ListReleases(ctx, organizationID, cluster.GetID(), helm.ReleaseFilter{}, helm.Options{})
+		if err != nil {
+			c.logger.Warn("failed to list releases", map[string]interface{}{
+				"organizationId": organizationID,
+				"clusterId":      cluster.GetID(),
+				"error":          err.Error(),
+			})
+
+			continue
+		}
+
+		count += len(releases)
+	}
+
+	return count, nil
+}
+
+// countSecrets counts the secrets of an organization, ignoring hidden secrets created by Pipeline itself.
+func (c UsageCounter) countSecrets(ctx context.Context, organizationID uint) (int, error) {
+	secrets, err := c.secrets.List(ctx, organizationID)
+	if err != nil {
+		return 0, errors.WrapIf(err, "failed to list secrets")
+	}
+
+	var count int
+
+	for _, s := range secrets {
+		if !hasTag(s.Tags, pipelineSecret.TagBanzaiHidden) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClusterGetter returns a cluster by its ID.
+type ClusterGetter interface {
+	GetClusterByIDOnly(ctx context.Context, clusterID uint) (cluster.CommonCluster, error)
+}
+
+// NodePoolStore provides the size of node pools from the status of the clusters.
+type NodePoolStore struct {
+	clusters ClusterGetter
+}
+
+// NewNodePoolStore returns a new NodePoolStore.
+func NewNodePoolStore(clusters ClusterGetter) NodePoolStore {
+	return NodePoolStore{
+		clusters: clusters,
+	}
+}
+
+// GetNodePoolSize returns the current number of nodes in a node pool.
+func (s NodePoolStore) GetNodePoolSize(ctx context.Context, clusterID uint, nodePoolName string) (int, error) {
+	c, err := s.clusters.GetClusterByIDOnly(ctx, clusterID)
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(err, "failed to get cluster", "clusterId", clusterID)
+	}
+
+	status, err := c.GetStatus()
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(err, "failed to get cluster status", "clusterId", clusterID)
+	}
+
+	nodePool, ok := status.NodePools[nodePoolName]
+	if !ok || nodePool == nil {
+		return 0, nil
+	}
+
+	return nodePool.Count, nil
+}
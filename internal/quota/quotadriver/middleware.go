@@ -0,0 +1,176 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotadriver
+
+import (
+	"context"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/helm"
+	"github.com/banzaicloud/pipeline/internal/quota"
+)
+
+// ClusterStore provides the organization of a cluster.
+type ClusterStore interface {
+	// GetCluster returns a generic Cluster.
+	// Returns an error with the NotFound behavior when the cluster cannot be found.
+	GetCluster(ctx context.Context, id uint) (cluster.Cluster, error)
+}
+
+// NodePoolStore provides the size of existing node pools.
+type NodePoolStore interface {
+	// GetNodePoolSize returns the current number of nodes in a node pool.
+	GetNodePoolSize(ctx context.Context, clusterID uint, nodePoolName string) (int, error)
+}
+
+// ClusterServiceMiddleware makes sure new and resized node pools fit into the quotas of the organization.
+func ClusterServiceMiddleware(enforcer quota.Enforcer, clusters ClusterStore, nodePools NodePoolStore) func(cluster.Service) cluster.Service {
+	return func(next cluster.Service) cluster.Service {
+		return clusterServiceMiddleware{
+			Service: next,
+
+			enforcer:  enforcer,
+			clusters:  clusters,
+			nodePools: nodePools,
+		}
+	}
+}
+
+type clusterServiceMiddleware struct {
+	cluster.Service
+
+	enforcer  quota.Enforcer
+	clusters  ClusterStore
+	nodePools NodePoolStore
+}
+
+func (m clusterServiceMiddleware) CreateNodePool(ctx context.Context, clusterID uint, rawNodePool cluster.NewRawNodePool) error {
+	c, err := m.clusters.GetCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	err = m.enforcer.Enforce(ctx, c.OrganizationID, quota.Request{
+		quota.ResourceNodePools: 1,
+		quota.ResourceNodes:     NewNodePoolSize(rawNodePool),
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.Service.CreateNodePool(ctx, clusterID, rawNodePool)
+}
+
+func (m clusterServiceMiddleware) UpdateNodePool(
+	ctx context.Context,
+	clusterID uint,
+	nodePoolName string,
+	rawNodePoolUpdate cluster.RawNodePoolUpdate,
+) (string, error) {
+	// Updates not touching the size of the node pool do not need additional nodes
+	if size, ok := nodePoolUpdateSize(rawNodePoolUpdate); ok {
+		c, err := m.clusters.GetCluster(ctx, clusterID)
+		if err != nil {
+			return "", err
+		}
+
+		currentSize, err := m.nodePools.GetNodePoolSize(ctx, clusterID, nodePoolName)
+		if err != nil {
+			return "", err
+		}
+
+		if size > currentSize {
+			err = m.enforcer.Enforce(ctx, c.OrganizationID, quota.Request{quota.ResourceNodes: size - currentSize})
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return m.Service.UpdateNodePool(ctx, clusterID, nodePoolName, rawNodePoolUpdate)
+}
+
+// NewNodePoolSize returns the initial number of nodes in a new node pool.
+// When autoscaling is enabled the node pool starts with its minimum size.
+func NewNodePoolSize(rawNodePool cluster.NewRawNodePool) int {
+	if autoscaling, ok := rawNodePool["autoscaling"].(map[string]interface{}); ok {
+		if enabled, ok := autoscaling["enabled"].(bool); ok && enabled {
+			return intValue(autoscaling["minSize"])
+		}
+	}
+
+	return intValue(rawNodePool["size"])
+}
+
+// nodePoolUpdateSize returns the number of nodes a node pool update resizes the node pool to (if it does).
+func nodePoolUpdateSize(rawNodePoolUpdate cluster.RawNodePoolUpdate) (int, bool) {
+	if autoscaling, ok := rawNodePoolUpdate["autoscaling"].(map[string]interface{}); ok {
+		if enabled, ok := autoscaling["enabled"].(bool); ok && enabled {
+			return intValue(autoscaling["minSize"]), true
+		}
+	}
+
+	if size, ok := rawNodePoolUpdate["size"]; ok {
+		return intValue(size), true
+	}
+
+	return 0, false
+}
+
+func intValue(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+// HelmServiceMiddleware makes sure new releases fit into the quotas of the organization.
+func HelmServiceMiddleware(enforcer quota.Enforcer) func(helm.Service) helm.Service {
+	return func(next helm.Service) helm.Service {
+		return helmServiceMiddleware{
+			Service: next,
+
+			enforcer: enforcer,
+		}
+	}
+}
+
+type helmServiceMiddleware struct {
+	helm.Service
+
+	enforcer quota.Enforcer
+}
+
+func (m helmServiceMiddleware) InstallRelease(
+	ctx context.Context,
+	organizationID uint,
+	clusterID uint,
+	release helm.Release,
+	options helm.Options,
+) error {
+	if !options.DryRun {
+		if err := m.enforcer.Enforce(ctx, organizationID, quota.Request{quota.ResourceReleases: 1}); err != nil {
+			return err
+		}
+	}
+
+	return m.Service.InstallRelease(ctx, organizationID, clusterID, release, options)
+}
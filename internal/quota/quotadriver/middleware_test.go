@@ -0,0 +1,229 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotadriver
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/helm"
+	"github.com/banzaicloud/pipeline/internal/quota"
+)
+
+type clusterStoreStub struct {
+	clusters map[uint]cluster.Cluster
+}
+
+func (s clusterStoreStub) GetCluster(_ context.Context, id uint) (cluster.Cluster, error) {
+	c, ok := s.clusters[id]
+	if !ok {
+		return cluster.Cluster{}, cluster.NotFoundError{ClusterID: id}
+	}
+
+	return c, nil
+}
+
+type nodePoolStoreStub struct {
+	sizes map[string]int
+}
+
+func (s nodePoolStoreStub) GetNodePoolSize(_ context.Context, _ uint, nodePoolName string) (int, error) {
+	return s.sizes[nodePoolName], nil
+}
+
+func TestNewNodePoolSize(t *testing.T) {
+	tests := []struct {
+		rawNodePool cluster.NewRawNodePool
+		size        int
+	}{
+		{
+			rawNodePool: cluster.NewRawNodePool{"size": float64(3)},
+			size:        3,
+		},
+		{
+			rawNodePool: cluster.NewRawNodePool{
+				"size": 3,
+				"autoscaling": map[string]interface{}{
+					"enabled": true,
+					"minSize": float64(2),
+					"maxSize": float64(5),
+				},
+			},
+			size: 2,
+		},
+		{
+			rawNodePool: cluster.NewRawNodePool{},
+			size:        0,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.size, NewNodePoolSize(test.rawNodePool))
+	}
+}
+
+func TestClusterServiceMiddleware_CreateNodePool(t *testing.T) {
+	ctx := context.Background()
+
+	clusters := clusterStoreStub{
+		clusters: map[uint]cluster.Cluster{
+			1: {ID: 1, OrganizationID: 2},
+		},
+	}
+
+	rawNodePool := cluster.NewRawNodePool{"name": "pool0", "size": 3}
+
+	t.Run("Allowed", func(t *testing.T) {
+		enforcer := new(quota.MockEnforcer)
+		enforcer.On("Enforce", ctx, uint(2), quota.Request{quota.ResourceNodePools: 1, quota.ResourceNodes: 3}).Return(nil)
+
+		service := new(cluster.MockService)
+		service.On("CreateNodePool", ctx, uint(1), rawNodePool).Return(nil)
+
+		err := ClusterServiceMiddleware(enforcer, clusters, nodePoolStoreStub{})(service).CreateNodePool(ctx, 1, rawNodePool)
+		require.NoError(t, err)
+
+		enforcer.AssertExpectations(t)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Exceeded", func(t *testing.T) {
+		quotaErr := quota.ExceededError{OrganizationID: 2, Resource: quota.ResourceNodes, Limit: 4, Used: 2, Requested: 3}
+
+		enforcer := new(quota.MockEnforcer)
+		enforcer.On("Enforce", ctx, uint(2), mock.Anything).Return(quotaErr)
+
+		service := new(cluster.MockService)
+
+		err := ClusterServiceMiddleware(enforcer, clusters, nodePoolStoreStub{})(service).CreateNodePool(ctx, 1, rawNodePool)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, quotaErr))
+
+		service.AssertNotCalled(t, "CreateNodePool", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestClusterServiceMiddleware_UpdateNodePool(t *testing.T) {
+	ctx := context.Background()
+
+	clusters := clusterStoreStub{
+		clusters: map[uint]cluster.Cluster{
+			1: {ID: 1, OrganizationID: 2},
+		},
+	}
+
+	nodePools := nodePoolStoreStub{
+		sizes: map[string]int{"pool0": 3},
+	}
+
+	t.Run("Grow", func(t *testing.T) {
+		rawNodePoolUpdate := cluster.RawNodePoolUpdate{"size": float64(5)}
+
+		enforcer := new(quota.MockEnforcer)
+		enforcer.On("Enforce", ctx, uint(2), quota.Request{quota.ResourceNodes: 2}).Return(nil)
+
+		service := new(cluster.MockService)
+		service.On("UpdateNodePool", ctx, uint(1), "pool0", rawNodePoolUpdate).Return("process", nil)
+
+		processID, err := ClusterServiceMiddleware(enforcer, clusters, nodePools)(service).UpdateNodePool(ctx, 1, "pool0", rawNodePoolUpdate)
+		require.NoError(t, err)
+		assert.Equal(t, "process", processID)
+
+		enforcer.AssertExpectations(t)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Exceeded", func(t *testing.T) {
+		rawNodePoolUpdate := cluster.RawNodePoolUpdate{
+			"autoscaling": map[string]interface{}{
+				"enabled": true,
+				"minSize": float64(6),
+				"maxSize": float64(10),
+			},
+		}
+
+		quotaErr := quota.ExceededError{OrganizationID: 2, Resource: quota.ResourceNodes, Limit: 4, Used: 3, Requested: 3}
+
+		enforcer := new(quota.MockEnforcer)
+		enforcer.On("Enforce", ctx, uint(2), quota.Request{quota.ResourceNodes: 3}).Return(quotaErr)
+
+		service := new(cluster.MockService)
+
+		_, err := ClusterServiceMiddleware(enforcer, clusters, nodePools)(service).UpdateNodePool(ctx, 1, "pool0", rawNodePoolUpdate)
+		require.Error(t, err)
+
+		assert.True(t, errors.Is(err, quotaErr))
+
+		service.AssertNotCalled(t, "UpdateNodePool", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotResized", func(t *testing.T) {
+		for _, rawNodePoolUpdate := range []cluster.RawNodePoolUpdate{
+			{"image": "ami-xxxxxxxxxxxxx"},
+			{"size": 2},
+		} {
+			enforcer := new(quota.MockEnforcer)
+
+			service := new(cluster.MockService)
+			service.On("UpdateNodePool", ctx, uint(1), "pool0", rawNodePoolUpdate).Return("process", nil)
+
+			_, err := ClusterServiceMiddleware(enforcer, clusters, nodePools)(service).UpdateNodePool(ctx, 1, "pool0", rawNodePoolUpdate)
+			require.NoError(t, err)
+
+			enforcer.AssertNotCalled(t, "Enforce", mock.Anything, mock.Anything, mock.Anything)
+			service.AssertExpectations(t)
+		}
+	})
+}
+
+type helmServiceStub struct {
+	helm.Service
+
+	installed int
+}
+
+func (s *helmServiceStub) InstallRelease(_ context.Context, _ uint, _ uint, _ helm.Release, _ helm.Options) error {
+	s.installed++
+
+	return nil
+}
+
+func TestHelmServiceMiddleware_InstallRelease(t *testing.T) {
+	ctx := context.Background()
+
+	quotaErr := quota.ExceededError{OrganizationID: 1, Resource: quota.ResourceReleases, Limit: 1, Used: 1, Requested: 1}
+
+	enforcer := new(quota.MockEnforcer)
+	enforcer.On("Enforce", ctx, uint(1), quota.Request{quota.ResourceReleases: 1}).Return(quotaErr)
+
+	next := &helmServiceStub{}
+	service := HelmServiceMiddleware(enforcer)(next)
+
+	err := service.InstallRelease(ctx, 1, 1, helm.Release{}, helm.Options{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, quotaErr))
+
+	err = service.InstallRelease(ctx, 1, 1, helm.Release{}, helm.Options{DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, next.installed)
+}
@@ -0,0 +1,516 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Config contains the configuration of the binding synchronization.
+type Config struct {
+	// Enabled turns the synchronization scheduler on.
+	Enabled bool
+
+	// Schedule is the cron schedule of synchronizing bindings.
+	Schedule string
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.Enabled && c.Schedule == "" {
+		return errors.New("secret binding schedule is required")
+	}
+
+	return nil
+}
+
+// Source is a Pipeline secret whose values are copied to the Kubernetes secret of a binding.
+type Source struct {
+	// SecretID is the ID of the Pipeline secret.
+	SecretID string `json:"secretId"`
+
+	// Keys maps Kubernetes secret keys to the keys of the Pipeline secret.
+	// Every key of the Pipeline secret is copied when it is empty.
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+// Target describes the clusters a binding is synchronized to.
+// Either a list of clusters or a cluster group is accepted.
+type Target struct {
+	ClusterIDs     []uint `json:"clusterIds,omitempty"`
+	ClusterGroupID uint   `json:"clusterGroupId,omitempty"`
+}
+
+// BindingRequest describes the desired state of a binding.
+type BindingRequest struct {
+	// Name is the name of the Kubernetes secret.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Kubernetes secret.
+	Namespace string `json:"namespace"`
+
+	Sources []Source `json:"sources"`
+	Target  Target   `json:"target"`
+}
+
+// Validate validates a binding request.
+func (r BindingRequest) Validate() error {
+	var violations []string
+
+	for _, msg := range validation.IsDNS1123Subdomain(r.Name) {
+		violations = append(violations, fmt.Sprintf("name: %s", msg))
+	}
+
+	for _, msg := range validation.IsDNS1123Label(r.Namespace) {
+		violations = append(violations, fmt.Sprintf("namespace: %s", msg))
+	}
+
+	if len(r.Sources) == 0 {
+		violations = append(violations, "at least one source secret is required")
+	}
+
+	secretIDs := make(map[string]bool, len(r.Sources))
+	keys := make(map[string]bool)
+
+	for _, source := range r.Sources {
+		if source.SecretID == "" {
+			violations = append(violations, "source secret ID is required")
+
+			continue
+		}
+
+		if secretIDs[source.SecretID] {
+			violations = append(violations, fmt.Sprintf("source secret %s is listed more than once", source.SecretID))
+		}
+		secretIDs[source.SecretID] = true
+
+		for key := range source.Keys {
+			for _, msg := range validation.IsConfigMapKey(key) {
+				violations = append(violations, fmt.Sprintf("key %q: %s", key, msg))
+			}
+
+			if keys[key] {
+				violations = append(violations, fmt.Sprintf("key %q is mapped more than once", key))
+			}
+			keys[key] = true
+		}
+	}
+
+	if len(r.Target.ClusterIDs) == 0 && r.Target.ClusterGroupID == 0 {
+		violations = append(violations, "either target clusters or a target cluster group is required")
+	} else if len(r.Target.ClusterIDs) > 0 && r.Target.ClusterGroupID != 0 {
+		violations = append(violations, "target clusters and a target cluster group cannot be used together")
+	}
+
+	if len(violations) > 0 {
+		return secret.NewValidationError("invalid secret binding", violations)
+	}
+
+	return nil
+}
+
+// Binding binds Pipeline secrets to a Kubernetes secret in a set of clusters.
+type Binding struct {
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"-"`
+	Name           string    `json:"name"`
+	Namespace      string    `json:"namespace"`
+	Sources        []Source  `json:"sources"`
+	Target         Target    `json:"target"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Synchronization statuses of a binding in a cluster.
+const (
+	StatusPending = "PENDING"
+	StatusSynced  = "SYNCED"
+	StatusFailed  = "FAILED"
+)
+
+// ClusterStatus is the synchronization status of a binding in a cluster.
+type ClusterStatus struct {
+	ClusterID uint   `json:"clusterId"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+
+	// Checksum identifies the content of the Kubernetes secret that was last synchronized.
+	Checksum string `json:"checksum,omitempty"`
+
+	// SyncedAt is the time the Kubernetes secret was last changed by a successful synchronization.
+	SyncedAt *time.Time `json:"syncedAt,omitempty"`
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service manages secret bindings.
+type Service interface {
+	// CreateBinding creates a new binding and starts synchronizing it.
+	CreateBinding(ctx context.Context, organizationID uint, request BindingRequest) (binding Binding, err error)
+
+	// ListBindings lists the bindings of an organization.
+	ListBindings(ctx context.Context, organizationID uint) (bindings []Binding, err error)
+
+	// GetBinding returns a binding.
+	GetBinding(ctx context.Context, organizationID uint, bindingID uint) (binding Binding, err error)
+
+	// UpdateBinding changes the sources and the targets of a binding.
+	// The name and the namespace of the Kubernetes secret cannot be changed.
+	UpdateBinding(ctx context.Context, organizationID uint, bindingID uint, request BindingRequest) (binding Binding, err error)
+
+	// DeleteBinding removes the Kubernetes secrets of a binding from the clusters and deletes the binding.
+	DeleteBinding(ctx context.Context, organizationID uint, bindingID uint) error
+
+	// GetBindingStatus returns the synchronization status of a binding in each target cluster.
+	GetBindingStatus(ctx context.Context, organizationID uint, bindingID uint) (statuses []ClusterStatus, err error)
+}
+
+// +testify:mock:testOnly=true
+
+// Store persists bindings and their synchronization status.
+type Store interface {
+	// Create creates a new binding and returns its ID.
+	Create(ctx context.Context, binding Binding) (uint, error)
+
+	// Get returns a binding.
+	// Returns a NotFoundError when the binding cannot be found.
+	Get(ctx context.Context, organizationID uint, id uint) (Binding, error)
+
+	// List lists the bindings of an organization.
+	List(ctx context.Context, organizationID uint) ([]Binding, error)
+
+	// ListAll lists the bindings of every organization.
+	ListAll(ctx context.Context) ([]Binding, error)
+
+	// Update updates the sources and the targets of a binding.
+	Update(ctx context.Context, binding Binding) error
+
+	// Delete deletes a binding together with its statuses.
+	Delete(ctx context.Context, organizationID uint, id uint) error
+
+	// ListStatuses lists the synchronization statuses of a binding.
+	ListStatuses(ctx context.Context, id uint) ([]ClusterStatus, error)
+
+	// PutStatus creates or replaces the synchronization status of a binding in a cluster.
+	PutStatus(ctx context.Context, id uint, status ClusterStatus) error
+
+	// DeleteStatus deletes the synchronization status of a binding in a cluster.
+	DeleteStatus(ctx context.Context, id uint, clusterID uint) error
+}
+
+// ClusterStore provides access to clusters.
+type ClusterStore interface {
+	// GetCluster returns a generic representation of a cluster.
+	GetCluster(ctx context.Context, id uint) (cluster.Cluster, error)
+}
+
+// ClusterGroupStore provides access to cluster groups.
+type ClusterGroupStore interface {
+	// GetMemberIDs returns the IDs of the member clusters of a cluster group.
+	// Returns a ClusterGroupNotFoundError when the cluster group cannot be found.
+	GetMemberIDs(ctx context.Context, organizationID uint, clusterGroupID uint) ([]uint, error)
+}
+
+// KubernetesSecretManager manages the Kubernetes secrets of bindings.
+type KubernetesSecretManager interface {
+	// ApplySecret creates or updates a Kubernetes secret (and its namespace) in a cluster.
+	ApplySecret(ctx context.Context, clusterID uint, secret corev1.Secret) error
+
+	// DeleteSecret deletes a Kubernetes secret from a cluster if it exists.
+	DeleteSecret(ctx context.Context, clusterID uint, secret corev1.Secret) error
+}
+
+// +testify:mock:testOnly=true
+
+// Starter starts synchronizing bindings in the background.
+type Starter interface {
+	// StartSync starts synchronizing a binding.
+	StartSync(ctx context.Context, organizationID uint, bindingID uint) error
+}
+
+// NewService returns a new Service.
+func NewService(
+	store Store,
+	secrets secret.Store,
+	clusters ClusterStore,
+	syncer Syncer,
+	starter Starter,
+	logger Logger,
+) Service {
+	return service{
+		store:    store,
+		secrets:  secrets,
+		clusters: clusters,
+		syncer:   syncer,
+		starter:  starter,
+
+		logger: logger,
+	}
+}
+
+type service struct {
+	store    Store
+	secrets  secret.Store
+	clusters ClusterStore
+	syncer   Syncer
+	starter  Starter
+
+	logger Logger
+}
+
+func (s service) CreateBinding(ctx context.Context, organizationID uint, request BindingRequest) (Binding, error) {
+	if err := s.validate(ctx, organizationID, request); err != nil {
+		return Binding{}, err
+	}
+
+	bindings, err := s.store.List(ctx, organizationID)
+	if err != nil {
+		return Binding{}, err
+	}
+
+	for _, b := range bindings {
+		if b.Name == request.Name && b.Namespace == request.Namespace {
+			return Binding{}, secret.NewValidationError(
+				"invalid secret binding",
+				[]string{fmt.Sprintf("kubernetes secret %s/%s is already bound", request.Namespace, request.Name)},
+			)
+		}
+	}
+
+	binding := Binding{
+		OrganizationID: organizationID,
+		Name:           request.Name,
+		Namespace:      request.Namespace,
+		Sources:        request.Sources,
+		Target:         request.Target,
+	}
+
+	id, err := s.store.Create(ctx, binding)
+	if err != nil {
+		return Binding{}, err
+	}
+
+	s.startSync(ctx, organizationID, id)
+
+	return s.store.Get(ctx, organizationID, id)
+}
+
+func (s service) ListBindings(ctx context.Context, organizationID uint) ([]Binding, error) {
+	return s.store.List(ctx, organizationID)
+}
+
+func (s service) GetBinding(ctx context.Context, organizationID uint, bindingID uint) (Binding, error) {
+	return s.store.Get(ctx, organizationID, bindingID)
+}
+
+func (s service) UpdateBinding(ctx context.Context, organizationID uint, bindingID uint, request BindingRequest) (Binding, error) {
+	binding, err := s.store.Get(ctx, organizationID, bindingID)
+	if err != nil {
+		return Binding{}, err
+	}
+
+	if request.Name != binding.Name || request.Namespace != binding.Namespace {
+		return Binding{}, secret.NewValidationError(
+			"invalid secret binding",
+			[]string{"the name and the namespace of the kubernetes secret cannot be changed"},
+		)
+	}
+
+	if err := s.validate(ctx, organizationID, request); err != nil {
+		return Binding{}, err
+	}
+
+	binding.Sources = request.Sources
+	binding.Target = request.Target
+
+	if err := s.store.Update(ctx, binding); err != nil {
+		return Binding{}, err
+	}
+
+	s.startSync(ctx, organizationID, bindingID)
+
+	return s.store.Get(ctx, organizationID, bindingID)
+}
+
+func (s service) DeleteBinding(ctx context.Context, organizationID uint, bindingID uint) error {
+	binding, err := s.store.Get(ctx, organizationID, bindingID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.syncer.RemoveBinding(ctx, binding); err != nil {
+		return err
+	}
+
+	return s.store.Delete(ctx, organizationID, bindingID)
+}
+
+func (s service) GetBindingStatus(ctx context.Context, organizationID uint, bindingID uint) ([]ClusterStatus, error) {
+	binding, err := s.store.Get(ctx, organizationID, bindingID)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterIDs, err := s.syncer.TargetClusterIDs(ctx, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.store.ListStatuses(ctx, bindingID)
+	if err != nil {
+		return nil, err
+	}
+
+	statusMap := make(map[uint]ClusterStatus, len(statuses))
+	for _, status := range statuses {
+		statusMap[status.ClusterID] = status
+	}
+
+	result := make([]ClusterStatus, 0, len(clusterIDs))
+
+	for _, clusterID := range clusterIDs {
+		status, ok := statusMap[clusterID]
+		if !ok {
+			status = ClusterStatus{
+				ClusterID: clusterID,
+				Status:    StatusPending,
+			}
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// validate checks that the sources and the targets of a binding exist in the organization.
+func (s service) validate(ctx context.Context, organizationID uint, request BindingRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	var violations []string
+
+	for _, source := range request.Sources {
+		_, err := s.secrets.Get(ctx, organizationID, source.SecretID)
+		if errors.As(err, &secret.NotFoundError{}) {
+			violations = append(violations, fmt.Sprintf("source secret %s cannot be found", source.SecretID))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, clusterID := range request.Target.ClusterIDs {
+		c, err := s.clusters.GetCluster(ctx, clusterID)
+		if (err == nil && c.OrganizationID != organizationID) || cluster.IsNotFoundError(err) {
+			violations = append(violations, fmt.Sprintf("target cluster %d cannot be found", clusterID))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if request.Target.ClusterGroupID != 0 {
+		_, err := s.syncer.TargetClusterIDs(ctx, Binding{OrganizationID: organizationID, Target: request.Target})
+		if errors.As(err, &ClusterGroupNotFoundError{}) {
+			violations = append(violations, fmt.Sprintf("target cluster group %d cannot be found", request.Target.ClusterGroupID))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(violations) > 0 {
+		return secret.NewValidationError("invalid secret binding", violations)
+	}
+
+	return nil
+}
+
+// startSync starts synchronizing a binding.
+// Failures are not returned, since the binding is synchronized by the scheduler eventually.
+func (s service) startSync(ctx context.Context, organizationID uint, bindingID uint) {
+	if err := s.starter.StartSync(ctx, organizationID, bindingID); err != nil {
+		s.logger.Warn("failed to start secret binding synchronization", map[string]interface{}{
+			"organizationId": organizationID,
+			"bindingId":      bindingID,
+			"error":          err.Error(),
+		})
+	}
+}
+
+// NotFoundError is returned when a binding cannot be found.
+type NotFoundError struct {
+	OrganizationID uint
+	BindingID      uint
+}
+
+// Error implements the error interface.
+func (NotFoundError) Error() string {
+	return "secret binding not found"
+}
+
+// Details returns error details.
+func (e NotFoundError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "bindingId", e.BindingID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (NotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (NotFoundError) ServiceError() bool {
+	return true
+}
+
+// ClusterGroupNotFoundError is returned when the target cluster group of a binding cannot be found.
+type ClusterGroupNotFoundError struct {
+	OrganizationID uint
+	ClusterGroupID uint
+}
+
+// Error implements the error interface.
+func (ClusterGroupNotFoundError) Error() string {
+	return "cluster group not found"
+}
+
+// Details returns error details.
+func (e ClusterGroupNotFoundError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "clusterGroupId", e.ClusterGroupID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (ClusterGroupNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (ClusterGroupNotFoundError) ServiceError() bool {
+	return true
+}
@@ -0,0 +1,539 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	internaltesting "github.com/banzaicloud/pipeline/internal/testing"
+)
+
+type inMemoryStore struct {
+	bindings map[uint]Binding
+	statuses map[uint]map[uint]ClusterStatus
+	puts     int
+}
+
+func newInMemoryStore() *inMemoryStore {
+	return &inMemoryStore{
+		bindings: make(map[uint]Binding),
+		statuses: make(map[uint]map[uint]ClusterStatus),
+	}
+}
+
+func (s *inMemoryStore) Create(_ context.Context, binding Binding) (uint, error) {
+	binding.ID = uint(len(s.bindings) + 1)
+	s.bindings[binding.ID] = binding
+
+	return binding.ID, nil
+}
+
+func (s *inMemoryStore) Get(_ context.Context, organizationID uint, id uint) (Binding, error) {
+	binding, ok := s.bindings[id]
+	if !ok || binding.OrganizationID != organizationID {
+		return Binding{}, NotFoundError{OrganizationID: organizationID, BindingID: id}
+	}
+
+	return binding, nil
+}
+
+func (s *inMemoryStore) List(_ context.Context, organizationID uint) ([]Binding, error) {
+	var bindings []Binding
+
+	for _, binding := range s.bindings {
+		if binding.OrganizationID == organizationID {
+			bindings = append(bindings, binding)
+		}
+	}
+
+	return bindings, nil
+}
+
+func (s *inMemoryStore) ListAll(_ context.Context) ([]Binding, error) {
+	var bindings []Binding
+
+	for _, binding := range s.bindings {
+		bindings = append(bindings, binding)
+	}
+
+	return bindings, nil
+}
+
+func (s *inMemoryStore) Update(_ context.Context, binding Binding) error {
+	s.bindings[binding.ID] = binding
+
+	return nil
+}
+
+func (s *inMemoryStore) Delete(_ context.Context, _ uint, id uint) error {
+	delete(s.bindings, id)
+	delete(s.statuses, id)
+
+	return nil
+}
+
+func (s *inMemoryStore) ListStatuses(_ context.Context, id uint) ([]ClusterStatus, error) {
+	var statuses []ClusterStatus
+
+	for _, status := range s.statuses[id] {
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ClusterID < statuses[j].ClusterID })
+
+	return statuses, nil
+}
+
+func (s *inMemoryStore) PutStatus(_ context.Context, id uint, status ClusterStatus) error {
+	if s.statuses[id] == nil {
+		s.statuses[id] = make(map[uint]ClusterStatus)
+	}
+
+	s.statuses[id][status.ClusterID] = status
+	s.puts++
+
+	return nil
+}
+
+func (s *inMemoryStore) DeleteStatus(_ context.Context, id uint, clusterID uint) error {
+	delete(s.statuses[id], clusterID)
+
+	return nil
+}
+
+type clusterStoreStub map[uint]cluster.Cluster
+
+func (s clusterStoreStub) GetCluster(_ context.Context, id uint) (cluster.Cluster, error) {
+	c, ok := s[id]
+	if !ok {
+		return cluster.Cluster{}, cluster.NotFoundError{ClusterID: id}
+	}
+
+	return c, nil
+}
+
+type clusterGroupStoreStub map[uint][]uint
+
+func (s clusterGroupStoreStub) GetMemberIDs(_ context.Context, organizationID uint, clusterGroupID uint) ([]uint, error) {
+	ids, ok := s[clusterGroupID]
+	if !ok {
+		return nil, ClusterGroupNotFoundError{OrganizationID: organizationID, ClusterGroupID: clusterGroupID}
+	}
+
+	return ids, nil
+}
+
+type kubernetesSecretManagerStub struct {
+	secrets map[uint]corev1.Secret
+	failing map[uint]bool
+}
+
+func newKubernetesSecretManagerStub() *kubernetesSecretManagerStub {
+	return &kubernetesSecretManagerStub{
+		secrets: make(map[uint]corev1.Secret),
+		failing: make(map[uint]bool),
+	}
+}
+
+func (m *kubernetesSecretManagerStub) ApplySecret(_ context.Context, clusterID uint, secret corev1.Secret) error {
+	if m.failing[clusterID] {
+		return errors.New("cluster unreachable")
+	}
+
+	m.secrets[clusterID] = secret
+
+	return nil
+}
+
+func (m *kubernetesSecretManagerStub) DeleteSecret(_ context.Context, clusterID uint, _ corev1.Secret) error {
+	if m.failing[clusterID] {
+		return errors.New("cluster unreachable")
+	}
+
+	delete(m.secrets, clusterID)
+
+	return nil
+}
+
+type testFixture struct {
+	store      *inMemoryStore
+	secrets    secret.Store
+	kubernetes *kubernetesSecretManagerStub
+	starter    *MockStarter
+	syncer     Syncer
+	service    Service
+}
+
+func newTestFixture(t *testing.T) testFixture {
+	f := testFixture{
+		store: newInMemoryStore(),
+		secrets: internaltesting.NewSecretStore(
+			t, 1,
+			secret.Model{ID: "registry", Name: "registry", Values: map[string]string{"username": "user", "password": "pass"}},
+			secret.Model{ID: "token", Name: "token", Values: map[string]string{"token": "abc"}},
+		),
+		kubernetes: newKubernetesSecretManagerStub(),
+		starter:    new(MockStarter),
+	}
+
+	clusters := clusterStoreStub{
+		1: {ID: 1, OrganizationID: 1, Status: cluster.Running},
+		2: {ID: 2, OrganizationID: 1, Status: cluster.Running},
+		3: {ID: 3, OrganizationID: 1, Status: cluster.Creating},
+		4: {ID: 4, OrganizationID: 1, Status: cluster.Running},
+		5: {ID: 5, OrganizationID: 2, Status: cluster.Running},
+	}
+	groups := clusterGroupStoreStub{1: {1, 2}}
+
+	f.syncer = NewSyncer(f.store, f.secrets, clusters, groups, f.kubernetes, NoopLogger{})
+	f.service = NewService(f.store, f.secrets, clusters, f.syncer, f.starter, NoopLogger{})
+
+	return f
+}
+
+func TestBindingRequest_Validate(t *testing.T) {
+	valid := BindingRequest{
+		Name:      "registry",
+		Namespace: "default",
+		Sources:   []Source{{SecretID: "registry", Keys: map[string]string{".dockerconfigjson": "config"}}},
+		Target:    Target{ClusterIDs: []uint{1}},
+	}
+
+	tests := map[string]struct {
+		modify func(r *BindingRequest)
+		valid  bool
+	}{
+		"valid": {
+			modify: func(r *BindingRequest) {},
+			valid:  true,
+		},
+		"invalid name": {
+			modify: func(r *BindingRequest) { r.Name = "Registry_Secret" },
+		},
+		"invalid namespace": {
+			modify: func(r *BindingRequest) { r.Namespace = "" },
+		},
+		"no sources": {
+			modify: func(r *BindingRequest) { r.Sources = nil },
+		},
+		"duplicate source": {
+			modify: func(r *BindingRequest) { r.Sources = append(r.Sources, Source{SecretID: "registry"}) },
+		},
+		"duplicate key": {
+			modify: func(r *BindingRequest) {
+				r.Sources = append(r.Sources, Source{SecretID: "other", Keys: map[string]string{".dockerconfigjson": "config"}})
+			},
+		},
+		"invalid key": {
+			modify: func(r *BindingRequest) { r.Sources[0].Keys = map[string]string{"a/b": "config"} },
+		},
+		"no target": {
+			modify: func(r *BindingRequest) { r.Target = Target{} },
+		},
+		"both targets": {
+			modify: func(r *BindingRequest) { r.Target.ClusterGroupID = 1 },
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			request := valid
+			request.Sources = []Source{{SecretID: valid.Sources[0].SecretID, Keys: map[string]string{".dockerconfigjson": "config"}}}
+
+			test.modify(&request)
+
+			err := request.Validate()
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.As(err, &secret.ValidationError{}))
+			}
+		})
+	}
+}
+
+func TestService_CreateBinding(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		f := newTestFixture(t)
+		f.starter.On("StartSync", ctx, uint(1), uint(1)).Return(nil)
+
+		binding, err := f.service.CreateBinding(ctx, 1, BindingRequest{
+			Name:      "registry",
+			Namespace: "default",
+			Sources:   []Source{{SecretID: "registry"}},
+			Target:    Target{ClusterGroupID: 1},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, uint(1), binding.ID)
+		assert.Equal(t, uint(1), binding.OrganizationID)
+		f.starter.AssertExpectations(t)
+	})
+
+	t.Run("starter failure is not returned", func(t *testing.T) {
+		f := newTestFixture(t)
+		f.starter.On("StartSync", ctx, uint(1), uint(1)).Return(errors.New("cadence unavailable"))
+
+		_, err := f.service.CreateBinding(ctx, 1, BindingRequest{
+			Name:      "registry",
+			Namespace: "default",
+			Sources:   []Source{{SecretID: "registry"}},
+			Target:    Target{ClusterIDs: []uint{1}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("missing references", func(t *testing.T) {
+		f := newTestFixture(t)
+
+		_, err := f.service.CreateBinding(ctx, 1, BindingRequest{
+			Name:      "registry",
+			Namespace: "default",
+			Sources:   []Source{{SecretID: "missing"}},
+			Target:    Target{ClusterIDs: []uint{5, 10}},
+		})
+
+		var validationErr secret.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Violations(), 3)
+	})
+
+	t.Run("missing cluster group", func(t *testing.T) {
+		f := newTestFixture(t)
+
+		_, err := f.service.CreateBinding(ctx, 1, BindingRequest{
+			Name:      "registry",
+			Namespace: "default",
+			Sources:   []Source{{SecretID: "registry"}},
+			Target:    Target{ClusterGroupID: 2},
+		})
+
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+
+	t.Run("already bound", func(t *testing.T) {
+		f := newTestFixture(t)
+		f.starter.On("StartSync", ctx, uint(1), uint(1)).Return(nil)
+
+		request := BindingRequest{
+			Name:      "registry",
+			Namespace: "default",
+			Sources:   []Source{{SecretID: "registry"}},
+			Target:    Target{ClusterIDs: []uint{1}},
+		}
+
+		_, err := f.service.CreateBinding(ctx, 1, request)
+		require.NoError(t, err)
+
+		_, err = f.service.CreateBinding(ctx, 1, request)
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+}
+
+func TestService_UpdateBinding(t *testing.T) {
+	ctx := context.Background()
+	f := newTestFixture(t)
+
+	id, err := f.store.Create(ctx, Binding{
+		OrganizationID: 1,
+		Name:           "registry",
+		Namespace:      "default",
+		Sources:        []Source{{SecretID: "registry"}},
+		Target:         Target{ClusterIDs: []uint{1}},
+	})
+	require.NoError(t, err)
+
+	_, err = f.service.UpdateBinding(ctx, 1, id, BindingRequest{
+		Name:      "registry",
+		Namespace: "other",
+		Sources:   []Source{{SecretID: "registry"}},
+		Target:    Target{ClusterIDs: []uint{1}},
+	})
+	assert.True(t, errors.As(err, &secret.ValidationError{}))
+
+	f.starter.On("StartSync", ctx, uint(1), id).Return(nil)
+
+	binding, err := f.service.UpdateBinding(ctx, 1, id, BindingRequest{
+		Name:      "registry",
+		Namespace: "default",
+		Sources:   []Source{{SecretID: "registry"}, {SecretID: "token"}},
+		Target:    Target{ClusterGroupID: 1},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, binding.Sources, 2)
+	assert.Equal(t, Target{ClusterGroupID: 1}, binding.Target)
+	f.starter.AssertExpectations(t)
+}
+
+func TestSyncer_SyncBinding(t *testing.T) {
+	ctx := context.Background()
+	f := newTestFixture(t)
+
+	id, err := f.store.Create(ctx, Binding{
+		OrganizationID: 1,
+		Name:           "registry",
+		Namespace:      "default",
+		Sources: []Source{
+			{SecretID: "registry", Keys: map[string]string{"REGISTRY_USER": "username"}},
+			{SecretID: "token"},
+		},
+		Target: Target{ClusterIDs: []uint{1, 2, 3, 10}},
+	})
+	require.NoError(t, err)
+
+	// Cluster 4 is no longer targeted
+	require.NoError(t, f.store.PutStatus(ctx, id, ClusterStatus{ClusterID: 4, Status: StatusSynced}))
+	f.kubernetes.secrets[4] = corev1.Secret{}
+	f.kubernetes.failing[2] = true
+
+	err = f.syncer.SyncBinding(ctx, 1, id)
+	require.Error(t, err)
+
+	require.Contains(t, f.kubernetes.secrets, uint(1))
+	assert.NotContains(t, f.kubernetes.secrets, uint(3))
+	assert.NotContains(t, f.kubernetes.secrets, uint(4))
+
+	kubeSecret := f.kubernetes.secrets[1]
+	assert.Equal(t, "registry", kubeSecret.Name)
+	assert.Equal(t, "default", kubeSecret.Namespace)
+	assert.Equal(t, "1", kubeSecret.Labels[BindingLabel])
+	assert.Equal(t, map[string][]byte{"REGISTRY_USER": []byte("user"), "token": []byte("abc")}, kubeSecret.Data)
+
+	statuses, err := f.store.ListStatuses(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, uint(1), statuses[0].ClusterID)
+	assert.Equal(t, StatusSynced, statuses[0].Status)
+	assert.Equal(t, kubeSecret.Annotations[ChecksumAnnotation], statuses[0].Checksum)
+	require.NotNil(t, statuses[0].SyncedAt)
+
+	assert.Equal(t, uint(2), statuses[1].ClusterID)
+	assert.Equal(t, StatusFailed, statuses[1].Status)
+	assert.Equal(t, "cluster unreachable", statuses[1].Message)
+
+	// Nothing changed
+	f.kubernetes.failing[2] = false
+	require.NoError(t, f.syncer.SyncBinding(ctx, 1, id))
+
+	puts := f.store.puts
+	require.NoError(t, f.syncer.SyncBinding(ctx, 1, id))
+	assert.Equal(t, puts, f.store.puts)
+
+	// Source changed
+	require.NoError(t, f.secrets.Put(ctx, 1, secret.Model{ID: "token", Name: "token", Values: map[string]string{"token": "def"}}))
+
+	require.NoError(t, f.syncer.SyncBinding(ctx, 1, id))
+	assert.Equal(t, []byte("def"), f.kubernetes.secrets[1].Data["token"])
+	assert.Equal(t, []byte("def"), f.kubernetes.secrets[2].Data["token"])
+
+	statuses, err = f.store.ListStatuses(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, f.kubernetes.secrets[1].Annotations[ChecksumAnnotation], statuses[0].Checksum)
+	assert.NotEqual(t, kubeSecret.Annotations[ChecksumAnnotation], statuses[0].Checksum)
+
+	// Source key is removed
+	require.NoError(t, f.secrets.Put(ctx, 1, secret.Model{ID: "registry", Name: "registry", Values: map[string]string{"password": "pass"}}))
+
+	require.Error(t, f.syncer.SyncBinding(ctx, 1, id))
+
+	statuses, err = f.store.ListStatuses(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, statuses[0].Status)
+	assert.Contains(t, statuses[0].Message, "username")
+}
+
+func TestService_DeleteBinding(t *testing.T) {
+	ctx := context.Background()
+	f := newTestFixture(t)
+
+	id, err := f.store.Create(ctx, Binding{
+		OrganizationID: 1,
+		Name:           "registry",
+		Namespace:      "default",
+		Sources:        []Source{{SecretID: "registry"}},
+		Target:         Target{ClusterGroupID: 1},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.syncer.SyncBinding(ctx, 1, id))
+	require.Len(t, f.kubernetes.secrets, 2)
+
+	f.kubernetes.failing[2] = true
+
+	err = f.service.DeleteBinding(ctx, 1, id)
+	require.Error(t, err)
+
+	_, err = f.store.Get(ctx, 1, id)
+	require.NoError(t, err, "binding should be kept until every kubernetes secret is removed")
+
+	f.kubernetes.failing[2] = false
+
+	err = f.service.DeleteBinding(ctx, 1, id)
+	require.NoError(t, err)
+
+	assert.Empty(t, f.kubernetes.secrets)
+
+	_, err = f.store.Get(ctx, 1, id)
+	assert.True(t, errors.As(err, &NotFoundError{}))
+}
+
+func TestService_GetBindingStatus(t *testing.T) {
+	ctx := context.Background()
+	f := newTestFixture(t)
+
+	id, err := f.store.Create(ctx, Binding{
+		OrganizationID: 1,
+		Name:           "registry",
+		Namespace:      "default",
+		Sources:        []Source{{SecretID: "registry"}},
+		Target:         Target{ClusterIDs: []uint{1, 3}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.syncer.SyncBinding(ctx, 1, id))
+
+	statuses, err := f.service.GetBindingStatus(ctx, 1, id)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, StatusSynced, statuses[0].Status)
+	assert.Equal(t, ClusterStatus{ClusterID: 3, Status: StatusPending}, statuses[1])
+
+	_, err = f.service.GetBindingStatus(ctx, 2, id)
+	assert.True(t, errors.As(err, &NotFoundError{}))
+}
+
+func TestSyncer_SyncBinding_Deleted(t *testing.T) {
+	f := newTestFixture(t)
+
+	err := f.syncer.SyncBinding(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, f.kubernetes.secrets)
+}
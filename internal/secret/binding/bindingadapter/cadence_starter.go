@@ -0,0 +1,85 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding/bindingworkflow"
+)
+
+// CadenceStarter starts secret binding synchronization workflows.
+type CadenceStarter struct {
+	workflowClient client.Client
+}
+
+// NewCadenceStarter returns a new CadenceStarter.
+func NewCadenceStarter(workflowClient client.Client) CadenceStarter {
+	return CadenceStarter{
+		workflowClient: workflowClient,
+	}
+}
+
+// StartSync implements the binding.Starter interface.
+// It does nothing if the binding is already being synchronized.
+func (s CadenceStarter) StartSync(ctx context.Context, organizationID uint, bindingID uint) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           bindingworkflow.SyncBindingWorkflowID(bindingID),
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 30 * time.Minute,
+		WorkflowIDReusePolicy:        client.WorkflowIDReusePolicyAllowDuplicate,
+	}
+
+	input := bindingworkflow.SyncBindingWorkflowInput{
+		OrganizationID: organizationID,
+		BindingID:      bindingID,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, bindingworkflow.SyncBindingWorkflowName, input)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", bindingworkflow.SyncBindingWorkflowName)
+	}
+
+	return nil
+}
+
+// StartScheduler starts the cron workflow that synchronizes every binding periodically.
+// It does nothing if the scheduler is already running.
+func (s CadenceStarter) StartScheduler(ctx context.Context, schedule string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           bindingworkflow.SyncSchedulerWorkflowName,
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 12 * time.Hour,
+		CronSchedule:                 schedule,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, bindingworkflow.SyncSchedulerWorkflowName)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", bindingworkflow.SyncSchedulerWorkflowName)
+	}
+
+	return nil
+}
+
+func isAlreadyStartedError(err error) bool {
+	var alreadyStartedErr *shared.WorkflowExecutionAlreadyStartedError
+
+	return errors.As(err, &alreadyStartedErr)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/clustergroup"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+// ClusterGroupRepository provides access to cluster group models.
+type ClusterGroupRepository interface {
+	FindOne(cg clustergroup.ClusterGroupModel) (*clustergroup.ClusterGroupModel, error)
+}
+
+// ClusterGroupStore returns the members of cluster groups.
+type ClusterGroupStore struct {
+	repository ClusterGroupRepository
+}
+
+// NewClusterGroupStore returns a new ClusterGroupStore.
+func NewClusterGroupStore(repository ClusterGroupRepository) ClusterGroupStore {
+	return ClusterGroupStore{
+		repository: repository,
+	}
+}
+
+// GetMemberIDs implements the binding.ClusterGroupStore interface.
+func (s ClusterGroupStore) GetMemberIDs(ctx context.Context, organizationID uint, clusterGroupID uint) ([]uint, error) {
+	clusterGroup, err := s.repository.FindOne(clustergroup.ClusterGroupModel{
+		ID:             clusterGroupID,
+		OrganizationID: organizationID,
+	})
+	if clustergroup.IsClusterGroupNotFoundError(err) {
+		return nil, errors.WithStack(binding.ClusterGroupNotFoundError{
+			OrganizationID: organizationID,
+			ClusterGroupID: clusterGroupID,
+		})
+	} else if err != nil {
+		return nil, errors.WrapIfWithDetails(
+			err, "failed to get cluster group",
+			"organizationId", organizationID,
+			"clusterGroupId", clusterGroupID,
+		)
+	}
+
+	ids := make([]uint, 0, len(clusterGroup.Members))
+	for _, member := range clusterGroup.Members {
+		ids = append(ids, member.ClusterID)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,309 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingadapter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+// bindingModel describes the secret binding model.
+type bindingModel struct {
+	ID                   uint `gorm:"primary_key"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	OrganizationID       uint   `gorm:"unique_index:idx_secret_bindings_org_namespace_name"`
+	Namespace            string `gorm:"unique_index:idx_secret_bindings_org_namespace_name"`
+	Name                 string `gorm:"unique_index:idx_secret_bindings_org_namespace_name"`
+	Sources              string `gorm:"type:text"`
+	TargetClusterIDs     string `gorm:"type:text"`
+	TargetClusterGroupID uint
+}
+
+// TableName changes the default table name.
+func (bindingModel) TableName() string {
+	return "secret_bindings"
+}
+
+// statusModel describes the synchronization status of a secret binding in a cluster.
+type statusModel struct {
+	ID        uint `gorm:"primary_key"`
+	UpdatedAt time.Time
+	BindingID uint `gorm:"unique_index:idx_secret_binding_statuses_binding_cluster"`
+	ClusterID uint `gorm:"unique_index:idx_secret_binding_statuses_binding_cluster"`
+	Status    string
+	Message   string `gorm:"type:text"`
+	Checksum  string
+	SyncedAt  *time.Time
+}
+
+// TableName changes the default table name.
+func (statusModel) TableName() string {
+	return "secret_binding_statuses"
+}
+
+// GormStore is a secret binding store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// Create implements the binding.Store interface.
+func (s GormStore) Create(ctx context.Context, b binding.Binding) (uint, error) {
+	model, err := fromBinding(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.db.Create(&model).Error; err != nil {
+		return 0, errors.WrapIfWithDetails(
+			err, "failed to create secret binding",
+			"organizationId", b.OrganizationID,
+			"namespace", b.Namespace,
+			"name", b.Name,
+		)
+	}
+
+	return model.ID, nil
+}
+
+// Get implements the binding.Store interface.
+func (s GormStore) Get(ctx context.Context, organizationID uint, id uint) (binding.Binding, error) {
+	var model bindingModel
+
+	err := s.db.Where(bindingModel{ID: id, OrganizationID: organizationID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return binding.Binding{}, errors.WithStack(binding.NotFoundError{
+			OrganizationID: organizationID,
+			BindingID:      id,
+		})
+	} else if err != nil {
+		return binding.Binding{}, errors.WrapIfWithDetails(
+			err, "failed to get secret binding",
+			"organizationId", organizationID,
+			"bindingId", id,
+		)
+	}
+
+	return toBinding(model)
+}
+
+// List implements the binding.Store interface.
+func (s GormStore) List(ctx context.Context, organizationID uint) ([]binding.Binding, error) {
+	var models []bindingModel
+
+	if err := s.db.Where(bindingModel{OrganizationID: organizationID}).Order("id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list secret bindings", "organizationId", organizationID)
+	}
+
+	return toBindings(models)
+}
+
+// ListAll implements the binding.Store interface.
+func (s GormStore) ListAll(ctx context.Context) ([]binding.Binding, error) {
+	var models []bindingModel
+
+	if err := s.db.Order("id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIf(err, "failed to list secret bindings")
+	}
+
+	return toBindings(models)
+}
+
+// Update implements the binding.Store interface.
+func (s GormStore) Update(ctx context.Context, b binding.Binding) error {
+	model, err := fromBinding(b)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Model(&bindingModel{ID: b.ID}).
+		Where(bindingModel{OrganizationID: b.OrganizationID}).
+		Updates(map[string]interface{}{ // Zero values are ignored when a struct is used here
+			"sources":                 model.Sources,
+			"target_cluster_ids":      model.TargetClusterIDs,
+			"target_cluster_group_id": model.TargetClusterGroupID,
+		}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to update secret binding",
+			"organizationId", b.OrganizationID,
+			"bindingId", b.ID,
+		)
+	}
+
+	return nil
+}
+
+// Delete implements the binding.Store interface.
+func (s GormStore) Delete(ctx context.Context, organizationID uint, id uint) error {
+	result := s.db.Where(bindingModel{ID: id, OrganizationID: organizationID}).Delete(bindingModel{})
+	if result.Error != nil {
+		return errors.WrapIfWithDetails(
+			result.Error, "failed to delete secret binding",
+			"organizationId", organizationID,
+			"bindingId", id,
+		)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := s.db.Where(statusModel{BindingID: id}).Delete(statusModel{}).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to delete secret binding statuses", "bindingId", id)
+	}
+
+	return nil
+}
+
+// ListStatuses implements the binding.Store interface.
+func (s GormStore) ListStatuses(ctx context.Context, id uint) ([]binding.ClusterStatus, error) {
+	var models []statusModel
+
+	if err := s.db.Where(statusModel{BindingID: id}).Order("cluster_id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list secret binding statuses", "bindingId", id)
+	}
+
+	statuses := make([]binding.ClusterStatus, 0, len(models))
+
+	for _, model := range models {
+		statuses = append(statuses, binding.ClusterStatus{
+			ClusterID: model.ClusterID,
+			Status:    model.Status,
+			Message:   model.Message,
+			Checksum:  model.Checksum,
+			SyncedAt:  model.SyncedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// PutStatus implements the binding.Store interface.
+func (s GormStore) PutStatus(ctx context.Context, id uint, status binding.ClusterStatus) error {
+	var model statusModel
+
+	err := s.db.
+		Where(statusModel{BindingID: id, ClusterID: status.ClusterID}).
+		Assign(map[string]interface{}{ // Zero values are ignored when a struct is used here
+			"status":    status.Status,
+			"message":   status.Message,
+			"checksum":  status.Checksum,
+			"synced_at": status.SyncedAt,
+		}).
+		FirstOrCreate(&model).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to save secret binding status",
+			"bindingId", id,
+			"clusterId", status.ClusterID,
+		)
+	}
+
+	return nil
+}
+
+// DeleteStatus implements the binding.Store interface.
+func (s GormStore) DeleteStatus(ctx context.Context, id uint, clusterID uint) error {
+	err := s.db.Where(statusModel{BindingID: id, ClusterID: clusterID}).Delete(statusModel{}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to delete secret binding status",
+			"bindingId", id,
+			"clusterId", clusterID,
+		)
+	}
+
+	return nil
+}
+
+func fromBinding(b binding.Binding) (bindingModel, error) {
+	sources, err := json.Marshal(b.Sources)
+	if err != nil {
+		return bindingModel{}, errors.WrapIf(err, "failed to encode secret binding sources")
+	}
+
+	var clusterIDs []byte
+	if len(b.Target.ClusterIDs) > 0 {
+		clusterIDs, err = json.Marshal(b.Target.ClusterIDs)
+		if err != nil {
+			return bindingModel{}, errors.WrapIf(err, "failed to encode secret binding target clusters")
+		}
+	}
+
+	return bindingModel{
+		ID:                   b.ID,
+		OrganizationID:       b.OrganizationID,
+		Namespace:            b.Namespace,
+		Name:                 b.Name,
+		Sources:              string(sources),
+		TargetClusterIDs:     string(clusterIDs),
+		TargetClusterGroupID: b.Target.ClusterGroupID,
+	}, nil
+}
+
+func toBinding(model bindingModel) (binding.Binding, error) {
+	b := binding.Binding{
+		ID:             model.ID,
+		OrganizationID: model.OrganizationID,
+		Name:           model.Name,
+		Namespace:      model.Namespace,
+		Target: binding.Target{
+			ClusterGroupID: model.TargetClusterGroupID,
+		},
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+
+	if err := json.Unmarshal([]byte(model.Sources), &b.Sources); err != nil {
+		return binding.Binding{}, errors.WrapIfWithDetails(err, "failed to decode secret binding sources", "bindingId", model.ID)
+	}
+
+	if model.TargetClusterIDs != "" {
+		if err := json.Unmarshal([]byte(model.TargetClusterIDs), &b.Target.ClusterIDs); err != nil {
+			return binding.Binding{}, errors.WrapIfWithDetails(err, "failed to decode secret binding target clusters", "bindingId", model.ID)
+		}
+	}
+
+	return b, nil
+}
+
+func toBindings(models []bindingModel) ([]binding.Binding, error) {
+	bindings := make([]binding.Binding, 0, len(models))
+
+	for _, model := range models {
+		b, err := toBinding(model)
+		if err != nil {
+			return nil, err
+		}
+
+		bindings = append(bindings, b)
+	}
+
+	return bindings, nil
+}
@@ -0,0 +1,124 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.Get(ctx, 1, 1)
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &binding.NotFoundError{}))
+
+	b := binding.Binding{
+		OrganizationID: 1,
+		Name:           "registry",
+		Namespace:      "default",
+		Sources: []binding.Source{
+			{SecretID: "secret1", Keys: map[string]string{"username": "user"}},
+		},
+		Target: binding.Target{ClusterIDs: []uint{1, 2}},
+	}
+
+	id, err := store.Create(ctx, b)
+	require.NoError(t, err)
+
+	_, err = store.Create(ctx, binding.Binding{OrganizationID: 2, Name: "other", Namespace: "default", Target: binding.Target{ClusterGroupID: 1}})
+	require.NoError(t, err)
+
+	b.Sources = append(b.Sources, binding.Source{SecretID: "secret2"})
+	b.Target = binding.Target{ClusterGroupID: 3}
+	b.ID = id
+
+	err = store.Update(ctx, b)
+	require.NoError(t, err)
+
+	actual, err := store.Get(ctx, 1, id)
+	require.NoError(t, err)
+	assert.Equal(t, b.Sources, actual.Sources)
+	assert.Equal(t, b.Target, actual.Target)
+	assert.Equal(t, "registry", actual.Name)
+
+	_, err = store.Get(ctx, 2, id)
+	assert.True(t, errors.As(err, &binding.NotFoundError{}))
+
+	bindings, err := store.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, bindings, 1)
+	assert.Equal(t, id, bindings[0].ID)
+
+	bindings, err = store.ListAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, bindings, 2)
+
+	syncedAt := time.Date(2020, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+	err = store.PutStatus(ctx, id, binding.ClusterStatus{ClusterID: 1, Status: binding.StatusFailed, Message: "unreachable"})
+	require.NoError(t, err)
+
+	err = store.PutStatus(ctx, id, binding.ClusterStatus{ClusterID: 1, Status: binding.StatusSynced, Checksum: "abc", SyncedAt: &syncedAt})
+	require.NoError(t, err)
+
+	err = store.PutStatus(ctx, id, binding.ClusterStatus{ClusterID: 2, Status: binding.StatusFailed, Message: "unreachable"})
+	require.NoError(t, err)
+
+	err = store.DeleteStatus(ctx, id, 2)
+	require.NoError(t, err)
+
+	statuses, err := store.ListStatuses(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, uint(1), statuses[0].ClusterID)
+	assert.Equal(t, binding.StatusSynced, statuses[0].Status)
+	assert.Equal(t, "", statuses[0].Message)
+	assert.Equal(t, "abc", statuses[0].Checksum)
+	require.NotNil(t, statuses[0].SyncedAt)
+	assert.True(t, syncedAt.Equal(*statuses[0].SyncedAt))
+
+	err = store.Delete(ctx, 1, id)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, 1, id)
+	assert.True(t, errors.As(err, &binding.NotFoundError{}))
+
+	statuses, err = store.ListStatuses(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
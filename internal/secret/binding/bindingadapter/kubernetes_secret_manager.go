@@ -0,0 +1,128 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+// KubernetesService provides access to the objects of clusters.
+type KubernetesService interface {
+	GetObject(ctx context.Context, clusterID uint, objRef corev1.ObjectReference, obj runtime.Object) error
+	EnsureObject(ctx context.Context, clusterID uint, o runtime.Object) error
+	Update(ctx context.Context, clusterID uint, o runtime.Object) error
+	DeleteObject(ctx context.Context, clusterID uint, o runtime.Object) error
+}
+
+// KubernetesSecretManager manages the Kubernetes secrets of bindings.
+// Secrets that are not created by the same binding are never changed.
+type KubernetesSecretManager struct {
+	kubernetes KubernetesService
+}
+
+// NewKubernetesSecretManager returns a new KubernetesSecretManager.
+func NewKubernetesSecretManager(kubernetes KubernetesService) KubernetesSecretManager {
+	return KubernetesSecretManager{
+		kubernetes: kubernetes,
+	}
+}
+
+// ApplySecret implements the binding.KubernetesSecretManager interface.
+func (m KubernetesSecretManager) ApplySecret(ctx context.Context, clusterID uint, secret corev1.Secret) error {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secret.Namespace,
+		},
+	}
+
+	if err := m.kubernetes.EnsureObject(ctx, clusterID, namespace); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to ensure namespace", "namespace", secret.Namespace)
+	}
+
+	current, err := m.get(ctx, clusterID, secret)
+	if k8sapierrors.IsNotFound(errors.Cause(err)) {
+		return errors.WrapIf(m.kubernetes.EnsureObject(ctx, clusterID, &secret), "failed to create kubernetes secret")
+	} else if err != nil {
+		return err
+	}
+
+	if current.Labels[binding.BindingLabel] != secret.Labels[binding.BindingLabel] {
+		return errors.NewWithDetails(
+			"kubernetes secret exists, but it is not managed by the binding",
+			"namespace", secret.Namespace,
+			"name", secret.Name,
+		)
+	}
+
+	if current.Annotations[binding.ChecksumAnnotation] == secret.Annotations[binding.ChecksumAnnotation] {
+		return nil
+	}
+
+	if current.Annotations == nil {
+		current.Annotations = make(map[string]string, len(secret.Annotations))
+	}
+
+	for key, value := range secret.Annotations {
+		current.Annotations[key] = value
+	}
+
+	current.Data = secret.Data
+
+	return errors.WrapIf(m.kubernetes.Update(ctx, clusterID, &current), "failed to update kubernetes secret")
+}
+
+// DeleteSecret implements the binding.KubernetesSecretManager interface.
+func (m KubernetesSecretManager) DeleteSecret(ctx context.Context, clusterID uint, secret corev1.Secret) error {
+	current, err := m.get(ctx, clusterID, secret)
+	if k8sapierrors.IsNotFound(errors.Cause(err)) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if current.Labels[binding.BindingLabel] != secret.Labels[binding.BindingLabel] {
+		return nil
+	}
+
+	return errors.WrapIf(m.kubernetes.DeleteObject(ctx, clusterID, &current), "failed to delete kubernetes secret")
+}
+
+func (m KubernetesSecretManager) get(ctx context.Context, clusterID uint, secret corev1.Secret) (corev1.Secret, error) {
+	var current corev1.Secret
+
+	objRef := corev1.ObjectReference{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	}
+
+	err := m.kubernetes.GetObject(ctx, clusterID, objRef, &current)
+	if err != nil && !k8sapierrors.IsNotFound(errors.Cause(err)) {
+		return current, errors.WrapIfWithDetails(
+			err, "failed to get kubernetes secret",
+			"namespace", secret.Namespace,
+			"name", secret.Name,
+		)
+	}
+
+	return current, err
+}
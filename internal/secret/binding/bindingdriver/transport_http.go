@@ -0,0 +1,206 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingdriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodPost).Path("").Handler(kithttp.NewServer(
+		endpoints.CreateBinding,
+		decodeCreateBindingHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeCreateBindingHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("").Handler(kithttp.NewServer(
+		endpoints.ListBindings,
+		decodeListBindingsHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeListBindingsHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/{bindingId}").Handler(kithttp.NewServer(
+		endpoints.GetBinding,
+		decodeGetBindingHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetBindingHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPut).Path("/{bindingId}").Handler(kithttp.NewServer(
+		endpoints.UpdateBinding,
+		decodeUpdateBindingHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeUpdateBindingHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("/{bindingId}").Handler(kithttp.NewServer(
+		endpoints.DeleteBinding,
+		decodeDeleteBindingHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodGet).Path("/{bindingId}/status").Handler(kithttp.NewServer(
+		endpoints.GetBindingStatus,
+		decodeGetBindingStatusHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetBindingStatusHTTPResponse, errorEncoder),
+		options...,
+	))
+}
+
+func decodeOrganizationID(r *http.Request) (uint, error) {
+	orgIDStr, ok := mux.Vars(r)["orgId"]
+	if !ok || orgIDStr == "" {
+		return 0, errors.NewWithDetails("missing parameter from the URL", "param", "orgId")
+	}
+
+	orgID, err := strconv.ParseUint(orgIDStr, 0, 0)
+	if err != nil {
+		return 0, errors.WrapIf(err, "invalid organization ID format")
+	}
+
+	return uint(orgID), nil
+}
+
+func decodeBindingParams(r *http.Request) (uint, uint, error) {
+	orgID, err := decodeOrganizationID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	bindingIDStr, ok := mux.Vars(r)["bindingId"]
+	if !ok || bindingIDStr == "" {
+		return 0, 0, errors.NewWithDetails("missing parameter from the URL", "param", "bindingId")
+	}
+
+	bindingID, err := strconv.ParseUint(bindingIDStr, 0, 0)
+	if err != nil {
+		return 0, 0, errors.WrapIf(err, "invalid binding ID format")
+	}
+
+	return orgID, uint(bindingID), nil
+}
+
+func decodeCreateBindingHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, err := decodeOrganizationID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var request binding.BindingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	return CreateBindingRequest{OrganizationID: orgID, Request: request}, nil
+}
+
+func encodeCreateBindingHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(CreateBindingResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(resp.Binding, http.StatusCreated))
+}
+
+func decodeListBindingsHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, err := decodeOrganizationID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListBindingsRequest{OrganizationID: orgID}, nil
+}
+
+func encodeListBindingsHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(ListBindingsResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Bindings)
+}
+
+func decodeGetBindingHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, bindingID, err := decodeBindingParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetBindingRequest{OrganizationID: orgID, BindingID: bindingID}, nil
+}
+
+func encodeGetBindingHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetBindingResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Binding)
+}
+
+func decodeUpdateBindingHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, bindingID, err := decodeBindingParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var request binding.BindingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	return UpdateBindingRequest{OrganizationID: orgID, BindingID: bindingID, Request: request}, nil
+}
+
+func encodeUpdateBindingHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(UpdateBindingResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Binding)
+}
+
+func decodeDeleteBindingHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, bindingID, err := decodeBindingParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeleteBindingRequest{OrganizationID: orgID, BindingID: bindingID}, nil
+}
+
+func decodeGetBindingStatusHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, bindingID, err := decodeBindingParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetBindingStatusRequest{OrganizationID: orgID, BindingID: bindingID}, nil
+}
+
+func encodeGetBindingStatusHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetBindingStatusResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Statuses)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+const ListBindingsActivityName = "secret-binding-list-bindings"
+
+// ListBindingsActivity lists the secret bindings of every organization.
+type ListBindingsActivity struct {
+	syncer binding.Syncer
+}
+
+type ListBindingsActivityInput struct{}
+
+type ListBindingsActivityOutput struct {
+	Bindings []BindingRef
+}
+
+// BindingRef identifies a secret binding.
+type BindingRef struct {
+	OrganizationID uint
+	BindingID      uint
+}
+
+// NewListBindingsActivity returns a new ListBindingsActivity.
+func NewListBindingsActivity(syncer binding.Syncer) ListBindingsActivity {
+	return ListBindingsActivity{
+		syncer: syncer,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ListBindingsActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ListBindingsActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ListBindingsActivity) Execute(ctx context.Context, _ ListBindingsActivityInput) (ListBindingsActivityOutput, error) {
+	bindings, err := a.syncer.ListBindings(ctx)
+	if err != nil {
+		return ListBindingsActivityOutput{}, err
+	}
+
+	refs := make([]BindingRef, 0, len(bindings))
+	for _, b := range bindings {
+		refs = append(refs, BindingRef{
+			OrganizationID: b.OrganizationID,
+			BindingID:      b.ID,
+		})
+	}
+
+	return ListBindingsActivityOutput{
+		Bindings: refs,
+	}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+const SyncBindingActivityName = "secret-binding-sync-binding"
+
+// SyncBindingActivity synchronizes the Kubernetes secret of a binding to its target clusters.
+type SyncBindingActivity struct {
+	syncer binding.Syncer
+}
+
+type SyncBindingActivityInput struct {
+	OrganizationID uint
+	BindingID      uint
+}
+
+// NewSyncBindingActivity returns a new SyncBindingActivity.
+func NewSyncBindingActivity(syncer binding.Syncer) SyncBindingActivity {
+	return SyncBindingActivity{
+		syncer: syncer,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a SyncBindingActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: SyncBindingActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a SyncBindingActivity) Execute(ctx context.Context, input SyncBindingActivityInput) error {
+	return a.syncer.SyncBinding(ctx, input.OrganizationID, input.BindingID)
+}
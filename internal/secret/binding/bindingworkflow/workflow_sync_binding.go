@@ -0,0 +1,67 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const SyncBindingWorkflowName = "sync-secret-binding"
+
+// SyncBindingWorkflowID returns the ID of the workflow synchronizing a binding.
+// There can be only one synchronization running for a binding at a time.
+func SyncBindingWorkflowID(bindingID uint) string {
+	return fmt.Sprintf("%s-%d", SyncBindingWorkflowName, bindingID)
+}
+
+// SyncBindingWorkflow synchronizes the Kubernetes secret of a binding to its target clusters.
+type SyncBindingWorkflow struct{}
+
+type SyncBindingWorkflowInput struct {
+	OrganizationID uint
+	BindingID      uint
+}
+
+// NewSyncBindingWorkflow returns a new SyncBindingWorkflow.
+func NewSyncBindingWorkflow() SyncBindingWorkflow {
+	return SyncBindingWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w SyncBindingWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: SyncBindingWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+// Failures are recorded in the status of the binding and retried by the scheduler,
+// so the activity is not retried here.
+func (w SyncBindingWorkflow) Execute(ctx workflow.Context, input SyncBindingWorkflowInput) error {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    15 * time.Minute,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	activityInput := SyncBindingActivityInput{
+		OrganizationID: input.OrganizationID,
+		BindingID:      input.BindingID,
+	}
+
+	return workflow.ExecuteActivity(ctx, SyncBindingActivityName, activityInput).Get(ctx, nil)
+}
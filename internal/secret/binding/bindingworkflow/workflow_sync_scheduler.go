@@ -0,0 +1,90 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const SyncSchedulerWorkflowName = "secret-binding-sync-scheduler"
+
+// SyncSchedulerWorkflow synchronizes every secret binding, so that Kubernetes secrets follow the changes of their sources.
+type SyncSchedulerWorkflow struct{}
+
+// NewSyncSchedulerWorkflow returns a new SyncSchedulerWorkflow.
+func NewSyncSchedulerWorkflow() SyncSchedulerWorkflow {
+	return SyncSchedulerWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w SyncSchedulerWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: SyncSchedulerWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w SyncSchedulerWorkflow) Execute(ctx workflow.Context) error {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	var output ListBindingsActivityOutput
+
+	err := workflow.ExecuteActivity(ctx, ListBindingsActivityName, ListBindingsActivityInput{}).Get(ctx, &output)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	futures := make([]workflow.ChildWorkflowFuture, 0, len(output.Bindings))
+
+	for _, ref := range output.Bindings {
+		childWorkflowOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:                   SyncBindingWorkflowID(ref.BindingID),
+			ExecutionStartToCloseTimeout: 30 * time.Minute,
+			TaskStartToCloseTimeout:      30 * time.Second,
+		}
+
+		workflowInput := SyncBindingWorkflowInput{
+			OrganizationID: ref.OrganizationID,
+			BindingID:      ref.BindingID,
+		}
+
+		futures = append(futures, workflow.ExecuteChildWorkflow(
+			workflow.WithChildOptions(ctx, childWorkflowOptions),
+			SyncBindingWorkflowName,
+			workflowInput,
+		))
+	}
+
+	// Synchronization failures are recorded in the binding statuses, they should not stop the scheduler
+	for i, future := range futures {
+		if err := future.Get(ctx, nil); err != nil {
+			logger.Warnw(
+				"failed to synchronize secret binding",
+				"organizationId", output.Bindings[i].OrganizationID,
+				"bindingId", output.Bindings[i].BindingID,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindingworkflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/secret/binding"
+)
+
+// nolint: gochecknoinits
+func init() {
+	NewListBindingsActivity(binding.Syncer{}).Register()
+	NewSyncBindingActivity(binding.Syncer{}).Register()
+	NewSyncBindingWorkflow().Register()
+}
+
+type SyncSchedulerWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestSyncSchedulerWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(SyncSchedulerWorkflowTestSuite))
+}
+
+func (s *SyncSchedulerWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewSyncSchedulerWorkflow().Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+}
+
+func (s *SyncSchedulerWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *SyncSchedulerWorkflowTestSuite) Test_Success() {
+	s.env.OnActivity(ListBindingsActivityName, mock.Anything, ListBindingsActivityInput{}).Return(
+		ListBindingsActivityOutput{
+			Bindings: []BindingRef{
+				{OrganizationID: 1, BindingID: 1},
+				{OrganizationID: 2, BindingID: 2},
+			},
+		},
+		nil,
+	)
+
+	s.env.OnActivity(SyncBindingActivityName, mock.Anything, SyncBindingActivityInput{OrganizationID: 1, BindingID: 1}).Return(nil)
+	s.env.OnActivity(SyncBindingActivityName, mock.Anything, SyncBindingActivityInput{OrganizationID: 2, BindingID: 2}).Return(errors.New("cluster unreachable"))
+
+	s.env.ExecuteWorkflow(s.T().Name())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *SyncSchedulerWorkflowTestSuite) Test_ListFailed() {
+	s.env.OnActivity(ListBindingsActivityName, mock.Anything, ListBindingsActivityInput{}).Return(ListBindingsActivityOutput{}, errors.New("database error"))
+
+	s.env.ExecuteWorkflow(s.T().Name())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
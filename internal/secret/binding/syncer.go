@@ -0,0 +1,312 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/kubesecret"
+)
+
+// Labels and annotations of Kubernetes secrets managed by bindings.
+const (
+	BindingLabel       = "secret.banzaicloud.io/binding"
+	ChecksumAnnotation = "secret.banzaicloud.io/binding-checksum"
+)
+
+// Syncer synchronizes the Kubernetes secrets of bindings with their source Pipeline secrets.
+type Syncer struct {
+	store      Store
+	secrets    secret.Store
+	clusters   ClusterStore
+	groups     ClusterGroupStore
+	kubernetes KubernetesSecretManager
+
+	logger Logger
+}
+
+// NewSyncer returns a new Syncer.
+func NewSyncer(
+	store Store,
+	secrets secret.Store,
+	clusters ClusterStore,
+	groups ClusterGroupStore,
+	kubernetes KubernetesSecretManager,
+	logger Logger,
+) Syncer {
+	return Syncer{
+		store:      store,
+		secrets:    secrets,
+		clusters:   clusters,
+		groups:     groups,
+		kubernetes: kubernetes,
+
+		logger: logger,
+	}
+}
+
+// ListBindings lists the bindings of every organization.
+func (s Syncer) ListBindings(ctx context.Context) ([]Binding, error) {
+	return s.store.ListAll(ctx)
+}
+
+// TargetClusterIDs returns the IDs of the clusters a binding should be synchronized to.
+func (s Syncer) TargetClusterIDs(ctx context.Context, binding Binding) ([]uint, error) {
+	if binding.Target.ClusterGroupID == 0 {
+		return binding.Target.ClusterIDs, nil
+	}
+
+	return s.groups.GetMemberIDs(ctx, binding.OrganizationID, binding.Target.ClusterGroupID)
+}
+
+// SyncBinding synchronizes the Kubernetes secret of a binding to its running target clusters
+// and removes it from the clusters that are no longer targeted.
+// The result of the synchronization is recorded in the status of the binding for each cluster.
+func (s Syncer) SyncBinding(ctx context.Context, organizationID uint, bindingID uint) error {
+	binding, err := s.store.Get(ctx, organizationID, bindingID)
+	if errors.As(err, &NotFoundError{}) { // Binding is deleted in the meantime
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	logger := s.logger.WithFields(map[string]interface{}{
+		"organizationId": organizationID,
+		"bindingId":      bindingID,
+	})
+
+	clusterIDs, err := s.TargetClusterIDs(ctx, binding)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list target clusters")
+	}
+
+	statuses, err := s.store.ListStatuses(ctx, bindingID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list binding statuses")
+	}
+
+	statusMap := make(map[uint]ClusterStatus, len(statuses))
+	for _, status := range statuses {
+		statusMap[status.ClusterID] = status
+	}
+
+	kubeSecret, renderErr := s.render(ctx, binding)
+
+	var errs []error
+
+	targets := make(map[uint]bool, len(clusterIDs))
+
+	for _, clusterID := range clusterIDs {
+		targets[clusterID] = true
+
+		c, err := s.clusters.GetCluster(ctx, clusterID)
+		if cluster.IsNotFoundError(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, errors.WrapIfWithDetails(err, "failed to get cluster", "clusterId", clusterID))
+
+			continue
+		}
+
+		// Secrets are synchronized to running clusters only, the rest are synchronized once they are up again
+		if c.OrganizationID != organizationID || (c.Status != cluster.Running && c.Status != cluster.Warning) {
+			continue
+		}
+
+		previous := statusMap[clusterID]
+		status := ClusterStatus{
+			ClusterID: clusterID,
+			SyncedAt:  previous.SyncedAt,
+		}
+
+		if renderErr != nil {
+			status.Status = StatusFailed
+			status.Message = renderErr.Error()
+			status.Checksum = previous.Checksum
+		} else if err := s.kubernetes.ApplySecret(ctx, clusterID, *kubeSecret.DeepCopy()); err != nil {
+			status.Status = StatusFailed
+			status.Message = err.Error()
+			status.Checksum = previous.Checksum
+
+			errs = append(errs, errors.WrapIfWithDetails(err, "failed to apply kubernetes secret", "clusterId", clusterID))
+		} else {
+			status.Status = StatusSynced
+			status.Checksum = kubeSecret.Annotations[ChecksumAnnotation]
+
+			if previous.Status != StatusSynced || previous.Checksum != status.Checksum {
+				now := time.Now()
+				status.SyncedAt = &now
+
+				logger.Info("kubernetes secret synchronized", map[string]interface{}{"clusterId": clusterID})
+			}
+		}
+
+		if status.Status == previous.Status && status.Message == previous.Message && status.Checksum == previous.Checksum {
+			continue
+		}
+
+		if err := s.store.PutStatus(ctx, bindingID, status); err != nil {
+			errs = append(errs, errors.WrapIfWithDetails(err, "failed to save binding status", "clusterId", clusterID))
+		}
+	}
+
+	for _, status := range statuses {
+		if targets[status.ClusterID] {
+			continue
+		}
+
+		if err := s.removeFromCluster(ctx, binding, status.ClusterID); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		logger.Info("kubernetes secret removed from cluster no longer targeted", map[string]interface{}{"clusterId": status.ClusterID})
+	}
+
+	if renderErr != nil {
+		errs = append(errs, renderErr)
+	}
+
+	return errors.Combine(errs...)
+}
+
+// RemoveBinding removes the Kubernetes secret of a binding from every cluster it was synchronized to.
+func (s Syncer) RemoveBinding(ctx context.Context, binding Binding) error {
+	statuses, err := s.store.ListStatuses(ctx, binding.ID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list binding statuses")
+	}
+
+	var errs []error
+
+	for _, status := range statuses {
+		if err := s.removeFromCluster(ctx, binding, status.ClusterID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Combine(errs...)
+}
+
+// removeFromCluster deletes the Kubernetes secret of a binding from a cluster and forgets its status.
+// Deleted clusters are ignored.
+func (s Syncer) removeFromCluster(ctx context.Context, binding Binding, clusterID uint) error {
+	_, err := s.clusters.GetCluster(ctx, clusterID)
+	if err != nil && !cluster.IsNotFoundError(err) {
+		return errors.WrapIfWithDetails(err, "failed to get cluster", "clusterId", clusterID)
+	}
+
+	if err == nil {
+		if err := s.kubernetes.DeleteSecret(ctx, clusterID, s.kubeSecretMeta(binding)); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to delete kubernetes secret", "clusterId", clusterID)
+		}
+	}
+
+	if err := s.store.DeleteStatus(ctx, binding.ID, clusterID); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to delete binding status", "clusterId", clusterID)
+	}
+
+	return nil
+}
+
+// render creates the Kubernetes secret of a binding from the current values of its sources.
+func (s Syncer) render(ctx context.Context, binding Binding) (corev1.Secret, error) {
+	data := make(map[string][]byte)
+
+	for _, source := range binding.Sources {
+		model, err := s.secrets.Get(ctx, binding.OrganizationID, source.SecretID)
+		if errors.As(err, &secret.NotFoundError{}) {
+			return corev1.Secret{}, errors.Errorf("source secret %s cannot be found", source.SecretID)
+		} else if err != nil {
+			return corev1.Secret{}, errors.WrapIfWithDetails(err, "failed to get source secret", "secretId", source.SecretID)
+		}
+
+		keys := source.Keys
+		if len(keys) == 0 {
+			keys = make(map[string]string, len(model.Values))
+			for key := range model.Values {
+				keys[key] = key
+			}
+		}
+
+		for kubeKey, secretKey := range keys {
+			value, ok := model.Values[secretKey]
+			if !ok {
+				return corev1.Secret{}, errors.Errorf("source secret %s has no key %q", model.Name, secretKey)
+			}
+
+			if _, ok := data[kubeKey]; ok {
+				return corev1.Secret{}, errors.Errorf("key %q is provided by more than one source secret", kubeKey)
+			}
+
+			data[kubeKey] = []byte(value)
+		}
+	}
+
+	kubeSecret := s.kubeSecretMeta(binding)
+	kubeSecret.Type = corev1.SecretTypeOpaque
+	kubeSecret.Data = data
+	kubeSecret.Annotations = map[string]string{
+		ChecksumAnnotation: checksum(data),
+	}
+
+	return kubeSecret, nil
+}
+
+// kubeSecretMeta returns the identifying metadata of the Kubernetes secret of a binding.
+func (s Syncer) kubeSecretMeta(binding Binding) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binding.Name,
+			Namespace: binding.Namespace,
+			Labels: map[string]string{
+				kubesecret.ManagedByLabel: kubesecret.ManagedByLabelValue,
+				BindingLabel:              strconv.FormatUint(uint64(binding.ID), 10),
+			},
+		},
+	}
+}
+
+// checksum calculates a checksum of secret data that does not depend on the order of keys.
+func checksum(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(hash, "%d:%s%d:", len(key), key, len(data[key]))
+		_, _ = hash.Write(data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
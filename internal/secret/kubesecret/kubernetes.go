@@ -26,6 +26,15 @@ import (
 	"github.com/banzaicloud/pipeline/src/secret"
 )
 
+// Labels and annotations of Kubernetes Secrets installed from pipeline secrets.
+// They allow updating installed secrets when the source secret changes (eg. after rotation).
+const (
+	ManagedByLabel         = "secret.banzaicloud.io/managed-by"
+	ManagedByLabelValue    = "pipeline"
+	SourceSecretAnnotation = "secret.banzaicloud.io/source-secret"
+	SourceSpecAnnotation   = "secret.banzaicloud.io/source-spec"
+)
+
 // KubeSecretRequest contains details for a Kubernetes Secret creation from pipeline secrets.
 type KubeSecretRequest struct {
 	Name      string
@@ -33,10 +42,29 @@ type KubeSecretRequest struct {
 	Type      string
 	Values    map[string]string
 	Spec      KubeSecretSpec
+
+	// SourceSecretName is the name of the pipeline secret (if any) the Kubernetes Secret is created from.
+	SourceSecretName string
 }
 
 type KubeSecretSpec map[string]KubeSecretSpecItem
 
+// ParseSourceSpec parses the spec a Kubernetes Secret was installed with from its annotations.
+func ParseSourceSpec(annotations map[string]string) (KubeSecretSpec, error) {
+	var spec KubeSecretSpec
+
+	rawSpec, ok := annotations[SourceSpecAnnotation]
+	if !ok {
+		return spec, nil
+	}
+
+	if err := json.Unmarshal([]byte(rawSpec), &spec); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal secret spec")
+	}
+
+	return spec, nil
+}
+
 type KubeSecretSpecItem struct {
 	Source    string
 	SourceMap map[string]string
@@ -53,6 +81,24 @@ func CreateKubeSecret(req KubeSecretRequest) (v1.Secret, error) {
 		StringData: map[string]string{},
 	}
 
+	if req.SourceSecretName != "" {
+		kubeSecret.ObjectMeta.Labels = map[string]string{
+			ManagedByLabel: ManagedByLabelValue,
+		}
+		kubeSecret.ObjectMeta.Annotations = map[string]string{
+			SourceSecretAnnotation: req.SourceSecretName,
+		}
+
+		if len(req.Spec) > 0 {
+			rawSpec, err := json.Marshal(req.Spec)
+			if err != nil {
+				return kubeSecret, errors.Wrap(err, "could not marshal secret spec")
+			}
+
+			kubeSecret.ObjectMeta.Annotations[SourceSpecAnnotation] = string(rawSpec)
+		}
+	}
+
 	secretMeta := secrettype.DefaultRules[req.Type]
 	opaqueMap := make(map[string]bool, len(secretMeta.Fields))
 
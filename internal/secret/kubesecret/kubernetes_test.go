@@ -197,6 +197,38 @@ func TestCreateKubeSecret(t *testing.T) {
 				},
 			},
 		},
+		"secret with source secret": {
+			v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret",
+					Namespace: "namespace",
+					Labels: map[string]string{
+						kubesecret.ManagedByLabel: kubesecret.ManagedByLabelValue,
+					},
+					Annotations: map[string]string{
+						kubesecret.SourceSecretAnnotation: "source",
+						kubesecret.SourceSpecAnnotation:   `{"tls.crt":{"Source":"clientCert","SourceMap":null,"Value":""}}`,
+					},
+				},
+				StringData: map[string]string{
+					"tls.crt": "tlscert",
+				},
+			},
+			kubesecret.KubeSecretRequest{
+				Name:      "secret",
+				Namespace: "namespace",
+				Type:      "generic",
+				Values: map[string]string{
+					"clientCert": "tlscert",
+				},
+				Spec: map[string]kubesecret.KubeSecretSpecItem{
+					"tls.crt": {
+						Source: "clientCert",
+					},
+				},
+				SourceSecretName: "source",
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -208,3 +240,30 @@ func TestCreateKubeSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSourceSpec(t *testing.T) {
+	spec := kubesecret.KubeSecretSpec{
+		"tls.crt": {
+			Source: "clientCert",
+		},
+	}
+
+	kubeSecret, err := kubesecret.CreateKubeSecret(kubesecret.KubeSecretRequest{
+		Name:             "secret",
+		Type:             "generic",
+		Values:           map[string]string{"clientCert": "tlscert"},
+		Spec:             spec,
+		SourceSecretName: "source",
+	})
+	require.NoError(t, err)
+
+	actual, err := kubesecret.ParseSourceSpec(kubeSecret.Annotations)
+	require.NoError(t, err)
+
+	assert.Equal(t, spec, actual)
+
+	actual, err = kubesecret.ParseSourceSpec(nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, actual)
+}
@@ -0,0 +1,147 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkesecret
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+)
+
+// Validity of the generated CAs (matching the lease TTLs of the Vault PKI engines).
+const (
+	rootCAValidity         = 43801 * time.Hour
+	intermediateCAValidity = 43800 * time.Hour
+)
+
+// NewLocalPkeSecreter returns a PKE secreter that generates the cluster CAs in process.
+// It is used when Pipeline runs without Vault.
+func NewLocalPkeSecreter() LocalPkeSecreter {
+	return LocalPkeSecreter{}
+}
+
+// LocalPkeSecreter generates the CAs of a PKE cluster without a PKI engine.
+//
+// The private key of the root CA is discarded once the intermediate CAs are signed,
+// just like Vault never exports the key of an internally generated root CA.
+type LocalPkeSecreter struct{}
+
+func (LocalPkeSecreter) GeneratePkeSecret(organizationID uint, tags []string) (map[string]string, error) {
+	clusterID := getClusterIDFromTags(tags)
+	if clusterID == "" {
+		return nil, errors.New("clusterID is missing from the tags")
+	}
+
+	rootKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error generating root CA key for cluster %s", clusterID)
+	}
+
+	rootTemplate, err := caTemplate(fmt.Sprintf("cluster-%s-ca", clusterID), rootCAValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error generating root CA for cluster %s", clusterID)
+	}
+
+	rootCert, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing root CA for cluster %s", clusterID)
+	}
+
+	ca := string(encodeCertificatePEM(rootDER))
+
+	var cas []*certificate
+
+	for _, commonName := range []string{
+		secrettype.KubernetesCACommonName,
+		secrettype.EtcdCACommonName,
+		secrettype.KubernetesFrontProxyCACommonName,
+	} {
+		cert, err := generateLocalIntermediateCert(clusterID, commonName, rootCert, rootKey)
+		if err != nil {
+			return nil, err
+		}
+
+		cas = append(cas, cert)
+	}
+
+	return pkeSecretValues(clusterID, ca, cas[0], cas[1], cas[2])
+}
+
+// DeletePkeSecret is a no-op: the generated CAs are only stored in the secret itself.
+func (LocalPkeSecreter) DeletePkeSecret(organizationID uint, tags []string) error {
+	return nil
+}
+
+func generateLocalIntermediateCert(clusterID, commonName string, rootCert *x509.Certificate, rootKey *rsa.PrivateKey) (*certificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error generating %s intermediate key for cluster %s", commonName, clusterID)
+	}
+
+	template, err := caTemplate(commonName, intermediateCAValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, rootCert, &key.PublicKey, rootKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error signing %s intermediate cert for cluster %s", commonName, clusterID)
+	}
+
+	return &certificate{
+		Key:  string(encodePrivateKeyPEM(key)),
+		Cert: string(encodeCertificatePEM(der)),
+	}, nil
+}
+
+func caTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate certificate serial number")
+	}
+
+	now := time.Now()
+
+	return &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-30 * time.Second),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil
+}
+
+func encodeCertificatePEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	})
+}
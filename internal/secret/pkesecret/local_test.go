@@ -0,0 +1,96 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkesecret
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
+)
+
+func TestLocalPkeSecreter_GeneratePkeSecret(t *testing.T) {
+	values, err := NewLocalPkeSecreter().GeneratePkeSecret(1, []string{"clusterID:42"})
+	require.NoError(t, err)
+
+	for _, key := range []string{
+		secrettype.KubernetesCAKey,
+		secrettype.EtcdCAKey,
+		secrettype.FrontProxyCAKey,
+		secrettype.SAPub,
+		secrettype.SAKey,
+		secrettype.EncryptionSecret,
+	} {
+		assert.NotEmpty(t, values[key], key)
+	}
+
+	for _, test := range []struct {
+		certKey    string
+		commonName string
+	}{
+		{secrettype.KubernetesCACert, secrettype.KubernetesCACommonName},
+		{secrettype.EtcdCACert, secrettype.EtcdCACommonName},
+		{secrettype.FrontProxyCACert, secrettype.KubernetesFrontProxyCACommonName},
+	} {
+		test := test
+
+		t.Run(test.commonName, func(t *testing.T) {
+			certs := parseCertificates(t, values[test.certKey])
+			require.Len(t, certs, 2)
+
+			intermediate, root := certs[0], certs[1]
+
+			assert.Equal(t, test.commonName, intermediate.Subject.CommonName)
+			assert.True(t, intermediate.IsCA)
+			assert.Equal(t, "cluster-42-ca", root.Subject.CommonName)
+			assert.NoError(t, intermediate.CheckSignatureFrom(root))
+		})
+	}
+
+	signingCerts := parseCertificates(t, values[secrettype.KubernetesCASigningCert])
+	require.Len(t, signingCerts, 1)
+	assert.Equal(t, secrettype.KubernetesCACommonName, signingCerts[0].Subject.CommonName)
+}
+
+func TestLocalPkeSecreter_GeneratePkeSecret_MissingClusterID(t *testing.T) {
+	_, err := NewLocalPkeSecreter().GeneratePkeSecret(1, nil)
+
+	assert.Error(t, err)
+}
+
+func parseCertificates(t *testing.T, data string) []*x509.Certificate {
+	var certs []*x509.Certificate
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+
+		certs = append(certs, cert)
+	}
+
+	return certs
+}
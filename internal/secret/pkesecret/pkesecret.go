@@ -142,6 +142,11 @@ func (s PkeSecreter) GeneratePkeSecret(organizationID uint, tags []string) (map[
 		return nil, err
 	}
 
+	return pkeSecretValues(clusterID, ca, kubernetesCA, etcdCA, frontProxyCA)
+}
+
+// pkeSecretValues assembles the values of a PKE secret from the generated CAs.
+func pkeSecretValues(clusterID string, rootCA string, kubernetesCA, etcdCA, frontProxyCA *certificate) (map[string]string, error) {
 	// Service Account key-pair
 	saPub, saPriv, err := generateSAKeyPair(clusterID)
 	if err != nil {
@@ -158,12 +163,12 @@ func (s PkeSecreter) GeneratePkeSecret(organizationID uint, tags []string) (map[
 
 	return map[string]string{
 		secrettype.KubernetesCAKey:         kubernetesCA.Key,
-		secrettype.KubernetesCACert:        kubernetesCA.Cert + "\n" + ca,
+		secrettype.KubernetesCACert:        kubernetesCA.Cert + "\n" + rootCA,
 		secrettype.KubernetesCASigningCert: kubernetesCA.Cert,
 		secrettype.EtcdCAKey:               etcdCA.Key,
-		secrettype.EtcdCACert:              etcdCA.Cert + "\n" + ca,
+		secrettype.EtcdCACert:              etcdCA.Cert + "\n" + rootCA,
 		secrettype.FrontProxyCAKey:         frontProxyCA.Key,
-		secrettype.FrontProxyCACert:        frontProxyCA.Cert + "\n" + ca,
+		secrettype.FrontProxyCACert:        frontProxyCA.Cert + "\n" + rootCA,
 		secrettype.SAPub:                   saPub,
 		secrettype.SAKey:                   saPriv,
 		secrettype.EncryptionSecret:        encryptionSecret,
@@ -44,6 +44,10 @@ type secretStore interface {
 	Store(orgID uint, request *secret.CreateSecretRequest) (string, error)
 	Update(orgID uint, secretID string, request *secret.CreateSecretRequest) error
 	Verify(organizationID uint, secretID string) error
+	SoftDelete(organizationID uint, secretID string) error
+	ListVersions(organizationID uint, secretID string) ([]secret.SecretVersionResponse, error)
+	GetVersion(organizationID uint, secretID string, version int) (*secret.SecretItemResponse, error)
+	Rollback(organizationID uint, secretID string, version int, updatedBy string) error
 }
 
 func (s *restrictedSecretStore) List(orgid uint, query *secret.ListSecretsQuery) ([]*secret.SecretItemResponse, error) {
@@ -87,6 +91,62 @@ func (s *restrictedSecretStore) Verify(organizationID uint, secretID string) err
 	return s.secretStore.Verify(organizationID, secretID)
 }
 
+func (s *restrictedSecretStore) SoftDelete(organizationID uint, secretID string) error {
+	if err := s.checkBlockingTags(organizationID, secretID); err != nil {
+		return err
+	}
+
+	return s.secretStore.SoftDelete(organizationID, secretID)
+}
+
+func (s *restrictedSecretStore) ListVersions(organizationID uint, secretID string) ([]secret.SecretVersionResponse, error) {
+	versions, err := s.secretStore.ListVersions(organizationID, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the forbidden tags of the latest readable version (the secret itself might be soft deleted)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Deleted {
+			continue
+		}
+
+		if _, err := s.GetVersion(organizationID, secretID, versions[i].Version); err != nil {
+			return nil, err
+		}
+
+		break
+	}
+
+	return versions, nil
+}
+
+func (s *restrictedSecretStore) GetVersion(organizationID uint, secretID string, version int) (*secret.SecretItemResponse, error) {
+	secretItem, err := s.secretStore.GetVersion(organizationID, secretID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := HasForbiddenTag(secretItem.Tags); err != nil {
+		return nil, err
+	}
+
+	return secretItem, nil
+}
+
+func (s *restrictedSecretStore) Rollback(organizationID uint, secretID string, version int, updatedBy string) error {
+	// soft deleted secrets can be restored as well
+	if err := s.checkBlockingTags(organizationID, secretID); err != nil && err != secret.ErrSecretNotExists {
+		return err
+	}
+
+	if _, err := s.GetVersion(organizationID, secretID, version); err != nil {
+		return err
+	}
+
+	return s.secretStore.Rollback(organizationID, secretID, version, updatedBy)
+}
+
 func (s *restrictedSecretStore) checkBlockingTags(organizationID uint, secretID string) error {
 	secretItem, err := s.secretStore.Get(organizationID, secretID)
 	if err != nil {
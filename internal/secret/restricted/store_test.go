@@ -18,6 +18,11 @@ import (
 	"reflect"
 	"testing"
 
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalsecret "github.com/banzaicloud/pipeline/internal/secret"
 	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
 	"github.com/banzaicloud/pipeline/src/secret"
 )
@@ -38,9 +43,7 @@ func TestBlockingTags(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			store := restrictedSecretStore{
-				secretStore: inMemorySecretStore{
-					secrets: make(map[uint]map[string]secret.CreateSecretRequest),
-				},
+				secretStore: newInMemorySecretStore(),
 			}
 
 			secretID, err := store.Store(orgID, tc.request)
@@ -73,6 +76,135 @@ func TestBlockingTags(t *testing.T) {
 	}
 }
 
+func TestBlockingTags_SoftDelete(t *testing.T) {
+	store := restrictedSecretStore{
+		secretStore: newInMemorySecretStore(),
+	}
+
+	secretID, err := store.Store(orgID, &requestReadOnly)
+	if err != nil {
+		t.Fatalf("error during storing readonly secret: %s", err.Error())
+	}
+
+	err = store.SoftDelete(orgID, secretID)
+
+	expErr := ReadOnlyError{SecretID: secretID}
+	if !reflect.DeepEqual(err, expErr) {
+		t.Errorf("expected error: %v, got: %v", expErr, err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	store := restrictedSecretStore{
+		secretStore: newInMemorySecretStore(),
+	}
+
+	request := requestPassword
+	secretID, err := store.Store(orgID, &request)
+	require.NoError(t, err)
+
+	request.Values = map[string]string{"key": "new value"}
+	require.NoError(t, store.Update(orgID, secretID, &request))
+
+	versions, err := store.ListVersions(orgID, secretID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	first, err := store.GetVersion(orgID, secretID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "value", first.Values["key"])
+
+	_, err = store.GetVersion(orgID, secretID, 3)
+	assert.True(t, errors.As(err, &internalsecret.VersionNotFoundError{}))
+
+	require.NoError(t, store.Rollback(orgID, secretID, 1, "bob"))
+
+	latest, err := store.Get(orgID, secretID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, latest.Version)
+	assert.Equal(t, "value", latest.Values["key"])
+	assert.Equal(t, "bob", latest.UpdatedBy)
+}
+
+func TestVersions_SoftDeleted(t *testing.T) {
+	store := restrictedSecretStore{
+		secretStore: newInMemorySecretStore(),
+	}
+
+	request := requestPassword
+	secretID, err := store.Store(orgID, &request)
+	require.NoError(t, err)
+
+	require.NoError(t, store.SoftDelete(orgID, secretID))
+
+	versions, err := store.ListVersions(orgID, secretID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.True(t, versions[1].Deleted)
+
+	require.NoError(t, store.Rollback(orgID, secretID, 1, "bob"))
+
+	latest, err := store.Get(orgID, secretID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, latest.Version)
+}
+
+func TestVersions_Forbidden(t *testing.T) {
+	inner := newInMemorySecretStore()
+	store := restrictedSecretStore{
+		secretStore: inner,
+	}
+
+	request := requestForbidden
+	secretID, err := store.Store(orgID, &request)
+	require.NoError(t, err)
+
+	_, err = store.ListVersions(orgID, secretID)
+	assert.IsType(t, ForbiddenError{}, err)
+
+	_, err = store.GetVersion(orgID, secretID, 1)
+	assert.IsType(t, ForbiddenError{}, err)
+
+	err = store.Rollback(orgID, secretID, 1, "bob")
+	assert.IsType(t, ForbiddenError{}, err)
+
+	// the history of soft deleted secrets is protected as well
+	require.NoError(t, inner.SoftDelete(orgID, secretID))
+
+	_, err = store.ListVersions(orgID, secretID)
+	assert.IsType(t, ForbiddenError{}, err)
+
+	err = store.Rollback(orgID, secretID, 1, "bob")
+	assert.IsType(t, ForbiddenError{}, err)
+
+	_, err = inner.Get(orgID, secretID)
+	assert.Equal(t, secret.ErrSecretNotExists, err)
+}
+
+func TestVersions_ReadOnly(t *testing.T) {
+	store := restrictedSecretStore{
+		secretStore: newInMemorySecretStore(),
+	}
+
+	request := requestReadOnly
+	secretID, err := store.Store(orgID, &request)
+	require.NoError(t, err)
+
+	versions, err := store.ListVersions(orgID, secretID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	_, err = store.GetVersion(orgID, secretID, 1)
+	require.NoError(t, err)
+
+	err = store.Rollback(orgID, secretID, 1, "bob")
+	assert.Equal(t, ReadOnlyError{SecretID: secretID}, err)
+
+	versions, err = store.ListVersions(orgID, secretID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
+
 // nolint: gochecknoglobals
 var (
 	requestReadOnly = secret.CreateSecretRequest{
@@ -87,6 +219,15 @@ var (
 		UpdatedBy: "banzaiuser",
 	}
 
+	requestPassword = secret.CreateSecretRequest{
+		Name: "password",
+		Type: secrettype.Password,
+		Values: map[string]string{
+			"key": "value",
+		},
+		UpdatedBy: "banzaiuser",
+	}
+
 	requestForbidden = secret.CreateSecretRequest{
 		Name: "forbidden",
 		Type: secrettype.Password,
@@ -98,14 +239,32 @@ var (
 	}
 )
 
+// inMemorySecretStore keeps every version of the secrets in memory.
+// Soft deleted versions are stored as nil.
 type inMemorySecretStore struct {
-	secrets map[uint]map[string]secret.CreateSecretRequest
+	secrets map[uint]map[string][]*secret.CreateSecretRequest
+}
+
+func newInMemorySecretStore() inMemorySecretStore {
+	return inMemorySecretStore{
+		secrets: make(map[uint]map[string][]*secret.CreateSecretRequest),
+	}
 }
 
 func (ss inMemorySecretStore) Verify(organizationID uint, secretID string) error {
 	panic("implement me")
 }
 
+func (ss inMemorySecretStore) put(orgID uint, secretID string, request *secret.CreateSecretRequest) {
+	os := ss.secrets[orgID]
+	if os == nil {
+		os = make(map[string][]*secret.CreateSecretRequest)
+		ss.secrets[orgID] = os
+	}
+
+	os[secretID] = append(os[secretID], request)
+}
+
 func (ss inMemorySecretStore) Delete(orgID uint, secretID string) error {
 	if os, ok := ss.secrets[orgID]; ok {
 		delete(os, secretID)
@@ -114,59 +273,106 @@ func (ss inMemorySecretStore) Delete(orgID uint, secretID string) error {
 }
 
 func (ss inMemorySecretStore) Get(orgID uint, secretID string) (*secret.SecretItemResponse, error) {
-	if os := ss.secrets[orgID]; os != nil {
-		if s, ok := os[secretID]; ok {
-			return &secret.SecretItemResponse{
-				ID:        secretID,
-				Name:      s.Name,
-				Type:      s.Type,
-				Values:    s.Values,
-				Tags:      s.Tags,
-				Version:   1,
-				UpdatedBy: s.UpdatedBy,
-			}, nil
-		}
+	versions := ss.secrets[orgID][secretID]
+	if len(versions) == 0 || versions[len(versions)-1] == nil {
+		return nil, secret.ErrSecretNotExists
 	}
-	return nil, secret.ErrSecretNotExists
+
+	return secretItem(secretID, len(versions), versions[len(versions)-1]), nil
 }
 
 func (ss inMemorySecretStore) List(orgID uint, query *secret.ListSecretsQuery) ([]*secret.SecretItemResponse, error) {
 	var list []*secret.SecretItemResponse
-	for _, os := range ss.secrets {
-		for secretID, s := range os {
-			list = append(list, &secret.SecretItemResponse{
-				ID:        secretID,
-				Name:      s.Name,
-				Type:      s.Type,
-				Values:    s.Values,
-				Tags:      s.Tags,
-				Version:   1,
-				UpdatedBy: s.UpdatedBy,
-			})
+	for secretID := range ss.secrets[orgID] {
+		if s, err := ss.Get(orgID, secretID); err == nil {
+			list = append(list, s)
 		}
 	}
 	return list, nil
 }
 
 func (ss inMemorySecretStore) Store(orgID uint, request *secret.CreateSecretRequest) (string, error) {
-	os := ss.secrets[orgID]
-	if os == nil {
-		os = make(map[string]secret.CreateSecretRequest)
-		ss.secrets[orgID] = os
-	}
-
 	secretID := secret.GenerateSecretID(request)
-	os[secretID] = *request
+	r := *request
+	ss.put(orgID, secretID, &r)
 
 	return secretID, nil
 }
 
 func (ss inMemorySecretStore) Update(orgID uint, secretID string, request *secret.CreateSecretRequest) error {
-	if os := ss.secrets[orgID]; os != nil {
-		if _, ok := os[secretID]; ok {
-			os[secretID] = *request
-			return nil
+	if len(ss.secrets[orgID][secretID]) == 0 {
+		return secret.ErrSecretNotExists
+	}
+
+	r := *request
+	ss.put(orgID, secretID, &r)
+
+	return nil
+}
+
+func (ss inMemorySecretStore) SoftDelete(orgID uint, secretID string) error {
+	if _, err := ss.Get(orgID, secretID); err != nil {
+		return nil
+	}
+
+	ss.put(orgID, secretID, nil)
+
+	return nil
+}
+
+func (ss inMemorySecretStore) ListVersions(orgID uint, secretID string) ([]secret.SecretVersionResponse, error) {
+	versions := ss.secrets[orgID][secretID]
+	if len(versions) == 0 {
+		return nil, secret.ErrSecretNotExists
+	}
+
+	responseItems := make([]secret.SecretVersionResponse, 0, len(versions))
+	for i, v := range versions {
+		item := secret.SecretVersionResponse{Version: i + 1, Deleted: v == nil}
+		if v != nil {
+			item.UpdatedBy = v.UpdatedBy
 		}
+
+		responseItems = append(responseItems, item)
+	}
+
+	return responseItems, nil
+}
+
+func (ss inMemorySecretStore) GetVersion(orgID uint, secretID string, version int) (*secret.SecretItemResponse, error) {
+	versions := ss.secrets[orgID][secretID]
+	if version < 1 || version > len(versions) || versions[version-1] == nil {
+		return nil, internalsecret.VersionNotFoundError{OrganizationID: orgID, SecretID: secretID, Version: version}
+	}
+
+	return secretItem(secretID, version, versions[version-1]), nil
+}
+
+func (ss inMemorySecretStore) Rollback(orgID uint, secretID string, version int, updatedBy string) error {
+	s, err := ss.GetVersion(orgID, secretID, version)
+	if err != nil {
+		return err
+	}
+
+	ss.put(orgID, secretID, &secret.CreateSecretRequest{
+		Name:      s.Name,
+		Type:      s.Type,
+		Values:    s.Values,
+		Tags:      s.Tags,
+		UpdatedBy: updatedBy,
+	})
+
+	return nil
+}
+
+func secretItem(secretID string, version int, request *secret.CreateSecretRequest) *secret.SecretItemResponse {
+	return &secret.SecretItemResponse{
+		ID:        secretID,
+		Name:      request.Name,
+		Type:      request.Type,
+		Values:    request.Values,
+		Tags:      request.Tags,
+		Version:   version,
+		UpdatedBy: request.UpdatedBy,
 	}
-	return secret.ErrSecretNotExists
 }
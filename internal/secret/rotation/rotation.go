@@ -0,0 +1,309 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	pipelineSecret "github.com/banzaicloud/pipeline/src/secret"
+)
+
+// UpdatedBy is recorded as the author of secret versions created by rotation.
+const UpdatedBy = "pipeline"
+
+// Config contains the configuration of the rotation scheduler.
+type Config struct {
+	// Enabled turns the rotation scheduler on.
+	Enabled bool
+
+	// Schedule is the cron schedule of checking rotation policies.
+	Schedule string
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.Enabled && c.Schedule == "" {
+		return errors.New("secret rotation schedule is required")
+	}
+
+	return nil
+}
+
+// Policy describes when a secret should be rotated.
+type Policy struct {
+	// IntervalDays rotates a secret periodically (0 turns periodic rotation off).
+	IntervalDays int `json:"intervalDays,omitempty"`
+
+	// DaysBeforeExpiry rotates a secret before it expires (0 turns rotation before expiry off).
+	DaysBeforeExpiry int `json:"daysBeforeExpiry,omitempty"`
+
+	// NotificationSecretID is the ID of a Slack secret used for sending notifications about rotations.
+	NotificationSecretID string `json:"notificationSecretId,omitempty"`
+}
+
+// Validate validates a rotation policy.
+func (p Policy) Validate() error {
+	var violations []string
+
+	if p.IntervalDays < 0 {
+		violations = append(violations, "intervalDays must not be negative")
+	}
+
+	if p.DaysBeforeExpiry < 0 {
+		violations = append(violations, "daysBeforeExpiry must not be negative")
+	}
+
+	if p.IntervalDays == 0 && p.DaysBeforeExpiry == 0 {
+		violations = append(violations, "either intervalDays or daysBeforeExpiry is required")
+	}
+
+	if len(violations) > 0 {
+		return secret.NewValidationError("invalid rotation policy", violations)
+	}
+
+	return nil
+}
+
+// Due tells whether a secret should be rotated.
+// updatedAt is the time of the last change of the secret, expiresAt is zero for secrets that do not expire.
+func (p Policy) Due(now time.Time, updatedAt time.Time, expiresAt time.Time) bool {
+	if p.IntervalDays > 0 && !now.Before(updatedAt.AddDate(0, 0, p.IntervalDays)) {
+		return true
+	}
+
+	if p.DaysBeforeExpiry > 0 && !expiresAt.IsZero() && !now.Before(expiresAt.AddDate(0, 0, -p.DaysBeforeExpiry)) {
+		return true
+	}
+
+	return false
+}
+
+// SecretPolicy is the rotation policy of a secret.
+type SecretPolicy struct {
+	OrganizationID uint
+	SecretID       string
+	Policy         Policy
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service manages secret rotation policies.
+type Service interface {
+	// GetPolicy returns the rotation policy of a secret.
+	GetPolicy(ctx context.Context, organizationID uint, secretID string) (policy Policy, err error)
+
+	// SetPolicy creates or replaces the rotation policy of a secret.
+	SetPolicy(ctx context.Context, organizationID uint, secretID string, policy Policy) error
+
+	// DeletePolicy deletes the rotation policy of a secret.
+	DeletePolicy(ctx context.Context, organizationID uint, secretID string) error
+
+	// RotateSecret starts rotating a secret immediately.
+	// It returns the ID of the rotation process.
+	RotateSecret(ctx context.Context, organizationID uint, secretID string) (processID string, err error)
+}
+
+// +testify:mock:testOnly=true
+
+// Store persists rotation policies.
+type Store interface {
+	// Get returns the rotation policy of a secret.
+	// Returns a PolicyNotFoundError when the secret has no rotation policy.
+	Get(ctx context.Context, organizationID uint, secretID string) (Policy, error)
+
+	// Put creates or replaces the rotation policy of a secret.
+	Put(ctx context.Context, organizationID uint, secretID string, policy Policy) error
+
+	// Delete deletes the rotation policy of a secret.
+	Delete(ctx context.Context, organizationID uint, secretID string) error
+
+	// List lists the rotation policies of every organization.
+	List(ctx context.Context) ([]SecretPolicy, error)
+}
+
+// +testify:mock:testOnly=true
+
+// Starter starts rotating secrets in the background.
+type Starter interface {
+	// StartRotation starts rotating a secret.
+	// It returns the ID of the rotation process.
+	StartRotation(ctx context.Context, organizationID uint, secretID string, notificationSecretID string) (string, error)
+}
+
+// NewService returns a new Service.
+func NewService(store Store, secrets secret.Store, types secret.TypeList, starter Starter) Service {
+	return service{
+		store:   store,
+		secrets: secrets,
+		types:   types,
+		starter: starter,
+	}
+}
+
+type service struct {
+	store   Store
+	secrets secret.Store
+	types   secret.TypeList
+	starter Starter
+}
+
+func (s service) GetPolicy(ctx context.Context, organizationID uint, secretID string) (Policy, error) {
+	return s.store.Get(ctx, organizationID, secretID)
+}
+
+func (s service) SetPolicy(ctx context.Context, organizationID uint, secretID string, policy Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	typ, err := s.rotatorType(ctx, organizationID, secretID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := typ.(secret.ExpiringType); policy.DaysBeforeExpiry > 0 && !ok {
+		return secret.NewValidationError(
+			"invalid rotation policy",
+			[]string{fmt.Sprintf("secrets of type %s do not expire", typ.Name())},
+		)
+	}
+
+	if policy.NotificationSecretID != "" {
+		notificationSecret, err := s.secrets.Get(ctx, organizationID, policy.NotificationSecretID)
+		if err != nil {
+			return err
+		}
+
+		if notificationSecret.Type != types.Slack {
+			return secret.NewValidationError(
+				"invalid rotation policy",
+				[]string{fmt.Sprintf("notification secret must be of type %s", types.Slack)},
+			)
+		}
+	}
+
+	return s.store.Put(ctx, organizationID, secretID, policy)
+}
+
+func (s service) DeletePolicy(ctx context.Context, organizationID uint, secretID string) error {
+	return s.store.Delete(ctx, organizationID, secretID)
+}
+
+func (s service) RotateSecret(ctx context.Context, organizationID uint, secretID string) (string, error) {
+	if _, err := s.rotatorType(ctx, organizationID, secretID); err != nil {
+		return "", err
+	}
+
+	var notificationSecretID string
+
+	policy, err := s.store.Get(ctx, organizationID, secretID)
+	if err == nil {
+		notificationSecretID = policy.NotificationSecretID
+	} else if !errors.As(err, &PolicyNotFoundError{}) {
+		return "", err
+	}
+
+	return s.starter.StartRotation(ctx, organizationID, secretID, notificationSecretID)
+}
+
+// rotatorType returns the type of a secret if it supports rotation.
+func (s service) rotatorType(ctx context.Context, organizationID uint, secretID string) (secret.Type, error) {
+	model, err := s.secrets.Get(ctx, organizationID, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range model.Tags {
+		if tag == pipelineSecret.TagBanzaiReadonly || tag == pipelineSecret.TagBanzaiHidden {
+			return nil, secret.NewValidationError(
+				"secret cannot be rotated",
+				[]string{"secrets managed by Pipeline cannot be rotated"},
+			)
+		}
+	}
+
+	typ := s.types.Type(model.Type)
+	if _, ok := typ.(secret.RotatorType); !ok {
+		return nil, secret.NewValidationError(
+			"secret cannot be rotated",
+			[]string{fmt.Sprintf("secrets of type %s cannot be rotated", model.Type)},
+		)
+	}
+
+	return typ, nil
+}
+
+// PolicyNotFoundError is returned when a secret has no rotation policy.
+type PolicyNotFoundError struct {
+	OrganizationID uint
+	SecretID       string
+}
+
+// Error implements the error interface.
+func (PolicyNotFoundError) Error() string {
+	return "rotation policy not found"
+}
+
+// Details returns error details.
+func (e PolicyNotFoundError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "secretId", e.SecretID}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (PolicyNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (PolicyNotFoundError) ServiceError() bool {
+	return true
+}
+
+// AlreadyRunningError is returned when a secret is already being rotated.
+type AlreadyRunningError struct {
+	OrganizationID uint
+	SecretID       string
+}
+
+// Error implements the error interface.
+func (AlreadyRunningError) Error() string {
+	return "secret rotation is already running"
+}
+
+// Details returns error details.
+func (e AlreadyRunningError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "secretId", e.SecretID}
+}
+
+// Conflict tells the consumer that this error is related to a conflicting request.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (AlreadyRunningError) Conflict() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (AlreadyRunningError) ServiceError() bool {
+	return true
+}
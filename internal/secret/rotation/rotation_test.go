@@ -0,0 +1,243 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	internaltesting "github.com/banzaicloud/pipeline/internal/testing"
+)
+
+func testTypeList() secret.TypeList {
+	return secret.NewTypeList([]secret.Type{
+		types.GenericType{},
+		types.PasswordType{},
+		types.SlackType{},
+		types.TLSType{DefaultValidity: 365 * 24 * time.Hour},
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, Policy{IntervalDays: 30}.Validate())
+	assert.NoError(t, Policy{DaysBeforeExpiry: 30}.Validate())
+
+	err := Policy{}.Validate()
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &secret.ValidationError{}))
+
+	err = Policy{IntervalDays: -1, DaysBeforeExpiry: -1}.Validate()
+	require.Error(t, err)
+
+	var verr secret.ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Len(t, verr.Violations(), 2)
+}
+
+func TestPolicy_Due(t *testing.T) {
+	now := time.Date(2020, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		updatedAt time.Time
+		expiresAt time.Time
+		due       bool
+	}{
+		{
+			name:      "interval not elapsed",
+			policy:    Policy{IntervalDays: 30},
+			updatedAt: now.AddDate(0, 0, -29),
+			due:       false,
+		},
+		{
+			name:      "interval elapsed",
+			policy:    Policy{IntervalDays: 30},
+			updatedAt: now.AddDate(0, 0, -30),
+			due:       true,
+		},
+		{
+			name:      "expiry far away",
+			policy:    Policy{DaysBeforeExpiry: 30},
+			updatedAt: now.AddDate(-1, 0, 0),
+			expiresAt: now.AddDate(0, 0, 31),
+			due:       false,
+		},
+		{
+			name:      "expiry close",
+			policy:    Policy{DaysBeforeExpiry: 30},
+			updatedAt: now,
+			expiresAt: now.AddDate(0, 0, 29),
+			due:       true,
+		},
+		{
+			name:      "does not expire",
+			policy:    Policy{DaysBeforeExpiry: 30},
+			updatedAt: now.AddDate(-1, 0, 0),
+			due:       false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.due, test.policy.Due(now, test.updatedAt, test.expiresAt))
+		})
+	}
+}
+
+func TestService_SetPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	secrets := internaltesting.NewSecretStore(
+		t, 1,
+		secret.Model{ID: "password", Name: "password", Type: types.Password},
+		secret.Model{ID: "generic", Name: "generic", Type: types.Generic},
+		secret.Model{ID: "slack", Name: "slack", Type: types.Slack},
+		secret.Model{ID: "readonly", Name: "readonly", Type: types.Password, Tags: []string{"banzai:readonly"}},
+	)
+
+	t.Run("OK", func(t *testing.T) {
+		store := new(MockStore)
+		policy := Policy{IntervalDays: 30, NotificationSecretID: "slack"}
+		store.On("Put", ctx, uint(1), "password", policy).Return(nil)
+
+		service := NewService(store, secrets, testTypeList(), new(MockStarter))
+
+		err := service.SetPolicy(ctx, 1, "password", policy)
+		require.NoError(t, err)
+
+		store.AssertExpectations(t)
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		service := NewService(new(MockStore), secrets, testTypeList(), new(MockStarter))
+
+		err := service.SetPolicy(ctx, 1, "readonly", Policy{IntervalDays: 30})
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+
+	t.Run("NotRotatable", func(t *testing.T) {
+		service := NewService(new(MockStore), secrets, testTypeList(), new(MockStarter))
+
+		err := service.SetPolicy(ctx, 1, "generic", Policy{IntervalDays: 30})
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+
+	t.Run("NotExpiring", func(t *testing.T) {
+		service := NewService(new(MockStore), secrets, testTypeList(), new(MockStarter))
+
+		err := service.SetPolicy(ctx, 1, "password", Policy{DaysBeforeExpiry: 30})
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+
+	t.Run("InvalidNotificationSecret", func(t *testing.T) {
+		service := NewService(new(MockStore), secrets, testTypeList(), new(MockStarter))
+
+		err := service.SetPolicy(ctx, 1, "password", Policy{IntervalDays: 30, NotificationSecretID: "generic"})
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &secret.ValidationError{}))
+	})
+}
+
+func TestService_RotateSecret(t *testing.T) {
+	ctx := context.Background()
+
+	secrets := internaltesting.NewSecretStore(t, 1, secret.Model{ID: "password", Name: "password", Type: types.Password})
+
+	store := new(MockStore)
+	store.On("Get", ctx, uint(1), "password").Return(Policy{IntervalDays: 30, NotificationSecretID: "slack"}, nil)
+
+	starter := new(MockStarter)
+	starter.On("StartRotation", ctx, uint(1), "password", "slack").Return("process-id", nil)
+
+	service := NewService(store, secrets, testTypeList(), starter)
+
+	processID, err := service.RotateSecret(ctx, 1, "password")
+	require.NoError(t, err)
+
+	assert.Equal(t, "process-id", processID)
+
+	store.AssertExpectations(t)
+	starter.AssertExpectations(t)
+}
+
+func TestRotator(t *testing.T) {
+	ctx := context.Background()
+
+	secrets := internaltesting.NewSecretStore(
+		t, 1,
+		secret.Model{
+			ID:     "password",
+			Name:   "password",
+			Type:   types.Password,
+			Values: map[string]string{types.FieldPasswordUsername: "user", types.FieldPasswordPassword: "secret"},
+		},
+		secret.Model{
+			ID:     "fresh",
+			Name:   "fresh",
+			Type:   types.Password,
+			Values: map[string]string{types.FieldPasswordUsername: "user", types.FieldPasswordPassword: "secret"},
+		},
+	)
+
+	// The secrets are written now, so they are checked a month later
+	now := time.Now().AddDate(0, 0, 31)
+
+	store := new(MockStore)
+	store.On("List", ctx).Return([]SecretPolicy{
+		{OrganizationID: 1, SecretID: "password", Policy: Policy{IntervalDays: 30}},
+		{OrganizationID: 1, SecretID: "fresh", Policy: Policy{IntervalDays: 60}},
+		{OrganizationID: 1, SecretID: "deleted", Policy: Policy{IntervalDays: 30}},
+	}, nil)
+
+	rotator := NewRotator(store, secrets, testTypeList(), NoopLogger{})
+
+	due, err := rotator.ListDueSecrets(ctx, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []SecretPolicy{{OrganizationID: 1, SecretID: "password", Policy: Policy{IntervalDays: 30}}}, due)
+
+	version, err := rotator.GetVersion(ctx, 1, "password")
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	model, err := rotator.Rotate(ctx, 1, "password", version)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, model.Version)
+	assert.Equal(t, UpdatedBy, model.UpdatedBy)
+	assert.Equal(t, "user", model.Values[types.FieldPasswordUsername])
+	assert.NotEqual(t, "secret", model.Values[types.FieldPasswordPassword])
+
+	// retrying the same rotation does not rotate the secret again
+	retried, err := rotator.Rotate(ctx, 1, "password", version)
+	require.NoError(t, err)
+
+	assert.Equal(t, model, retried)
+
+	store.AssertExpectations(t)
+}
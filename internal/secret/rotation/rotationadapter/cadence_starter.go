@@ -0,0 +1,91 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation/rotationworkflow"
+)
+
+// CadenceStarter starts secret rotation workflows.
+type CadenceStarter struct {
+	workflowClient client.Client
+}
+
+// NewCadenceStarter returns a new CadenceStarter.
+func NewCadenceStarter(workflowClient client.Client) CadenceStarter {
+	return CadenceStarter{
+		workflowClient: workflowClient,
+	}
+}
+
+// StartRotation implements the rotation.Starter interface.
+func (s CadenceStarter) StartRotation(ctx context.Context, organizationID uint, secretID string, notificationSecretID string) (string, error) {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           rotationworkflow.RotateSecretWorkflowID(organizationID, secretID),
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 2 * time.Hour,
+		WorkflowIDReusePolicy:        client.WorkflowIDReusePolicyAllowDuplicate,
+	}
+
+	input := rotationworkflow.RotateSecretWorkflowInput{
+		OrganizationID:       organizationID,
+		SecretID:             secretID,
+		NotificationSecretID: notificationSecretID,
+	}
+
+	execution, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, rotationworkflow.RotateSecretWorkflowName, input)
+	if isAlreadyStartedError(err) {
+		return "", errors.WithStack(rotation.AlreadyRunningError{
+			OrganizationID: organizationID,
+			SecretID:       secretID,
+		})
+	} else if err != nil {
+		return "", errors.WrapWithDetails(err, "failed to start workflow", "workflow", rotationworkflow.RotateSecretWorkflowName)
+	}
+
+	return execution.ID, nil
+}
+
+// StartScheduler starts the cron workflow that checks rotation policies periodically.
+// It does nothing if the scheduler is already running.
+func (s CadenceStarter) StartScheduler(ctx context.Context, schedule string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           rotationworkflow.RotationSchedulerWorkflowName,
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 12 * time.Hour,
+		CronSchedule:                 schedule,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, rotationworkflow.RotationSchedulerWorkflowName)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", rotationworkflow.RotationSchedulerWorkflowName)
+	}
+
+	return nil
+}
+
+func isAlreadyStartedError(err error) bool {
+	var alreadyStartedErr *shared.WorkflowExecutionAlreadyStartedError
+
+	return errors.As(err, &alreadyStartedErr)
+}
@@ -0,0 +1,91 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/src/cluster"
+)
+
+// ClusterLister lists the clusters of an organization.
+type ClusterLister interface {
+	GetClusters(ctx context.Context, organizationID uint) ([]cluster.CommonCluster, error)
+}
+
+// ClusterSecretSyncer updates the Kubernetes secrets installed from a secret in the running clusters of an organization.
+type ClusterSecretSyncer struct {
+	clusters ClusterLister
+
+	logger common.Logger
+}
+
+// NewClusterSecretSyncer returns a new ClusterSecretSyncer.
+func NewClusterSecretSyncer(clusters ClusterLister, logger common.Logger) ClusterSecretSyncer {
+	return ClusterSecretSyncer{
+		clusters: clusters,
+
+		logger: logger,
+	}
+}
+
+// SyncSecret implements the rotation.SecretSyncer interface.
+func (s ClusterSecretSyncer) SyncSecret(ctx context.Context, organizationID uint, secretName string) (int, error) {
+	clusters, err := s.clusters.GetClusters(ctx, organizationID)
+	if err != nil {
+		return 0, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var count int
+	var errs []error
+
+	for _, c := range clusters {
+		status, err := c.GetStatus()
+		if err != nil || status.Status != pkgCluster.Running {
+			continue
+		}
+
+		kubeConfig, err := c.GetK8sConfig()
+		if err != nil {
+			errs = append(errs, errors.WrapIfWithDetails(err, "failed to get kubeconfig", "clusterId", c.GetID()))
+
+			continue
+		}
+
+		n, err := cluster.SyncSecretByK8SConfig(kubeConfig, organizationID, secretName)
+		if err != nil {
+			errs = append(errs, errors.WrapIfWithDetails(err, "failed to update kubernetes secrets", "clusterId", c.GetID()))
+
+			continue
+		}
+
+		if n > 0 {
+			s.logger.Info("kubernetes secrets updated", map[string]interface{}{
+				"organizationId": organizationID,
+				"clusterId":      c.GetID(),
+				"secret":         secretName,
+				"count":          n,
+			})
+		}
+
+		count += n
+	}
+
+	return count, errors.Combine(errs...)
+}
@@ -0,0 +1,141 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+// policyModel describes the secret rotation policy model.
+type policyModel struct {
+	ID                   uint `gorm:"primary_key"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	OrganizationID       uint   `gorm:"unique_index:idx_secret_rotation_policies_org_secret"`
+	SecretID             string `gorm:"unique_index:idx_secret_rotation_policies_org_secret"`
+	IntervalDays         int
+	DaysBeforeExpiry     int
+	NotificationSecretID string
+}
+
+// TableName changes the default table name.
+func (policyModel) TableName() string {
+	return "secret_rotation_policies"
+}
+
+// GormStore is a rotation policy store backed by a relational database.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new GormStore.
+func NewGormStore(db *gorm.DB) GormStore {
+	return GormStore{
+		db: db,
+	}
+}
+
+// Get implements the rotation.Store interface.
+func (s GormStore) Get(ctx context.Context, organizationID uint, secretID string) (rotation.Policy, error) {
+	var model policyModel
+
+	err := s.db.Where(policyModel{OrganizationID: organizationID, SecretID: secretID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return rotation.Policy{}, errors.WithStack(rotation.PolicyNotFoundError{
+			OrganizationID: organizationID,
+			SecretID:       secretID,
+		})
+	} else if err != nil {
+		return rotation.Policy{}, errors.WrapIfWithDetails(
+			err, "failed to get rotation policy",
+			"organizationId", organizationID,
+			"secretId", secretID,
+		)
+	}
+
+	return toPolicy(model), nil
+}
+
+// Put implements the rotation.Store interface.
+func (s GormStore) Put(ctx context.Context, organizationID uint, secretID string, policy rotation.Policy) error {
+	var model policyModel
+
+	err := s.db.
+		Where(policyModel{OrganizationID: organizationID, SecretID: secretID}).
+		Assign(map[string]interface{}{ // Zero values are ignored when a struct is used here
+			"interval_days":          policy.IntervalDays,
+			"days_before_expiry":     policy.DaysBeforeExpiry,
+			"notification_secret_id": policy.NotificationSecretID,
+		}).
+		FirstOrCreate(&model).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to save rotation policy",
+			"organizationId", organizationID,
+			"secretId", secretID,
+		)
+	}
+
+	return nil
+}
+
+// Delete implements the rotation.Store interface.
+func (s GormStore) Delete(ctx context.Context, organizationID uint, secretID string) error {
+	err := s.db.Where(policyModel{OrganizationID: organizationID, SecretID: secretID}).Delete(policyModel{}).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(
+			err, "failed to delete rotation policy",
+			"organizationId", organizationID,
+			"secretId", secretID,
+		)
+	}
+
+	return nil
+}
+
+// List implements the rotation.Store interface.
+func (s GormStore) List(ctx context.Context) ([]rotation.SecretPolicy, error) {
+	var models []policyModel
+
+	if err := s.db.Order("id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIf(err, "failed to list rotation policies")
+	}
+
+	policies := make([]rotation.SecretPolicy, 0, len(models))
+
+	for _, model := range models {
+		policies = append(policies, rotation.SecretPolicy{
+			OrganizationID: model.OrganizationID,
+			SecretID:       model.SecretID,
+			Policy:         toPolicy(model),
+		})
+	}
+
+	return policies, nil
+}
+
+func toPolicy(model policyModel) rotation.Policy {
+	return rotation.Policy{
+		IntervalDays:         model.IntervalDays,
+		DaysBeforeExpiry:     model.DaysBeforeExpiry,
+		NotificationSecretID: model.NotificationSecretID,
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationadapter
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormStore(setUpDatabase(t))
+
+	_, err := store.Get(ctx, 1, "secret")
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &rotation.PolicyNotFoundError{}))
+
+	err = store.Put(ctx, 1, "secret", rotation.Policy{IntervalDays: 30})
+	require.NoError(t, err)
+
+	err = store.Put(ctx, 1, "secret", rotation.Policy{DaysBeforeExpiry: 10, NotificationSecretID: "slack"})
+	require.NoError(t, err)
+
+	err = store.Put(ctx, 2, "secret", rotation.Policy{IntervalDays: 7})
+	require.NoError(t, err)
+
+	policy, err := store.Get(ctx, 1, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, rotation.Policy{DaysBeforeExpiry: 10, NotificationSecretID: "slack"}, policy)
+
+	policies, err := store.List(ctx)
+	require.NoError(t, err)
+
+	expected := []rotation.SecretPolicy{
+		{OrganizationID: 1, SecretID: "secret", Policy: rotation.Policy{DaysBeforeExpiry: 10, NotificationSecretID: "slack"}},
+		{OrganizationID: 2, SecretID: "secret", Policy: rotation.Policy{IntervalDays: 7}},
+	}
+	assert.Equal(t, expected, policies)
+
+	err = store.Delete(ctx, 1, "secret")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, 1, "secret")
+	assert.True(t, errors.As(err, &rotation.PolicyNotFoundError{}))
+}
@@ -0,0 +1,142 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationdriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("/rotation").Handler(kithttp.NewServer(
+		endpoints.GetPolicy,
+		decodeGetPolicyHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetPolicyHTTPResponse, errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPut).Path("/rotation").Handler(kithttp.NewServer(
+		endpoints.SetPolicy,
+		decodeSetPolicyHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodDelete).Path("/rotation").Handler(kithttp.NewServer(
+		endpoints.DeletePolicy,
+		decodeDeletePolicyHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(kitxhttp.StatusCodeResponseEncoder(http.StatusNoContent), errorEncoder),
+		options...,
+	))
+
+	router.Methods(http.MethodPost).Path("/rotate").Handler(kithttp.NewServer(
+		endpoints.RotateSecret,
+		decodeRotateSecretHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeRotateSecretHTTPResponse, errorEncoder),
+		options...,
+	))
+}
+
+func decodeSecretParams(r *http.Request) (uint, string, error) {
+	vars := mux.Vars(r)
+
+	orgIDStr, ok := vars["orgId"]
+	if !ok || orgIDStr == "" {
+		return 0, "", errors.NewWithDetails("missing parameter from the URL", "param", "orgId")
+	}
+
+	orgID, err := strconv.ParseUint(orgIDStr, 0, 0)
+	if err != nil {
+		return 0, "", errors.WrapIf(err, "invalid organization ID format")
+	}
+
+	secretID, ok := vars["secretId"]
+	if !ok || secretID == "" {
+		return 0, "", errors.NewWithDetails("missing parameter from the URL", "param", "secretId")
+	}
+
+	return uint(orgID), secretID, nil
+}
+
+func decodeGetPolicyHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, secretID, err := decodeSecretParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPolicyRequest{OrganizationID: orgID, SecretID: secretID}, nil
+}
+
+func encodeGetPolicyHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetPolicyResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Policy)
+}
+
+func decodeSetPolicyHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, secretID, err := decodeSecretParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy rotation.Policy
+
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode request")
+	}
+
+	return SetPolicyRequest{OrganizationID: orgID, SecretID: secretID, Policy: policy}, nil
+}
+
+func decodeDeletePolicyHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, secretID, err := decodeSecretParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeletePolicyRequest{OrganizationID: orgID, SecretID: secretID}, nil
+}
+
+func decodeRotateSecretHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	orgID, secretID, err := decodeSecretParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return RotateSecretRequest{OrganizationID: orgID, SecretID: secretID}, nil
+}
+
+func encodeRotateSecretHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(RotateSecretResponse)
+
+	apiResp := map[string]string{
+		"processId": resp.ProcessID,
+	}
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, kitxhttp.WithStatusCode(apiResp, http.StatusAccepted))
+}
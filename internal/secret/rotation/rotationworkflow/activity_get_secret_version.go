@@ -0,0 +1,63 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+const GetSecretVersionActivityName = "secret-rotation-get-secret-version"
+
+// GetSecretVersionActivity returns the current version of a secret.
+type GetSecretVersionActivity struct {
+	rotator rotation.Rotator
+}
+
+type GetSecretVersionActivityInput struct {
+	OrganizationID uint
+	SecretID       string
+}
+
+type GetSecretVersionActivityOutput struct {
+	Version int
+}
+
+// NewGetSecretVersionActivity returns a new GetSecretVersionActivity.
+func NewGetSecretVersionActivity(rotator rotation.Rotator) GetSecretVersionActivity {
+	return GetSecretVersionActivity{
+		rotator: rotator,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a GetSecretVersionActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: GetSecretVersionActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a GetSecretVersionActivity) Execute(ctx context.Context, input GetSecretVersionActivityInput) (GetSecretVersionActivityOutput, error) {
+	version, err := a.rotator.GetVersion(ctx, input.OrganizationID, input.SecretID)
+	if err != nil {
+		return GetSecretVersionActivityOutput{}, err
+	}
+
+	return GetSecretVersionActivityOutput{
+		Version: version,
+	}, nil
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+const ListDueSecretsActivityName = "secret-rotation-list-due-secrets"
+
+// ListDueSecretsActivity lists the secrets that should be rotated.
+type ListDueSecretsActivity struct {
+	rotator rotation.Rotator
+}
+
+type ListDueSecretsActivityInput struct {
+	Now time.Time
+}
+
+type ListDueSecretsActivityOutput struct {
+	Secrets []rotation.SecretPolicy
+}
+
+// NewListDueSecretsActivity returns a new ListDueSecretsActivity.
+func NewListDueSecretsActivity(rotator rotation.Rotator) ListDueSecretsActivity {
+	return ListDueSecretsActivity{
+		rotator: rotator,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ListDueSecretsActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ListDueSecretsActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ListDueSecretsActivity) Execute(ctx context.Context, input ListDueSecretsActivityInput) (ListDueSecretsActivityOutput, error) {
+	secrets, err := a.rotator.ListDueSecrets(ctx, input.Now)
+	if err != nil {
+		return ListDueSecretsActivityOutput{}, err
+	}
+
+	return ListDueSecretsActivityOutput{
+		Secrets: secrets,
+	}, nil
+}
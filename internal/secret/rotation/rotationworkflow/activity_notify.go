@@ -0,0 +1,51 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+const NotifyActivityName = "secret-rotation-notify"
+
+// NotifyActivity sends a notification about a secret rotation.
+type NotifyActivity struct {
+	notifier rotation.Notifier
+}
+
+type NotifyActivityInput struct {
+	Notification rotation.Notification
+}
+
+// NewNotifyActivity returns a new NotifyActivity.
+func NewNotifyActivity(notifier rotation.Notifier) NotifyActivity {
+	return NotifyActivity{
+		notifier: notifier,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a NotifyActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: NotifyActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a NotifyActivity) Execute(ctx context.Context, input NotifyActivityInput) error {
+	return a.notifier.Notify(ctx, input.Notification)
+}
@@ -0,0 +1,69 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+const RotateSecretActivityName = "secret-rotation-rotate-secret"
+
+// RotateSecretActivity regenerates the values of a secret.
+type RotateSecretActivity struct {
+	rotator rotation.Rotator
+}
+
+type RotateSecretActivityInput struct {
+	OrganizationID uint
+	SecretID       string
+
+	// Version is the version of the secret the rotation starts from.
+	// It makes retrying the activity safe: the secret is not rotated again once a newer version is stored.
+	Version int
+}
+
+type RotateSecretActivityOutput struct {
+	SecretName string
+	Version    int
+}
+
+// NewRotateSecretActivity returns a new RotateSecretActivity.
+func NewRotateSecretActivity(rotator rotation.Rotator) RotateSecretActivity {
+	return RotateSecretActivity{
+		rotator: rotator,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a RotateSecretActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: RotateSecretActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a RotateSecretActivity) Execute(ctx context.Context, input RotateSecretActivityInput) (RotateSecretActivityOutput, error) {
+	model, err := a.rotator.Rotate(ctx, input.OrganizationID, input.SecretID, input.Version)
+	if err != nil {
+		return RotateSecretActivityOutput{}, err
+	}
+
+	return RotateSecretActivityOutput{
+		SecretName: model.Name,
+		Version:    model.Version,
+	}, nil
+}
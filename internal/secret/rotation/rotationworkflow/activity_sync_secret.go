@@ -0,0 +1,63 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+)
+
+const SyncSecretActivityName = "secret-rotation-sync-secret"
+
+// SyncSecretActivity updates the Kubernetes secrets installed from a secret.
+type SyncSecretActivity struct {
+	syncer rotation.SecretSyncer
+}
+
+type SyncSecretActivityInput struct {
+	OrganizationID uint
+	SecretName     string
+}
+
+type SyncSecretActivityOutput struct {
+	KubernetesSecrets int
+}
+
+// NewSyncSecretActivity returns a new SyncSecretActivity.
+func NewSyncSecretActivity(syncer rotation.SecretSyncer) SyncSecretActivity {
+	return SyncSecretActivity{
+		syncer: syncer,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a SyncSecretActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: SyncSecretActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a SyncSecretActivity) Execute(ctx context.Context, input SyncSecretActivityInput) (SyncSecretActivityOutput, error) {
+	count, err := a.syncer.SyncSecret(ctx, input.OrganizationID, input.SecretName)
+	if err != nil {
+		return SyncSecretActivityOutput{}, err
+	}
+
+	return SyncSecretActivityOutput{
+		KubernetesSecrets: count,
+	}, nil
+}
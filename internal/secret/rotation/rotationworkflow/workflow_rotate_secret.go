@@ -0,0 +1,166 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/pkg/sdk/brn"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+const RotateSecretWorkflowName = "rotate-secret"
+
+// RotateSecretWorkflowID returns the ID of the workflow rotating a secret.
+// There can be only one rotation running for a secret at a time.
+func RotateSecretWorkflowID(organizationID uint, secretID string) string {
+	return fmt.Sprintf("%s-%d-%s", RotateSecretWorkflowName, organizationID, secretID)
+}
+
+// RotateSecretWorkflow regenerates the values of a secret and updates the Kubernetes secrets installed from it.
+type RotateSecretWorkflow struct {
+	processLogger processlog.ProcessLogger
+}
+
+type RotateSecretWorkflowInput struct {
+	OrganizationID       uint
+	SecretID             string
+	NotificationSecretID string
+}
+
+// NewRotateSecretWorkflow returns a new RotateSecretWorkflow.
+func NewRotateSecretWorkflow(processLogger processlog.ProcessLogger) RotateSecretWorkflow {
+	return RotateSecretWorkflow{
+		processLogger: processLogger,
+	}
+}
+
+// Register registers the workflow in the worker.
+func (w RotateSecretWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: RotateSecretWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w RotateSecretWorkflow) Execute(ctx workflow.Context, input RotateSecretWorkflowInput) (err error) {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          10 * time.Second,
+			BackoffCoefficient:       1.5,
+			MaximumAttempts:          5,
+			NonRetriableErrorReasons: []string{"cadenceInternal:Panic"},
+		},
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	process := w.processLogger.StartProcess(ctx, brn.New(input.OrganizationID, brn.SecretResourceType, input.SecretID).String())
+	defer func() {
+		process.Finish(ctx, err)
+	}()
+
+	notification := rotation.Notification{
+		OrganizationID:       input.OrganizationID,
+		SecretID:             input.SecretID,
+		NotificationSecretID: input.NotificationSecretID,
+	}
+
+	defer func() {
+		if err != nil {
+			if cadence.IsCanceledError(err) {
+				ctx, _ = workflow.NewDisconnectedContext(ctx)
+			}
+
+			notification.Error = err.Error()
+		}
+
+		activityInput := NotifyActivityInput{
+			Notification: notification,
+		}
+
+		// Failing to send a notification should not fail the rotation itself
+		_ = workflow.ExecuteActivity(ctx, NotifyActivityName, activityInput).Get(ctx, nil)
+	}()
+
+	var version int
+	{
+		activityInput := GetSecretVersionActivityInput{
+			OrganizationID: input.OrganizationID,
+			SecretID:       input.SecretID,
+		}
+
+		var output GetSecretVersionActivityOutput
+
+		err = workflow.ExecuteActivity(ctx, GetSecretVersionActivityName, activityInput).Get(ctx, &output)
+		if err != nil {
+			return err
+		}
+
+		version = output.Version
+	}
+
+	{
+		activityInput := RotateSecretActivityInput{
+			OrganizationID: input.OrganizationID,
+			SecretID:       input.SecretID,
+			Version:        version,
+		}
+
+		var output RotateSecretActivityOutput
+
+		processActivity := process.StartActivity(ctx, RotateSecretActivityName)
+		err = workflow.ExecuteActivity(ctx, RotateSecretActivityName, activityInput).Get(ctx, &output)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+
+		notification.SecretName = output.SecretName
+		notification.Version = output.Version
+	}
+
+	{
+		activityInput := SyncSecretActivityInput{
+			OrganizationID: input.OrganizationID,
+			SecretName:     notification.SecretName,
+		}
+
+		activityOptions := activityOptions
+		activityOptions.StartToCloseTimeout = 30 * time.Minute
+
+		var output SyncSecretActivityOutput
+
+		processActivity := process.StartActivity(ctx, SyncSecretActivityName)
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, activityOptions),
+			SyncSecretActivityName,
+			activityInput,
+		).Get(ctx, &output)
+		processActivity.Finish(ctx, err)
+		if err != nil {
+			return err
+		}
+
+		notification.KubernetesSecrets = output.KubernetesSecrets
+	}
+
+	return nil
+}
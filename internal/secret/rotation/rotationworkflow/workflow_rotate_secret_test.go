@@ -0,0 +1,152 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/pkg/sdk/cadence/lib/pipeline/processlog"
+)
+
+// nolint: gochecknoinits
+func init() {
+	NewGetSecretVersionActivity(rotation.Rotator{}).Register()
+	NewRotateSecretActivity(rotation.Rotator{}).Register()
+	NewSyncSecretActivity(nil).Register()
+	NewNotifyActivity(nil).Register()
+}
+
+type noopProcessLogger struct{}
+
+func (noopProcessLogger) StartProcess(_ workflow.Context, _ string) processlog.Process {
+	return noopProcess{}
+}
+
+type noopProcess struct{}
+
+func (noopProcess) Finish(_ workflow.Context, _ error) {}
+
+func (noopProcess) StartActivity(_ workflow.Context, _ string) processlog.Activity {
+	return noopProcess{}
+}
+
+type RotateSecretWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestRotateSecretWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(RotateSecretWorkflowTestSuite))
+}
+
+func (s *RotateSecretWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+
+	workflow.RegisterWithOptions(
+		NewRotateSecretWorkflow(noopProcessLogger{}).Execute,
+		workflow.RegisterOptions{Name: s.T().Name()},
+	)
+}
+
+func (s *RotateSecretWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *RotateSecretWorkflowTestSuite) Test_Success() {
+	s.env.OnActivity(
+		GetSecretVersionActivityName,
+		mock.Anything,
+		GetSecretVersionActivityInput{OrganizationID: 1, SecretID: "secret-id"},
+	).Return(GetSecretVersionActivityOutput{Version: 1}, nil)
+
+	s.env.OnActivity(
+		RotateSecretActivityName,
+		mock.Anything,
+		RotateSecretActivityInput{OrganizationID: 1, SecretID: "secret-id", Version: 1},
+	).Return(RotateSecretActivityOutput{SecretName: "secret", Version: 2}, nil)
+
+	s.env.OnActivity(
+		SyncSecretActivityName,
+		mock.Anything,
+		SyncSecretActivityInput{OrganizationID: 1, SecretName: "secret"},
+	).Return(SyncSecretActivityOutput{KubernetesSecrets: 3}, nil)
+
+	s.env.OnActivity(
+		NotifyActivityName,
+		mock.Anything,
+		NotifyActivityInput{
+			Notification: rotation.Notification{
+				OrganizationID:       1,
+				SecretID:             "secret-id",
+				SecretName:           "secret",
+				Version:              2,
+				KubernetesSecrets:    3,
+				NotificationSecretID: "slack",
+			},
+		},
+	).Return(nil)
+
+	workflowInput := RotateSecretWorkflowInput{
+		OrganizationID:       1,
+		SecretID:             "secret-id",
+		NotificationSecretID: "slack",
+	}
+
+	s.env.ExecuteWorkflow(s.T().Name(), workflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *RotateSecretWorkflowTestSuite) Test_RotationFailed() {
+	s.env.OnActivity(
+		GetSecretVersionActivityName,
+		mock.Anything,
+		GetSecretVersionActivityInput{OrganizationID: 1, SecretID: "secret-id"},
+	).Return(GetSecretVersionActivityOutput{Version: 1}, nil)
+
+	s.env.OnActivity(
+		RotateSecretActivityName,
+		mock.Anything,
+		RotateSecretActivityInput{OrganizationID: 1, SecretID: "secret-id", Version: 1},
+	).Return(RotateSecretActivityOutput{}, errors.New("rotation failed"))
+
+	s.env.OnActivity(
+		NotifyActivityName,
+		mock.Anything,
+		mock.MatchedBy(func(input NotifyActivityInput) bool {
+			return input.Notification.SecretID == "secret-id" && input.Notification.Error != ""
+		}),
+	).Return(nil)
+
+	workflowInput := RotateSecretWorkflowInput{
+		OrganizationID: 1,
+		SecretID:       "secret-id",
+	}
+
+	s.env.ExecuteWorkflow(s.T().Name(), workflowInput)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
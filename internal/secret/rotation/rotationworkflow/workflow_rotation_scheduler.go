@@ -0,0 +1,96 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotationworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const RotationSchedulerWorkflowName = "secret-rotation-scheduler"
+
+// RotationSchedulerWorkflow starts rotating the secrets that are due according to their rotation policies.
+// It is supposed to be started as a cron workflow.
+type RotationSchedulerWorkflow struct{}
+
+// NewRotationSchedulerWorkflow returns a new RotationSchedulerWorkflow.
+func NewRotationSchedulerWorkflow() RotationSchedulerWorkflow {
+	return RotationSchedulerWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w RotationSchedulerWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: RotationSchedulerWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w RotationSchedulerWorkflow) Execute(ctx workflow.Context) error {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    5 * time.Minute,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	var output ListDueSecretsActivityOutput
+
+	activityInput := ListDueSecretsActivityInput{
+		Now: workflow.Now(ctx),
+	}
+
+	err := workflow.ExecuteActivity(ctx, ListDueSecretsActivityName, activityInput).Get(ctx, &output)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx).Sugar()
+
+	futures := make([]workflow.ChildWorkflowFuture, 0, len(output.Secrets))
+
+	for _, policy := range output.Secrets {
+		childWorkflowOptions := workflow.ChildWorkflowOptions{
+			WorkflowID:                   RotateSecretWorkflowID(policy.OrganizationID, policy.SecretID),
+			ExecutionStartToCloseTimeout: 2 * time.Hour,
+			TaskStartToCloseTimeout:      30 * time.Second,
+		}
+
+		workflowInput := RotateSecretWorkflowInput{
+			OrganizationID:       policy.OrganizationID,
+			SecretID:             policy.SecretID,
+			NotificationSecretID: policy.Policy.NotificationSecretID,
+		}
+
+		futures = append(futures, workflow.ExecuteChildWorkflow(
+			workflow.WithChildOptions(ctx, childWorkflowOptions),
+			RotateSecretWorkflowName,
+			workflowInput,
+		))
+	}
+
+	// Rotation failures are reported by the rotation workflows, they should not stop the scheduler
+	for i, future := range futures {
+		if err := future.Get(ctx, nil); err != nil {
+			logger.Warnw(
+				"failed to rotate secret",
+				"organizationId", output.Secrets[i].OrganizationID,
+				"secretId", output.Secrets[i].SecretID,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Rotator regenerates the values of secrets.
+type Rotator struct {
+	store   Store
+	secrets secret.Store
+	types   secret.TypeList
+
+	logger Logger
+}
+
+// NewRotator returns a new Rotator.
+func NewRotator(store Store, secrets secret.Store, types secret.TypeList, logger Logger) Rotator {
+	return Rotator{
+		store:   store,
+		secrets: secrets,
+		types:   types,
+
+		logger: logger,
+	}
+}
+
+// ListDueSecrets returns the secrets that should be rotated according to their policies.
+func (r Rotator) ListDueSecrets(ctx context.Context, now time.Time) ([]SecretPolicy, error) {
+	policies, err := r.store.List(ctx)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list rotation policies")
+	}
+
+	var due []SecretPolicy
+
+	for _, policy := range policies {
+		logger := r.logger.WithFields(map[string]interface{}{
+			"organizationId": policy.OrganizationID,
+			"secretId":       policy.SecretID,
+		})
+
+		model, err := r.secrets.Get(ctx, policy.OrganizationID, policy.SecretID)
+		if errors.As(err, &secret.NotFoundError{}) { // Secret is deleted, but its policy is kept in case it gets restored
+			continue
+		} else if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get secret", "organizationId", policy.OrganizationID, "secretId", policy.SecretID)
+		}
+
+		var expiresAt time.Time
+
+		if typ, ok := r.types.Type(model.Type).(secret.ExpiringType); ok {
+			expiresAt, err = typ.ExpiresAt(model.Values)
+			if err != nil {
+				logger.Warn("failed to determine secret expiry", map[string]interface{}{"error": err.Error()})
+			}
+		}
+
+		if policy.Policy.Due(now, model.UpdatedAt, expiresAt) {
+			due = append(due, policy)
+		}
+	}
+
+	return due, nil
+}
+
+// GetVersion returns the current version of a secret.
+func (r Rotator) GetVersion(ctx context.Context, organizationID uint, secretID string) (int, error) {
+	model, err := r.secrets.Get(ctx, organizationID, secretID)
+	if err != nil {
+		return 0, err
+	}
+
+	return model.Version, nil
+}
+
+// Rotate regenerates the values of a secret and stores them as a new version.
+// The rotation starts from the given version of the secret: if a newer version has already been stored by a rotation
+// (eg. by a previous attempt of the same rotation), that version is returned instead of rotating the secret again.
+func (r Rotator) Rotate(ctx context.Context, organizationID uint, secretID string, version int) (secret.Model, error) {
+	model, err := r.secrets.Get(ctx, organizationID, secretID)
+	if err != nil {
+		return secret.Model{}, err
+	}
+
+	if model.Version > version && model.UpdatedBy == UpdatedBy {
+		return model, nil
+	}
+
+	typ := r.types.Type(model.Type)
+
+	rt, ok := typ.(secret.RotatorType)
+	if !ok {
+		return secret.Model{}, errors.NewWithDetails("secret type does not support rotation", "type", model.Type)
+	}
+
+	values, err := rt.Rotate(organizationID, model.Name, model.Values, model.Tags)
+	if err != nil {
+		return secret.Model{}, errors.WrapIf(err, "failed to rotate secret")
+	}
+
+	if pt, ok := typ.(secret.ProcessorType); ok {
+		values, err = pt.Process(values)
+		if err != nil {
+			return secret.Model{}, errors.WrapIf(err, "failed to process secret")
+		}
+	}
+
+	model.Values = values
+	model.UpdatedBy = UpdatedBy
+
+	if err := r.secrets.Put(ctx, organizationID, model); err != nil {
+		return secret.Model{}, errors.WrapIf(err, "failed to store rotated secret")
+	}
+
+	return r.secrets.Get(ctx, organizationID, secretID)
+}
+
+// +testify:mock:testOnly=true
+
+// SecretSyncer updates the Kubernetes secrets installed from a secret in the clusters of an organization.
+type SecretSyncer interface {
+	// SyncSecret updates every Kubernetes secret installed from a secret.
+	// It returns the number of updated Kubernetes secrets.
+	SyncSecret(ctx context.Context, organizationID uint, secretName string) (int, error)
+}
+
+// Notification describes the result of a secret rotation.
+type Notification struct {
+	OrganizationID       uint
+	SecretID             string
+	SecretName           string
+	Version              int
+	KubernetesSecrets    int
+	Error                string
+	NotificationSecretID string
+}
+
+// Message returns a human readable message about the rotation.
+func (n Notification) Message() string {
+	name := n.SecretName
+	if name == "" {
+		name = n.SecretID
+	}
+
+	if n.Error != "" {
+		return fmt.Sprintf("Failed to rotate secret %q: %s", name, n.Error)
+	}
+
+	return fmt.Sprintf(
+		"Secret %q has been rotated (version %d), %d Kubernetes secret(s) updated",
+		name, n.Version, n.KubernetesSecrets,
+	)
+}
+
+// +testify:mock:testOnly=true
+
+// Notifier sends notifications about secret rotations.
+type Notifier interface {
+	// Notify sends a notification about a secret rotation.
+	Notify(ctx context.Context, notification Notification) error
+}
@@ -0,0 +1,136 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"emperror.dev/errors"
+)
+
+// kekSize is the size of the key encryption key (AES-256).
+const kekSize = 32
+
+// ParseKEK decodes a base64 encoded key encryption key.
+func ParseKEK(encodedKEK string) ([]byte, error) {
+	kek, err := base64.StdEncoding.DecodeString(encodedKEK)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode key encryption key")
+	}
+
+	if len(kek) != kekSize {
+		return nil, errors.NewWithDetails("key encryption key must be 32 bytes long", "size", len(kek))
+	}
+
+	return kek, nil
+}
+
+// envelope implements envelope encryption:
+// every payload is encrypted with a random data encryption key (DEK) which is then encrypted with the key encryption key (KEK).
+type envelope struct {
+	kek cipher.AEAD
+}
+
+func newEnvelope(kek []byte) (envelope, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return envelope{}, errors.WrapIf(err, "invalid key encryption key")
+	}
+
+	return envelope{kek: aead}, nil
+}
+
+// seal encrypts a payload and returns the encrypted data encryption key along with the ciphertext.
+func (e envelope) seal(plaintext []byte) ([]byte, []byte, error) {
+	dek := make([]byte, kekSize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate data encryption key")
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err := encrypt(aead, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encryptedKey, err := encrypt(e.kek, dek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return encryptedKey, ciphertext, nil
+}
+
+// open decrypts a payload sealed by seal.
+func (e envelope) open(encryptedKey []byte, ciphertext []byte) ([]byte, error) {
+	dek, err := decrypt(e.kek, encryptedKey)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to decrypt data encryption key")
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(aead, ciphertext)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	return aead, nil
+}
+
+// encrypt encrypts a plaintext and prepends the random nonce to the ciphertext.
+func encrypt(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt")
+	}
+
+	return plaintext, nil
+}
@@ -32,4 +32,5 @@ func TestIntegration(t *testing.T) {
 	}
 
 	t.Run("VaultStore", testVaultStore)
+	t.Run("VaultStoreContract", testVaultStoreContract)
 }
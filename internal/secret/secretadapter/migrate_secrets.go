@@ -0,0 +1,63 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// MigrateSecrets copies the secrets of the given organizations from one secret store to another.
+// Secrets already present in the destination store are overwritten; the source store is left intact.
+// It returns the number of migrated secrets.
+func MigrateSecrets(
+	ctx context.Context,
+	source secret.Store,
+	destination secret.Store,
+	organizationIDs []uint,
+	logger common.Logger,
+) (int, error) {
+	var count int
+
+	for _, organizationID := range organizationIDs {
+		models, err := source.List(ctx, organizationID)
+		if err != nil {
+			return count, errors.WrapIfWithDetails(err, "failed to list secrets", "organizationId", organizationID)
+		}
+
+		for _, model := range models {
+			if err := destination.Put(ctx, organizationID, model); err != nil {
+				return count, errors.WrapIfWithDetails(
+					err, "failed to migrate secret",
+					"organizationId", organizationID,
+					"secretId", model.ID,
+				)
+			}
+
+			count++
+		}
+
+		logger.Info("migrated organization secrets", map[string]interface{}{
+			"organizationId": organizationID,
+			"secrets":        len(models),
+		})
+	}
+
+	return count, nil
+}
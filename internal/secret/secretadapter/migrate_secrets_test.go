@@ -0,0 +1,60 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+func TestMigrateSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := NewSQLStore(setUpDatabase(t), testKEK)
+	require.NoError(t, err)
+
+	require.NoError(t, source.Create(ctx, 1, secret.Model{ID: "secret-1", Name: "secret-1", Type: "example", Values: map[string]string{"key": "value"}}))
+	require.NoError(t, source.Create(ctx, 1, secret.Model{ID: "secret-2", Name: "secret-2", Type: "example"}))
+	require.NoError(t, source.Create(ctx, 2, secret.Model{ID: "secret-3", Name: "secret-3", Type: "example"}))
+	require.NoError(t, source.Create(ctx, 3, secret.Model{ID: "secret-4", Name: "secret-4", Type: "example"}))
+
+	destination := NewKubernetesStore(fake.NewSimpleClientset(), "pipeline-system")
+
+	count, err := MigrateSecrets(ctx, source, destination, []uint{1, 2}, common.NoopLogger{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, count)
+
+	model, err := destination.Get(ctx, 1, "secret-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, model.Values)
+
+	models, err := destination.List(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, models)
+
+	// Migration can be repeated safely
+	count, err = MigrateSecrets(ctx, source, destination, []uint{1, 2}, common.NoopLogger{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,165 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// testStoreContract runs the tests every secret store implementation must pass.
+// newStore must return an empty store for every call.
+func testStoreContract(t *testing.T, newStore func(t *testing.T) secret.Store) {
+	newModel := func(id string) secret.Model {
+		return secret.Model{
+			ID:        id,
+			Name:      id + "-name",
+			Type:      "example",
+			Values:    map[string]string{"key": "value"},
+			Tags:      []string{"tag:b", "tag:a"},
+			UpdatedBy: "user",
+		}
+	}
+
+	t.Run("CreateGet", func(t *testing.T) {
+		ctx := context.Background()
+		store := newStore(t)
+
+		err := store.Create(ctx, 1, newModel("created-secret-id"))
+		require.NoError(t, err)
+
+		actual, err := store.Get(ctx, 1, "created-secret-id")
+		require.NoError(t, err)
+
+		assert.False(t, actual.UpdatedAt.IsZero())
+		actual.UpdatedAt = time.Time{}
+
+		expected := newModel("created-secret-id")
+		expected.Tags = []string{"tag:a", "tag:b"}
+		expected.Version = 1
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Create_AlreadyExists", func(t *testing.T) {
+		ctx := context.Background()
+		store := newStore(t)
+
+		err := store.Create(ctx, 1, newModel("already-existing-secret-id"))
+		require.NoError(t, err)
+
+		err = store.Create(ctx, 1, newModel("already-existing-secret-id"))
+		require.Error(t, err)
+
+		var alreadyExistsErr secret.AlreadyExistsError
+		if assert.True(t, errors.As(err, &alreadyExistsErr)) {
+			assert.Equal(t, uint(1), alreadyExistsErr.OrganizationID)
+			assert.Equal(t, "already-existing-secret-id", alreadyExistsErr.SecretID)
+		}
+
+		// The same ID in another organization is a different secret
+		err = store.Create(ctx, 2, newModel("already-existing-secret-id"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Put", func(t *testing.T) {
+		ctx := context.Background()
+		store := newStore(t)
+
+		model := newModel("updated-secret-id")
+
+		err := store.Put(ctx, 1, model)
+		require.NoError(t, err)
+
+		model.Values = map[string]string{"key": "value2"}
+		model.UpdatedBy = "user2"
+
+		err = store.Put(ctx, 1, model)
+		require.NoError(t, err)
+
+		actual, err := store.Get(ctx, 1, "updated-secret-id")
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"key": "value2"}, actual.Values)
+		assert.Equal(t, "user2", actual.UpdatedBy)
+		assert.Equal(t, 2, actual.Version)
+	})
+
+	t.Run("Get_NotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		_, err := store.Get(context.Background(), 1, "not-found-secret-id")
+		require.Error(t, err)
+
+		var notFoundErr secret.NotFoundError
+		if assert.True(t, errors.As(err, &notFoundErr)) {
+			assert.Equal(t, uint(1), notFoundErr.OrganizationID)
+			assert.Equal(t, "not-found-secret-id", notFoundErr.SecretID)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ctx := context.Background()
+		store := newStore(t)
+
+		models, err := store.List(ctx, 2)
+		require.NoError(t, err)
+		assert.NotNil(t, models)
+		assert.Empty(t, models)
+
+		require.NoError(t, store.Create(ctx, 2, newModel("list-secret-id-1")))
+		require.NoError(t, store.Create(ctx, 2, newModel("list-secret-id-2")))
+		require.NoError(t, store.Create(ctx, 3, newModel("other-org-secret-id")))
+
+		models, err = store.List(ctx, 2)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, model := range models {
+			ids = append(ids, model.ID)
+		}
+
+		assert.ElementsMatch(t, []string{"list-secret-id-1", "list-secret-id-2"}, ids)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		ctx := context.Background()
+		store := newStore(t)
+
+		require.NoError(t, store.Create(ctx, 1, newModel("delete-secret-id")))
+
+		err := store.Delete(ctx, 1, "delete-secret-id")
+		require.NoError(t, err)
+
+		_, err = store.Get(ctx, 1, "delete-secret-id")
+		assert.True(t, errors.As(err, &secret.NotFoundError{}))
+
+		// Deleted secrets can be created again
+		err = store.Create(ctx, 1, newModel("delete-secret-id"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete_Idempotent", func(t *testing.T) {
+		err := newStore(t).Delete(context.Background(), 1, "delete-idempotent-secret-id")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Labels and annotations of Kubernetes Secrets holding Pipeline secrets.
+const (
+	kubernetesManagedByLabel          = "secret.banzaicloud.io/managed-by"
+	kubernetesManagedByLabelValue     = "pipeline-secret-store"
+	kubernetesOrganizationLabel       = "secret.banzaicloud.io/organization-id"
+	kubernetesSecretIDAnnotation      = "secret.banzaicloud.io/secret-id"
+	kubernetesVersionAnnotation       = "secret.banzaicloud.io/version"
+	kubernetesUpdatedAtAnnotation     = "secret.banzaicloud.io/updated-at"
+	kubernetesSecretDataKey           = "secret"
+	kubernetesSecretObjectNamePattern = "pipeline-secret-%d-%s"
+)
+
+// NewKubernetesStore returns a new secret store backed by Kubernetes Secrets in a single namespace.
+func NewKubernetesStore(client kubernetes.Interface, namespace string) secret.Store {
+	return kubernetesStore{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+type kubernetesStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+func (s kubernetesStore) Create(_ context.Context, organizationID uint, model secret.Model) error {
+	kubeSecret, err := s.newKubeSecret(organizationID, model, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.CoreV1().Secrets(s.namespace).Create(kubeSecret)
+	if apierrors.IsAlreadyExists(err) {
+		return errors.WithStack(secret.AlreadyExistsError{
+			OrganizationID: organizationID,
+			SecretID:       model.ID,
+		})
+	} else if err != nil {
+		return errors.Wrap(err, "failed to store secret")
+	}
+
+	return nil
+}
+
+func (s kubernetesStore) Put(ctx context.Context, organizationID uint, model secret.Model) error {
+	existing, err := s.client.CoreV1().Secrets(s.namespace).Get(s.objectName(organizationID, model.ID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return s.Create(ctx, organizationID, model)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check if secret exists")
+	}
+
+	version, _ := strconv.Atoi(existing.Annotations[kubernetesVersionAnnotation])
+
+	kubeSecret, err := s.newKubeSecret(organizationID, model, version+1)
+	if err != nil {
+		return err
+	}
+
+	// Optimistic locking: the update fails if the secret changed since it was read
+	kubeSecret.ResourceVersion = existing.ResourceVersion
+
+	if _, err := s.client.CoreV1().Secrets(s.namespace).Update(kubeSecret); err != nil {
+		return errors.Wrap(err, "failed to store secret")
+	}
+
+	return nil
+}
+
+func (s kubernetesStore) Get(_ context.Context, organizationID uint, id string) (secret.Model, error) {
+	kubeSecret, err := s.client.CoreV1().Secrets(s.namespace).Get(s.objectName(organizationID, id), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return secret.Model{}, errors.WithStack(secret.NotFoundError{
+			OrganizationID: organizationID,
+			SecretID:       id,
+		})
+	} else if err != nil {
+		return secret.Model{}, errors.Wrap(err, "failed to read secret")
+	}
+
+	return parseKubeSecret(*kubeSecret)
+}
+
+func (s kubernetesStore) List(_ context.Context, organizationID uint) ([]secret.Model, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		kubernetesManagedByLabel:    kubernetesManagedByLabelValue,
+		kubernetesOrganizationLabel: strconv.FormatUint(uint64(organizationID), 10),
+	})
+
+	kubeSecrets, err := s.client.CoreV1().Secrets(s.namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed to list secrets", "organizationId", organizationID)
+	}
+
+	models := make([]secret.Model, 0, len(kubeSecrets.Items))
+
+	for _, kubeSecret := range kubeSecrets.Items {
+		model, err := parseKubeSecret(kubeSecret)
+		if err != nil {
+			return nil, errors.WithDetails(err, "organizationId", organizationID)
+		}
+
+		models = append(models, model)
+	}
+
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	return models, nil
+}
+
+func (s kubernetesStore) Delete(_ context.Context, organizationID uint, id string) error {
+	err := s.client.CoreV1().Secrets(s.namespace).Delete(s.objectName(organizationID, id), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.WrapWithDetails(
+			err, "failed to delete secret",
+			"organizationId", organizationID,
+			"secretId", id,
+		)
+	}
+
+	return nil
+}
+
+func (s kubernetesStore) objectName(organizationID uint, id string) string {
+	return fmt.Sprintf(kubernetesSecretObjectNamePattern, organizationID, id)
+}
+
+func (s kubernetesStore) newKubeSecret(organizationID uint, model secret.Model, version int) (*corev1.Secret, error) {
+	data, err := encodeSecret(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.objectName(organizationID, model.ID),
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				kubernetesManagedByLabel:    kubernetesManagedByLabelValue,
+				kubernetesOrganizationLabel: strconv.FormatUint(uint64(organizationID), 10),
+			},
+			Annotations: map[string]string{
+				kubernetesSecretIDAnnotation:  model.ID,
+				kubernetesVersionAnnotation:   strconv.Itoa(version),
+				kubernetesUpdatedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			kubernetesSecretDataKey: data,
+		},
+	}, nil
+}
+
+func parseKubeSecret(kubeSecret corev1.Secret) (secret.Model, error) {
+	id := kubeSecret.Annotations[kubernetesSecretIDAnnotation]
+
+	model, err := decodeSecret(id, kubeSecret.Data[kubernetesSecretDataKey])
+	if err != nil {
+		return secret.Model{}, err
+	}
+
+	model.Version, _ = strconv.Atoi(kubeSecret.Annotations[kubernetesVersionAnnotation])
+
+	updatedAt, err := time.Parse(time.RFC3339, kubeSecret.Annotations[kubernetesUpdatedAtAnnotation])
+	if err != nil {
+		return secret.Model{}, errors.WrapWithDetails(err, "failed to parse update time", "secretId", id)
+	}
+
+	model.UpdatedAt = updatedAt
+
+	return model, nil
+}
@@ -0,0 +1,48 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+func TestKubernetesStore(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) secret.Store {
+		return NewKubernetesStore(fake.NewSimpleClientset(), "pipeline-system")
+	})
+}
+
+func TestKubernetesStore_Object(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := NewKubernetesStore(client, "pipeline-system")
+
+	err := store.Create(context.Background(), 1, secret.Model{ID: "secret-id", Name: "secret-name", Type: "example"})
+	require.NoError(t, err)
+
+	kubeSecret, err := client.CoreV1().Secrets("pipeline-system").Get("pipeline-secret-1-secret-id", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", kubeSecret.Labels[kubernetesOrganizationLabel])
+	assert.Equal(t, "secret-id", kubeSecret.Annotations[kubernetesSecretIDAnnotation])
+	assert.Equal(t, "1", kubeSecret.Annotations[kubernetesVersionAnnotation])
+}
@@ -0,0 +1,203 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// encryptedSecretModel describes an encrypted secret in the database.
+type encryptedSecretModel struct {
+	ID             uint `gorm:"primary_key"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	OrganizationID uint   `gorm:"unique_index:idx_encrypted_secrets_org_secret"`
+	SecretID       string `gorm:"unique_index:idx_encrypted_secrets_org_secret"`
+	Version        int
+	EncryptedKey   []byte
+	Ciphertext     []byte
+}
+
+// TableName changes the default table name.
+func (encryptedSecretModel) TableName() string {
+	return "encrypted_secrets"
+}
+
+// Migrate executes the table migrations for the SQL secret store.
+func Migrate(db *gorm.DB, logger common.Logger) error {
+	tables := []interface{}{
+		encryptedSecretModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.Info("migrating model tables", map[string]interface{}{"table_names": strings.TrimSpace(tableNames)})
+
+	return db.AutoMigrate(tables...).Error
+}
+
+// NewSQLStore returns a new secret store backed by a relational database.
+// Secrets are encrypted at rest using envelope encryption with the provided key encryption key.
+func NewSQLStore(db *gorm.DB, kek []byte) (secret.Store, error) {
+	e, err := newEnvelope(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlStore{
+		db:       db,
+		envelope: e,
+	}, nil
+}
+
+type sqlStore struct {
+	db       *gorm.DB
+	envelope envelope
+}
+
+func (s sqlStore) Create(ctx context.Context, organizationID uint, model secret.Model) error {
+	var count int
+
+	err := s.db.Model(encryptedSecretModel{}).
+		Where(encryptedSecretModel{OrganizationID: organizationID, SecretID: model.ID}).
+		Count(&count).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to check if secret exists")
+	}
+
+	if count > 0 {
+		return errors.WithStack(secret.AlreadyExistsError{
+			OrganizationID: organizationID,
+			SecretID:       model.ID,
+		})
+	}
+
+	return s.Put(ctx, organizationID, model)
+}
+
+func (s sqlStore) Put(_ context.Context, organizationID uint, model secret.Model) error {
+	data, err := encodeSecret(model)
+	if err != nil {
+		return err
+	}
+
+	encryptedKey, ciphertext, err := s.envelope.seal(data)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to encrypt secret", "secretId", model.ID)
+	}
+
+	var existing encryptedSecretModel
+
+	err = s.db.Where(encryptedSecretModel{OrganizationID: organizationID, SecretID: model.ID}).First(&existing).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return errors.Wrap(err, "failed to check if secret exists")
+	}
+
+	existing.OrganizationID = organizationID
+	existing.SecretID = model.ID
+	existing.Version++
+	existing.EncryptedKey = encryptedKey
+	existing.Ciphertext = ciphertext
+
+	if err := s.db.Save(&existing).Error; err != nil {
+		return errors.WrapWithDetails(
+			err, "failed to store secret",
+			"organizationId", organizationID,
+			"secretId", model.ID,
+		)
+	}
+
+	return nil
+}
+
+func (s sqlStore) Get(_ context.Context, organizationID uint, id string) (secret.Model, error) {
+	var model encryptedSecretModel
+
+	err := s.db.Where(encryptedSecretModel{OrganizationID: organizationID, SecretID: id}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return secret.Model{}, errors.WithStack(secret.NotFoundError{
+			OrganizationID: organizationID,
+			SecretID:       id,
+		})
+	} else if err != nil {
+		return secret.Model{}, errors.Wrap(err, "failed to read secret")
+	}
+
+	return s.decrypt(model)
+}
+
+func (s sqlStore) List(_ context.Context, organizationID uint) ([]secret.Model, error) {
+	var models []encryptedSecretModel
+
+	err := s.db.Where(encryptedSecretModel{OrganizationID: organizationID}).Order("secret_id").Find(&models).Error
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed to list secrets", "organizationId", organizationID)
+	}
+
+	secrets := make([]secret.Model, 0, len(models))
+
+	for _, model := range models {
+		secretModel, err := s.decrypt(model)
+		if err != nil {
+			return nil, errors.WithDetails(err, "organizationId", organizationID)
+		}
+
+		secrets = append(secrets, secretModel)
+	}
+
+	return secrets, nil
+}
+
+func (s sqlStore) Delete(_ context.Context, organizationID uint, id string) error {
+	err := s.db.Where(encryptedSecretModel{OrganizationID: organizationID, SecretID: id}).Delete(encryptedSecretModel{}).Error
+	if err != nil {
+		return errors.WrapWithDetails(
+			err, "failed to delete secret",
+			"organizationId", organizationID,
+			"secretId", id,
+		)
+	}
+
+	return nil
+}
+
+func (s sqlStore) decrypt(model encryptedSecretModel) (secret.Model, error) {
+	data, err := s.envelope.open(model.EncryptedKey, model.Ciphertext)
+	if err != nil {
+		return secret.Model{}, errors.WrapIfWithDetails(err, "failed to decrypt secret", "secretId", model.SecretID)
+	}
+
+	secretModel, err := decodeSecret(model.SecretID, data)
+	if err != nil {
+		return secret.Model{}, err
+	}
+
+	secretModel.UpdatedAt = model.UpdatedAt
+	secretModel.Version = model.Version
+
+	return secretModel, nil
+}
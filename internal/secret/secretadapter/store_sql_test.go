@@ -0,0 +1,93 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+var testKEK = bytes.Repeat([]byte{1}, kekSize)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestSQLStore(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) secret.Store {
+		store, err := NewSQLStore(setUpDatabase(t), testKEK)
+		require.NoError(t, err)
+
+		return store
+	})
+}
+
+func TestSQLStore_EncryptedAtRest(t *testing.T) {
+	ctx := context.Background()
+	db := setUpDatabase(t)
+
+	store, err := NewSQLStore(db, testKEK)
+	require.NoError(t, err)
+
+	err = store.Create(ctx, 1, secret.Model{
+		ID:     "encrypted-secret-id",
+		Name:   "encrypted-secret-name",
+		Type:   "example",
+		Values: map[string]string{"key": "very-secret-value"},
+	})
+	require.NoError(t, err)
+
+	var model encryptedSecretModel
+	require.NoError(t, db.First(&model).Error)
+
+	assert.NotContains(t, string(model.Ciphertext), "very-secret-value")
+	assert.NotContains(t, string(model.Ciphertext), "encrypted-secret-name")
+
+	otherStore, err := NewSQLStore(db, bytes.Repeat([]byte{2}, kekSize))
+	require.NoError(t, err)
+
+	_, err = otherStore.Get(ctx, 1, "encrypted-secret-id")
+	require.Error(t, err, "secrets must not be readable with another key encryption key")
+}
+
+func TestParseKEK(t *testing.T) {
+	kek, err := ParseKEK(base64.StdEncoding.EncodeToString(testKEK))
+	require.NoError(t, err)
+	assert.Equal(t, testKEK, kek)
+
+	_, err = ParseKEK(base64.StdEncoding.EncodeToString([]byte("too-short")))
+	require.Error(t, err)
+
+	_, err = ParseKEK("not base64")
+	require.Error(t, err)
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,7 +33,7 @@ import (
 )
 
 // NewVaultStore returns a new secret store backed by Vault.
-func NewVaultStore(client *vault.Client, mountPath string) secret.Store {
+func NewVaultStore(client *vault.Client, mountPath string) secret.VersionedStore {
 	return vaultStore{
 		client: client,
 
@@ -58,6 +59,25 @@ func (s vaultStore) Create(_ context.Context, organizationID uint, model secret.
 
 	if _, err := s.client.RawClient().Logical().Write(path, data); err != nil {
 		if strings.Contains(err.Error(), "check-and-set parameter did not match the current version") {
+			// The latest version of a soft deleted secret can be overwritten
+			version, deleted, merr := s.currentVersion(organizationID, model.ID)
+			if merr != nil {
+				return merr
+			}
+
+			if deleted {
+				data, err := secretData(version, model)
+				if err != nil {
+					return err
+				}
+
+				if _, err := s.client.RawClient().Logical().Write(path, data); err != nil {
+					return errors.Wrap(err, "failed to store secret")
+				}
+
+				return nil
+			}
+
 			return secret.AlreadyExistsError{
 				OrganizationID: organizationID,
 				SecretID:       model.ID,
@@ -110,7 +130,8 @@ func (s vaultStore) Get(_ context.Context, organizationID uint, id string) (secr
 		return secret.Model{}, errors.Wrap(err, "failed to read secret")
 	}
 
-	if vaultSecret == nil {
+	// Soft deleted secrets are returned without data
+	if vaultSecret == nil || vaultSecret.Data["data"] == nil {
 		return secret.Model{}, errors.WithStack(secret.NotFoundError{
 			OrganizationID: organizationID,
 			SecretID:       id,
@@ -146,7 +167,7 @@ func (s vaultStore) List(_ context.Context, organizationID uint) ([]secret.Model
 			)
 		}
 
-		if vaultSecret == nil { // Secret was removed?
+		if vaultSecret == nil || vaultSecret.Data["data"] == nil { // Secret was removed or soft deleted?
 			continue
 		}
 
@@ -179,6 +200,122 @@ func (s vaultStore) Delete(_ context.Context, organizationID uint, id string) er
 	return nil
 }
 
+func (s vaultStore) ListVersions(_ context.Context, organizationID uint, id string) ([]secret.Version, error) {
+	metadata, err := s.readMetadata(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultVersions := cast.ToStringMap(metadata.Data["versions"])
+
+	versions := make([]secret.Version, 0, len(vaultVersions))
+
+	for key, value := range vaultVersions {
+		versionNumber, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.WrapWithDetails(err, "failed to parse secret version", "version", key)
+		}
+
+		vaultVersion := cast.ToStringMap(value)
+
+		updatedAt, err := time.Parse(time.RFC3339, cast.ToString(vaultVersion["created_time"]))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse update time")
+		}
+
+		version := secret.Version{
+			Version:   versionNumber,
+			UpdatedAt: updatedAt,
+			Deleted:   cast.ToString(vaultVersion["deletion_time"]) != "" || cast.ToBool(vaultVersion["destroyed"]),
+		}
+
+		if !version.Deleted {
+			model, err := s.GetVersion(context.Background(), organizationID, id, versionNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			version.UpdatedBy = model.UpdatedBy
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+
+	return versions, nil
+}
+
+func (s vaultStore) GetVersion(_ context.Context, organizationID uint, id string, version int) (secret.Model, error) {
+	path := s.secretDataPath(organizationID, id)
+
+	vaultSecret, err := s.client.RawClient().Logical().ReadWithData(path, map[string][]string{
+		"version": {strconv.Itoa(version)},
+	})
+	if err != nil {
+		return secret.Model{}, errors.Wrap(err, "failed to read secret version")
+	}
+
+	if vaultSecret == nil || vaultSecret.Data["data"] == nil {
+		return secret.Model{}, errors.WithStack(secret.VersionNotFoundError{
+			OrganizationID: organizationID,
+			SecretID:       id,
+			Version:        version,
+		})
+	}
+
+	return parseSecret(id, vaultSecret)
+}
+
+func (s vaultStore) SoftDelete(_ context.Context, organizationID uint, id string) error {
+	if _, err := s.client.RawClient().Logical().Delete(s.secretDataPath(organizationID, id)); err != nil {
+		return errors.WrapWithDetails(
+			err, "failed to delete secret",
+			"organizationId", organizationID,
+			"secretId", id,
+		)
+	}
+
+	return nil
+}
+
+func (s vaultStore) readMetadata(organizationID uint, id string) (*vaultapi.Secret, error) {
+	path := fmt.Sprintf("%s/metadata/orgs/%d/%s", s.mountPath, organizationID, id)
+
+	metadata, err := s.client.RawClient().Logical().Read(path)
+	if err != nil {
+		return nil, errors.WrapWithDetails(
+			err, "failed to read secret metadata",
+			"organizationId", organizationID,
+			"secretId", id,
+		)
+	}
+
+	if metadata == nil {
+		return nil, errors.WithStack(secret.NotFoundError{
+			OrganizationID: organizationID,
+			SecretID:       id,
+		})
+	}
+
+	return metadata, nil
+}
+
+// currentVersion returns the current version of a secret and whether that version is deleted.
+func (s vaultStore) currentVersion(organizationID uint, id string) (int, bool, error) {
+	metadata, err := s.readMetadata(organizationID, id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	version, _ := strconv.Atoi(fmt.Sprint(metadata.Data["current_version"]))
+	vaultVersion := cast.ToStringMap(cast.ToStringMap(metadata.Data["versions"])[strconv.Itoa(version)])
+
+	deleted := cast.ToString(vaultVersion["deletion_time"]) != "" || cast.ToBool(vaultVersion["destroyed"])
+
+	return version, deleted, nil
+}
+
 func (s vaultStore) secretDataPath(organizationID uint, secretID string) string {
 	return fmt.Sprintf("%s/data/orgs/%d/%s", s.mountPath, organizationID, secretID)
 }
@@ -205,10 +342,13 @@ func parseSecret(id string, vaultSecret *vaultapi.Secret) (secret.Model, error)
 		return secret.Model{}, errors.Wrap(err, "failed to parse update time")
 	}
 
+	version, _ := strconv.Atoi(fmt.Sprint(metadata["version"]))
+
 	model := secret.Model{
 		ID:        id,
 		UpdatedAt: updatedAt,
 		Tags:      []string{},
+		Version:   version,
 	}
 
 	if err := mapstructure.Decode(data["value"], &model); err != nil {
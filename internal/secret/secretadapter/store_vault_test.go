@@ -24,6 +24,7 @@ import (
 
 	"github.com/banzaicloud/bank-vaults/pkg/sdk/vault"
 	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/banzaicloud/pipeline/internal/secret"
@@ -37,6 +38,28 @@ func testVaultStore(t *testing.T) {
 	suite.Run(t, new(VaultStoreTestSuite))
 }
 
+// testVaultStoreContract runs the secret store contract tests against Vault using a new mount path for every test.
+func testVaultStoreContract(t *testing.T) {
+	client, err := vault.NewClient("")
+	require.NoError(t, err)
+
+	t.Cleanup(client.Close)
+
+	testStoreContract(t, func(t *testing.T) secret.Store {
+		path := fmt.Sprintf("%s-%d%d%d", mountPath, rand.Intn(30), rand.Intn(30), rand.Intn(30))
+
+		err := client.RawClient().Sys().Mount(path, &vaultapi.MountInput{
+			Type:    "kv",
+			Options: map[string]string{"version": "2"},
+		})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { _ = client.RawClient().Sys().Unmount(path) })
+
+		return NewVaultStore(client, path)
+	})
+}
+
 const mountPath = "testsecret"
 
 type VaultStoreTestSuite struct {
@@ -45,7 +68,7 @@ type VaultStoreTestSuite struct {
 	client    *vault.Client
 	mountPath string
 
-	store secret.Store
+	store secret.VersionedStore
 }
 
 func (s *VaultStoreTestSuite) SetupSuite() {
@@ -238,6 +261,7 @@ func (s *VaultStoreTestSuite) TestGet() {
 		},
 		Tags:      []string{"tag:value"},
 		UpdatedBy: "user",
+		Version:   1,
 	}
 
 	actual, err := s.store.Get(context.Background(), 1, "get-secret-id")
@@ -285,6 +309,7 @@ func (s *VaultStoreTestSuite) TestList() {
 			},
 			Tags:      []string{"tag:value"},
 			UpdatedBy: "user",
+			Version:   1,
 		},
 	}
 
@@ -325,3 +350,92 @@ func (s *VaultStoreTestSuite) TestDelete_Idempotent() {
 	err := s.store.Delete(context.Background(), 1, "delete-idempotent-secret-id")
 	s.Require().NoError(err)
 }
+
+func (s *VaultStoreTestSuite) TestVersions() {
+	ctx := context.Background()
+
+	model := secret.Model{
+		ID:        "versioned-secret-id",
+		Name:      "versioned-secret-name",
+		Type:      "example",
+		Values:    map[string]string{"key": "value"},
+		Tags:      []string{},
+		UpdatedBy: "user1",
+	}
+
+	err := s.store.Create(ctx, 1, model)
+	s.Require().NoError(err)
+
+	model.Values = map[string]string{"key": "value2"}
+	model.UpdatedBy = "user2"
+
+	err = s.store.Put(ctx, 1, model)
+	s.Require().NoError(err)
+
+	versions, err := s.store.ListVersions(ctx, 1, "versioned-secret-id")
+	s.Require().NoError(err)
+
+	if s.Assert().Len(versions, 2) {
+		s.Assert().Equal(1, versions[0].Version)
+		s.Assert().Equal("user1", versions[0].UpdatedBy)
+		s.Assert().False(versions[0].Deleted)
+		s.Assert().Equal(2, versions[1].Version)
+		s.Assert().Equal("user2", versions[1].UpdatedBy)
+	}
+
+	first, err := s.store.GetVersion(ctx, 1, "versioned-secret-id", 1)
+	s.Require().NoError(err)
+
+	s.Assert().Equal(map[string]string{"key": "value"}, first.Values)
+	s.Assert().Equal(1, first.Version)
+
+	_, err = s.store.GetVersion(ctx, 1, "versioned-secret-id", 3)
+	s.Require().Error(err)
+
+	var versionNotFoundErr secret.VersionNotFoundError
+	s.Assert().True(errors.As(err, &versionNotFoundErr))
+}
+
+func (s *VaultStoreTestSuite) TestSoftDelete() {
+	ctx := context.Background()
+
+	model := secret.Model{
+		ID:        "soft-delete-secret-id",
+		Name:      "soft-delete-secret-name",
+		Type:      "example",
+		Values:    map[string]string{"key": "value"},
+		Tags:      []string{},
+		UpdatedBy: "user",
+	}
+
+	err := s.store.Create(ctx, 3, model)
+	s.Require().NoError(err)
+
+	err = s.store.SoftDelete(ctx, 3, "soft-delete-secret-id")
+	s.Require().NoError(err)
+
+	_, err = s.store.Get(ctx, 3, "soft-delete-secret-id")
+	s.Require().Error(err)
+
+	var notFoundErr secret.NotFoundError
+	s.Assert().True(errors.As(err, &notFoundErr))
+
+	models, err := s.store.List(ctx, 3)
+	s.Require().NoError(err)
+	s.Assert().Empty(models)
+
+	versions, err := s.store.ListVersions(ctx, 3, "soft-delete-secret-id")
+	s.Require().NoError(err)
+
+	if s.Assert().Len(versions, 1) {
+		s.Assert().True(versions[0].Deleted)
+	}
+
+	// Soft deleted secrets can be created again
+	err = s.store.Create(ctx, 3, model)
+	s.Require().NoError(err)
+
+	actual, err := s.store.Get(ctx, 3, "soft-delete-secret-id")
+	s.Require().NoError(err)
+	s.Assert().Equal(2, actual.Version)
+}
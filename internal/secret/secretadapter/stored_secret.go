@@ -0,0 +1,74 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretadapter
+
+import (
+	"encoding/json"
+	"sort"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// storedSecret is the serialized form of a secret in stores without a native secret format.
+type storedSecret struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Values    map[string]string `json:"values"`
+	Tags      []string          `json:"tags"`
+	UpdatedBy string            `json:"updatedBy"`
+}
+
+func encodeSecret(model secret.Model) ([]byte, error) {
+	tags := append([]string{}, model.Tags...)
+	sort.Strings(tags)
+
+	data, err := json.Marshal(storedSecret{
+		Name:      model.Name,
+		Type:      model.Type,
+		Values:    model.Values,
+		Tags:      tags,
+		UpdatedBy: model.UpdatedBy,
+	})
+	if err != nil {
+		return nil, errors.WrapWithDetails(err, "failed to encode secret", "secretId", model.ID)
+	}
+
+	return data, nil
+}
+
+func decodeSecret(id string, data []byte) (secret.Model, error) {
+	var s storedSecret
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return secret.Model{}, errors.WrapWithDetails(err, "failed to parse secret", "secretId", id)
+	}
+
+	model := secret.Model{
+		ID:        id,
+		Name:      s.Name,
+		Type:      s.Type,
+		Values:    s.Values,
+		Tags:      s.Tags,
+		UpdatedBy: s.UpdatedBy,
+	}
+
+	if model.Tags == nil {
+		model.Tags = []string{}
+	}
+
+	return model, nil
+}
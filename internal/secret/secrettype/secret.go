@@ -176,6 +176,13 @@ const (
 	DoToken = "DO_TOKEN"
 )
 
+// RFC2136 keys
+const (
+	RFC2136TSIGKeyName   = "TSIG_KEY_NAME"
+	RFC2136TSIGSecret    = "TSIG_SECRET"
+	RFC2136TSIGSecretAlg = "TSIG_SECRET_ALG"
+)
+
 // Vault keys
 const (
 	VaultToken = "token"
@@ -191,6 +198,20 @@ const (
 	PagerDutyIntegrationKey = "integrationKey"
 )
 
+// Opsgenie keys
+const (
+	OpsgenieAPIKey = "apiKey"
+)
+
+// SMTP keys
+const (
+	SMTPSmarthost  = "smarthost"
+	SMTPFrom       = "from"
+	SMTPUsername   = "username"
+	SMTPPassword   = "password"
+	SMTPRequireTLS = "requireTls"
+)
+
 const (
 	// GenericSecret represents generic secret types, without schema
 	GenericSecret = "generic"
@@ -212,12 +233,18 @@ const (
 	CloudFlareSecretType = "cloudflare"
 	// DigitalOceanSecretType marks secrets as of type "digitalocean"
 	DigitalOceanSecretType = "digitalocean"
+	// RFC2136SecretType marks secrets as of type "rfc2136"
+	RFC2136SecretType = "rfc2136"
 	// VaultSecretType as marks secrets as of type "vault"
 	VaultSecretType = "vault"
 	// SlackSecretType as marks secrets as of type "slack"
 	SlackSecretType = "slack"
 	// PagerDutySecretType as marks secrets as of type "pagerduty"
 	PagerDutySecretType = "pagerduty"
+	// OpsgenieSecretType as marks secrets as of type "opsgenie"
+	OpsgenieSecretType = "opsgenie"
+	// SMTPSecretType as marks secrets as of type "smtp"
+	SMTPSecretType = "smtp"
 )
 
 // DefaultRules key matching for types
@@ -360,6 +387,13 @@ var DefaultRules = map[string]Meta{
 			{Name: DoToken, Required: true, Opaque: true, Description: "Your API Token"},
 		},
 	},
+	RFC2136SecretType: {
+		Fields: []FieldMeta{
+			{Name: RFC2136TSIGKeyName, Required: true, Description: "Name of the TSIG key"},
+			{Name: RFC2136TSIGSecret, Required: true, Opaque: true, Description: "Base64 encoded TSIG secret"},
+			{Name: RFC2136TSIGSecretAlg, Required: false, Description: "TSIG algorithm (defaults to hmac-sha256)"},
+		},
+	},
 	VaultSecretType: {
 		Fields: []FieldMeta{
 			{Name: VaultToken, Required: true, Opaque: true, Description: "Token for Vault"},
@@ -375,4 +409,18 @@ var DefaultRules = map[string]Meta{
 			{Name: PagerDutyIntegrationKey, Required: true, Opaque: true, Description: "The PagerDuty integration key"},
 		},
 	},
+	OpsgenieSecretType: {
+		Fields: []FieldMeta{
+			{Name: OpsgenieAPIKey, Required: true, Opaque: true, Description: "The Opsgenie API key"},
+		},
+	},
+	SMTPSecretType: {
+		Fields: []FieldMeta{
+			{Name: SMTPSmarthost, Required: true, Description: "SMTP server address in host:port format"},
+			{Name: SMTPFrom, Required: true, Description: "Sender address of the emails"},
+			{Name: SMTPUsername, Required: false, Description: "SMTP username"},
+			{Name: SMTPPassword, Required: false, Opaque: true, Description: "SMTP password"},
+			{Name: SMTPRequireTLS, Required: false, Description: "Require STARTTLS (true or false, defaults to true)"},
+		},
+	},
 }
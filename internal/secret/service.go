@@ -75,6 +75,35 @@ func (AlreadyExistsError) ServiceError() bool {
 	return true
 }
 
+// VersionNotFoundError is returned when a secret version cannot be found.
+type VersionNotFoundError struct {
+	OrganizationID uint
+	SecretID       string
+	Version        int
+}
+
+// Error implements the error interface.
+func (VersionNotFoundError) Error() string {
+	return "secret version not found"
+}
+
+// Details returns error details.
+func (e VersionNotFoundError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "secretId", e.SecretID, "version", e.Version}
+}
+
+// NotFound tells a consumer that this error is related to a resource being not found.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (VersionNotFoundError) NotFound() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (VersionNotFoundError) ServiceError() bool {
+	return true
+}
+
 // Model is an internal, low-level representation of a secret.
 type Model struct {
 	ID        string            `mapstructure:"-"`
@@ -84,8 +113,21 @@ type Model struct {
 	Tags      []string          `mapstructure:"tags"`
 	UpdatedAt time.Time         `mapstructure:"-"`
 	UpdatedBy string            `mapstructure:"updatedBy"`
+	Version   int               `mapstructure:"-"`
+}
+
+// Version describes a single version in the history of a secret.
+type Version struct {
+	Version   int
+	UpdatedAt time.Time
+	UpdatedBy string
+	Deleted   bool
 }
 
+// SystemOrganizationID is the organization under which Pipeline keeps its own secrets (eg. access tokens) in a Store.
+// Organization IDs start from 1, so these secrets never show up among the secrets of an organization.
+const SystemOrganizationID uint = 0
+
 // Store is a low-level interface for a key-value like secret store.
 type Store interface {
 	// Create writes a new secret in the store.
@@ -105,3 +147,18 @@ type Store interface {
 	// Delete deletes a secret from the store.
 	Delete(ctx context.Context, organizationID uint, id string) error
 }
+
+// VersionedStore is a secret store that keeps the history of secrets.
+type VersionedStore interface {
+	Store
+
+	// ListVersions lists the versions of a secret (including deleted ones) in ascending order.
+	ListVersions(ctx context.Context, organizationID uint, id string) ([]Version, error)
+
+	// GetVersion retrieves a specific version of a secret from the store.
+	GetVersion(ctx context.Context, organizationID uint, id string, version int) (Model, error)
+
+	// SoftDelete deletes the latest version of a secret, keeping its history.
+	// A soft deleted secret is not returned by Get and List, but it can be restored by writing an earlier version again.
+	SoftDelete(ctx context.Context, organizationID uint, id string) error
+}
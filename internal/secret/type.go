@@ -14,6 +14,10 @@
 
 package secret
 
+import (
+	"time"
+)
+
 // Type describes a secret type.
 type Type interface {
 	// Name is the type name.
@@ -72,6 +76,22 @@ type VerifierType interface {
 	Verify(data map[string]string) error
 }
 
+// RotatorType can be implemented by a secret type that can regenerate the values of an existing secret.
+//
+// Rotation is done periodically based on the rotation policy of a secret.
+type RotatorType interface {
+	// Rotate generates new values for an existing secret.
+	//
+	// Values that are not generated (eg. usernames, hosts) should be kept.
+	Rotate(organizationID uint, secretName string, data map[string]string, tags []string) (map[string]string, error)
+}
+
+// ExpiringType can be implemented by a secret type whose values expire (eg. certificates).
+type ExpiringType interface {
+	// ExpiresAt returns the time when a secret expires.
+	ExpiresAt(data map[string]string) (time.Time, error)
+}
+
 // CleanupType can be implemented by a secret type that adds secret cleanup abilities to the type.
 //
 // This is added temporarily for PKE secret type.
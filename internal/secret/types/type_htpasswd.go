@@ -96,6 +96,19 @@ func (t HtpasswdType) Generate(_ uint, _ string, data map[string]string, _ []str
 	return data, nil
 }
 
+// Rotate generates a new password. The htpasswd file is regenerated when the secret is processed.
+func (t HtpasswdType) Rotate(_ uint, _ string, data map[string]string, _ []string) (map[string]string, error) {
+	password, err := passwordRandomString("randAlphaNum", 12)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to generate password")
+	}
+
+	values := copyValues(data)
+	values[FieldHtpasswdPassword] = password
+
+	return values, nil
+}
+
 func (t HtpasswdType) Process(data map[string]string) (map[string]string, error) {
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(data[FieldHtpasswdPassword]), bcrypt.DefaultCost)
 	if err != nil {
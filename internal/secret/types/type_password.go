@@ -109,6 +109,26 @@ func (t PasswordType) Generate(_ uint, _ string, data map[string]string, _ []str
 	return data, nil
 }
 
+// Rotate generates a new password with the length of the current one.
+func (t PasswordType) Rotate(_ uint, _ string, data map[string]string, _ []string) (map[string]string, error) {
+	length := len(data[FieldPasswordPassword])
+	if length < minRotatedPasswordLength {
+		length = minRotatedPasswordLength
+	}
+
+	password, err := passwordRandomString("randAlphaNum", length)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to generate password")
+	}
+
+	values := copyValues(data)
+	values[FieldPasswordPassword] = password
+
+	return values, nil
+}
+
+const minRotatedPasswordLength = 12
+
 // passwordRandomString creates a random string whose length is the number of characters specified.
 // TODO: reuse random function (or use single, struct level password generator in the type?).
 func passwordRandomString(genType string, length int) (res string, err error) {
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/banzaicloud/pipeline/internal/secret"
 )
@@ -26,6 +27,7 @@ import (
 func TestPasswordType(t *testing.T) {
 	assert.Implements(t, (*secret.Type)(nil), new(PasswordType))
 	assert.Implements(t, (*secret.GeneratorType)(nil), new(PasswordType))
+	assert.Implements(t, (*secret.RotatorType)(nil), new(PasswordType))
 }
 
 func TestPasswordType_Validate(t *testing.T) {
@@ -152,3 +154,19 @@ func TestPasswordType_ValidateNew(t *testing.T) {
 func TestPasswordType_Generate(t *testing.T) {
 	// TODO
 }
+
+func TestPasswordType_Rotate(t *testing.T) {
+	typ := PasswordType{}
+
+	data := map[string]string{
+		FieldPasswordUsername: "user",
+		FieldPasswordPassword: "01234567890123456789",
+	}
+
+	values, err := typ.Rotate(0, "", data, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", values[FieldPasswordUsername])
+	assert.Len(t, values[FieldPasswordPassword], 20)
+	assert.NotEqual(t, data[FieldPasswordPassword], values[FieldPasswordPassword])
+}
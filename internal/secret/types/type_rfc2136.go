@@ -0,0 +1,72 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+const RFC2136 = "rfc2136"
+
+const (
+	FieldRFC2136TSIGKeyName   = "TSIG_KEY_NAME"
+	FieldRFC2136TSIGSecret    = "TSIG_SECRET"
+	FieldRFC2136TSIGSecretAlg = "TSIG_SECRET_ALG"
+)
+
+// RFC2136DefaultTSIGSecretAlg is used when no TSIG algorithm is specified.
+const RFC2136DefaultTSIGSecretAlg = "hmac-sha256"
+
+// nolint: gochecknoglobals
+var rfc2136TSIGSecretAlgs = []string{"hmac-md5", "hmac-sha1", "hmac-sha224", "hmac-sha256", "hmac-sha384", "hmac-sha512"}
+
+type RFC2136Type struct{}
+
+func (RFC2136Type) Name() string {
+	return RFC2136
+}
+
+func (RFC2136Type) Definition() secret.TypeDefinition {
+	return secret.TypeDefinition{
+		Fields: []secret.FieldDefinition{
+			{Name: FieldRFC2136TSIGKeyName, Required: true, Description: "Name of the TSIG key"},
+			{Name: FieldRFC2136TSIGSecret, Required: true, Opaque: true, Description: "Base64 encoded TSIG secret"},
+			{Name: FieldRFC2136TSIGSecretAlg, Required: false, Description: fmt.Sprintf("TSIG algorithm (one of %s, defaults to %s)", strings.Join(rfc2136TSIGSecretAlgs, ", "), RFC2136DefaultTSIGSecretAlg)},
+		},
+	}
+}
+
+func (t RFC2136Type) Validate(data map[string]string) error {
+	if err := validateDefinition(data, t.Definition()); err != nil {
+		return err
+	}
+
+	if alg := data[FieldRFC2136TSIGSecretAlg]; alg != "" {
+		for _, supportedAlg := range rfc2136TSIGSecretAlgs {
+			if alg == supportedAlg {
+				return nil
+			}
+		}
+
+		message := fmt.Sprintf("unsupported TSIG algorithm: %s", alg)
+
+		return secret.NewValidationError(message, []string{message})
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"net"
+	"net/mail"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+const SMTP = "smtp"
+
+const (
+	FieldSMTPSmarthost  = "smarthost"
+	FieldSMTPFrom       = "from"
+	FieldSMTPUsername   = "username"
+	FieldSMTPPassword   = "password"
+	FieldSMTPRequireTLS = "requireTls"
+)
+
+type SMTPType struct{}
+
+func (SMTPType) Name() string {
+	return SMTP
+}
+
+func (SMTPType) Definition() secret.TypeDefinition {
+	return secret.TypeDefinition{
+		Fields: []secret.FieldDefinition{
+			{Name: FieldSMTPSmarthost, Required: true, Description: "SMTP server address in host:port format"},
+			{Name: FieldSMTPFrom, Required: true, Description: "Sender address of the emails"},
+			{Name: FieldSMTPUsername, Required: false, Description: "SMTP username"},
+			{Name: FieldSMTPPassword, Required: false, Opaque: true, Description: "SMTP password"},
+			{Name: FieldSMTPRequireTLS, Required: false, Description: "Require STARTTLS (true or false, defaults to true)"},
+		},
+	}
+}
+
+func (t SMTPType) Validate(data map[string]string) error {
+	if err := validateDefinition(data, t.Definition()); err != nil {
+		return err
+	}
+
+	var violations []string
+
+	if _, port, err := net.SplitHostPort(data[FieldSMTPSmarthost]); err != nil || port == "" {
+		violations = append(violations, "smarthost must be in host:port format")
+	}
+
+	if _, err := mail.ParseAddress(data[FieldSMTPFrom]); err != nil {
+		violations = append(violations, "from must be a valid email address")
+	}
+
+	if requireTLS, ok := data[FieldSMTPRequireTLS]; ok && requireTLS != "" {
+		if _, err := strconv.ParseBool(requireTLS); err != nil {
+			violations = append(violations, "requireTls must be true or false")
+		}
+	}
+
+	if len(violations) > 0 {
+		return secret.NewValidationError("invalid SMTP secret", violations)
+	}
+
+	return nil
+}
@@ -15,7 +15,10 @@
 package types
 
 import (
+	"emperror.dev/errors"
+
 	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/ssh"
 )
 
 const SSH = "ssh"
@@ -49,3 +52,18 @@ func (SSHType) Definition() secret.TypeDefinition {
 func (t SSHType) Validate(data map[string]string) error {
 	return validateDefinition(data, t.Definition())
 }
+
+// Rotate generates a new key pair for the same user.
+func (t SSHType) Rotate(_ uint, _ string, data map[string]string, _ []string) (map[string]string, error) {
+	keyPair, err := ssh.NewKeyPairGenerator().Generate()
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to generate SSH key pair")
+	}
+
+	values := copyValues(data)
+	values[FieldSSHPublicKeyData] = keyPair.PublicKeyData
+	values[FieldSSHPublicKeyFingerprint] = keyPair.PublicKeyFingerprint
+	values[FieldSSHPrivateKeyData] = keyPair.PrivateKeyData
+
+	return values, nil
+}
@@ -19,12 +19,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/banzaicloud/pipeline/internal/secret"
 )
 
 func TestSSHType(t *testing.T) {
 	assert.Implements(t, (*secret.Type)(nil), new(SSHType))
+	assert.Implements(t, (*secret.RotatorType)(nil), new(SSHType))
 }
 
 func TestSSHType_Validate(t *testing.T) {
@@ -81,3 +83,23 @@ func TestSSHType_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestSSHType_Rotate(t *testing.T) {
+	typ := SSHType{}
+
+	data := map[string]string{
+		FieldSSHUser:                 "user",
+		FieldSSHIdentifier:           "identifier",
+		FieldSSHPublicKeyData:        "public",
+		FieldSSHPublicKeyFingerprint: "fingerprint",
+		FieldSSHPrivateKeyData:       "private",
+	}
+
+	values, err := typ.Rotate(0, "", data, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", values[FieldSSHUser])
+	assert.Equal(t, "identifier", values[FieldSSHIdentifier])
+	assert.NotEqual(t, "private", values[FieldSSHPrivateKeyData])
+	assert.NoError(t, typ.Validate(values))
+}
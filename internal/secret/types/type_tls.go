@@ -15,6 +15,8 @@
 package types
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"time"
 
@@ -137,3 +139,47 @@ func (t TLSType) Generate(_ uint, _ string, data map[string]string, _ []string)
 
 	return data, nil
 }
+
+// Rotate generates a new set of certificates for the same hosts.
+func (t TLSType) Rotate(organizationID uint, secretName string, data map[string]string, tags []string) (map[string]string, error) {
+	values := map[string]string{
+		FieldTLSHosts: data[FieldTLSHosts],
+	}
+
+	if validity, ok := data[FieldTLSValidity]; ok {
+		values[FieldTLSValidity] = validity
+	}
+
+	return t.Generate(organizationID, secretName, values, tags)
+}
+
+// ExpiresAt returns the time when the first certificate of the secret expires.
+func (t TLSType) ExpiresAt(data map[string]string) (time.Time, error) {
+	var expiresAt time.Time
+
+	for _, field := range []string{FieldTLSCACert, FieldTLSServerCert, FieldTLSClientCert, FieldTLSPeerCert} {
+		if data[field] == "" {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(data[field]))
+		if block == nil {
+			return time.Time{}, errors.NewWithDetails("failed to decode certificate", "field", field)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, errors.WrapIfWithDetails(err, "failed to parse certificate", "field", field)
+		}
+
+		if expiresAt.IsZero() || cert.NotAfter.Before(expiresAt) {
+			expiresAt = cert.NotAfter
+		}
+	}
+
+	if expiresAt.IsZero() {
+		return time.Time{}, errors.New("secret does not contain any certificates")
+	}
+
+	return expiresAt, nil
+}
@@ -17,8 +17,10 @@ package types
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/banzaicloud/pipeline/internal/secret"
 )
@@ -26,6 +28,8 @@ import (
 func TestTLSType(t *testing.T) {
 	assert.Implements(t, (*secret.Type)(nil), new(TLSType))
 	assert.Implements(t, (*secret.GeneratorType)(nil), new(TLSType))
+	assert.Implements(t, (*secret.RotatorType)(nil), new(TLSType))
+	assert.Implements(t, (*secret.ExpiringType)(nil), new(TLSType))
 }
 
 func TestTLSType_Validate(t *testing.T) {
@@ -191,3 +195,33 @@ func TestTLSType_ValidateNew(t *testing.T) {
 func TestTLSType_Generate(t *testing.T) {
 	// TODO
 }
+
+func TestTLSType_Rotate(t *testing.T) {
+	typ := TLSType{DefaultValidity: 24 * time.Hour}
+
+	data, err := typ.Generate(0, "", map[string]string{FieldTLSHosts: "example.com", FieldTLSValidity: "48h"}, nil)
+	require.NoError(t, err)
+
+	values, err := typ.Rotate(0, "", data, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", values[FieldTLSHosts])
+	assert.Equal(t, "48h", values[FieldTLSValidity])
+	assert.NotEqual(t, data[FieldTLSServerCert], values[FieldTLSServerCert])
+	assert.NoError(t, typ.Validate(values))
+}
+
+func TestTLSType_ExpiresAt(t *testing.T) {
+	typ := TLSType{}
+
+	values, err := typ.Generate(0, "", map[string]string{FieldTLSHosts: "example.com", FieldTLSValidity: "48h"}, nil)
+	require.NoError(t, err)
+
+	expiresAt, err := typ.ExpiresAt(values)
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), expiresAt, time.Hour)
+
+	_, err = typ.ExpiresAt(map[string]string{FieldTLSHosts: "example.com"})
+	assert.Error(t, err)
+}
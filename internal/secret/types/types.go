@@ -40,11 +40,14 @@ func NewDefaultTypeList(config DefaultTypeListConfig) secret.TypeList {
 		GoogleType{},
 		HtpasswdType{},
 		KubernetesType{},
+		OpsgenieType{},
 		OracleType{},
 		PagerDutyType{},
 		PasswordType{},
 		PKEType{PkeSecreter: config.PkeSecreter},
+		RFC2136Type{},
 		SlackType{},
+		SMTPType{},
 		SSHType{},
 		TLSType{DefaultValidity: config.TLSDefaultValidity},
 		VaultType{},
@@ -36,3 +36,14 @@ func validateDefinition(secretData map[string]string, def secret.TypeDefinition)
 
 	return nil
 }
+
+// copyValues returns a copy of secret values, so that types do not modify their input.
+func copyValues(data map[string]string) map[string]string {
+	values := make(map[string]string, len(data))
+
+	for key, value := range data {
+		values[key] = value
+	}
+
+	return values
+}
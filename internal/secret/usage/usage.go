@@ -0,0 +1,218 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"sort"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Resource types referencing secrets.
+const (
+	ResourceTypeCluster           = "cluster"
+	ResourceTypeHelmRepository    = "helmRepository"
+	ResourceTypeIntegratedService = "integratedService"
+	ResourceTypeBucket            = "bucket"
+	ResourceTypeBackupBucket      = "backupBucket"
+)
+
+// Reference describes a resource referencing a secret.
+type Reference struct {
+	// SecretID is the ID of the referenced secret.
+	SecretID string `json:"secretId"`
+
+	// ResourceType is the type of the referencing resource.
+	ResourceType string `json:"resourceType"`
+
+	// ResourceID identifies the referencing resource (within its type and cluster).
+	ResourceID string `json:"resourceId"`
+
+	// ResourceName is the human readable name of the referencing resource.
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// ClusterID is the ID of the cluster the referencing resource belongs to (if any).
+	ClusterID uint `json:"clusterId,omitempty"`
+
+	// Field is the field of the resource holding the secret ID.
+	Field string `json:"field"`
+}
+
+// +testify:mock:testOnly=true
+
+// Source lists secret references of a resource type.
+type Source interface {
+	// ListReferences lists every secret reference in an organization.
+	ListReferences(ctx context.Context, organizationID uint) ([]Reference, error)
+}
+
+// Index is a reverse-reference index of secrets built from the resources referencing them.
+// Nothing is cached: every query scans the sources, so that deletion checks never see stale references.
+type Index struct {
+	sources []Source
+}
+
+// NewIndex returns a new Index.
+func NewIndex(sources ...Source) Index {
+	return Index{
+		sources: sources,
+	}
+}
+
+// Scan lists every secret reference in an organization from the sources, keyed by secret ID.
+func (i Index) Scan(ctx context.Context, organizationID uint) (map[string][]Reference, error) {
+	index := make(map[string][]Reference)
+
+	for _, source := range i.sources {
+		references, err := source.ListReferences(ctx, organizationID)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to list secret references", "organizationId", organizationID)
+		}
+
+		for _, reference := range references {
+			if reference.SecretID == "" {
+				continue
+			}
+
+			index[reference.SecretID] = append(index[reference.SecretID], reference)
+		}
+	}
+
+	for _, references := range index {
+		sortReferences(references)
+	}
+
+	return index, nil
+}
+
+// References returns the resources referencing a secret.
+func (i Index) References(ctx context.Context, organizationID uint, secretID string) ([]Reference, error) {
+	index, err := i.Scan(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return index[secretID], nil
+}
+
+// EnsureUnused returns an InUseError if a secret is referenced by any resource.
+func (i Index) EnsureUnused(ctx context.Context, organizationID uint, secretID string) error {
+	references, err := i.References(ctx, organizationID, secretID)
+	if err != nil {
+		return err
+	}
+
+	if len(references) > 0 {
+		return errors.WithStack(InUseError{
+			OrganizationID: organizationID,
+			SecretID:       secretID,
+			References:     references,
+		})
+	}
+
+	return nil
+}
+
+func sortReferences(references []Reference) {
+	sort.Slice(references, func(i, j int) bool {
+		a, b := references[i], references[j]
+
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+
+		if a.ClusterID != b.ClusterID {
+			return a.ClusterID < b.ClusterID
+		}
+
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+
+		return a.Field < b.Field
+	})
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service tells where secrets are used.
+type Service interface {
+	// GetSecretUsage returns the resources referencing a secret.
+	GetSecretUsage(ctx context.Context, organizationID uint, secretID string) (references []Reference, err error)
+}
+
+// NewService returns a new Service.
+func NewService(index Index, secrets secret.Store) Service {
+	return service{
+		index:   index,
+		secrets: secrets,
+	}
+}
+
+type service struct {
+	index   Index
+	secrets secret.Store
+}
+
+func (s service) GetSecretUsage(ctx context.Context, organizationID uint, secretID string) ([]Reference, error) {
+	// Make sure the secret exists (references to deleted secrets are not interesting)
+	if _, err := s.secrets.Get(ctx, organizationID, secretID); err != nil {
+		return nil, err
+	}
+
+	references, err := s.index.References(ctx, organizationID, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	if references == nil {
+		references = []Reference{}
+	}
+
+	return references, nil
+}
+
+// InUseError is returned when a secret cannot be deleted because other resources still reference it.
+type InUseError struct {
+	OrganizationID uint
+	SecretID       string
+	References     []Reference
+}
+
+// Error implements the error interface.
+func (InUseError) Error() string {
+	return "secret is in use"
+}
+
+// Details returns error details.
+func (e InUseError) Details() []interface{} {
+	return []interface{}{"organizationId", e.OrganizationID, "secretId", e.SecretID, "references", len(e.References)}
+}
+
+// Conflict tells the consumer that this error is related to a conflicting request.
+// Can be used to translate the error to the consumer's response format (eg. status codes).
+func (InUseError) Conflict() bool {
+	return true
+}
+
+// ServiceError tells the consumer that this is a business error and it should be returned to the client.
+// Non-service errors are usually translated into "internal" errors.
+func (InUseError) ServiceError() bool {
+	return true
+}
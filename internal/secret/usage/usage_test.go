@@ -0,0 +1,80 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+
+	clusters := new(MockSource)
+	clusters.On("ListReferences", ctx, uint(1)).Return([]Reference{
+		{SecretID: "provider", ResourceType: ResourceTypeCluster, ResourceID: "2", ClusterID: 2, Field: "secretId"},
+		{SecretID: "provider", ResourceType: ResourceTypeCluster, ResourceID: "1", ClusterID: 1, Field: "secretId"},
+		{SecretID: "", ResourceType: ResourceTypeCluster, ResourceID: "1", ClusterID: 1, Field: "sshSecretId"},
+	}, nil)
+
+	repositories := new(MockSource)
+	repositories.On("ListReferences", ctx, uint(1)).Return([]Reference{
+		{SecretID: "password", ResourceType: ResourceTypeHelmRepository, ResourceID: "stable", Field: "passwordSecretId"},
+	}, nil)
+
+	index := NewIndex(clusters, repositories)
+
+	references, err := index.Scan(ctx, 1)
+	require.NoError(t, err)
+
+	expected := map[string][]Reference{
+		"provider": {
+			{SecretID: "provider", ResourceType: ResourceTypeCluster, ResourceID: "1", ClusterID: 1, Field: "secretId"},
+			{SecretID: "provider", ResourceType: ResourceTypeCluster, ResourceID: "2", ClusterID: 2, Field: "secretId"},
+		},
+		"password": {
+			{SecretID: "password", ResourceType: ResourceTypeHelmRepository, ResourceID: "stable", Field: "passwordSecretId"},
+		},
+	}
+	assert.Equal(t, expected, references)
+
+	err = index.EnsureUnused(ctx, 1, "unused")
+	require.NoError(t, err)
+
+	err = index.EnsureUnused(ctx, 1, "password")
+	require.Error(t, err)
+
+	var inUseErr InUseError
+	require.True(t, errors.As(err, &inUseErr))
+	assert.Equal(t, expected["password"], inUseErr.References)
+
+	clusters.AssertExpectations(t)
+	repositories.AssertExpectations(t)
+}
+
+func TestIndex_SourceError(t *testing.T) {
+	ctx := context.Background()
+
+	source := new(MockSource)
+	source.On("ListReferences", ctx, uint(1)).Return(nil, errors.New("error"))
+
+	err := NewIndex(source).EnsureUnused(ctx, 1, "secret")
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &InUseError{}))
+}
@@ -0,0 +1,105 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+)
+
+// bucketTable describes a table storing object store buckets.
+type bucketTable struct {
+	name               string
+	organizationColumn string
+	nameColumn         string
+	secretColumns      map[string]string // column name -> field name
+	softDelete         bool
+	resourceType       string
+}
+
+// nolint: gochecknoglobals
+var bucketTables = []bucketTable{
+	{name: "alibaba_buckets", organizationColumn: "org_id", nameColumn: "name", secretColumns: map[string]string{"secret_ref": "secretId"}, resourceType: usage.ResourceTypeBucket},
+	{name: "amazon_buckets", organizationColumn: "organization_id", nameColumn: "name", secretColumns: map[string]string{"secret_ref": "secretId"}, resourceType: usage.ResourceTypeBucket},
+	{name: "azure_buckets", organizationColumn: "organization_id", nameColumn: "name", secretColumns: map[string]string{"secret_ref": "secretId", "access_secret_ref": "accessSecretId"}, resourceType: usage.ResourceTypeBucket},
+	{name: "google_buckets", organizationColumn: "organization_id", nameColumn: "name", secretColumns: map[string]string{"secret_ref": "secretId"}, resourceType: usage.ResourceTypeBucket},
+	{name: "oracle_buckets", organizationColumn: "org_id", nameColumn: "name", secretColumns: map[string]string{"secret_ref": "secretId"}, resourceType: usage.ResourceTypeBucket},
+	{name: "ark_backup_buckets", organizationColumn: "organization_id", nameColumn: "bucket_name", secretColumns: map[string]string{"secret_id": "secretId"}, softDelete: true, resourceType: usage.ResourceTypeBackupBucket},
+}
+
+// BucketSource lists the secrets referenced by object store and backup buckets.
+//
+// Bucket models live in the provider packages, so they are queried by table here
+// to avoid depending on every provider.
+type BucketSource struct {
+	db *gorm.DB
+}
+
+// NewBucketSource returns a new BucketSource.
+func NewBucketSource(db *gorm.DB) BucketSource {
+	return BucketSource{
+		db: db,
+	}
+}
+
+// ListReferences implements the usage.Source interface.
+func (s BucketSource) ListReferences(_ context.Context, organizationID uint) ([]usage.Reference, error) {
+	var references []usage.Reference
+
+	for _, table := range bucketTables {
+		if !s.db.HasTable(table.name) {
+			continue
+		}
+
+		for column, field := range table.secretColumns {
+			var rows []struct {
+				ID       uint
+				Name     string
+				SecretID string
+			}
+
+			query := s.db.
+				Table(table.name).
+				Select(fmt.Sprintf("id, %s AS name, %s AS secret_id", table.nameColumn, column)).
+				Where(fmt.Sprintf("%s = ?", table.organizationColumn), organizationID).
+				Where(fmt.Sprintf("%s <> ''", column))
+
+			if table.softDelete {
+				query = query.Where("deleted_at IS NULL")
+			}
+
+			if err := query.Scan(&rows).Error; err != nil {
+				return nil, errors.WrapIfWithDetails(err, "failed to list buckets", "table", table.name)
+			}
+
+			for _, row := range rows {
+				references = append(references, usage.Reference{
+					SecretID:     row.SecretID,
+					ResourceType: table.resourceType,
+					ResourceID:   fmt.Sprintf("%s/%d", table.name, row.ID),
+					ResourceName: row.Name,
+					Field:        field,
+				})
+			}
+		}
+	}
+
+	return references, nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+)
+
+func TestBucketSource(t *testing.T) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	statements := []string{
+		"CREATE TABLE amazon_buckets (id integer primary key, organization_id integer, name text, secret_ref text)",
+		"CREATE TABLE ark_backup_buckets (id integer primary key, organization_id integer, bucket_name text, secret_id text, deleted_at datetime)",
+		"INSERT INTO amazon_buckets (id, organization_id, name, secret_ref) VALUES (1, 1, 'bucket', 'secret'), (2, 2, 'other', 'secret'), (3, 1, 'nosecret', '')",
+		"INSERT INTO ark_backup_buckets (id, organization_id, bucket_name, secret_id, deleted_at) VALUES (1, 1, 'backups', 'secret', NULL), (2, 1, 'deleted', 'secret', '2020-01-01 00:00:00')",
+	}
+
+	for _, statement := range statements {
+		require.NoError(t, db.Exec(statement).Error)
+	}
+
+	references, err := NewBucketSource(db).ListReferences(context.Background(), 1)
+	require.NoError(t, err)
+
+	expected := []usage.Reference{
+		{
+			SecretID:     "secret",
+			ResourceType: usage.ResourceTypeBucket,
+			ResourceID:   "amazon_buckets/1",
+			ResourceName: "bucket",
+			Field:        "secretId",
+		},
+		{
+			SecretID:     "secret",
+			ResourceType: usage.ResourceTypeBackupBucket,
+			ResourceID:   "ark_backup_buckets/1",
+			ResourceName: "backups",
+			Field:        "secretId",
+		},
+	}
+
+	assert.Equal(t, expected, references)
+}
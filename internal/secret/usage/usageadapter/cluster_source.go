@@ -0,0 +1,80 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+	"github.com/banzaicloud/pipeline/src/model"
+)
+
+// ClusterFinder lists the clusters of an organization.
+type ClusterFinder interface {
+	FindByOrganization(organizationID uint) ([]*model.ClusterModel, error)
+}
+
+// ClusterSource lists the secrets referenced by clusters.
+type ClusterSource struct {
+	clusters ClusterFinder
+}
+
+// NewClusterSource returns a new ClusterSource.
+func NewClusterSource(clusters ClusterFinder) ClusterSource {
+	return ClusterSource{
+		clusters: clusters,
+	}
+}
+
+// ListReferences implements the usage.Source interface.
+func (s ClusterSource) ListReferences(_ context.Context, organizationID uint) ([]usage.Reference, error) {
+	clusters, err := s.clusters.FindByOrganization(organizationID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var references []usage.Reference
+
+	for _, cluster := range clusters {
+		fields := []struct {
+			name     string
+			secretID string
+		}{
+			{name: "secretId", secretID: cluster.SecretId},
+			{name: "configSecretId", secretID: cluster.ConfigSecretId},
+			{name: "sshSecretId", secretID: cluster.SshSecretId},
+		}
+
+		for _, field := range fields {
+			if field.secretID == "" {
+				continue
+			}
+
+			references = append(references, usage.Reference{
+				SecretID:     field.secretID,
+				ResourceType: usage.ResourceTypeCluster,
+				ResourceID:   fmt.Sprint(cluster.ID),
+				ResourceName: cluster.Name,
+				ClusterID:    cluster.ID,
+				Field:        field.name,
+			})
+		}
+	}
+
+	return references, nil
+}
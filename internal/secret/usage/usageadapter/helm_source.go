@@ -0,0 +1,75 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/helm"
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+)
+
+// HelmRepositoryLister lists the Helm repositories of an organization.
+type HelmRepositoryLister interface {
+	List(ctx context.Context, organizationID uint) ([]helm.Repository, error)
+}
+
+// HelmRepositorySource lists the secrets referenced by Helm repositories.
+type HelmRepositorySource struct {
+	repositories HelmRepositoryLister
+}
+
+// NewHelmRepositorySource returns a new HelmRepositorySource.
+func NewHelmRepositorySource(repositories HelmRepositoryLister) HelmRepositorySource {
+	return HelmRepositorySource{
+		repositories: repositories,
+	}
+}
+
+// ListReferences implements the usage.Source interface.
+func (s HelmRepositorySource) ListReferences(ctx context.Context, organizationID uint) ([]usage.Reference, error) {
+	repositories, err := s.repositories.List(ctx, organizationID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list helm repositories")
+	}
+
+	var references []usage.Reference
+
+	for _, repository := range repositories {
+		if repository.PasswordSecretID != "" {
+			references = append(references, usage.Reference{
+				SecretID:     repository.PasswordSecretID,
+				ResourceType: usage.ResourceTypeHelmRepository,
+				ResourceID:   repository.Name,
+				ResourceName: repository.Name,
+				Field:        "passwordSecretId",
+			})
+		}
+
+		if repository.TlsSecretID != "" {
+			references = append(references, usage.Reference{
+				SecretID:     repository.TlsSecretID,
+				ResourceType: usage.ResourceTypeHelmRepository,
+				ResourceID:   repository.Name,
+				ResourceName: repository.Name,
+				Field:        "tlsSecretId",
+			})
+		}
+	}
+
+	return references, nil
+}
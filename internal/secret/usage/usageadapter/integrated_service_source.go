@@ -0,0 +1,127 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
+)
+
+// IntegratedServiceLister lists the integrated services of a cluster.
+type IntegratedServiceLister interface {
+	GetIntegratedServices(ctx context.Context, clusterID uint) ([]integratedservices.IntegratedService, error)
+}
+
+// IntegratedServiceSource lists the secrets referenced by integrated service specs.
+//
+// Integrated services reference secrets using "secretId" keys at arbitrary depth of their specs
+// (eg. the DNS provider secret or the logging output secrets).
+type IntegratedServiceSource struct {
+	clusters           ClusterFinder
+	integratedServices IntegratedServiceLister
+}
+
+// NewIntegratedServiceSource returns a new IntegratedServiceSource.
+func NewIntegratedServiceSource(clusters ClusterFinder, integratedServices IntegratedServiceLister) IntegratedServiceSource {
+	return IntegratedServiceSource{
+		clusters:           clusters,
+		integratedServices: integratedServices,
+	}
+}
+
+// ListReferences implements the usage.Source interface.
+func (s IntegratedServiceSource) ListReferences(ctx context.Context, organizationID uint) ([]usage.Reference, error) {
+	clusters, err := s.clusters.FindByOrganization(organizationID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	var references []usage.Reference
+
+	for _, cluster := range clusters {
+		services, err := s.integratedServices.GetIntegratedServices(ctx, cluster.ID)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to list integrated services", "clusterId", cluster.ID)
+		}
+
+		for _, service := range services {
+			for field, secretID := range findSecretIDs(service.Spec, "") {
+				references = append(references, usage.Reference{
+					SecretID:     secretID,
+					ResourceType: usage.ResourceTypeIntegratedService,
+					ResourceID:   service.Name,
+					ResourceName: service.Name,
+					ClusterID:    cluster.ID,
+					Field:        field,
+				})
+			}
+		}
+	}
+
+	return references, nil
+}
+
+// findSecretIDs walks a spec and collects the secret IDs keyed by their paths.
+func findSecretIDs(value interface{}, path string) map[string]string {
+	secretIDs := make(map[string]string)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+
+			if s, ok := v[key].(string); ok {
+				if isSecretIDKey(key) && s != "" {
+					secretIDs[fieldPath] = s
+				}
+
+				continue
+			}
+
+			for p, secretID := range findSecretIDs(v[key], fieldPath) {
+				secretIDs[p] = secretID
+			}
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			for p, secretID := range findSecretIDs(item, path+"["+strconv.Itoa(i)+"]") {
+				secretIDs[p] = secretID
+			}
+		}
+	}
+
+	return secretIDs
+}
+
+func isSecretIDKey(key string) bool {
+	return key == "secretId" || strings.HasSuffix(key, "SecretId")
+}
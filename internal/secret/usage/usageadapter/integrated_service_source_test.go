@@ -0,0 +1,48 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usageadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSecretIDs(t *testing.T) {
+	spec := map[string]interface{}{
+		"externalDns": map[string]interface{}{
+			"provider": map[string]interface{}{
+				"name":     "route53",
+				"secretId": "dns-secret",
+			},
+		},
+		"outputs": []interface{}{
+			map[string]interface{}{"secretId": "output-secret"},
+			map[string]interface{}{"secretId": ""},
+		},
+		"alertmanager": map[string]interface{}{
+			"smtpSecretId": "smtp-secret",
+		},
+		"enabled": true,
+	}
+
+	expected := map[string]string{
+		"externalDns.provider.secretId": "dns-secret",
+		"outputs[0].secretId":           "output-secret",
+		"alertmanager.smtpSecretId":     "smtp-secret",
+	}
+
+	assert.Equal(t, expected, findSecretIDs(spec, ""))
+}
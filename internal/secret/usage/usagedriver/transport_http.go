@@ -0,0 +1,67 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usagedriver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+)
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("/usage").Handler(kithttp.NewServer(
+		endpoints.GetSecretUsage,
+		decodeGetSecretUsageHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetSecretUsageHTTPResponse, errorEncoder),
+		options...,
+	))
+}
+
+func decodeGetSecretUsageHTTPRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+
+	orgIDStr, ok := vars["orgId"]
+	if !ok || orgIDStr == "" {
+		return nil, errors.NewWithDetails("missing parameter from the URL", "param", "orgId")
+	}
+
+	orgID, err := strconv.ParseUint(orgIDStr, 0, 0)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid organization ID format")
+	}
+
+	secretID, ok := vars["secretId"]
+	if !ok || secretID == "" {
+		return nil, errors.NewWithDetails("missing parameter from the URL", "param", "secretId")
+	}
+
+	return GetSecretUsageRequest{OrganizationID: uint(orgID), SecretID: secretID}, nil
+}
+
+func encodeGetSecretUsageHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetSecretUsageResponse)
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.References)
+}
@@ -17,6 +17,7 @@ package anchore
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"emperror.dev/errors"
 	securityV1Alpha "github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
@@ -40,23 +41,37 @@ type SecurityResourceService interface {
 type WhitelistService interface {
 	GetWhitelists(ctx context.Context, cluster Cluster) ([]securityV1Alpha.WhiteListItem, error)
 	CreateWhitelist(ctx context.Context, cluster Cluster, whitelistItem security.ReleaseWhiteListItem) (interface{}, error)
+	ApproveWhitelist(ctx context.Context, cluster Cluster, whitelistItemName string, approver string) error
 	DeleteWhitelist(ctx context.Context, cluster Cluster, whitelistItemName string) error
 }
 
+// ErrWhitelistSelfApproval is returned when the owner of a whitelist item tries to approve it.
+const ErrWhitelistSelfApproval = errors.Sentinel("a whitelist item can not be approved by its owner")
+
 type ScanlogService interface {
 	ListScanLogs(ctx context.Context, cluster Cluster) (interface{}, error)
 	GetScanLogs(ctx context.Context, cluster Cluster, releaseName string) (interface{}, error)
 }
 
+// ClusterClientFactory creates clients for accessing the security resources of a cluster.
+type ClusterClientFactory interface {
+	// FromCluster creates a client for a cluster.
+	FromCluster(ctx context.Context, cluster Cluster) (client.Client, error)
+}
+
 type securityResourceService struct {
-	logger common.Logger
+	clientFactory ClusterClientFactory
+	auditLog      WhitelistAuditLog
+	logger        common.Logger
 }
 
-func NewSecurityResourceService(logger common.Logger) SecurityResourceService {
+func NewSecurityResourceService(clientFactory ClusterClientFactory, auditLog WhitelistAuditLog, logger common.Logger) SecurityResourceService {
 	_ = scheme.AddToScheme(scheme.Scheme)
 
 	return securityResourceService{
-		logger: logger,
+		clientFactory: clientFactory,
+		auditLog:      auditLog,
+		logger:        logger,
 	}
 }
 
@@ -94,10 +109,76 @@ func (s securityResourceService) CreateWhitelist(ctx context.Context, cluster Cl
 		return nil, errors.WrapIf(err, "failed to create whitelist item")
 	}
 
+	actor := whitelistActor(ctx)
+	if actor == "" {
+		actor = whitelistItem.Owner
+	}
+
+	s.recordAuditEvent(ctx, WhitelistAuditEvent{
+		ClusterID: cluster.GetID(),
+		ItemName:  whitelistItem.Name,
+		Action:    WhitelistActionCreated,
+		Actor:     actor,
+		Owner:     whitelistItem.Owner,
+		Reason:    whitelistItem.Reason,
+		ExpiresAt: whitelistItem.ExpiresAt,
+	})
+
 	s.logger.Info("whitelist item successfully created", logCtx)
 	return wlItem, nil
 }
 
+// ApproveWhitelist records the approval of a whitelist item by a user other than its owner.
+func (s securityResourceService) ApproveWhitelist(ctx context.Context, cluster Cluster, whitelistItemName string, approver string) error {
+	logCtx := map[string]interface{}{"clusterID": cluster.GetID(), "whiteListItem": whitelistItemName}
+	s.logger.Info("approving whitelist item ...", logCtx)
+
+	if approver == "" {
+		return errors.New("approver must be specified")
+	}
+
+	cli, err := s.getClusterClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	whiteListItem, err := s.getWhitelist(ctx, cluster, whitelistItemName)
+	if err != nil {
+		return err
+	}
+
+	if whiteListItem.Spec.Creator == approver {
+		return errors.WithStack(ErrWhitelistSelfApproval)
+	}
+
+	if whiteListItem.Annotations == nil {
+		whiteListItem.Annotations = make(map[string]string)
+	}
+	whiteListItem.Annotations[WhitelistApproverAnnotation] = approver
+
+	if err := cli.Update(ctx, whiteListItem); err != nil {
+		return errors.WrapIf(err, "failed to approve whitelist item")
+	}
+
+	event := WhitelistAuditEvent{
+		ClusterID: cluster.GetID(),
+		ItemName:  whitelistItemName,
+		Action:    WhitelistActionApproved,
+		Actor:     approver,
+		Owner:     whiteListItem.Spec.Creator,
+		Approver:  approver,
+		Reason:    whiteListItem.Spec.Reason,
+	}
+	if expiresAt, ok := WhitelistItemExpiresAt(*whiteListItem); ok {
+		event.ExpiresAt = &expiresAt
+	}
+
+	s.recordAuditEvent(ctx, event)
+
+	s.logger.Info("whitelist item successfully approved", logCtx)
+	return nil
+}
+
 func (s securityResourceService) ListScanLogs(ctx context.Context, cluster Cluster) (interface{}, error) {
 	logCtx := map[string]interface{}{"clusterID": cluster.GetID()}
 	s.logger.Info("listing scan logs ...", logCtx)
@@ -173,6 +254,27 @@ func (s securityResourceService) DeleteWhitelist(ctx context.Context, cluster Cl
 		return errors.WrapIf(err, "failed to delete whitelist")
 	}
 
+	action := WhitelistActionDeleted
+	expiresAt, expires := WhitelistItemExpiresAt(*whiteListItem)
+	if expires && !expiresAt.After(time.Now()) {
+		action = WhitelistActionExpired
+	}
+
+	event := WhitelistAuditEvent{
+		ClusterID: cluster.GetID(),
+		ItemName:  whitelistItemName,
+		Action:    action,
+		Actor:     whitelistActor(ctx),
+		Owner:     whiteListItem.Spec.Creator,
+		Approver:  WhitelistItemApprover(*whiteListItem),
+		Reason:    whiteListItem.Spec.Reason,
+	}
+	if expires {
+		event.ExpiresAt = &expiresAt
+	}
+
+	s.recordAuditEvent(ctx, event)
+
 	s.logger.Info("whitelist item successfully deleted", logCtx)
 	return nil
 }
@@ -197,7 +299,38 @@ func (s securityResourceService) getWhitelist(ctx context.Context, cluster Clust
 	return whiteListItem, nil
 }
 
+// recordAuditEvent records a whitelist audit event, failures are logged as the cluster is already changed
+func (s securityResourceService) recordAuditEvent(ctx context.Context, event WhitelistAuditEvent) {
+	if s.auditLog == nil {
+		return
+	}
+
+	event.CreatedAt = time.Now()
+
+	if err := s.auditLog.Record(ctx, event); err != nil {
+		s.logger.Warn("failed to record whitelist audit event", map[string]interface{}{
+			"clusterID":     event.ClusterID,
+			"whiteListItem": event.ItemName,
+			"action":        event.Action,
+			"error":         err.Error(),
+		})
+	}
+}
+
 func (s securityResourceService) getClusterClient(ctx context.Context, cluster Cluster) (client.Client, error) {
+	return s.clientFactory.FromCluster(ctx, cluster)
+}
+
+// KubeConfigClientFactory creates clients from the kubeconfig of a cluster.
+type KubeConfigClientFactory struct{}
+
+// NewKubeConfigClientFactory returns a new KubeConfigClientFactory.
+func NewKubeConfigClientFactory() KubeConfigClientFactory {
+	return KubeConfigClientFactory{}
+}
+
+// FromCluster creates a client for a cluster.
+func (KubeConfigClientFactory) FromCluster(_ context.Context, cluster Cluster) (client.Client, error) {
 	kubeConfig, err := cluster.GetK8sConfig()
 	if err != nil {
 		return nil, errors.WrapIf(err, "failed to get k8s config for the cluster")
@@ -223,7 +356,8 @@ func (s securityResourceService) assembleWhiteListItem(whitelistItem security.Re
 			APIVersion: fmt.Sprintf("%v/%v", securityV1Alpha.GroupName, securityV1Alpha.GroupVersion),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: whitelistItem.Name,
+			Name:        whitelistItem.Name,
+			Annotations: whitelistItemAnnotations(whitelistItem),
 		},
 		Spec: securityV1Alpha.WhiteListSpec{
 			Creator: whitelistItem.Owner,
@@ -233,6 +367,26 @@ func (s securityResourceService) assembleWhiteListItem(whitelistItem security.Re
 	}
 }
 
+// whitelistItemAnnotations returns the annotations of a new whitelist item.
+// New items are never approved: the approver is only set by ApproveWhitelist.
+func whitelistItemAnnotations(whitelistItem security.ReleaseWhiteListItem) map[string]string {
+	annotations := make(map[string]string)
+
+	if whitelistItem.ExpiresAt != nil {
+		annotations[WhitelistExpiresAtAnnotation] = whitelistItem.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	if whitelistItem.NotificationSecretID != "" {
+		annotations[WhitelistNotificationSecretIDAnnotation] = whitelistItem.NotificationSecretID
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	return annotations
+}
+
 // Cluster defines operations that can be performed on a k8s cluster
 type Cluster interface {
 	GetK8sConfig() ([]byte, error)
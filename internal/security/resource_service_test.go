@@ -0,0 +1,176 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anchore
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	securityV1Alpha "github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/pkg/security"
+)
+
+type fakeCluster struct {
+	id uint
+}
+
+func (c fakeCluster) GetK8sConfig() ([]byte, error) {
+	return nil, nil
+}
+
+func (c fakeCluster) GetID() uint {
+	return c.id
+}
+
+type fakeClientFactory struct {
+	client client.Client
+}
+
+func (f fakeClientFactory) FromCluster(_ context.Context, _ Cluster) (client.Client, error) {
+	return f.client, nil
+}
+
+type inmemoryWhitelistAuditLog struct {
+	events []WhitelistAuditEvent
+}
+
+func (l *inmemoryWhitelistAuditLog) Record(_ context.Context, event WhitelistAuditEvent) error {
+	l.events = append(l.events, event)
+
+	return nil
+}
+
+func (l *inmemoryWhitelistAuditLog) List(_ context.Context, _ uint) ([]WhitelistAuditEvent, error) {
+	return l.events, nil
+}
+
+func newFakeSecurityClient(t *testing.T, objects ...runtime.Object) client.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, securityV1Alpha.AddToScheme(s))
+
+	return fake.NewFakeClientWithScheme(s, objects...)
+}
+
+// The image validator webhook records its decisions in Audit resources regardless of the scanner backend,
+// so scan logs of a Trivy backed webhook are served the same way as the Anchore ones.
+func TestSecurityResourceService_ScanLogs(t *testing.T) {
+	audit := &securityV1Alpha.Audit{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-release"},
+		Spec: securityV1Alpha.AuditSpec{
+			ReleaseName: "my-release",
+			Resource:    "Pod",
+			Action:      "reject",
+			Result:      []string{"CVE-2020-1234: CRITICAL severity vulnerability found by trivy"},
+			Images: []securityV1Alpha.AuditImage{
+				{ImageName: "nginx", ImageTag: "1.17"},
+			},
+		},
+	}
+
+	service := NewSecurityResourceService(fakeClientFactory{client: newFakeSecurityClient(t, audit)}, nil, common.NoopLogger{})
+
+	scanLogs, err := service.ListScanLogs(context.Background(), fakeCluster{id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []securityV1Alpha.AuditSpec{audit.Spec}, scanLogs)
+
+	scanLog, err := service.GetScanLogs(context.Background(), fakeCluster{id: 1}, "my-release")
+	require.NoError(t, err)
+	assert.Equal(t, &audit.Spec, scanLog)
+}
+
+func TestSecurityResourceService_Whitelists(t *testing.T) {
+	auditLog := &inmemoryWhitelistAuditLog{}
+	service := NewSecurityResourceService(fakeClientFactory{client: newFakeSecurityClient(t)}, auditLog, common.NoopLogger{})
+
+	ctx := WithWhitelistActor(context.Background(), "john")
+	cluster := fakeCluster{id: 1}
+
+	_, err := service.CreateWhitelist(ctx, cluster, security.ReleaseWhiteListItem{
+		Name:   "my-release",
+		Owner:  "john",
+		Reason: "false positive",
+	})
+	require.NoError(t, err)
+
+	whitelists, err := service.GetWhitelists(ctx, cluster)
+	require.NoError(t, err)
+	require.Len(t, whitelists, 1)
+	assert.Equal(t, "my-release", whitelists[0].Name)
+	assert.Equal(t, "john", whitelists[0].Spec.Creator)
+	assert.Equal(t, "false positive", whitelists[0].Spec.Reason)
+
+	require.NoError(t, service.DeleteWhitelist(ctx, cluster, "my-release"))
+
+	whitelists, err = service.GetWhitelists(ctx, cluster)
+	require.NoError(t, err)
+	assert.Empty(t, whitelists)
+
+	require.Len(t, auditLog.events, 2)
+	assert.Equal(t, WhitelistActionCreated, auditLog.events[0].Action)
+	assert.Equal(t, WhitelistActionDeleted, auditLog.events[1].Action)
+	assert.Equal(t, "john", auditLog.events[1].Actor)
+}
+
+func TestSecurityResourceService_ApproveWhitelist(t *testing.T) {
+	auditLog := &inmemoryWhitelistAuditLog{}
+	service := NewSecurityResourceService(fakeClientFactory{client: newFakeSecurityClient(t)}, auditLog, common.NoopLogger{})
+
+	ctx := WithWhitelistActor(context.Background(), "john")
+	cluster := fakeCluster{id: 1}
+
+	// new items are never approved
+	_, err := service.CreateWhitelist(ctx, cluster, security.ReleaseWhiteListItem{
+		Name:     "my-release",
+		Owner:    "john",
+		Reason:   "false positive",
+		Approver: "jane",
+	})
+	require.NoError(t, err)
+
+	whitelists, err := service.GetWhitelists(ctx, cluster)
+	require.NoError(t, err)
+	require.Len(t, whitelists, 1)
+	assert.Empty(t, WhitelistItemApprover(whitelists[0]))
+
+	err = service.ApproveWhitelist(ctx, cluster, "my-release", "john")
+	assert.True(t, errors.Is(err, ErrWhitelistSelfApproval))
+
+	err = service.ApproveWhitelist(ctx, cluster, "other-release", "jane")
+	assert.Error(t, err)
+
+	require.NoError(t, service.ApproveWhitelist(ctx, cluster, "my-release", "jane"))
+
+	whitelists, err = service.GetWhitelists(ctx, cluster)
+	require.NoError(t, err)
+	require.Len(t, whitelists, 1)
+	assert.Equal(t, "jane", WhitelistItemApprover(whitelists[0]))
+	assert.Equal(t, "john", whitelists[0].Spec.Creator)
+
+	require.Len(t, auditLog.events, 2)
+	assert.Empty(t, auditLog.events[0].Approver)
+	assert.Equal(t, WhitelistActionApproved, auditLog.events[1].Action)
+	assert.Equal(t, "jane", auditLog.events[1].Actor)
+	assert.Equal(t, "jane", auditLog.events[1].Approver)
+	assert.Equal(t, "john", auditLog.events[1].Owner)
+}
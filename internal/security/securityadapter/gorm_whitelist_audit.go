@@ -0,0 +1,103 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package securityadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	anchore "github.com/banzaicloud/pipeline/internal/security"
+)
+
+// whitelistAuditEventModel describes the whitelist audit event model.
+type whitelistAuditEventModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	ClusterID uint `gorm:"index:idx_security_whitelist_audit_events_cluster"`
+	ItemName  string
+	Action    string
+	Actor     string
+	Owner     string
+	Approver  string
+	Reason    string `gorm:"type:text"`
+	ExpiresAt *time.Time
+}
+
+// TableName changes the default table name.
+func (whitelistAuditEventModel) TableName() string {
+	return "security_whitelist_audit_events"
+}
+
+// GormWhitelistAuditLog is a whitelist audit log backed by a relational database.
+type GormWhitelistAuditLog struct {
+	db *gorm.DB
+}
+
+// NewGormWhitelistAuditLog returns a new GormWhitelistAuditLog.
+func NewGormWhitelistAuditLog(db *gorm.DB) GormWhitelistAuditLog {
+	return GormWhitelistAuditLog{
+		db: db,
+	}
+}
+
+// Record implements the anchore.WhitelistAuditLog interface.
+func (l GormWhitelistAuditLog) Record(ctx context.Context, event anchore.WhitelistAuditEvent) error {
+	model := whitelistAuditEventModel{
+		CreatedAt: event.CreatedAt,
+		ClusterID: event.ClusterID,
+		ItemName:  event.ItemName,
+		Action:    event.Action,
+		Actor:     event.Actor,
+		Owner:     event.Owner,
+		Approver:  event.Approver,
+		Reason:    event.Reason,
+		ExpiresAt: event.ExpiresAt,
+	}
+
+	if err := l.db.Create(&model).Error; err != nil {
+		return errors.WrapIfWithDetails(err, "failed to record whitelist audit event", "clusterId", event.ClusterID, "name", event.ItemName)
+	}
+
+	return nil
+}
+
+// List implements the anchore.WhitelistAuditLog interface.
+func (l GormWhitelistAuditLog) List(ctx context.Context, clusterID uint) ([]anchore.WhitelistAuditEvent, error) {
+	var models []whitelistAuditEventModel
+
+	if err := l.db.Where(whitelistAuditEventModel{ClusterID: clusterID}).Order("created_at, id").Find(&models).Error; err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list whitelist audit events", "clusterId", clusterID)
+	}
+
+	events := make([]anchore.WhitelistAuditEvent, 0, len(models))
+	for _, model := range models {
+		events = append(events, anchore.WhitelistAuditEvent{
+			ClusterID: model.ClusterID,
+			ItemName:  model.ItemName,
+			Action:    model.Action,
+			Actor:     model.Actor,
+			Owner:     model.Owner,
+			Approver:  model.Approver,
+			Reason:    model.Reason,
+			ExpiresAt: model.ExpiresAt,
+			CreatedAt: model.CreatedAt,
+		})
+	}
+
+	return events, nil
+}
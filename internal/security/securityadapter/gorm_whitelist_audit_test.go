@@ -0,0 +1,88 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package securityadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	anchore "github.com/banzaicloud/pipeline/internal/security"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormWhitelistAuditLog(t *testing.T) {
+	ctx := context.Background()
+	auditLog := NewGormWhitelistAuditLog(setUpDatabase(t))
+
+	created := time.Date(2020, 5, 20, 10, 0, 0, 0, time.UTC)
+	expiresAt := created.AddDate(0, 0, 7)
+
+	events := []anchore.WhitelistAuditEvent{
+		{
+			ClusterID: 1,
+			ItemName:  "release",
+			Action:    anchore.WhitelistActionCreated,
+			Actor:     "john",
+			Owner:     "john",
+			Approver:  "jane",
+			Reason:    "false positive",
+			ExpiresAt: &expiresAt,
+			CreatedAt: created,
+		},
+		{
+			ClusterID: 2,
+			ItemName:  "other",
+			Action:    anchore.WhitelistActionCreated,
+			CreatedAt: created,
+		},
+		{
+			ClusterID: 1,
+			ItemName:  "release",
+			Action:    anchore.WhitelistActionExpired,
+			Actor:     "pipeline",
+			CreatedAt: expiresAt,
+		},
+	}
+
+	for _, event := range events {
+		require.NoError(t, auditLog.Record(ctx, event))
+	}
+
+	listed, err := auditLog.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	assert.Equal(t, anchore.WhitelistActionCreated, listed[0].Action)
+	assert.Equal(t, "jane", listed[0].Approver)
+	require.NotNil(t, listed[0].ExpiresAt)
+	assert.True(t, listed[0].ExpiresAt.Equal(expiresAt))
+	assert.Equal(t, anchore.WhitelistActionExpired, listed[1].Action)
+}
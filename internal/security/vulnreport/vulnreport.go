@@ -0,0 +1,554 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreport
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/patrickmn/go-cache"
+)
+
+// Vulnerability severities in increasing order.
+const (
+	SeverityUnknown    = "Unknown"
+	SeverityNegligible = "Negligible"
+	SeverityLow        = "Low"
+	SeverityMedium     = "Medium"
+	SeverityHigh       = "High"
+	SeverityCritical   = "Critical"
+)
+
+// Image scan states.
+const (
+	ScanStatusScanned     = "scanned"
+	ScanStatusUnavailable = "unavailable"
+)
+
+var severities = []string{
+	SeverityUnknown,
+	SeverityNegligible,
+	SeverityLow,
+	SeverityMedium,
+	SeverityHigh,
+	SeverityCritical,
+}
+
+// NormalizeSeverity returns the canonical form of a severity reported by a scanner (eg. Trivy reports them in upper case).
+func NormalizeSeverity(severity string) string {
+	if s, ok := lookupSeverity(severity); ok {
+		return s
+	}
+
+	return SeverityUnknown
+}
+
+func lookupSeverity(severity string) (string, bool) {
+	for _, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return s, true
+		}
+	}
+
+	return "", false
+}
+
+func severityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// Cluster is a cluster with security scan enabled.
+type Cluster struct {
+	ID   uint
+	Name string
+
+	// Scanner is the type of the vulnerability scanner configured for the cluster.
+	Scanner string
+}
+
+// Workload describes where an image is running.
+type Workload struct {
+	ClusterID   uint   `json:"clusterId"`
+	ClusterName string `json:"clusterName"`
+	Namespace   string `json:"namespace"`
+	Release     string `json:"release,omitempty"`
+}
+
+// RunningImage is an image running in a cluster.
+type RunningImage struct {
+	Name      string
+	Tag       string
+	Digest    string
+	Workloads []Workload
+}
+
+// Vulnerability is a known vulnerability of an image.
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Package  string `json:"package"`
+	Fix      string `json:"fix,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Fixable returns true if a fix is available for the vulnerability.
+func (v Vulnerability) Fixable() bool {
+	return v.Fix != "" && !strings.EqualFold(v.Fix, "none")
+}
+
+// ImageReport is the vulnerability report of a single image.
+type ImageReport struct {
+	Name            string          `json:"name"`
+	Tag             string          `json:"tag"`
+	Digest          string          `json:"digest"`
+	ScanStatus      string          `json:"scanStatus"`
+	HighestSeverity string          `json:"highestSeverity,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+	Workloads       []Workload      `json:"workloads"`
+}
+
+// TrendPoint summarizes the vulnerabilities of an organization at a given day.
+type TrendPoint struct {
+	Date   time.Time      `json:"date"`
+	Images int            `json:"images"`
+	Counts map[string]int `json:"counts"`
+}
+
+// ClusterError is a problem encountered while collecting the report of a cluster.
+type ClusterError struct {
+	ClusterID uint   `json:"clusterId"`
+	Message   string `json:"message"`
+}
+
+// Report is the vulnerability report of an organization.
+type Report struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Summary     map[string]int `json:"summary"`
+	Images      []ImageReport  `json:"images"`
+	Trend       []TrendPoint   `json:"trend"`
+	Errors      []ClusterError `json:"errors,omitempty"`
+}
+
+// ReportOptions filters the report.
+type ReportOptions struct {
+	// MinSeverity filters out vulnerabilities below the given severity.
+	MinSeverity string
+
+	// CVE filters for a single vulnerability ID.
+	CVE string
+
+	// Fixable filters for vulnerabilities with (or without) an available fix.
+	Fixable *bool
+
+	// Refresh bypasses the report cache.
+	Refresh bool
+}
+
+// Validate validates the report options.
+func (o ReportOptions) Validate() error {
+	if _, ok := lookupSeverity(o.MinSeverity); o.MinSeverity != "" && !ok {
+		return errors.WithStack(NewValidationError("invalid report options", []string{"unknown severity: " + o.MinSeverity}))
+	}
+
+	return nil
+}
+
+// Config contains the vulnerability report configuration.
+type Config struct {
+	// CacheTTL is the duration reports are cached for.
+	CacheTTL time.Duration
+
+	// TrendDays is the number of days the trend is reported for.
+	TrendDays int
+
+	// ScanConcurrency is the maximum number of images looked up in the scanner of a cluster at the same time.
+	ScanConcurrency int
+}
+
+// +kit:endpoint:errorStrategy=service
+// +testify:mock
+
+// Service provides organization wide vulnerability reports.
+type Service interface {
+	// GetReport returns the vulnerability report of an organization.
+	GetReport(ctx context.Context, organizationID uint, options ReportOptions) (report Report, err error)
+}
+
+// +testify:mock:testOnly=true
+
+// ClusterLister lists clusters with security scan enabled.
+type ClusterLister interface {
+	// ListClusters lists the clusters of an organization with security scan enabled.
+	ListClusters(ctx context.Context, organizationID uint) ([]Cluster, error)
+}
+
+// +testify:mock:testOnly=true
+
+// ImageLister lists the images running in a cluster.
+type ImageLister interface {
+	// ListImages lists the images running in a cluster along with the workloads running them.
+	ListImages(ctx context.Context, cluster Cluster) ([]RunningImage, error)
+}
+
+// +testify:mock:testOnly=true
+
+// Scanner returns the known vulnerabilities of an image.
+type Scanner interface {
+	// GetVulnerabilities returns the vulnerabilities of an image found by the scanner of a cluster.
+	GetVulnerabilities(ctx context.Context, clusterID uint, imageDigest string) ([]Vulnerability, error)
+}
+
+// Scanners are the vulnerability sources keyed by the scanner type of the clusters.
+type Scanners map[string]Scanner
+
+// +testify:mock:testOnly=true
+
+// TrendStore persists daily report summaries.
+type TrendStore interface {
+	// Save stores the summary of a day, replacing the previous one of the same day.
+	Save(ctx context.Context, organizationID uint, point TrendPoint) error
+
+	// List returns the summaries recorded since the given time ordered by date.
+	List(ctx context.Context, organizationID uint, since time.Time) ([]TrendPoint, error)
+}
+
+// NewService returns a new Service.
+func NewService(config Config, clusters ClusterLister, images ImageLister, scanners Scanners, trends TrendStore) Service {
+	if config.ScanConcurrency < 1 {
+		config.ScanConcurrency = 1
+	}
+
+	return service{
+		config:   config,
+		clusters: clusters,
+		images:   images,
+		scanners: scanners,
+		trends:   trends,
+		cache:    cache.New(config.CacheTTL, 2*config.CacheTTL),
+		now:      time.Now,
+	}
+}
+
+type service struct {
+	config   Config
+	clusters ClusterLister
+	images   ImageLister
+	scanners Scanners
+	trends   TrendStore
+	cache    *cache.Cache
+	now      func() time.Time
+}
+
+func (s service) GetReport(ctx context.Context, organizationID uint, options ReportOptions) (Report, error) {
+	if err := options.Validate(); err != nil {
+		return Report{}, err
+	}
+
+	cacheKey := strconv.FormatUint(uint64(organizationID), 10)
+
+	var report Report
+	if cached, ok := s.cache.Get(cacheKey); ok && !options.Refresh {
+		report = cached.(Report)
+	} else {
+		var err error
+
+		report, err = s.buildReport(ctx, organizationID)
+		if err != nil {
+			return Report{}, err
+		}
+
+		s.cache.SetDefault(cacheKey, report)
+	}
+
+	return filterReport(report, options), nil
+}
+
+func (s service) buildReport(ctx context.Context, organizationID uint) (Report, error) {
+	clusters, err := s.clusters.ListClusters(ctx, organizationID)
+	if err != nil {
+		return Report{}, errors.WrapIfWithDetails(err, "failed to list clusters", "organizationId", organizationID)
+	}
+
+	report := Report{
+		GeneratedAt: s.now(),
+		Images:      []ImageReport{},
+	}
+
+	imagesByDigest := make(map[string]*ImageReport)
+	var digests []string
+
+	for _, cluster := range clusters {
+		images, err := s.images.ListImages(ctx, cluster)
+		if err != nil {
+			report.Errors = append(report.Errors, ClusterError{ClusterID: cluster.ID, Message: err.Error()})
+
+			continue
+		}
+
+		var unscanned []*ImageReport
+		queued := make(map[string]bool)
+
+		for _, image := range images {
+			imageReport, ok := imagesByDigest[image.Digest]
+			if !ok {
+				imageReport = &ImageReport{
+					Name:       image.Name,
+					Tag:        image.Tag,
+					Digest:     image.Digest,
+					ScanStatus: ScanStatusUnavailable,
+				}
+				imagesByDigest[image.Digest] = imageReport
+				digests = append(digests, image.Digest)
+			}
+
+			imageReport.Workloads = append(imageReport.Workloads, image.Workloads...)
+
+			// the same image may be analyzed by the scanner of any cluster running it
+			if imageReport.ScanStatus != ScanStatusScanned && !queued[image.Digest] {
+				unscanned = append(unscanned, imageReport)
+				queued[image.Digest] = true
+			}
+		}
+
+		if len(unscanned) == 0 {
+			continue
+		}
+
+		scanner, ok := s.scanners[cluster.Scanner]
+		if !ok {
+			report.Errors = append(report.Errors, ClusterError{
+				ClusterID: cluster.ID,
+				Message:   fmt.Sprintf("vulnerabilities cannot be retrieved from the %q scanner", cluster.Scanner),
+			})
+
+			continue
+		}
+
+		report.Errors = append(report.Errors, s.scanImages(ctx, scanner, cluster, unscanned)...)
+	}
+
+	sort.Strings(digests)
+
+	for _, digest := range digests {
+		report.Images = append(report.Images, *imagesByDigest[digest])
+	}
+
+	today := truncateToDay(report.GeneratedAt)
+	point := summarize(report.Images)
+	point.Date = today
+
+	if err := s.trends.Save(ctx, organizationID, point); err != nil {
+		return Report{}, errors.WrapIfWithDetails(err, "failed to save report summary", "organizationId", organizationID)
+	}
+
+	trend, err := s.trends.List(ctx, organizationID, today.AddDate(0, 0, -s.config.TrendDays))
+	if err != nil {
+		return Report{}, errors.WrapIfWithDetails(err, "failed to list report summaries", "organizationId", organizationID)
+	}
+
+	report.Trend = trend
+
+	return report, nil
+}
+
+// scanImages looks up the vulnerabilities of images in the scanner of a cluster concurrently.
+func (s service) scanImages(ctx context.Context, scanner Scanner, cluster Cluster, images []*ImageReport) []ClusterError {
+	var (
+		errs []ClusterError
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	sem := make(chan struct{}, s.config.ScanConcurrency)
+
+	for _, image := range images {
+		image := image
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			vulnerabilities, err := scanner.GetVulnerabilities(ctx, cluster.ID, image.Digest)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, ClusterError{
+					ClusterID: cluster.ID,
+					Message:   fmt.Sprintf("failed to get vulnerabilities of image %s: %s", image.Digest, err.Error()),
+				})
+
+				return
+			}
+
+			image.ScanStatus = ScanStatusScanned
+			image.Vulnerabilities = normalizeVulnerabilities(vulnerabilities)
+		}()
+	}
+
+	wg.Wait()
+
+	// keep the errors in a stable order
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Message < errs[j].Message })
+
+	return errs
+}
+
+func normalizeVulnerabilities(vulnerabilities []Vulnerability) []Vulnerability {
+	result := make([]Vulnerability, 0, len(vulnerabilities))
+
+	for _, vulnerability := range vulnerabilities {
+		vulnerability.Severity = NormalizeSeverity(vulnerability.Severity)
+		result = append(result, vulnerability)
+	}
+
+	// highest severity findings first
+	sort.SliceStable(result, func(i, j int) bool {
+		if ri, rj := severityRank(result[i].Severity), severityRank(result[j].Severity); ri != rj {
+			return ri > rj
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+func filterReport(report Report, options ReportOptions) Report {
+	filtered := report
+	filtered.Images = make([]ImageReport, 0, len(report.Images))
+
+	filterActive := options.MinSeverity != "" || options.CVE != "" || options.Fixable != nil
+	minRank := severityRank(NormalizeSeverity(options.MinSeverity))
+
+	for _, image := range report.Images {
+		vulnerabilities := make([]Vulnerability, 0, len(image.Vulnerabilities))
+
+		for _, vulnerability := range image.Vulnerabilities {
+			if severityRank(vulnerability.Severity) < minRank {
+				continue
+			}
+
+			if options.CVE != "" && !strings.EqualFold(vulnerability.ID, options.CVE) {
+				continue
+			}
+
+			if options.Fixable != nil && vulnerability.Fixable() != *options.Fixable {
+				continue
+			}
+
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+
+		// images without matching findings are only listed without filters
+		if filterActive && len(vulnerabilities) == 0 {
+			continue
+		}
+
+		image.Vulnerabilities = vulnerabilities
+		image.HighestSeverity = ""
+		if len(vulnerabilities) > 0 {
+			image.HighestSeverity = vulnerabilities[0].Severity
+		}
+
+		filtered.Images = append(filtered.Images, image)
+	}
+
+	filtered.Summary = summarize(filtered.Images).Counts
+
+	return filtered
+}
+
+// summarize counts the vulnerabilities of the images by severity.
+func summarize(images []ImageReport) TrendPoint {
+	point := TrendPoint{
+		Images: len(images),
+		Counts: make(map[string]int, len(severities)),
+	}
+
+	for _, severity := range severities {
+		point.Counts[severity] = 0
+	}
+
+	for _, image := range images {
+		for _, vulnerability := range image.Vulnerabilities {
+			point.Counts[vulnerability.Severity]++
+		}
+	}
+
+	return point
+}
+
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ValidationError is returned when a report request is semantically invalid.
+type ValidationError struct {
+	message    string
+	violations []string
+}
+
+// NewValidationError returns a new ValidationError.
+func NewValidationError(message string, violations []string) ValidationError {
+	return ValidationError{
+		message:    message,
+		violations: violations,
+	}
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return "invalid report options"
+}
+
+// Violations returns details of the failed validation.
+func (e ValidationError) Violations() []string {
+	return e.violations[:]
+}
+
+// Validation tells a client that this error is related to a semantic validation of the request.
+// Can be used to translate the error to status codes for example.
+func (ValidationError) Validation() bool {
+	return true
+}
+
+// ServiceError tells the consumer whether this error is caused by invalid input supplied by the client.
+// Client errors are usually returned to the consumer without retrying the operation.
+func (ValidationError) ServiceError() bool {
+	return true
+}
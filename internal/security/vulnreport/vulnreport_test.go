@@ -0,0 +1,244 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreport
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2020, 5, 20, 13, 14, 15, 0, time.UTC)
+	today := time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	cluster1 := Cluster{ID: 1, Name: "cluster1", Scanner: "anchore"}
+	cluster2 := Cluster{ID: 2, Name: "cluster2", Scanner: "anchore"}
+
+	clusters := new(MockClusterLister)
+	clusters.On("ListClusters", ctx, uint(1)).Return([]Cluster{cluster1, cluster2}, nil).Once()
+
+	images := new(MockImageLister)
+	images.On("ListImages", ctx, cluster1).Return([]RunningImage{
+		{
+			Name:      "nginx",
+			Tag:       "1.17",
+			Digest:    "sha256:nginx",
+			Workloads: []Workload{{ClusterID: 1, ClusterName: "cluster1", Namespace: "default", Release: "web"}},
+		},
+		{
+			Name:      "redis",
+			Tag:       "5",
+			Digest:    "sha256:redis",
+			Workloads: []Workload{{ClusterID: 1, ClusterName: "cluster1", Namespace: "cache"}},
+		},
+	}, nil).Once()
+	images.On("ListImages", ctx, cluster2).Return([]RunningImage{
+		{
+			Name:      "nginx",
+			Tag:       "1.17",
+			Digest:    "sha256:nginx",
+			Workloads: []Workload{{ClusterID: 2, ClusterName: "cluster2", Namespace: "web", Release: "web"}},
+		},
+	}, nil).Once()
+
+	scanner := new(MockScanner)
+	scanner.On("GetVulnerabilities", ctx, uint(1), "sha256:nginx").Return([]Vulnerability{
+		{ID: "CVE-2020-0001", Severity: "LOW", Package: "libc"},
+		{ID: "CVE-2020-0002", Severity: "Critical", Package: "openssl", Fix: "1.1.1g"},
+		{ID: "CVE-2020-0003", Severity: "High", Package: "zlib", Fix: "None"},
+	}, nil).Once()
+	scanner.On("GetVulnerabilities", ctx, uint(1), "sha256:redis").Return(nil, errors.New("image not analyzed")).Once()
+
+	trends := new(MockTrendStore)
+	trends.On("Save", ctx, uint(1), mock.MatchedBy(func(point TrendPoint) bool {
+		return point.Date.Equal(today) && point.Images == 2 && point.Counts[SeverityCritical] == 1 && point.Counts[SeverityLow] == 1
+	})).Return(nil).Once()
+	trends.On("List", ctx, uint(1), today.AddDate(0, 0, -30)).Return([]TrendPoint{{Date: today, Images: 2}}, nil).Once()
+
+	svc := NewService(Config{CacheTTL: time.Minute, TrendDays: 30, ScanConcurrency: 2}, clusters, images, Scanners{"anchore": scanner}, trends).(service)
+	svc.now = func() time.Time { return now }
+
+	report, err := svc.GetReport(ctx, 1, ReportOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Images, 2)
+
+	nginx := report.Images[0]
+	assert.Equal(t, "sha256:nginx", nginx.Digest)
+	assert.Equal(t, ScanStatusScanned, nginx.ScanStatus)
+	assert.Equal(t, SeverityCritical, nginx.HighestSeverity)
+	assert.Equal(t, []string{"CVE-2020-0002", "CVE-2020-0003", "CVE-2020-0001"}, vulnerabilityIDs(nginx.Vulnerabilities))
+	assert.Equal(t, SeverityLow, nginx.Vulnerabilities[2].Severity)
+	assert.Len(t, nginx.Workloads, 2)
+
+	redis := report.Images[1]
+	assert.Equal(t, ScanStatusUnavailable, redis.ScanStatus)
+	assert.Empty(t, redis.Vulnerabilities)
+
+	assert.Equal(t, []ClusterError{{ClusterID: 1, Message: "failed to get vulnerabilities of image sha256:redis: image not analyzed"}}, report.Errors)
+
+	assert.Equal(t, 1, report.Summary[SeverityHigh])
+	assert.Equal(t, []TrendPoint{{Date: today, Images: 2}}, report.Trend)
+
+	// served from the cache
+	fixable := true
+	report, err = svc.GetReport(ctx, 1, ReportOptions{MinSeverity: "high", Fixable: &fixable})
+	require.NoError(t, err)
+
+	require.Len(t, report.Images, 1)
+	assert.Equal(t, []string{"CVE-2020-0002"}, vulnerabilityIDs(report.Images[0].Vulnerabilities))
+	assert.Equal(t, map[string]int{
+		SeverityUnknown:    0,
+		SeverityNegligible: 0,
+		SeverityLow:        0,
+		SeverityMedium:     0,
+		SeverityHigh:       0,
+		SeverityCritical:   1,
+	}, report.Summary)
+
+	report, err = svc.GetReport(ctx, 1, ReportOptions{CVE: "cve-2020-0003"})
+	require.NoError(t, err)
+
+	require.Len(t, report.Images, 1)
+	assert.Equal(t, SeverityHigh, report.Images[0].HighestSeverity)
+
+	clusters.AssertExpectations(t)
+	images.AssertExpectations(t)
+	scanner.AssertExpectations(t)
+	trends.AssertExpectations(t)
+}
+
+func TestService_GetReport_InvalidOptions(t *testing.T) {
+	svc := NewService(Config{}, nil, nil, nil, nil)
+
+	_, err := svc.GetReport(context.Background(), 1, ReportOptions{MinSeverity: "severe"})
+	require.Error(t, err)
+
+	var validationErr ValidationError
+	require.True(t, errors.As(err, &validationErr))
+}
+
+func TestService_GetReport_ClusterError(t *testing.T) {
+	ctx := context.Background()
+
+	clusters := new(MockClusterLister)
+	clusters.On("ListClusters", ctx, uint(1)).Return([]Cluster{{ID: 1}}, nil)
+
+	images := new(MockImageLister)
+	images.On("ListImages", ctx, Cluster{ID: 1}).Return(nil, errors.New("cluster unreachable"))
+
+	trends := new(MockTrendStore)
+	trends.On("Save", ctx, uint(1), mock.Anything).Return(nil)
+	trends.On("List", ctx, uint(1), mock.Anything).Return(nil, nil)
+
+	report, err := NewService(Config{}, clusters, images, nil, trends).GetReport(ctx, 1, ReportOptions{})
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Images)
+	assert.Equal(t, []ClusterError{{ClusterID: 1, Message: "cluster unreachable"}}, report.Errors)
+}
+
+func TestService_GetReport_UnsupportedScanner(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := Cluster{ID: 1, Scanner: "trivy"}
+
+	clusters := new(MockClusterLister)
+	clusters.On("ListClusters", ctx, uint(1)).Return([]Cluster{cluster}, nil)
+
+	images := new(MockImageLister)
+	images.On("ListImages", ctx, cluster).Return([]RunningImage{{Name: "nginx", Digest: "sha256:nginx"}}, nil)
+
+	trends := new(MockTrendStore)
+	trends.On("Save", ctx, uint(1), mock.Anything).Return(nil)
+	trends.On("List", ctx, uint(1), mock.Anything).Return(nil, nil)
+
+	report, err := NewService(Config{}, clusters, images, Scanners{"anchore": new(MockScanner)}, trends).GetReport(ctx, 1, ReportOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Images, 1)
+	assert.Equal(t, ScanStatusUnavailable, report.Images[0].ScanStatus)
+	assert.Equal(t, []ClusterError{{ClusterID: 1, Message: `vulnerabilities cannot be retrieved from the "trivy" scanner`}}, report.Errors)
+}
+
+func TestService_GetReport_ScanConcurrency(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := Cluster{ID: 1, Scanner: "anchore"}
+
+	var runningImages []RunningImage
+	for i := 0; i < 20; i++ {
+		runningImages = append(runningImages, RunningImage{Name: "image", Digest: fmt.Sprintf("sha256:%02d", i)})
+	}
+
+	clusters := new(MockClusterLister)
+	clusters.On("ListClusters", ctx, uint(1)).Return([]Cluster{cluster}, nil)
+
+	images := new(MockImageLister)
+	images.On("ListImages", ctx, cluster).Return(runningImages, nil)
+
+	var running, maxRunning int32
+
+	scanner := new(MockScanner)
+	scanner.On("GetVulnerabilities", ctx, uint(1), mock.Anything).
+		Run(func(mock.Arguments) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}).
+		Return(nil, nil)
+
+	trends := new(MockTrendStore)
+	trends.On("Save", ctx, uint(1), mock.Anything).Return(nil)
+	trends.On("List", ctx, uint(1), mock.Anything).Return(nil, nil)
+
+	report, err := NewService(Config{ScanConcurrency: 4}, clusters, images, Scanners{"anchore": scanner}, trends).GetReport(ctx, 1, ReportOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Images, 20)
+	for _, image := range report.Images {
+		assert.Equal(t, ScanStatusScanned, image.ScanStatus)
+	}
+
+	scanner.AssertNumberOfCalls(t, "GetVulnerabilities", 20)
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 4, "at most 4 images are scanned at the same time")
+	assert.True(t, atomic.LoadInt32(&maxRunning) > 1, "images are scanned concurrently")
+}
+
+func vulnerabilityIDs(vulnerabilities []Vulnerability) []string {
+	ids := make([]string, 0, len(vulnerabilities))
+	for _, vulnerability := range vulnerabilities {
+		ids = append(ids, vulnerability.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,87 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportadapter
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"emperror.dev/errors"
+
+	anchoreapi "github.com/banzaicloud/pipeline/.gen/anchore"
+	"github.com/banzaicloud/pipeline/internal/anchore"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+)
+
+// AnchoreScanner reads image vulnerabilities from the Anchore Engine the cluster is connected to.
+type AnchoreScanner struct {
+	configProvider anchore.ConfigProvider
+}
+
+// NewAnchoreScanner returns a new AnchoreScanner.
+func NewAnchoreScanner(configProvider anchore.ConfigProvider) AnchoreScanner {
+	return AnchoreScanner{
+		configProvider: configProvider,
+	}
+}
+
+// GetVulnerabilities implements the vulnreport.Scanner interface.
+func (s AnchoreScanner) GetVulnerabilities(ctx context.Context, clusterID uint, imageDigest string) ([]vulnreport.Vulnerability, error) {
+	config, err := s.configProvider.GetConfiguration(ctx, clusterID)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get anchore configuration", "clusterId", clusterID)
+	}
+
+	client := anchoreapi.NewAPIClient(&anchoreapi.Configuration{
+		BasePath:      config.Endpoint,
+		DefaultHeader: make(map[string]string),
+		UserAgent:     "Pipeline/go",
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: config.Insecure, // nolint: gosec
+				},
+			},
+		},
+	})
+
+	authCtx := context.WithValue(ctx, anchoreapi.ContextBasicAuth, anchoreapi.BasicAuth{
+		UserName: config.User,
+		Password: config.Password,
+	})
+
+	response, resp, err := client.ImagesApi.GetImageVulnerabilitiesByType(authCtx, imageDigest, "all", nil)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get image vulnerabilities", "clusterId", clusterID, "imageDigest", imageDigest)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewWithDetails("failed to get image vulnerabilities", "clusterId", clusterID, "imageDigest", imageDigest, "statusCode", resp.StatusCode)
+	}
+
+	vulnerabilities := make([]vulnreport.Vulnerability, 0, len(response.Vulnerabilities))
+	for _, v := range response.Vulnerabilities {
+		vulnerabilities = append(vulnerabilities, vulnreport.Vulnerability{
+			ID:       v.Vuln,
+			Severity: v.Severity,
+			Package:  v.Package,
+			Fix:      v.Fix,
+			URL:      v.Url,
+		})
+	}
+
+	return vulnerabilities, nil
+}
@@ -0,0 +1,110 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/jinzhu/gorm"
+
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+)
+
+// trendModel describes the daily vulnerability report summary model.
+type trendModel struct {
+	ID              uint      `gorm:"primary_key"`
+	OrganizationID  uint      `gorm:"unique_index:idx_vulnerability_report_trends_org_date"`
+	Date            time.Time `gorm:"unique_index:idx_vulnerability_report_trends_org_date"`
+	Images          int
+	UnknownCount    int
+	NegligibleCount int
+	LowCount        int
+	MediumCount     int
+	HighCount       int
+	CriticalCount   int
+}
+
+// TableName changes the default table name.
+func (trendModel) TableName() string {
+	return "vulnerability_report_trends"
+}
+
+// GormTrendStore is a vulnerability report trend store backed by a relational database.
+type GormTrendStore struct {
+	db *gorm.DB
+}
+
+// NewGormTrendStore returns a new GormTrendStore.
+func NewGormTrendStore(db *gorm.DB) GormTrendStore {
+	return GormTrendStore{
+		db: db,
+	}
+}
+
+// Save implements the vulnreport.TrendStore interface.
+func (s GormTrendStore) Save(ctx context.Context, organizationID uint, point vulnreport.TrendPoint) error {
+	var model trendModel
+
+	err := s.db.
+		Where(trendModel{OrganizationID: organizationID, Date: point.Date}).
+		Assign(map[string]interface{}{ // Zero values are ignored when a struct is used here
+			"images":           point.Images,
+			"unknown_count":    point.Counts[vulnreport.SeverityUnknown],
+			"negligible_count": point.Counts[vulnreport.SeverityNegligible],
+			"low_count":        point.Counts[vulnreport.SeverityLow],
+			"medium_count":     point.Counts[vulnreport.SeverityMedium],
+			"high_count":       point.Counts[vulnreport.SeverityHigh],
+			"critical_count":   point.Counts[vulnreport.SeverityCritical],
+		}).
+		FirstOrCreate(&model).Error
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to save report summary", "organizationId", organizationID)
+	}
+
+	return nil
+}
+
+// List implements the vulnreport.TrendStore interface.
+func (s GormTrendStore) List(ctx context.Context, organizationID uint, since time.Time) ([]vulnreport.TrendPoint, error) {
+	var models []trendModel
+
+	err := s.db.
+		Where("organization_id = ? AND date >= ?", organizationID, since).
+		Order("date").
+		Find(&models).Error
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to list report summaries", "organizationId", organizationID)
+	}
+
+	points := make([]vulnreport.TrendPoint, 0, len(models))
+	for _, model := range models {
+		points = append(points, vulnreport.TrendPoint{
+			Date:   model.Date.UTC(),
+			Images: model.Images,
+			Counts: map[string]int{
+				vulnreport.SeverityUnknown:    model.UnknownCount,
+				vulnreport.SeverityNegligible: model.NegligibleCount,
+				vulnreport.SeverityLow:        model.LowCount,
+				vulnreport.SeverityMedium:     model.MediumCount,
+				vulnreport.SeverityHigh:       model.HighCount,
+				vulnreport.SeverityCritical:   model.CriticalCount,
+			},
+		})
+	}
+
+	return points, nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+)
+
+func setUpDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	err = Migrate(db, common.NoopLogger{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestGormTrendStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewGormTrendStore(setUpDatabase(t))
+
+	day1 := time.Date(2020, 5, 19, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	err := store.Save(ctx, 1, vulnreport.TrendPoint{Date: day1, Images: 3, Counts: map[string]int{vulnreport.SeverityHigh: 2}})
+	require.NoError(t, err)
+
+	err = store.Save(ctx, 1, vulnreport.TrendPoint{Date: day2, Images: 4, Counts: map[string]int{vulnreport.SeverityHigh: 5}})
+	require.NoError(t, err)
+
+	// the summary of the same day is replaced
+	err = store.Save(ctx, 1, vulnreport.TrendPoint{Date: day2, Images: 4, Counts: map[string]int{vulnreport.SeverityCritical: 1}})
+	require.NoError(t, err)
+
+	err = store.Save(ctx, 2, vulnreport.TrendPoint{Date: day2, Images: 1})
+	require.NoError(t, err)
+
+	points, err := store.List(ctx, 1, day1)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+
+	assert.True(t, points[0].Date.Equal(day1))
+	assert.Equal(t, 3, points[0].Images)
+	assert.Equal(t, 2, points[0].Counts[vulnreport.SeverityHigh])
+
+	assert.True(t, points[1].Date.Equal(day2))
+	assert.Equal(t, 0, points[1].Counts[vulnreport.SeverityHigh])
+	assert.Equal(t, 1, points[1].Counts[vulnreport.SeverityCritical])
+
+	points, err = store.List(ctx, 1, day2)
+	require.NoError(t, err)
+	assert.Len(t, points, 1)
+}
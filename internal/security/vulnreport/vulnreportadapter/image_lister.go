@@ -0,0 +1,140 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportadapter
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+)
+
+// KubeConfigGetter returns the Kubernetes config of a cluster.
+type KubeConfigGetter interface {
+	GetKubeConfig(ctx context.Context, clusterID uint) ([]byte, error)
+}
+
+// ImageLister lists the images of the running pods of a cluster.
+type ImageLister struct {
+	kubeConfigs KubeConfigGetter
+}
+
+// NewImageLister returns a new ImageLister.
+func NewImageLister(kubeConfigs KubeConfigGetter) ImageLister {
+	return ImageLister{
+		kubeConfigs: kubeConfigs,
+	}
+}
+
+// ListImages implements the vulnreport.ImageLister interface.
+func (l ImageLister) ListImages(ctx context.Context, cluster vulnreport.Cluster) ([]vulnreport.RunningImage, error) {
+	kubeConfig, err := l.kubeConfigs.GetKubeConfig(ctx, cluster.ID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to create Kubernetes client")
+	}
+
+	return listRunningImages(client, cluster)
+}
+
+func listRunningImages(client kubernetes.Interface, cluster vulnreport.Cluster) ([]vulnreport.RunningImage, error) {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list pods")
+	}
+
+	images := make(map[string]*vulnreport.RunningImage)
+	workloads := make(map[string]map[vulnreport.Workload]bool)
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		workload := vulnreport.Workload{
+			ClusterID:   cluster.ID,
+			ClusterName: cluster.Name,
+			Namespace:   pod.Namespace,
+			Release:     pkgHelm.GetHelmReleaseName(pod.Labels),
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			digest := parseImageDigest(status.ImageID)
+			if digest == "" {
+				continue
+			}
+
+			image, ok := images[digest]
+			if !ok {
+				name, tag := parseImageReference(status.Image)
+				image = &vulnreport.RunningImage{
+					Name:   name,
+					Tag:    tag,
+					Digest: digest,
+				}
+				images[digest] = image
+				workloads[digest] = make(map[vulnreport.Workload]bool)
+			}
+
+			if !workloads[digest][workload] {
+				workloads[digest][workload] = true
+				image.Workloads = append(image.Workloads, workload)
+			}
+		}
+	}
+
+	result := make([]vulnreport.RunningImage, 0, len(images))
+	for _, image := range images {
+		result = append(result, *image)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Digest < result[j].Digest
+	})
+
+	return result, nil
+}
+
+// parseImageReference splits an image reference into its name and tag (the registry may contain a port).
+func parseImageReference(reference string) (string, string) {
+	reference = strings.SplitN(reference, "@", 2)[0]
+
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+
+	return reference, "latest"
+}
+
+// parseImageDigest extracts the digest from an image ID (eg. docker-pullable://nginx@sha256:...).
+func parseImageDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+
+	return ""
+}
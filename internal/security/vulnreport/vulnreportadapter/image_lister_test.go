@@ -0,0 +1,73 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+)
+
+func TestListRunningImages(t *testing.T) {
+	pod := func(namespace string, name string, labels map[string]string, phase corev1.PodPhase, statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase, ContainerStatuses: statuses},
+		}
+	}
+
+	nginx := corev1.ContainerStatus{Image: "nginx:1.17", ImageID: "docker-pullable://nginx@sha256:nginx"}
+	registry := corev1.ContainerStatus{Image: "registry.example.com:5000/team/app", ImageID: "docker-pullable://registry.example.com:5000/team/app@sha256:app"}
+	pending := corev1.ContainerStatus{Image: "redis:5", ImageID: ""}
+
+	client := fake.NewSimpleClientset(
+		pod("default", "web-1", map[string]string{"app.kubernetes.io/instance": "web"}, corev1.PodRunning, nginx, registry),
+		pod("default", "web-2", map[string]string{"app.kubernetes.io/instance": "web"}, corev1.PodRunning, nginx),
+		pod("other", "nginx", nil, corev1.PodRunning, nginx, pending),
+		pod("other", "done", nil, corev1.PodSucceeded, registry),
+	)
+
+	cluster := vulnreport.Cluster{ID: 1, Name: "cluster"}
+
+	images, err := listRunningImages(client, cluster)
+	require.NoError(t, err)
+
+	expected := []vulnreport.RunningImage{
+		{
+			Name:   "registry.example.com:5000/team/app",
+			Tag:    "latest",
+			Digest: "sha256:app",
+			Workloads: []vulnreport.Workload{
+				{ClusterID: 1, ClusterName: "cluster", Namespace: "default", Release: "web"},
+			},
+		},
+		{
+			Name:   "nginx",
+			Tag:    "1.17",
+			Digest: "sha256:nginx",
+			Workloads: []vulnreport.Workload{
+				{ClusterID: 1, ClusterName: "cluster", Namespace: "default", Release: "web"},
+				{ClusterID: 1, ClusterName: "cluster", Namespace: "other"},
+			},
+		},
+	}
+	assert.Equal(t, expected, images)
+}
@@ -0,0 +1,168 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulnreportdriver
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+	kitxhttp "github.com/sagikazarmark/kitx/transport/http"
+
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+	"github.com/banzaicloud/pipeline/internal/security/vulnreport"
+)
+
+// Export formats.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+type formatContextKey struct{}
+
+// RegisterHTTPHandlers mounts all of the service endpoints into an http.Handler.
+func RegisterHTTPHandlers(endpoints Endpoints, router *mux.Router, options ...kithttp.ServerOption) {
+	errorEncoder := kitxhttp.NewJSONProblemErrorResponseEncoder(apphttp.NewDefaultProblemConverter())
+
+	router.Methods(http.MethodGet).Path("").Handler(kithttp.NewServer(
+		endpoints.GetReport,
+		decodeGetReportHTTPRequest,
+		kitxhttp.ErrorResponseEncoder(encodeGetReportHTTPResponse, errorEncoder),
+		append(options, kithttp.ServerBefore(extractFormat))...,
+	))
+}
+
+// extractFormat stores the requested export format in the context for the response encoder.
+func extractFormat(ctx context.Context, r *http.Request) context.Context {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		format = formatCSV
+	}
+
+	return context.WithValue(ctx, formatContextKey{}, format)
+}
+
+func decodeGetReportHTTPRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+
+	orgIDStr, ok := vars["orgId"]
+	if !ok || orgIDStr == "" {
+		return nil, errors.NewWithDetails("missing parameter from the URL", "param", "orgId")
+	}
+
+	orgID, err := strconv.ParseUint(orgIDStr, 0, 0)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid organization ID format")
+	}
+
+	switch format := ctx.Value(formatContextKey{}); format {
+	case "", formatJSON, formatCSV:
+	default:
+		return nil, errors.WithStack(vulnreport.NewValidationError("unsupported export format", []string{"format must be json or csv"}))
+	}
+
+	query := r.URL.Query()
+
+	options := vulnreport.ReportOptions{
+		MinSeverity: query.Get("severity"),
+		CVE:         query.Get("cve"),
+	}
+
+	if fixable := query.Get("fixable"); fixable != "" {
+		value, err := strconv.ParseBool(fixable)
+		if err != nil {
+			return nil, errors.WrapIf(err, "invalid fixable parameter")
+		}
+
+		options.Fixable = &value
+	}
+
+	if refresh := query.Get("refresh"); refresh != "" {
+		options.Refresh, err = strconv.ParseBool(refresh)
+		if err != nil {
+			return nil, errors.WrapIf(err, "invalid refresh parameter")
+		}
+	}
+
+	return GetReportRequest{OrganizationID: uint(orgID), Options: options}, nil
+}
+
+func encodeGetReportHTTPResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(GetReportResponse)
+
+	if ctx.Value(formatContextKey{}) == formatCSV {
+		return encodeReportCSV(w, resp.Report)
+	}
+
+	return kitxhttp.JSONResponseEncoder(ctx, w, resp.Report)
+}
+
+// encodeReportCSV writes a row for every vulnerability of every image (and a single row for images without findings).
+func encodeReportCSV(w http.ResponseWriter, report vulnreport.Report) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="vulnerability-report.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+
+	header := []string{"image", "tag", "digest", "scanStatus", "vulnerability", "severity", "package", "fix", "url", "workloads"}
+	if err := writer.Write(header); err != nil {
+		return errors.WrapIf(err, "failed to write CSV header")
+	}
+
+	for _, image := range report.Images {
+		workloads := make([]string, 0, len(image.Workloads))
+		for _, workload := range image.Workloads {
+			workloads = append(workloads, formatWorkload(workload))
+		}
+
+		row := []string{image.Name, image.Tag, image.Digest, image.ScanStatus}
+
+		if len(image.Vulnerabilities) == 0 {
+			if err := writer.Write(append(row, "", "", "", "", "", strings.Join(workloads, " "))); err != nil {
+				return errors.WrapIf(err, "failed to write CSV record")
+			}
+
+			continue
+		}
+
+		for _, v := range image.Vulnerabilities {
+			record := append(row[:4:4], v.ID, v.Severity, v.Package, v.Fix, v.URL, strings.Join(workloads, " "))
+			if err := writer.Write(record); err != nil {
+				return errors.WrapIf(err, "failed to write CSV record")
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return errors.WrapIf(writer.Error(), "failed to write CSV")
+}
+
+// formatWorkload formats a workload as cluster/namespace[/release].
+func formatWorkload(workload vulnreport.Workload) string {
+	parts := []string{workload.ClusterName, workload.Namespace}
+	if workload.Release != "" {
+		parts = append(parts, workload.Release)
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,98 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anchore
+
+import (
+	"context"
+	"time"
+
+	securityV1Alpha "github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
+)
+
+// Annotations storing whitelist item metadata not supported by the WhiteListItem resource.
+const (
+	WhitelistExpiresAtAnnotation            = "security.banzaicloud.io/expires-at"
+	WhitelistApproverAnnotation             = "security.banzaicloud.io/approver"
+	WhitelistNotificationSecretIDAnnotation = "security.banzaicloud.io/notification-secret-id"
+)
+
+// Whitelist audit actions.
+const (
+	WhitelistActionCreated        = "created"
+	WhitelistActionApproved       = "approved"
+	WhitelistActionDeleted        = "deleted"
+	WhitelistActionExpired        = "expired"
+	WhitelistActionExpiryNotified = "expiryNotified"
+)
+
+// WhitelistAuditEvent records a change of a whitelist item.
+type WhitelistAuditEvent struct {
+	ClusterID uint       `json:"clusterId"`
+	ItemName  string     `json:"name"`
+	Action    string     `json:"action"`
+	Actor     string     `json:"actor,omitempty"`
+	Owner     string     `json:"owner,omitempty"`
+	Approver  string     `json:"approver,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// WhitelistAuditLog persists the audit trail of whitelist items.
+type WhitelistAuditLog interface {
+	// Record stores an audit event.
+	Record(ctx context.Context, event WhitelistAuditEvent) error
+
+	// List returns the audit events of a cluster ordered by time.
+	List(ctx context.Context, clusterID uint) ([]WhitelistAuditEvent, error)
+}
+
+type whitelistActorContextKey struct{}
+
+// WithWhitelistActor returns a context recording the given actor in the whitelist audit events.
+func WithWhitelistActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, whitelistActorContextKey{}, actor)
+}
+
+func whitelistActor(ctx context.Context) string {
+	actor, _ := ctx.Value(whitelistActorContextKey{}).(string)
+
+	return actor
+}
+
+// WhitelistItemExpiresAt returns the expiry of a whitelist item (if any).
+func WhitelistItemExpiresAt(item securityV1Alpha.WhiteListItem) (time.Time, bool) {
+	value, ok := item.Annotations[WhitelistExpiresAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiresAt, true
+}
+
+// WhitelistItemApprover returns the approver of a whitelist item.
+func WhitelistItemApprover(item securityV1Alpha.WhiteListItem) string {
+	return item.Annotations[WhitelistApproverAnnotation]
+}
+
+// WhitelistItemNotificationSecretID returns the ID of the secret expiry notifications of a whitelist item are sent with.
+func WhitelistItemNotificationSecretID(item securityV1Alpha.WhiteListItem) string {
+	return item.Annotations[WhitelistNotificationSecretIDAnnotation]
+}
@@ -0,0 +1,257 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	securityV1Alpha "github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	anchore "github.com/banzaicloud/pipeline/internal/security"
+)
+
+// actor is recorded in the audit trail for changes made by the expiry job.
+const actor = "pipeline"
+
+// Config contains the whitelist expiry configuration.
+type Config struct {
+	// Enabled turns the expiry scheduler on.
+	Enabled bool
+
+	// Schedule is the cron schedule of checking whitelist items.
+	Schedule string
+
+	// NotifyBefore is the time owners are notified before their whitelist items expire.
+	NotifyBefore time.Duration
+}
+
+// Validate validates the configuration.
+func (c Config) Validate() error {
+	if c.Enabled && c.Schedule == "" {
+		return errors.New("whitelist expiry schedule is required")
+	}
+
+	return nil
+}
+
+// Cluster is a cluster with security scan enabled.
+type Cluster interface {
+	anchore.Cluster
+
+	GetOrganizationId() uint
+	GetName() string
+}
+
+// +testify:mock:testOnly=true
+
+// ClusterLister lists clusters with security scan enabled.
+type ClusterLister interface {
+	// ListClusters lists every cluster with security scan enabled.
+	ListClusters(ctx context.Context) ([]Cluster, error)
+}
+
+// Notification is sent to the owner of a whitelist item about its expiry.
+type Notification struct {
+	OrganizationID       uint
+	ClusterID            uint
+	ClusterName          string
+	ItemName             string
+	Owner                string
+	ExpiresAt            time.Time
+	Expired              bool
+	NotificationSecretID string
+}
+
+// Message returns a human readable message about the expiry.
+func (n Notification) Message() string {
+	if n.Expired {
+		return fmt.Sprintf(
+			"Whitelist item %q of %s has expired and has been removed from cluster %q",
+			n.ItemName, n.Owner, n.ClusterName,
+		)
+	}
+
+	return fmt.Sprintf(
+		"Whitelist item %q of %s in cluster %q expires at %s",
+		n.ItemName, n.Owner, n.ClusterName, n.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+}
+
+// +testify:mock:testOnly=true
+
+// Notifier sends notifications about whitelist item expiries.
+type Notifier interface {
+	// Notify sends a notification.
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Expirer removes expired whitelist items and notifies their owners.
+type Expirer struct {
+	config     Config
+	clusters   ClusterLister
+	whitelists anchore.WhitelistService
+	auditLog   anchore.WhitelistAuditLog
+	notifier   Notifier
+
+	logger common.Logger
+}
+
+// NewExpirer returns a new Expirer.
+func NewExpirer(
+	config Config,
+	clusters ClusterLister,
+	whitelists anchore.WhitelistService,
+	auditLog anchore.WhitelistAuditLog,
+	notifier Notifier,
+	logger common.Logger,
+) Expirer {
+	return Expirer{
+		config:     config,
+		clusters:   clusters,
+		whitelists: whitelists,
+		auditLog:   auditLog,
+		notifier:   notifier,
+
+		logger: logger,
+	}
+}
+
+// Expire removes the whitelist items expired by now and notifies the owners of the ones expiring soon.
+// A failing cluster does not stop processing the rest of them.
+func (e Expirer) Expire(ctx context.Context, now time.Time) error {
+	clusters, err := e.clusters.ListClusters(ctx)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list clusters")
+	}
+
+	ctx = anchore.WithWhitelistActor(ctx, actor)
+
+	var errs error
+
+	for _, cluster := range clusters {
+		if err := e.expireCluster(ctx, cluster, now); err != nil {
+			errs = errors.Append(errs, errors.WrapIfWithDetails(err, "failed to expire whitelist items", "clusterId", cluster.GetID()))
+		}
+	}
+
+	return errs
+}
+
+func (e Expirer) expireCluster(ctx context.Context, cluster Cluster, now time.Time) error {
+	items, err := e.whitelists.GetWhitelists(ctx, cluster)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list whitelist items")
+	}
+
+	var notified map[string]time.Time
+
+	var errs error
+
+	for _, item := range items {
+		expiresAt, ok := anchore.WhitelistItemExpiresAt(item)
+		if !ok {
+			continue
+		}
+
+		notification := Notification{
+			OrganizationID:       cluster.GetOrganizationId(),
+			ClusterID:            cluster.GetID(),
+			ClusterName:          cluster.GetName(),
+			ItemName:             item.Name,
+			Owner:                item.Spec.Creator,
+			ExpiresAt:            expiresAt,
+			NotificationSecretID: anchore.WhitelistItemNotificationSecretID(item),
+		}
+
+		switch {
+		case !expiresAt.After(now):
+			if err := e.whitelists.DeleteWhitelist(ctx, cluster, item.Name); err != nil {
+				errs = errors.Append(errs, errors.WrapIfWithDetails(err, "failed to remove expired whitelist item", "name", item.Name))
+
+				continue
+			}
+
+			notification.Expired = true
+			e.notify(ctx, notification)
+
+		case expiresAt.Sub(now) <= e.config.NotifyBefore:
+			if notified == nil {
+				notified, err = e.lastNotifications(ctx, cluster.GetID())
+				if err != nil {
+					return err
+				}
+			}
+
+			// owners are notified only once about an item
+			if lastNotified, ok := notified[item.Name]; ok && !lastNotified.Before(item.CreationTimestamp.Time) {
+				continue
+			}
+
+			e.notify(ctx, notification)
+
+			if err := e.auditLog.Record(ctx, expiryNotifiedEvent(cluster, item, expiresAt, now)); err != nil {
+				errs = errors.Append(errs, errors.WrapIfWithDetails(err, "failed to record expiry notification", "name", item.Name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// lastNotifications returns the time of the last expiry notification by whitelist item name.
+func (e Expirer) lastNotifications(ctx context.Context, clusterID uint) (map[string]time.Time, error) {
+	events, err := e.auditLog.List(ctx, clusterID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list whitelist audit events")
+	}
+
+	notified := make(map[string]time.Time)
+	for _, event := range events {
+		if event.Action == anchore.WhitelistActionExpiryNotified {
+			notified[event.ItemName] = event.CreatedAt
+		}
+	}
+
+	return notified, nil
+}
+
+// notify sends a notification, failures are only logged as they should not block the expiry
+func (e Expirer) notify(ctx context.Context, notification Notification) {
+	if err := e.notifier.Notify(ctx, notification); err != nil {
+		e.logger.Warn("failed to send whitelist expiry notification", map[string]interface{}{
+			"clusterId":     notification.ClusterID,
+			"whiteListItem": notification.ItemName,
+			"error":         err.Error(),
+		})
+	}
+}
+
+func expiryNotifiedEvent(cluster Cluster, item securityV1Alpha.WhiteListItem, expiresAt time.Time, now time.Time) anchore.WhitelistAuditEvent {
+	return anchore.WhitelistAuditEvent{
+		ClusterID: cluster.GetID(),
+		ItemName:  item.Name,
+		Action:    anchore.WhitelistActionExpiryNotified,
+		Actor:     actor,
+		Owner:     item.Spec.Creator,
+		Approver:  anchore.WhitelistItemApprover(item),
+		Reason:    item.Spec.Reason,
+		ExpiresAt: &expiresAt,
+		CreatedAt: now,
+	}
+}
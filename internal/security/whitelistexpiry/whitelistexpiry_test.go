@@ -0,0 +1,183 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	securityV1Alpha "github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	anchore "github.com/banzaicloud/pipeline/internal/security"
+	"github.com/banzaicloud/pipeline/pkg/security"
+)
+
+type testCluster struct{}
+
+func (testCluster) GetK8sConfig() ([]byte, error) { return nil, nil }
+func (testCluster) GetID() uint                   { return 1 }
+func (testCluster) GetOrganizationId() uint       { return 2 }
+func (testCluster) GetName() string               { return "cluster" }
+
+type inmemoryWhitelists struct {
+	items   []securityV1Alpha.WhiteListItem
+	deleted []string
+}
+
+func (w *inmemoryWhitelists) GetWhitelists(_ context.Context, _ anchore.Cluster) ([]securityV1Alpha.WhiteListItem, error) {
+	return w.items, nil
+}
+
+func (w *inmemoryWhitelists) CreateWhitelist(_ context.Context, _ anchore.Cluster, _ security.ReleaseWhiteListItem) (interface{}, error) {
+	return nil, nil
+}
+
+func (w *inmemoryWhitelists) ApproveWhitelist(_ context.Context, _ anchore.Cluster, _ string, _ string) error {
+	return nil
+}
+
+func (w *inmemoryWhitelists) DeleteWhitelist(_ context.Context, _ anchore.Cluster, name string) error {
+	w.deleted = append(w.deleted, name)
+
+	return nil
+}
+
+type inmemoryAuditLog struct {
+	events []anchore.WhitelistAuditEvent
+}
+
+func (l *inmemoryAuditLog) Record(_ context.Context, event anchore.WhitelistAuditEvent) error {
+	l.events = append(l.events, event)
+
+	return nil
+}
+
+func (l *inmemoryAuditLog) List(_ context.Context, _ uint) ([]anchore.WhitelistAuditEvent, error) {
+	return l.events, nil
+}
+
+func whitelistItem(name string, created time.Time, expiresAt time.Time) securityV1Alpha.WhiteListItem {
+	item := securityV1Alpha.WhiteListItem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: securityV1Alpha.WhiteListSpec{
+			Creator: "john",
+			Reason:  "testing",
+		},
+	}
+
+	if !expiresAt.IsZero() {
+		item.Annotations = map[string]string{
+			anchore.WhitelistExpiresAtAnnotation:            expiresAt.Format(time.RFC3339),
+			anchore.WhitelistNotificationSecretIDAnnotation: "slack",
+		}
+	}
+
+	return item
+}
+
+func TestExpirer_Expire(t *testing.T) {
+	now := time.Date(2020, time.May, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-30 * 24 * time.Hour)
+
+	whitelists := &inmemoryWhitelists{
+		items: []securityV1Alpha.WhiteListItem{
+			whitelistItem("permanent", created, time.Time{}),
+			whitelistItem("expired", created, now.Add(-time.Minute)),
+			whitelistItem("expiring", created, now.Add(time.Hour)),
+			whitelistItem("notified", created, now.Add(2*time.Hour)),
+			whitelistItem("later", created, now.Add(30*24*time.Hour)),
+		},
+	}
+
+	auditLog := &inmemoryAuditLog{
+		events: []anchore.WhitelistAuditEvent{
+			{
+				ClusterID: 1,
+				ItemName:  "notified",
+				Action:    anchore.WhitelistActionExpiryNotified,
+				CreatedAt: now.Add(-time.Hour),
+			},
+		},
+	}
+
+	clusters := new(MockClusterLister)
+	clusters.On("ListClusters", mock.Anything).Return([]Cluster{testCluster{}}, nil)
+
+	notifier := new(MockNotifier)
+	notifier.On("Notify", mock.Anything, Notification{
+		OrganizationID:       2,
+		ClusterID:            1,
+		ClusterName:          "cluster",
+		ItemName:             "expired",
+		Owner:                "john",
+		ExpiresAt:            now.Add(-time.Minute),
+		Expired:              true,
+		NotificationSecretID: "slack",
+	}).Return(nil).Once()
+	notifier.On("Notify", mock.Anything, Notification{
+		OrganizationID:       2,
+		ClusterID:            1,
+		ClusterName:          "cluster",
+		ItemName:             "expiring",
+		Owner:                "john",
+		ExpiresAt:            now.Add(time.Hour),
+		NotificationSecretID: "slack",
+	}).Return(nil).Once()
+
+	config := Config{
+		Enabled:      true,
+		Schedule:     "0 * * * *",
+		NotifyBefore: 72 * time.Hour,
+	}
+
+	expirer := NewExpirer(config, clusters, whitelists, auditLog, notifier, common.NoopLogger{})
+
+	err := expirer.Expire(context.Background(), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"expired"}, whitelists.deleted)
+
+	require.Len(t, auditLog.events, 2)
+	assert.Equal(t, "expiring", auditLog.events[1].ItemName)
+	assert.Equal(t, anchore.WhitelistActionExpiryNotified, auditLog.events[1].Action)
+	assert.Equal(t, actor, auditLog.events[1].Actor)
+
+	clusters.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestNotification_Message(t *testing.T) {
+	notification := Notification{
+		ClusterName: "cluster",
+		ItemName:    "item",
+		Owner:       "john",
+		ExpiresAt:   time.Date(2020, time.May, 10, 12, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, `Whitelist item "item" of john in cluster "cluster" expires at 2020-05-10T12:00:00Z`, notification.Message())
+
+	notification.Expired = true
+
+	assert.Equal(t, `Whitelist item "item" of john has expired and has been removed from cluster "cluster"`, notification.Message())
+}
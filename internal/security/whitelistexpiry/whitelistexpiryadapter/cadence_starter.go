@@ -0,0 +1,61 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiryadapter
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/client"
+
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry/whitelistexpiryworkflow"
+)
+
+// CadenceStarter starts the whitelist expiry workflow.
+type CadenceStarter struct {
+	workflowClient client.Client
+}
+
+// NewCadenceStarter returns a new CadenceStarter.
+func NewCadenceStarter(workflowClient client.Client) CadenceStarter {
+	return CadenceStarter{
+		workflowClient: workflowClient,
+	}
+}
+
+// StartScheduler starts the whitelist expiry cron workflow unless it is already running.
+func (s CadenceStarter) StartScheduler(ctx context.Context, schedule string) error {
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                           whitelistexpiryworkflow.ExpirySchedulerWorkflowName,
+		TaskList:                     "pipeline",
+		ExecutionStartToCloseTimeout: 12 * time.Hour,
+		CronSchedule:                 schedule,
+	}
+
+	_, err := s.workflowClient.StartWorkflow(ctx, workflowOptions, whitelistexpiryworkflow.ExpirySchedulerWorkflowName)
+	if err != nil && !isAlreadyStartedError(err) {
+		return errors.WrapWithDetails(err, "failed to start workflow", "workflow", whitelistexpiryworkflow.ExpirySchedulerWorkflowName)
+	}
+
+	return nil
+}
+
+func isAlreadyStartedError(err error) bool {
+	var alreadyStartedErr *shared.WorkflowExecutionAlreadyStartedError
+
+	return errors.As(err, &alreadyStartedErr)
+}
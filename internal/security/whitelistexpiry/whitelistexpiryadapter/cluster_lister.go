@@ -0,0 +1,72 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiryadapter
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/integratedservices"
+	"github.com/banzaicloud/pipeline/internal/integratedservices/services/securityscan"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry"
+	"github.com/banzaicloud/pipeline/src/cluster"
+)
+
+// ClusterGetter lists every cluster.
+type ClusterGetter interface {
+	GetAllClusters(ctx context.Context) ([]cluster.CommonCluster, error)
+}
+
+// ClusterLister lists the clusters with an active security scan integrated service.
+type ClusterLister struct {
+	clusters           ClusterGetter
+	integratedServices integratedservices.IntegratedServiceRepository
+}
+
+// NewClusterLister returns a new ClusterLister.
+func NewClusterLister(clusters ClusterGetter, integratedServices integratedservices.IntegratedServiceRepository) ClusterLister {
+	return ClusterLister{
+		clusters:           clusters,
+		integratedServices: integratedServices,
+	}
+}
+
+// ListClusters implements the whitelistexpiry.ClusterLister interface.
+func (l ClusterLister) ListClusters(ctx context.Context) ([]whitelistexpiry.Cluster, error) {
+	clusters, err := l.clusters.GetAllClusters(ctx)
+	if err != nil {
+		return nil, errors.WrapIf(err, "failed to list clusters")
+	}
+
+	result := make([]whitelistexpiry.Cluster, 0, len(clusters))
+
+	for _, c := range clusters {
+		service, err := l.integratedServices.GetIntegratedService(ctx, c.GetID(), securityscan.IntegratedServiceName)
+		if integratedservices.IsIntegratedServiceNotFoundError(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get security scan integrated service", "clusterId", c.GetID())
+		}
+
+		if service.Status != integratedservices.IntegratedServiceStatusActive {
+			continue
+		}
+
+		result = append(result, c)
+	}
+
+	return result, nil
+}
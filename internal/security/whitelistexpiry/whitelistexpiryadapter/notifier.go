@@ -0,0 +1,89 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiryadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry"
+)
+
+// SlackNotifier logs whitelist expiries and sends them to Slack when the item has a notification secret.
+type SlackNotifier struct {
+	secrets    secret.Store
+	httpClient *http.Client
+
+	logger common.Logger
+}
+
+// NewSlackNotifier returns a new SlackNotifier.
+func NewSlackNotifier(secrets secret.Store, httpClient *http.Client, logger common.Logger) SlackNotifier {
+	return SlackNotifier{
+		secrets:    secrets,
+		httpClient: httpClient,
+
+		logger: logger,
+	}
+}
+
+// Notify implements the whitelistexpiry.Notifier interface.
+func (n SlackNotifier) Notify(ctx context.Context, notification whitelistexpiry.Notification) error {
+	n.logger.Info(notification.Message(), map[string]interface{}{
+		"organizationId": notification.OrganizationID,
+		"clusterId":      notification.ClusterID,
+		"whiteListItem":  notification.ItemName,
+	})
+
+	if notification.NotificationSecretID == "" {
+		return nil
+	}
+
+	notificationSecret, err := n.secrets.Get(ctx, notification.OrganizationID, notification.NotificationSecretID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to get notification secret")
+	}
+
+	body, err := json.Marshal(map[string]string{"text": notification.Message()})
+	if err != nil {
+		return errors.WrapIf(err, "failed to encode slack message")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notificationSecret.Values[types.FieldSlackApiUrl], bytes.NewReader(body))
+	if err != nil {
+		return errors.WrapIf(err, "failed to create slack request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return errors.WrapIf(err, "failed to send slack message")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.NewWithDetails("failed to send slack message", "statusCode", resp.StatusCode)
+	}
+
+	return nil
+}
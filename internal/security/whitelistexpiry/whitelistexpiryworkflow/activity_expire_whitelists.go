@@ -0,0 +1,52 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiryworkflow
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/activity"
+
+	"github.com/banzaicloud/pipeline/internal/security/whitelistexpiry"
+)
+
+const ExpireWhitelistsActivityName = "security-whitelist-expire"
+
+// ExpireWhitelistsActivity removes expired whitelist items and notifies the owners of expiring ones.
+type ExpireWhitelistsActivity struct {
+	expirer whitelistexpiry.Expirer
+}
+
+type ExpireWhitelistsActivityInput struct {
+	Now time.Time
+}
+
+// NewExpireWhitelistsActivity returns a new ExpireWhitelistsActivity.
+func NewExpireWhitelistsActivity(expirer whitelistexpiry.Expirer) ExpireWhitelistsActivity {
+	return ExpireWhitelistsActivity{
+		expirer: expirer,
+	}
+}
+
+// Register registers the activity in the worker.
+func (a ExpireWhitelistsActivity) Register() {
+	activity.RegisterWithOptions(a.Execute, activity.RegisterOptions{Name: ExpireWhitelistsActivityName})
+}
+
+// Execute is the main body of the activity.
+func (a ExpireWhitelistsActivity) Execute(ctx context.Context, input ExpireWhitelistsActivityInput) error {
+	return a.expirer.Expire(ctx, input.Now)
+}
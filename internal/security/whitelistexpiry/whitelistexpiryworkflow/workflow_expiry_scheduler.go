@@ -0,0 +1,53 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package whitelistexpiryworkflow
+
+import (
+	"time"
+
+	"go.uber.org/cadence/workflow"
+)
+
+const ExpirySchedulerWorkflowName = "security-whitelist-expiry-scheduler"
+
+// ExpirySchedulerWorkflow removes expired whitelist items and notifies the owners of expiring ones.
+// It is supposed to be started as a cron workflow.
+type ExpirySchedulerWorkflow struct{}
+
+// NewExpirySchedulerWorkflow returns a new ExpirySchedulerWorkflow.
+func NewExpirySchedulerWorkflow() ExpirySchedulerWorkflow {
+	return ExpirySchedulerWorkflow{}
+}
+
+// Register registers the workflow in the worker.
+func (w ExpirySchedulerWorkflow) Register() {
+	workflow.RegisterWithOptions(w.Execute, workflow.RegisterOptions{Name: ExpirySchedulerWorkflowName})
+}
+
+// Execute is the main body of the workflow.
+func (w ExpirySchedulerWorkflow) Execute(ctx workflow.Context) error {
+	activityOptions := workflow.ActivityOptions{
+		ScheduleToStartTimeout: 10 * time.Minute,
+		StartToCloseTimeout:    30 * time.Minute,
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	activityInput := ExpireWhitelistsActivityInput{
+		Now: workflow.Now(ctx),
+	}
+
+	return workflow.ExecuteActivity(ctx, ExpireWhitelistsActivityName, activityInput).Get(ctx, nil)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+)
+
+// NewSecretStore returns a secret store backed by an in-memory SQLite database,
+// containing the given secrets in an organization.
+func NewSecretStore(t *testing.T, organizationID uint, models ...secret.Model) secret.Store {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err := secretadapter.Migrate(db, common.NoopLogger{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	store, err := secretadapter.NewSQLStore(db, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for _, model := range models {
+		if err := store.Create(context.Background(), organizationID, model); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	return store
+}
@@ -53,6 +53,7 @@ const (
 	Amazon     = "amazon"
 	Azure      = "azure"
 	Google     = "google"
+	Hosts      = "hosts"
 	Kubernetes = "kubernetes"
 	Oracle     = "oracle"
 	Vsphere    = "vsphere"
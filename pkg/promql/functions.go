@@ -0,0 +1,121 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+// valueType is the type of the value an expression evaluates to.
+type valueType int
+
+const (
+	valueTypeNone valueType = iota
+	valueTypeScalar
+	valueTypeVector
+	valueTypeMatrix
+	valueTypeString
+)
+
+func (t valueType) String() string {
+	switch t {
+	case valueTypeScalar:
+		return "scalar"
+	case valueTypeVector:
+		return "instant vector"
+	case valueTypeMatrix:
+		return "range vector"
+	case valueTypeString:
+		return "string"
+	default:
+		return "none"
+	}
+}
+
+// function is the signature of a PromQL function.
+type function struct {
+	argTypes []valueType
+
+	// optionalArgs is the number of trailing arguments that can be omitted.
+	optionalArgs int
+
+	// variadic functions accept any number of arguments of the type of the last one.
+	variadic bool
+
+	returnType valueType
+}
+
+// functions lists the functions of the Prometheus version deployed by the monitoring service (v2.13).
+// nolint: gochecknoglobals
+var functions = map[string]function{
+	"abs":                {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"absent":             {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"avg_over_time":      {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"ceil":               {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"changes":            {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"clamp_max":          {argTypes: []valueType{valueTypeVector, valueTypeScalar}, returnType: valueTypeVector},
+	"clamp_min":          {argTypes: []valueType{valueTypeVector, valueTypeScalar}, returnType: valueTypeVector},
+	"count_over_time":    {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"days_in_month":      {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"day_of_month":       {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"day_of_week":        {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"delta":              {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"deriv":              {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"exp":                {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"floor":              {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"histogram_quantile": {argTypes: []valueType{valueTypeScalar, valueTypeVector}, returnType: valueTypeVector},
+	"holt_winters":       {argTypes: []valueType{valueTypeMatrix, valueTypeScalar, valueTypeScalar}, returnType: valueTypeVector},
+	"hour":               {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"idelta":             {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"increase":           {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"irate":              {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"label_join":         {argTypes: []valueType{valueTypeVector, valueTypeString, valueTypeString, valueTypeString}, optionalArgs: 1, variadic: true, returnType: valueTypeVector},
+	"label_replace":      {argTypes: []valueType{valueTypeVector, valueTypeString, valueTypeString, valueTypeString, valueTypeString}, returnType: valueTypeVector},
+	"ln":                 {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"log10":              {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"log2":               {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"max_over_time":      {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"min_over_time":      {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"minute":             {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"month":              {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+	"predict_linear":     {argTypes: []valueType{valueTypeMatrix, valueTypeScalar}, returnType: valueTypeVector},
+	"quantile_over_time": {argTypes: []valueType{valueTypeScalar, valueTypeMatrix}, returnType: valueTypeVector},
+	"rate":               {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"resets":             {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"round":              {argTypes: []valueType{valueTypeVector, valueTypeScalar}, optionalArgs: 1, returnType: valueTypeVector},
+	"scalar":             {argTypes: []valueType{valueTypeVector}, returnType: valueTypeScalar},
+	"sort":               {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"sort_desc":          {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"sqrt":               {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"stddev_over_time":   {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"stdvar_over_time":   {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"sum_over_time":      {argTypes: []valueType{valueTypeMatrix}, returnType: valueTypeVector},
+	"time":               {returnType: valueTypeScalar},
+	"timestamp":          {argTypes: []valueType{valueTypeVector}, returnType: valueTypeVector},
+	"vector":             {argTypes: []valueType{valueTypeScalar}, returnType: valueTypeVector},
+	"year":               {argTypes: []valueType{valueTypeVector}, optionalArgs: 1, returnType: valueTypeVector},
+}
+
+// aggregators lists the aggregation operators along with the type of their parameter (if any).
+// nolint: gochecknoglobals
+var aggregators = map[string]valueType{
+	"sum":          valueTypeNone,
+	"min":          valueTypeNone,
+	"max":          valueTypeNone,
+	"avg":          valueTypeNone,
+	"stddev":       valueTypeNone,
+	"stdvar":       valueTypeNone,
+	"count":        valueTypeNone,
+	"count_values": valueTypeString,
+	"bottomk":      valueTypeScalar,
+	"topk":         valueTypeScalar,
+	"quantile":     valueTypeScalar,
+}
@@ -0,0 +1,187 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdentifier
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of input"
+	}
+
+	return strconv.Quote(t.val)
+}
+
+// operators lists the punctuation and operator tokens, longer ones first.
+// nolint: gochecknoglobals
+var operators = []string{
+	"==", "!=", "<=", ">=", "=~", "!~",
+	"<", ">", "=", "+", "-", "*", "/", "%", "^",
+	",", "(", ")", "{", "}", "[", "]", ":", "@",
+}
+
+// nolint: gochecknoglobals
+var durationRegexp = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	var inBrackets bool
+
+	for pos := 0; pos < len(input); {
+		c := rune(input[pos])
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case c == '#':
+			// comments last until the end of the line
+			for pos < len(input) && input[pos] != '\n' {
+				pos++
+			}
+
+		case c == '"' || c == '\'' || c == '`':
+			end, err := scanString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokenString, val: input[pos:end], pos: pos})
+			pos = end
+
+		case isDigit(c) || (c == '.' && pos+1 < len(input) && isDigit(rune(input[pos+1]))):
+			start := pos
+			for pos < len(input) {
+				r := rune(input[pos])
+				if isAlphaNumeric(r) || r == '.' {
+					pos++
+					continue
+				}
+				// exponent sign of decimal numbers, like 1e-3
+				if (r == '+' || r == '-') && pos > start && strings.ContainsRune("eE", rune(input[pos-1])) && !strings.HasPrefix(strings.ToLower(input[start:pos]), "0x") {
+					pos++
+					continue
+				}
+				break
+			}
+
+			val := input[start:pos]
+			switch {
+			case durationRegexp.MatchString(val):
+				tokens = append(tokens, token{typ: tokenDuration, val: val, pos: start})
+			case isNumber(val):
+				tokens = append(tokens, token{typ: tokenNumber, val: val, pos: start})
+			default:
+				return nil, fmt.Errorf("bad number or duration syntax %q at position %d", val, start)
+			}
+
+		// colons inside brackets separate the range and the resolution of subqueries
+		case isIdentifierStart(c) && !(c == ':' && inBrackets):
+			start := pos
+			for pos < len(input) && (isAlphaNumeric(rune(input[pos])) || input[pos] == ':') {
+				pos++
+			}
+			tokens = append(tokens, token{typ: tokenIdentifier, val: input[start:pos], pos: start})
+
+		default:
+			var found bool
+			for _, op := range operators {
+				if strings.HasPrefix(input[pos:], op) {
+					switch op {
+					case "[":
+						inBrackets = true
+					case "]":
+						inBrackets = false
+					}
+					tokens = append(tokens, token{typ: tokenOperator, val: op, pos: pos})
+					pos += len(op)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+		}
+	}
+
+	return append(tokens, token{typ: tokenEOF, pos: len(input)}), nil
+}
+
+// scanString returns the position after the string literal starting at pos.
+func scanString(input string, pos int) (int, error) {
+	quote := input[pos]
+	for i := pos + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case '\n':
+			if quote != '`' {
+				return 0, fmt.Errorf("unterminated string at position %d", pos)
+			}
+		case quote:
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated string at position %d", pos)
+}
+
+func isNumber(val string) bool {
+	if _, err := strconv.ParseFloat(val, 64); err == nil {
+		return true
+	}
+
+	_, err := strconv.ParseInt(val, 0, 64)
+
+	return err == nil
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == ':' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func isAlphaNumeric(r rune) bool {
+	return r == '_' || isDigit(r) || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
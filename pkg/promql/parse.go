@@ -0,0 +1,637 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promql checks PromQL expressions before they are handed to Prometheus.
+//
+// Besides the syntax, expressions are type checked against the functions and
+// operators of the Prometheus version deployed by the monitoring service.
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// binaryPrecedence returns the precedence of binary operators, 0 for other tokens.
+func binaryPrecedence(t token) int {
+	if t.typ != tokenOperator && t.typ != tokenIdentifier {
+		return 0
+	}
+
+	switch t.val {
+	case "or":
+		return 1
+	case "and", "unless":
+		return 2
+	case "==", "!=", "<=", "<", ">=", ">":
+		return 3
+	case "+", "-":
+		return 4
+	case "*", "/", "%":
+		return 5
+	case "^":
+		return 6
+	default:
+		return 0
+	}
+}
+
+func isComparisonOperator(op string) bool {
+	switch op {
+	case "==", "!=", "<=", "<", ">=", ">":
+		return true
+	default:
+		return false
+	}
+}
+
+func isSetOperator(op string) bool {
+	return op == "and" || op == "or" || op == "unless"
+}
+
+// nolint: gochecknoglobals
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Validate returns an error if the given expression is not a valid PromQL expression.
+func Validate(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return errors.New("expression cannot be empty")
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return errors.WrapIf(err, "invalid expression")
+	}
+
+	p := &parser{tokens: tokens}
+	if _, err := p.parseExpr(0); err != nil {
+		return errors.WrapIf(err, "invalid expression")
+	}
+
+	if t := p.peek(); t.typ != tokenEOF {
+		return errors.Errorf("invalid expression: unexpected %s at position %d", t, t.pos)
+	}
+
+	return nil
+}
+
+// exprKind tells which postfix modifiers an expression accepts.
+type exprKind int
+
+const (
+	exprKindOther exprKind = iota
+	exprKindVectorSelector
+	exprKindMatrixSelector
+	exprKindSubquery
+)
+
+// node describes a parsed (sub)expression.
+type node struct {
+	typ    valueType
+	kind   exprKind
+	offset bool
+	pos    int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) is(val string) bool {
+	t := p.peek()
+	return (t.typ == tokenOperator || t.typ == tokenIdentifier) && t.val == val
+}
+
+func (p *parser) expect(val string) error {
+	if t := p.next(); (t.typ != tokenOperator && t.typ != tokenIdentifier) || t.val != val {
+		return unexpected(t, strconv.Quote(val))
+	}
+
+	return nil
+}
+
+func unexpected(t token, expected string) error {
+	return fmt.Errorf("unexpected %s at position %d, expected %s", t, t.pos, expected)
+}
+
+func typeMismatch(n node, context string, expected ...valueType) error {
+	names := make([]string, 0, len(expected))
+	for _, typ := range expected {
+		names = append(names, typ.String())
+	}
+
+	return fmt.Errorf("%s at position %d must be %s, got %s", context, n.pos, strings.Join(names, " or "), n.typ)
+}
+
+// parseExpr parses binary expressions with operators of at least the given precedence.
+func (p *parser) parseExpr(minPrecedence int) (node, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return node{}, err
+	}
+
+	for {
+		op := p.peek()
+		precedence := binaryPrecedence(op)
+		if precedence == 0 || precedence < minPrecedence {
+			return lhs, nil
+		}
+		p.next()
+
+		returnBool, vectorMatching, err := p.parseBinaryModifiers(op)
+		if err != nil {
+			return node{}, err
+		}
+
+		// the power operator is right associative
+		nextPrecedence := precedence + 1
+		if op.val == "^" {
+			nextPrecedence = precedence
+		}
+
+		rhs, err := p.parseExpr(nextPrecedence)
+		if err != nil {
+			return node{}, err
+		}
+
+		lhs, err = checkBinary(op, lhs, rhs, returnBool, vectorMatching)
+		if err != nil {
+			return node{}, err
+		}
+	}
+}
+
+// checkBinary type checks a binary operation and returns the resulting node.
+func checkBinary(op token, lhs node, rhs node, returnBool bool, vectorMatching bool) (node, error) {
+	context := fmt.Sprintf("operand of %s", op)
+
+	if isSetOperator(op.val) {
+		for _, operand := range []node{lhs, rhs} {
+			if operand.typ != valueTypeVector {
+				return node{}, typeMismatch(operand, context, valueTypeVector)
+			}
+		}
+
+		return node{typ: valueTypeVector, pos: lhs.pos}, nil
+	}
+
+	for _, operand := range []node{lhs, rhs} {
+		if operand.typ != valueTypeScalar && operand.typ != valueTypeVector {
+			return node{}, typeMismatch(operand, context, valueTypeScalar, valueTypeVector)
+		}
+	}
+
+	bothScalars := lhs.typ == valueTypeScalar && rhs.typ == valueTypeScalar
+
+	if vectorMatching && (lhs.typ != valueTypeVector || rhs.typ != valueTypeVector) {
+		return node{}, fmt.Errorf("vector matching at position %d is only allowed between instant vectors", op.pos)
+	}
+
+	if isComparisonOperator(op.val) && bothScalars && !returnBool {
+		return node{}, fmt.Errorf("comparisons between scalars at position %d must use the bool modifier", op.pos)
+	}
+
+	if bothScalars {
+		return node{typ: valueTypeScalar, pos: lhs.pos}, nil
+	}
+
+	return node{typ: valueTypeVector, pos: lhs.pos}, nil
+}
+
+func (p *parser) parseBinaryModifiers(op token) (returnBool bool, vectorMatching bool, err error) {
+	if p.is("bool") {
+		t := p.next()
+		if !isComparisonOperator(op.val) {
+			return false, false, fmt.Errorf("bool modifier at position %d can only be used on comparison operators", t.pos)
+		}
+
+		returnBool = true
+	}
+
+	if p.is("on") || p.is("ignoring") {
+		p.next()
+		vectorMatching = true
+
+		if err := p.parseLabelList(); err != nil {
+			return false, false, err
+		}
+
+		if p.is("group_left") || p.is("group_right") {
+			t := p.next()
+			if isSetOperator(op.val) {
+				return false, false, fmt.Errorf("no grouping allowed for %s operation at position %d", op, t.pos)
+			}
+
+			if p.is("(") {
+				if err := p.parseLabelList(); err != nil {
+					return false, false, err
+				}
+			}
+		}
+	}
+
+	return returnBool, vectorMatching, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.is("+") || p.is("-") {
+		t := p.next()
+
+		n, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+
+		if n.typ != valueTypeScalar && n.typ != valueTypeVector {
+			return node{}, typeMismatch(n, fmt.Sprintf("operand of unary %s", t), valueTypeScalar, valueTypeVector)
+		}
+
+		return node{typ: n.typ, pos: t.pos}, nil
+	}
+
+	n, err := p.parsePrimary()
+	if err != nil {
+		return node{}, err
+	}
+
+	return p.parsePostfix(n)
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.typ {
+	case tokenNumber:
+		return node{typ: valueTypeScalar, pos: t.pos}, nil
+
+	case tokenString:
+		return node{typ: valueTypeString, pos: t.pos}, nil
+
+	case tokenOperator:
+		switch t.val {
+		case "(":
+			n, err := p.parseExpr(0)
+			if err != nil {
+				return node{}, err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return node{}, err
+			}
+
+			return node{typ: n.typ, pos: t.pos}, nil
+		case "{":
+			if err := p.parseLabelMatchers(t, false); err != nil {
+				return node{}, err
+			}
+
+			return node{typ: valueTypeVector, kind: exprKindVectorSelector, pos: t.pos}, nil
+		}
+
+	case tokenIdentifier:
+		switch {
+		case strings.EqualFold(t.val, "Inf") || strings.EqualFold(t.val, "NaN"):
+			return node{typ: valueTypeScalar, pos: t.pos}, nil
+		case isAggregator(t.val):
+			return p.parseAggregation(t)
+		case p.is("("):
+			return p.parseCall(t)
+		case p.is("{"):
+			p.next()
+			if err := p.parseLabelMatchers(t, true); err != nil {
+				return node{}, err
+			}
+
+			return node{typ: valueTypeVector, kind: exprKindVectorSelector, pos: t.pos}, nil
+		default:
+			if binaryPrecedence(t) > 0 || isKeyword(t.val) {
+				return node{}, unexpected(t, "expression")
+			}
+
+			return node{typ: valueTypeVector, kind: exprKindVectorSelector, pos: t.pos}, nil
+		}
+	}
+
+	return node{}, unexpected(t, "expression")
+}
+
+func isAggregator(name string) bool {
+	_, ok := aggregators[name]
+
+	return ok
+}
+
+func (p *parser) parseAggregation(name token) (node, error) {
+	var grouped bool
+	if p.is("by") || p.is("without") {
+		p.next()
+		if err := p.parseLabelList(); err != nil {
+			return node{}, err
+		}
+		grouped = true
+	}
+
+	args, err := p.parseArguments()
+	if err != nil {
+		return node{}, err
+	}
+
+	var argTypes []valueType
+	if paramType := aggregators[name.val]; paramType != valueTypeNone {
+		argTypes = append(argTypes, paramType)
+	}
+	argTypes = append(argTypes, valueTypeVector)
+
+	if len(args) != len(argTypes) {
+		return node{}, fmt.Errorf("wrong number of arguments for aggregation %s at position %d, expected %d, got %d", name, name.pos, len(argTypes), len(args))
+	}
+
+	for i, arg := range args {
+		if arg.typ != argTypes[i] {
+			return node{}, typeMismatch(arg, fmt.Sprintf("argument %d of aggregation %s", i+1, name), argTypes[i])
+		}
+	}
+
+	if !grouped && (p.is("by") || p.is("without")) {
+		p.next()
+		if err := p.parseLabelList(); err != nil {
+			return node{}, err
+		}
+	}
+
+	return node{typ: valueTypeVector, pos: name.pos}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.val]
+	if !ok {
+		return node{}, fmt.Errorf("unknown function %s at position %d", name, name.pos)
+	}
+
+	args, err := p.parseArguments()
+	if err != nil {
+		return node{}, err
+	}
+
+	minArgs := len(fn.argTypes) - fn.optionalArgs
+	if len(args) < minArgs || (!fn.variadic && len(args) > len(fn.argTypes)) {
+		expected := strconv.Itoa(minArgs)
+		switch {
+		case fn.variadic:
+			expected = "at least " + expected
+		case fn.optionalArgs > 0:
+			expected = fmt.Sprintf("%d to %d", minArgs, len(fn.argTypes))
+		}
+
+		return node{}, fmt.Errorf("wrong number of arguments for function %s at position %d, expected %s, got %d", name, name.pos, expected, len(args))
+	}
+
+	for i, arg := range args {
+		expected := fn.argTypes[len(fn.argTypes)-1]
+		if i < len(fn.argTypes) {
+			expected = fn.argTypes[i]
+		}
+
+		if arg.typ != expected {
+			return node{}, typeMismatch(arg, fmt.Sprintf("argument %d of function %s", i+1, name), expected)
+		}
+	}
+
+	return node{typ: fn.returnType, pos: name.pos}, nil
+}
+
+func (p *parser) parseArguments() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	if p.is(")") {
+		p.next()
+		return nil, nil
+	}
+
+	var args []node
+
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+
+		t := p.next()
+		switch {
+		case t.typ == tokenOperator && t.val == ")":
+			return args, nil
+		case t.typ == tokenOperator && t.val == ",":
+		default:
+			return nil, unexpected(t, `"," or ")"`)
+		}
+	}
+}
+
+func (p *parser) parseLabelList() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for !p.is(")") {
+		t := p.next()
+		if t.typ != tokenIdentifier || !labelNameRegexp.MatchString(t.val) {
+			return unexpected(t, "label name")
+		}
+
+		if p.is(",") {
+			p.next()
+		} else if !p.is(")") {
+			return unexpected(p.peek(), `"," or ")"`)
+		}
+	}
+	p.next()
+
+	return nil
+}
+
+// parseLabelMatchers parses the label matchers of a vector selector after the opening brace.
+func (p *parser) parseLabelMatchers(start token, named bool) error {
+	// selectors must not match every series
+	nonEmpty := named
+
+	for !p.is("}") {
+		name := p.next()
+		if name.typ != tokenIdentifier || !labelNameRegexp.MatchString(name.val) {
+			return unexpected(name, "label name")
+		}
+
+		op := p.next()
+		if op.typ != tokenOperator || (op.val != "=" && op.val != "!=" && op.val != "=~" && op.val != "!~") {
+			return unexpected(op, "label matching operator")
+		}
+
+		value := p.next()
+		if value.typ != tokenString {
+			return unexpected(value, "string")
+		}
+
+		matchesEmpty, err := matchesEmptyString(op.val, value)
+		if err != nil {
+			return err
+		}
+
+		if !matchesEmpty {
+			nonEmpty = true
+		}
+
+		if p.is(",") {
+			p.next()
+		} else if !p.is("}") {
+			return unexpected(p.peek(), `"," or "}"`)
+		}
+	}
+	p.next()
+
+	if !nonEmpty {
+		return fmt.Errorf("vector selector at position %d must contain at least one non-empty matcher", start.pos)
+	}
+
+	return nil
+}
+
+// matchesEmptyString tells whether a label matcher matches series without the label.
+func matchesEmptyString(op string, value token) (bool, error) {
+	unquoted, err := unquote(value.val)
+	if err != nil {
+		return false, fmt.Errorf("invalid string %s at position %d: %s", value, value.pos, err)
+	}
+
+	switch op {
+	case "=":
+		return unquoted == "", nil
+	case "!=":
+		return unquoted != "", nil
+	}
+
+	re, err := regexp.Compile("^(?:" + unquoted + ")$")
+	if err != nil {
+		return false, fmt.Errorf("invalid regular expression %s at position %d: %s", value, value.pos, err)
+	}
+
+	if op == "=~" {
+		return re.MatchString(""), nil
+	}
+
+	return !re.MatchString(""), nil
+}
+
+func unquote(val string) (string, error) {
+	if val[0] == '\'' {
+		return strconv.Unquote(`"` + strings.ReplaceAll(val[1:len(val)-1], `"`, `\"`) + `"`)
+	}
+
+	return strconv.Unquote(val)
+}
+
+func (p *parser) parsePostfix(n node) (node, error) {
+	for {
+		switch {
+		case p.is("["):
+			open := p.next()
+			if err := p.expectDuration(); err != nil {
+				return node{}, err
+			}
+
+			if p.is(":") {
+				// subquery with an optional resolution
+				p.next()
+				if p.peek().typ == tokenDuration {
+					p.next()
+				}
+
+				if n.typ != valueTypeVector {
+					return node{}, typeMismatch(n, "subquery expression", valueTypeVector)
+				}
+
+				n = node{typ: valueTypeMatrix, kind: exprKindSubquery, pos: n.pos}
+			} else {
+				if n.kind != exprKindVectorSelector || n.offset {
+					return node{}, fmt.Errorf("range at position %d is only allowed for vector selectors", open.pos)
+				}
+
+				n = node{typ: valueTypeMatrix, kind: exprKindMatrixSelector, pos: n.pos}
+			}
+
+			if err := p.expect("]"); err != nil {
+				return node{}, err
+			}
+
+		case p.is("offset"):
+			t := p.next()
+			if n.kind == exprKindOther || n.offset {
+				return node{}, fmt.Errorf("offset modifier at position %d must follow a selector or a subquery", t.pos)
+			}
+
+			if p.is("-") {
+				return node{}, fmt.Errorf("negative offset at position %d is not supported", p.peek().pos)
+			}
+
+			if err := p.expectDuration(); err != nil {
+				return node{}, err
+			}
+
+			n.offset = true
+
+		case p.is("@"):
+			return node{}, fmt.Errorf("@ modifier at position %d is not supported", p.peek().pos)
+
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) expectDuration() error {
+	if t := p.next(); t.typ != tokenDuration {
+		return unexpected(t, "duration")
+	}
+
+	return nil
+}
+
+func isKeyword(val string) bool {
+	switch val {
+	case "bool", "on", "ignoring", "group_left", "group_right", "by", "without", "offset":
+		return true
+	default:
+		return false
+	}
+}
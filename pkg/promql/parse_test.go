@@ -0,0 +1,134 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"up",
+		"up == 0",
+		"1 + 2 * 3 ^ 2 ^ 2",
+		"-1e-3",
+		"0x1F > bool 3",
+		"Inf",
+		`"string literal"`,
+		`http_requests_total{job="api", method!="GET", path=~"/api/.+", code!~'5..',}`,
+		`{__name__="up"}`,
+		"rate(http_requests_total[5m])",
+		"rate(http_requests_total[1h30m] offset 1d)",
+		"max_over_time(deriv(rate(x[1m])[5m:1m])[10m:])",
+		"sum by (job, instance) (rate(node_cpu_seconds_total{mode!=\"idle\"}[5m]))",
+		"sum(rate(x[5m])) without (instance)",
+		"topk(5, sum(x) by (job))",
+		"count_values(\"version\", build_version)",
+		"histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))",
+		"a / on (job) group_left (instance) b",
+		"a * ignoring (code) group_right b",
+		"a and b or c unless d",
+		"(up{job=\"node\"} == 0) # node is down",
+		"time() - process_start_time_seconds > 3600",
+		"job:request_latency_seconds:mean5m > 0.5",
+		"absent(up{job=\"api\"})",
+		"vector(1)",
+		"scalar(sum(x)) * 2",
+		"label_replace(up, \"foo\", \"$1\", \"job\", \"(.*)\")",
+		"label_join(up, \"foo\", \",\", \"job\", \"instance\")",
+		"round(x)",
+		"round(x, 0.5)",
+		"day_of_week()",
+		"day_of_week(x)",
+		"quantile_over_time(0.9, x[5m])",
+		"x[5m] offset 1h",
+		"rate(x[5m])[30m:1m] offset 1h",
+		"1 == bool 1",
+		"{job=\"api\", code=\"\"}",
+	}
+
+	for _, expr := range valid {
+		assert.NoError(t, Validate(expr), expr)
+	}
+
+	invalid := []string{
+		"",
+		"   ",
+		"up ==",
+		"sum(",
+		"rate(x[5m]",
+		"x[5]",
+		"x[5m",
+		`x{job="api"`,
+		`x{job=api}`,
+		`x{job=="api"}`,
+		`x{job=~"(api"}`,
+		`x{"job"="api"}`,
+		"sum by job (x)",
+		"x offset",
+		"(a + b",
+		"a + b)",
+		"a b",
+		"\"unterminated",
+		"x $ y",
+		"5m",
+		"1.2.3",
+		"and",
+		"x @ now",
+		"x @ 1609746000",
+		"x @ end()",
+		"rat(x[5m])",
+		"rate(x)",
+		"rate(x[5m], 1)",
+		"abs(x[5m])",
+		"time(x)",
+		"day_of_week(x, y)",
+		"label_replace(x, \"a\")",
+		"label_join(x, \"a\")",
+		"histogram_quantile(x, y)",
+		"sum(x[5m])",
+		"sum(1, x)",
+		"topk(x, y)",
+		"topk(x)",
+		"count_values(1, x)",
+		"group(x)",
+		"atan2(x, y)",
+		"x atan2 y",
+		"(x)[5m]",
+		"rate(x[5m])[5m]",
+		"x offset 5m [5m]",
+		"1[5m:]",
+		"x[5m] + 1",
+		"-x[5m]",
+		`"a" + 1`,
+		"a and 1",
+		"1 == 1",
+		"a + bool b",
+		"a + on (job) 1",
+		"a and on (job) group_left b",
+		"x offset -5m",
+		"x offset 5m offset 1h",
+		"sum(x) offset 5m",
+		"{}",
+		`{job=""}`,
+		`{job=~".*"}`,
+	}
+
+	for _, expr := range invalid {
+		assert.Error(t, Validate(expr), expr)
+	}
+}
@@ -121,8 +121,20 @@ type WhitelistItem struct {
 }
 
 type ReleaseWhiteListItem struct {
-	Name   string `json:"name" binding:"required"`
-	Owner  string `json:"owner" binding:"required"`
+	Name string `json:"name" binding:"required"`
+
+	// Owner is the user who created the item
+	Owner string `json:"owner"`
+
 	Reason string `json:"reason"`
 	Regexp string `json:"regexp,omitempty"`
+
+	// ExpiresAt is the time the item is removed from the cluster at (never when empty)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Approver is the user who approved the exception (only set by approving an existing item)
+	Approver string `json:"approver,omitempty"`
+
+	// NotificationSecretID is the Slack secret used to notify the owner about the expiry
+	NotificationSecretID string `json:"notificationSecretId,omitempty"`
 }
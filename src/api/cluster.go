@@ -31,11 +31,15 @@ import (
 
 	clusterAuth "github.com/banzaicloud/pipeline/internal/cluster/auth"
 	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
 	eksdriver "github.com/banzaicloud/pipeline/internal/cluster/distribution/eks/eksprovider/driver"
+	intClusterK8s "github.com/banzaicloud/pipeline/internal/cluster/kubernetes"
 	"github.com/banzaicloud/pipeline/internal/cluster/resourcesummary"
 	"github.com/banzaicloud/pipeline/internal/global"
 	azureDriver "github.com/banzaicloud/pipeline/internal/providers/azure/pke/driver"
+	hostsDriver "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver"
 	vsphereDriver "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/driver"
+	"github.com/banzaicloud/pipeline/internal/quota"
 	"github.com/banzaicloud/pipeline/internal/secret/restricted"
 	"github.com/banzaicloud/pipeline/pkg/cloudinfo"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
@@ -66,12 +70,16 @@ type ClusterAPI struct {
 	helmService        cluster.HelmService
 	authConfig         auth.Config
 	clientSecretGetter clusterAuth.ClusterClientSecretGetter
+	quotaEnforcer      quota.Enforcer
+
+	certificateExpiries clustercert.ExpiryGetter
 }
 
 type ClusterCreators struct {
 	PKEOnAzure   azureDriver.ClusterCreator
 	EKSAmazon    eksdriver.EksClusterCreator
 	PKEOnVsphere vsphereDriver.VspherePKEClusterCreator
+	PKEOnHosts   hostsDriver.PKEOnHostsClusterCreator
 }
 
 type ClusterDeleters struct {
@@ -83,6 +91,7 @@ type ClusterUpdaters struct {
 	PKEOnAzure   azureDriver.ClusterUpdater
 	EKSAmazon    eksdriver.EksClusterUpdater
 	PKEOnVsphere vsphereDriver.ClusterUpdater
+	PKEOnHosts   hostsDriver.ClusterUpdater
 }
 
 // NewClusterAPI returns a new ClusterAPI instance.
@@ -100,6 +109,8 @@ func NewClusterAPI(
 	helmService cluster.HelmService,
 	authConfig auth.Config,
 	clientSecretGetter clusterAuth.ClusterClientSecretGetter,
+	quotaEnforcer quota.Enforcer,
+	certificateExpiries clustercert.ExpiryGetter,
 ) *ClusterAPI {
 	return &ClusterAPI{
 		clusterManager:          clusterManager,
@@ -115,6 +126,8 @@ func NewClusterAPI(
 		helmService:             helmService,
 		authConfig:              authConfig,
 		clientSecretGetter:      clientSecretGetter,
+		quotaEnforcer:           quotaEnforcer,
+		certificateExpiries:     certificateExpiries,
 	}
 }
 
@@ -417,7 +430,7 @@ nodesloop:
 			continue
 		}
 		for _, taint := range node.Spec.Taints {
-			if taint.Key == "node.banzaicloud.io/draining" {
+			if taint.Key == intClusterK8s.DrainingTaintKey {
 				continue nodesloop
 			}
 		}
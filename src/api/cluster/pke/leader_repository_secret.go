@@ -0,0 +1,101 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Values of the secrets holding cluster leaders.
+const (
+	leaderSecretType     = "pkeleader"
+	leaderHostnameKey    = "hostname"
+	leaderIPKey          = "ip"
+	leaderSecretIDFormat = "pke-leader-%d-%d"
+)
+
+// SecretStoreLeaderRepository implements a LeaderRepository over a secret store.
+// It is used instead of VaultLeaderRepository when Pipeline runs without Vault.
+type SecretStoreLeaderRepository struct {
+	store secret.Store
+}
+
+// NewSecretStoreLeaderRepository returns a new SecretStoreLeaderRepository
+func NewSecretStoreLeaderRepository(store secret.Store) SecretStoreLeaderRepository {
+	return SecretStoreLeaderRepository{
+		store: store,
+	}
+}
+
+// GetLeader returns information about the leader of the specified cluster
+func (r SecretStoreLeaderRepository) GetLeader(organizationID, clusterID uint) (LeaderInfo, error) {
+	id := leaderSecretID(organizationID, clusterID)
+
+	model, err := r.store.Get(context.Background(), secret.SystemOrganizationID, id)
+	if errors.As(err, &secret.NotFoundError{}) {
+		return LeaderInfo{}, leaderNotFound{
+			path: id,
+		}
+	} else if err != nil {
+		return LeaderInfo{}, errors.WrapIf(err, "failed to read secret")
+	}
+
+	return LeaderInfo{
+		Hostname: model.Values[leaderHostnameKey],
+		IP:       model.Values[leaderIPKey],
+	}, nil
+}
+
+// SetLeader writes the given leader info for the specified cluster to the repository if it's not set yet
+func (r SecretStoreLeaderRepository) SetLeader(organizationID, clusterID uint, leaderInfo LeaderInfo) error {
+	model := secret.Model{
+		ID:   leaderSecretID(organizationID, clusterID),
+		Type: leaderSecretType,
+		Values: map[string]string{
+			leaderHostnameKey: leaderInfo.Hostname,
+			leaderIPKey:       leaderInfo.IP,
+		},
+	}
+
+	// only allow write if the key doesn't exist
+	err := r.store.Create(context.Background(), secret.SystemOrganizationID, model)
+	if errors.As(err, &secret.AlreadyExistsError{}) {
+		return errors.WrapIf(leaderSetError{}, "failed to write leader secret")
+	}
+
+	return errors.WrapIf(err, "failed to write leader secret")
+}
+
+func (r SecretStoreLeaderRepository) DeleteLeader(organizationID, clusterID uint) error {
+	id := leaderSecretID(organizationID, clusterID)
+
+	err := r.store.Delete(context.Background(), secret.SystemOrganizationID, id)
+	if errors.As(err, &secret.NotFoundError{}) {
+		return leaderNotFound{
+			path: id,
+		}
+	}
+
+	return errors.WrapIf(err, "failed to delete leader from repository")
+}
+
+func leaderSecretID(organizationID, clusterID uint) string {
+	return fmt.Sprintf(leaderSecretIDFormat, organizationID, clusterID)
+}
@@ -0,0 +1,64 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pke
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite" // SQLite driver used for integration test
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+)
+
+func TestSecretStoreLeaderRepository(t *testing.T) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	require.NoError(t, secretadapter.Migrate(db, common.NoopLogger{}))
+
+	store, err := secretadapter.NewSQLStore(db, bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	repository := NewSecretStoreLeaderRepository(store)
+
+	_, err = repository.GetLeader(1, 2)
+	assert.True(t, isLeaderNotFound(err))
+
+	leader := LeaderInfo{Hostname: "master-0", IP: "10.0.0.1"}
+
+	require.NoError(t, repository.SetLeader(1, 2, leader))
+
+	err = repository.SetLeader(1, 2, LeaderInfo{Hostname: "master-1", IP: "10.0.0.2"})
+	assert.True(t, isLeaderSet(err), "the leader can only be set once")
+
+	actual, err := repository.GetLeader(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, leader, actual)
+
+	_, err = repository.GetLeader(1, 3)
+	assert.True(t, isLeaderNotFound(err), "leaders are scoped to their cluster")
+
+	require.NoError(t, repository.DeleteLeader(1, 2))
+
+	_, err = repository.GetLeader(1, 2)
+	assert.True(t, isLeaderNotFound(err))
+}
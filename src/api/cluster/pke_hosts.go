@@ -0,0 +1,103 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/banzaicloud/pipeline/.gen/pipeline/pipeline"
+	intPKE "github.com/banzaicloud/pipeline/internal/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke"
+	"github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver"
+	"github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+const PKEOnHosts = pke.PKEOnHosts
+
+type CreatePKEOnHostsClusterRequest pipeline.CreatePkeOnHostsClusterRequest
+
+func (req CreatePKEOnHostsClusterRequest) ToPKEOnHostsClusterCreationParams(organizationID, userID uint) driver.PKEOnHostsClusterCreationParams {
+	return driver.PKEOnHostsClusterCreationParams{
+		Name:           req.Name,
+		OrganizationID: organizationID,
+		CreatedBy:      userID,
+		ScaleOptions: cluster.ScaleOptions{
+			Enabled:             req.ScaleOptions.Enabled,
+			DesiredCpu:          req.ScaleOptions.DesiredCpu,
+			DesiredMem:          req.ScaleOptions.DesiredMem,
+			DesiredGpu:          int(req.ScaleOptions.DesiredGpu),
+			OnDemandPct:         int(req.ScaleOptions.OnDemandPct),
+			Excludes:            req.ScaleOptions.Excludes,
+			KeepDesiredCapacity: req.ScaleOptions.KeepDesiredCapacity,
+		},
+		SecretID: req.SecretId,
+		Kubernetes: intPKE.Kubernetes{
+			Version: req.Kubernetes.Version,
+			RBAC:    req.Kubernetes.Rbac,
+			Network: intPKE.Network{
+				ServiceCIDR:    req.Kubernetes.Network.ServiceCIDR,
+				PodCIDR:        req.Kubernetes.Network.PodCIDR,
+				Provider:       req.Kubernetes.Network.Provider,
+				ProviderConfig: req.Kubernetes.Network.ProviderConfig,
+			},
+			CRI: intPKE.CRI{
+				Runtime:       req.Kubernetes.Cri.Runtime,
+				RuntimeConfig: req.Kubernetes.Cri.RuntimeConfig,
+			},
+			OIDC: intPKE.OIDC{
+				Enabled: req.Kubernetes.Oidc.Enabled,
+			},
+		},
+		NodePools: hostsRequestToClusterNodepools(req.Nodepools, userID),
+		HTTPProxy: intPKE.HTTPProxy{
+			HTTP:       clientPKEClusterHTTPProxyOptionsToPKEHTTPProxyOptions(req.Proxy.Http),
+			HTTPS:      clientPKEClusterHTTPProxyOptionsToPKEHTTPProxyOptions(req.Proxy.Https),
+			Exceptions: req.Proxy.Exceptions,
+		},
+		APIServerAddress: req.ApiServerAddress,
+	}
+}
+
+type UpdatePKEOnHostsClusterRequest pipeline.UpdatePkeOnHostsClusterRequest
+
+func (req UpdatePKEOnHostsClusterRequest) ToPKEOnHostsClusterUpdateParams(clusterID, userID uint) driver.PKEOnHostsClusterUpdateParams {
+	return driver.PKEOnHostsClusterUpdateParams{
+		ClusterID: clusterID,
+		NodePools: hostsRequestToClusterNodepools(req.Nodepools, userID),
+	}
+}
+
+func hostsRequestToClusterNodepools(request []pipeline.PkeOnHostsNodePool, userID uint) []driver.NodePool {
+	nodepools := make([]driver.NodePool, len(request))
+	for i, node := range request {
+		hosts := make([]driver.Host, len(node.Hosts))
+		for j, host := range node.Hosts {
+			hosts[j] = driver.Host{
+				Address:     host.Address,
+				Port:        int(host.Port),
+				SSHSecretID:           host.SshSecretId,
+				SSHHostKeyFingerprint: host.SshHostKeyFingerprint,
+				Labels:                host.Labels,
+			}
+		}
+
+		nodepools[i] = driver.NodePool{
+			CreatedBy: userID,
+			Name:      node.Name,
+			Roles:     node.Roles,
+			Labels:    node.Labels,
+			Hosts:     hosts,
+		}
+	}
+	return nodepools
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/banzaicloud/pipeline/.gen/pipeline/pipeline"
 	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	"github.com/banzaicloud/pipeline/internal/quota"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	clusterAPI "github.com/banzaicloud/pipeline/src/api/cluster"
@@ -112,6 +113,13 @@ func (a *ClusterAPI) CreateCluster(c *gin.Context) {
 			createClusterRequest.SecretId = secret.GenerateSecretIDFromName(createClusterRequest.SecretName)
 		}
 
+		quotaRequest := nodePoolQuotaRequest(createClusterNodePools(&createClusterRequest))
+		quotaRequest[quota.ResourceClusters] = 1
+
+		if !enforceQuota(ctx, c, a.quotaEnforcer, orgID, quotaRequest) {
+			return
+		}
+
 		commonCluster, err := a.createCluster(ctx, &createClusterRequest, orgID, userID, createClusterRequest.PostHooks)
 		if err != nil {
 			c.JSON(err.Code, err)
@@ -156,6 +164,14 @@ func (a *ClusterAPI) CreateCluster(c *gin.Context) {
 			return
 		}
 		req.SecretId = secretID
+
+		quotaRequest := nodePoolQuotaRequest(vspherePKENodePools(req.Nodepools))
+		quotaRequest[quota.ResourceClusters] = 1
+
+		if !enforceQuota(ctx, c, a.quotaEnforcer, orgID, quotaRequest) {
+			return
+		}
+
 		// TODO legacy posthook support if needed
 		params := req.ToVspherePKEClusterCreationParams(orgID, userID)
 		a.logger.Infof("request: %+v\n\n\nparams: %+v\n\n", req, params)
@@ -165,12 +181,41 @@ func (a *ClusterAPI) CreateCluster(c *gin.Context) {
 			return
 		}
 		cluster = vsphereCluster
+	case clusterAPI.PKEOnHosts:
+		var req clusterAPI.CreatePKEOnHostsClusterRequest
+		if ok := a.parseRequest(c, requestBody, &req); !ok {
+			return
+		}
+		req.SecretId = secretID
+
+		quotaRequest := nodePoolQuotaRequest(hostsPKENodePools(req.Nodepools))
+		quotaRequest[quota.ResourceClusters] = 1
+
+		if !enforceQuota(ctx, c, a.quotaEnforcer, orgID, quotaRequest) {
+			return
+		}
+
+		params := req.ToPKEOnHostsClusterCreationParams(orgID, userID)
+		hostsCluster, err := a.clusterCreators.PKEOnHosts.Create(ctx, params)
+		if err = errors.WrapIf(err, "failed to create cluster from request"); err != nil {
+			a.handleCreationError(c, err)
+			return
+		}
+		cluster = hostsCluster
 	case clusterAPI.PKEOnAzure:
 		var req clusterAPI.CreatePKEOnAzureClusterRequest
 		if ok := a.parseRequest(c, requestBody, &req); !ok {
 			return
 		}
 		req.SecretId = secretID
+
+		quotaRequest := nodePoolQuotaRequest(azurePKENodePools(req.Nodepools))
+		quotaRequest[quota.ResourceClusters] = 1
+
+		if !enforceQuota(ctx, c, a.quotaEnforcer, orgID, quotaRequest) {
+			return
+		}
+
 		params := req.ToAzurePKEClusterCreationParams(orgID, userID)
 		azurePKECluster, err := a.clusterCreators.PKEOnAzure.Create(ctx, params)
 		if err = errors.WrapIf(err, "failed to create cluster from request"); err != nil {
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/banzaicloud/pipeline/internal/cluster/clustercert"
 	"github.com/banzaicloud/pipeline/internal/cluster/oidc"
 	"github.com/banzaicloud/pipeline/internal/cluster/resourcesummary"
 	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
@@ -134,6 +135,15 @@ func (a *ClusterAPI) GetCluster(c *gin.Context) {
 		}
 	}
 
+	if clusterStatus.Distribution == pkgCluster.PKE {
+		expiry, err := a.certificateExpiries.GetExpiry(c.Request.Context(), commonCluster.GetID())
+		if err != nil {
+			errorHandler.Handle(err)
+		} else {
+			response.Certificates = expiry
+		}
+	}
+
 	ready, err := commonCluster.IsReady()
 	if err != nil {
 		err = errors.WithMessage(err, "failed to check if the cluster is ready")
@@ -277,6 +287,9 @@ type GetClusterResponse struct {
 	NodePools    map[string]GetClusterNodePool `json:"nodePools,omitempty"`
 	TotalSummary *ResourceSummary              `json:"totalSummary,omitempty"`
 
+	// Control plane certificate expiry of PKE clusters
+	Certificates *clustercert.Expiry `json:"certificates,omitempty"`
+
 	CreatedAt   time.Time `json:"createdAt,omitempty"`
 	CreatorName string    `json:"creatorName,omitempty"`
 	CreatorID   uint      `json:"creatorId,omitempty"`
@@ -0,0 +1,112 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"emperror.dev/emperror"
+	"emperror.dev/errors"
+	"github.com/gin-gonic/gin"
+
+	"github.com/banzaicloud/pipeline/internal/cluster/clusteraccess"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/src/auth"
+)
+
+// MemberAccessEnsurer makes sure that organization members are authorized in a cluster.
+type MemberAccessEnsurer interface {
+	Ensure(ctx context.Context, clusterID uint) error
+}
+
+// NewProxyImpersonationMiddleware returns a middleware that makes the Kubernetes API proxy act on behalf of the
+// current user: the organization role of the user is mapped to Kubernetes groups through impersonation.
+// Members are mapped to a group that needs a binding in the cluster, so it is ensured before proxying their requests.
+func NewProxyImpersonationMiddleware(roleSource auth.RoleSource, memberAccess MemberAccessEnsurer, errorHandler emperror.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Clients must never choose whom the cluster credentials of Pipeline impersonate
+		clusteraccess.RemoveImpersonation(c.Request.Header)
+
+		user := auth.GetCurrentUser(c.Request)
+		org := auth.GetCurrentOrganization(c.Request)
+
+		// Virtual users and service accounts keep using the cluster credentials of Pipeline
+		if user == nil || user.ID == 0 || org == nil {
+			return
+		}
+
+		role, member, err := roleSource.FindUserRole(c.Request.Context(), org.ID, user.ID)
+		if err != nil {
+			errorHandler.Handle(errors.WrapIfWithDetails(err, "failed to get user role", "userId", user.ID, "organizationId", org.ID))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Error proxying to cluster",
+				Error:   "failed to get user role",
+			})
+
+			return
+		}
+
+		if !member {
+			c.AbortWithStatus(http.StatusForbidden)
+
+			return
+		}
+
+		login := user.Login
+
+		// Cluster scoped tokens do not carry the login name of the user
+		if login == "" {
+			u, err := auth.GetUserById(user.ID)
+			if err != nil {
+				errorHandler.Handle(errors.WrapIfWithDetails(err, "failed to get user", "userId", user.ID))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+					Code:    http.StatusInternalServerError,
+					Message: "Error proxying to cluster",
+					Error:   "failed to get user",
+				})
+
+				return
+			}
+
+			login = u.Login
+		}
+
+		if role == auth.RoleMember {
+			clusterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+			if err != nil {
+				c.AbortWithStatus(http.StatusBadRequest)
+
+				return
+			}
+
+			err = memberAccess.Ensure(c.Request.Context(), uint(clusterID))
+			if err != nil {
+				errorHandler.Handle(err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+					Code:    http.StatusInternalServerError,
+					Message: "Error proxying to cluster",
+					Error:   "failed to ensure member access",
+				})
+
+				return
+			}
+		}
+
+		clusteraccess.Impersonate(c.Request.Header, clusteraccess.UserName(login), clusteraccess.GroupsForRole(role))
+	}
+}
@@ -55,6 +55,9 @@ func (a *ClusterAPI) UpdateCluster(c *gin.Context) {
 				})
 				return
 			}
+			if !a.enforceNodePoolUpdateQuota(c, commonCluster, azurePKENodePools(updateRequest.Nodepools), false) {
+				return
+			}
 			params := updateRequest.ToAzurePKEClusterUpdateParams(commonCluster.GetID(), auth.GetCurrentUser(c.Request).ID)
 			err = a.clusterUpdaters.PKEOnAzure.Update(c, params)
 		case pkgCluster.Vsphere:
@@ -68,8 +71,27 @@ func (a *ClusterAPI) UpdateCluster(c *gin.Context) {
 				})
 				return
 			}
+			if !a.enforceNodePoolUpdateQuota(c, commonCluster, vspherePKENodePools(updateRequest.Nodepools), false) {
+				return
+			}
 			params := updateRequest.ToVspherePKEClusterUpdateParams(commonCluster.GetID(), auth.GetCurrentUser(c.Request).ID)
 			err = a.clusterUpdaters.PKEOnVsphere.Update(c, params)
+		case pkgCluster.Hosts:
+			var updateRequest *apicluster.UpdatePKEOnHostsClusterRequest
+			if err := c.BindJSON(&updateRequest); err != nil {
+				a.logger.Errorf("Error parsing request: %s", err.Error())
+				c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+					Code:    http.StatusBadRequest,
+					Message: "Error parsing request",
+					Error:   err.Error(),
+				})
+				return
+			}
+			if !a.enforceNodePoolUpdateQuota(c, commonCluster, hostsPKENodePools(updateRequest.Nodepools), false) {
+				return
+			}
+			params := updateRequest.ToPKEOnHostsClusterUpdateParams(commonCluster.GetID(), auth.GetCurrentUser(c.Request).ID)
+			err = a.clusterUpdaters.PKEOnHosts.Update(c, params)
 		}
 	} else {
 		// bind request body to UpdateClusterRequest struct
@@ -84,6 +106,10 @@ func (a *ClusterAPI) UpdateCluster(c *gin.Context) {
 			return
 		}
 
+		if !a.enforceNodePoolUpdateQuota(c, commonCluster, updateClusterNodePools(updateRequest), false) {
+			return
+		}
+
 		ctx := ginutils.Context(context.Background(), c)
 
 		if _, ok := commonCluster.(*cluster.EKSCluster); ok {
@@ -157,6 +183,17 @@ func (a *ClusterAPI) UpdateNodePools(c *gin.Context) {
 		return
 	}
 
+	desiredNodePools := make(map[string]int, len(updateRequest.NodePools))
+	for name, nodePool := range updateRequest.NodePools {
+		if nodePool != nil {
+			desiredNodePools[name] = nodePool.Count
+		}
+	}
+
+	if !a.enforceNodePoolUpdateQuota(c, commonCluster, desiredNodePools, true) {
+		return
+	}
+
 	updateCtx := cluster.UpdateContext{
 		OrganizationID: auth.GetCurrentOrganization(c.Request).ID,
 		UserID:         auth.GetCurrentUser(c.Request).ID,
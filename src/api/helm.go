@@ -15,6 +15,7 @@
 package api
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/http"
@@ -32,7 +33,10 @@ import (
 	rls "k8s.io/helm/pkg/proto/hapi/services"
 	"k8s.io/helm/pkg/repo"
 
+	"github.com/banzaicloud/pipeline/internal/global/globalquota"
 	intlHelm "github.com/banzaicloud/pipeline/internal/helm"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	"github.com/banzaicloud/pipeline/internal/quota"
 	pkgCommmon "github.com/banzaicloud/pipeline/pkg/common"
 	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
 	"github.com/banzaicloud/pipeline/src/auth"
@@ -84,6 +88,15 @@ func CreateDeployment(c *gin.Context) {
 		return
 	}
 
+	if !parsedRequest.dryRun {
+		ctx := ginutils.Context(context.Background(), c)
+		organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+		if !enforceQuota(ctx, c, globalquota.Enforcer(), organizationID, quota.Request{quota.ResourceReleases: 1}) {
+			return
+		}
+	}
+
 	installOptions := []k8sHelm.InstallOption{
 		k8sHelm.InstallWait(parsedRequest.wait),
 		k8sHelm.ValueOverrides(parsedRequest.values),
@@ -0,0 +1,289 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/banzaicloud/pipeline/.gen/pipeline/pipeline"
+	apphttp "github.com/banzaicloud/pipeline/internal/platform/appkit/transport/http"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	"github.com/banzaicloud/pipeline/internal/quota"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/cluster/pke"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/problems"
+	"github.com/banzaicloud/pipeline/src/cluster"
+)
+
+// enforceQuota checks a request against the quotas of an organization.
+// It replies with a problem and returns false when the request cannot be fulfilled.
+func enforceQuota(ctx context.Context, c *gin.Context, enforcer quota.Enforcer, organizationID uint, request quota.Request) bool {
+	err := enforcer.Enforce(ctx, organizationID, request)
+	if err == nil {
+		return true
+	}
+
+	if apphttp.IsForbiddenError(err) {
+		c.Header("Content-Type", problems.ProblemMediaType)
+		c.AbortWithStatusJSON(http.StatusForbidden, problems.NewDetailedProblem(http.StatusForbidden, err.Error()))
+
+		return false
+	}
+
+	errorHandler.Handle(err)
+
+	c.AbortWithStatusJSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "failed to check quotas",
+		Error:   err.Error(),
+	})
+
+	return false
+}
+
+// enforceNodePoolUpdateQuota checks the desired node pools of a cluster against the quotas of its organization.
+// When partial is true, node pools missing from the desired set are kept unchanged.
+func (a *ClusterAPI) enforceNodePoolUpdateQuota(c *gin.Context, commonCluster cluster.CommonCluster, desired map[string]int, partial bool) bool {
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		errorHandler.Handle(err)
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to get cluster status",
+			Error:   err.Error(),
+		})
+
+		return false
+	}
+
+	if partial {
+		nodePools := make(map[string]int, len(status.NodePools))
+
+		for name, nodePool := range status.NodePools {
+			if nodePool != nil {
+				nodePools[name] = nodePool.Count
+			}
+		}
+
+		for name, count := range desired {
+			nodePools[name] = count
+		}
+
+		desired = nodePools
+	}
+
+	ctx := ginutils.Context(context.Background(), c)
+
+	return enforceQuota(ctx, c, a.quotaEnforcer, commonCluster.GetOrganizationId(), nodePoolUpdateQuotaRequest(status.NodePools, desired))
+}
+
+// nodePoolQuotaRequest returns the quota request for a set of new node pools (keyed by name, valued by node count).
+func nodePoolQuotaRequest(nodePools map[string]int) quota.Request {
+	request := quota.Request{
+		quota.ResourceNodePools: len(nodePools),
+	}
+
+	for _, count := range nodePools {
+		request[quota.ResourceNodes] += count
+	}
+
+	return request
+}
+
+// nodePoolUpdateQuotaRequest returns the quota request for replacing the node pools of a cluster.
+func nodePoolUpdateQuotaRequest(current map[string]*pkgCluster.NodePoolStatus, desired map[string]int) quota.Request {
+	request := nodePoolQuotaRequest(desired)
+
+	request[quota.ResourceNodePools] -= len(current)
+
+	for _, nodePool := range current {
+		if nodePool != nil {
+			request[quota.ResourceNodes] -= nodePool.Count
+		}
+	}
+
+	return request
+}
+
+// createClusterNodePools returns the node count of every node pool in a legacy cluster creation request.
+func createClusterNodePools(request *pkgCluster.CreateClusterRequest) map[string]int {
+	nodePools := make(map[string]int)
+
+	if request.Properties == nil {
+		return nodePools
+	}
+
+	properties := request.Properties
+
+	switch {
+	case properties.CreateClusterACK != nil:
+		for name, np := range properties.CreateClusterACK.NodePools {
+			if np != nil {
+				nodePools[name] = np.MinCount
+			}
+		}
+
+	case properties.CreateClusterAKS != nil:
+		for name, np := range properties.CreateClusterAKS.NodePools {
+			if np != nil {
+				nodePools[name] = np.Count
+			}
+		}
+
+	case properties.CreateClusterEKS != nil:
+		for name, np := range properties.CreateClusterEKS.NodePools {
+			if np != nil {
+				nodePools[name] = maxInt(np.Count, np.MinCount)
+			}
+		}
+
+	case properties.CreateClusterGKE != nil:
+		for name, np := range properties.CreateClusterGKE.NodePools {
+			if np != nil {
+				nodePools[name] = maxInt(np.Count, np.MinCount)
+			}
+		}
+
+	case properties.CreateClusterOKE != nil:
+		for name, np := range properties.CreateClusterOKE.NodePools {
+			if np != nil {
+				nodePools[name] = int(np.Count)
+			}
+		}
+
+	case properties.CreateClusterPKE != nil:
+		for _, np := range properties.CreateClusterPKE.NodePools {
+			nodePools[np.Name] = pkeNodePoolSize(np.Hosts, np.ProviderConfig)
+		}
+	}
+
+	return nodePools
+}
+
+// pkeNodePoolSize returns the desired size of an auto scaling group or the number of hosts of a PKE node pool.
+func pkeNodePoolSize(hosts pke.Hosts, providerConfig map[string]interface{}) int {
+	if asg, ok := providerConfig["autoScalingGroup"].(map[string]interface{}); ok {
+		if size, ok := asg["size"].(map[string]interface{}); ok {
+			return intValue(size["desired"])
+		}
+	}
+
+	return len(hosts)
+}
+
+// updateClusterNodePools returns the node count of every node pool in a legacy cluster update request.
+func updateClusterNodePools(request *pkgCluster.UpdateClusterRequest) map[string]int {
+	nodePools := make(map[string]int)
+
+	switch {
+	case request.ACK != nil:
+		for name, np := range request.ACK.NodePools {
+			if np != nil {
+				nodePools[name] = np.MinCount
+			}
+		}
+
+	case request.AKS != nil:
+		for name, np := range request.AKS.NodePools {
+			if np != nil {
+				nodePools[name] = np.Count
+			}
+		}
+
+	case request.EKS != nil:
+		for name, np := range request.EKS.NodePools {
+			if np != nil {
+				nodePools[name] = maxInt(np.Count, np.MinCount)
+			}
+		}
+
+	case request.GKE != nil:
+		for name, np := range request.GKE.NodePools {
+			if np != nil {
+				nodePools[name] = maxInt(np.Count, np.MinCount)
+			}
+		}
+
+	case request.OKE != nil:
+		for name, np := range request.OKE.NodePools {
+			if np != nil {
+				nodePools[name] = int(np.Count)
+			}
+		}
+
+	case request.PKE != nil:
+		for name, np := range request.PKE.NodePools {
+			nodePools[name] = np.Count
+		}
+	}
+
+	return nodePools
+}
+
+func azurePKENodePools(nodePools []pipeline.PkeOnAzureNodePool) map[string]int {
+	sizes := make(map[string]int, len(nodePools))
+
+	for _, np := range nodePools {
+		sizes[np.Name] = int(np.Count)
+	}
+
+	return sizes
+}
+
+func vspherePKENodePools(nodePools []pipeline.PkeOnVsphereNodePool) map[string]int {
+	sizes := make(map[string]int, len(nodePools))
+
+	for _, np := range nodePools {
+		sizes[np.Name] = int(np.Size)
+	}
+
+	return sizes
+}
+
+func hostsPKENodePools(nodePools []pipeline.PkeOnHostsNodePool) map[string]int {
+	sizes := make(map[string]int, len(nodePools))
+
+	for _, np := range nodePools {
+		sizes[np.Name] = len(np.Hosts)
+	}
+
+	return sizes
+}
+
+func intValue(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
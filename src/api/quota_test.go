@@ -0,0 +1,85 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/pipeline/internal/quota"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/cluster/aks"
+	"github.com/banzaicloud/pipeline/pkg/cluster/pke"
+)
+
+func TestCreateClusterNodePools(t *testing.T) {
+	t.Run("AKS", func(t *testing.T) {
+		request := &pkgCluster.CreateClusterRequest{
+			Properties: &pkgCluster.CreateClusterProperties{
+				CreateClusterAKS: &aks.CreateClusterAKS{
+					NodePools: map[string]*aks.NodePoolCreate{
+						"pool1": {Count: 3},
+						"pool2": {Count: 1},
+					},
+				},
+			},
+		}
+
+		assert.Equal(t, map[string]int{"pool1": 3, "pool2": 1}, createClusterNodePools(request))
+	})
+
+	t.Run("PKE", func(t *testing.T) {
+		request := &pkgCluster.CreateClusterRequest{
+			Properties: &pkgCluster.CreateClusterProperties{
+				CreateClusterPKE: &pke.CreateClusterPKE{
+					NodePools: pke.NodePools{
+						{
+							Name: "master",
+							ProviderConfig: map[string]interface{}{
+								"autoScalingGroup": map[string]interface{}{
+									"size": map[string]interface{}{"desired": float64(1)},
+								},
+							},
+						},
+						{
+							Name:  "workers",
+							Hosts: pke.Hosts{{}, {}},
+						},
+					},
+				},
+			},
+		}
+
+		assert.Equal(t, map[string]int{"master": 1, "workers": 2}, createClusterNodePools(request))
+	})
+}
+
+func TestNodePoolQuotaRequest(t *testing.T) {
+	request := nodePoolQuotaRequest(map[string]int{"pool1": 3, "pool2": 1})
+
+	assert.Equal(t, quota.Request{quota.ResourceNodePools: 2, quota.ResourceNodes: 4}, request)
+}
+
+func TestNodePoolUpdateQuotaRequest(t *testing.T) {
+	current := map[string]*pkgCluster.NodePoolStatus{
+		"pool1": {Count: 3},
+		"pool2": {Count: 1},
+	}
+
+	request := nodePoolUpdateQuotaRequest(current, map[string]int{"pool1": 5, "pool3": 2})
+
+	assert.Equal(t, quota.Request{quota.ResourceNodePools: 0, quota.ResourceNodes: 3}, request)
+}
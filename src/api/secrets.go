@@ -27,7 +27,12 @@ import (
 	"github.com/banzaicloud/pipeline/.gen/pipeline/pipeline"
 	"github.com/banzaicloud/pipeline/internal/cluster/clusteradapter"
 	"github.com/banzaicloud/pipeline/internal/global"
+	"github.com/banzaicloud/pipeline/internal/global/globalquota"
+	"github.com/banzaicloud/pipeline/internal/global/globalsecretusage"
+	ginutils "github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	"github.com/banzaicloud/pipeline/internal/quota"
 	"github.com/banzaicloud/pipeline/internal/secret/restricted"
+	"github.com/banzaicloud/pipeline/internal/secret/usage"
 	"github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/banzaicloud/pipeline/pkg/providers"
 	"github.com/banzaicloud/pipeline/src/auth"
@@ -93,6 +98,14 @@ func AddSecrets(c *gin.Context) {
 
 	createSecretRequest.Verify = validate
 
+	if !ContainsString(createSecretRequest.Tags, secret.TagBanzaiHidden) {
+		ctx := ginutils.Context(context.Background(), c)
+
+		if !enforceQuota(ctx, c, globalquota.Enforcer(), organizationID, quota.Request{quota.ResourceSecrets: 1}) {
+			return
+		}
+	}
+
 	secretID, err := restricted.GlobalSecretStore.Store(organizationID, &createSecretRequest)
 	if err != nil {
 		var verr interface {
@@ -297,6 +310,23 @@ func DeleteSecrets(c *gin.Context) {
 
 	secretID := getSecretID(c)
 
+	// soft delete keeps the history of the secret, so that it can be restored later
+	soft, err := strconv.ParseBool(c.DefaultQuery("soft", "false"))
+	if err != nil {
+		soft = false
+	}
+
+	// force deletes the secret even if other resources still reference it
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		force = false
+	}
+
+	deleteSecret := restricted.GlobalSecretStore.Delete
+	if soft {
+		deleteSecret = restricted.GlobalSecretStore.SoftDelete
+	}
+
 	log.Infof("Check clusters before delete secret[%s]", secretID)
 	if err := checkClustersBeforeDelete(organizationID, secretID); err != nil {
 		log.Errorf("Cluster found with this secret[%s]: %s", secretID, err.Error())
@@ -305,7 +335,18 @@ func DeleteSecrets(c *gin.Context) {
 			Message: fmt.Sprintf("Cluster found with this secret[%s]", secretID),
 			Error:   err.Error(),
 		})
-	} else if err := restricted.GlobalSecretStore.Delete(organizationID, secretID); err != nil {
+	} else if !force && !checkSecretUsageBeforeDelete(c, organizationID, secretID) {
+		return
+	} else if err := deleteSecret(organizationID, secretID); err != nil {
+		var inUseErr usage.InUseError
+		if errors.As(err, &inUseErr) {
+			// secrets with resources to clean up cannot be deleted while in use (not even forcefully)
+			message := fmt.Sprintf("Secret[%s] is in use, delete the referencing resources first", secretID)
+			abortSecretInUse(c, message, secretID, inUseErr)
+
+			return
+		}
+
 		log.Errorf("Error during deleting secrets: %s", err.Error())
 		code := http.StatusInternalServerError
 		resp := common.ErrorResponse{
@@ -320,6 +361,127 @@ func DeleteSecrets(c *gin.Context) {
 	}
 }
 
+// ListSecretVersions returns the history of a secret
+func ListSecretVersions(c *gin.Context) {
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+	secretID := getSecretID(c)
+
+	versions, err := restricted.GlobalSecretStore.ListVersions(organizationID, secretID)
+	if err != nil {
+		status := secretVersionErrorStatus(err)
+
+		log.Errorf("Error during listing secret versions: %s", err.Error())
+		c.AbortWithStatusJSON(status, common.ErrorResponse{
+			Code:    status,
+			Message: "Error during listing secret versions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetSecretVersion returns a specific version of a secret
+func GetSecretVersion(c *gin.Context) {
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+	secretID := getSecretID(c)
+
+	version, ok := getSecretVersion(c)
+	if !ok {
+		return
+	}
+
+	s, err := restricted.GlobalSecretStore.GetVersion(organizationID, secretID, version)
+	if err != nil {
+		status := secretVersionErrorStatus(err)
+
+		log.Errorf("Error during getting secret version: %s", err.Error())
+		c.AbortWithStatusJSON(status, common.ErrorResponse{
+			Code:    status,
+			Message: "Error during getting secret version",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, s)
+}
+
+// RollbackSecret restores an earlier version of a secret (including soft deleted ones)
+func RollbackSecret(c *gin.Context) {
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+	secretID := getSecretID(c)
+
+	version, ok := getSecretVersion(c)
+	if !ok {
+		return
+	}
+
+	err := restricted.GlobalSecretStore.Rollback(organizationID, secretID, version, auth.GetCurrentUser(c.Request).Login)
+	if err != nil {
+		status := secretVersionErrorStatus(err)
+
+		log.Errorf("Error during rolling back secret: %s", err.Error())
+		c.AbortWithStatusJSON(status, common.ErrorResponse{
+			Code:    status,
+			Message: "Error during rolling back secret",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	s, err := restricted.GlobalSecretStore.Get(organizationID, secretID)
+	if err != nil {
+		log.Errorf("error during getting secret: %s", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, pipeline.CreateSecretResponse{
+		Name:      s.Name,
+		Type:      s.Type,
+		Id:        secretID,
+		UpdatedAt: s.UpdatedAt,
+		UpdatedBy: s.UpdatedBy,
+		Version:   int32(s.Version),
+		Tags:      s.Tags,
+	})
+}
+
+func getSecretVersion(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid secret version",
+			Error:   fmt.Sprintf("invalid secret version: %q", c.Param("version")),
+		})
+		return 0, false
+	}
+
+	return version, true
+}
+
+func secretVersionErrorStatus(err error) int {
+	var notFoundErr interface {
+		NotFound() bool
+	}
+
+	switch {
+	case errors.Is(err, secret.ErrSecretNotExists), errors.As(err, &notFoundErr) && notFoundErr.NotFound():
+		return http.StatusNotFound
+	case errors.Is(err, secret.ErrVersioningNotSupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 // GetSecretTags returns tags of a secret by ID
 func GetSecretTags(c *gin.Context) {
 	organizationID := auth.GetCurrentOrganization(c.Request).ID
@@ -464,6 +626,49 @@ func DeleteSecretTag(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// SecretInUseResponse describes the resources preventing the deletion of a secret.
+type SecretInUseResponse struct {
+	common.ErrorResponse
+	References []usage.Reference `json:"references"`
+}
+
+// checkSecretUsageBeforeDelete aborts the request if the secret is still referenced by other resources.
+func checkSecretUsageBeforeDelete(c *gin.Context, organizationID uint, secretID string) bool {
+	err := globalsecretusage.Index().EnsureUnused(c.Request.Context(), organizationID, secretID)
+	if err == nil {
+		return true
+	}
+
+	var inUseErr usage.InUseError
+	if errors.As(err, &inUseErr) {
+		message := fmt.Sprintf("Secret[%s] is in use, delete the referencing resources first or use force", secretID)
+		abortSecretInUse(c, message, secretID, inUseErr)
+
+		return false
+	}
+
+	errorHandler.Handle(err)
+	c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "Error during checking secret usage",
+		Error:   err.Error(),
+	})
+
+	return false
+}
+
+func abortSecretInUse(c *gin.Context, message string, secretID string, err usage.InUseError) {
+	log.Infof("Secret[%s] is referenced by %d resource(s)", secretID, len(err.References))
+	c.AbortWithStatusJSON(http.StatusConflict, SecretInUseResponse{
+		ErrorResponse: common.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: message,
+			Error:   err.Error(),
+		},
+		References: err.References,
+	})
+}
+
 // checkClustersBeforeDelete returns error if there's a running cluster that created with the given secret
 func checkClustersBeforeDelete(orgId uint, secretId string) error {
 	// TODO: move these to a struct and create them only once upon application init
@@ -21,10 +21,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/banzaicloud/anchore-image-validator/pkg/apis/security/v1alpha1"
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -36,6 +38,7 @@ import (
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	"github.com/banzaicloud/pipeline/pkg/security"
 	apiCommon "github.com/banzaicloud/pipeline/src/api/common"
+	"github.com/banzaicloud/pipeline/src/auth"
 	legacyHelm "github.com/banzaicloud/pipeline/src/helm"
 )
 
@@ -275,7 +278,9 @@ type SecurityHandler interface {
 type WhitelistHandler interface {
 	GetWhiteLists(c *gin.Context)
 	CreateWhiteList(c *gin.Context)
+	ApproveWhiteList(c *gin.Context)
 	DeleteWhiteList(c *gin.Context)
+	GetWhiteListAudit(c *gin.Context)
 }
 
 type ScanLogHandler interface {
@@ -286,18 +291,21 @@ type ScanLogHandler interface {
 type securityHandlers struct {
 	clusterGetter   apiCommon.ClusterGetter
 	resourceService anchore.SecurityResourceService
+	auditLog        anchore.WhitelistAuditLog
 	errorHandler    internalCommon.ErrorHandler
 	logger          internalCommon.Logger
 }
 
 func NewSecurityApiHandlers(
 	clusterGetter apiCommon.ClusterGetter,
+	auditLog anchore.WhitelistAuditLog,
 	errorHandler internalCommon.ErrorHandler,
 	logger internalCommon.Logger) SecurityHandler {
-	wlSvc := anchore.NewSecurityResourceService(logger)
+	wlSvc := anchore.NewSecurityResourceService(anchore.NewKubeConfigClientFactory(), auditLog, logger)
 	return securityHandlers{
 		clusterGetter:   clusterGetter,
 		resourceService: wlSvc,
+		auditLog:        auditLog,
 		errorHandler:    errorHandler,
 		logger:          logger,
 	}
@@ -326,9 +334,14 @@ func (s securityHandlers) GetWhiteLists(c *gin.Context) {
 	releaseWhitelist := make([]security.ReleaseWhiteListItem, 0)
 	for _, whitelist := range whitelist {
 		whitelistItem := security.ReleaseWhiteListItem{
-			Name:   whitelist.Name,
-			Owner:  whitelist.Spec.Creator,
-			Reason: whitelist.Spec.Reason,
+			Name:                 whitelist.Name,
+			Owner:                whitelist.Spec.Creator,
+			Reason:               whitelist.Spec.Reason,
+			Approver:             anchore.WhitelistItemApprover(whitelist),
+			NotificationSecretID: anchore.WhitelistItemNotificationSecretID(whitelist),
+		}
+		if expiresAt, ok := anchore.WhitelistItemExpiresAt(whitelist); ok {
+			whitelistItem.ExpiresAt = &expiresAt
 		}
 		releaseWhitelist = append(releaseWhitelist, whitelistItem)
 	}
@@ -356,7 +369,21 @@ func (s securityHandlers) CreateWhiteList(c *gin.Context) {
 		return
 	}
 
-	if _, err := s.resourceService.CreateWhitelist(c.Request.Context(), cluster, *whiteListItem); err != nil {
+	if err := validateWhiteListItem(*whiteListItem); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid whitelist item",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// the item is owned by the user creating it
+	whiteListItem.Owner = currentUserLogin(c)
+
+	ctx := anchore.WithWhitelistActor(c.Request.Context(), whiteListItem.Owner)
+
+	if _, err := s.resourceService.CreateWhitelist(ctx, cluster, *whiteListItem); err != nil {
 		s.errorHandler.HandleContext(c.Request.Context(), err)
 
 		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
@@ -388,7 +415,9 @@ func (s securityHandlers) DeleteWhiteList(c *gin.Context) {
 		return
 	}
 
-	if err := s.resourceService.DeleteWhitelist(c.Request.Context(), cluster, whitelisItemtName); err != nil {
+	ctx := anchore.WithWhitelistActor(c.Request.Context(), currentUserLogin(c))
+
+	if err := s.resourceService.DeleteWhitelist(ctx, cluster, whitelisItemtName); err != nil {
 		s.errorHandler.HandleContext(c.Request.Context(), err)
 
 		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
@@ -402,6 +431,108 @@ func (s securityHandlers) DeleteWhiteList(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (s securityHandlers) GetWhiteListAudit(c *gin.Context) {
+	cluster, ok := s.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		s.logger.Warn("failed to retrieve cluster based on the request")
+
+		return
+	}
+
+	events, err := s.auditLog.List(c.Request.Context(), cluster.GetID())
+	if err != nil {
+		s.errorHandler.HandleContext(c.Request.Context(), err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Error while retrieving whitelist audit events",
+			Error:   errors.Cause(err).Error(),
+		})
+		return
+	}
+
+	if name := c.Query("name"); name != "" {
+		filtered := make([]anchore.WhitelistAuditEvent, 0, len(events))
+		for _, event := range events {
+			if event.ItemName == name {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
+
+	s.successResponse(c, events)
+}
+
+// ApproveWhiteList records the current user as the approver of a whitelist item.
+// Only organization admins can send the request (like any other non-read request).
+func (s securityHandlers) ApproveWhiteList(c *gin.Context) {
+	whitelistItemName := c.Param("name")
+
+	cluster, ok := s.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		s.logger.Warn("failed to retrieve cluster based on the request")
+
+		return
+	}
+
+	approver := currentUserLogin(c)
+	ctx := anchore.WithWhitelistActor(c.Request.Context(), approver)
+
+	if err := s.resourceService.ApproveWhitelist(ctx, cluster, whitelistItemName, approver); err != nil {
+		if errors.Is(err, anchore.ErrWhitelistSelfApproval) {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid approval",
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			c.JSON(http.StatusNotFound, pkgCommon.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Whitelist item not found",
+				Error:   errors.Cause(err).Error(),
+			})
+			return
+		}
+
+		s.errorHandler.HandleContext(c.Request.Context(), err)
+
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Error while approving whitelist",
+			Error:   errors.Cause(err).Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateWhiteListItem checks the expiry of a new whitelist item and that it is not approved up front
+func validateWhiteListItem(item security.ReleaseWhiteListItem) error {
+	if item.ExpiresAt != nil && !item.ExpiresAt.After(time.Now()) {
+		return errors.New("expiresAt must be in the future")
+	}
+
+	if item.Approver != "" {
+		return errors.New("whitelist items can only be approved after they are created")
+	}
+
+	return nil
+}
+
+// currentUserLogin returns the login of the user sending the request
+func currentUserLogin(c *gin.Context) string {
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		return user.Login
+	}
+
+	return ""
+}
+
 func (s securityHandlers) ListScanLogs(c *gin.Context) {
 	cluster, ok := s.clusterGetter.GetClusterFromRequest(c)
 	if !ok {
@@ -0,0 +1,215 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authadapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"emperror.dev/errors"
+	bauth "github.com/banzaicloud/bank-vaults/pkg/sdk/auth"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+)
+
+// Values of the secrets holding access tokens.
+const (
+	tokenSecretType     = "accesstoken"
+	tokenUserIDKey      = "userId"
+	tokenIDKey          = "id"
+	tokenNameKey        = "name"
+	tokenValueKey       = "value"
+	tokenCreatedAtKey   = "createdAt"
+	tokenExpiresAtKey   = "expiresAt"
+	tokenSecretIDPrefix = "accesstoken-"
+)
+
+// SecretTokenStore stores access tokens in a secret store.
+// It is used instead of the Vault token store when organization secrets are not kept in Vault.
+type SecretTokenStore struct {
+	store secret.Store
+}
+
+// NewSecretTokenStore returns a new SecretTokenStore.
+func NewSecretTokenStore(store secret.Store) SecretTokenStore {
+	return SecretTokenStore{
+		store: store,
+	}
+}
+
+// Store stores a token of a user.
+func (s SecretTokenStore) Store(userID string, token *bauth.Token) error {
+	createdAt := time.Now()
+	if token.CreatedAt != nil {
+		createdAt = *token.CreatedAt
+	}
+
+	values := map[string]string{
+		tokenUserIDKey:    userID,
+		tokenIDKey:        token.ID,
+		tokenNameKey:      token.Name,
+		tokenValueKey:     token.Value,
+		tokenCreatedAtKey: createdAt.Format(time.RFC3339),
+	}
+
+	if token.ExpiresAt != nil {
+		values[tokenExpiresAtKey] = token.ExpiresAt.Format(time.RFC3339)
+	}
+
+	model := secret.Model{
+		ID:     tokenSecretID(userID, token.ID),
+		Name:   token.Name,
+		Type:   tokenSecretType,
+		Values: values,
+	}
+
+	err := s.store.Put(context.Background(), secret.SystemOrganizationID, model)
+
+	return errors.WrapIfWithDetails(err, "failed to store token", "userId", userID, "tokenId", token.ID)
+}
+
+// Lookup returns a token of a user or nil if the token does not exist or it has expired.
+func (s SecretTokenStore) Lookup(userID string, tokenID string) (*bauth.Token, error) {
+	model, err := s.store.Get(context.Background(), secret.SystemOrganizationID, tokenSecretID(userID, tokenID))
+	if errors.As(err, &secret.NotFoundError{}) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to look up token", "userId", userID, "tokenId", tokenID)
+	}
+
+	return parseTokenSecret(model, false)
+}
+
+// Exists checks whether a token of a user exists.
+func (s SecretTokenStore) Exists(userID string, tokenID string) (bool, error) {
+	token, err := s.Lookup(userID, tokenID)
+
+	return token != nil, err
+}
+
+// Revoke deletes a token of a user.
+func (s SecretTokenStore) Revoke(userID string, tokenID string) error {
+	err := s.store.Delete(context.Background(), secret.SystemOrganizationID, tokenSecretID(userID, tokenID))
+	if errors.As(err, &secret.NotFoundError{}) {
+		return nil
+	}
+
+	return errors.WrapIfWithDetails(err, "failed to revoke token", "userId", userID, "tokenId", tokenID)
+}
+
+// List returns the tokens of a user that have not expired yet.
+func (s SecretTokenStore) List(userID string) ([]*bauth.Token, error) {
+	tokens := []*bauth.Token{}
+
+	err := s.each(func(tokenUserID string, model secret.Model) error {
+		if tokenUserID != userID {
+			return nil
+		}
+
+		token, err := parseTokenSecret(model, false)
+		if err != nil {
+			return err
+		}
+
+		if token != nil {
+			tokens = append(tokens, token)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithDetails(err, "userId", userID)
+	}
+
+	return tokens, nil
+}
+
+// GC deletes the expired tokens of every user.
+func (s SecretTokenStore) GC() error {
+	now := time.Now()
+
+	return s.each(func(userID string, model secret.Model) error {
+		token, err := parseTokenSecret(model, true)
+		if err != nil {
+			return err
+		}
+
+		if token.ExpiresAt != nil && token.ExpiresAt.Before(now) {
+			return s.Revoke(userID, token.ID)
+		}
+
+		return nil
+	})
+}
+
+// each calls fn for every token secret in the store.
+func (s SecretTokenStore) each(fn func(userID string, model secret.Model) error) error {
+	models, err := s.store.List(context.Background(), secret.SystemOrganizationID)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list tokens")
+	}
+
+	for _, model := range models {
+		if model.Type != tokenSecretType {
+			continue
+		}
+
+		if err := fn(model.Values[tokenUserIDKey], model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tokenSecretID returns a secret ID that is safe to use in every secret store backend.
+func tokenSecretID(userID string, tokenID string) string {
+	sum := sha256.Sum256([]byte(userID + "/" + tokenID))
+
+	return tokenSecretIDPrefix + hex.EncodeToString(sum[:])
+}
+
+func parseTokenSecret(model secret.Model, showExpired bool) (*bauth.Token, error) {
+	token := bauth.Token{
+		ID:    model.Values[tokenIDKey],
+		Name:  model.Values[tokenNameKey],
+		Value: model.Values[tokenValueKey],
+	}
+
+	if expiresAtRaw := model.Values[tokenExpiresAtKey]; expiresAtRaw != "" {
+		expiresAt, err := time.Parse(time.RFC3339, expiresAtRaw)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "invalid token expiration", "secretId", model.ID)
+		}
+
+		// Token has expired, make it invisible
+		if !showExpired && expiresAt.Before(time.Now()) {
+			return nil, nil
+		}
+
+		token.ExpiresAt = &expiresAt
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, model.Values[tokenCreatedAtKey])
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid token creation time", "secretId", model.ID)
+	}
+
+	token.CreatedAt = &createdAt
+
+	return &token, nil
+}
@@ -0,0 +1,123 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authadapter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	bauth "github.com/banzaicloud/bank-vaults/pkg/sdk/auth"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/common"
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/secretadapter"
+)
+
+func newTestSecretStore(t *testing.T) secret.Store {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	require.NoError(t, secretadapter.Migrate(db, common.NoopLogger{}))
+
+	store, err := secretadapter.NewSQLStore(db, bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestSecretTokenStore(t *testing.T) {
+	secretStore := newTestSecretStore(t)
+	store := NewSecretTokenStore(secretStore)
+
+	createdAt := time.Now().Truncate(time.Second)
+	expiresAt := createdAt.Add(time.Hour)
+
+	token := &bauth.Token{
+		ID:        "token1",
+		Name:      "my token",
+		Value:     "secret value",
+		CreatedAt: &createdAt,
+		ExpiresAt: &expiresAt,
+	}
+
+	require.NoError(t, store.Store("1", token))
+	require.NoError(t, store.Store("2", bauth.NewToken("token2", "other user's token")))
+
+	actual, err := store.Lookup("1", "token1")
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.Equal(t, token.ID, actual.ID)
+	assert.Equal(t, token.Name, actual.Name)
+	assert.Equal(t, token.Value, actual.Value)
+	assert.True(t, createdAt.Equal(*actual.CreatedAt))
+	assert.True(t, expiresAt.Equal(*actual.ExpiresAt))
+
+	exists, err := store.Exists("2", "token1")
+	require.NoError(t, err)
+	assert.False(t, exists, "tokens are scoped to their user")
+
+	tokens, err := store.List("1")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "token1", tokens[0].ID)
+
+	// Tokens are not stored among the secrets of any organization
+	models, err := secretStore.List(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, models)
+
+	require.NoError(t, store.Revoke("1", "token1"))
+
+	exists, err = store.Exists("1", "token1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Revoke("1", "token1"), "revoking a missing token is not an error")
+}
+
+func TestSecretTokenStore_Expired(t *testing.T) {
+	secretStore := newTestSecretStore(t)
+	store := NewSecretTokenStore(secretStore)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	expired := bauth.NewToken("expired", "expired token")
+	expired.ExpiresAt = &expiresAt
+
+	require.NoError(t, store.Store("1", expired))
+	require.NoError(t, store.Store("1", bauth.NewToken("valid", "valid token")))
+
+	token, err := store.Lookup("1", "expired")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	tokens, err := store.List("1")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "valid", tokens[0].ID)
+
+	require.NoError(t, store.GC())
+
+	models, err := secretStore.List(context.Background(), secret.SystemOrganizationID)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "valid", models[0].Values[tokenIDKey])
+}
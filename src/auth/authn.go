@@ -183,6 +183,13 @@ func Init(db *gorm.DB, config Config, tokenStore bauth.TokenStore, tokenManager
 		func(claims *ginauth.ScopedClaims) interface{} {
 			userID, _ := strconv.ParseUint(claims.Subject, 10, 32)
 
+			if claims.Type == ginauth.TokenType(KubeconfigToken) {
+				return &User{
+					ID:           uint(userID),
+					ClusterScope: claims.Text,
+				}
+			}
+
 			return &User{
 				ID:      uint(userID),
 				Login:   claims.Text, // This is needed for virtual user tokens
@@ -117,6 +117,11 @@ func (e RbacEnforcer) Enforce(org *Organization, user *User, path, method string
 	case RoleAdmin:
 		return true, nil
 	case RoleMember:
+		// Members can issue short-lived kubeconfigs for themselves
+		if ok, err := regexp.MatchString(`^/api/v1/orgs/\d+/clusters/\d+/kubeconfigs$`, path); err == nil && ok && method == http.MethodPost {
+			return true, nil
+		}
+
 		// Members can only read organization resources
 		if ok, err := regexp.MatchString(`^/api/v1/orgs(?:/.*)?$`, path); err != nil || (ok && method != http.MethodGet && method != http.MethodHead) {
 			return false, nil
@@ -127,8 +132,8 @@ func (e RbacEnforcer) Enforce(org *Organization, user *User, path, method string
 			return false, nil
 		}
 
-		// Members cannot access secrets at all
-		if ok, err := regexp.MatchString(`^/api/v1/orgs/\d+/secrets(?:/.*)?$`, path); err != nil || ok {
+		// Members cannot access secrets (and their bindings) at all
+		if ok, err := regexp.MatchString(`^/api/v1/orgs/\d+/(?:secrets|secretbindings)(?:/.*)?$`, path); err != nil || ok {
 			return false, errors.WithStackIf(err)
 		}
 
@@ -238,12 +238,30 @@ func TestRbacEnforcer_Enforce(t *testing.T) {
 			method:   "POST",
 			expected: false,
 		},
+		{
+			role:     RoleMember,
+			path:     "/api/v1/orgs/1/secretbindings/1/status",
+			method:   "GET",
+			expected: false,
+		},
 		{
 			role:     RoleMember,
 			path:     "/api/v1/orgs/1/clusters/1/config",
 			method:   "GET",
 			expected: false,
 		},
+		{
+			role:     RoleMember,
+			path:     "/api/v1/orgs/1/clusters/1/kubeconfigs",
+			method:   "POST",
+			expected: true,
+		},
+		{
+			role:     RoleMember,
+			path:     "/api/v1/orgs/1/clusters/1/kubeconfigs",
+			method:   "DELETE",
+			expected: false,
+		},
 	}
 
 	for _, test := range tests {
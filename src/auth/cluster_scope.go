@@ -0,0 +1,59 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// nolint: gochecknoglobals
+var clusterProxyPathRegexp = regexp.MustCompile(`^/api/v1/orgs/(\d+)/clusters/(\d+)/proxy(?:/.*)?$`)
+
+// NewClusterScopeHandler returns a handler that restricts users authenticated with cluster scoped tokens
+// to the Kubernetes API proxy of the cluster the token was issued for.
+func NewClusterScopeHandler(basePath string) gin.HandlerFunc {
+	basePath = fmt.Sprintf("/%s", strings.Trim(basePath, "/"))
+
+	return func(c *gin.Context) {
+		user := GetCurrentUser(c.Request)
+		if user == nil || user.ClusterScope == "" {
+			return
+		}
+
+		path := c.Request.URL.Path
+		if basePath != "/" {
+			path = strings.TrimPrefix(path, basePath)
+		}
+
+		if !IsClusterScopeAllowed(user.ClusterScope, path) {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+}
+
+// IsClusterScopeAllowed checks whether a token with the given cluster scope can be used for accessing a path.
+func IsClusterScopeAllowed(scope string, path string) bool {
+	match := clusterProxyPathRegexp.FindStringSubmatch(path)
+	if match == nil {
+		return false
+	}
+
+	return scope == fmt.Sprintf("orgs/%s/clusters/%s", match[1], match[2])
+}
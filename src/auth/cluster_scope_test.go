@@ -0,0 +1,67 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsClusterScopeAllowed(t *testing.T) {
+	scope := ClusterScope(1, 2)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{
+			path:     "/api/v1/orgs/1/clusters/2/proxy",
+			expected: true,
+		},
+		{
+			path:     "/api/v1/orgs/1/clusters/2/proxy/api/v1/namespaces",
+			expected: true,
+		},
+		{
+			path:     "/api/v1/orgs/1/clusters/3/proxy/api/v1/namespaces",
+			expected: false,
+		},
+		{
+			path:     "/api/v1/orgs/2/clusters/2/proxy/api/v1/namespaces",
+			expected: false,
+		},
+		{
+			path:     "/api/v1/orgs/1/clusters/2/config",
+			expected: false,
+		},
+		{
+			path:     "/api/v1/orgs/1/clusters/2/proxyfoo",
+			expected: false,
+		},
+		{
+			path:     "/api/v1/tokens",
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.expected, IsClusterScopeAllowed(scope, test.path))
+		})
+	}
+}
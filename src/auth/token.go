@@ -26,6 +26,15 @@ import (
 // ClusterToken is the token given to clusters to manage themselves.
 const ClusterToken auth.TokenType = "cluster"
 
+// KubeconfigToken is the short-lived token embedded in kubeconfigs issued by Pipeline.
+// It can only be used for accessing the Kubernetes API proxy of the cluster it was issued for.
+const KubeconfigToken auth.TokenType = "kubeconfig"
+
+// ClusterScope returns the scope of tokens that can only be used for accessing a single cluster.
+func ClusterScope(orgID uint, clusterID uint) string {
+	return fmt.Sprintf("orgs/%d/clusters/%d", orgID, clusterID)
+}
+
 // ClusterTokenGenerator looks up or generates and stores a token for a cluster.
 type ClusterTokenGenerator struct {
 	tokenManager TokenManager
@@ -67,3 +76,27 @@ func (g ClusterTokenGenerator) GenerateClusterToken(orgID uint, clusterID uint)
 
 	return g.tokenManager.GenerateToken(userID, nil, ClusterToken, userID, userID, true)
 }
+
+// KubeconfigTokenGenerator generates and stores tokens for kubeconfigs issued by Pipeline.
+type KubeconfigTokenGenerator struct {
+	tokenManager TokenManager
+}
+
+// NewKubeconfigTokenGenerator returns a new KubeconfigTokenGenerator.
+func NewKubeconfigTokenGenerator(tokenManager TokenManager) KubeconfigTokenGenerator {
+	return KubeconfigTokenGenerator{
+		tokenManager: tokenManager,
+	}
+}
+
+// GenerateKubeconfigToken generates and stores a token of a user that expires at the given time
+// and can only be used for accessing a single cluster.
+func (g KubeconfigTokenGenerator) GenerateKubeconfigToken(
+	userID uint,
+	orgID uint,
+	clusterID uint,
+	expiresAt time.Time,
+	name string,
+) (string, string, error) {
+	return g.tokenManager.GenerateToken(fmt.Sprint(userID), &expiresAt, KubeconfigToken, ClusterScope(orgID, clusterID), name, false)
+}
@@ -68,6 +68,7 @@ type User struct {
 	Virtual        bool           `json:"-" gorm:"-"` // Used only internally
 	APIToken       string         `json:"-" gorm:"-"` // Used only internally
 	ServiceAccount bool           `json:"-" gorm:"-"` // Used only internally
+	ClusterScope   string         `json:"-" gorm:"-"` // Used only internally
 }
 
 // CICDUser struct
@@ -35,6 +35,8 @@ import (
 	"github.com/banzaicloud/pipeline/internal/providers/azure/pke"
 	"github.com/banzaicloud/pipeline/internal/providers/azure/pke/adapter"
 	pkeAzureAdapter "github.com/banzaicloud/pipeline/internal/providers/azure/pke/driver/commoncluster"
+	hostsadapter "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/adapter"
+	pkeHostsAdapter "github.com/banzaicloud/pipeline/internal/providers/hosts/pke/driver/commoncluster"
 	"github.com/banzaicloud/pipeline/internal/providers/kubernetes/kubernetesadapter"
 	vsphereadapter "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/adapter"
 	pkeVsphereAdapter "github.com/banzaicloud/pipeline/internal/providers/vsphere/pke/driver/commoncluster"
@@ -308,6 +310,8 @@ func GetCommonClusterFromModel(modelCluster *model.ClusterModel) (CommonCluster,
 			return pkeAzureAdapter.MakeCommonClusterGetter(secret.Store, adapter.NewClusterStore(db, logger)).GetByID(modelCluster.ID)
 		case pkgCluster.Vsphere:
 			return pkeVsphereAdapter.MakeCommonClusterGetter(secret.Store, vsphereadapter.NewClusterStore(db)).GetByID(modelCluster.ID)
+		case pkgCluster.Hosts:
+			return pkeHostsAdapter.MakeCommonClusterGetter(secret.Store, hostsadapter.NewClusterStore(db)).GetByID(modelCluster.ID)
 		default:
 			return createCommonClusterWithDistributionFromModel(modelCluster)
 		}
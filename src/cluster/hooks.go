@@ -362,8 +362,8 @@ func (hpa *HorizontalPodAutoscalerPostHook) Do(cluster CommonCluster) error {
 		metricsServerValues := make(map[string]interface{}, 0)
 		metricsServerValues["enabled"] = true
 
-		// use InternalIP on VSphere
-		if cluster.GetCloud() == pkgCluster.Vsphere {
+		// use InternalIP on VSphere and on existing hosts
+		if cluster.GetCloud() == pkgCluster.Vsphere || cluster.GetCloud() == pkgCluster.Hosts {
 			metricsServerValues["args"] = []string{
 				"--kubelet-preferred-address-types=InternalIP",
 			}
@@ -16,6 +16,7 @@ package cluster
 
 import (
 	stderrors "errors"
+	"fmt"
 
 	"emperror.dev/errors"
 	v1 "k8s.io/api/core/v1"
@@ -96,9 +97,10 @@ func InstallSecretsByK8SConfig(kubeConfig []byte, orgID uint, query *secret.List
 		}
 
 		kubeSecretRequest := kubesecret.KubeSecretRequest{
-			Name:   s.Name,
-			Type:   s.Type,
-			Values: s.Values,
+			Name:             s.Name,
+			Type:             s.Type,
+			Values:           s.Values,
+			SourceSecretName: s.Name,
 		}
 
 		newK8sSecret, err := kubesecret.CreateKubeSecret(kubeSecretRequest)
@@ -113,6 +115,8 @@ func InstallSecretsByK8SConfig(kubeConfig []byte, orgID uint, query *secret.List
 		} else {
 			k8sSecret.Data = nil // Clear data so that it is created from string data again
 			k8sSecret.StringData = newK8sSecret.StringData
+			k8sSecret.Labels = mergeStringMaps(k8sSecret.Labels, newK8sSecret.Labels)
+			k8sSecret.Annotations = mergeStringMaps(k8sSecret.Annotations, newK8sSecret.Annotations)
 
 			_, err = clusterClient.CoreV1().Secrets(namespace).Update(&k8sSecret)
 		}
@@ -167,9 +171,10 @@ func InstallSecretByK8SConfig(kubeConfig []byte, orgID uint, secretName string,
 	}
 
 	kubeSecretRequest := kubesecret.KubeSecretRequest{
-		Name:      secretName,
-		Namespace: req.Namespace,
-		Spec:      make(kubesecret.KubeSecretSpec, len(req.Spec)),
+		Name:             secretName,
+		Namespace:        req.Namespace,
+		Spec:             make(kubesecret.KubeSecretSpec, len(req.Spec)),
+		SourceSecretName: req.SourceSecretName,
 	}
 
 	if req.SourceSecretName != "" {
@@ -288,3 +293,80 @@ func MergeSecretByK8SConfig(kubeConfig []byte, orgID uint, secretName string, re
 
 	return secretName, nil
 }
+
+// SyncSecretByK8SConfig updates every Kubernetes secret in a cluster that was installed from a pipeline secret.
+// It returns the number of updated Kubernetes secrets.
+func SyncSecretByK8SConfig(kubeConfig []byte, orgID uint, sourceSecretName string) (int, error) {
+	clusterClient, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	secretItem, err := secret.Store.GetByName(orgID, sourceSecretName)
+	if err == secret.ErrSecretNotExists {
+		return 0, ErrSecretNotFound
+	} else if err != nil {
+		return 0, errors.WithDetails(errors.Wrap(err, "failed to get secret"), "secret", sourceSecretName)
+	}
+
+	clusterSecretList, err := clusterClient.CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", kubesecret.ManagedByLabel, kubesecret.ManagedByLabelValue),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list kubernetes secrets")
+	}
+
+	var count int
+
+	for _, clusterSecret := range clusterSecretList.Items {
+		if clusterSecret.Annotations[kubesecret.SourceSecretAnnotation] != sourceSecretName {
+			continue
+		}
+
+		spec, err := kubesecret.ParseSourceSpec(clusterSecret.Annotations)
+		if err != nil {
+			return count, errors.WithDetails(err, "namespace", clusterSecret.Namespace, "secret", clusterSecret.Name)
+		}
+
+		kubeSecret, err := kubesecret.CreateKubeSecret(kubesecret.KubeSecretRequest{
+			Name:             clusterSecret.Name,
+			Namespace:        clusterSecret.Namespace,
+			Type:             secretItem.Type,
+			Values:           secretItem.Values,
+			Spec:             spec,
+			SourceSecretName: sourceSecretName,
+		})
+		if err != nil {
+			return count, errors.WrapIf(err, "failed to create kubernetes secret")
+		}
+
+		clusterSecret := clusterSecret
+		clusterSecret.Data = nil // Clear data so that it is created from string data again
+		clusterSecret.StringData = kubeSecret.StringData
+
+		_, err = clusterClient.CoreV1().Secrets(clusterSecret.Namespace).Update(&clusterSecret)
+		if err != nil {
+			return count, errors.WrapIfWithDetails(err, "failed to update kubernetes secret", "namespace", clusterSecret.Namespace, "secret", clusterSecret.Name)
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+func mergeStringMaps(dst map[string]string, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+
+	for key, value := range src {
+		dst[key] = value
+	}
+
+	return dst
+}
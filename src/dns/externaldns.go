@@ -76,6 +76,11 @@ func newExternalDnsServiceClientInstance() {
 		return
 	}
 
+	if global.Vault() == nil {
+		log.Infoln("DNS is not available without Vault (Pipeline only connects to Vault with the vault secret store backend)")
+		return
+	}
+
 	const gcInterval = time.Minute
 
 	// This is how the secrets are expected to be written in Vault:
@@ -27,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	"github.com/banzaicloud/pipeline/internal/global/globalsecretusage"
 	"github.com/banzaicloud/pipeline/internal/secret"
 	"github.com/banzaicloud/pipeline/internal/secret/secrettype"
 )
@@ -39,6 +40,10 @@ var Store *secretStore
 // nolint: gochecknoglobals
 var ErrSecretNotExists = fmt.Errorf("There's no secret with this ID")
 
+// ErrVersioningNotSupported is returned when the underlying store does not keep the history of secrets
+// nolint: gochecknoglobals
+var ErrVersioningNotSupported = fmt.Errorf("secret store does not support versioning")
+
 // InitSecretStore initializes the global secret store.
 func InitSecretStore(store secret.Store, types secret.TypeList) {
 	Store = &secretStore{
@@ -87,6 +92,14 @@ type SecretItemResponse struct {
 	UpdatedBy string            `json:"updatedBy,omitempty" mapstructure:"updatedBy"`
 }
 
+// SecretVersionResponse for ListSecretVersions
+type SecretVersionResponse struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	Deleted   bool      `json:"deleted"`
+}
+
 // ValidateSecretType validates the secret type
 func ValidateSecretType(s *SecretItemResponse, validType string) error {
 	if s.Type != validType {
@@ -159,12 +172,22 @@ func (ss *secretStore) Delete(organizationID uint, secretID string) error {
 		return errors.Errorf("wrong secret type: %s", s.Type)
 	}
 
+	ct, cleanup := secretType.(secret.CleanupType)
+	if cleanup {
+		// Resources created for the secret might still be used by the resources referencing it,
+		// so even a forced delete is refused until the references are removed
+		err := globalsecretusage.Index().EnsureUnused(context.Background(), organizationID, secretID)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := ss.SecretStore.Delete(context.Background(), organizationID, secretID); err != nil {
 		return err
 	}
 
-	if ct, ok := secretType.(secret.CleanupType); ok {
-		err := ct.Cleanup(organizationID, s.Values, s.Tags)
+	if cleanup {
+		err = ct.Cleanup(organizationID, s.Values, s.Tags)
 		if err != nil {
 			return err
 		}
@@ -356,7 +379,7 @@ func (ss *secretStore) Get(organizationID uint, secretID string) (*SecretItemRes
 		Type:      model.Type,
 		Values:    model.Values,
 		Tags:      model.Tags,
-		Version:   1,
+		Version:   model.Version,
 		UpdatedAt: model.UpdatedAt,
 		UpdatedBy: model.UpdatedBy,
 	}, nil
@@ -416,7 +439,7 @@ func (ss *secretStore) List(orgid uint, query *ListSecretsQuery) ([]*SecretItemR
 			Type:      model.Type,
 			Values:    model.Values,
 			Tags:      model.Tags,
-			Version:   1,
+			Version:   model.Version,
 			UpdatedAt: model.UpdatedAt,
 			UpdatedBy: model.UpdatedBy,
 		}
@@ -434,6 +457,111 @@ func (ss *secretStore) List(orgid uint, query *ListSecretsQuery) ([]*SecretItemR
 	return responseItems, nil
 }
 
+// SoftDelete deletes the latest version of a secret, keeping its history secret/orgs/:orgid:/:id: scope
+// Unlike Delete, it does not clean up the resources belonging to the secret, so that it can be restored later.
+func (ss *secretStore) SoftDelete(organizationID uint, secretID string) error {
+	store, ok := ss.SecretStore.(secret.VersionedStore)
+	if !ok {
+		return ErrVersioningNotSupported
+	}
+
+	log.WithFields(logrus.Fields{
+		"organizationId": organizationID,
+		"secretId":       secretID,
+	}).Debugln("soft deleting secret")
+
+	_, err := ss.Get(organizationID, secretID)
+	if err == ErrSecretNotExists { // Already deleted
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "Error during querying secret before deletion")
+	}
+
+	return store.SoftDelete(context.Background(), organizationID, secretID)
+}
+
+// ListVersions lists the versions of a secret secret/orgs/:orgid:/:id: scope
+func (ss *secretStore) ListVersions(organizationID uint, secretID string) ([]SecretVersionResponse, error) {
+	store, ok := ss.SecretStore.(secret.VersionedStore)
+	if !ok {
+		return nil, ErrVersioningNotSupported
+	}
+
+	versions, err := store.ListVersions(context.Background(), organizationID, secretID)
+	if err != nil && errors.As(err, &secret.NotFoundError{}) {
+		return nil, ErrSecretNotExists
+	} else if err != nil {
+		return nil, err
+	}
+
+	responseItems := make([]SecretVersionResponse, 0, len(versions))
+
+	for _, version := range versions {
+		responseItems = append(responseItems, SecretVersionResponse{
+			Version:   version.Version,
+			UpdatedAt: version.UpdatedAt,
+			UpdatedBy: version.UpdatedBy,
+			Deleted:   version.Deleted,
+		})
+	}
+
+	return responseItems, nil
+}
+
+// GetVersion retrieves a specific version of a secret secret/orgs/:orgid:/:id: scope
+func (ss *secretStore) GetVersion(organizationID uint, secretID string, version int) (*SecretItemResponse, error) {
+	store, ok := ss.SecretStore.(secret.VersionedStore)
+	if !ok {
+		return nil, ErrVersioningNotSupported
+	}
+
+	model, err := store.GetVersion(context.Background(), organizationID, secretID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretItemResponse{
+		ID:        model.ID,
+		Name:      model.Name,
+		Type:      model.Type,
+		Values:    model.Values,
+		Tags:      model.Tags,
+		Version:   model.Version,
+		UpdatedAt: model.UpdatedAt,
+		UpdatedBy: model.UpdatedBy,
+	}, nil
+}
+
+// Rollback writes the content of an earlier version of a secret as its latest version secret/orgs/:orgid:/:id: scope
+// Soft deleted secrets can be restored this way as well.
+func (ss *secretStore) Rollback(organizationID uint, secretID string, version int, updatedBy string) error {
+	store, ok := ss.SecretStore.(secret.VersionedStore)
+	if !ok {
+		return ErrVersioningNotSupported
+	}
+
+	log.WithFields(logrus.Fields{
+		"organizationId": organizationID,
+		"secretId":       secretID,
+		"version":        version,
+	}).Debugln("rolling back secret")
+
+	model, err := store.GetVersion(context.Background(), organizationID, secretID, version)
+	if err != nil {
+		return err
+	}
+
+	// the earlier version goes through the same validation as any other update
+	return ss.Update(organizationID, secretID, &CreateSecretRequest{
+		Name:      model.Name,
+		Type:      model.Type,
+		Values:    model.Values,
+		Tags:      model.Tags,
+		UpdatedBy: updatedBy,
+	})
+}
+
 // Verify secret secret/orgs/:orgid:/:id: scope
 func (ss *secretStore) Verify(organizationID uint, secretID string) error {
 	s, err := ss.Get(organizationID, secretID)
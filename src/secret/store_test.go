@@ -0,0 +1,178 @@
+// Copyright © 2020 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/pipeline/internal/secret"
+	"github.com/banzaicloud/pipeline/internal/secret/types"
+)
+
+func TestSecretStore_Rollback(t *testing.T) {
+	const organizationID = 1
+
+	store := &versionedSecretStore{}
+	ss := &secretStore{
+		SecretStore: store,
+		Types:       secret.NewTypeList([]secret.Type{types.PasswordType{}}),
+	}
+
+	secretID, err := ss.Store(organizationID, &CreateSecretRequest{
+		Name:      "my-password",
+		Type:      types.PasswordType{}.Name(),
+		Values:    map[string]string{types.FieldPasswordUsername: "user", types.FieldPasswordPassword: "first"},
+		UpdatedBy: "alice",
+	})
+	require.NoError(t, err)
+
+	err = ss.Update(organizationID, secretID, &CreateSecretRequest{
+		Name:      "my-password",
+		Type:      types.PasswordType{}.Name(),
+		Values:    map[string]string{types.FieldPasswordUsername: "user", types.FieldPasswordPassword: "second"},
+		UpdatedBy: "alice",
+	})
+	require.NoError(t, err)
+
+	err = ss.Rollback(organizationID, secretID, 1, "bob")
+	require.NoError(t, err)
+
+	s, err := ss.Get(organizationID, secretID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, s.Version)
+	assert.Equal(t, "first", s.Values[types.FieldPasswordPassword])
+	assert.Equal(t, "bob", s.UpdatedBy)
+}
+
+func TestSecretStore_Rollback_Invalid(t *testing.T) {
+	const organizationID = 1
+
+	store := &versionedSecretStore{}
+	ss := &secretStore{
+		SecretStore: store,
+		Types:       secret.NewTypeList([]secret.Type{types.PasswordType{}}),
+	}
+
+	// a version written before the password field became mandatory
+	require.NoError(t, store.Put(context.Background(), organizationID, secret.Model{
+		ID:     GenerateSecretIDFromName("my-password"),
+		Name:   "my-password",
+		Type:   types.PasswordType{}.Name(),
+		Values: map[string]string{types.FieldPasswordUsername: "user"},
+	}))
+
+	secretID, err := ss.CreateOrUpdate(organizationID, &CreateSecretRequest{
+		Name:   "my-password",
+		Type:   types.PasswordType{}.Name(),
+		Values: map[string]string{types.FieldPasswordUsername: "user", types.FieldPasswordPassword: "second"},
+	})
+	require.NoError(t, err)
+
+	err = ss.Rollback(organizationID, secretID, 1, "bob")
+	require.Error(t, err)
+
+	s, err := ss.Get(organizationID, secretID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, s.Version)
+	assert.Equal(t, "second", s.Values[types.FieldPasswordPassword])
+}
+
+// versionedSecretStore keeps every version of the secrets of a single organization in memory.
+type versionedSecretStore struct {
+	versions map[string][]secret.Model
+}
+
+func (s *versionedSecretStore) Create(ctx context.Context, organizationID uint, model secret.Model) error {
+	if _, err := s.Get(ctx, organizationID, model.ID); err == nil {
+		return secret.AlreadyExistsError{OrganizationID: organizationID, SecretID: model.ID}
+	}
+
+	return s.Put(ctx, organizationID, model)
+}
+
+func (s *versionedSecretStore) Put(_ context.Context, _ uint, model secret.Model) error {
+	if s.versions == nil {
+		s.versions = make(map[string][]secret.Model)
+	}
+
+	model.Version = len(s.versions[model.ID]) + 1
+	s.versions[model.ID] = append(s.versions[model.ID], model)
+
+	return nil
+}
+
+func (s *versionedSecretStore) Get(_ context.Context, organizationID uint, id string) (secret.Model, error) {
+	versions := s.versions[id]
+	if len(versions) == 0 || versions[len(versions)-1].Values == nil {
+		return secret.Model{}, secret.NotFoundError{OrganizationID: organizationID, SecretID: id}
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+func (s *versionedSecretStore) List(ctx context.Context, organizationID uint) ([]secret.Model, error) {
+	var models []secret.Model
+	for id := range s.versions {
+		if model, err := s.Get(ctx, organizationID, id); err == nil {
+			models = append(models, model)
+		}
+	}
+
+	return models, nil
+}
+
+func (s *versionedSecretStore) Delete(_ context.Context, _ uint, id string) error {
+	delete(s.versions, id)
+
+	return nil
+}
+
+func (s *versionedSecretStore) ListVersions(_ context.Context, organizationID uint, id string) ([]secret.Version, error) {
+	if len(s.versions[id]) == 0 {
+		return nil, secret.NotFoundError{OrganizationID: organizationID, SecretID: id}
+	}
+
+	versions := make([]secret.Version, 0, len(s.versions[id]))
+	for _, model := range s.versions[id] {
+		versions = append(versions, secret.Version{Version: model.Version, UpdatedBy: model.UpdatedBy, Deleted: model.Values == nil})
+	}
+
+	return versions, nil
+}
+
+func (s *versionedSecretStore) GetVersion(_ context.Context, organizationID uint, id string, version int) (secret.Model, error) {
+	if version < 1 || version > len(s.versions[id]) || s.versions[id][version-1].Values == nil {
+		return secret.Model{}, secret.VersionNotFoundError{OrganizationID: organizationID, SecretID: id, Version: version}
+	}
+
+	return s.versions[id][version-1], nil
+}
+
+func (s *versionedSecretStore) SoftDelete(ctx context.Context, organizationID uint, id string) error {
+	model, err := s.Get(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+
+	model.Values = nil
+
+	return s.Put(ctx, organizationID, model)
+}